@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/hdbsql"
+)
+
+// audit operation types.
+const (
+	AuditOpQuery = "query" // query execution
+	AuditOpExec  = "exec"  // statement execution (e.g. DML, DDL)
+	AuditOpCall  = "call"  // procedure call
+)
+
+// An AuditRecord describes an executed statement.
+type AuditRecord struct {
+	Op            string        // Operation type (AuditOpQuery, AuditOpExec or AuditOpCall).
+	Fingerprint   string        // Statement fingerprint (see QueryFingerprint).
+	Statement     string        // Normalized statement (see NormalizeQuery).
+	User          string        // Database user of the connection.
+	SessionID     int64         // Database session id of the connection.
+	CorrelationID string        // Correlation id of the statement execution.
+	RowsAffected  int64         // Number of rows affected (exec, call) or read (query).
+	Duration      time.Duration // Execution time (queries: until the result set is closed).
+	Err           error         // Error of the execution (nil: success).
+}
+
+/*
+An AuditFunc is called after every statement execution of the connections of a connector (see Connector.SetAuditFunc).
+
+As the statement text is normalized, literal values are not part of the audit record and arguments are not
+passed at all. The audit function of a query is called after the result set is closed, so that the number of
+rows read is known, or after the query failed.
+
+The audit function is called synchronously while the connection is locked, so that the audit records of a
+connection are delivered in execution order. Therefore the function must not use the connection and should
+return quickly (e.g. by writing to a buffered audit log).
+*/
+type AuditFunc func(ctx context.Context, r *AuditRecord)
+
+// auditOp returns the audit operation type of query executed via driver operation op.
+func auditOp(op, query string) string {
+	if class, err := hdbsql.Classify(query); err == nil && class.Kind == hdbsql.KindCall {
+		return AuditOpCall
+	}
+	if op == opQuery {
+		return AuditOpQuery
+	}
+	return AuditOpExec
+}
+
+// audit calls the audit function of the connection.
+func (c *conn) audit(ctx context.Context, op, query string, numRow int64, d time.Duration, err error) {
+	corrID, _ := CorrelationIDFromContext(ctx)
+	stmt := NormalizeQuery(query)
+	c.auditFunc(ctx, &AuditRecord{
+		Op:            auditOp(op, query),
+		Fingerprint:   fingerprint(stmt),
+		Statement:     stmt,
+		User:          c.username,
+		SessionID:     c.session.ID(),
+		CorrelationID: corrID,
+		RowsAffected:  numRow,
+		Duration:      d,
+		Err:           err,
+	})
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockAudit(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{RowsAffected: 1})
+	s.Handle("select a from t where b = 'x'", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "A", TypeName: "INTEGER"}},
+		Rows:    [][]interface{}{{int32(1)}, {int32(2)}},
+	})
+	s.Handle("call proc", &drivertest.MockStatement{})
+	s.Handle("delete from t", &drivertest.MockStatement{Err: &drivertest.MockError{Code: 259, Text: "invalid table name"}})
+
+	var records []*driver.AuditRecord
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetAuditFunc(func(ctx context.Context, r *driver.AuditRecord) { records = append(records, r) }); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "insert into t values (?)", "v"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := conn.QueryContext(ctx, "select a from t where b = 'x'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "call proc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(ctx, "delete from t"); err == nil {
+		t.Fatal("error expected")
+	}
+
+	exp := []struct {
+		op, stmt string
+		numRow   int64
+		err      bool
+	}{
+		{driver.AuditOpExec, "insert into t values (?)", 1, false},
+		{driver.AuditOpQuery, "select a from t where b = ?", 2, false},
+		{driver.AuditOpCall, "call proc", 0, false},
+		{driver.AuditOpExec, "delete from t", 0, true},
+	}
+	if len(records) != len(exp) {
+		t.Fatalf("number of audit records %d - expected %d", len(records), len(exp))
+	}
+	var sessionID int64
+	for i, r := range records {
+		e := exp[i]
+		if r.Op != e.op || r.Statement != e.stmt || r.RowsAffected != e.numRow || (r.Err != nil) != e.err {
+			t.Fatalf("audit record %d %v - expected %v", i, r, e)
+		}
+		if r.Fingerprint != driver.QueryFingerprint(e.stmt) {
+			t.Fatalf("audit record %d fingerprint %s - expected %s", i, r.Fingerprint, driver.QueryFingerprint(e.stmt))
+		}
+		if r.User != "user" || r.CorrelationID == "" {
+			t.Fatalf("audit record %d user %s correlation id %s", i, r.User, r.CorrelationID)
+		}
+		if i == 0 {
+			sessionID = r.SessionID
+		}
+		if r.SessionID != sessionID {
+			t.Fatalf("audit record %d session id %d - expected %d", i, r.SessionID, sessionID)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// ErrBatchStatement is returned for statements not supported by batch executions (see Conn.SendBatch).
+var ErrBatchStatement = errors.New("statement is not supported by batch executions")
+
+// BatchItem is a statement of a batch (see Conn.SendBatch).
+type BatchItem struct {
+	Query string
+	Args  []interface{}
+}
+
+// BatchResult is the execution result of a batch statement (see Conn.SendBatch).
+type BatchResult struct {
+	RowsAffected int64
+	Err          error // database error of the statement (nil: statement executed successfully)
+}
+
+/*
+SendBatch executes the independent statements items with a minimal number of round trips: the requests of
+all statements are sent back-to-back before the replies are read. Statements with arguments are prepared
+in one round trip, executed in a second one and released afterwards.
+
+The statements are executed in the order of items and are committed per statement in auto commit mode.
+The results are returned per statement, so that an error of one statement does not prevent the execution
+of the other statements. Queries, procedure calls, statements with named, lob or output parameters
+and bulk executions are rejected with ErrBatchStatement.
+*/
+func (c *conn) SendBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	c.session.Lock()
+	defer c.session.Unlock()
+
+	if c.session.IsBad() {
+		return nil, driver.ErrBadConn
+	}
+	if c.session.InQuery() {
+		return nil, ErrNestedQuery
+	}
+
+	results := make([]BatchResult, len(items))
+	queries := make([]string, len(items))
+	prepIdx := make([]int, len(items)) // index of the prepared statement (-1: direct execution)
+	var prepQueries []string
+	for i, item := range items {
+		prepIdx[i] = -1
+		qd, err := p.NewQueryDescr(item.Query, c.scanner)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if k := qd.Kind(); k == p.QkSelect || k == p.QkCall || k == p.QkID || qd.IsBulk() || len(qd.NamedParams()) != 0 {
+			results[i].Err = fmt.Errorf("%w: %s", ErrBatchStatement, item.Query)
+			continue
+		}
+		queries[i] = qd.Query()
+		if len(item.Args) != 0 {
+			prepIdx[i] = len(prepQueries)
+			prepQueries = append(prepQueries, queries[i])
+		}
+	}
+
+	err := c.call(ctx, opBatch, "", func() error {
+		var prs []*p.PrepareResult
+		var prepErrs []error
+		if len(prepQueries) != 0 {
+			var err error
+			if prs, prepErrs, err = c.session.PrepareBatch(prepQueries); err != nil {
+				return err
+			}
+			defer func() {
+				ids := make([]uint64, 0, len(prs))
+				for _, pr := range prs {
+					if pr != nil {
+						ids = append(ids, pr.StmtID())
+					}
+				}
+				if len(ids) != 0 && !c.session.IsBad() {
+					c.session.DropStatementIDs(ids)
+				}
+			}()
+		}
+
+		execs := make([]p.BatchExec, 0, len(items))
+		idx := make([]int, 0, len(items)) // item index of executions
+		for i, item := range items {
+			if results[i].Err != nil {
+				continue
+			}
+			exec := p.BatchExec{Query: queries[i]}
+			if j := prepIdx[i]; j != -1 {
+				if prepErrs[j] != nil {
+					results[i].Err = prepErrs[j]
+					continue
+				}
+				exec.PR = prs[j]
+				nvs, err := batchArgs(exec.PR, item.Args)
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				exec.Args = nvs
+			}
+			execs = append(execs, exec)
+			idx = append(idx, i)
+		}
+		if len(execs) == 0 {
+			return nil
+		}
+
+		rs, execErrs, err := c.session.ExecBatch(execs)
+		if err != nil {
+			return err
+		}
+		for k, i := range idx {
+			if execErrs[k] != nil {
+				results[i].Err = execErrs[k]
+				continue
+			}
+			if n, err := rs[k].RowsAffected(); err == nil {
+				results[i].RowsAffected = n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// batchArgs converts the arguments args of a batch statement with prepare result pr.
+func batchArgs(pr *p.PrepareResult, args []interface{}) ([]driver.NamedValue, error) {
+	if len(args) != pr.NumField() {
+		return nil, fmt.Errorf("invalid number of arguments %d - %d expected", len(args), pr.NumField())
+	}
+	nvs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+		if err := convertNamedValue(pr, &nvs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return nvs, nil
+}
+
+// SendBatch executes the independent statements items with a minimal number of round trips (see Conn.SendBatch).
+func (c *NativeConn) SendBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	return c.conn.SendBatch(ctx, items)
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockBatch(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var inserted []string
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		inserted = append(inserted, args[0].(string))
+		return &drivertest.MockResult{RowsAffected: 1}, nil
+	}})
+	s.Handle("delete from t", &drivertest.MockStatement{RowsAffected: 3})
+	s.Handle("update t set x = ?", &drivertest.MockStatement{Err: &drivertest.MockError{Code: 301, Text: "unique constraint violated"}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	conn, err := connector.NativeConn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	items := []driver.BatchItem{
+		{Query: "insert into t values (?)", Args: []interface{}{"0"}},
+		{Query: "delete from t"},
+		{Query: "update t set x = ?", Args: []interface{}{"1"}},
+		{Query: "select 1 from dummy"},
+		{Query: "insert into t values (?)", Args: []interface{}{"1", "2"}},
+		{Query: "delete from u"},
+		{Query: "insert into u values (?)", Args: []interface{}{"1"}},
+	}
+	n := len(items)
+	for i := 1; i <= 40; i++ { // exceed pipeline depth
+		items = append(items, driver.BatchItem{Query: "insert into t values (?)", Args: []interface{}{strconv.Itoa(i)}})
+	}
+
+	results, err := conn.SendBatch(context.Background(), items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("number of results %d - expected %d", len(results), len(items))
+	}
+
+	var dbErr driver.Error
+	checks := []func(r driver.BatchResult) bool{
+		func(r driver.BatchResult) bool { return r.Err == nil && r.RowsAffected == 1 },
+		func(r driver.BatchResult) bool { return r.Err == nil && r.RowsAffected == 3 },
+		func(r driver.BatchResult) bool { return errors.As(r.Err, &dbErr) && dbErr.Code() == 301 },
+		func(r driver.BatchResult) bool { return errors.Is(r.Err, driver.ErrBatchStatement) },
+		func(r driver.BatchResult) bool { return r.Err != nil },
+		func(r driver.BatchResult) bool {
+			return errors.As(r.Err, &dbErr) && dbErr.Code() == drivertest.ErrorCodeInvalidStatement
+		},
+		func(r driver.BatchResult) bool {
+			return errors.As(r.Err, &dbErr) && dbErr.Code() == drivertest.ErrorCodeInvalidStatement
+		},
+	}
+	for i, check := range checks {
+		if !check(results[i]) {
+			t.Fatalf("statement %d: unexpected result %v", i, results[i])
+		}
+	}
+	for i, r := range results[n:] {
+		if r.Err != nil || r.RowsAffected != 1 {
+			t.Fatalf("statement %d: unexpected result %v", n+i, r)
+		}
+	}
+
+	mu.Lock()
+	if len(inserted) != 41 || inserted[0] != "0" || inserted[40] != "40" {
+		t.Fatalf("inserted %v", inserted)
+	}
+	mu.Unlock()
+
+	// connection is usable after the batch
+	if _, err := conn.Exec(context.Background(), "delete from t"); err != nil {
+		t.Fatal(err)
+	}
+}
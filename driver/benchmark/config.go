@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package benchmark
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+// Environment variables providing the defaults of the benchmark flags.
+const (
+	EnvDSN        = drivertest.EnvDSN
+	EnvFetchSize  = "GOHDBFETCHSIZE"
+	EnvBulkSize   = "GOHDBBULKSIZE"
+	EnvBufferSize = "GOHDBBUFFERSIZE"
+	EnvRows       = "GOHDBBENCHROWS"
+	EnvLobSize    = "GOHDBBENCHLOBSIZE"
+)
+
+// Benchmark defaults.
+const (
+	DefaultRows    = 10000
+	DefaultLobSize = 1 << 20 // 1 MB
+)
+
+// Config is the benchmark configuration. Zero connector settings (fetch size, bulk size, buffer size)
+// keep the driver defaults.
+type Config struct {
+	DSN        string
+	FetchSize  int
+	BulkSize   int
+	BufferSize int // read and write buffer size
+	Rows       int // number of rows of fetch and bulk insert benchmarks
+	LobSize    int // lob size in bytes of lob benchmarks
+}
+
+func envInt(name string, defValue int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return defValue
+	}
+	return v
+}
+
+// NewConfig returns a new Config initialized by the benchmark environment variables.
+func NewConfig() *Config {
+	return &Config{
+		DSN:        os.Getenv(EnvDSN),
+		FetchSize:  envInt(EnvFetchSize, 0),
+		BulkSize:   envInt(EnvBulkSize, 0),
+		BufferSize: envInt(EnvBufferSize, 0),
+		Rows:       envInt(EnvRows, DefaultRows),
+		LobSize:    envInt(EnvLobSize, DefaultLobSize),
+	}
+}
+
+// RegisterFlags registers the benchmark flags in flag set fs using the actual configuration values as defaults.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.DSN, "dsn", c.DSN, "database dsn")
+	fs.IntVar(&c.FetchSize, "fetchSize", c.FetchSize, "connector fetch size (0: driver default)")
+	fs.IntVar(&c.BulkSize, "bulkSize", c.BulkSize, "connector bulk size (0: driver default)")
+	fs.IntVar(&c.BufferSize, "bufferSize", c.BufferSize, "connector read and write buffer size (0: driver default)")
+	fs.IntVar(&c.Rows, "rows", c.Rows, "number of rows of fetch and bulk insert benchmarks")
+	fs.IntVar(&c.LobSize, "lobSize", c.LobSize, "lob size in bytes of lob benchmarks")
+}
+
+// NewConnector returns a new connector with the configured settings.
+func (c *Config) NewConnector() (*driver.Connector, error) {
+	connector, err := driver.NewDSNConnector(c.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if c.FetchSize != 0 {
+		if err := connector.SetFetchSize(c.FetchSize); err != nil {
+			return nil, err
+		}
+	}
+	if c.BulkSize != 0 {
+		if err := connector.SetBulkSize(c.BulkSize); err != nil {
+			return nil, err
+		}
+	}
+	if c.BufferSize != 0 {
+		if err := connector.SetReadBufferSize(c.BufferSize); err != nil {
+			return nil, err
+		}
+		if err := connector.SetWriteBufferSize(c.BufferSize); err != nil {
+			return nil, err
+		}
+	}
+	return connector, nil
+}
+
+func (c *Config) String() string {
+	return fmt.Sprintf("fetchSize %d bulkSize %d bufferSize %d rows %d lobSize %d", c.FetchSize, c.BulkSize, c.BufferSize, c.Rows, c.LobSize)
+}
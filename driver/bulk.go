@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// BulkRowError is the error returned for a bulk row rejected before it is added to the bulk buffer.
+// Rows buffered before stay buffered and are executed with the next flush.
+// BulkRowError is also the error of a row rejected by the database server (see BulkError).
+type BulkRowError struct {
+	Row int   // Index of the row in the bulk buffer.
+	Err error // Error of the row.
+}
+
+func (e *BulkRowError) Error() string { return fmt.Sprintf("bulk row %d: %s", e.Row, e.Err) }
+
+// Unwrap returns the nested error.
+func (e *BulkRowError) Unwrap() error { return e.Err }
+
+/*
+BulkError is the error returned by the flush of a bulk buffer if rows are rejected by the database server.
+
+RowErrors contain the errors of the rejected rows, where the row index refers to the flushed bulk buffer and the
+row error is a database error (see Error). RowsAffected is the number of rows not rejected, which is only reported
+if the execution is continued despite of rejected rows (see Connector.SetBulkContinueOnError).
+BulkError wraps the database error of the execution, so that errors.As(err, &dbErr) for a driver.Error dbErr still
+returns all errors of the execution.
+
+Example:
+
+	if _, err := stmt.Exec(); err != nil { // flush
+		var bulkErr *driver.BulkError
+		if errors.As(err, &bulkErr) {
+			for _, rowErr := range bulkErr.RowErrors {
+				log.Printf("row %d rejected: %s", rowErr.Row, rowErr.Err)
+			}
+		}
+	}
+*/
+type BulkError struct {
+	RowErrors    []*BulkRowError // Errors of the rejected rows.
+	RowsAffected int64           // Number of rows not rejected.
+	err          error
+}
+
+// newBulkError returns a BulkError for database error err and the execution result r, if rows are rejected.
+// Otherwise err is returned.
+func newBulkError(err error, r driver.Result) error {
+	errs := p.StmtErrors(err)
+	if errs == nil {
+		return err
+	}
+	bulkErr := &BulkError{RowErrors: make([]*BulkRowError, len(errs)), err: err}
+	for i, rowErr := range errs {
+		bulkErr.RowErrors[i] = &BulkRowError{Row: rowErr.(Error).StmtNo(), Err: rowErr}
+	}
+	if r != nil {
+		bulkErr.RowsAffected, _ = r.RowsAffected()
+	}
+	return bulkErr
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("bulk execution: %d rows rejected: %s", len(e.RowErrors), e.err)
+}
+
+// Unwrap returns the database error.
+func (e *BulkError) Unwrap() error { return e.err }
+
+// checkBulkRow checks that the encoded size of the bulk row args fits into an execute request of maximal
+// packet size (see Connector.SetMaxPacketSize), so that an oversized row is rejected when it is added and
+// does not fail the execution of the whole bulk buffer.
+func (s *stmt) checkBulkRow(args []driver.NamedValue) error {
+	maxSize := s.session.MaxRowSize()
+	if maxSize == 0 {
+		return nil
+	}
+	if size := s.pr.RowSize(args); size > maxSize {
+		return &BulkRowError{Row: s.bulkNum, Err: &LimitError{Name: "row size", Value: size, Limit: maxSize, Max: true}}
+	}
+	return nil
+}
+
+// A BulkDefault returns the value replacing a nil argument of a bulk statement.
+type BulkDefault func() (interface{}, error)
+
+// BulkValue returns a BulkDefault replacing nil arguments by the constant value v.
+func BulkValue(v interface{}) BulkDefault { return func() (interface{}, error) { return v, nil } }
+
+// BulkSequence returns a BulkDefault replacing nil arguments by the values of a sequence starting with start
+// incremented by increment. BulkSequence is safe for concurrent use.
+func BulkSequence(start, increment int64) BulkDefault {
+	next := start - increment
+	return func() (interface{}, error) { return atomic.AddInt64(&next, increment), nil }
+}
+
+// BulkNow is a BulkDefault replacing nil arguments by the current time.
+func BulkNow() (interface{}, error) { return time.Now(), nil }
+
+/*
+BulkDefaults maps the parameter indexes (starting with 0) of a bulk statement to the defaults of nil arguments.
+
+The defaults are passed via the context of the statement preparation (see WithBulkDefaults) and are evaluated on client
+side before a row is added to the bulk buffer, so that nil arguments e.g. of columns missing in an ETL source can be
+replaced by column defaults or generated values without mapping code:
+
+	ctx := driver.WithBulkDefaults(ctx, driver.BulkDefaults{0: driver.BulkSequence(1, 1), 2: driver.BulkNow})
+	stmt, err := conn.PrepareContext(ctx, "bulk insert into t values (?, ?, ?)")
+
+As database/sql prepares a statement again on other connections with the context of the execution, bulk statements
+with defaults should be prepared on a single connection (sql.Conn or sql.Tx).
+*/
+type BulkDefaults map[int]BulkDefault
+
+type bulkDefaultsCtxKey struct{}
+
+// WithBulkDefaults returns a context with the bulk defaults of the statements prepared with the context.
+func WithBulkDefaults(ctx context.Context, defaults BulkDefaults) context.Context {
+	return context.WithValue(ctx, bulkDefaultsCtxKey{}, defaults)
+}
+
+// BulkDefaultsFromContext returns the bulk defaults of ctx.
+func BulkDefaultsFromContext(ctx context.Context) (BulkDefaults, bool) {
+	defaults, ok := ctx.Value(bulkDefaultsCtxKey{}).(BulkDefaults)
+	return defaults, ok
+}
+
+// checkBulkDefaults checks that the parameter indexes of defaults are valid for a statement with numField parameters.
+func checkBulkDefaults(defaults BulkDefaults, numField int) error {
+	for idx := range defaults {
+		if idx < 0 || idx >= numField {
+			return fmt.Errorf("invalid bulk default parameter index %d - expected range [0, %d)", idx, numField)
+		}
+	}
+	return nil
+}
+
+// applyBulkDefaults replaces the nil bulk row args by the values of the statement bulk defaults.
+func (s *stmt) applyBulkDefaults(args []driver.NamedValue) error {
+	for idx, d := range s.defaults {
+		if args[idx].Value != nil {
+			continue
+		}
+		v, err := d()
+		if err == nil {
+			v, err = s.pr.PrmField(idx).Converter().Convert(v)
+		}
+		if err != nil {
+			return &BulkRowError{Row: s.bulkNum, Err: fmt.Errorf("default of parameter %d: %w", idx, err)}
+		}
+		args[idx].Value = v
+	}
+	return nil
+}
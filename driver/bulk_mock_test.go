@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockBulkRowSize(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var numRow int32
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		atomic.AddInt32(&numRow, int32(len(args)))
+		return &drivertest.MockResult{RowsAffected: int64(len(args))}, nil
+	}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetMaxPacketSize(driver.MinMaxPacketSize); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	stmt, err := db.Prepare("insert into t values (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.Exec("small", driver.NoFlush); err != nil {
+		t.Fatal(err)
+	}
+	_, err = stmt.Exec(strings.Repeat("x", driver.MinMaxPacketSize), driver.NoFlush)
+	var rowErr *driver.BulkRowError
+	if !errors.As(err, &rowErr) || !errors.Is(err, driver.ErrLimitExceeded) {
+		t.Fatalf("error %v - expected bulk row error", err)
+	}
+	if rowErr.Row != 1 {
+		t.Fatalf("bulk row %d - expected %d", rowErr.Row, 1)
+	}
+	if _, err := stmt.Exec("small", driver.Flush); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&numRow); n != 2 {
+		t.Fatalf("rows executed %d - expected %d", n, 2)
+	}
+}
+
+func TestMockBulkDefaults(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var args []interface{}
+	s.Handle("insert into t values (?, ?, ?)", &drivertest.MockStatement{
+		Params: []string{"INTEGER", "NVARCHAR", "TIMESTAMP"},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			args = a
+			return &drivertest.MockResult{RowsAffected: int64(len(a) / 3)}, nil
+		},
+	})
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// invalid parameter index
+	if _, err := conn.PrepareContext(driver.WithBulkDefaults(ctx, driver.BulkDefaults{3: driver.BulkNow}), "insert into t values (?, ?, ?)"); err == nil {
+		t.Fatal("error expected for invalid parameter index")
+	}
+
+	defaults := driver.BulkDefaults{0: driver.BulkSequence(10, 5), 1: driver.BulkValue("n/a"), 2: driver.BulkNow}
+	stmt, err := conn.PrepareContext(driver.WithBulkDefaults(ctx, defaults), "insert into t values (?, ?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	ts := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Now().Add(-time.Second)
+
+	rows := [][]interface{}{{1, "a", ts}, {nil, nil, ts}, {2, nil, nil}, {nil, "b", ts}}
+	for _, row := range rows {
+		if _, err := stmt.Exec(append(row, driver.NoFlush)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(args) != 3*len(rows) {
+		t.Fatalf("number of args %d - expected %d", len(args), 3*len(rows))
+	}
+	expIDs := []int64{1, 10, 2, 15}
+	expNames := []string{"a", "n/a", "n/a", "b"}
+	for i := range rows {
+		if id := args[3*i]; id != expIDs[i] {
+			t.Fatalf("row %d id %v - expected %d", i, id, expIDs[i])
+		}
+		if name := args[3*i+1]; name != expNames[i] {
+			t.Fatalf("row %d name %v - expected %s", i, name, expNames[i])
+		}
+		tv, ok := args[3*i+2].(time.Time)
+		if !ok {
+			t.Fatalf("row %d timestamp %v - expected time value", i, args[3*i+2])
+		}
+		if i != 2 && !tv.Equal(ts) {
+			t.Fatalf("row %d timestamp %s - expected %s", i, tv, ts)
+		}
+		if i == 2 && tv.Before(start) {
+			t.Fatalf("row %d timestamp %s - expected current time", i, tv)
+		}
+	}
+}
+
+func TestMockBulkError(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const samples = 200
+	rejected := []int{10, 100, 150}
+	value := strings.Repeat("x", 1000)
+
+	var numRequest int32
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Params: []string{"NVARCHAR"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		atomic.AddInt32(&numRequest, 1)
+		result := &drivertest.MockResult{}
+		for i, arg := range args {
+			if strings.HasPrefix(arg.(string), "bad") {
+				if result.RowErrors == nil {
+					result.RowErrors = map[int]*drivertest.MockError{}
+				}
+				result.RowErrors[i] = &drivertest.MockError{Code: 301, Text: "unique constraint violated"}
+			}
+		}
+		result.RowsAffected = int64(len(args))
+		return result, nil
+	}})
+
+	testBulkError := func(continueOnError bool, expRows []int, expRowsAffected int64) {
+		connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+		if err := connector.SetMaxPacketSize(driver.MinMaxPacketSize); err != nil {
+			t.Fatal(err)
+		}
+		if err := connector.SetBulkContinueOnError(continueOnError); err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		stmt, err := db.Prepare("bulk insert into t values (?)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stmt.Close()
+
+		atomic.StoreInt32(&numRequest, 0)
+		for i := 0; i < samples; i++ {
+			v := value
+			for _, row := range rejected {
+				if i == row {
+					v = "bad" + value
+				}
+			}
+			if _, err := stmt.Exec(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err = stmt.Exec() // flush
+
+		var bulkErr *driver.BulkError
+		if !errors.As(err, &bulkErr) {
+			t.Fatalf("error %v - expected bulk error", err)
+		}
+		if len(bulkErr.RowErrors) != len(expRows) {
+			t.Fatalf("number of row errors %d - expected %d", len(bulkErr.RowErrors), len(expRows))
+		}
+		for i, rowErr := range bulkErr.RowErrors {
+			var dbErr driver.Error
+			if rowErr.Row != expRows[i] || !errors.As(rowErr, &dbErr) || dbErr.Code() != 301 || dbErr.NumError() != 1 {
+				t.Fatalf("row error %v - expected database error of row %d", rowErr, expRows[i])
+			}
+		}
+		if bulkErr.RowsAffected != expRowsAffected {
+			t.Fatalf("rows affected %d - expected %d", bulkErr.RowsAffected, expRowsAffected)
+		}
+		var dbErr driver.Error
+		if !errors.As(err, &dbErr) || dbErr.NumError() != len(expRows) {
+			t.Fatalf("error %v - expected database error with %d errors", err, len(expRows))
+		}
+		if n := atomic.LoadInt32(&numRequest); continueOnError && n < 2 {
+			t.Fatalf("number of execute requests %d - expected split bulk execution", n)
+		}
+	}
+
+	t.Run("stopOnError", func(t *testing.T) { testBulkError(false, rejected[:1], 0) })
+	t.Run("continueOnError", func(t *testing.T) { testBulkError(true, rejected, samples-int64(len(rejected))) })
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/sqltrace"
+)
+
+// A Preparer is a database object preparing statements like sql.DB, sql.Conn or sql.Tx.
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+/*
+BulkInserter inserts rows column-wise into a database table.
+
+The rows are passed as one slice per column. Slices of the go types matching the column type ([]int64, []int32, []int
+for integer columns, []float64 for floating point columns, []string and [][]byte for character and binary columns
+and []bool for boolean columns) are encoded directly into the execute requests without the conversion of the single
+values, which avoids the allocations of row-wise bulk inserts for large loads. Slices of other types (e.g.
+[]interface{}, []time.Time or []sql.NullString) are converted value by value like the arguments of an insert
+statement. Nil values of [][]byte columns are inserted as null values. Lob columns are not supported.
+
+Example:
+
+	ins, err := driver.NewBulkInserter(ctx, db, "orders", "id", "customer", "amount")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ins.Close()
+	n, err := ins.Insert(ctx, []int64{1, 2}, []string{"alice", "bob"}, []float64{10.5, 4.25})
+
+The rows of an Insert call are inserted by a single statement execution split into multiple execute requests if the
+maximal packet size is exceeded (see Connector.SetMaxPacketSize). Rows rejected by the database server are reported
+like the rows of bulk statements (see BulkError).
+*/
+type BulkInserter struct {
+	stmt      *sql.Stmt
+	numColumn int
+}
+
+/*
+NewBulkInserter prepares the insert statement of a BulkInserter for table and columns.
+
+table and columns are used in the insert statement as is, so that schema qualified or delimited names need to be
+given in SQL syntax (see Identifier).
+*/
+func NewBulkInserter(ctx context.Context, p Preparer, table string, columns ...string) (*BulkInserter, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("bulk inserter: no columns")
+	}
+	query := fmt.Sprintf("insert into %s (%s) values (%s?)", table, strings.Join(columns, ", "), strings.Repeat("?, ", len(columns)-1))
+	stmt, err := p.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkInserter{stmt: stmt, numColumn: len(columns)}, nil
+}
+
+// Insert inserts the rows given as one slice per column and returns the number of inserted rows.
+// All column slices need to have the same length.
+func (b *BulkInserter) Insert(ctx context.Context, columns ...interface{}) (int64, error) {
+	if len(columns) != b.numColumn {
+		return 0, fmt.Errorf("invalid number of columns %d - %d expected", len(columns), b.numColumn)
+	}
+	r, err := b.stmt.ExecContext(ctx, &bulkColumns{columns: columns})
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// Close closes the insert statement.
+func (b *BulkInserter) Close() error { return b.stmt.Close() }
+
+// bulkColumns is the argument of a column-wise bulk execution (see BulkInserter).
+type bulkColumns struct {
+	columns []interface{}
+}
+
+func bulkColumnsArg(args []driver.NamedValue) (*bulkColumns, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	cols, ok := args[0].Value.(*bulkColumns)
+	return cols, ok
+}
+
+// execColumns executes the statement column-wise for the rows of the argument columns.
+func (s *stmt) execColumns(ctx context.Context, cols *bulkColumns) (r driver.Result, err error) {
+	s.session.Lock()
+	defer s.session.Unlock()
+
+	if s.session.IsBad() {
+		return nil, driver.ErrBadConn
+	}
+	if s.session.InQuery() {
+		return nil, ErrNestedQuery
+	}
+	if s.bulk || s.pr.IsProcedureCall() {
+		return nil, errors.New("column-wise bulk executions are not supported for bulk statements and procedure calls")
+	}
+
+	ctx, _, err = s.conn.beforeExec(ctx, s.query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if sqltrace.On() {
+		sqltrace.Log(s.conn.logger, s.query, s.conn.traceKeyvals(ctx, "columns", len(cols.columns))...)
+	}
+
+	convert := func(idx int, v interface{}) (interface{}, error) {
+		nv := &driver.NamedValue{Ordinal: idx + 1, Value: v}
+		if err := convertNamedValue(s.pr, nv); err != nil {
+			return nil, err
+		}
+		return nv.Value, nil
+	}
+
+	start := time.Now()
+
+	err = s.conn.call(ctx, opExec, s.query, func() (err error) {
+		s.conn.traceStmtID(s.pr.StmtID())
+		err = s.reprepared(func() (err error) { r, err = s.session.ExecColumns(s.pr, cols.columns, convert); return err })
+		if err != nil {
+			err = newBulkError(err, r)
+		}
+		return err
+	})
+	s.conn.setStatementInfo(ctx, s.pr.StmtID())
+	if err != nil {
+		s.conn.afterExec(ctx, s.query, nil, start, nil, err)
+		return nil, err
+	}
+	s.conn.afterExec(ctx, s.query, nil, start, r, nil)
+	return r, nil
+}
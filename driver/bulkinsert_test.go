@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockBulkInserter(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const insertQuery = "insert into t (id, name, amount, flag, ts, data) values (?, ?, ?, ?, ?, ?)"
+	const numColumn = 6
+
+	var mu sync.Mutex
+	var numRequest int
+	var values []interface{}
+	s.Handle(insertQuery, &drivertest.MockStatement{Params: []string{"BIGINT", "NVARCHAR", "DOUBLE", "BOOLEAN", "TIMESTAMP", "VARBINARY"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		numRequest++
+		values = append(values, args...)
+		return &drivertest.MockResult{RowsAffected: int64(len(args) / numColumn)}, nil
+	}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetMaxPacketSize(driver.MinMaxPacketSize); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	ins, err := driver.NewBulkInserter(ctx, db, "t", "id", "name", "amount", "flag", "ts", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ins.Close()
+
+	const numRow = 2000 // exceeding the minimal packet size
+	ts := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	ids := make([]int64, numRow)
+	names := make([]string, numRow)
+	amounts := make([]float64, numRow)
+	flags := make([]bool, numRow)
+	tss := make([]time.Time, numRow)
+	data := make([][]byte, numRow)
+	for i := 0; i < numRow; i++ {
+		ids[i] = int64(i)
+		names[i] = fmt.Sprintf("name %d 世界", i)
+		amounts[i] = float64(i) / 4
+		flags[i] = i%2 == 0
+		tss[i] = ts.Add(time.Duration(i) * time.Second)
+		if i%3 != 0 { // nil: null value
+			data[i] = []byte{byte(i), byte(i >> 8)}
+		}
+	}
+
+	n, err := ins.Insert(ctx, ids, names, amounts, flags, tss, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != numRow {
+		t.Fatalf("rows affected %d - expected %d", n, numRow)
+	}
+
+	mu.Lock()
+	if numRequest < 2 {
+		t.Fatalf("number of execute requests %d - expected the execution to be split", numRequest)
+	}
+	if len(values) != numRow*numColumn {
+		t.Fatalf("number of values %d - expected %d", len(values), numRow*numColumn)
+	}
+	for i := 0; i < numRow; i++ {
+		row := values[i*numColumn : (i+1)*numColumn]
+		var expData interface{}
+		if data[i] != nil {
+			expData = data[i]
+		}
+		exp := []interface{}{ids[i], names[i], amounts[i], flags[i], tss[i], expData}
+		if !reflect.DeepEqual(row, exp) {
+			t.Fatalf("row %d: %v - expected %v", i, row, exp)
+		}
+	}
+	mu.Unlock()
+
+	// converted columns
+	if _, err := ins.Insert(ctx, []interface{}{int32(1)}, []sql.NullString{{String: "a", Valid: true}}, []float32{1}, []bool{true}, []interface{}{ts}, [][]byte{nil}); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid columns
+	invalidColumns := [][]interface{}{
+		{ids, names}, // invalid number of columns
+		{ids[:1], names, amounts, flags, tss, data},                           // invalid number of rows
+		{ids, names, amounts, flags, tss, 42},                                 // no slice
+		{[]string{"x"}, names[:1], amounts[:1], flags[:1], tss[:1], data[:1]}, // invalid value
+	}
+	for _, columns := range invalidColumns {
+		if _, err := ins.Insert(ctx, columns...); err == nil {
+			t.Fatalf("columns %T: error expected", columns)
+		}
+	}
+}
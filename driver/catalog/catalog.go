@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package catalog provides typed access to the database catalog (SYS views) like tables, columns,
+primary keys, procedures and procedure parameters.
+
+Object names are passed and returned as stored in the catalog, i.e. names of objects created
+with simple identifiers are upper case (see driver.ParseIdentifier). An empty schema name
+selects the current schema of the session.
+*/
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// A Table describes a database table.
+type Table struct {
+	Schema   string
+	Name     string
+	Type     string // COLUMN or ROW
+	Comments string
+}
+
+// A Column describes a table column.
+type Column struct {
+	Schema   string
+	Table    string
+	Name     string
+	Position int
+	DataType string        // database type name (e.g. NVARCHAR)
+	Length   int           // length or precision
+	Scale    sql.NullInt64 // scale of decimal types
+	Nullable bool
+	Default  sql.NullString // default value
+	Identity bool           // identity column (generated by default or always as identity)
+	Comments string
+}
+
+// A PrimaryKey describes the primary key of a table.
+type PrimaryKey struct {
+	Schema  string
+	Table   string
+	Name    string   // constraint name
+	Columns []string // in key order
+}
+
+// A Procedure describes a stored procedure.
+type Procedure struct {
+	Schema         string
+	Name           string
+	NumInputParam  int
+	NumOutputParam int
+	NumInOutParam  int
+	NumResultSet   int
+	ReadOnly       bool
+	Comments       string
+}
+
+// Parameter modes.
+const (
+	ModeIn    = "IN"
+	ModeOut   = "OUT"
+	ModeInOut = "INOUT"
+)
+
+// A Parameter describes a parameter of a stored procedure.
+type Parameter struct {
+	Schema    string
+	Procedure string
+	Name      string
+	Position  int
+	Mode      string // ModeIn, ModeOut or ModeInOut
+	DataType  string // database type name (TABLE_TYPE for table parameters)
+	Length    int
+	Scale     sql.NullInt64
+	TableType string // qualified name of the table type of table parameters (see driver.QualifiedName)
+	Default   bool   // parameter has a default value
+}
+
+// schemaCond returns the schema condition and the query arguments.
+func schemaCond(schema string, args ...interface{}) (string, []interface{}) {
+	if schema == "" {
+		return "schema_name = current_schema", args
+	}
+	return "schema_name = ?", append([]interface{}{schema}, args...)
+}
+
+func isTrue(s string) bool { return s == "TRUE" }
+
+// query executes query and calls scan for each row.
+func query(ctx context.Context, q Queryer, query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Tables returns the tables of schema ordered by name.
+func Tables(ctx context.Context, q Queryer, schema string) ([]*Table, error) {
+	cond, args := schemaCond(schema)
+	var tables []*Table
+	err := query(ctx, q, fmt.Sprintf("select schema_name, table_name, table_type, ifnull(comments, '') from sys.tables where %s and is_user_defined_type = 'FALSE' order by table_name", cond), args, func(rows *sql.Rows) error {
+		t := &Table{}
+		if err := rows.Scan(&t.Schema, &t.Name, &t.Type, &t.Comments); err != nil {
+			return err
+		}
+		tables = append(tables, t)
+		return nil
+	})
+	return tables, err
+}
+
+// Columns returns the columns of table ordered by position.
+func Columns(ctx context.Context, q Queryer, schema, table string) ([]*Column, error) {
+	cond, args := schemaCond(schema, table)
+	var cols []*Column
+	err := query(ctx, q, fmt.Sprintf("select schema_name, table_name, column_name, position, data_type_name, length, scale, is_nullable, default_value, ifnull(generation_type, ''), ifnull(comments, '') from sys.table_columns where %s and table_name = ? order by position", cond), args, func(rows *sql.Rows) error {
+		c := &Column{}
+		var nullable, generationType string
+		if err := rows.Scan(&c.Schema, &c.Table, &c.Name, &c.Position, &c.DataType, &c.Length, &c.Scale, &nullable, &c.Default, &generationType, &c.Comments); err != nil {
+			return err
+		}
+		c.Nullable = isTrue(nullable)
+		c.Identity = generationType != ""
+		cols = append(cols, c)
+		return nil
+	})
+	return cols, err
+}
+
+// PrimaryKeyOf returns the primary key of table or nil if the table does not have a primary key.
+func PrimaryKeyOf(ctx context.Context, q Queryer, schema, table string) (*PrimaryKey, error) {
+	cond, args := schemaCond(schema, table)
+	var pk *PrimaryKey
+	err := query(ctx, q, fmt.Sprintf("select schema_name, table_name, constraint_name, column_name from sys.constraints where %s and table_name = ? and is_primary_key = 'TRUE' order by position", cond), args, func(rows *sql.Rows) error {
+		var schema, table, name, column string
+		if err := rows.Scan(&schema, &table, &name, &column); err != nil {
+			return err
+		}
+		if pk == nil {
+			pk = &PrimaryKey{Schema: schema, Table: table, Name: name}
+		}
+		pk.Columns = append(pk.Columns, column)
+		return nil
+	})
+	return pk, err
+}
+
+// Procedures returns the procedures of schema ordered by name.
+func Procedures(ctx context.Context, q Queryer, schema string) ([]*Procedure, error) {
+	cond, args := schemaCond(schema)
+	var procs []*Procedure
+	err := query(ctx, q, fmt.Sprintf("select schema_name, procedure_name, input_parameter_count, output_parameter_count, inout_parameter_count, result_set_count, is_read_only, ifnull(comments, '') from sys.procedures where %s order by procedure_name", cond), args, func(rows *sql.Rows) error {
+		p := &Procedure{}
+		var readOnly string
+		if err := rows.Scan(&p.Schema, &p.Name, &p.NumInputParam, &p.NumOutputParam, &p.NumInOutParam, &p.NumResultSet, &readOnly, &p.Comments); err != nil {
+			return err
+		}
+		p.ReadOnly = isTrue(readOnly)
+		procs = append(procs, p)
+		return nil
+	})
+	return procs, err
+}
+
+// Parameters returns the parameters of procedure ordered by position.
+func Parameters(ctx context.Context, q Queryer, schema, procedure string) ([]*Parameter, error) {
+	cond, args := schemaCond(schema, procedure)
+	var prms []*Parameter
+	err := query(ctx, q, fmt.Sprintf("select schema_name, procedure_name, parameter_name, position, parameter_type, data_type_name, length, scale, ifnull(table_type_schema, ''), ifnull(table_type_name, ''), has_default_value from sys.procedure_parameters where %s and procedure_name = ? order by position", cond), args, func(rows *sql.Rows) error {
+		p := &Parameter{}
+		var tableTypeSchema, tableTypeName, hasDefault string
+		if err := rows.Scan(&p.Schema, &p.Procedure, &p.Name, &p.Position, &p.Mode, &p.DataType, &p.Length, &p.Scale, &tableTypeSchema, &tableTypeName, &hasDefault); err != nil {
+			return err
+		}
+		if tableTypeName != "" {
+			p.TableType = driver.QualifiedName(driver.Identifier(tableTypeSchema), driver.Identifier(tableTypeName))
+		}
+		p.Default = isTrue(hasDefault)
+		prms = append(prms, p)
+		return nil
+	})
+	return prms, err
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package catalog_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/catalog"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testCatalogColumns(names ...string) []drivertest.MockColumn {
+	cols := make([]drivertest.MockColumn, len(names))
+	for i, name := range names {
+		typeName := "NVARCHAR"
+		if name[0] == '#' { // integer column
+			name, typeName = name[1:], "INTEGER"
+		}
+		cols[i] = drivertest.MockColumn{Name: name, TypeName: typeName}
+	}
+	return cols
+}
+
+func testTables(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("select schema_name, table_name, table_type, ifnull(comments, '') from sys.tables where schema_name = current_schema and is_user_defined_type = 'FALSE' order by table_name", &drivertest.MockStatement{
+		Columns: testCatalogColumns("SCHEMA_NAME", "TABLE_NAME", "TABLE_TYPE", "COMMENTS"),
+		Rows:    [][]interface{}{{"S", "A", "COLUMN", ""}, {"S", "b", "ROW", "table b"}},
+	})
+	tables, err := catalog.Tables(context.Background(), db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*catalog.Table{{Schema: "S", Name: "A", Type: "COLUMN"}, {Schema: "S", Name: "b", Type: "ROW", Comments: "table b"}}
+	if !reflect.DeepEqual(tables, exp) {
+		t.Fatalf("tables %v - expected %v", tables, exp)
+	}
+}
+
+func testColumns(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("select schema_name, table_name, column_name, position, data_type_name, length, scale, is_nullable, default_value, ifnull(generation_type, ''), ifnull(comments, '') from sys.table_columns where schema_name = ? and table_name = ? order by position", &drivertest.MockStatement{
+		Columns: testCatalogColumns("SCHEMA_NAME", "TABLE_NAME", "COLUMN_NAME", "#POSITION", "DATA_TYPE_NAME", "#LENGTH", "#SCALE", "IS_NULLABLE", "DEFAULT_VALUE", "GENERATION_TYPE", "COMMENTS"),
+		Rows: [][]interface{}{
+			{"S", "A", "ID", int32(1), "BIGINT", int32(19), int32(0), "FALSE", nil, "BY DEFAULT AS IDENTITY", ""},
+			{"S", "A", "NAME", int32(2), "NVARCHAR", int32(20), nil, "TRUE", "n/a", "", "name"},
+		},
+	})
+	cols, err := catalog.Columns(context.Background(), db, "S", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*catalog.Column{
+		{Schema: "S", Table: "A", Name: "ID", Position: 1, DataType: "BIGINT", Length: 19, Scale: sql.NullInt64{Valid: true}, Identity: true},
+		{Schema: "S", Table: "A", Name: "NAME", Position: 2, DataType: "NVARCHAR", Length: 20, Nullable: true, Default: sql.NullString{String: "n/a", Valid: true}, Comments: "name"},
+	}
+	if !reflect.DeepEqual(cols, exp) {
+		t.Fatalf("columns %v - expected %v", cols, exp)
+	}
+}
+
+func testPrimaryKey(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("select schema_name, table_name, constraint_name, column_name from sys.constraints where schema_name = current_schema and table_name = ? and is_primary_key = 'TRUE' order by position", &drivertest.MockStatement{
+		Columns: testCatalogColumns("SCHEMA_NAME", "TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME"),
+		Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			if args[0] != "A" {
+				return &drivertest.MockResult{}, nil
+			}
+			return &drivertest.MockResult{Rows: [][]interface{}{{"S", "A", "PK_A", "K1"}, {"S", "A", "PK_A", "K2"}}}, nil
+		},
+	})
+	pk, err := catalog.PrimaryKeyOf(context.Background(), db, "", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &catalog.PrimaryKey{Schema: "S", Table: "A", Name: "PK_A", Columns: []string{"K1", "K2"}}
+	if !reflect.DeepEqual(pk, exp) {
+		t.Fatalf("primary key %v - expected %v", pk, exp)
+	}
+	if pk, err = catalog.PrimaryKeyOf(context.Background(), db, "", "B"); err != nil || pk != nil {
+		t.Fatalf("primary key %v (%v) - expected nil", pk, err)
+	}
+}
+
+func testProcedures(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("select schema_name, procedure_name, input_parameter_count, output_parameter_count, inout_parameter_count, result_set_count, is_read_only, ifnull(comments, '') from sys.procedures where schema_name = current_schema order by procedure_name", &drivertest.MockStatement{
+		Columns: testCatalogColumns("SCHEMA_NAME", "PROCEDURE_NAME", "#INPUT_PARAMETER_COUNT", "#OUTPUT_PARAMETER_COUNT", "#INOUT_PARAMETER_COUNT", "#RESULT_SET_COUNT", "IS_READ_ONLY", "COMMENTS"),
+		Rows:    [][]interface{}{{"S", "P", int32(1), int32(2), int32(0), int32(1), "TRUE", ""}},
+	})
+	s.Handle("select schema_name, procedure_name, parameter_name, position, parameter_type, data_type_name, length, scale, ifnull(table_type_schema, ''), ifnull(table_type_name, ''), has_default_value from sys.procedure_parameters where schema_name = current_schema and procedure_name = ? order by position", &drivertest.MockStatement{
+		Columns: testCatalogColumns("SCHEMA_NAME", "PROCEDURE_NAME", "PARAMETER_NAME", "#POSITION", "PARAMETER_TYPE", "DATA_TYPE_NAME", "#LENGTH", "#SCALE", "TABLE_TYPE_SCHEMA", "TABLE_TYPE_NAME", "HAS_DEFAULT_VALUE"),
+		Rows: [][]interface{}{
+			{"S", "P", "A", int32(1), "IN", "INTEGER", int32(10), int32(0), "", "", "TRUE"},
+			{"S", "P", "T", int32(2), "OUT", "TABLE_TYPE", int32(0), nil, "S", "_SYS_SS_TBL_1", "FALSE"},
+		},
+	})
+
+	ctx := context.Background()
+	procs, err := catalog.Procedures(ctx, db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expProcs := []*catalog.Procedure{{Schema: "S", Name: "P", NumInputParam: 1, NumOutputParam: 2, NumResultSet: 1, ReadOnly: true}}
+	if !reflect.DeepEqual(procs, expProcs) {
+		t.Fatalf("procedures %v - expected %v", procs, expProcs)
+	}
+	prms, err := catalog.Parameters(ctx, db, "", "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expPrms := []*catalog.Parameter{
+		{Schema: "S", Procedure: "P", Name: "A", Position: 1, Mode: catalog.ModeIn, DataType: "INTEGER", Length: 10, Scale: sql.NullInt64{Valid: true}, Default: true},
+		{Schema: "S", Procedure: "P", Name: "T", Position: 2, Mode: catalog.ModeOut, DataType: "TABLE_TYPE", TableType: "S._SYS_SS_TBL_1"},
+	}
+	if !reflect.DeepEqual(prms, expPrms) {
+		t.Fatalf("parameters %v - expected %v", prms, expPrms)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	tests := []struct {
+		name string
+		fct  func(t *testing.T, s *drivertest.MockServer, db *sql.DB)
+	}{
+		{"tables", testTables},
+		{"columns", testColumns},
+		{"primaryKey", testPrimaryKey},
+		{"procedures", testProcedures},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t, s, db)
+		})
+	}
+}
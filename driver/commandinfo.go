@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"runtime"
+	"strings"
+)
+
+/*
+CommandInfo is the application source location of a statement, which is sent to the database server
+with prepare and execute requests, so that server side traces like the expensive statement trace
+can point back to the application call site.
+*/
+type CommandInfo struct {
+	SourceModule string // Source file (module) name.
+	LineNumber   int    // Line number in source file.
+}
+
+type commandInfoCtxKey struct{}
+
+// WithCommandInfo returns a context with the command info of the statements executed with the context.
+// The command info of the context takes precedence over the caller command info (see Connector.SetCallerCommandInfo).
+func WithCommandInfo(ctx context.Context, info CommandInfo) context.Context {
+	return context.WithValue(ctx, commandInfoCtxKey{}, info)
+}
+
+// CommandInfoFromContext returns the command info of ctx.
+func CommandInfoFromContext(ctx context.Context) (CommandInfo, bool) {
+	info, ok := ctx.Value(commandInfoCtxKey{}).(CommandInfo)
+	return info, ok
+}
+
+// commandInfoSkipPrefixes are the function name prefixes of the frames skipped while
+// looking up the application call site.
+var commandInfoSkipPrefixes = []string{
+	"runtime.",
+	"database/sql.",
+	"github.com/SAP/go-hdb/driver.",
+}
+
+const maxCommandInfoFrames = 32
+
+func isCommandInfoSkipFrame(function string) bool {
+	for _, prefix := range commandInfoSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// callerCommandInfo returns the source location of the first caller outside of the driver and database/sql.
+func callerCommandInfo() (CommandInfo, bool) {
+	pc := make([]uintptr, maxCommandInfoFrames)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isCommandInfoSkipFrame(frame.Function) {
+			return CommandInfo{SourceModule: frame.File, LineNumber: frame.Line}, true
+		}
+		if !more {
+			return CommandInfo{}, false
+		}
+	}
+}
+
+// commandInfo returns the command info of a statement execution.
+func (c *conn) commandInfo(ctx context.Context) (CommandInfo, bool) {
+	if info, ok := CommandInfoFromContext(ctx); ok {
+		return info, info.SourceModule != ""
+	}
+	if !c.callerCommandInfo {
+		return CommandInfo{}, false
+	}
+	return callerCommandInfo()
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockCommandInfo(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{RowsAffected: 1})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	checkCommandInfo := func(sourceModule string, lineNumber int) {
+		t.Helper()
+		if module, line := s.CommandInfo(); module != sourceModule || line != lineNumber {
+			t.Fatalf("command info %s:%d - expected %s:%d", module, line, sourceModule, lineNumber)
+		}
+	}
+
+	// no command info
+	if _, err := db.Exec("insert into t values (?)", "a"); err != nil {
+		t.Fatal(err)
+	}
+	checkCommandInfo("", 0)
+
+	// command info via context
+	ctx := driver.WithCommandInfo(context.Background(), driver.CommandInfo{SourceModule: "app.go", LineNumber: 42})
+	if _, err := db.ExecContext(ctx, "insert into t values (?)", "b"); err != nil {
+		t.Fatal(err)
+	}
+	checkCommandInfo("app.go", 42)
+
+	// caller command info
+	if err := connector.SetCallerCommandInfo(true); err != nil {
+		t.Fatal(err)
+	}
+	db2 := sql.OpenDB(connector)
+	defer db2.Close()
+
+	_, file, line, _ := runtime.Caller(0)
+	_, err := db2.Exec("insert into t values (?)", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCommandInfo(file, line+1)
+}
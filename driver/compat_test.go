@@ -0,0 +1,65 @@
+// +build !unit
+
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testCompatPing(db *sql.DB, t *testing.T) {
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCompatDaydate(db *sql.DB, t *testing.T) {
+	rows, err := db.Query("select current_date from dummy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typeName := columnTypes[0].DatabaseTypeName(); typeName != "DAYDATE" {
+		t.Fatalf("database type name %s - expected %s", typeName, "DAYDATE")
+	}
+}
+
+func TestCompatibility(t *testing.T) {
+	tests := []struct {
+		name string
+		req  drivertest.Requirement
+		fct  func(db *sql.DB, t *testing.T)
+	}{
+		{"ping", drivertest.Requirement{}, testCompatPing},
+		{"daydate", drivertest.Requirement{MinDfv: DfvLevel3}, testCompatDaydate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			TestMatrix.Run(t, test.req, func(t *testing.T, tg *drivertest.Target) {
+				connector, err := NewDSNConnector(tg.DSN)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tg.Dfv != 0 {
+					if err := connector.SetDfv(tg.Dfv); err != nil {
+						t.Fatal(err)
+					}
+				}
+				db := sql.OpenDB(connector)
+				defer db.Close()
+				test.fct(db, t)
+			})
+		})
+	}
+}
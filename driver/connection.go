@@ -11,8 +11,10 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime/pprof"
 	"time"
 
+	"github.com/SAP/go-hdb/driver/dlog"
 	"github.com/SAP/go-hdb/driver/sqltrace"
 	p "github.com/SAP/go-hdb/internal/protocol"
 	"github.com/SAP/go-hdb/internal/protocol/scanner"
@@ -55,6 +57,10 @@ var ErrNestedTransaction = errors.New("nested transactions are not supported")
 // Example: execute sql statement before rows of privious select statement are closed.
 var ErrNestedQuery = errors.New("nested sql queries are not supported")
 
+// ErrProtocolValidation is the error wrapped by protocol violations detected in strict protocol validation mode
+// (see Connector.SetStrictProtocol).
+var ErrProtocolValidation = p.ErrProtocolValidation
+
 // queries
 const (
 	pingQuery          = "select 1 from dummy"
@@ -100,20 +106,78 @@ var (
 )
 
 type conn struct {
-	session *p.Session
-	scanner *scanner.Scanner
-	closed  chan struct{}
+	session     *p.Session
+	scanner     *scanner.Scanner
+	closed      chan struct{}
+	stmtMetrics *StmtMetrics
+	hooks       Hooks
+	pprofLabels bool
+	logger      dlog.Logger
+	redactFunc  RedactFunc
+	execNo      uint64 // statement execution counter (correlation id)
+
+	sliceExpansion    bool
+	maxSliceExpansion int
+	statementTimeout  time.Duration
+	retryIdempotent   bool
+	retryPolicy       RetryPolicy
+	callerCommandInfo bool
+
+	host       string      // database host of the connection (latency probes)
+	readOnly   bool        // read-only connection (see Connector.SetReadOnly)
+	hostHealth *HostHealth // host health registry (nil: no recording)
+	latency    connLatency // latency probes of the connection
+
+	statementRouting bool             // route prepared statement executions to the nodes of a scale-out system
+	nodes            map[string]*conn // connections to the nodes of a scale-out system by address (statement routing)
+
+	username  string    // database user (audit records)
+	auditFunc AuditFunc // audit function (nil: no audit)
+
+	nestedTx   bool   // emulate nested transactions by savepoints
+	txLevel    int    // nesting level of the innermost nested transaction (0: no nested transaction)
+	txGen      uint64 // generation of the outer transaction (detects nested transactions of ended outer transactions)
+	txIsoLevel string // isolation level of the outer transaction
+	txReadOnly bool   // access mode of the outer transaction
+
+	stmtCache *stmtCache // prepared statement cache (nil: no caching)
+
+	tableNo int // number of temporary tables of table arguments (unique temporary table names)
+
+	connector *Connector // frozen connector settings of the connection (re-dial)
+
+	metrics       *driverMetrics // connection statistics of the connector
+	statsObserver StatsObserver  // stats observer (nil: none)
+
+	traceHook TraceHook  // trace hook (nil: none)
+	span      *TraceSpan // span of the currently traced operation (nil: none)
 }
 
 func newConn(ctx context.Context, ctr *Connector) (driver.Conn, error) {
-	session, err := p.NewSession(ctx, ctr)
+	ctr = ctr.snapshot() // frozen connector settings of the connection
+	if err := ctr.Validate(); err != nil {
+		return nil, err
+	}
+	session, err := newFailoverSession(ctx, ctr)
 	if err != nil {
 		return nil, err
 	}
-	c := &conn{session: session, scanner: &scanner.Scanner{}, closed: make(chan struct{})}
+	c := &conn{session: session, scanner: &scanner.Scanner{}, closed: make(chan struct{}), stmtMetrics: ctr.StmtMetrics(), hooks: ctr.Hooks(), pprofLabels: ctr.PprofLabels(), logger: ctr.Logger(), redactFunc: ctr.RedactFunc(), sliceExpansion: ctr.SliceExpansion(), maxSliceExpansion: ctr.MaxSliceExpansion(), statementTimeout: ctr.StatementTimeout(), retryIdempotent: ctr.RetryIdempotent(), retryPolicy: ctr.RetryPolicy(), callerCommandInfo: ctr.CallerCommandInfo(), host: ctr.Host(), hostHealth: ctr.HostHealth(), latency: connLatency{probes: newLatencyProbes()}, username: ctr.Username(), auditFunc: ctr.AuditFunc(), nestedTx: ctr.NestedTransactions(), connector: ctr, metrics: ctr.metrics, statsObserver: ctr.StatsObserver(), traceHook: ctr.TraceHook(), readOnly: ctr.ReadOnly(), statementRouting: ctr.StatementRouting()}
+	if size := ctr.StmtCacheSize(); size > 0 {
+		c.stmtCache = newStmtCache(size)
+	}
+	c.session.SetOpObserver(c.observeSessionOp)
 	if err := c.init(ctx, ctr); err != nil {
 		return nil, err
 	}
+	c.metrics.connOpened()
+	if c.hooks != nil {
+		if err := c.hooks.OnConnect(ctx, c); err != nil {
+			c.hooks.OnError(ctx, "", err)
+			c.Close()
+			return nil, err
+		}
+	}
 	d := ctr.PingInterval()
 	if d != 0 {
 		go c.pinger(d, c.closed)
@@ -127,6 +191,11 @@ func (c *conn) init(ctx context.Context, ctr *Connector) error {
 			return err
 		}
 	}
+	if c.readOnly {
+		c.session.Lock()
+		defer c.session.Unlock()
+		return c.setReadOnlyMode()
+	}
 	return nil
 }
 
@@ -145,6 +214,205 @@ func (c *conn) pinger(d time.Duration, done <-chan struct{}) {
 	}
 }
 
+/*
+call executes the database operation f supporting the cancellation of ctx:
+the session I/O is interrupted in case ctx gets canceled while f is executed (the connection becomes a bad connection).
+If f fails and ctx is done, an error wrapping the context error (matching ErrQueryTimeout or ErrCanceled)
+is returned instead of the error returned by f.
+If activated, the pprof labels of the operation are set while f is executed.
+If f fails because of a broken connection, f is retried on a new connection according to the retry policy
+of the connector (see Connector.SetRetryPolicy).
+The timeouts and the fetch size of the context query options (see WithQueryOptions) and the statement
+timeout of the connector apply to f. Queries request scrollable result sets if a cursor is set (see WithCursor).
+*/
+func (c *conn) call(ctx context.Context, op, query string, f func() error) (err error) {
+	stmtTimeout := c.statementTimeout
+	if opts, ok := QueryOptionsFromContext(ctx); ok {
+		if opts.ServerTimeout != 0 {
+			stmtTimeout = opts.ServerTimeout
+		}
+		if opts.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+			defer cancel()
+		}
+		if opts.FetchSize > 0 {
+			c.session.SetStmtFetchSize(opts.FetchSize)
+			defer c.session.SetStmtFetchSize(0)
+		}
+	}
+	if stmtTimeout > 0 {
+		c.session.SetStmtTimeout(stmtTimeout)
+		defer c.session.SetStmtTimeout(0)
+	}
+	if _, ok := cursorFromContext(ctx); ok && op == opQuery {
+		c.session.SetStmtScrollable(true)
+		defer c.session.SetStmtScrollable(false)
+	}
+	if op == opPrepare || op == opQuery || op == opExec {
+		if info, ok := c.commandInfo(ctx); ok {
+			c.session.SetCommandInfo(info.SourceModule, info.LineNumber)
+			defer c.session.SetCommandInfo("", 0)
+		}
+	}
+	start := time.Now()
+	traced := op == opPrepare || op == opQuery || op == opExec
+	if traced {
+		end := c.trace(ctx, op, query)
+		defer func() { end(err) }()
+	}
+	err = c.watched(ctx, op, query, f)
+	if err != nil {
+		err = c.checkRetry(ctx, op, query, f, err)
+	}
+	if traced {
+		c.observe(op, time.Since(start), err)
+	}
+	return err
+}
+
+// watched calls f watching the cancellation of ctx (see call).
+func (c *conn) watched(ctx context.Context, op, query string, f func() error) error {
+	if err := c.session.Watch(ctx); err != nil {
+		return newCtxError(err)
+	}
+	var err error
+	if c.pprofLabels {
+		pprof.Do(ctx, pprofLabels(op, query), func(context.Context) { err = f() })
+	} else {
+		err = f()
+	}
+	c.session.Unwatch()
+	if err != nil && ctx.Err() != nil {
+		return newCtxError(ctx.Err())
+	}
+	return err
+}
+
+// nextCorrelationID sets a new correlation id for the next statement execution in the session and ctx.
+func (c *conn) nextCorrelationID(ctx context.Context) context.Context {
+	c.execNo++
+	id := newCorrelationID(c.session.ConnNo(), c.execNo)
+	c.session.SetCorrelationID(id)
+	return contextWithCorrelationID(ctx, id)
+}
+
+// beforePrepare sets the correlation id, applies the query options and hints of the context (see WithQueryOptions, WithHints) and calls the BeforePrepare hooks (if registered).
+func (c *conn) beforePrepare(ctx context.Context, query string) (context.Context, string, error) {
+	ctx = c.nextCorrelationID(ctx)
+	query, err := c.applyQueryOptions(ctx, query)
+	if err != nil {
+		return ctx, query, err
+	}
+	if c.hooks == nil {
+		return ctx, query, nil
+	}
+	ctx, query, err = c.hooks.BeforePrepare(ctx, query)
+	if err != nil {
+		c.hooks.OnError(ctx, query, err)
+	}
+	return ctx, query, err
+}
+
+// beforeQuery sets the correlation id, applies the query options and hints of the context (see WithQueryOptions, WithHints) and calls the BeforeQuery hooks (if registered).
+func (c *conn) beforeQuery(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	ctx = c.nextCorrelationID(ctx)
+	query, err := c.applyQueryOptions(ctx, query)
+	if err != nil {
+		return ctx, query, err
+	}
+	if c.hooks == nil {
+		return ctx, query, nil
+	}
+	ctx, query, err = c.hooks.BeforeQuery(ctx, query, args)
+	if err != nil {
+		c.hooks.OnError(ctx, query, err)
+	}
+	return ctx, query, err
+}
+
+// beforeExec sets the correlation id, applies the query options and hints of the context (see WithQueryOptions, WithHints) and calls the BeforeExec hooks (if registered).
+func (c *conn) beforeExec(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	ctx = c.nextCorrelationID(ctx)
+	query, err := c.applyQueryOptions(ctx, query)
+	if err != nil {
+		return ctx, query, err
+	}
+	if c.hooks == nil {
+		return ctx, query, nil
+	}
+	ctx, query, err = c.hooks.BeforeExec(ctx, query, args)
+	if err != nil {
+		c.hooks.OnError(ctx, query, err)
+	}
+	return ctx, query, err
+}
+
+// afterQuery calls the AfterQuery hooks (if registered), records the query execution metrics (if activated) and
+// wraps rows to add the number of rows read to the metrics and to trace the query on close.
+func (c *conn) afterQuery(ctx context.Context, query string, args []driver.NamedValue, start time.Time, rows driver.Rows, err error) driver.Rows {
+	d := time.Since(start)
+	corrID, _ := CorrelationIDFromContext(ctx)
+	if c.hooks != nil {
+		c.hooks.AfterQuery(ctx, query, args, err)
+		if err != nil {
+			c.hooks.OnError(ctx, query, err)
+		}
+	}
+	if c.stmtMetrics != nil {
+		c.stmtMetrics.record(query, d, 0, err)
+	}
+	if err != nil {
+		sqltrace.TraceStmt(c.logger, opQuery, c.session.ID(), corrID, query, d, 0, err)
+		if c.auditFunc != nil {
+			c.audit(ctx, opQuery, query, 0, d, err)
+		}
+		return rows
+	}
+	if c.stmtMetrics == nil && c.auditFunc == nil && !sqltrace.On() && !sqltrace.SlowOn() {
+		return rows
+	}
+	serverTime := c.session.ServerExecutionTime()
+	return newRows(rows, func(numRow int64) {
+		d := time.Since(start)
+		if c.stmtMetrics != nil {
+			c.stmtMetrics.addRows(query, numRow)
+		}
+		if c.auditFunc != nil {
+			c.audit(ctx, opQuery, query, numRow, d, nil)
+		}
+		sqltrace.TraceStmt(c.logger, opQuery, c.session.ID(), corrID, query, d, numRow, nil)
+		sqltrace.TraceSlow(c.logger, c.session.ID(), corrID, query, d, numRow, serverTime)
+	})
+}
+
+// afterExec calls the AfterExec hooks (if registered), records the exec execution metrics (if activated)
+// and traces the statement.
+func (c *conn) afterExec(ctx context.Context, query string, args []driver.NamedValue, start time.Time, r driver.Result, err error) {
+	d := time.Since(start)
+	corrID, _ := CorrelationIDFromContext(ctx)
+	if c.hooks != nil {
+		c.hooks.AfterExec(ctx, query, args, r, err)
+		if err != nil {
+			c.hooks.OnError(ctx, query, err)
+		}
+	}
+	var numRow int64
+	if r != nil {
+		numRow, _ = r.RowsAffected()
+	}
+	if c.stmtMetrics != nil {
+		c.stmtMetrics.record(query, d, numRow, err)
+	}
+	if c.auditFunc != nil {
+		c.audit(ctx, opExec, query, numRow, d, err)
+	}
+	sqltrace.TraceStmt(c.logger, opExec, c.session.ID(), corrID, query, d, numRow, err)
+	if err == nil {
+		sqltrace.TraceSlow(c.logger, c.session.ID(), corrID, query, d, numRow, c.session.ServerExecutionTime())
+	}
+}
+
 func (c *conn) Ping(ctx context.Context) (err error) {
 	c.session.Lock()
 	defer c.session.Unlock()
@@ -159,18 +427,20 @@ func (c *conn) Ping(ctx context.Context) (err error) {
 	// caution!!!
 	defer c.session.SetInQuery(false)
 
-	done := make(chan struct{})
-	go func() {
-		_, err = c.session.QueryDirect(pingQuery)
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		c.session.Kill()
-		return ctx.Err()
-	case <-done:
+	t := time.Now()
+	err = c.call(ctx, opPing, pingQuery, func() error {
+		_, err := c.session.QueryDirect(pingQuery)
 		return err
+	})
+	c.probed(time.Since(t), err)
+	return err
+}
+
+// probed records the round trip time d of a ping as latency probe.
+func (c *conn) probed(d time.Duration, err error) {
+	c.latency.add(d, err)
+	if c.hostHealth != nil {
+		c.hostHealth.record(c.host, d, err)
 	}
 }
 
@@ -196,43 +466,59 @@ func (c *conn) PrepareContext(ctx context.Context, query string) (stmt driver.St
 		return nil, ErrNestedQuery
 	}
 
-	done := make(chan struct{})
-	go func() {
-		var (
-			qd *p.QueryDescr
-			pr *p.PrepareResult
-		)
+	ctx, query, err = c.beforePrepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := c.prepareStmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if c.statementRouting {
+		c.routeStmt(ctx, query, s)
+	}
+	return s, nil
+}
 
-		qd, err = p.NewQueryDescr(query, c.scanner)
+// prepareStmt prepares query (hints applied) and returns the prepared statement.
+// The caller needs to hold the session lock.
+func (c *conn) prepareStmt(ctx context.Context, query string) (s *stmt, err error) {
+	err = c.call(ctx, opPrepare, query, func() error {
+		qd, err := p.NewQueryDescr(query, c.scanner)
 		if err != nil {
-			goto done
+			return err
 		}
-		pr, err = c.session.Prepare(qd.Query())
+		pr, err := c.prepare(qd)
 		if err != nil {
-			goto done
+			return err
 		}
-
-		if err = pr.Check(qd); err != nil {
-			goto done
+		c.traceStmtID(pr.StmtID())
+		defaults, _ := BulkDefaultsFromContext(ctx)
+		if err := checkBulkDefaults(defaults, pr.NumField()); err != nil {
+			return err
 		}
+		s, err = newStmt(c, qd.Query(), qd.IsBulk(), qd.NamedParams(), defaults, pr)
+		return err
+	})
+	return s, err
+}
 
-		select {
-		default:
-		case <-ctx.Done():
-			return
+// prepare returns the prepared statement of qd, either taken from the statement cache or prepared by the database server.
+func (c *conn) prepare(qd *p.QueryDescr) (*p.PrepareResult, error) {
+	if c.stmtCache != nil {
+		if pr, ok := c.stmtCache.get(qd.Query()); ok {
+			return pr, nil
 		}
-		stmt, err = newStmt(c.session, qd.Query(), qd.IsBulk(), pr)
-	done:
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		c.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return stmt, err
 	}
+	pr, err := c.session.Prepare(qd.Query())
+	if err != nil {
+		return nil, err
+	}
+	if err := pr.Check(qd); err != nil {
+		return nil, err
+	}
+	return pr, nil
 }
 
 func (c *conn) Close() error {
@@ -240,6 +526,8 @@ func (c *conn) Close() error {
 	defer c.session.Unlock()
 
 	close(c.closed) // signal connection close
+	c.closeNodes()
+	c.metrics.connClosed(c.session.IsBad())
 	return c.session.Close()
 }
 
@@ -250,7 +538,7 @@ func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx
 	if c.session.IsBad() {
 		return nil, driver.ErrBadConn
 	}
-	if c.session.InTx() {
+	if c.session.InTx() && !c.nestedTx {
 		return nil, ErrNestedTransaction
 	}
 	if c.session.InQuery() {
@@ -261,33 +549,52 @@ func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (tx driver.Tx
 	if !ok {
 		return nil, ErrUnsupportedIsolationLevel
 	}
+	txReadOnly := opts.ReadOnly || c.readOnly
 
-	done := make(chan struct{})
-	go func() {
+	if c.session.InTx() { // nested transaction
+		if level != c.txIsoLevel || txReadOnly != c.txReadOnly {
+			return nil, ErrNestedTxOptions
+		}
+		err = c.call(ctx, opBegin, "", func() (err error) {
+			tx, err = c.beginSavepoint()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return tx, nil
+	}
+
+	err = c.call(ctx, opBegin, "", func() error {
 		// set isolation level
-		if _, err = c.session.ExecDirect(fmt.Sprintf(isolationLevelStmt, level)); err != nil {
-			goto done
+		if _, err := c.session.ExecDirect(fmt.Sprintf(isolationLevelStmt, level)); err != nil {
+			return err
 		}
 		// set access mode
-		if _, err = c.session.ExecDirect(fmt.Sprintf(accessModeStmt, readOnly[opts.ReadOnly])); err != nil {
-			goto done
+		if _, err := c.session.ExecDirect(fmt.Sprintf(accessModeStmt, readOnly[txReadOnly])); err != nil {
+			return err
 		}
-		c.session.SetInTx(true)
-		tx = newTx(c.session)
-	done:
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		c.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return tx, err
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	c.session.SetInTx(true)
+	c.txLevel = 0
+	c.txGen++
+	c.txIsoLevel, c.txReadOnly = level, txReadOnly
+	c.metrics.txOpened()
+	return newTx(c), nil
 }
 
 func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (rows driver.Rows, err error) {
+	if c.sliceExpansion && hasSliceArg(args) {
+		return c.queryExpanded(ctx, query, args)
+	}
+	if hasTableArg(args) {
+		return c.queryTables(ctx, query, args)
+	}
+
 	c.session.Lock()
 	defer c.session.Unlock()
 
@@ -321,24 +628,37 @@ func (c *conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 		return qrs, nil
 	}
 
-	sqltrace.Traceln(query)
+	ctx, query, err = c.beforeQuery(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	done := make(chan struct{})
-	go func() {
-		rows, err = c.session.QueryDirect(query)
-		close(done)
-	}()
+	if sqltrace.On() {
+		sqltrace.Log(c.logger, query, c.traceKeyvals(ctx)...)
+	}
+
+	start := time.Now()
 
-	select {
-	case <-ctx.Done():
-		c.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return rows, err
+	err = c.call(ctx, opQuery, query, func() (err error) {
+		rows, err = c.session.QueryDirect(query)
+		return err
+	})
+	c.setStatementInfo(ctx, 0)
+	bindCursor(ctx, rows)
+	if err != nil {
+		return c.afterQuery(ctx, query, nil, start, nil, err), err
 	}
+	return c.afterQuery(ctx, query, nil, start, rows, nil), nil
 }
 
 func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Result, err error) {
+	if c.sliceExpansion && hasSliceArg(args) {
+		return c.execExpanded(ctx, query, args)
+	}
+	if hasTableArg(args) {
+		return c.execTables(ctx, query, args)
+	}
+
 	c.session.Lock()
 	defer c.session.Unlock()
 
@@ -353,27 +673,32 @@ func (c *conn) ExecContext(ctx context.Context, query string, args []driver.Name
 		return nil, driver.ErrSkip //fast path not possible (prepare needed)
 	}
 
-	sqltrace.Traceln(query)
+	ctx, query, err = c.beforeExec(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if sqltrace.On() {
+		sqltrace.Log(c.logger, query, c.traceKeyvals(ctx)...)
+	}
+
+	start := time.Now()
 
-	done := make(chan struct{})
-	go func() {
-		var qd *p.QueryDescr
-		qd, err = p.NewQueryDescr(query, c.scanner)
+	err = c.call(ctx, opExec, query, func() error {
+		qd, err := p.NewQueryDescr(query, c.scanner)
 		if err != nil {
-			goto done
+			return err
 		}
 		r, err = c.session.ExecDirect(qd.Query())
-	done:
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		c.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return r, err
+		return err
+	})
+	c.setStatementInfo(ctx, 0)
+	if err != nil {
+		c.afterExec(ctx, query, nil, start, nil, err)
+		return nil, err
 	}
+	c.afterExec(ctx, query, nil, start, r, nil)
+	return r, nil
 }
 
 // CheckNamedValue implements NamedValueChecker interface.
@@ -394,34 +719,44 @@ var (
 )
 
 type tx struct {
+	conn    *conn
 	session *p.Session
+	metrics *driverMetrics
 }
 
-func newTx(session *p.Session) *tx {
+func newTx(c *conn) *tx {
 	return &tx{
-		session: session,
+		conn:    c,
+		session: c.session,
+		metrics: c.metrics,
 	}
 }
 
-func (t *tx) Commit() error {
+func (t *tx) Commit() (err error) {
 	t.session.Lock()
 	defer t.session.Unlock()
+	defer t.metrics.txClosed()
 
 	if t.session.IsBad() {
 		return driver.ErrBadConn
 	}
 
+	end := t.conn.trace(context.Background(), TraceOpCommit, "")
+	defer func() { end(err) }()
 	return t.session.Commit()
 }
 
-func (t *tx) Rollback() error {
+func (t *tx) Rollback() (err error) {
 	t.session.Lock()
 	defer t.session.Unlock()
+	defer t.metrics.txClosed()
 
 	if t.session.IsBad() {
 		return driver.ErrBadConn
 	}
 
+	end := t.conn.trace(context.Background(), TraceOpRollback, "")
+	defer func() { end(err) }()
 	return t.session.Rollback()
 }
 
@@ -437,25 +772,70 @@ var (
 
 type stmt struct {
 	pr                  *p.PrepareResult
+	conn                *conn
 	session             *p.Session
 	query               string
 	bulk, flush         bool
 	maxBulkNum, bulkNum int
 	args                []driver.NamedValue
+	params              namedParams
+	defaults            BulkDefaults // defaults of nil bulk arguments
+	node                *stmt        // statement prepared on the node connection executions are routed to (nil: none)
+}
+
+func newStmt(c *conn, query string, bulk bool, params []string, defaults BulkDefaults, pr *p.PrepareResult) (*stmt, error) {
+	c.metrics.stmtOpened()
+	return &stmt{conn: c, session: c.session, query: query, pr: pr, bulk: bulk, params: params, defaults: defaults, maxBulkNum: c.session.MaxBulkNum()}, nil
+}
+
+// isStmtInvalidated returns true if err reports that the prepared statement is not known to the database server (anymore).
+func isStmtInvalidated(err error) bool {
+	var dbErr Error
+	return errors.As(err, &dbErr) && dbErr.Code() == p.ErrCodeInvalidStatementID
 }
 
-func newStmt(session *p.Session, query string, bulk bool, pr *p.PrepareResult) (*stmt, error) {
-	return &stmt{session: session, query: query, pr: pr, bulk: bulk, maxBulkNum: session.MaxBulkNum()}, nil
+/*
+reprepared calls f and re-prepares the statement, if the database server reports the prepared statement to be
+invalidated (e.g. after a failover), so that f can be called again with the new prepared statement instead of
+failing for the rest of the statement lifetime. As the statement text is kept, re-preparing is transparent to
+the caller as long as the statement metadata is unchanged.
+*/
+func (s *stmt) reprepared(f func() error) error {
+	err := f()
+	if err == nil || !isStmtInvalidated(err) || s.session.IsBad() {
+		return err
+	}
+	pr, prepareErr := s.session.Prepare(s.query)
+	if prepareErr != nil {
+		return err
+	}
+	if pr.NumField() != s.pr.NumField() || pr.IsProcedureCall() != s.pr.IsProcedureCall() {
+		s.session.DropStatementID(pr.StmtID())
+		return err
+	}
+	s.pr = pr
+	return f()
 }
 
 func (s *stmt) Close() error {
+	if s.node != nil {
+		s.node.Close()
+	}
+
 	s.session.Lock()
 	defer s.session.Unlock()
+	defer s.conn.metrics.stmtClosed()
 
 	if len(s.args) != 0 {
-		sqltrace.Tracef("close: %s - not flushed records: %d)", s.query, len(s.args)/s.NumInput())
+		sqltrace.Log(s.conn.logger, "close: "+s.query, "connID", s.session.ID(), "notFlushedRecords", s.bulkNum)
+	}
+	pr := s.pr
+	if s.conn.stmtCache != nil && !s.session.IsBad() {
+		if pr = s.conn.stmtCache.put(s.query, s.pr); pr == nil {
+			return nil
+		}
 	}
-	return s.session.DropStatementID(s.pr.StmtID())
+	return s.session.DropStatementID(pr.StmtID())
 }
 
 func (s *stmt) NumInput() int {
@@ -470,6 +850,13 @@ func (s *stmt) NumInput() int {
 }
 
 func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	if node := s.routed(); node != nil {
+		return node.QueryContext(ctx, args)
+	}
+	if hasTableArg(args) {
+		return s.queryTables(ctx, args)
+	}
+
 	s.session.Lock()
 	defer s.session.Unlock()
 
@@ -480,7 +867,18 @@ func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows
 		return nil, ErrNestedQuery
 	}
 
-	sqltrace.Tracef("%s %v", s.query, args)
+	if args, err = s.params.bind(args); err != nil {
+		return nil, err
+	}
+
+	ctx, _, err = s.conn.beforeQuery(ctx, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if sqltrace.On() {
+		sqltrace.Log(s.conn.logger, s.query, s.conn.traceKeyvals(ctx, "args", redactArgs(s.conn.redactFunc, s.query, args))...)
+	}
 
 	numArg := len(args)
 	var numExpected int
@@ -493,26 +891,38 @@ func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows
 		return nil, fmt.Errorf("invalid number of arguments %d - %d expected", numArg, numExpected)
 	}
 
-	done := make(chan struct{})
-	go func() {
-		if s.pr.IsProcedureCall() {
-			rows, err = s.session.QueryCall(s.pr, args)
-		} else {
-			rows, err = s.session.Query(s.pr, args)
-		}
-		close(done)
-	}()
+	start := time.Now()
 
-	select {
-	case <-ctx.Done():
-		s.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return rows, err
+	err = s.conn.call(ctx, opQuery, s.query, func() error {
+		s.conn.traceStmtID(s.pr.StmtID())
+		return s.reprepared(func() (err error) {
+			if s.pr.IsProcedureCall() {
+				rows, err = s.session.QueryCall(s.pr, args)
+			} else {
+				rows, err = s.session.Query(s.pr, args)
+			}
+			return err
+		})
+	})
+	s.conn.setStatementInfo(ctx, s.pr.StmtID())
+	bindCursor(ctx, rows)
+	if err != nil {
+		return s.conn.afterQuery(ctx, s.query, args, start, nil, err), err
 	}
+	return s.conn.afterQuery(ctx, s.query, args, start, rows, nil), nil
 }
 
 func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r driver.Result, err error) {
+	if node := s.routed(); node != nil {
+		return node.ExecContext(ctx, args)
+	}
+	if hasTableArg(args) {
+		return s.execTables(ctx, args)
+	}
+	if cols, ok := bulkColumnsArg(args); ok {
+		return s.execColumns(ctx, cols)
+	}
+
 	s.session.Lock()
 	defer s.session.Unlock()
 
@@ -523,7 +933,18 @@ func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r dri
 		return nil, ErrNestedQuery
 	}
 
-	sqltrace.Tracef("%s %v", s.query, args)
+	if args, err = s.params.bind(args); err != nil {
+		return nil, err
+	}
+
+	ctx, _, err = s.conn.beforeExec(ctx, s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if sqltrace.On() {
+		sqltrace.Log(s.conn.logger, s.query, s.conn.traceKeyvals(ctx, "args", redactArgs(s.conn.redactFunc, s.query, args))...)
+	}
 
 	numArg := len(args)
 	var numExpected int
@@ -541,11 +962,26 @@ func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r dri
 	}
 	defer func() { s.flush = false }()
 
-	done := make(chan struct{})
-	go func() {
+	if s.bulk && numArg != 0 {
+		if err := s.applyBulkDefaults(args); err != nil {
+			return nil, err
+		}
+		if err := s.checkBulkRow(args); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.bulk { // buffered rows would be lost by a retry
+		ctx = withoutRetry(ctx)
+	}
+
+	start := time.Now()
+
+	err = s.conn.call(ctx, opExec, s.query, func() (err error) {
+		s.conn.traceStmtID(s.pr.StmtID())
 		switch {
 		case s.pr.IsProcedureCall():
-			r, err = s.session.ExecCall(s.pr, args)
+			err = s.reprepared(func() (err error) { r, err = s.session.ExecCall(s.pr, args); return err })
 		case s.bulk:
 			r, err = driver.ResultNoRows, nil
 
@@ -558,23 +994,25 @@ func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r dri
 			}
 
 			if s.bulkNum != 0 && (s.flush || s.bulkNum == s.maxBulkNum) { // flush
-				r, err = s.session.Exec(s.pr, s.args)
+				err = s.reprepared(func() (err error) { r, err = s.session.Exec(s.pr, s.args); return err })
+				if err != nil {
+					err = newBulkError(err, r)
+				}
 				s.args = s.args[:0]
 				s.bulkNum = 0
 			}
 		default:
-			r, err = s.session.Exec(s.pr, args)
+			err = s.reprepared(func() (err error) { r, err = s.session.Exec(s.pr, args); return err })
 		}
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		s.session.Kill()
-		return nil, ctx.Err()
-	case <-done:
-		return r, err
+		return err
+	})
+	s.conn.setStatementInfo(ctx, s.pr.StmtID())
+	if err != nil {
+		s.conn.afterExec(ctx, s.query, args, start, nil, err)
+		return nil, err
 	}
+	s.conn.afterExec(ctx, s.query, args, start, r, nil)
+	return r, nil
 }
 
 // CheckNamedValue implements NamedValueChecker interface.
@@ -592,5 +1030,24 @@ func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
 		}
 	}
 
+	if _, ok := nv.Value.(*TableRows); ok { // bound to a temporary table on execution
+		return nil
+	}
+
+	if _, ok := nv.Value.(*bulkColumns); ok { // column-wise bulk execution
+		return nil
+	}
+
+	if s.params != nil { // convert argument according to the named parameter field
+		idx, err := s.params.check(nv)
+		if err != nil {
+			return err
+		}
+		ordinal := nv.Ordinal
+		nv.Ordinal = idx + 1
+		err = convertNamedValue(s.pr, nv)
+		nv.Ordinal = ordinal
+		return err
+	}
 	return convertNamedValue(s.pr, nv)
 }
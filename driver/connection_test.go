@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockStatementCancel(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("call slow", &drivertest.MockStatement{Delay: 5 * time.Second})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetStatementCancel(true); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for i, cancelFunc := range []func() (context.Context, context.CancelFunc){
+		func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		},
+		func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			return ctx, cancel
+		},
+	} {
+		ctx, cancel := cancelFunc()
+		start := time.Now()
+		_, err := conn.ExecContext(ctx, "call slow")
+		cancel()
+		if !errors.Is(err, driver.ErrQueryTimeout) && !errors.Is(err, driver.ErrCanceled) {
+			t.Fatalf("error %v - expected %v or %v", err, driver.ErrQueryTimeout, driver.ErrCanceled)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Fatalf("statement canceled after %s", d)
+		}
+		if n := s.Canceled(); n != i+1 {
+			t.Fatalf("canceled statements %d - expected %d", n, i+1)
+		}
+		// connection is still usable
+		if err := conn.PingContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// statement returns before the (slow) cancel request is done: the call does not wait for the cancel request
+	s.Handle("call short", &drivertest.MockStatement{Delay: 100 * time.Millisecond})
+	s.SetCancelDelay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	conn.ExecContext(ctx, "call short") // statement returns with or without error
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("call returned after %s - waited for cancel request", d)
+	}
+	// connection is still usable
+	if err := conn.PingContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockConnectorClone(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select 1 from dummy", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "1", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}}})
+
+	base := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	base.SetFetchSize(10)
+	base.SetSessionVariables(driver.SessionVariables{"k1": "v1"})
+
+	db := sql.OpenDB(base)
+	defer db.Close()
+	var i int
+	if err := db.QueryRow("select 1 from dummy").Scan(&i); err != nil {
+		t.Fatal(err)
+	}
+
+	clone := base.Clone()
+	clone.SetFetchSize(20)
+	clone.SetLocale("de_DE")
+	clone.SetSessionVariables(driver.SessionVariables{"k1": "v1", "k2": "v2"})
+
+	if base.FetchSize() != 10 || base.Locale() != "" || len(base.SessionVariables()) != 1 {
+		t.Fatalf("base connector modified: fetch size %d locale %s session variables %v", base.FetchSize(), base.Locale(), base.SessionVariables())
+	}
+	if clone.Host() != base.Host() || clone.FetchSize() != 20 || len(clone.SessionVariables()) != 2 {
+		t.Fatalf("clone: host %s fetch size %d session variables %v", clone.Host(), clone.FetchSize(), clone.SessionVariables())
+	}
+	if base.ConnStats().RoundTrips == 0 || clone.ConnStats().RoundTrips != 0 {
+		t.Fatalf("round trips base %d clone %d - expected > 0 and 0", base.ConnStats().RoundTrips, clone.ConnStats().RoundTrips)
+	}
+
+	cloneDB := sql.OpenDB(clone)
+	defer cloneDB.Close()
+	if err := cloneDB.QueryRow("select 1 from dummy").Scan(&i); err != nil {
+		t.Fatal(err)
+	}
+	if clone.ConnStats().RoundTrips == 0 {
+		t.Fatal("clone round trips expected")
+	}
+}
+
+func TestMockMaxPacketSize(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var numExec, numRow int32
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		atomic.AddInt32(&numExec, 1)
+		atomic.AddInt32(&numRow, int32(len(args)))
+		return &drivertest.MockResult{RowsAffected: int64(len(args))}, nil
+	}})
+
+	const samples = 200
+	value := strings.Repeat("x", 1000)
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetMaxPacketSize(1024); !errors.Is(err, driver.ErrLimitExceeded) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrLimitExceeded)
+	}
+	if err := connector.SetMaxPacketSize(driver.MinMaxPacketSize); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	stmt, err := db.Prepare("insert into t values (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	prm := driver.NoFlush
+	var result sql.Result
+	for i := 0; i < samples; i++ {
+		if i == samples-1 {
+			prm = driver.Flush
+		}
+		if result, err = stmt.Exec(value, prm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rowsAffected != samples || atomic.LoadInt32(&numRow) != samples {
+		t.Fatalf("rows affected %d rows executed %d - expected %d", rowsAffected, numRow, samples)
+	}
+	// 200 rows of ~1KiB are split into requests of max. 64KiB
+	if n := atomic.LoadInt32(&numExec); n < 3 {
+		t.Fatalf("number of executions %d - expected >= %d", n, 3)
+	}
+}
+
+// mockCertificate returns the certificate of key signed by the parent certificate and key (nil: self-signed).
+func mockCertificate(t *testing.T, cn string, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	b, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func mockCertPEM(certs ...*x509.Certificate) []byte {
+	var b []byte
+	for _, cert := range certs {
+		b = append(b, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return b
+}
+
+func mockKeyPEM(t *testing.T, key crypto.Signer) []byte {
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+}
+
+func TestMockX509(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var subjects []string
+	s.SetX509Acceptor(func(certs []*x509.Certificate) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, cert := range certs {
+			subjects = append(subjects, cert.Subject.CommonName)
+		}
+		if certs[0].Subject.CommonName == "rejected" {
+			return "", errors.New("unknown certificate")
+		}
+		return "CERTUSER", nil
+	})
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := mockCertificate(t, "ca", caKey, nil, nil)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		certPEM []byte
+		keyPEM  []byte
+		exp     []string
+	}{
+		{"ecdsa", mockCertPEM(mockCertificate(t, "ecdsa", ecdsaKey, nil, nil)), mockKeyPEM(t, ecdsaKey), []string{"ecdsa"}},
+		{"rsa", mockCertPEM(mockCertificate(t, "rsa", rsaKey, nil, nil)), mockKeyPEM(t, rsaKey), []string{"rsa"}},
+		{"ed25519", mockCertPEM(mockCertificate(t, "ed25519", ed25519Key, nil, nil)), mockKeyPEM(t, ed25519Key), []string{"ed25519"}},
+		{"chain", mockCertPEM(mockCertificate(t, "leaf", ecdsaKey, ca, caKey), ca), mockKeyPEM(t, ecdsaKey), []string{"leaf", "ca"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mu.Lock()
+			subjects = nil
+			mu.Unlock()
+
+			connector, err := driver.NewX509AuthConnector(s.Host(), test.certPEM, test.keyPEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, err := connector.NativeConn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if err := conn.Ping(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			got := subjects
+			mu.Unlock()
+			if !reflect.DeepEqual(got, test.exp) {
+				t.Fatalf("certificate subjects %v - expected %v", got, test.exp)
+			}
+		})
+	}
+
+	// rejected certificate
+	connector, err := driver.NewX509AuthConnector(s.Host(), mockCertPEM(mockCertificate(t, "rejected", ecdsaKey, nil, nil)), mockKeyPEM(t, ecdsaKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := connector.NativeConn(ctx); err == nil {
+		t.Fatal("expected authentication error")
+	}
+
+	// certificate not matching private key
+	if _, err := driver.NewX509AuthConnector(s.Host(), tests[0].certPEM, tests[1].keyPEM); err == nil {
+		t.Fatal("expected key mismatch error")
+	}
+}
+
+func TestMockCompression(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select ? from dummy", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "V", TypeName: "NVARCHAR"}},
+		Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			return &drivertest.MockResult{Rows: [][]interface{}{{args[0]}}}, nil
+		},
+	})
+
+	dsn := fmt.Sprintf("hdb://user:password@%s", s.Host())
+	value := strings.Repeat("compressible ", 5000)
+
+	for _, compression := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compression %t", compression), func(t *testing.T) {
+			hook := &stmtCacheHook{}
+			db, err := driver.OpenDB(dsn,
+				driver.WithCompression(compression),
+				driver.WithCompressionThreshold(512),
+				driver.WithHooks(hook),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+
+			var v string
+			if err := db.QueryRow("select ? from dummy", value).Scan(&v); err != nil {
+				t.Fatal(err)
+			}
+			if v != value {
+				t.Fatalf("value length %d - expected %d", len(v), len(value))
+			}
+
+			conn := hook.conn
+			if conn.ServerInfo().Compression != compression {
+				t.Fatalf("compression %t - expected %t", conn.ServerInfo().Compression, compression)
+			}
+			// compressed requests and replies are much smaller than the value sent and received
+			stats := conn.Stats()
+			if compressed := stats.BytesWritten < uint64(len(value)); compressed != compression {
+				t.Fatalf("bytes written %d value size %d: compressed %t - expected %t", stats.BytesWritten, len(value), compressed, compression)
+			}
+			if compressed := stats.BytesRead < uint64(len(value)); compressed != compression {
+				t.Fatalf("bytes read %d value size %d: compressed %t - expected %t", stats.BytesRead, len(value), compressed, compression)
+			}
+		})
+	}
+}
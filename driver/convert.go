@@ -33,6 +33,17 @@ func convertNamedValue(pr *p.PrepareResult, nv *driver.NamedValue) error {
 
 	var err error
 
+	// decimal arguments (big.Rat and decimal types implementing DecimalMarshaler)
+	if !out {
+		dv, ok, err := convertDecimalArg(v)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v = dv
+		}
+	}
+
 	// let fields with own Value converter convert themselves first (e.g. NullInt64, ...)
 	if valuer, ok := v.(driver.Valuer); ok {
 		if v, err = valuer.Value(); err != nil {
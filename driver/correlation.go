@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+/*
+A correlation id identifies a single statement execution (prepare, query or exec)
+in the format <connection number>-<execution number>, where the connection number is
+the client side number of the physical connection (unique per process) and
+the execution number is a counter per connection.
+
+The correlation id is included in sql trace and protocol trace entries and attached
+to database errors, so that the output of different logs can be related to a single request.
+*/
+
+type correlationIDCtxKey struct{}
+
+func newCorrelationID(connNo, execNo uint64) string { return fmt.Sprintf("%d-%d", connNo, execNo) }
+
+func contextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDCtxKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation id of the statement execution
+// from the context passed to the Hooks methods.
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDCtxKey{}).(string)
+	return id, ok
+}
+
+// ErrorCorrelationID returns the correlation id of the statement execution a database error was raised by.
+func ErrorCorrelationID(err error) (string, bool) {
+	var e interface{ CorrelationID() string }
+	if !errors.As(err, &e) {
+		return "", false
+	}
+	id := e.CorrelationID()
+	return id, id != ""
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type testCorrelationError struct{ id string }
+
+func (e testCorrelationError) Error() string         { return "test error" }
+func (e testCorrelationError) CorrelationID() string { return e.id }
+
+func testCorrelationIDContext(t *testing.T) {
+	if _, ok := CorrelationIDFromContext(context.Background()); ok {
+		t.Fatal("correlation id in background context")
+	}
+	ctx := contextWithCorrelationID(context.Background(), newCorrelationID(3, 42))
+	id, ok := CorrelationIDFromContext(ctx)
+	if !ok || id != "3-42" {
+		t.Fatalf("correlation id %s - expected %s", id, "3-42")
+	}
+}
+
+func testCorrelationIDError(t *testing.T) {
+	tests := []struct {
+		err error
+		id  string
+		ok  bool
+	}{
+		{errors.New("test error"), "", false},
+		{testCorrelationError{}, "", false},
+		{testCorrelationError{id: "1-2"}, "1-2", true},
+		{fmt.Errorf("wrapped: %w", testCorrelationError{id: "1-2"}), "1-2", true},
+	}
+
+	for i, test := range tests {
+		id, ok := ErrorCorrelationID(test.err)
+		if id != test.id || ok != test.ok {
+			t.Fatalf("test %d: correlation id %s %t - expected %s %t", i, id, ok, test.id, test.ok)
+		}
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"context", testCorrelationIDContext},
+		{"error", testCorrelationIDError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// ErrCursorNotBound is returned by the Cursor methods if the cursor is not bound to a scrollable result set.
+var ErrCursorNotBound = errors.New("cursor is not bound to a scrollable result set")
+
+/*
+Cursor positions the rows of a scrollable result set, e.g. to page through the result of a query in a user interface
+without reading all rows up to the requested page.
+
+As database/sql does not give access to the driver rows, a cursor is passed via the context of the query
+(see WithCursor). The query then requests a scrollable result set from the database server and binds the cursor
+to the returned rows. The cursor methods position the rows, so that the following call of sql.Rows.Next returns
+the row at the new position:
+
+	cur := new(driver.Cursor)
+	rows, err := db.QueryContext(driver.WithCursor(ctx, cur), "select * from t order by id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	if err := cur.SeekAbsolute(101); err != nil { // page 11 with 10 rows per page
+		log.Fatal(err)
+	}
+	for i := 0; i < 10 && rows.Next(); i++ {
+		...
+	}
+
+Rows are numbered starting with 1, negative absolute positions count from the last row (-1: last row). A position
+after the last row lets sql.Rows.Next return false. The cursor must not be used concurrently with the rows and
+not after the rows are closed. Scrollable result sets are kept open on the server until the rows are closed
+and are not prefetched (see Connector.SetPrefetch).
+*/
+type Cursor struct {
+	rows p.ScrollableRows
+}
+
+type cursorCtxKey struct{}
+
+// WithCursor returns a context which lets a query executed with the context request a scrollable result set
+// bound to cur.
+func WithCursor(ctx context.Context, cur *Cursor) context.Context {
+	return context.WithValue(ctx, cursorCtxKey{}, cur)
+}
+
+func cursorFromContext(ctx context.Context) (*Cursor, bool) {
+	cur, ok := ctx.Value(cursorCtxKey{}).(*Cursor)
+	return cur, ok && cur != nil
+}
+
+// bindCursor binds the cursor requested via WithCursor (if any) to the scrollable query result rows.
+func bindCursor(ctx context.Context, rows driver.Rows) {
+	cur, ok := cursorFromContext(ctx)
+	if !ok {
+		return
+	}
+	cur.rows = nil
+	if sr, ok := rows.(p.ScrollableRows); ok && sr.Scrollable() {
+		cur.rows = sr
+	}
+}
+
+func (c *Cursor) scroll(mode p.ScrollMode, n int) error {
+	if c.rows == nil {
+		return ErrCursorNotBound
+	}
+	return c.rows.Scroll(mode, n)
+}
+
+// SeekAbsolute positions the rows at row n (1: first row, -1: last row).
+func (c *Cursor) SeekAbsolute(n int) error { return c.scroll(p.ScrollAbsolute, n) }
+
+// SeekRelative moves the position of the rows by n rows relative to the row returned next by sql.Rows.Next,
+// i.e. SeekRelative(0) does not change the position and SeekRelative(-1) returns the last row read again.
+func (c *Cursor) SeekRelative(n int) error { return c.scroll(p.ScrollRelative, n) }
+
+// First positions the rows at the first row.
+func (c *Cursor) First() error { return c.scroll(p.ScrollFirst, 0) }
+
+// Last positions the rows at the last row.
+func (c *Cursor) Last() error { return c.scroll(p.ScrollLast, 0) }
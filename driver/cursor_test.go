@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockCursor(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const numRow = 100
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i + 1)}
+	}
+	stmt := &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Rows: rows}
+	s.Handle("select id from t", stmt)
+	s.Handle("select id from t where id > ?", stmt)
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetFetchSize(10)
+	if err := connector.SetPrefetch(2); err != nil { // scrollable result sets are not prefetched
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// next returns the ids of the next n rows (less if the end of the result set is reached).
+	next := func(t *testing.T, r *sql.Rows, n int) []int {
+		var ids []int
+		for i := 0; i < n && r.Next(); i++ {
+			var id int
+			if err := r.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		read  int // number of rows read before seek
+		seek  func(*driver.Cursor) error
+		ids   []int // ids of the next rows after seek
+	}{
+		{"absolute", "select id from t", nil, 0, func(c *driver.Cursor) error { return c.SeekAbsolute(50) }, []int{50, 51, 52}},
+		{"absoluteFromEnd", "select id from t", nil, 0, func(c *driver.Cursor) error { return c.SeekAbsolute(-2) }, []int{99, 100}},
+		{"absoluteAfterEnd", "select id from t", nil, 0, func(c *driver.Cursor) error { return c.SeekAbsolute(numRow + 1) }, nil},
+		{"relativeForward", "select id from t", nil, 3, func(c *driver.Cursor) error { return c.SeekRelative(10) }, []int{14, 15}},
+		{"relativeBackward", "select id from t", nil, 25, func(c *driver.Cursor) error { return c.SeekRelative(-1) }, []int{25, 26}},
+		{"relativeZero", "select id from t", nil, 5, func(c *driver.Cursor) error { return c.SeekRelative(0) }, []int{6, 7}},
+		{"relativeAtEnd", "select id from t", nil, numRow, func(c *driver.Cursor) error { return c.SeekRelative(-3) }, []int{98, 99, 100}},
+		{"first", "select id from t", nil, 42, func(c *driver.Cursor) error { return c.First() }, []int{1, 2}},
+		{"last", "select id from t", nil, 0, func(c *driver.Cursor) error { return c.Last() }, []int{100}},
+		{"prepared", "select id from t where id > ?", []interface{}{"0"}, 0, func(c *driver.Cursor) error { return c.SeekAbsolute(31) }, []int{31, 32, 33}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cur := new(driver.Cursor)
+			r, err := db.QueryContext(driver.WithCursor(context.Background(), cur), test.query, test.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			if ids := next(t, r, test.read); len(ids) != test.read {
+				t.Fatalf("number of rows read %d - expected %d", len(ids), test.read)
+			}
+			if err := test.seek(cur); err != nil {
+				t.Fatal(err)
+			}
+			n := len(test.ids)
+			if n == 0 || test.ids[n-1] == numRow { // check end of result set
+				n++
+			}
+			if ids := next(t, r, n); !reflect.DeepEqual(ids, test.ids) {
+				t.Fatalf("ids %v - expected %v", ids, test.ids)
+			}
+		})
+	}
+
+	t.Run("notBound", func(t *testing.T) {
+		cur := new(driver.Cursor)
+		if err := cur.SeekAbsolute(1); !errors.Is(err, driver.ErrCursorNotBound) {
+			t.Fatalf("error %v - expected %v", err, driver.ErrCursorNotBound)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		cur := new(driver.Cursor)
+		r, err := db.QueryContext(driver.WithCursor(context.Background(), cur), "select id from t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cur.SeekAbsolute(0); err == nil {
+			t.Fatal("expected invalid position error")
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := cur.First(); err == nil {
+			t.Fatal("expected error seeking closed rows")
+		}
+		// connection is still usable
+		if ids := func() []int {
+			r, err := db.Query("select id from t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			return next(t, r, numRow+1)
+		}(); len(ids) != numRow {
+			t.Fatalf("number of rows %d - expected %d", len(ids), numRow)
+		}
+	})
+}
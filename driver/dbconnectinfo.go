@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+/*
+DBConnectInfo contains the connect information of a database (tenant) returned by the database server.
+
+It is intended to diagnose connections to a wrong tenant of a multi tenant database system: if the connection
+is not connected to the requested database, Host and Port are the address the database is served at.
+*/
+type DBConnectInfo struct {
+	DatabaseName string // name of the requested database
+	Host         string // host of the database (if not connected)
+	Port         int    // port of the database (if not connected)
+	IsConnected  bool   // true, if the connection is connected to the database
+}
+
+func newDBConnectInfo(ci *p.DBConnectInfo) *DBConnectInfo {
+	return &DBConnectInfo{DatabaseName: ci.DatabaseName, Host: ci.Host, Port: ci.Port, IsConnected: ci.IsConnected}
+}
+
+// DBConnectInfo implements the Conn interface.
+func (c *conn) DBConnectInfo(ctx context.Context, databaseName string) (*DBConnectInfo, error) {
+	c.session.Lock()
+	defer c.session.Unlock()
+
+	if c.session.IsBad() {
+		return nil, driver.ErrBadConn
+	}
+	if c.session.InQuery() {
+		return nil, ErrNestedQuery
+	}
+
+	var ci *p.DBConnectInfo
+	if err := c.call(ctx, opDBConnectInfo, "", func() (err error) {
+		ci, err = c.session.DBConnectInfo(databaseName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return newDBConnectInfo(ci), nil
+}
+
+// DBConnectInfo requests the connect information of the database (tenant) databaseName from the server.
+func (c *NativeConn) DBConnectInfo(ctx context.Context, databaseName string) (*DBConnectInfo, error) {
+	return c.conn.DBConnectInfo(ctx, databaseName)
+}
+
+// DBConnectInfo requests the connect information of the database (tenant) databaseName via a new connection
+// of the connector.
+func (c *Connector) DBConnectInfo(ctx context.Context, databaseName string) (*DBConnectInfo, error) {
+	dc, err := newConn(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	defer dc.Close()
+	return dc.(*conn).DBConnectInfo(ctx, databaseName)
+}
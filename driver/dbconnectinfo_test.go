@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockDBConnectInfo(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	host, portStr, err := net.SplitHostPort(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+
+	tests := []struct {
+		databaseName string
+		info         driver.DBConnectInfo
+	}{
+		{"MOCK", driver.DBConnectInfo{DatabaseName: "MOCK", IsConnected: true}},
+		{"mock", driver.DBConnectInfo{DatabaseName: "mock", IsConnected: true}},
+		{"TENANT", driver.DBConnectInfo{DatabaseName: "TENANT", Host: host, Port: port}},
+	}
+
+	for _, test := range tests {
+		info, err := connector.DBConnectInfo(context.Background(), test.databaseName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *info != test.info {
+			t.Fatalf("database %s: connect info %v - expected %v", test.databaseName, *info, test.info)
+		}
+	}
+}
@@ -5,7 +5,9 @@
 package driver
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
@@ -32,19 +34,16 @@ var natZero = big.NewInt(0)
 var natOne = big.NewInt(1)
 var natTen = big.NewInt(10)
 
-var nat = []*big.Int{
-	natOne,                  //10^0
-	natTen,                  //10^1
-	big.NewInt(100),         //10^2
-	big.NewInt(1000),        //10^3
-	big.NewInt(10000),       //10^4
-	big.NewInt(100000),      //10^5
-	big.NewInt(1000000),     //10^6
-	big.NewInt(10000000),    //10^7
-	big.NewInt(100000000),   //10^8
-	big.NewInt(1000000000),  //10^9
-	big.NewInt(10000000000), //10^10
-}
+// nat contains the powers of ten 10^0 ... 10^(2*dec128Digits)
+// (covering the usual decimal scales and the rounding of dec128 values).
+var nat = func() []*big.Int {
+	nat := make([]*big.Int, 2*dec128Digits+1)
+	nat[0], nat[1] = natOne, natTen
+	for i := 2; i < len(nat); i++ {
+		nat[i] = new(big.Int).Mul(nat[i-1], natTen)
+	}
+	return nat
+}()
 
 const lg10 = math.Ln10 / math.Ln2 // ~log2(10)
 
@@ -72,22 +71,32 @@ var bigRatFree = sync.Pool{
 }
 
 // A Decimal is the driver representation of a database decimal field value as big.Rat.
+// A *big.Rat can be bound as decimal parameter directly and scanned via a *Decimal conversion ((*Decimal)(r)).
 type Decimal big.Rat
 
-// Scan implements the database/sql/Scanner interface.
-func (d *Decimal) Scan(src interface{}) error {
-
+// decimalBytes returns the database decimal value of src.
+func decimalBytes(src interface{}) ([]byte, error) {
 	b, ok := src.([]byte)
 	if !ok {
-		return fmt.Errorf("decimal: invalid data type %T", src)
+		return nil, fmt.Errorf("decimal: invalid data type %T", src)
 	}
 
 	if len(b) != decimalSize {
-		return fmt.Errorf("decimal: invalid size %d of %v - %d expected", len(b), b, decimalSize)
+		return nil, fmt.Errorf("decimal: invalid size %d of %v - %d expected", len(b), b, decimalSize)
 	}
 
 	if (b[15] & 0x60) == 0x60 {
-		return fmt.Errorf("decimal: format (infinity, nan, ...) not supported : %v", b)
+		return nil, fmt.Errorf("decimal: format (infinity, nan, ...) not supported : %v", b)
+	}
+	return b, nil
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (d *Decimal) Scan(src interface{}) error {
+
+	b, err := decimalBytes(src)
+	if err != nil {
+		return err
 	}
 
 	v := (*big.Rat)(d)
@@ -120,6 +129,11 @@ func (d *Decimal) Scan(src interface{}) error {
 
 // Value implements the database/sql/Valuer interface.
 func (d Decimal) Value() (driver.Value, error) {
+	// fast path: exact decimal with an uint64 mantissa
+	if m, neg, exp, ok := convertRatToDecimal64((*big.Rat)(&d)); ok {
+		return encodeDecimal64(m, neg, exp), nil
+	}
+
 	m := bigIntFree.Get().(*big.Int)
 	neg, exp, df := convertRatToDecimal((*big.Rat)(&d), m, dec128Digits, dec128MinExp, dec128MaxExp)
 
@@ -142,6 +156,72 @@ func (d Decimal) Value() (driver.Value, error) {
 	return v, err
 }
 
+// abs64 returns the absolute value of x if it fits into an uint64.
+func abs64(x *big.Int) (uint64, bool) {
+	if x.BitLen() > 64 {
+		return 0, false
+	}
+	var r uint64
+	for i, w := range x.Bits() {
+		r |= uint64(w) << uint(i*bits.UintSize)
+	}
+	return r, true
+}
+
+/*
+convertRatToDecimal64 is the allocation free fast path of convertRatToDecimal for values
+which can be represented exactly by an uint64 mantissa (integers and fractions with
+a denominator of the form 2^a*5^b). ok is false, if x does not fit.
+*/
+func convertRatToDecimal64(x *big.Rat) (m uint64, neg bool, exp int, ok bool) {
+	if m, ok = abs64(x.Num()); !ok {
+		return 0, false, 0, false
+	}
+	if m == 0 {
+		return 0, false, 0, true
+	}
+	neg = x.Sign() < 0
+
+	if !x.IsInt() {
+		q, ok := abs64(x.Denom())
+		if !ok {
+			return 0, false, 0, false
+		}
+		// q == 2^n2 * 5^n5 -> x = m * 2^(k-n2) * 5^(k-n5) / 10^k with k = max(n2, n5)
+		n2 := bits.TrailingZeros64(q)
+		q >>= uint(n2)
+		n5 := 0
+		for q%5 == 0 {
+			q /= 5
+			n5++
+		}
+		if q != 1 {
+			return 0, false, 0, false
+		}
+		k := max(n2, n5)
+		for i := 0; i < k-n2; i++ {
+			if m > math.MaxUint64/2 {
+				return 0, false, 0, false
+			}
+			m *= 2
+		}
+		for i := 0; i < k-n5; i++ {
+			if m > math.MaxUint64/5 {
+				return 0, false, 0, false
+			}
+			m *= 5
+		}
+		exp = -k
+	}
+
+	// norm
+	for m%10 == 0 {
+		m /= 10
+		exp++
+	}
+	return m, neg, exp, true
+}
+
 func convertRatToDecimal(x *big.Rat, m *big.Int, digits, minExp, maxExp int) (bool, int, decFlags) {
 
 	neg := x.Sign() < 0 //store sign
@@ -275,38 +355,33 @@ func decodeDecimal(b []byte, m *big.Int) (bool, int) {
 	neg := (b[15] & 0x80) != 0
 	exp := int((((uint16(b[15])<<8)|uint16(b[14]))<<1)>>2) - dec128Bias
 
-	b14 := b[14]  // save b[14]
-	b[14] &= 0x01 // keep the mantissa bit (rest: sign and exp)
-
-	//most significand byte
-	msb := 14
-	for msb > 0 {
-		if b[msb] != 0 {
-			break
-		}
-		msb--
+	// fast path: mantissa fits into uint64
+	if b[8]|b[9]|b[10]|b[11]|b[12]|b[13]|(b[14]&0x01) == 0 {
+		m.SetUint64(binary.LittleEndian.Uint64(b[:8]))
+		return neg, exp
 	}
 
-	//calc number of words
-	numWords := (msb / _S) + 1
-	w := make([]big.Word, numWords)
-
-	k := numWords - 1
-	d := big.Word(0)
-	for i := msb; i >= 0; i-- {
-		d |= big.Word(b[i])
-		if k*_S == i {
-			w[k] = d
-			k--
-			d = 0
-		}
-		d <<= 8
+	// little endian db decimal format -> big endian mantissa
+	// (big.Int.SetBytes reuses the words of m instead of allocating new ones)
+	var be [15]byte
+	for i := 0; i < 15; i++ {
+		be[14-i] = b[i]
 	}
-	b[14] = b14 // restore b[14]
-	m.SetBits(w)
+	be[0] &= 0x01 // keep the mantissa bit (rest: sign and exp)
+	m.SetBytes(be[:])
 	return neg, exp
 }
 
+// decodeDecimal64 is the uint64 mantissa version of decodeDecimal. ok is false, if the mantissa does not fit.
+func decodeDecimal64(b []byte) (m uint64, neg bool, exp int, ok bool) {
+	if b[8]|b[9]|b[10]|b[11]|b[12]|b[13]|(b[14]&0x01) != 0 {
+		return 0, false, 0, false
+	}
+	neg = (b[15] & 0x80) != 0
+	exp = int((((uint16(b[15])<<8)|uint16(b[14]))<<1)>>2) - dec128Bias
+	return binary.LittleEndian.Uint64(b[:8]), neg, exp, true
+}
+
 func encodeDecimal(m *big.Int, neg bool, exp int) (driver.Value, error) {
 
 	b := make([]byte, decimalSize)
@@ -332,6 +407,21 @@ func encodeDecimal(m *big.Int, neg bool, exp int) (driver.Value, error) {
 	return b, nil
 }
 
+// encodeDecimal64 is the uint64 mantissa version of encodeDecimal.
+func encodeDecimal64(m uint64, neg bool, exp int) driver.Value {
+	b := make([]byte, decimalSize)
+	binary.LittleEndian.PutUint64(b, m)
+
+	exp += dec128Bias
+	b[14] |= (byte(exp) << 1)
+	b[15] = byte(uint16(exp) >> 7)
+
+	if neg {
+		b[15] |= 0x80
+	}
+	return b
+}
+
 // NullDecimal represents an Decimal that may be null.
 // NullDecimal implements the Scanner interface so
 // it can be used as a scan destination, similar to NullString.
@@ -364,3 +454,61 @@ func (n NullDecimal) Value() (driver.Value, error) {
 	}
 	return n.Decimal.Value()
 }
+
+/*
+DecimalMarshaler is the interface implemented by decimal types (e.g. wrappers of third-party decimal types),
+which can be bound as decimal parameters without a lossy conversion via float64.
+MarshalDecimal returns the exact value as big.Rat or nil for NULL.
+*/
+type DecimalMarshaler interface {
+	MarshalDecimal() (*big.Rat, error)
+}
+
+/*
+DecimalUnmarshaler is the interface implemented by decimal types which can be scanned from
+database decimal fields via NewDecimalScanner.
+UnmarshalDecimal sets the value to the exact value r or to NULL if r is nil.
+*/
+type DecimalUnmarshaler interface {
+	UnmarshalDecimal(r *big.Rat) error
+}
+
+// NewDecimalScanner returns a scan destination for decimal fields setting the value of u.
+func NewDecimalScanner(u DecimalUnmarshaler) sql.Scanner { return decimalScanner{u: u} }
+
+type decimalScanner struct {
+	u DecimalUnmarshaler
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (s decimalScanner) Scan(src interface{}) error {
+	if src == nil {
+		return s.u.UnmarshalDecimal(nil)
+	}
+	d := new(Decimal)
+	if err := d.Scan(src); err != nil {
+		return err
+	}
+	return s.u.UnmarshalDecimal((*big.Rat)(d))
+}
+
+// convertDecimalArg converts *big.Rat and DecimalMarshaler arguments into database decimal values.
+// ok is false, if v is not a decimal argument.
+func convertDecimalArg(v interface{}) (dv driver.Value, ok bool, err error) {
+	var r *big.Rat
+	switch v := v.(type) {
+	case *big.Rat:
+		r = v
+	case DecimalMarshaler:
+		if r, err = v.MarshalDecimal(); err != nil {
+			return nil, true, err
+		}
+	default:
+		return nil, false, nil
+	}
+	if r == nil {
+		return nil, true, nil
+	}
+	dv, err = (*Decimal)(r).Value()
+	return dv, true, err
+}
@@ -5,6 +5,8 @@
 package driver
 
 import (
+	"bytes"
+	"math"
 	"math/big"
 	"testing"
 )
@@ -98,6 +100,207 @@ func testConvertRat(t *testing.T) {
 	}
 }
 
+func testDecimalFastPath(t *testing.T) {
+	testData := []*big.Rat{
+		new(big.Rat).SetFrac64(0, 1),
+		new(big.Rat).SetFrac64(1, 1),
+		new(big.Rat).SetFrac64(-1, 1),
+		new(big.Rat).SetFrac64(1000, 1),
+		new(big.Rat).SetFrac64(-12345, 100),
+		new(big.Rat).SetFrac64(11, 2),
+		new(big.Rat).SetFrac64(1, 1<<20),
+		new(big.Rat).SetFrac64(7, 3125),
+		new(big.Rat).SetFrac64(math.MaxInt64, 1),
+		new(big.Rat).SetFrac64(math.MinInt64, 1),
+		new(big.Rat).SetFrac64(math.MaxInt64, 1000),
+		new(big.Rat).SetInt(new(big.Int).SetUint64(math.MaxUint64)),
+	}
+
+	m := new(big.Int)
+
+	for i, x := range testData {
+		m64, neg64, exp64, ok := convertRatToDecimal64(x)
+		if !ok {
+			t.Fatalf("value %d %s: fast path not applicable", i, x)
+		}
+		neg, exp, _ := convertRatToDecimal(x, m, dec128Digits, dec128MinExp, dec128MaxExp)
+		if !m.IsUint64() || m.Uint64() != m64 || neg != neg64 || exp != exp64 {
+			t.Fatalf("value %d %s: m %d neg %t exp %d - expected m %s neg %t exp %d", i, x, m64, neg64, exp64, m, neg, exp)
+		}
+
+		v, _ := encodeDecimal(m, neg, exp)
+		if v64 := encodeDecimal64(m64, neg64, exp64); !bytes.Equal(v64.([]byte), v.([]byte)) {
+			t.Fatalf("value %d %s: encoded %v - expected %v", i, x, v64, v)
+		}
+
+		d := new(Decimal)
+		if err := d.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if (*big.Rat)(d).Cmp(x) != 0 {
+			t.Fatalf("value %d: scanned %s - expected %s", i, (*big.Rat)(d), x)
+		}
+	}
+
+	// not applicable
+	testData = []*big.Rat{
+		new(big.Rat).SetFrac64(1, 3),
+		new(big.Rat).SetFrac64(1, 10).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 70)),
+		new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 64)),
+		new(big.Rat).SetFrac64(math.MaxInt64, 3125),
+	}
+	for i, x := range testData {
+		if _, _, _, ok := convertRatToDecimal64(x); ok {
+			t.Fatalf("value %d %s: fast path applied", i, x)
+		}
+	}
+}
+
+func testFixed8(t *testing.T) {
+	tests := []struct {
+		in    Fixed8
+		scale int // scale of scan variable
+		out   Fixed8
+		err   error
+	}{
+		{Fixed8{12345, 2}, 0, Fixed8{12345, 2}, nil},
+		{Fixed8{12345, 2}, 4, Fixed8{1234500, 4}, nil},
+		{Fixed8{-15, 1}, 2, Fixed8{-150, 2}, nil},
+		{Fixed8{0, 0}, 2, Fixed8{0, 2}, nil},
+		{Fixed8{math.MaxInt64, 0}, 0, Fixed8{math.MaxInt64, 0}, nil},
+		{Fixed8{math.MinInt64, 3}, 0, Fixed8{math.MinInt64, 3}, nil},
+		{Fixed8{123, -2}, 0, Fixed8{12300, 0}, nil},
+		{Fixed8{math.MaxInt64, 0}, 1, Fixed8{}, ErrDecimalOutOfRange},
+	}
+
+	for i, test := range tests {
+		v, err := test.in.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := Fixed8{Scale: test.scale}
+		err = out.Scan(v)
+		switch {
+		case test.err != nil:
+			if err != test.err {
+				t.Fatalf("test %d: error %v - expected %v", i, err, test.err)
+			}
+		case err != nil:
+			t.Fatalf("test %d: %s", i, err)
+		case out != test.out:
+			t.Fatalf("test %d: value %v - expected %v", i, out, test.out)
+		case out.Rat().Cmp(test.in.Rat()) != 0:
+			t.Fatalf("test %d: rat %s - expected %s", i, out.Rat(), test.in.Rat())
+		}
+	}
+
+	// values exceeding the uint64 mantissa
+	v, err := (*Decimal)(new(big.Rat).SetInt(new(big.Int).Lsh(natOne, 80))).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Fixed8).Scan(v); err != ErrDecimalOutOfRange {
+		t.Fatalf("error %v - expected %v", err, ErrDecimalOutOfRange)
+	}
+}
+
+func testFixed12(t *testing.T) {
+	maxUnscaled := new(big.Int).Sub(new(big.Int).Lsh(natOne, 95), natOne)
+	minUnscaled := new(big.Int).Neg(new(big.Int).Lsh(natOne, 95))
+
+	tests := []struct {
+		in       Fixed12
+		unscaled *big.Int
+		err      error
+	}{
+		{NewFixed12(12345, 2), big.NewInt(12345), nil},
+		{NewFixed12(-12345, 2), big.NewInt(-12345), nil},
+		{NewFixed12(math.MinInt64, 0), big.NewInt(math.MinInt64), nil},
+		{Fixed12{Hi: 1, Lo: 5, Scale: 4}, new(big.Int).Add(new(big.Int).Lsh(natOne, 64), big.NewInt(5)), nil},
+		{Fixed12{Hi: -2, Lo: 0, Scale: 1}, new(big.Int).Neg(new(big.Int).Lsh(natOne, 65)), nil},
+		{Fixed12{Hi: math.MaxInt32, Lo: math.MaxUint64}, maxUnscaled, nil},
+		{Fixed12{Hi: math.MinInt32}, minUnscaled, nil},
+	}
+
+	for i, test := range tests {
+		if test.in.Unscaled().Cmp(test.unscaled) != 0 {
+			t.Fatalf("test %d: unscaled %s - expected %s", i, test.in.Unscaled(), test.unscaled)
+		}
+		v, err := test.in.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out Fixed12
+		if err := out.Scan(v); err != nil {
+			t.Fatalf("test %d: %s", i, err)
+		}
+		if out.Rat().Cmp(test.in.Rat()) != 0 {
+			t.Fatalf("test %d: rat %s - expected %s", i, out.Rat(), test.in.Rat())
+		}
+	}
+
+	v, err := (*Decimal)(new(big.Rat).SetInt(new(big.Int).Add(maxUnscaled, natOne))).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Fixed12).Scan(v); err != ErrDecimalOutOfRange {
+		t.Fatalf("error %v - expected %v", err, ErrDecimalOutOfRange)
+	}
+}
+
+// testDecimal is a decimal type implementing DecimalMarshaler and DecimalUnmarshaler.
+type testDecimal struct {
+	r     *big.Rat
+	valid bool
+}
+
+func (d testDecimal) MarshalDecimal() (*big.Rat, error) {
+	if !d.valid {
+		return nil, nil
+	}
+	return d.r, nil
+}
+
+func (d *testDecimal) UnmarshalDecimal(r *big.Rat) error {
+	d.r, d.valid = r, r != nil
+	return nil
+}
+
+func testDecimalArg(t *testing.T) {
+	r := big.NewRat(-123456789, 1000)
+
+	tests := []struct {
+		arg   interface{}
+		valid bool
+	}{
+		{r, true},
+		{testDecimal{r: r, valid: true}, true},
+		{testDecimal{}, false},
+		{(*big.Rat)(nil), false},
+	}
+
+	for i, test := range tests {
+		v, ok, err := convertDecimalArg(test.arg)
+		if err != nil || !ok {
+			t.Fatalf("test %d: ok %t error %v", i, ok, err)
+		}
+		var d testDecimal
+		if err := NewDecimalScanner(&d).Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if d.valid != test.valid {
+			t.Fatalf("test %d: valid %t - expected %t", i, d.valid, test.valid)
+		}
+		if d.valid && d.r.Cmp(r) != 0 {
+			t.Fatalf("test %d: value %s - expected %s", i, d.r, r)
+		}
+	}
+
+	if _, ok, _ := convertDecimalArg(1.5); ok {
+		t.Fatal("float64 is not a decimal argument")
+	}
+}
+
 func TestDecimal(t *testing.T) {
 	tests := []struct {
 		name string
@@ -106,6 +309,10 @@ func TestDecimal(t *testing.T) {
 		{"decimalInfo", testDecimalInfo},
 		{"digits10", testDigits10},
 		{"convertRat", testConvertRat},
+		{"fastPath", testDecimalFastPath},
+		{"fixed8", testFixed8},
+		{"fixed12", testFixed12},
+		{"decimalArg", testDecimalArg},
 	}
 
 	for _, test := range tests {
@@ -114,3 +321,25 @@ func TestDecimal(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkDecimal(b *testing.B) {
+	d := (*Decimal)(new(big.Rat).SetFrac64(-123456789, 1000))
+	v, err := d.Value()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Value", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.Value()
+		}
+	})
+	b.Run("Scan", func(b *testing.B) {
+		d := new(Decimal)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d.Scan(v)
+		}
+	})
+}
@@ -0,0 +1,325 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dial
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// FaultOp is the connection operation a fault is injected into.
+type FaultOp int
+
+// FaultOp constants.
+const (
+	FaultRead  FaultOp = iota // database to client
+	FaultWrite                // client to database
+	FaultDial                 // connection establishment
+)
+
+// FaultKind is the kind of an injected fault.
+type FaultKind int
+
+// FaultKind constants.
+const (
+	FaultDelay      FaultKind = iota // delay the operation by Fault.Delay
+	FaultDisconnect                  // close the connection (mid-stream disconnect or truncated message if Fault.Offset > 0)
+	FaultDeadline                    // fail the operation with a timeout error like an expired deadline
+)
+
+/*
+A Fault describes a network fault injected by a FaultDialer.
+
+The fault position is defined in terms of hdb protocol messages: the fault becomes active after Messages protocol
+messages got transferred in direction Op and Offset bytes of the following message got transferred. The protocol
+prolog counts as first message of a connection. For connections already established when the fault is injected,
+messages are counted from the next message on.
+A fault triggers once on the first connection reaching the fault position.
+*/
+type Fault struct {
+	Op       FaultOp
+	Kind     FaultKind
+	Messages int
+	Offset   int
+	Delay    time.Duration // FaultDelay only
+}
+
+// ErrFaultDisconnect is returned by writes to connections closed by an injected fault.
+var ErrFaultDisconnect = errors.New("connection closed by injected fault")
+
+// faultTimeoutError is returned by operations failing because of an injected deadline fault.
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "i/o timeout (injected fault)" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
+
+var _ net.Error = faultTimeoutError{}
+
+type faultState struct {
+	Fault
+	triggered bool
+}
+
+/*
+FaultDialer is a Dialer injecting network faults (delayed reads, truncated messages, mid-stream disconnects
+and deadline expiry) into the connections it dials. It is intended to be used in tests verifying retry and failover
+logic (see driver.Connector.SetDialer).
+*/
+type FaultDialer struct {
+	dialer Dialer
+
+	mu     sync.Mutex
+	faults []*faultState
+}
+
+// NewFaultDialer returns a new FaultDialer using dialer to dial connections. If dialer is nil, DefaultDialer is used.
+func NewFaultDialer(dialer Dialer) *FaultDialer {
+	if dialer == nil {
+		dialer = DefaultDialer
+	}
+	return &FaultDialer{dialer: dialer}
+}
+
+// Inject injects faults into connections dialed already and dialed later on.
+func (d *FaultDialer) Inject(faults ...Fault) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range faults {
+		d.faults = append(d.faults, &faultState{Fault: f})
+	}
+}
+
+// Reset removes all faults.
+func (d *FaultDialer) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.faults = nil
+}
+
+// Pending returns the number of faults not triggered yet.
+func (d *FaultDialer) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cnt := 0
+	for _, f := range d.faults {
+		if !f.triggered {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// DialContext implements the Dialer interface.
+func (d *FaultDialer) DialContext(ctx context.Context, address string, options DialerOptions) (net.Conn, error) {
+	for {
+		f := d.dialFault()
+		if f == nil {
+			break
+		}
+		switch f.Kind {
+		case FaultDelay:
+			time.Sleep(f.Delay)
+		case FaultDeadline:
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: faultTimeoutError{}}
+		default:
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: ErrFaultDisconnect}
+		}
+	}
+	conn, err := d.dialer.DialContext(ctx, address, options)
+	if err != nil {
+		return nil, err
+	}
+	return &faultConn{
+		Conn:     conn,
+		d:        d,
+		rdPos:    msgPos{size: initReplySize},
+		wrPos:    msgPos{size: initRequestSize},
+		baseline: map[*faultState]int{},
+	}, nil
+}
+
+func (d *FaultDialer) dialFault() *faultState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, f := range d.faults {
+		if f.Op == FaultDial && !f.triggered {
+			f.triggered = true
+			return f
+		}
+	}
+	return nil
+}
+
+// hdb protocol framing
+const (
+	initRequestSize     = 14
+	initReplySize       = 8
+	messageHeaderSize   = 32
+	varPartLengthOffset = 12
+)
+
+// msgPos tracks the protocol message position of a connection stream.
+type msgPos struct {
+	msg    int // number of transferred messages
+	off    int // byte offset in current message
+	size   int // size of current message (0: message header not yet complete)
+	header [messageHeaderSize]byte
+}
+
+// step returns the number of bytes (max n) until the next framing event (header complete or message end).
+func (p *msgPos) step(n int) int {
+	var k int
+	if p.size == 0 {
+		k = messageHeaderSize - p.off
+	} else {
+		k = p.size - p.off
+	}
+	if k > n {
+		return n
+	}
+	return k
+}
+
+func (p *msgPos) advance(b []byte) {
+	for len(b) > 0 {
+		k := p.step(len(b))
+		if p.size == 0 {
+			copy(p.header[p.off:], b[:k])
+			if p.off+k == messageHeaderSize {
+				p.size = messageHeaderSize + int(binary.LittleEndian.Uint32(p.header[varPartLengthOffset:]))
+			}
+		}
+		p.off += k
+		b = b[k:]
+		if p.size != 0 && p.off == p.size {
+			p.msg++
+			p.off, p.size = 0, 0
+		}
+	}
+}
+
+// until returns the number of bytes of b transferred before position msg, off is reached
+// and whether the position is reached within b.
+func (p msgPos) until(b []byte, msg, off int) (int, bool) {
+	n := 0
+	for {
+		if p.msg > msg || (p.msg == msg && p.off >= off) {
+			return n, true
+		}
+		if n == len(b) {
+			return n, false
+		}
+		k := p.step(len(b) - n)
+		if p.msg == msg && off-p.off < k {
+			k = off - p.off
+		}
+		p.advance(b[n : n+k])
+		n += k
+	}
+}
+
+type faultConn struct {
+	net.Conn
+	d            *FaultDialer
+	rdPos, wrPos msgPos
+	baseline     map[*faultState]int // first message counted per fault
+}
+
+// next returns the next fault for op triggering within b and the number of bytes to transfer before.
+func (c *faultConn) next(op FaultOp, pos *msgPos, b []byte) (*faultState, int) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	var next *faultState
+	min := 0
+	for _, f := range c.d.faults {
+		if f.Op != op || f.triggered {
+			continue
+		}
+		base, ok := c.baseline[f]
+		if !ok {
+			base = pos.msg
+			if pos.off != 0 { // count from next message on
+				base++
+			}
+			c.baseline[f] = base
+		}
+		n, ok := pos.until(b, base+f.Messages, f.Offset)
+		if ok && (next == nil || n < min) {
+			next, min = f, n
+		}
+	}
+	return next, min
+}
+
+// apply applies fault f.
+func (c *faultConn) apply(f *faultState, op FaultOp) error {
+	c.d.mu.Lock()
+	f.triggered = true
+	c.d.mu.Unlock()
+
+	switch f.Kind {
+	case FaultDelay:
+		time.Sleep(f.Delay)
+		return nil
+	case FaultDeadline:
+		return faultTimeoutError{}
+	default:
+		c.Conn.Close()
+		if op == FaultRead {
+			return io.EOF
+		}
+		return ErrFaultDisconnect
+	}
+}
+
+func (c *faultConn) Read(b []byte) (int, error) {
+	for {
+		f, n := c.next(FaultRead, &c.rdPos, b)
+		if f == nil {
+			break
+		}
+		if n != 0 { // read up to fault position
+			b = b[:n]
+			break
+		}
+		if err := c.apply(f, FaultRead); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Read(b)
+	c.rdPos.advance(b[:n])
+	return n, err
+}
+
+func (c *faultConn) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) != 0 {
+		f, n := c.next(FaultWrite, &c.wrPos, b)
+		if f != nil && n == 0 {
+			if err := c.apply(f, FaultWrite); err != nil {
+				return written, err
+			}
+			continue
+		}
+		if f == nil {
+			n = len(b)
+		}
+		m, err := c.Conn.Write(b[:n])
+		c.wrPos.advance(b[:m])
+		written += m
+		b = b[m:]
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
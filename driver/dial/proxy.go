@@ -0,0 +1,375 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dial
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+A ProxyFunc returns the proxy server to be used for connections to the database server address.
+If the returned URL is nil, the database server is connected directly.
+
+Supported proxy URL schemes are socks5 (socks5h) for SOCKS5 proxies and http for HTTP proxies supporting
+the CONNECT method. The user information of the URL is used to authenticate at the proxy server
+(SOCKS5 username / password authentication respectively HTTP basic authentication).
+*/
+type ProxyFunc func(address string) (*url.URL, error)
+
+// ProxyURL returns a ProxyFunc that always returns proxyURL.
+func ProxyURL(proxyURL *url.URL) ProxyFunc {
+	return func(address string) (*url.URL, error) { return proxyURL, nil }
+}
+
+/*
+ProxyFromEnvironment returns the proxy server of the database server address defined by the environment
+variables ALL_PROXY and HTTPS_PROXY (or the lowercase versions thereof), where ALL_PROXY takes precedence.
+Proxy values without scheme are interpreted as HTTP proxies.
+
+The environment variable NO_PROXY (or no_proxy) is a comma separated list of hosts which are connected
+directly. Entries are either host names, matching the host and its sub domains (e.g. 'example.com' or
+'.example.com'), IP addresses, CIDR notations (e.g. '10.0.0.0/8') or '*' matching all hosts.
+An optional port restricts the match to the port.
+*/
+func ProxyFromEnvironment(address string) (*url.URL, error) {
+	proxy := getEnv("ALL_PROXY", "all_proxy")
+	if proxy == "" {
+		proxy = getEnv("HTTPS_PROXY", "https_proxy")
+	}
+	if proxy == "" || noProxy(getEnv("NO_PROXY", "no_proxy"), address) {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %q: %w", proxy, err)
+	}
+	return proxyURL, nil
+}
+
+func getEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// noProxy returns true if address matches an entry of the NO_PROXY list.
+func noProxy(list, address string) bool {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			entryHost, entryPort = entry, ""
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, "*")
+		switch {
+		case strings.HasPrefix(entryHost, "."):
+			if strings.HasSuffix(host, entryHost) || host == entryHost[1:] {
+				return true
+			}
+		case host == entryHost || strings.HasSuffix(host, "."+entryHost):
+			return true
+		}
+	}
+	return false
+}
+
+// NewProxyDialer returns a Dialer connecting to the database server via the proxy server returned by proxy,
+// using dialer to connect to the proxy server. If dialer is nil, DefaultDialer is used.
+func NewProxyDialer(proxy ProxyFunc, dialer Dialer) Dialer {
+	if dialer == nil {
+		dialer = DefaultDialer
+	}
+	return &proxyDialer{proxy: proxy, dialer: dialer}
+}
+
+type proxyDialer struct {
+	proxy  ProxyFunc
+	dialer Dialer
+}
+
+// DialContext implements the Dialer interface.
+func (d *proxyDialer) DialContext(ctx context.Context, address string, options DialerOptions) (net.Conn, error) {
+	proxyURL, err := d.proxy(address)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return d.dialer.DialContext(ctx, address, options)
+	}
+
+	var handshake func(conn net.Conn, proxyURL *url.URL, address string) (net.Conn, error)
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		handshake = socks5Connect
+	case "http":
+		handshake = httpConnect
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	proxyAddress := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddress = net.JoinHostPort(proxyURL.Hostname(), defaultProxyPort(proxyURL.Scheme))
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	conn, err := d.dialer.DialContext(ctx, proxyAddress, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// interrupt the handshake in case ctx gets done
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	proxyConn, err := handshake(conn, proxyURL, address)
+	close(done)
+	<-stopped
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("proxy %s: %w", proxyAddress, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return proxyConn, nil
+}
+
+func defaultProxyPort(scheme string) string {
+	if scheme == "http" {
+		return "80"
+	}
+	return "1080"
+}
+
+// SOCKS5 protocol constants (RFC 1928, RFC 1929).
+const (
+	socks5Version         = 0x05
+	socks5AuthNone        = 0x00
+	socks5AuthPassword    = 0x02
+	socks5AuthNoAccepted  = 0xff
+	socks5CmdConnect      = 0x01
+	socks5AddrIPv4        = 0x01
+	socks5AddrDomain      = 0x03
+	socks5AddrIPv6        = 0x04
+	socks5PasswordVersion = 0x01
+)
+
+var socks5Replies = []string{
+	"succeeded",
+	"general SOCKS server failure",
+	"connection not allowed by ruleset",
+	"network unreachable",
+	"host unreachable",
+	"connection refused",
+	"TTL expired",
+	"command not supported",
+	"address type not supported",
+}
+
+// socks5Connect establishes a connection to address via the SOCKS5 proxy connected by conn.
+// The address is sent unresolved, so that the host name is resolved by the proxy server.
+func socks5Connect(conn net.Conn, proxyURL *url.URL, address string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+	if len(host) > 255 {
+		return nil, fmt.Errorf("host name %q too long", host)
+	}
+
+	method := byte(socks5AuthNone)
+	if proxyURL.User != nil {
+		method = socks5AuthPassword
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 2)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		return nil, err
+	}
+	if b[0] != socks5Version {
+		return nil, fmt.Errorf("invalid socks version %d", b[0])
+	}
+	switch b[1] {
+	case method:
+	case socks5AuthNoAccepted:
+		return nil, errors.New("no acceptable socks authentication method")
+	default:
+		return nil, fmt.Errorf("unexpected socks authentication method %d", b[1])
+	}
+
+	if method == socks5AuthPassword {
+		username := proxyURL.User.Username()
+		password, _ := proxyURL.User.Password()
+		if len(username) > 255 || len(password) > 255 {
+			return nil, errors.New("socks username or password too long")
+		}
+		req := []byte{socks5PasswordVersion, byte(len(username))}
+		req = append(req, username...)
+		req = append(req, byte(len(password)))
+		req = append(req, password...)
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return nil, err
+		}
+		if b[1] != 0 {
+			return nil, errors.New("socks authentication failed")
+		}
+	}
+
+	req := []byte{socks5Version, socks5CmdConnect, 0}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socks5AddrIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socks5AddrIPv6)
+			req = append(req, ip...)
+		}
+	} else {
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, 0, 0)
+	binary.BigEndian.PutUint16(req[len(req)-2:], uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	// reply: version, reply, reserved, address type, bound address, bound port
+	b = make([]byte, 4)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		return nil, err
+	}
+	if b[1] != 0 {
+		if int(b[1]) < len(socks5Replies) {
+			return nil, fmt.Errorf("socks connect %s: %s", address, socks5Replies[b[1]])
+		}
+		return nil, fmt.Errorf("socks connect %s: reply %d", address, b[1])
+	}
+	var addrLen int
+	switch b[3] {
+	case socks5AddrIPv4:
+		addrLen = net.IPv4len
+	case socks5AddrIPv6:
+		addrLen = net.IPv6len
+	case socks5AddrDomain:
+		if _, err := io.ReadFull(conn, b[:1]); err != nil {
+			return nil, err
+		}
+		addrLen = int(b[0])
+	default:
+		return nil, fmt.Errorf("invalid socks address type %d", b[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// httpConnect establishes a connection to address via the HTTP proxy connected by conn using the CONNECT method.
+func httpConnect(conn net.Conn, proxyURL *url.URL, address string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	rd := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(rd, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http connect %s: %s", address, resp.Status)
+	}
+	if rd.Buffered() != 0 { // data sent by the database server already
+		return &bufferedConn{Conn: conn, rd: rd}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection reading data buffered during the proxy handshake first.
+type bufferedConn struct {
+	net.Conn
+	rd *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) { return c.rd.Read(b) }
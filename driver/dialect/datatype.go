@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Size limits of the HANA variable length types.
+const (
+	MaxVarLength        = 5000 // maximum length of NVARCHAR and VARBINARY columns
+	DefaultVarLength    = 256  // column length of strings and byte slices if no size is specified
+	MaxDecimalPrecision = 38
+)
+
+// A Column describes a table column.
+type Column struct {
+	Name          string
+	Type          reflect.Type // go type of the column values
+	DBType        string       // database type (overrides the mapping of Type)
+	Size          int          // length of character and binary types (0: DefaultVarLength)
+	Precision     int          // precision of decimal types (0: floating decimal)
+	Scale         int
+	PrimaryKey    bool
+	AutoIncrement bool // identity column
+	NotNull       bool
+	Unique        bool
+	Default       string // default value sql expression
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	bytesType       = reflect.TypeOf([]byte(nil))
+	ratType         = reflect.TypeOf(big.Rat{})
+	decimalType     = reflect.TypeOf(driver.Decimal{})
+	nullDecimalType = reflect.TypeOf(driver.NullDecimal{})
+	lobType         = reflect.TypeOf(driver.Lob{})
+	nullLobType     = reflect.TypeOf(driver.NullLob{})
+	geometryType    = reflect.TypeOf(driver.Geometry(nil))
+	nullBytesType   = reflect.TypeOf(driver.NullBytes{})
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullInt32Type   = reflect.TypeOf(sql.NullInt32{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullTimeType    = reflect.TypeOf(driver.NullTime{})
+)
+
+// DataType returns the HANA column type of column c.
+func DataType(c Column) (string, error) {
+	if c.DBType != "" {
+		return c.DBType, nil
+	}
+	if c.Type == nil {
+		return "", fmt.Errorf("column %s: missing type", c.Name)
+	}
+
+	t := c.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, nullTimeType:
+		return "TIMESTAMP", nil
+	case ratType, decimalType, nullDecimalType:
+		return decimalDataType(c)
+	case lobType, nullLobType:
+		return "BLOB", nil
+	case geometryType:
+		return "ST_GEOMETRY", nil
+	case bytesType, nullBytesType:
+		return varDataType("VARBINARY", "BLOB", c.Size), nil
+	case nullStringType:
+		return varDataType("NVARCHAR", "NCLOB", c.Size), nil
+	case nullBoolType:
+		return "BOOLEAN", nil
+	case nullInt32Type:
+		return "INTEGER", nil
+	case nullInt64Type:
+		return "BIGINT", nil
+	case nullFloat64Type:
+		return "DOUBLE", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Uint8: // TINYINT is unsigned
+		return "TINYINT", nil
+	case reflect.Int8, reflect.Int16:
+		return "SMALLINT", nil
+	case reflect.Uint16, reflect.Int32:
+		return "INTEGER", nil
+	case reflect.Uint32, reflect.Int, reflect.Int64:
+		return "BIGINT", nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return "DECIMAL(20,0)", nil
+	case reflect.Float32:
+		return "REAL", nil
+	case reflect.Float64:
+		return "DOUBLE", nil
+	case reflect.String:
+		return varDataType("NVARCHAR", "NCLOB", c.Size), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return varDataType("VARBINARY", "BLOB", c.Size), nil
+		}
+	}
+	return "", fmt.Errorf("column %s: unsupported type %s", c.Name, c.Type)
+}
+
+func varDataType(varType, lobType string, size int) string {
+	switch {
+	case size <= 0:
+		size = DefaultVarLength
+	case size > MaxVarLength:
+		return lobType
+	}
+	return fmt.Sprintf("%s(%d)", varType, size)
+}
+
+func decimalDataType(c Column) (string, error) {
+	switch {
+	case c.Precision == 0:
+		return "DECIMAL", nil // floating decimal
+	case c.Precision < 0 || c.Precision > MaxDecimalPrecision:
+		return "", fmt.Errorf("column %s: invalid decimal precision %d", c.Name, c.Precision)
+	case c.Scale < 0 || c.Scale > c.Precision:
+		return "", fmt.Errorf("column %s: invalid decimal scale %d", c.Name, c.Scale)
+	}
+	return fmt.Sprintf("DECIMAL(%d,%d)", c.Precision, c.Scale), nil
+}
+
+// columnDefinition returns the column definition used in create table and alter table statements.
+// If inlinePK is set, the primary key constraint is part of the column definition.
+func columnDefinition(c Column, inlinePK bool) (string, error) {
+	typ, err := DataType(c)
+	if err != nil {
+		return "", err
+	}
+	b := new(strings.Builder)
+	b.WriteString(Quote(c.Name))
+	b.WriteByte(' ')
+	b.WriteString(typ)
+	if c.Default != "" {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(c.Default)
+	}
+	if c.AutoIncrement {
+		b.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+	}
+	if c.NotNull || c.PrimaryKey || c.AutoIncrement {
+		b.WriteString(" NOT NULL")
+	}
+	if c.PrimaryKey && inlinePK {
+		b.WriteString(" PRIMARY KEY")
+	}
+	if c.Unique && !c.PrimaryKey {
+		b.WriteString(" UNIQUE")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dialect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Quote returns the quoted identifier name if it is not a simple (upper case) identifier.
+func Quote(name string) string { return driver.Identifier(name).String() }
+
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// CreateTable returns the statement creating a column table with the columns cols.
+func CreateTable(table string, cols []Column) (string, error) {
+	if len(cols) == 0 {
+		return "", fmt.Errorf("table %s: no columns", table)
+	}
+
+	var pks []string
+	for _, c := range cols {
+		if c.PrimaryKey {
+			pks = append(pks, c.Name)
+		}
+	}
+
+	defs := make([]string, 0, len(cols)+1)
+	for _, c := range cols {
+		def, err := columnDefinition(c, len(pks) == 1)
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+	if len(pks) > 1 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", quoteList(pks)))
+	}
+	return fmt.Sprintf("CREATE COLUMN TABLE %s (%s)", Quote(table), strings.Join(defs, ", ")), nil
+}
+
+// DropTable returns the statement dropping table.
+func DropTable(table string) string { return fmt.Sprintf("DROP TABLE %s", Quote(table)) }
+
+// RenameTable returns the statement renaming table from to table to.
+func RenameTable(from, to string) string {
+	return fmt.Sprintf("RENAME TABLE %s TO %s", Quote(from), Quote(to))
+}
+
+// AddColumn returns the statement adding column c to table.
+func AddColumn(table string, c Column) (string, error) {
+	def, err := columnDefinition(c, true)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD (%s)", Quote(table), def), nil
+}
+
+// AlterColumn returns the statement changing the definition of column c of table.
+func AlterColumn(table string, c Column) (string, error) {
+	def, err := columnDefinition(c, false)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER (%s)", Quote(table), def), nil
+}
+
+// DropColumn returns the statement dropping column of table.
+func DropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP (%s)", Quote(table), Quote(column))
+}
+
+// RenameColumn returns the statement renaming column from of table to column to.
+func RenameColumn(table, from, to string) string {
+	return fmt.Sprintf("RENAME COLUMN %s.%s TO %s", Quote(table), Quote(from), Quote(to))
+}
+
+// CreateIndex returns the statement creating the index name on the columns cols of table.
+func CreateIndex(name, table string, cols []string, unique bool) string {
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, Quote(name), Quote(table), quoteList(cols))
+}
+
+// DropIndex returns the statement dropping the index name.
+func DropIndex(name string) string { return fmt.Sprintf("DROP INDEX %s", Quote(name)) }
+
+// RenameIndex returns the statement renaming the index from to index to.
+func RenameIndex(from, to string) string {
+	return fmt.Sprintf("RENAME INDEX %s TO %s", Quote(from), Quote(to))
+}
+
+// Catalog queries returning the number of matching objects in the current schema.
+// As Quote preserves the case of identifiers, the object names are passed as query parameters as is.
+const (
+	// HasTableQuery has the table name as parameter.
+	HasTableQuery = "SELECT COUNT(*) FROM SYS.TABLES WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ?"
+	// HasColumnQuery has the table and the column name as parameters.
+	HasColumnQuery = "SELECT COUNT(*) FROM SYS.TABLE_COLUMNS WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+	// HasIndexQuery has the table and the index name as parameters.
+	HasIndexQuery = "SELECT COUNT(*) FROM SYS.INDEXES WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ? AND INDEX_NAME = ?"
+	// HasConstraintQuery has the table and the constraint name (incl. foreign keys) as parameters.
+	HasConstraintQuery = "SELECT COUNT(*) FROM (" +
+		"SELECT TABLE_NAME, CONSTRAINT_NAME FROM SYS.CONSTRAINTS WHERE SCHEMA_NAME = CURRENT_SCHEMA UNION ALL " +
+		"SELECT TABLE_NAME, CONSTRAINT_NAME FROM SYS.REFERENTIAL_CONSTRAINTS WHERE SCHEMA_NAME = CURRENT_SCHEMA" +
+		") WHERE TABLE_NAME = ? AND CONSTRAINT_NAME = ?"
+)
+
+// Catalog queries returning names.
+const (
+	// CurrentSchemaQuery returns the name of the current schema.
+	CurrentSchemaQuery = "SELECT CURRENT_SCHEMA FROM DUMMY"
+	// TablesQuery returns the names of the tables of the current schema.
+	TablesQuery = "SELECT TABLE_NAME FROM SYS.TABLES WHERE SCHEMA_NAME = CURRENT_SCHEMA"
+)
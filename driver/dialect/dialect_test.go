@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dialect_test
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/dialect"
+	"github.com/SAP/go-hdb/driver/hdbtest"
+)
+
+func testDataType(t *testing.T) {
+	tests := []struct {
+		c   dialect.Column
+		typ string
+	}{
+		{dialect.Column{Type: reflect.TypeOf(true)}, "BOOLEAN"},
+		{dialect.Column{Type: reflect.TypeOf(uint8(0))}, "TINYINT"},
+		{dialect.Column{Type: reflect.TypeOf(int8(0))}, "SMALLINT"},
+		{dialect.Column{Type: reflect.TypeOf(int32(0))}, "INTEGER"},
+		{dialect.Column{Type: reflect.TypeOf(0)}, "BIGINT"},
+		{dialect.Column{Type: reflect.TypeOf(uint64(0))}, "DECIMAL(20,0)"},
+		{dialect.Column{Type: reflect.TypeOf(float32(0))}, "REAL"},
+		{dialect.Column{Type: reflect.TypeOf(new(float64))}, "DOUBLE"},
+		{dialect.Column{Type: reflect.TypeOf("")}, "NVARCHAR(256)"},
+		{dialect.Column{Type: reflect.TypeOf(""), Size: 20}, "NVARCHAR(20)"},
+		{dialect.Column{Type: reflect.TypeOf(""), Size: 10000}, "NCLOB"},
+		{dialect.Column{Type: reflect.TypeOf([]byte(nil)), Size: 16}, "VARBINARY(16)"},
+		{dialect.Column{Type: reflect.TypeOf(time.Time{})}, "TIMESTAMP"},
+		{dialect.Column{Type: reflect.TypeOf(sql.NullString{})}, "NVARCHAR(256)"},
+		{dialect.Column{Type: reflect.TypeOf(sql.NullInt64{})}, "BIGINT"},
+		{dialect.Column{Type: reflect.TypeOf(driver.NullTime{})}, "TIMESTAMP"},
+		{dialect.Column{Type: reflect.TypeOf(big.Rat{})}, "DECIMAL"},
+		{dialect.Column{Type: reflect.TypeOf(new(driver.Decimal)), Precision: 10, Scale: 2}, "DECIMAL(10,2)"},
+		{dialect.Column{Type: reflect.TypeOf(driver.NullLob{})}, "BLOB"},
+		{dialect.Column{Type: reflect.TypeOf(driver.Geometry(nil))}, "ST_GEOMETRY"},
+		{dialect.Column{Type: reflect.TypeOf(""), DBType: "VARCHAR(10)"}, "VARCHAR(10)"},
+	}
+	for _, test := range tests {
+		typ, err := dialect.DataType(test.c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != test.typ {
+			t.Fatalf("type %s: %s - expected %s", test.c.Type, typ, test.typ)
+		}
+	}
+
+	for _, c := range []dialect.Column{
+		{Name: "a"},
+		{Name: "b", Type: reflect.TypeOf([]int{})},
+		{Name: "c", Type: reflect.TypeOf(driver.Decimal{}), Precision: 39},
+		{Name: "d", Type: reflect.TypeOf(driver.Decimal{}), Precision: 10, Scale: 11},
+	} {
+		if _, err := dialect.DataType(c); err == nil {
+			t.Fatalf("column %s: error expected", c.Name)
+		}
+	}
+}
+
+func testDDL(t *testing.T) {
+	cols := []dialect.Column{
+		{Name: "id", Type: reflect.TypeOf(int64(0)), PrimaryKey: true, AutoIncrement: true},
+		{Name: "NAME", Type: reflect.TypeOf(""), Size: 50, Unique: true},
+		{Name: "active", Type: reflect.TypeOf(true), Default: "TRUE", NotNull: true},
+	}
+	stmt, err := dialect.CreateTable("users", cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		stmt, expected string
+	}{
+		{stmt, `CREATE COLUMN TABLE "users" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL PRIMARY KEY, NAME NVARCHAR(50) UNIQUE, "active" BOOLEAN DEFAULT TRUE NOT NULL)`},
+		{dialect.DropTable("users"), `DROP TABLE "users"`},
+		{dialect.RenameTable("users", "USERS"), `RENAME TABLE "users" TO USERS`},
+		{dialect.DropColumn("users", "age"), `ALTER TABLE "users" DROP ("age")`},
+		{dialect.RenameColumn("users", "age", "AGE"), `RENAME COLUMN "users"."age" TO AGE`},
+		{dialect.CreateIndex("idx_name", "users", []string{"NAME", "active"}, true), `CREATE UNIQUE INDEX "idx_name" ON "users" (NAME, "active")`},
+		{dialect.DropIndex("idx_name"), `DROP INDEX "idx_name"`},
+	}
+	if stmt, err = dialect.AddColumn("users", dialect.Column{Name: "AGE", Type: reflect.TypeOf(int16(0))}); err != nil {
+		t.Fatal(err)
+	}
+	tests = append(tests, struct{ stmt, expected string }{stmt, `ALTER TABLE "users" ADD (AGE SMALLINT)`})
+	if stmt, err = dialect.AlterColumn("users", dialect.Column{Name: "AGE", Type: reflect.TypeOf(int32(0)), NotNull: true}); err != nil {
+		t.Fatal(err)
+	}
+	tests = append(tests, struct{ stmt, expected string }{stmt, `ALTER TABLE "users" ALTER (AGE INTEGER NOT NULL)`})
+
+	for _, test := range tests {
+		if test.stmt != test.expected {
+			t.Fatalf("statement %s - expected %s", test.stmt, test.expected)
+		}
+	}
+
+	stmt, err = dialect.CreateTable("T", []dialect.Column{
+		{Name: "A", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "B", Type: reflect.TypeOf(0), PrimaryKey: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "CREATE COLUMN TABLE T (A BIGINT NOT NULL, B BIGINT NOT NULL, PRIMARY KEY (A, B))"; stmt != expected {
+		t.Fatalf("statement %s - expected %s", stmt, expected)
+	}
+	if _, err := dialect.CreateTable("T", nil); err == nil {
+		t.Fatal("error expected for table without columns")
+	}
+}
+
+func testTranslateError(t *testing.T) {
+	db, err := sql.Open(hdbtest.DriverName, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stmt, err := dialect.CreateTable("PERSONS", []dialect.Column{
+		{Name: "ID", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "NAME", Type: reflect.TypeOf(""), Size: 5, NotNull: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(stmt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("insert into persons values (1, 'A')"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		kind  error
+	}{
+		{"insert into persons values (1, 'B')", dialect.ErrDuplicatedKey},
+		{"insert into persons values (2, NULL)", dialect.ErrNotNullViolated},
+		{"insert into persons values (3, 'ABCDEFG')", dialect.ErrValueTooLarge},
+		{"insert into unknown values (1)", dialect.ErrTableNotFound},
+		{stmt, dialect.ErrDuplicatedTable},
+	}
+	for _, test := range tests {
+		_, err := db.Exec(test.query)
+		if err == nil {
+			t.Fatalf("query %s: error expected", test.query)
+		}
+		err = dialect.TranslateError(err)
+		if !errors.Is(err, test.kind) {
+			t.Fatalf("query %s: error %v - expected %v", test.query, err, test.kind)
+		}
+		var dbErr driver.Error
+		if !errors.As(err, &dbErr) {
+			t.Fatalf("query %s: error %v - expected driver.Error", test.query, err)
+		}
+	}
+
+	other := errors.New("other")
+	if err := dialect.TranslateError(other); err != other {
+		t.Fatalf("error %v - expected %v", err, other)
+	}
+	if err := dialect.TranslateError(nil); err != nil {
+		t.Fatalf("error %v - expected nil", err)
+	}
+}
+
+func TestDialect(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"dataType", testDataType},
+		{"ddl", testDDL},
+		{"translateError", testTranslateError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
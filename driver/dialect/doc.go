@@ -0,0 +1,20 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package dialect provides helpers building HANA specific sql statements and values.
+
+The package contains:
+
+  - the mapping of go types to HANA column types (DataType),
+  - the ddl statements used to create or extend a schema (CreateTable, AddColumn, CreateIndex, ...) and the
+    catalog queries to inspect the existing schema (HasTableQuery, HasColumnQuery, HasIndexQuery),
+  - identity column handling: as HANA does not support a RETURNING clause, the value of an identity
+    column generated by an insert statement is queried afterwards in the same session (LastInsertID),
+  - the translation of database errors to error kinds like duplicated keys (TranslateError).
+
+The package does not depend on any object relational mapper. The GORM dialector built on this package is
+provided by the separate module github.com/SAP/go-hdb/gormhdb, so that the driver module does not depend on GORM.
+*/
+package dialect
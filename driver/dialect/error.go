@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dialect
+
+import (
+	"errors"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Error kinds returned by TranslateError.
+var (
+	ErrDuplicatedKey         = errors.New("duplicated key")
+	ErrForeignKeyViolated    = errors.New("foreign key violated")
+	ErrNotNullViolated       = errors.New("not null constraint violated")
+	ErrCheckViolated         = errors.New("check constraint violated")
+	ErrValueTooLarge         = errors.New("value too large")
+	ErrTableNotFound         = errors.New("table not found")
+	ErrColumnNotFound        = errors.New("column not found")
+	ErrDuplicatedTable       = errors.New("duplicated table")
+	ErrDuplicatedColumn      = errors.New("duplicated column")
+	ErrDuplicatedIndex       = errors.New("duplicated index")
+	ErrIndexNotFound         = errors.New("index not found")
+	ErrLockTimeout           = errors.New("lock wait timeout")
+	ErrDeadlock              = errors.New("deadlock detected")
+	ErrInsufficientPrivilege = errors.New("insufficient privilege")
+)
+
+// HANA error codes translated by TranslateError.
+var errorKinds = map[int]error{
+	131: ErrLockTimeout,           // transaction rolled back by lock wait timeout
+	133: ErrDeadlock,              // transaction rolled back by detected deadlock
+	258: ErrInsufficientPrivilege, // insufficient privilege
+	259: ErrTableNotFound,         // invalid table name
+	260: ErrColumnNotFound,        // invalid column name
+	261: ErrIndexNotFound,         // invalid index name
+	274: ErrValueTooLarge,         // inserted value too large for column
+	287: ErrNotNullViolated,       // cannot insert NULL or update to NULL
+	288: ErrDuplicatedTable,       // cannot use duplicate table name
+	289: ErrDuplicatedIndex,       // cannot use duplicate index name
+	301: ErrDuplicatedKey,         // unique constraint violated
+	308: ErrDuplicatedColumn,      // column name already exists
+	461: ErrForeignKeyViolated,    // foreign key constraint violation
+	462: ErrForeignKeyViolated,    // failed on update or delete by foreign key constraint violation
+	677: ErrCheckViolated,         // check constraint violation
+}
+
+// translatedError is a database error including its error kind.
+type translatedError struct {
+	kind error
+	err  error
+}
+
+func (e *translatedError) Error() string { return e.err.Error() }
+
+// Unwrap returns the database error.
+func (e *translatedError) Unwrap() error { return e.err }
+
+// Is returns true if target is the error kind.
+func (e *translatedError) Is(target error) bool { return target == e.kind }
+
+// TranslateError returns an error matching the error kind of database error err via errors.Is.
+// The returned error still wraps err, so that the database error can be retrieved by errors.As.
+// Errors which are not database errors or errors without corresponding kind are returned unchanged.
+func TranslateError(err error) error {
+	var dbErr driver.Error
+	if !errors.As(err, &dbErr) {
+		return err
+	}
+	kind, ok := errorKinds[dbErr.Code()]
+	if !ok {
+		return err
+	}
+	return &translatedError{kind: kind, err: err}
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNoIdentity is returned by LastInsertID if no identity value was generated in the session.
+var ErrNoIdentity = errors.New("no identity value generated")
+
+// LastInsertIDQuery is the query returning the last identity value generated in the session.
+const LastInsertIDQuery = "SELECT CURRENT_IDENTITY_VALUE() FROM DUMMY"
+
+// Queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// LastInsertID returns the identity value generated by the last insert statement.
+// As the identity value is session specific, q needs to be the connection or transaction
+// the insert statement was executed on (not a *sql.DB connection pool).
+func LastInsertID(ctx context.Context, q Queryer) (int64, error) {
+	var id sql.NullInt64
+	if err := q.QueryRowContext(ctx, LastInsertIDQuery).Scan(&id); err != nil {
+		return 0, err
+	}
+	if !id.Valid {
+		return 0, ErrNoIdentity
+	}
+	return id.Int64, nil
+}
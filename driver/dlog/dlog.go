@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package dlog defines the driver logger interface.
+
+Driver log and trace output is written to a Logger. The package provides loggers writing to the standard
+library logger (NewStdLogger), as JSON lines (NewJSONLogger) and, for go1.21 and later, to a log/slog logger
+(NewSlogLogger).
+
+To keep the driver free of third party dependencies, there are no adapters for logging libraries like logr or zap.
+Instead, driver output can be forwarded to any structured logging library by implementing the Logger interface,
+e.g. via the Func adapter:
+
+	logger := dlog.Func(func(level dlog.Level, msg string, keyvals ...interface{}) {
+		zapLogger.Sugar().Infow(msg, keyvals...) // map level accordingly
+	})
+*/
+package dlog
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Level is the log level of a log entry.
+type Level int
+
+// Log level constants.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelString = map[Level]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+func (l Level) String() string {
+	if s, ok := levelString[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("Level(%d)", l)
+}
+
+/*
+Logger is the interface used by the driver to write log entries.
+
+The keyvals parameter contains alternating keys (strings) and values
+adding structured context information to the log entry.
+*/
+type Logger interface {
+	Log(level Level, msg string, keyvals ...interface{})
+}
+
+// Func is an adapter to allow the use of ordinary functions as Logger.
+type Func func(level Level, msg string, keyvals ...interface{})
+
+// Log implements the Logger interface.
+func (f Func) Log(level Level, msg string, keyvals ...interface{}) { f(level, msg, keyvals...) }
+
+type stdLogger struct {
+	log *log.Logger
+}
+
+// NewStdLogger returns a Logger writing to the standard library logger l.
+// Log entries are formatted as message followed by key=value pairs,
+// prefixed with the log level for warnings and errors.
+func NewStdLogger(l *log.Logger) Logger { return &stdLogger{log: l} }
+
+func (l *stdLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l.log.Print(Format(level, msg, keyvals...))
+}
+
+// Format formats a log entry as text.
+func Format(level Level, msg string, keyvals ...interface{}) string {
+	b := new(strings.Builder)
+	if level >= LevelWarn {
+		b.WriteString(level.String())
+		b.WriteByte(' ')
+	}
+	b.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		b.WriteByte(' ')
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(b, "%v=%v", keyvals[i], keyvals[i+1])
+		} else {
+			fmt.Fprintf(b, "%v", keyvals[i])
+		}
+	}
+	return b.String()
+}
+
+var std = struct {
+	mu     sync.RWMutex
+	logger Logger
+}{logger: NewStdLogger(log.New(os.Stderr, "hdb ", log.Ldate|log.Ltime))}
+
+// Default returns the default driver logger.
+func Default() Logger {
+	std.mu.RLock()
+	defer std.mu.RUnlock()
+	return std.logger
+}
+
+// SetDefault sets the default driver logger used if no logger is set in the connector.
+// Setting nil restores the standard logger.
+func SetDefault(logger Logger) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	if logger == nil {
+		logger = NewStdLogger(log.New(os.Stderr, "hdb ", log.Ldate|log.Ltime))
+	}
+	std.logger = logger
+}
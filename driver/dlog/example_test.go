@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dlog_test
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver/dlog"
+)
+
+// Example demonstrates how to forward driver log entries to a custom logging function.
+func Example() {
+	logger := dlog.Func(func(level dlog.Level, msg string, keyvals ...interface{}) {
+		fmt.Println(dlog.Format(level, msg, keyvals...))
+	})
+
+	logger.Log(dlog.LevelInfo, "select * from dummy", "args", []int{1, 2})
+	logger.Log(dlog.LevelWarn, "slow query", "stmt", "select * from dummy", "rows", 1)
+	// Output:
+	// select * from dummy args=[1 2]
+	// WARN slow query stmt=select * from dummy rows=1
+}
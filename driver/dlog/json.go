@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type jsonLogger struct {
+	mu  sync.Mutex // serialize writes
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+/*
+NewJSONLogger returns a Logger writing one JSON object per log entry (JSON lines) to w.
+
+Each object contains the timestamp (time), the log level (level), the message (msg)
+and the key value pairs of the log entry. Durations are written as nanoseconds
+and errors as error text.
+*/
+func NewJSONLogger(w io.Writer) Logger { return &jsonLogger{w: w} }
+
+func (l *jsonLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buf.Reset()
+	l.buf.WriteString(`{"time":`)
+	writeJSONValue(&l.buf, time.Now().Format(time.RFC3339Nano))
+	l.buf.WriteString(`,"level":`)
+	writeJSONValue(&l.buf, level.String())
+	l.buf.WriteString(`,"msg":`)
+	writeJSONValue(&l.buf, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		l.buf.WriteByte(',')
+		writeJSONValue(&l.buf, fmt.Sprint(keyvals[i]))
+		l.buf.WriteByte(':')
+		if i+1 < len(keyvals) {
+			writeJSONValue(&l.buf, keyvals[i+1])
+		} else {
+			l.buf.WriteString("null")
+		}
+	}
+	l.buf.WriteString("}\n")
+	l.w.Write(l.buf.Bytes())
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case time.Duration:
+		fmt.Fprintf(buf, "%d", int64(v))
+		return
+	case error:
+		writeJSONValue(buf, v.Error())
+		return
+	case fmt.Stringer:
+		if _, ok := v.(json.Marshaler); !ok {
+			writeJSONValue(buf, v.String())
+			return
+		}
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false) // do not escape sql operators like < and >
+	if err := enc.Encode(v); err != nil {
+		b.Reset()
+		enc.Encode(fmt.Sprint(v))
+	}
+	buf.Write(bytes.TrimRight(b.Bytes(), "\n"))
+}
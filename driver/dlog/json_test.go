@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJSONLogger(t *testing.T) {
+	b := new(bytes.Buffer)
+	logger := NewJSONLogger(b)
+
+	logger.Log(LevelError, "query", "connID", 42, "stmt", "select * from t where a < 1", "duration", time.Millisecond, "error", errors.New("test error"))
+	logger.Log(LevelInfo, "exec", "rows", 2)
+
+	lines := bytes.Split(bytes.TrimSpace(b.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("number of lines %d - expected %d", len(lines), 2)
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(lines[0], &m); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"level":    "ERROR",
+		"msg":      "query",
+		"connID":   float64(42),
+		"stmt":     "select * from t where a < 1",
+		"duration": float64(time.Millisecond),
+		"error":    "test error",
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Fatalf("key %s value %v - expected %v", k, m[k], v)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, m["time"].(string)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(lines[0], []byte("a < 1")) {
+		t.Fatalf("unexpected escaping in %s", lines[0])
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotateOptions contains the rotation and retention parameters of a RotatingFile.
+type RotateOptions struct {
+	// MaxSize is the maximum size in bytes of the current file before it gets rotated (0: no size limit).
+	MaxSize int64
+	// MaxAge is the maximum age of the current file before it gets rotated (0: no age limit).
+	MaxAge time.Duration
+	// MaxBackups is the maximum number of rotated files to retain (0: retain all).
+	MaxBackups int
+	// MaxBackupAge is the maximum age of rotated files to retain (0: retain all).
+	MaxBackupAge time.Duration
+}
+
+// backupTimeFormat is the timestamp format of rotated file names.
+const backupTimeFormat = "20060102T150405.000000000"
+
+/*
+RotatingFile is an io.WriteCloser writing to a file, which is rotated by size and age.
+
+A rotated file is renamed to <filename>.<timestamp> and a new file is opened.
+Rotated files exceeding the retention limits are removed.
+RotatingFile can be used as output of trace loggers, e.g.
+
+	f, err := dlog.OpenRotatingFile("/var/log/hdbtrace.log", dlog.RotateOptions{MaxSize: 10 << 20, MaxBackups: 5})
+	...
+	sqltrace.SetLogger(dlog.NewJSONLogger(f))
+*/
+type RotatingFile struct {
+	mu       sync.Mutex
+	filename string
+	opts     RotateOptions
+	file     *os.File
+	size     int64
+	opened   time.Time
+	now      func() time.Time
+}
+
+// OpenRotatingFile opens the file filename for appending (creating it if not existent) with rotation options opts.
+func OpenRotatingFile(filename string, opts RotateOptions) (*RotatingFile, error) {
+	f := &RotatingFile{filename: filename, opts: opts, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = fi.Size()
+	f.opened = f.now()
+	return nil
+}
+
+// Write implements the io.Writer interface.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) needsRotation(n int64) bool {
+	if f.size == 0 { // do not rotate empty files
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	if f.opts.MaxAge > 0 && f.now().Sub(f.opened) >= f.opts.MaxAge {
+		return true
+	}
+	return false
+}
+
+// Rotate rotates the current file.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	backup := fmt.Sprintf("%s.%s", f.filename, f.now().Format(backupTimeFormat))
+	if err := os.Rename(f.filename, backup); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.cleanup()
+}
+
+// cleanup removes the rotated files exceeding the retention limits.
+func (f *RotatingFile) cleanup() error {
+	if f.opts.MaxBackups <= 0 && f.opts.MaxBackupAge <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(f.filename + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups))) // newest first (timestamp format sorts lexically)
+
+	cutoff := f.now().Add(-f.opts.MaxBackupAge)
+	for i, backup := range backups {
+		remove := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if !remove && f.opts.MaxBackupAge > 0 {
+			if fi, err := os.Stat(backup); err == nil && fi.ModTime().Before(cutoff) {
+				remove = true
+			}
+		}
+		if remove {
+			if err := os.Remove(backup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close implements the io.Closer interface.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
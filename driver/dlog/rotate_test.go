@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package dlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testRotatingFile(t *testing.T, opts RotateOptions, fct func(f *RotatingFile, clock *time.Time, filename string)) {
+	dir, err := ioutil.TempDir("", "dlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clock := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	filename := filepath.Join(dir, "trace.log")
+
+	f, err := OpenRotatingFile(filename, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.now = func() time.Time { return clock }
+	f.opened = clock
+
+	fct(f, &clock, filename)
+}
+
+func numBackup(t *testing.T, filename string) int {
+	backups, err := filepath.Glob(filename + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(backups)
+}
+
+func testRotateSize(t *testing.T) {
+	testRotatingFile(t, RotateOptions{MaxSize: 10, MaxBackups: 2}, func(f *RotatingFile, clock *time.Time, filename string) {
+		for i := 0; i < 5; i++ {
+			*clock = clock.Add(time.Second)
+			if _, err := f.Write([]byte("12345678\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if n := numBackup(t, filename); n != 2 {
+			t.Fatalf("number of backups %d - expected %d", n, 2)
+		}
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "12345678\n" {
+			t.Fatalf("file content %q - expected %q", b, "12345678\n")
+		}
+	})
+}
+
+func testRotateAge(t *testing.T) {
+	testRotatingFile(t, RotateOptions{MaxAge: time.Hour}, func(f *RotatingFile, clock *time.Time, filename string) {
+		f.Write([]byte("a\n"))
+		*clock = clock.Add(30 * time.Minute)
+		f.Write([]byte("b\n"))
+		if n := numBackup(t, filename); n != 0 {
+			t.Fatalf("number of backups %d - expected %d", n, 0)
+		}
+		*clock = clock.Add(30 * time.Minute)
+		f.Write([]byte("c\n"))
+		if n := numBackup(t, filename); n != 1 {
+			t.Fatalf("number of backups %d - expected %d", n, 1)
+		}
+	})
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"rotateSize", testRotateSize},
+		{"rotateAge", testRotateAge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build go1.21
+
+package dlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+var slogLevel = map[Level]slog.Level{
+	LevelDebug: slog.LevelDebug,
+	LevelInfo:  slog.LevelInfo,
+	LevelWarn:  slog.LevelWarn,
+	LevelError: slog.LevelError,
+}
+
+type slogLogger struct {
+	log *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to the structured logger l of the standard library package log/slog
+// (go1.21 and later). The driver log levels are mapped to the corresponding slog levels.
+func NewSlogLogger(l *slog.Logger) Logger { return &slogLogger{log: l} }
+
+func (l *slogLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l.log.Log(context.Background(), slogLevel[level], msg, keyvals...)
+}
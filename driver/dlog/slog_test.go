@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build go1.21
+
+package dlog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	b := new(bytes.Buffer)
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	logger.Log(LevelDebug, "exec", "rows", 2)
+	logger.Log(LevelWarn, "slow query", "stmt", "select * from dummy")
+	logger.Log(LevelError, "query", "connID", 42)
+
+	const exp = "level=DEBUG msg=exec rows=2\n" +
+		"level=WARN msg=\"slow query\" stmt=\"select * from dummy\"\n" +
+		"level=ERROR msg=query connID=42\n"
+	if b.String() != exp {
+		t.Fatalf("log output\n%s- expected\n%s", b.String(), exp)
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Environment variables used to describe the test database.
+const (
+	// EnvDSN is the environment variable containing the data source name of the test database.
+	EnvDSN = "GOHDBDSN"
+	// EnvContainer is the environment variable enabling the start of a HANA express container
+	// if no data source name is provided. The value is either the container image or "true" for the default image.
+	EnvContainer = "GOHDBCONTAINER"
+)
+
+// Container defaults.
+const (
+	DefaultContainerImage    = "store/saplabs/hanaexpress:2.00.045.00.20200121.1"
+	DefaultContainerPassword = "HXEHana1"
+	DefaultContainerTimeout  = 15 * time.Minute
+)
+
+const (
+	containerSQLPort     = "39041/tcp" // sql port of the tenant database HXE (39017: system database)
+	containerUser        = "SYSTEM"
+	containerStartupDone = "Startup finished!"
+	containerPollTime    = 5 * time.Second
+)
+
+// ContainerOptions are the options to start a HANA express container.
+type ContainerOptions struct {
+	Image    string        // container image (default: DefaultContainerImage)
+	Password string        // master password (default: DefaultContainerPassword)
+	Timeout  time.Duration // startup timeout (default: DefaultContainerTimeout)
+}
+
+// Container represents a HANA express docker container started for testing.
+type Container struct {
+	id  string
+	dsn string
+}
+
+// ID returns the docker container id.
+func (c *Container) ID() string { return c.id }
+
+// DSN returns the data source name of the container database.
+func (c *Container) DSN() string { return c.dsn }
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+/*
+StartContainer starts a HANA express docker container and waits until the database is available.
+The container sql port is mapped to a random local port (see Container.DSN).
+Running the container requires a docker installation and accepting the SAP HANA express license.
+*/
+func StartContainer(ctx context.Context, opts *ContainerOptions) (*Container, error) {
+	image, password, timeout := DefaultContainerImage, DefaultContainerPassword, DefaultContainerTimeout
+	if opts != nil {
+		if opts.Image != "" {
+			image = opts.Image
+		}
+		if opts.Password != "" {
+			password = opts.Password
+		}
+		if opts.Timeout != 0 {
+			timeout = opts.Timeout
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id, err := docker(ctx, "run", "-d", "--rm", "-p", "127.0.0.1::"+containerSQLPort, "--ulimit", "nofile=1048576:1048576",
+		image, "--agree-to-sap-license", "--master-password", password)
+	if err != nil {
+		return nil, err
+	}
+	c := &Container{id: id}
+
+	if err := c.waitStartup(ctx); err != nil {
+		c.Stop(context.Background())
+		return nil, err
+	}
+
+	out, err := docker(ctx, "port", id, containerSQLPort)
+	if err != nil {
+		c.Stop(context.Background())
+		return nil, err
+	}
+	host, err := parseDockerPort(out)
+	if err != nil {
+		c.Stop(context.Background())
+		return nil, err
+	}
+	c.dsn = (&url.URL{Scheme: "hdb", User: url.UserPassword(containerUser, password), Host: host}).String()
+	return c, nil
+}
+
+func (c *Container) waitStartup(ctx context.Context) error {
+	ticker := time.NewTicker(containerPollTime)
+	defer ticker.Stop()
+	for {
+		logs, err := docker(ctx, "logs", c.id)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(logs, containerStartupDone) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("container %s startup: %w", c.id, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseDockerPort parses the output of the docker port command (e.g. 127.0.0.1:32768).
+func parseDockerPort(s string) (string, error) {
+	for _, line := range strings.Split(s, "\n") {
+		host, port, err := net.SplitHostPort(strings.TrimSpace(line))
+		if err != nil {
+			continue
+		}
+		if host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		return net.JoinHostPort(host, port), nil
+	}
+	return "", fmt.Errorf("invalid docker port output: %s", s)
+}
+
+// Stop stops and removes the container.
+func (c *Container) Stop(ctx context.Context) error {
+	_, err := docker(ctx, "stop", c.id)
+	return err
+}
+
+/*
+Setup returns the data source name of the test database.
+
+If environment variable EnvDSN is set, its value is returned. Otherwise, if environment variable EnvContainer is set,
+a HANA express container is started. The returned teardown function stops the container and is to be called
+after test execution.
+*/
+func Setup(ctx context.Context) (dsn string, teardown func(), err error) {
+	if dsn := os.Getenv(EnvDSN); dsn != "" {
+		return dsn, func() {}, nil
+	}
+	image := os.Getenv(EnvContainer)
+	if image == "" {
+		return "", nil, fmt.Errorf("no test database: set environment variable %s or %s", EnvDSN, EnvContainer)
+	}
+	opts := &ContainerOptions{}
+	if image != "true" {
+		opts.Image = image
+	}
+	c, err := StartContainer(ctx, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.DSN(), func() { c.Stop(context.Background()) }, nil
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"testing"
+)
+
+func TestParseDockerPort(t *testing.T) {
+	tests := []struct {
+		out  string
+		host string
+	}{
+		{"127.0.0.1:32768", "127.0.0.1:32768"},
+		{"0.0.0.0:32768\n:::32768", "localhost:32768"},
+	}
+
+	for _, test := range tests {
+		host, err := parseDockerPort(test.out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if host != test.host {
+			t.Fatalf("host %s - expected %s", host, test.host)
+		}
+	}
+
+	if _, err := parseDockerPort("invalid"); err == nil {
+		t.Fatal("error expected for invalid port output")
+	}
+}
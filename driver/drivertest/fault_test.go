@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package drivertest_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/dial"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+const faultQuery = "select 1 from dummy"
+
+func testFaultDial(db *sql.DB, d *dial.FaultDialer, t *testing.T) {
+	d.Inject(dial.Fault{Op: dial.FaultDial, Kind: dial.FaultDisconnect})
+	if err := db.Ping(); err == nil {
+		t.Fatal("dial error expected")
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testFaultDelay(db *sql.DB, d *dial.FaultDialer, t *testing.T) {
+	const delay = 50 * time.Millisecond
+	d.Inject(dial.Fault{Op: dial.FaultRead, Kind: dial.FaultDelay, Delay: delay})
+	start := time.Now()
+	if _, err := db.Exec(faultQuery); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Fatalf("elapsed time %v - expected >= %v", elapsed, delay)
+	}
+}
+
+// testFaultRetry checks that the faulty connection is discarded (driver.ErrBadConn)
+// and the statement is retried by database/sql on a new connection.
+func testFaultRetry(fault dial.Fault) func(db *sql.DB, d *dial.FaultDialer, t *testing.T) {
+	return func(db *sql.DB, d *dial.FaultDialer, t *testing.T) {
+		d.Inject(fault)
+		if _, err := db.Exec(faultQuery); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFaultDialer(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		fct  func(db *sql.DB, d *dial.FaultDialer, t *testing.T)
+	}{
+		{"dial", testFaultDial},
+		{"delay", testFaultDelay},
+		{"disconnect", testFaultRetry(dial.Fault{Op: dial.FaultRead, Kind: dial.FaultDisconnect})},
+		{"truncate", testFaultRetry(dial.Fault{Op: dial.FaultRead, Kind: dial.FaultDisconnect, Offset: 40})},
+		{"writeDisconnect", testFaultRetry(dial.Fault{Op: dial.FaultWrite, Kind: dial.FaultDisconnect, Offset: 16})},
+		{"deadline", testFaultRetry(dial.Fault{Op: dial.FaultRead, Kind: dial.FaultDeadline})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := dial.NewFaultDialer(nil)
+			connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+			if err := connector.SetDialer(d); err != nil {
+				t.Fatal(err)
+			}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+			db.SetMaxOpenConns(1)
+
+			if test.name != "dial" {
+				// establish connection before injecting faults
+				if err := db.Ping(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			test.fct(db, d, t)
+			if n := d.Pending(); n != 0 {
+				t.Fatalf("pending faults %d - expected %d", n, 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Generator default lengths.
+const (
+	DefaultCharLength = 1    // length of character and binary columns without explicit length
+	DefaultLobLength  = 1024 // maximum length of generated lob values without explicit length
+)
+
+// ColumnSpec describes a table column values are generated for.
+type ColumnSpec struct {
+	Name      string
+	TypeName  string // database type name (e.g. NVARCHAR, DECIMAL)
+	Length    int    // character and binary types: column length, lob types: maximum length of generated values
+	Precision int    // DECIMAL only - 0: floating point decimal
+	Scale     int    // DECIMAL only
+	Nullable  bool
+}
+
+// SQLType returns the column type as used in a create table statement.
+func (s ColumnSpec) SQLType() string {
+	typeName := strings.ToUpper(s.TypeName)
+	switch {
+	case typeName == "DECIMAL" && s.Precision != 0:
+		return fmt.Sprintf("%s(%d,%d)", typeName, s.Precision, s.Scale)
+	case s.Length != 0 && s.isCharOrBinary():
+		return fmt.Sprintf("%s(%d)", typeName, s.Length)
+	}
+	return typeName
+}
+
+func (s ColumnSpec) isCharOrBinary() bool {
+	switch strings.ToUpper(s.TypeName) {
+	case "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "ALPHANUM", "SHORTTEXT", "BINARY", "VARBINARY":
+		return true
+	}
+	return false
+}
+
+func (s ColumnSpec) isLob() bool {
+	switch strings.ToUpper(s.TypeName) {
+	case "BLOB", "CLOB", "NCLOB", "TEXT":
+		return true
+	}
+	return false
+}
+
+func (s ColumnSpec) length(defaultLength int) int {
+	if s.Length == 0 {
+		return defaultLength
+	}
+	return s.Length
+}
+
+// ColumnSpecs returns column specifications covering all data types supported by the generator.
+func ColumnSpecs() []ColumnSpec {
+	return []ColumnSpec{
+		{Name: "C_BOOLEAN", TypeName: "BOOLEAN"},
+		{Name: "C_TINYINT", TypeName: "TINYINT"},
+		{Name: "C_SMALLINT", TypeName: "SMALLINT"},
+		{Name: "C_INTEGER", TypeName: "INTEGER"},
+		{Name: "C_BIGINT", TypeName: "BIGINT"},
+		{Name: "C_REAL", TypeName: "REAL"},
+		{Name: "C_DOUBLE", TypeName: "DOUBLE"},
+		{Name: "C_DECIMAL", TypeName: "DECIMAL"},
+		{Name: "C_DECIMAL_P_S", TypeName: "DECIMAL", Precision: 18, Scale: 4},
+		{Name: "C_SMALLDECIMAL", TypeName: "SMALLDECIMAL"},
+		{Name: "C_CHAR", TypeName: "CHAR", Length: 10},
+		{Name: "C_VARCHAR", TypeName: "VARCHAR", Length: 100},
+		{Name: "C_NCHAR", TypeName: "NCHAR", Length: 10},
+		{Name: "C_NVARCHAR", TypeName: "NVARCHAR", Length: 100},
+		{Name: "C_ALPHANUM", TypeName: "ALPHANUM", Length: 20},
+		{Name: "C_SHORTTEXT", TypeName: "SHORTTEXT", Length: 100},
+		{Name: "C_BINARY", TypeName: "BINARY", Length: 10},
+		{Name: "C_VARBINARY", TypeName: "VARBINARY", Length: 100},
+		{Name: "C_DATE", TypeName: "DATE"},
+		{Name: "C_TIME", TypeName: "TIME"},
+		{Name: "C_SECONDDATE", TypeName: "SECONDDATE"},
+		{Name: "C_TIMESTAMP", TypeName: "TIMESTAMP"},
+		{Name: "C_BLOB", TypeName: "BLOB"},
+		{Name: "C_CLOB", TypeName: "CLOB"},
+		{Name: "C_NCLOB", TypeName: "NCLOB"},
+		{Name: "C_TEXT", TypeName: "TEXT"},
+	}
+}
+
+// characters used for generated values
+const (
+	asciiChars    = " !#$%&()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[]^_abcdefghijklmnopqrstuvwxyz{|}~"
+	alphaChars    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	alphanumChars = alphaChars + "0123456789"
+)
+
+// unicodeChars are ascii and some non ascii characters of the basic multilingual plane
+// (one character in CESU-8 and UTF-8 encoding).
+var unicodeChars = []rune(asciiChars + "äöüÄÖÜßéèçñåøœ€£¥αβγδΩЖЯжя中文日本語한국어")
+
+// date range of generated date and time values
+var (
+	minGenDate = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxGenDate = time.Date(2100, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+/*
+A Generator produces random but valid values for the supported database types respecting precision, scale and length
+of the column specification. Generated values are the values expected when reading them back from the database:
+
+	BOOLEAN                                         bool
+	TINYINT, SMALLINT, INTEGER, BIGINT              uint8, int16, int32, int64
+	REAL, DOUBLE                                    float32, float64
+	DECIMAL, SMALLDECIMAL                           *big.Rat
+	CHAR, VARCHAR, NCHAR, NVARCHAR, ALPHANUM,
+	SHORTTEXT, CLOB, NCLOB, TEXT                    string
+	BINARY, VARBINARY, BLOB                         []byte
+	DATE, TIME, SECONDDATE, TIMESTAMP               time.Time (UTC)
+
+Use Args to convert generated values into statement arguments.
+A Generator is not safe for concurrent use.
+*/
+type Generator struct {
+	rnd *rand.Rand
+	// NullRatio is the probability (0 <= NullRatio <= 1) of generating nil values for nullable columns.
+	NullRatio float64
+	// NewDecimal converts decimal values into statement arguments (e.g. func(r *big.Rat) interface{} { return (*driver.Decimal)(r) }).
+	// If NewDecimal is nil, decimal arguments are passed as *big.Rat values.
+	NewDecimal func(r *big.Rat) interface{}
+}
+
+// NewGenerator returns a new Generator. Generators created with the same seed produce the same values.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (g *Generator) string(chars []rune, min, max int) string {
+	n := min
+	if max > min {
+		n += g.rnd.Intn(max - min + 1)
+	}
+	b := make([]rune, n)
+	for i := range b {
+		b[i] = chars[g.rnd.Intn(len(chars))]
+	}
+	return string(b)
+}
+
+func (g *Generator) bytes(min, max int) []byte {
+	n := min
+	if max > min {
+		n += g.rnd.Intn(max - min + 1)
+	}
+	b := make([]byte, n)
+	g.rnd.Read(b)
+	return b
+}
+
+// digits returns a random integer of maximal n digits.
+func (g *Generator) digits(n int) *big.Int {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	x := new(big.Int).Rand(g.rnd, max)
+	if g.rnd.Intn(2) == 0 {
+		x.Neg(x)
+	}
+	return x
+}
+
+func (g *Generator) decimal(precision, scale int) *big.Rat {
+	if precision == 0 { // floating point decimal
+		precision, scale = 16, g.rnd.Intn(21)-10
+	}
+	r := new(big.Rat).SetInt(g.digits(precision))
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(scale))), nil)
+	if scale >= 0 {
+		return r.Quo(r, new(big.Rat).SetInt(exp))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(exp))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func (g *Generator) time(precision time.Duration) time.Time {
+	d := maxGenDate.Sub(minGenDate) + 24*time.Hour
+	return minGenDate.Add(time.Duration(g.rnd.Int63n(int64(d)))).Truncate(precision)
+}
+
+// Value returns a random value for the column specification spec.
+func (g *Generator) Value(spec ColumnSpec) (interface{}, error) {
+	if spec.Nullable && g.rnd.Float64() < g.NullRatio {
+		return nil, nil
+	}
+
+	switch strings.ToUpper(spec.TypeName) {
+	case "BOOLEAN":
+		return g.rnd.Intn(2) == 1, nil
+	case "TINYINT":
+		return uint8(g.rnd.Intn(math.MaxUint8 + 1)), nil
+	case "SMALLINT":
+		return int16(g.rnd.Intn(math.MaxUint16+1) + math.MinInt16), nil
+	case "INTEGER":
+		return int32(g.rnd.Uint32()), nil
+	case "BIGINT":
+		return int64(g.rnd.Uint64()), nil
+	case "REAL":
+		return float32(g.rnd.NormFloat64() * 1e6), nil
+	case "DOUBLE":
+		return g.rnd.NormFloat64() * 1e12, nil
+	case "DECIMAL":
+		return g.decimal(spec.Precision, spec.Scale), nil
+	case "SMALLDECIMAL":
+		return g.decimal(0, 0), nil
+	case "CHAR":
+		n := spec.length(DefaultCharLength)
+		return g.string([]rune(asciiChars), n, n), nil
+	case "NCHAR":
+		n := spec.length(DefaultCharLength)
+		return g.string(unicodeChars, n, n), nil
+	case "VARCHAR":
+		return g.string([]rune(asciiChars), 0, spec.length(DefaultCharLength)), nil
+	case "NVARCHAR", "SHORTTEXT":
+		return g.string(unicodeChars, 0, spec.length(DefaultCharLength)), nil
+	case "ALPHANUM": // starts with letter (avoid normalization of numeric values)
+		n := spec.length(DefaultCharLength)
+		return g.string([]rune(alphaChars), 1, 1) + g.string([]rune(alphanumChars), 0, n-1), nil
+	case "BINARY":
+		n := spec.length(DefaultCharLength)
+		return g.bytes(n, n), nil
+	case "VARBINARY":
+		return g.bytes(0, spec.length(DefaultCharLength)), nil
+	case "DATE":
+		return g.time(24 * time.Hour), nil
+	case "TIME":
+		t := g.time(time.Second)
+		return time.Date(1, 1, 1, t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+	case "SECONDDATE":
+		return g.time(time.Second), nil
+	case "TIMESTAMP":
+		return g.time(100 * time.Nanosecond), nil
+	case "BLOB":
+		return g.bytes(0, spec.length(DefaultLobLength)), nil
+	case "CLOB":
+		return g.string([]rune(asciiChars), 0, spec.length(DefaultLobLength)), nil
+	case "NCLOB", "TEXT":
+		return g.string(unicodeChars, 0, spec.length(DefaultLobLength)), nil
+	default:
+		return nil, fmt.Errorf("generator: unsupported type %s of column %s", spec.TypeName, spec.Name)
+	}
+}
+
+// Row returns a random row for the column specifications specs.
+func (g *Generator) Row(specs []ColumnSpec) ([]interface{}, error) {
+	row := make([]interface{}, len(specs))
+	for i, spec := range specs {
+		v, err := g.Value(spec)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+// Args converts the generated row values into statement arguments: decimals are converted by NewDecimal,
+// lob values are passed as readers.
+func (g *Generator) Args(specs []ColumnSpec, row []interface{}) []interface{} {
+	args := make([]interface{}, len(row))
+	for i, v := range row {
+		args[i] = v
+		if v == nil {
+			continue
+		}
+		switch v := v.(type) {
+		case *big.Rat:
+			if g.NewDecimal != nil {
+				args[i] = g.NewDecimal(v)
+			}
+		case string:
+			if specs[i].isLob() {
+				args[i] = strings.NewReader(v)
+			}
+		case []byte:
+			if specs[i].isLob() {
+				args[i] = bytes.NewReader(v)
+			}
+		}
+	}
+	return args
+}
+
+// CreateTableStmt returns the create table statement for table with column specifications specs.
+func CreateTableStmt(table string, specs []ColumnSpec) string {
+	cols := make([]string, len(specs))
+	for i, spec := range specs {
+		cols[i] = quoteIdentifier(spec.Name) + " " + spec.SQLType()
+		if !spec.Nullable {
+			cols[i] += " not null"
+		}
+	}
+	return fmt.Sprintf("create table %s (%s)", table, strings.Join(cols, ", "))
+}
+
+// InsertStmt returns the insert statement for table with column specifications specs.
+func InsertStmt(table string, specs []ColumnSpec) string {
+	return fmt.Sprintf("insert into %s values (%s)", table, strings.TrimSuffix(strings.Repeat("?, ", len(specs)), ", "))
+}
+
+// Seed inserts n random rows into table in a single transaction and returns the inserted rows.
+// The table needs to be created before (see CreateTableStmt).
+func (g *Generator) Seed(ctx context.Context, db *sql.DB, table string, specs []ColumnSpec, n int) ([][]interface{}, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	stmt, err := tx.PrepareContext(ctx, InsertStmt(table, specs))
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows := make([][]interface{}, n)
+	for i := 0; i < n; i++ {
+		row, err := g.Row(specs)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := stmt.ExecContext(ctx, g.Args(specs, row)...); err != nil {
+			return nil, err
+		}
+		rows[i] = row
+	}
+	return rows, tx.Commit()
+}
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+	"github.com/SAP/go-hdb/driver/hdbtest"
+)
+
+func newDecimal(r *big.Rat) interface{} { return (*driver.Decimal)(r) }
+
+// generatorSpecs returns the column specifications supported by the hdbtest driver.
+func generatorSpecs() []drivertest.ColumnSpec {
+	var specs []drivertest.ColumnSpec
+	for _, spec := range drivertest.ColumnSpecs() {
+		if spec.TypeName != "SMALLDECIMAL" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// generatorScanner is the scan destination of a generated value.
+type generatorScanner struct {
+	v   interface{}
+	buf *bytes.Buffer // lob content
+}
+
+func newGeneratorScanner(spec drivertest.ColumnSpec, v interface{}) *generatorScanner {
+	switch {
+	case isLob(spec):
+		buf := new(bytes.Buffer)
+		return &generatorScanner{v: driver.NewLob(nil, buf), buf: buf}
+	case spec.TypeName == "DECIMAL":
+		return &generatorScanner{v: new(driver.Decimal)}
+	default:
+		return &generatorScanner{v: reflect.New(reflect.TypeOf(v)).Interface()}
+	}
+}
+
+// value returns the scanned value in the type of the generated value v.
+func (s *generatorScanner) value(v interface{}) interface{} {
+	switch x := s.v.(type) {
+	case *driver.Decimal:
+		return (*big.Rat)(x)
+	case *driver.Lob:
+		if _, ok := v.([]byte); ok {
+			return s.buf.Bytes()
+		}
+		return s.buf.String()
+	default:
+		return reflect.ValueOf(x).Elem().Interface()
+	}
+}
+
+func isLob(spec drivertest.ColumnSpec) bool {
+	switch spec.TypeName {
+	case "BLOB", "CLOB", "NCLOB", "TEXT":
+		return true
+	}
+	return false
+}
+
+func testGeneratorRoundTrip(db *sql.DB, t *testing.T) {
+	const numRow = 100
+
+	specs := generatorSpecs()
+	if _, err := db.Exec(drivertest.CreateTableStmt("generator", specs)); err != nil {
+		t.Fatal(err)
+	}
+
+	g := drivertest.NewGenerator(time.Now().UnixNano())
+	g.NewDecimal = newDecimal
+	rows, err := g.Seed(context.Background(), db, "generator", specs, numRow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := db.Query("select * from generator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+
+	i := 0
+	for result.Next() {
+		row := rows[i]
+		scanners := make([]*generatorScanner, len(row))
+		dest := make([]interface{}, len(row))
+		for j, v := range row {
+			scanners[j] = newGeneratorScanner(specs[j], v)
+			dest[j] = scanners[j].v
+		}
+		if err := result.Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+		for j, v := range row {
+			got := scanners[j].value(v)
+			switch v := v.(type) {
+			case *big.Rat:
+				if v.Cmp(got.(*big.Rat)) != 0 {
+					t.Fatalf("row %d column %s value %s - expected %s", i, specs[j].Name, got.(*big.Rat).RatString(), v.RatString())
+				}
+				continue
+			case []byte: // nil and empty slices are equal
+				if !bytes.Equal(got.([]byte), v) {
+					t.Fatalf("row %d column %s value %v - expected %v", i, specs[j].Name, got, v)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(got, v) {
+				t.Fatalf("row %d column %s value %v - expected %v", i, specs[j].Name, got, v)
+			}
+		}
+		i++
+	}
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != numRow {
+		t.Fatalf("number of rows %d - expected %d", i, numRow)
+	}
+}
+
+func testGeneratorDeterministic(db *sql.DB, t *testing.T) {
+	specs := drivertest.ColumnSpecs()
+	g1, g2 := drivertest.NewGenerator(42), drivertest.NewGenerator(42)
+	for i := 0; i < 10; i++ {
+		r1, err := g1.Row(specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r2, err := g2.Row(specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r1, r2) {
+			t.Fatalf("row %v - expected %v", r2, r1)
+		}
+	}
+}
+
+func testGeneratorLimits(db *sql.DB, t *testing.T) {
+	specs := []drivertest.ColumnSpec{
+		{Name: "D", TypeName: "DECIMAL", Precision: 5, Scale: 2},
+		{Name: "S", TypeName: "NVARCHAR", Length: 3},
+		{Name: "C", TypeName: "CHAR", Length: 4},
+		{Name: "N", TypeName: "INTEGER", Nullable: true},
+	}
+	max := big.NewRat(99999, 100)
+
+	g := drivertest.NewGenerator(time.Now().UnixNano())
+	g.NullRatio = 1
+	for i := 0; i < 100; i++ {
+		row, err := g.Row(specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := new(big.Rat).Abs(row[0].(*big.Rat)); d.Cmp(max) > 0 {
+			t.Fatalf("decimal %s exceeds %s", d.RatString(), max.RatString())
+		}
+		if n := len([]rune(row[1].(string))); n > 3 {
+			t.Fatalf("string length %d - expected <= %d", n, 3)
+		}
+		if n := len(row[2].(string)); n != 4 {
+			t.Fatalf("char length %d - expected %d", n, 4)
+		}
+		if row[3] != nil {
+			t.Fatalf("value %v - expected nil", row[3])
+		}
+	}
+	if _, err := g.Value(drivertest.ColumnSpec{Name: "X", TypeName: "ST_POINT"}); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("error %v - expected unsupported type error", err)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(db *sql.DB, t *testing.T)
+	}{
+		{"roundTrip", testGeneratorRoundTrip},
+		{"deterministic", testGeneratorDeterministic},
+		{"limits", testGeneratorLimits},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := "generator_" + test.name
+			db := sql.OpenDB(hdbtest.NewConnector(name))
+			defer hdbtest.Drop(name)
+			defer db.Close()
+			test.fct(db, t)
+		})
+	}
+}
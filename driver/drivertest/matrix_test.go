@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package drivertest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testMatrixVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		cmp  int
+	}{
+		{"2.00.045.00.1575639312", "2.00.045", 0},
+		{"2.00.045.00", "2.00.040.00", 1},
+		{"2.00.040.01", "2.00.045.00", -1},
+		{"4.00.000.00.1608802791", "2.00.059.00", 1},
+	}
+	for _, test := range tests {
+		a, b := drivertest.MustParseVersion(test.a), drivertest.MustParseVersion(test.b)
+		if cmp := a.Compare(b); cmp != test.cmp {
+			t.Fatalf("compare %s %s: %d - expected %d", a, b, cmp, test.cmp)
+		}
+	}
+	if _, err := drivertest.ParseVersion("2.x"); err == nil {
+		t.Fatal("error expected for invalid version")
+	}
+	if v := drivertest.MustParseVersion("2.00.045.00.1575639312").String(); v != "2.00.045.00" {
+		t.Fatalf("version %s - expected %s", v, "2.00.045.00")
+	}
+}
+
+func testMatrixParse(t *testing.T) {
+	m, err := drivertest.ParseMatrix("sps04=hdb://u:p@host1:30015?timeout=10; cloud=hdb://u:p@host2:443", "4, 8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"sps04_dfv_4", "sps04_dfv_8", "cloud_dfv_4", "cloud_dfv_8"}
+	if len(m) != len(names) {
+		t.Fatalf("number of targets %d - expected %d", len(m), len(names))
+	}
+	for i, tg := range m {
+		if tg.String() != names[i] {
+			t.Fatalf("target %s - expected %s", tg, names[i])
+		}
+	}
+	if m[0].DSN != "hdb://u:p@host1:30015?timeout=10" {
+		t.Fatalf("dsn %s - expected %s", m[0].DSN, "hdb://u:p@host1:30015?timeout=10")
+	}
+
+	if _, err := drivertest.ParseMatrix("hdb://u:p@host1:30015", ""); err == nil {
+		t.Fatal("error expected for target without name")
+	}
+	if _, err := drivertest.ParseMatrix("a=hdb://u:p@host1:30015", "x"); err == nil {
+		t.Fatal("error expected for invalid data format version")
+	}
+}
+
+func testMatrixRequirement(t *testing.T) {
+	sps04 := &drivertest.Target{Name: "sps04", Dfv: 4, Version: drivertest.MustParseVersion("2.00.045")}
+	cloud := &drivertest.Target{Name: "cloud", Dfv: 8, Version: drivertest.MustParseVersion("4.00.000")}
+
+	tests := []struct {
+		r                drivertest.Requirement
+		sps04OK, cloudOK bool
+	}{
+		{drivertest.Requirement{}, true, true},
+		{drivertest.Requirement{MinVersion: drivertest.MustParseVersion("2.00.050")}, false, true},
+		{drivertest.Requirement{MinDfv: 8}, false, true},
+		{drivertest.Requirement{Cloud: true}, false, true},
+		{drivertest.Requirement{NoCloud: true}, true, false},
+	}
+	for i, test := range tests {
+		if ok, _ := test.r.Check(sps04); ok != test.sps04OK {
+			t.Fatalf("test %d: sps04 %t - expected %t", i, ok, test.sps04OK)
+		}
+		if ok, _ := test.r.Check(cloud); ok != test.cloudOK {
+			t.Fatalf("test %d: cloud %t - expected %t", i, ok, test.cloudOK)
+		}
+	}
+}
+
+func testMatrixRun(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select version from sys.m_database", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "VERSION", TypeName: "NVARCHAR"}},
+		Rows:    [][]interface{}{{"2.00.045.00.1575639312"}},
+	})
+
+	dsn := fmt.Sprintf("hdb://user:password@%s", s.Host())
+	m, err := drivertest.ParseMatrix("mock="+dsn, "4,8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DetectVersions(context.Background(), driver.DriverName); err != nil {
+		t.Fatal(err)
+	}
+
+	var run []string
+	t.Run("dfv8", func(t *testing.T) {
+		m.Run(t, drivertest.Requirement{MinVersion: drivertest.MustParseVersion("2.00.040"), MinDfv: 8}, func(t *testing.T, tg *drivertest.Target) {
+			run = append(run, tg.String())
+		})
+	})
+	if len(run) != 1 || run[0] != "mock_dfv_8" {
+		t.Fatalf("run targets %v - expected %v", run, []string{"mock_dfv_8"})
+	}
+	if v := m[0].Version.String(); v != "2.00.045.00" {
+		t.Fatalf("version %s - expected %s", v, "2.00.045.00")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"version", testMatrixVersion},
+		{"parse", testMatrixParse},
+		{"requirement", testMatrixRequirement},
+		{"run", testMatrixRun},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,615 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package drivertest
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// MockColumn is a result set column of a MockStatement.
+type MockColumn struct {
+	Name     string
+	TypeName string // BOOLEAN, TINYINT, SMALLINT, INTEGER, BIGINT, DOUBLE, NVARCHAR, VARBINARY, TIMESTAMP or BLOB
+}
+
+// MockResult is the result of a MockStatement function.
+type MockResult struct {
+	Rows         [][]interface{}
+	RowsAffected int64
+	// RowErrors are the errors of rows rejected by a bulk execution (row index: error). RowsAffected is ignored, as
+	// the rows not rejected are reported to affect one row each.
+	RowErrors map[int]*MockError
+}
+
+// MockError is a database error returned by a MockStatement.
+type MockError struct {
+	Code     int
+	Position int    // start position of the erroneous statement part
+	SQLState string // defaults to HY000
+	Text     string
+}
+
+func (e *MockError) Error() string { return fmt.Sprintf("SQL Error %d - %s", e.Code, e.Text) }
+
+// ErrMockDisconnect can be returned by a MockStatement function to close the connection instead of replying to the execution.
+var ErrMockDisconnect = p.ErrServerDisconnect
+
+/*
+MockStatement defines the server behavior for a statement.
+
+Statements with columns are queries returning Rows, statements without columns return RowsAffected.
+If Func is set, rows and rows affected are taken from the function result.
+Params are the database type names of the statement parameters. If not set, a NVARCHAR parameter is assumed
+for each placeholder in the statement. Statements with BLOB or NCLOB parameters are executed after the lob content
+is written by the client. The lob content is passed to Func as []byte (BLOB) or string (NCLOB) and the
+number of rows is returned as rows affected.
+*/
+type MockStatement struct {
+	Params       []string
+	Columns      []MockColumn
+	Rows         [][]interface{}
+	RowsAffected int64
+	Func         func(args []interface{}) (*MockResult, error)
+
+	VolumeID int // volume id of the table location returned by the prepare reply (0: none, see MockServer.SetTopology)
+
+	Err        error         // error returned by the execution (use MockError for database errors)
+	Delay      time.Duration // delay before the server replies to the execution
+	Disconnect bool          // server closes the connection instead of replying to the execution
+}
+
+// ErrorCodeInvalidStatement is the database error code returned for statements unknown to the MockServer.
+const ErrorCodeInvalidStatement = 257
+
+// ErrorCodeQueryTimeout is the database error code returned for statement executions exceeding
+// the server statement timeout set by the client (see driver.Connector.SetStatementTimeout).
+const ErrorCodeQueryTimeout = p.ServerErrorCodeQueryTimeout
+
+// ErrorCodeCanceled is the database error code returned for statement executions canceled by a cancel request
+// (see driver.Connector.SetStatementCancel). Delayed statements (see MockStatement.Delay) can be canceled.
+const ErrorCodeCanceled = p.ServerErrorCodeCanceled
+
+// ErrorCodeInvalidStatementID is the database error code returned for executions of prepared statements
+// invalidated by the MockServer (see InvalidateStatements).
+const ErrorCodeInvalidStatementID = p.ServerErrorCodeInvalidStatementID
+
+// ErrorCodePasswordChangeRequired is the database error code returned for connects and statements of users
+// forced to change the password (see MockServer.SetPasswordChangeRequired).
+const ErrorCodePasswordChangeRequired = p.ServerErrorCodePasswordChangeRequired
+
+// ErrorCodePasswordReuse is the database error code returned for password changes reusing the current password.
+const ErrorCodePasswordReuse = 412
+
+// pingQuery is the driver statement checking the database connection.
+const pingQuery = "select 1 from dummy"
+
+/*
+MockServer is a scriptable server implementing a subset of the hdb protocol
+(handshake, SCRAMSHA256, GSS, X509, JWT and SAML authentication, prepare, execute, lob write and fetch) to run driver tests without a database.
+
+Statements the server should answer are registered via Handle. By default all users with
+any password are accepted - use SetCredentials to enable the password verification.
+
+The server is only built with the unit build tag (go test -tags unit), so that it is not part of
+applications using the driver.
+*/
+type MockServer struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu        sync.RWMutex
+	username  string
+	password  string
+	stmts     map[string]*MockStatement
+	conns     map[net.Conn]*p.ServerSession
+	sessionID int64
+	version   string // database version ("": default version)
+	maxDfv    int    // maximal data format version (0: any version)
+	topology  []p.TopologyHost
+	lobLimit  int64 // read lob replies end the lob content after lobLimit bytes (0: no limit)
+	closed    bool
+
+	sourceModule string // source module of the last received command info
+	lineNumber   int    // line number of the last received command info
+
+	clientInfo map[string]string // received client info (session variables)
+
+	gssAcceptor   func(token []byte) (string, []byte, error)      // GSS authentication (nil: not supported)
+	x509Acceptor  func(certs []*x509.Certificate) (string, error) // X509 authentication (nil: not supported)
+	tokenAcceptor func(method, token string) (string, error)      // JWT and SAML authentication (nil: not supported)
+
+	passwordChange bool // user is forced to change the password (see SetPasswordChangeRequired)
+
+	delayed     map[int64]chan struct{} // cancel channels of sessions executing a delayed statement
+	cancelDelay time.Duration           // delay of cancel requests
+	canceled    int                     // number of canceled statements
+	prepared    int                     // number of prepared statements
+}
+
+// NewMockServer starts and returns a new MockServer listening on a local tcp port.
+func NewMockServer() (*MockServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &MockServer{
+		ln:      ln,
+		stmts:   map[string]*MockStatement{},
+		conns:   map[net.Conn]*p.ServerSession{},
+		delayed: map[int64]chan struct{}{},
+	}
+	s.Handle(pingQuery, &MockStatement{Columns: []MockColumn{{Name: "1", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}}})
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// NewTestMockServer starts a MockServer accepting the user "user" with password "password" and fails the test
+// if the server cannot be started. The server needs to be closed by the caller.
+func NewTestMockServer(tb testing.TB) *MockServer {
+	tb.Helper()
+	s, err := NewMockServer()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	s.SetCredentials("user", "password")
+	return s
+}
+
+// Host returns the host address ("host:port") of the server.
+func (s *MockServer) Host() string { return s.ln.Addr().String() }
+
+// SetCredentials sets the credentials of the only user accepted by the server.
+func (s *MockServer) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username, s.password = username, password
+}
+
+/*
+SetPasswordChangeRequired forces the user set by SetCredentials to change the password. Connections are
+restricted to the password change (ALTER USER <user> PASSWORD <password>) until the password is changed.
+Statements other than the password change fail with error code ErrorCodePasswordChangeRequired.
+*/
+func (s *MockServer) SetPasswordChangeRequired(b bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passwordChange = b
+}
+
+// Password returns the password of the user set by SetCredentials (changed passwords included).
+func (s *MockServer) Password() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.password
+}
+
+// SetVersion sets the database version reported to clients connecting after the call (e.g. 1.00.122.00.1466466057).
+func (s *MockServer) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// SetMaxDfv sets the maximal data format version negotiated with clients connecting after the call
+// (0: any version requested by the client).
+func (s *MockServer) SetMaxDfv(dfv int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxDfv = dfv
+}
+
+// SetLobLimit ends the lob content returned by read lob requests of clients connecting after the call after
+// limit bytes, like a lob shrinking after the query reported its size (0: no limit).
+func (s *MockServer) SetLobLimit(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lobLimit = limit
+}
+
+// MockTopologyHost is a database host of the topology information returned by the MockServer (see SetTopology).
+type MockTopologyHost struct {
+	Address   string // host address ("host:port")
+	VolumeID  int    // volume id of the index server (statement routing, see MockStatement.VolumeID)
+	Master    bool   // master index server
+	Secondary bool   // read-enabled secondary site (Active/Active read enabled)
+}
+
+// SetTopology sets the database hosts of the topology information returned to clients connecting after the call
+// (no hosts: no topology information). The host matching the server address is reported as host of the current session.
+func (s *MockServer) SetTopology(hosts ...MockTopologyHost) error {
+	topology := make([]p.TopologyHost, 0, len(hosts))
+	for _, h := range hosts {
+		host, portStr, err := net.SplitHostPort(h.Address)
+		if err != nil {
+			return err
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return err
+		}
+		topology = append(topology, p.TopologyHost{Host: host, Port: port, VolumeID: h.VolumeID, Master: h.Master, Secondary: h.Secondary})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topology = topology
+	return nil
+}
+
+// SetGSSAcceptor enables the GSS (Kerberos) authentication of clients connecting after the call.
+// The acceptor returns the database user and the reply token of the client token or an error,
+// if the client cannot be authenticated. Setting nil disables the GSS authentication.
+func (s *MockServer) SetGSSAcceptor(acceptor func(token []byte) (username string, replyToken []byte, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gssAcceptor = acceptor
+}
+
+// SetX509Acceptor enables the X509 client certificate authentication of clients connecting after the call.
+// The acceptor is called with the certificate chain (client certificate first) of clients which proved the
+// possession of the private key and returns the database user or an error, if the certificate is not accepted.
+// Setting nil disables the X509 authentication.
+func (s *MockServer) SetX509Acceptor(acceptor func(certs []*x509.Certificate) (username string, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.x509Acceptor = acceptor
+}
+
+// SetTokenAcceptor enables the token authentication of clients connecting after the call.
+// The acceptor is called with the authentication method (JWT or SAML) and the token and returns the database user
+// or an error, if the token is not accepted. Setting nil disables the token authentication.
+func (s *MockServer) SetTokenAcceptor(acceptor func(method, token string) (username string, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenAcceptor = acceptor
+}
+
+// Handle registers the statement behavior for query. Queries are matched ignoring case and surrounding whitespace.
+func (s *MockServer) Handle(query string, stmt *MockStatement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stmts[normQuery(query)] = stmt
+}
+
+// InvalidateStatements invalidates the prepared statements of all client connections, like a database server
+// does e.g. after a failover. Executions of invalidated statements fail with ErrorCodeInvalidStatementID.
+func (s *MockServer) InvalidateStatements() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, session := range s.conns {
+		session.InvalidateStatements()
+	}
+}
+
+// CommandInfo returns the source module and line number of the last command info sent by a client.
+func (s *MockServer) CommandInfo() (string, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sourceModule, s.lineNumber
+}
+
+// ClientInfo returns the session variables received as client info with statement requests of all clients.
+func (s *MockServer) ClientInfo() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := make(map[string]string, len(s.clientInfo))
+	for k, v := range s.clientInfo {
+		m[k] = v
+	}
+	return m
+}
+
+// SetCancelDelay delays the execution of cancel requests by d.
+func (s *MockServer) SetCancelDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelDelay = d
+}
+
+// Canceled returns the number of statement executions canceled by cancel requests.
+func (s *MockServer) Canceled() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.canceled
+}
+
+// Prepared returns the number of statements prepared by the server.
+func (s *MockServer) Prepared() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.prepared
+}
+
+// Close stops the server and closes all client connections.
+func (s *MockServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *MockServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.sessionID++
+		session := p.NewServerSession(conn, s.sessionID)
+		if s.version != "" {
+			session.SetVersion(s.version)
+		}
+		session.SetMaxDfv(s.maxDfv)
+		session.SetTopology(s.topology)
+		session.SetLobLimit(s.lobLimit)
+		s.conns[conn] = session
+		s.mu.Unlock()
+
+		h := mockHandler{MockServer: s, sessionID: s.sessionID}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			session.Serve(h) // errors are reported to the client
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// mockHandler implements the protocol.ServerHandler interface for a MockServer session.
+type mockHandler struct {
+	*MockServer
+	sessionID int64
+}
+
+func serverError(err error) error {
+	if e, ok := err.(*MockError); ok {
+		return &p.ServerError{Code: e.Code, Position: e.Position, SQLState: e.SQLState, Text: e.Text}
+	}
+	return err
+}
+
+func normQuery(query string) string { return strings.ToLower(strings.TrimSpace(query)) }
+
+// numParam returns the number of parameters (?) of query ignoring question marks in literals and comments.
+func numParam(query string) int {
+	var sc scanner.Scanner
+	sc.Reset(query)
+	n := 0
+	for token, _, _ := sc.Next(); token != scanner.EOS; token, _, _ = sc.Next() {
+		if token == scanner.Variable {
+			n++
+		}
+	}
+	return n
+}
+
+// cancelWorkPrefix is the prefix of the cancel request statement (alter system cancel work in session '<session id>').
+const cancelWorkPrefix = "alter system cancel work in session "
+
+// cancelWork returns the session id of a cancel request or false, if query is not a cancel request.
+func cancelWork(query string) (int64, bool) {
+	query = normQuery(query)
+	if !strings.HasPrefix(query, cancelWorkPrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.Trim(query[len(cancelWorkPrefix):], "'"), 10, 64)
+	return id, err == nil
+}
+
+// cancel cancels the delayed statement executed by session sessionID (if any).
+func (s *MockServer) cancel(sessionID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.delayed[sessionID]; ok {
+		close(ch)
+		delete(s.delayed, sessionID)
+	}
+}
+
+// delay delays the statement execution of session sessionID by d or until the execution gets canceled.
+func (s *MockServer) delay(sessionID int64, d time.Duration) error {
+	ch := make(chan struct{})
+	s.mu.Lock()
+	s.delayed[sessionID] = ch
+	s.mu.Unlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		s.cancel(sessionID) // remove cancel channel
+		return nil
+	case <-ch:
+		s.mu.Lock()
+		s.canceled++
+		s.mu.Unlock()
+		return &p.ServerError{Code: ErrorCodeCanceled, Text: "current operation cancelled by request and transaction rolled back"}
+	}
+}
+
+func (s *MockServer) stmt(query string) (*MockStatement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stmt, ok := s.stmts[normQuery(query)]
+	if !ok {
+		return nil, &p.ServerError{Code: ErrorCodeInvalidStatement, Text: fmt.Sprintf("invalid table name: %s", query)}
+	}
+	return stmt, nil
+}
+
+// Password implements the protocol.ServerHandler interface.
+func (s mockHandler) Password(username string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.username == "" {
+		return "", true
+	}
+	return s.password, username == s.username
+}
+
+// PasswordChangeRequired implements the protocol.ServerPasswordChangeHandler interface.
+func (s mockHandler) PasswordChangeRequired(username string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.passwordChange && username == s.username
+}
+
+// ChangePassword implements the protocol.ServerPasswordChangeHandler interface.
+func (s mockHandler) ChangePassword(username, password string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if password == s.password {
+		return &p.ServerError{Code: ErrorCodePasswordReuse, Text: "invalid password: last password used is not allowed"}
+	}
+	s.password = password
+	s.passwordChange = false
+	return nil
+}
+
+// AcceptSecContext implements the protocol.ServerGSSHandler interface.
+func (s mockHandler) AcceptSecContext(token []byte) (string, []byte, error) {
+	s.mu.RLock()
+	acceptor := s.gssAcceptor
+	s.mu.RUnlock()
+	if acceptor == nil {
+		return "", nil, errors.New("gss authentication not supported")
+	}
+	return acceptor(token)
+}
+
+// AuthenticateX509 implements the protocol.ServerX509Handler interface.
+func (s mockHandler) AuthenticateX509(certs []*x509.Certificate) (string, error) {
+	s.mu.RLock()
+	acceptor := s.x509Acceptor
+	s.mu.RUnlock()
+	if acceptor == nil {
+		return "", errors.New("x509 authentication not supported")
+	}
+	return acceptor(certs)
+}
+
+// AuthenticateToken implements the protocol.ServerTokenHandler interface.
+func (s mockHandler) AuthenticateToken(method, token string) (string, error) {
+	s.mu.RLock()
+	acceptor := s.tokenAcceptor
+	s.mu.RUnlock()
+	if acceptor == nil {
+		return "", errors.New("token authentication not supported")
+	}
+	return acceptor(method, token)
+}
+
+// Prepare implements the protocol.ServerHandler interface.
+func (s mockHandler) Prepare(query string) (*p.ServerStmt, error) {
+	if _, ok := cancelWork(query); ok {
+		return &p.ServerStmt{}, nil
+	}
+	stmt, err := s.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.prepared++
+	s.mu.Unlock()
+	params := stmt.Params
+	if params == nil {
+		for i := numParam(query); i > 0; i-- {
+			params = append(params, "NVARCHAR")
+		}
+	}
+	columns := make([]p.ServerColumn, len(stmt.Columns))
+	for i, c := range stmt.Columns {
+		columns[i] = p.ServerColumn{Name: c.Name, TypeName: c.TypeName}
+	}
+	return &p.ServerStmt{Params: params, Columns: columns, VolumeID: stmt.VolumeID}, nil
+}
+
+// CommandInfo implements the protocol.ServerCommandInfoHandler interface.
+func (s mockHandler) CommandInfo(sourceModule string, lineNumber int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sourceModule, s.lineNumber = sourceModule, lineNumber
+}
+
+// ClientInfo implements the protocol.ServerClientInfoHandler interface.
+func (s mockHandler) ClientInfo(m map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clientInfo == nil {
+		s.clientInfo = map[string]string{}
+	}
+	for k, v := range m {
+		s.clientInfo[k] = v
+	}
+}
+
+// Execute implements the protocol.ServerHandler interface.
+func (s mockHandler) Execute(query string, args []interface{}) (*p.ServerResult, error) {
+	if sessionID, ok := cancelWork(query); ok {
+		s.mu.RLock()
+		d := s.cancelDelay
+		s.mu.RUnlock()
+		time.Sleep(d)
+		s.cancel(sessionID)
+		return &p.ServerResult{}, nil
+	}
+	stmt, err := s.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Delay != 0 {
+		if err := s.delay(s.sessionID, stmt.Delay); err != nil {
+			return nil, err
+		}
+	}
+	if stmt.Disconnect {
+		return nil, p.ErrServerDisconnect
+	}
+	if stmt.Err != nil {
+		return nil, serverError(stmt.Err)
+	}
+	if stmt.Func != nil {
+		result, err := stmt.Func(args)
+		if err != nil {
+			return nil, serverError(err)
+		}
+		sr := &p.ServerResult{Rows: result.Rows, RowsAffected: result.RowsAffected}
+		if len(result.RowErrors) != 0 {
+			sr.RowErrors = make(map[int]*p.ServerError, len(result.RowErrors))
+			for i, e := range result.RowErrors {
+				sr.RowErrors[i] = &p.ServerError{Code: e.Code, Position: e.Position, SQLState: e.SQLState, Text: e.Text}
+			}
+		}
+		return sr, nil
+	}
+	return &p.ServerResult{Rows: stmt.Rows, RowsAffected: stmt.RowsAffected}, nil
+}
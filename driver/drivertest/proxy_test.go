@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package drivertest_test
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/dial"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+const proxyQuery = "select 1 from dummy"
+
+// testProxy is a minimal SOCKS5 or HTTP CONNECT proxy server recording the requested target addresses.
+type testProxy struct {
+	ln     net.Listener
+	socks  bool
+	user   *url.Userinfo // required credentials (nil: no authentication)
+	mu     sync.Mutex
+	target []string
+}
+
+func newTestProxy(t *testing.T, socks bool, user *url.Userinfo) *testProxy {
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProxy{ln: ln, socks: socks, user: user}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(conn)
+		}
+	}()
+	return p
+}
+
+func (p *testProxy) close() { p.ln.Close() }
+
+func (p *testProxy) url() *url.URL {
+	scheme := "http"
+	if p.socks {
+		scheme = "socks5"
+	}
+	return &url.URL{Scheme: scheme, Host: p.ln.Addr().String(), User: p.user}
+}
+
+func (p *testProxy) targets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.target...)
+}
+
+func (p *testProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	var target string
+	var ok bool
+	if p.socks {
+		target, ok = p.socksHandshake(rd, conn)
+	} else {
+		target, ok = p.httpHandshake(rd, conn)
+	}
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	p.target = append(p.target, target)
+	p.mu.Unlock()
+
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	go io.Copy(upstream, rd)
+	io.Copy(conn, upstream)
+}
+
+func (p *testProxy) socksHandshake(rd *bufio.Reader, conn net.Conn) (string, bool) {
+	b := make([]byte, 2)
+	if _, err := io.ReadFull(rd, b); err != nil {
+		return "", false
+	}
+	methods := make([]byte, b[1])
+	if _, err := io.ReadFull(rd, methods); err != nil {
+		return "", false
+	}
+	if p.user == nil {
+		conn.Write([]byte{5, 0})
+	} else {
+		conn.Write([]byte{5, 2})
+		// username / password authentication
+		if _, err := io.ReadFull(rd, b); err != nil {
+			return "", false
+		}
+		username := make([]byte, b[1])
+		io.ReadFull(rd, username)
+		n, _ := rd.ReadByte()
+		password := make([]byte, n)
+		io.ReadFull(rd, password)
+		expPassword, _ := p.user.Password()
+		if string(username) != p.user.Username() || string(password) != expPassword {
+			conn.Write([]byte{1, 1})
+			return "", false
+		}
+		conn.Write([]byte{1, 0})
+	}
+
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(rd, req); err != nil {
+		return "", false
+	}
+	var host string
+	switch req[3] {
+	case 1, 4:
+		ip := make([]byte, 4)
+		if req[3] == 4 {
+			ip = make([]byte, 16)
+		}
+		io.ReadFull(rd, ip)
+		host = net.IP(ip).String()
+	case 3:
+		n, _ := rd.ReadByte()
+		name := make([]byte, n)
+		io.ReadFull(rd, name)
+		host = string(name)
+	}
+	port := make([]byte, 2)
+	io.ReadFull(rd, port)
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), true
+}
+
+func (p *testProxy) httpHandshake(rd *bufio.Reader, conn net.Conn) (string, bool) {
+	req, err := http.ReadRequest(rd)
+	if err != nil || req.Method != http.MethodConnect {
+		return "", false
+	}
+	if p.user != nil {
+		username, password, ok := (&http.Request{Header: http.Header{"Authorization": req.Header["Proxy-Authorization"]}}).BasicAuth()
+		expPassword, _ := p.user.Password()
+		if !ok || username != p.user.Username() || password != expPassword {
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return "", false
+		}
+	}
+	io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	return req.Host, true
+}
+
+func testProxyConnect(s *drivertest.MockServer, socks bool, user *url.Userinfo, t *testing.T) {
+	p := newTestProxy(t, socks, user)
+	defer p.close()
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetProxy(dial.ProxyURL(p.url())); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var i int
+	if err := db.QueryRow(proxyQuery).Scan(&i); err != nil {
+		t.Fatal(err)
+	}
+	if targets := p.targets(); len(targets) == 0 || targets[0] != s.Host() {
+		t.Fatalf("proxy targets %v - expected %s", targets, s.Host())
+	}
+
+	if user == nil {
+		return
+	}
+	// invalid credentials
+	invalidURL := p.url()
+	invalidURL.User = url.UserPassword(user.Username(), "invalid")
+	connector.SetProxy(dial.ProxyURL(invalidURL))
+	invalidDB := sql.OpenDB(connector)
+	defer invalidDB.Close()
+	if err := invalidDB.Ping(); err == nil {
+		t.Fatal("error expected for invalid proxy credentials")
+	}
+}
+
+func testProxyFromEnvironment(s *drivertest.MockServer, t *testing.T) {
+	p := newTestProxy(t, true, nil)
+	defer p.close()
+
+	for _, name := range []string{"ALL_PROXY", "all_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		if v, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, v)
+			os.Unsetenv(name)
+		}
+	}
+	os.Setenv("ALL_PROXY", p.url().String())
+	defer os.Unsetenv("ALL_PROXY")
+
+	host, _, err := net.SplitHostPort(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testData := []struct {
+		noProxy string
+		proxied bool
+	}{
+		{"", true},
+		{"*", false},
+		{"example.com, " + host, false},
+		{".example.com", true},
+		{"127.0.0.0/8", host != "127.0.0.1"},
+	}
+	for _, d := range testData {
+		os.Setenv("NO_PROXY", d.noProxy)
+		proxyURL, err := dial.ProxyFromEnvironment(s.Host())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (proxyURL != nil) != d.proxied {
+			t.Fatalf("NO_PROXY %q: proxy %v - expected proxied %t", d.noProxy, proxyURL, d.proxied)
+		}
+	}
+	os.Unsetenv("NO_PROXY")
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetProxy(dial.ProxyFromEnvironment)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if targets := p.targets(); len(targets) != 1 {
+		t.Fatalf("proxy targets %v - expected %s", targets, s.Host())
+	}
+}
+
+func TestProxy(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle(proxyQuery, &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "1", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}}})
+
+	user := url.UserPassword("proxyuser", "proxypassword")
+
+	tests := []struct {
+		name string
+		fct  func(s *drivertest.MockServer, t *testing.T)
+	}{
+		{"socks5", func(s *drivertest.MockServer, t *testing.T) { testProxyConnect(s, true, nil, t) }},
+		{"socks5Auth", func(s *drivertest.MockServer, t *testing.T) { testProxyConnect(s, true, user, t) }},
+		{"httpConnect", func(s *drivertest.MockServer, t *testing.T) { testProxyConnect(s, false, nil, t) }},
+		{"httpConnectAuth", func(s *drivertest.MockServer, t *testing.T) { testProxyConnect(s, false, user, t) }},
+		{"environment", testProxyFromEnvironment},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(s, t)
+		})
+	}
+}
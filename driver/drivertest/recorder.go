@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// RecordedExchange is a recorded protocol request and the corresponding database reply.
+type RecordedExchange struct {
+	Request []byte `json:"request"`
+	Reply   []byte `json:"reply"`
+}
+
+// RecordedConn is the recorded protocol conversation of a client connection.
+type RecordedConn struct {
+	Exchanges []*RecordedExchange `json:"exchanges"` // first exchange: protocol prolog
+}
+
+/*
+A Recording is the recorded protocol conversation of a test run (see RecordProxy).
+Connections are recorded in the order the client opened them.
+*/
+type Recording struct {
+	mu    sync.Mutex
+	Conns []*RecordedConn `json:"conns"`
+}
+
+func (r *Recording) addConn() *RecordedConn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &RecordedConn{}
+	r.Conns = append(r.Conns, c)
+	return c
+}
+
+func (r *Recording) conn(idx int) (*RecordedConn, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if idx >= len(r.Conns) {
+		return nil, false
+	}
+	return r.Conns[idx], true
+}
+
+// Save writes the recording in json format.
+func (r *Recording) Save(wr io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.NewEncoder(wr).Encode(r)
+}
+
+// LoadRecording reads a recording saved by Recording.Save.
+func LoadRecording(rd io.Reader) (*Recording, error) {
+	r := &Recording{}
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// proxyServer is the listener part common to RecordProxy and ReplayServer.
+type proxyServer struct {
+	ln     net.Listener
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	cnt    int
+	errs   []error
+	closed bool
+}
+
+func newProxyServer(handler func(idx int, conn net.Conn) error) (*proxyServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &proxyServer{ln: ln, conns: map[net.Conn]struct{}{}}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			idx := s.cnt
+			s.cnt++
+			s.conns[conn] = struct{}{}
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				err := handler(idx, conn)
+				conn.Close()
+				s.mu.Lock()
+				delete(s.conns, conn)
+				if err != nil && err != io.EOF && !s.closed {
+					s.errs = append(s.errs, err)
+				}
+				s.mu.Unlock()
+			}()
+		}
+	}()
+	return s, nil
+}
+
+// Host returns the host address ("host:port") clients should connect to.
+func (s *proxyServer) Host() string { return s.ln.Addr().String() }
+
+// Err returns the first error of a connection handled by the server.
+func (s *proxyServer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return s.errs[0]
+}
+
+// Close stops the server and closes all client connections.
+func (s *proxyServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+/*
+RecordProxy is a proxy between client and database recording the protocol conversation.
+The recording can be replayed later on by a ReplayServer, so that tests can run deterministically without database access.
+*/
+type RecordProxy struct {
+	*proxyServer
+	dbAddr    string
+	recording *Recording
+}
+
+// NewRecordProxy starts a new RecordProxy forwarding client connections to the database address dbAddr ("host:port").
+func NewRecordProxy(dbAddr string) (*RecordProxy, error) {
+	rp := &RecordProxy{dbAddr: dbAddr, recording: &Recording{}}
+	s, err := newProxyServer(rp.record)
+	if err != nil {
+		return nil, err
+	}
+	rp.proxyServer = s
+	return rp, nil
+}
+
+// Recording returns the recording. The recording is complete after the proxy got closed.
+func (rp *RecordProxy) Recording() *Recording { return rp.recording }
+
+func (rp *RecordProxy) record(idx int, conn net.Conn) error {
+	dbConn, err := net.Dial("tcp", rp.dbAddr)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	c := rp.recording.addConn()
+	clRd, dbRd := bufio.NewReader(conn), bufio.NewReader(dbConn)
+
+	readRequest := func() ([]byte, error) { return p.ReadRawProlog(clRd, true) }
+	readReply := func() ([]byte, error) { return p.ReadRawProlog(dbRd, false) }
+	for {
+		request, err := readRequest()
+		if err != nil {
+			return err
+		}
+		if _, err := dbConn.Write(request); err != nil {
+			return err
+		}
+		reply, err := readReply()
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(reply); err != nil {
+			return err
+		}
+		rp.recording.mu.Lock()
+		c.Exchanges = append(c.Exchanges, &RecordedExchange{Request: request, Reply: reply})
+		rp.recording.mu.Unlock()
+
+		// after prolog: messages
+		readRequest = func() ([]byte, error) { return p.ReadRawMessage(clRd) }
+		readReply = func() ([]byte, error) { return p.ReadRawMessage(dbRd) }
+	}
+}
+
+/*
+ReplayServer replays a recorded protocol conversation (see RecordProxy).
+The n-th client connection gets the replies of the n-th recorded connection in recorded order.
+Client requests are not compared to the recorded requests - tests need to execute the same statements in the same order
+like during the recording.
+*/
+type ReplayServer struct {
+	*proxyServer
+	recording *Recording
+}
+
+// NewReplayServer starts a new ReplayServer for recording.
+func NewReplayServer(recording *Recording) (*ReplayServer, error) {
+	rs := &ReplayServer{recording: recording}
+	s, err := newProxyServer(rs.replay)
+	if err != nil {
+		return nil, err
+	}
+	rs.proxyServer = s
+	return rs, nil
+}
+
+func (rs *ReplayServer) replay(idx int, conn net.Conn) error {
+	c, ok := rs.recording.conn(idx)
+	if !ok {
+		return fmt.Errorf("replay: connection %d not recorded", idx)
+	}
+	rd := bufio.NewReader(conn)
+	for i, e := range c.Exchanges {
+		var err error
+		if i == 0 {
+			_, err = p.ReadRawProlog(rd, true)
+		} else {
+			_, err = p.ReadRawMessage(rd)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(e.Reply); err != nil {
+			return err
+		}
+	}
+	// wait for client to close the connection
+	if _, err := p.ReadRawMessage(rd); err != io.EOF {
+		return fmt.Errorf("replay: connection %d - request exceeds recording", idx)
+	}
+	return nil
+}
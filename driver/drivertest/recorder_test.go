@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package drivertest_test
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testRecordedQuery(t *testing.T, host string) {
+	db := sql.OpenDB(driver.NewBasicAuthConnector(host, "user", "password"))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var name string
+	if err := db.QueryRow("select name from persons where id = ?", 1).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Alice" {
+		t.Fatalf("name %s - expected %s", name, "Alice")
+	}
+	if _, err := db.Exec("delete from persons"); err == nil {
+		t.Fatal("error expected for unknown statement")
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	s.Handle("select name from persons where id = ?", &drivertest.MockStatement{
+		Params:  []string{"INTEGER"},
+		Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}},
+		Rows:    [][]interface{}{{"Alice"}},
+	})
+
+	// record
+	rp, err := drivertest.NewRecordProxy(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRecordedQuery(t, rp.Host())
+	rp.Close()
+	s.Close()
+	if err := rp.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := rp.Recording().Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	recording, err := drivertest.LoadRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recording.Conns) != 1 {
+		t.Fatalf("recorded connections %d - expected %d", len(recording.Conns), 1)
+	}
+
+	// replay without server
+	rs, err := drivertest.NewReplayServer(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRecordedQuery(t, rs.Host())
+	rs.Close()
+	if err := rs.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaPrefix is the default name prefix of test schemas.
+const SchemaPrefix = "goHdbTest_"
+
+// CleanupPolicy defines if a test schema is dropped after test execution.
+type CleanupPolicy int
+
+// CleanupPolicy constants.
+const (
+	DropOnSuccess CleanupPolicy = iota // drop schema if test ran successfully - keep schema on failure for debugging
+	DropAlways                         // drop schema in any case
+	KeepAlways                         // do not drop schema
+)
+
+var reInvalidSchemaChar = regexp.MustCompile("[^_A-Za-z0-9]")
+
+// SchemaName returns an unique schema name consisting of prefix, name (e.g. test or package name) and a random part.
+// Characters of name not allowed in simple identifiers are replaced by underscores.
+func SchemaName(prefix, name string) string {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(err.Error()) // rand should never fail
+	}
+	if name != "" {
+		name = reInvalidSchemaChar.ReplaceAllString(name, "_") + "_"
+	}
+	return fmt.Sprintf("%s%s%x", prefix, name, b)
+}
+
+// quoteIdentifier returns the quoted identifier s.
+func quoteIdentifier(s string) string { return strconv.Quote(s) }
+
+// Schema is a test schema.
+type Schema struct {
+	name   string
+	db     *sql.DB
+	policy CleanupPolicy
+}
+
+// CreateSchema creates a new test schema with an unique name (see SchemaName).
+// Parallel tests should use own schemas to be isolated from each other.
+func CreateSchema(ctx context.Context, db *sql.DB, prefix, name string, policy CleanupPolicy) (*Schema, error) {
+	s := &Schema{name: SchemaName(prefix, name), db: db, policy: policy}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("create schema %s", quoteIdentifier(s.name))); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the schema name.
+func (s *Schema) Name() string { return s.name }
+
+// Qualify returns the schema qualified name of the database object name.
+func (s *Schema) Qualify(name string) string {
+	return quoteIdentifier(s.name) + "." + quoteIdentifier(name)
+}
+
+// Cleanup drops the schema according to the schema cleanup policy.
+// The return value dropped reports, if the schema was dropped.
+func (s *Schema) Cleanup(ctx context.Context, failed bool) (dropped bool, err error) {
+	switch {
+	case s.policy == KeepAlways, s.policy == DropOnSuccess && failed:
+		return false, nil
+	}
+	if err := DropSchema(ctx, s.db, s.name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DropSchema drops the schema name including all contained database objects.
+func DropSchema(ctx context.Context, db *sql.DB, name string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("drop schema %s cascade", quoteIdentifier(name)))
+	return err
+}
+
+// likeEscape escapes like pattern characters.
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `_`, `\_`, `%`, `\%`).Replace(s)
+}
+
+/*
+DropStaleSchemas is the garbage collector for test schemas left on the database by aborted or failed test runs.
+It drops all schemas with name prefix created before maxAge and returns the names of the dropped schemas.
+Choose maxAge well above the maximum test execution time, so that schemas of concurrently running tests are not affected.
+*/
+func DropStaleSchemas(ctx context.Context, db *sql.DB, prefix string, maxAge time.Duration) ([]string, error) {
+	names, err := staleSchemas(ctx, db, prefix, maxAge)
+	if err != nil {
+		return nil, err
+	}
+	// cannot drop schemas in select loop (SQL Error 150 - statement cancelled or snapshot timestamp already invalidated)
+	dropped := make([]string, 0, len(names))
+	for _, name := range names {
+		if err := DropSchema(ctx, db, name); err != nil {
+			return dropped, err
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+func staleSchemas(ctx context.Context, db *sql.DB, prefix string, maxAge time.Duration) ([]string, error) {
+	query := fmt.Sprintf("select schema_name from sys.schemas where schema_name like '%s%%' escape '\\' and seconds_between(create_time, current_timestamp) > ?", likeEscape(prefix))
+	rows, err := db.QueryContext(ctx, query, int64(maxAge/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package drivertest
+
+import (
+	"regexp"
+	"testing"
+)
+
+func testSchemaName(t *testing.T) {
+	re := regexp.MustCompile(`^goHdbTest_TestX_sub_test_[0-9a-f]{16}$`)
+
+	name1 := SchemaName(SchemaPrefix, "TestX/sub test")
+	name2 := SchemaName(SchemaPrefix, "TestX/sub test")
+	if !re.MatchString(name1) {
+		t.Fatalf("schema name %s does not match %s", name1, re)
+	}
+	if name1 == name2 {
+		t.Fatalf("schema name %s - expected unique name", name1)
+	}
+}
+
+func testLikeEscape(t *testing.T) {
+	if s := likeEscape(`a_b%c\`); s != `a\_b\%c\\` {
+		t.Fatalf("escaped %s - expected %s", s, `a\_b\%c\\`)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"schemaName", testSchemaName},
+		{"likeEscape", testLikeEscape},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -4,6 +4,12 @@
 
 package driver
 
+import (
+	"context"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
 // HDB error levels.
 const (
 	HdbWarning    = 0
@@ -11,7 +17,35 @@ const (
 	HdbFatalError = 2
 )
 
-// Error represents errors send by the database server.
+/*
+HDB error codes of frequent database errors (see Error.Code).
+
+The error code identifies the cause of a database error and can be used to decide how to handle the error,
+e.g. to retry a transaction rolled back by a lock wait timeout, or to report a unique constraint violation to the user.
+*/
+const (
+	HdbErrCodeLockTimeout         = 131 // transaction rolled back by lock wait timeout
+	HdbErrCodeDeadlock            = 133 // transaction rolled back by detected deadlock
+	HdbErrCodeCanceled            = 139 // current operation cancelled by request and transaction rolled back
+	HdbErrCodeSyntax              = 257 // sql syntax error
+	HdbErrCodeInvalidTableName    = 259 // invalid table name
+	HdbErrCodeInvalidColumnName   = 260 // invalid column name
+	HdbErrCodeValueTooLarge       = 274 // inserted value too large for column
+	HdbErrCodeNotNull             = 287 // cannot insert NULL or update to NULL
+	HdbErrCodeDuplicateTableName  = 288 // cannot use duplicate table name
+	HdbErrCodeUniqueConstraint    = 301 // unique constraint violated
+	HdbErrCodeInvalidValue        = 339 // invalid number / value
+	HdbErrCodeForeignKeyViolation = 461 // foreign key constraint violation
+	HdbErrCodeQueryTimeout        = 613 // execution aborted by timeout
+)
+
+/*
+Error represents errors send by the database server.
+
+Database errors returned by the driver implement Error and can be retrieved via errors.As. Code and Position
+identify the error programmatically, the level (see HdbWarning, HdbError and HdbFatalError) its severity. In case of
+multiple errors (e.g. errors of bulk executions, see NumError) the methods refer to the error selected by SetIdx.
+*/
 type Error interface {
 	Error() string   // Implements the golang error interface.
 	NumError() int   // NumError returns the number of errors.
@@ -25,3 +59,61 @@ type Error interface {
 	IsError() bool   // IsError returns true if the HDB error level equals 1.
 	IsFatal() bool   // IsFatal returns true if the HDB error level equals 2.
 }
+
+/*
+SQLStateError is implemented by the database errors returned by the driver in addition to Error.
+
+SQLState returns the five character SQLSTATE code of the error (e.g. 23000 for integrity constraint violations)
+selected by Error.SetIdx.
+*/
+type SQLStateError interface {
+	SQLState() string
+}
+
+/*
+Errors of aborted statement executions matched by errors.Is.
+
+ErrQueryTimeout is matched by statement executions exceeding the server query timeout (see QueryOptions.ServerTimeout
+and Connector.SetStatementTimeout) and by executions aborted by a client side timeout (deadline of the context
+incl. QueryOptions.Timeout). Timed out queries might succeed if retried, e.g. with a higher timeout.
+
+ErrLockTimeout is matched by statement executions aborted by the database server because a lock could not be
+acquired within the lock wait timeout. The transaction is rolled back and might be retried after a backoff.
+
+ErrCanceled is matched by statement executions canceled by the database server (e.g. cancel session requests)
+and by executions aborted by the cancellation of the context. Canceled executions are usually propagated.
+
+Errors caused by done contexts wrap the context error, so that errors.Is(err, context.DeadlineExceeded) and
+errors.Is(err, context.Canceled) are still matched.
+*/
+var (
+	ErrQueryTimeout = p.ErrQueryTimeout
+	ErrLockTimeout  = p.ErrLockTimeout
+	ErrCanceled     = p.ErrCanceled
+)
+
+// ErrPasswordChangeRequired is matched by errors of connection attempts of users forced to change the
+// password, if no password change handler is set (see Connector.SetPasswordChangeHandler).
+var ErrPasswordChangeRequired = p.ErrPasswordChangeRequired
+
+// ctxError is the error of a statement execution aborted by a done context.
+type ctxError struct {
+	err error // context error
+}
+
+// newCtxError returns the error of a statement execution aborted by a done context with error err.
+func newCtxError(err error) error { return &ctxError{err: err} }
+
+func (e *ctxError) Error() string { return e.err.Error() }
+func (e *ctxError) Unwrap() error { return e.err }
+
+// Is returns true for target ErrQueryTimeout in case of a context deadline and for target ErrCanceled in case of a context cancellation.
+func (e *ctxError) Is(target error) bool {
+	switch e.err {
+	case context.DeadlineExceeded:
+		return target == ErrQueryTimeout
+	case context.Canceled:
+		return target == ErrCanceled
+	}
+	return false
+}
@@ -15,10 +15,6 @@ import (
 	"github.com/SAP/go-hdb/driver"
 )
 
-const (
-	errCodeInvalidTableName = 259
-)
-
 func ExampleError() {
 	db, err := sql.Open(driver.DriverName, driver.TestDSN)
 	if err != nil {
@@ -37,7 +33,7 @@ func ExampleError() {
 		// Check if error is driver.Error.
 		if errors.As(err, &dbError) {
 			switch dbError.Code() {
-			case errCodeInvalidTableName:
+			case driver.HdbErrCodeInvalidTableName:
 				fmt.Print("invalid table name")
 			default:
 				log.Fatalf("code %d text %s", dbError.Code(), dbError.Text())
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// ErrSliceExpansion is the error wrapped by errors raised if a slice argument cannot be expanded
+// (see Connector.SetSliceExpansion).
+var ErrSliceExpansion = errors.New("slice expansion failed")
+
+// isSliceArg returns true if v is a slice argument to be expanded.
+func isSliceArg(v driver.Value) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 // []byte is a binary value
+}
+
+func hasSliceArg(args []driver.NamedValue) bool {
+	for _, arg := range args {
+		if isSliceArg(arg.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// expansionBucket returns the number of placeholders a slice of length n is expanded to
+// (the next power of two limited by max).
+func expansionBucket(n, max int) int {
+	bucket := 1
+	for bucket < n {
+		bucket <<= 1
+	}
+	if bucket > max {
+		return max
+	}
+	return bucket
+}
+
+type scanToken struct {
+	token      scanner.Token
+	start, end int
+}
+
+func (t scanToken) is(token scanner.Token, query, value string) bool {
+	return t.token == token && strings.EqualFold(query[t.start:t.end], value)
+}
+
+/*
+expandSlices expands the placeholders of slice arguments in IN clauses of query
+and returns the expanded query and the expanded arguments.
+Queries using named parameters are rewritten to positional parameters first.
+*/
+func expandSlices(sc *scanner.Scanner, query string, args []driver.NamedValue, max int) (string, []driver.NamedValue, error) {
+	qd, err := p.NewQueryDescr(query, sc)
+	if err != nil {
+		return "", nil, err
+	}
+	if qd.IsBulk() {
+		return "", nil, fmt.Errorf("%w: bulk statement", ErrSliceExpansion)
+	}
+	query = qd.Query()
+	if args, err = namedParams(qd.NamedParams()).bind(args); err != nil {
+		return "", nil, err
+	}
+
+	var tokens []scanToken
+	sc.Reset(query)
+	for {
+		token, start, end := sc.Next()
+		if token == scanner.EOS {
+			break
+		}
+		tokens = append(tokens, scanToken{token: token, start: start, end: end})
+	}
+
+	var (
+		b        strings.Builder
+		last     int
+		argIdx   int
+		expanded = make([]driver.NamedValue, 0, len(args))
+	)
+
+	addArg := func(v driver.Value) {
+		expanded = append(expanded, driver.NamedValue{Ordinal: len(expanded) + 1, Value: v})
+	}
+
+	for i, t := range tokens {
+		if t.token != scanner.Variable {
+			continue
+		}
+		if argIdx >= len(args) {
+			return "", nil, fmt.Errorf("invalid number of arguments %d - more placeholders expected", len(args))
+		}
+		arg := args[argIdx]
+		argIdx++
+
+		if !isSliceArg(arg.Value) {
+			addArg(arg.Value)
+			continue
+		}
+
+		if i < 2 || i+1 >= len(tokens) ||
+			!tokens[i-2].is(scanner.Identifier, query, "in") ||
+			!tokens[i-1].is(scanner.Delimiter, query, "(") ||
+			!tokens[i+1].is(scanner.Delimiter, query, ")") {
+			return "", nil, fmt.Errorf("%w: slice argument %d is not bound to an IN (?) clause", ErrSliceExpansion, argIdx)
+		}
+
+		rv := reflect.ValueOf(arg.Value)
+		n := rv.Len()
+		switch {
+		case n == 0:
+			return "", nil, fmt.Errorf("%w: empty slice argument %d", ErrSliceExpansion, argIdx)
+		case n > max:
+			return "", nil, fmt.Errorf("%w: length %d of slice argument %d exceeds maximum %d", ErrSliceExpansion, n, argIdx, max)
+		}
+
+		bucket := expansionBucket(n, max)
+		for j := 0; j < bucket; j++ {
+			if j < n {
+				addArg(rv.Index(j).Interface())
+			} else {
+				addArg(rv.Index(n - 1).Interface()) // pad with last element
+			}
+		}
+		b.WriteString(query[last:t.start])
+		b.WriteString(strings.Repeat("?, ", bucket-1))
+		b.WriteByte('?')
+		last = t.end
+	}
+	if argIdx != len(args) {
+		return "", nil, fmt.Errorf("invalid number of arguments %d - %d expected", len(args), argIdx)
+	}
+	b.WriteString(query[last:])
+	return b.String(), expanded, nil
+}
+
+// prepareExpanded expands the slice arguments, prepares the expanded query and
+// converts the expanded arguments.
+func (c *conn) prepareExpanded(ctx context.Context, query string, args []driver.NamedValue) (*stmt, []driver.NamedValue, error) {
+	c.session.Lock()
+	query, args, err := expandSlices(c.scanner, query, args, c.maxSliceExpansion)
+	c.session.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ds, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := ds.(*stmt)
+	for i := range args {
+		if err := s.CheckNamedValue(&args[i]); err != nil {
+			s.Close()
+			return nil, nil, err
+		}
+	}
+	return s, args, nil
+}
+
+// queryExpanded executes a query with slice arguments. The prepared statement is dropped when the rows are closed.
+func (c *conn) queryExpanded(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, args, err := c.prepareExpanded(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.QueryContext(ctx, args)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return newRows(rows, func(int64) { s.Close() }), nil
+}
+
+// execExpanded executes a statement with slice arguments.
+func (c *conn) execExpanded(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s, args, err := c.prepareExpanded(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.ExecContext(ctx, args)
+}
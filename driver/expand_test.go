@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+func testExpandArgs(values ...driver.Value) []driver.NamedValue {
+	args := make([]driver.NamedValue, len(values))
+	for i, v := range values {
+		args[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return args
+}
+
+func TestExpandSlices(t *testing.T) {
+	tests := []struct {
+		query  string
+		args   []driver.NamedValue
+		result string
+		values []driver.Value
+	}{
+		{
+			"select * from t where a in (?)",
+			testExpandArgs([]int{1, 2, 3}),
+			"select * from t where a in (?, ?, ?, ?)",
+			[]driver.Value{1, 2, 3, 3},
+		},
+		{
+			"select * from t where a = ? and b IN ( ? ) and c = ?",
+			testExpandArgs("x", []string{"a"}, []byte("b")),
+			"select * from t where a = ? and b IN ( ? ) and c = ?",
+			[]driver.Value{"x", "a", []byte("b")},
+		},
+		{
+			"select * from t where a in (:a) or b in (:a)",
+			[]driver.NamedValue{{Name: "a", Ordinal: 1, Value: []int64{7, 8}}},
+			"select * from t where a in (?, ?) or b in (?, ?)",
+			[]driver.Value{int64(7), int64(8), int64(7), int64(8)},
+		},
+		{
+			"select * from t where a in (?)",
+			testExpandArgs([]int{1, 2, 3, 4, 5}),
+			"select * from t where a in (?, ?, ?, ?, ?, ?)", // bucket limited by max
+			[]driver.Value{1, 2, 3, 4, 5, 5},
+		},
+	}
+
+	sc := &scanner.Scanner{}
+	for _, test := range tests {
+		query, args, err := expandSlices(sc, test.query, test.args, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != test.result {
+			t.Fatalf("query %s - expected %s", query, test.result)
+		}
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			if arg.Ordinal != i+1 {
+				t.Fatalf("argument %d ordinal %d - expected %d", i, arg.Ordinal, i+1)
+			}
+			values[i] = arg.Value
+		}
+		if !reflect.DeepEqual(values, test.values) {
+			t.Fatalf("values %v - expected %v", values, test.values)
+		}
+	}
+
+	for _, test := range []struct {
+		query string
+		args  []driver.NamedValue
+	}{
+		{"select * from t where a = ?", testExpandArgs([]int{1})},                      // not in IN clause
+		{"select * from t where a in (?, ?)", testExpandArgs([]int{1}, 2)},             // not single placeholder
+		{"select * from t where a in (?)", testExpandArgs([]int{})},                    // empty
+		{"select * from t where a in (?)", testExpandArgs([]int{1, 2, 3, 4, 5, 6, 7})}, // exceeds maximum
+		{"bulk insert into t values (?)", testExpandArgs([]int{1})},                    // bulk
+	} {
+		if _, _, err := expandSlices(sc, test.query, test.args, 6); !errors.Is(err, ErrSliceExpansion) {
+			t.Fatalf("query %s: error %v - expected %v", test.query, err, ErrSliceExpansion)
+		}
+	}
+}
+
+func TestExpansionBucket(t *testing.T) {
+	tests := []struct{ n, max, bucket int }{
+		{1, 1024, 1}, {2, 1024, 2}, {3, 1024, 4}, {5, 1024, 8}, {600, 1024, 1024}, {1000, 1000, 1000},
+	}
+	for _, test := range tests {
+		if bucket := expansionBucket(test.n, test.max); bucket != test.bucket {
+			t.Fatalf("bucket %d of %d - expected %d", bucket, test.n, test.bucket)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// explainStatementName returns a unique statement name for the explain plan table.
+var explainStatementName = func() string { return string(RandomIdentifier("goHdbExplain_")) }
+
+// A PlanOperator is an operator of a query execution plan.
+type PlanOperator struct {
+	ID          int
+	Name        string // operator name (e.g. COLUMN SEARCH)
+	Details     string
+	Engine      string // execution engine
+	Schema      string
+	Table       string
+	TableType   string
+	TableSize   sql.NullFloat64 // estimated table size
+	OutputSize  sql.NullFloat64 // estimated output size
+	SubtreeCost sql.NullFloat64 // estimated cost of the operator including its children
+	Level       int
+	Position    int
+	Children    []*PlanOperator
+}
+
+// A Plan is a query execution plan.
+type Plan struct {
+	Query string
+	Roots []*PlanOperator // root operators (usually one)
+}
+
+func (p *Plan) String() string {
+	b := new(strings.Builder)
+	var write func(op *PlanOperator, indent int)
+	write = func(op *PlanOperator, indent int) {
+		fmt.Fprintf(b, "%s%s", strings.Repeat("  ", indent), op.Name)
+		if op.Table != "" {
+			fmt.Fprintf(b, " %s", QualifiedName(Identifier(op.Schema), Identifier(op.Table)))
+		}
+		if op.Details != "" {
+			fmt.Fprintf(b, " (%s)", op.Details)
+		}
+		if op.OutputSize.Valid {
+			fmt.Fprintf(b, " output size %g", op.OutputSize.Float64)
+		}
+		if op.SubtreeCost.Valid {
+			fmt.Fprintf(b, " cost %g", op.SubtreeCost.Float64)
+		}
+		b.WriteByte('\n')
+		for _, child := range op.Children {
+			write(child, indent+1)
+		}
+	}
+	for _, root := range p.Roots {
+		write(root, 0)
+	}
+	return b.String()
+}
+
+const (
+	explainPlanStmt   = "explain plan set statement_name = %s for %s"
+	explainPlanQuery  = "select operator_id, parent_operator_id, operator_name, ifnull(operator_details, ''), ifnull(execution_engine, ''), ifnull(schema_name, ''), ifnull(table_name, ''), ifnull(table_type, ''), table_size, output_size, subtree_cost, level, position from explain_plan_table where statement_name = ? order by level, position"
+	explainPlanDelete = "delete from explain_plan_table where statement_name = ?"
+)
+
+/*
+ExplainPlan returns the execution plan of query computed by the database (EXPLAIN PLAN).
+
+The plan is written to the explain plan table under a generated statement name, read and
+removed from the table afterwards. The arguments args are bound to the parameters of query if provided.
+*/
+func ExplainPlan(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*Plan, error) {
+	conn, err := db.Conn(ctx) // explain plan and plan table access in same session
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	name := explainStatementName()
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(explainPlanStmt, StringLiteral(name), query), args...); err != nil {
+		return nil, err
+	}
+	plan, err := readPlan(ctx, conn, name, query)
+	if _, delErr := conn.ExecContext(ctx, explainPlanDelete, name); err == nil {
+		err = delErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func readPlan(ctx context.Context, conn *sql.Conn, name, query string) (*Plan, error) {
+	rows, err := conn.QueryContext(ctx, explainPlanQuery, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type node struct {
+		op       *PlanOperator
+		parentID sql.NullInt64
+	}
+	var nodes []node
+	ops := map[int]*PlanOperator{}
+	for rows.Next() {
+		op := &PlanOperator{}
+		var parentID sql.NullInt64
+		if err := rows.Scan(&op.ID, &parentID, &op.Name, &op.Details, &op.Engine, &op.Schema, &op.Table, &op.TableType, &op.TableSize, &op.OutputSize, &op.SubtreeCost, &op.Level, &op.Position); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node{op: op, parentID: parentID})
+		ops[op.ID] = op
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Query: query}
+	for _, n := range nodes { // ordered by level and position
+		parent, ok := ops[int(n.parentID.Int64)]
+		if !n.parentID.Valid || !ok {
+			plan.Roots = append(plan.Roots, n.op)
+			continue
+		}
+		parent.Children = append(parent.Children, n.op)
+	}
+	return plan, nil
+}
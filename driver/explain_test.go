@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestExplainPlan(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	defer func(f func() string) { explainStatementName = f }(explainStatementName)
+	explainStatementName = func() string { return "test" }
+
+	query := "select * from persons where id = ?"
+	s.Handle("explain plan set statement_name = 'test' for "+query, &drivertest.MockStatement{Params: []string{"INTEGER"}})
+
+	deleted := false
+	s.Handle(explainPlanDelete, &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		deleted = args[0] == "test"
+		return &drivertest.MockResult{RowsAffected: 2}, nil
+	}})
+
+	columns := []drivertest.MockColumn{
+		{Name: "OPERATOR_ID", TypeName: "INTEGER"}, {Name: "PARENT_OPERATOR_ID", TypeName: "INTEGER"},
+		{Name: "OPERATOR_NAME", TypeName: "NVARCHAR"}, {Name: "OPERATOR_DETAILS", TypeName: "NVARCHAR"},
+		{Name: "EXECUTION_ENGINE", TypeName: "NVARCHAR"}, {Name: "SCHEMA_NAME", TypeName: "NVARCHAR"},
+		{Name: "TABLE_NAME", TypeName: "NVARCHAR"}, {Name: "TABLE_TYPE", TypeName: "NVARCHAR"},
+		{Name: "TABLE_SIZE", TypeName: "DOUBLE"}, {Name: "OUTPUT_SIZE", TypeName: "DOUBLE"}, {Name: "SUBTREE_COST", TypeName: "DOUBLE"},
+		{Name: "LEVEL", TypeName: "INTEGER"}, {Name: "POSITION", TypeName: "INTEGER"},
+	}
+	s.Handle(explainPlanQuery, &drivertest.MockStatement{
+		Columns: columns,
+		Rows: [][]interface{}{
+			{int32(1), nil, "ROW SEARCH", "", "COLUMN", "", "", "", nil, float64(1), float64(0.5), int32(1), int32(1)},
+			{int32(2), int32(1), "COLUMN SEARCH", "PERSONS.ID = ?", "COLUMN", "S", "PERSONS", "COLUMN TABLE", float64(100), float64(1), float64(0.4), int32(2), int32(1)},
+		},
+	})
+
+	db := sql.OpenDB(NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	plan, err := ExplainPlan(context.Background(), db, query, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Fatal("explain plan table entries not deleted")
+	}
+	if len(plan.Roots) != 1 {
+		t.Fatalf("number of root operators %d - expected %d", len(plan.Roots), 1)
+	}
+	root := plan.Roots[0]
+	if root.Name != "ROW SEARCH" || len(root.Children) != 1 {
+		t.Fatalf("root operator %s children %d - expected %s children %d", root.Name, len(root.Children), "ROW SEARCH", 1)
+	}
+	child := root.Children[0]
+	if child.Table != "PERSONS" || !child.TableSize.Valid || child.TableSize.Float64 != 100 {
+		t.Fatalf("child operator table %s size %v - expected %s size %v", child.Table, child.TableSize, "PERSONS", 100)
+	}
+	if s := plan.String(); !strings.Contains(s, "\n  COLUMN SEARCH S.PERSONS (PERSONS.ID = ?)") {
+		t.Fatalf("unexpected plan\n%s", s)
+	}
+}
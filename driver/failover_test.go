@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockReprepare(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select id from t where id = ?", &drivertest.MockStatement{Params: []string{"INTEGER"}, Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}}})
+	s.Handle("update t set id = ?", &drivertest.MockStatement{Params: []string{"INTEGER"}, RowsAffected: 1})
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	queryStmt, err := db.Prepare("select id from t where id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queryStmt.Close()
+	execStmt, err := db.Prepare("update t set id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer execStmt.Close()
+
+	for i := 0; i < 2; i++ {
+		var id int
+		if err := queryStmt.QueryRow(1).Scan(&id); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if _, err := execStmt.Exec(1); err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		s.InvalidateStatements() // e.g. failover
+	}
+}
+
+func TestMockFailover(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	// unreachable host
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	downHost := ln.Addr().String()
+	ln.Close()
+
+	ctx := context.Background()
+
+	connector, err := driver.NewDSNConnector(fmt.Sprintf("hdb://user:password@%s,%s", downHost, s.Host()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hosts := connector.Hosts(); !reflect.DeepEqual(hosts, []string{downHost, s.Host()}) {
+		t.Fatalf("hosts %v - expected %v", hosts, []string{downHost, s.Host()})
+	}
+	hostHealth := driver.NewHostHealth()
+	if err := connector.SetHostHealth(hostHealth); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if score := hostHealth.Score(downHost); score >= 1 {
+		t.Fatalf("score %f of unreachable host - expected unhealthy host", score)
+	}
+	// failed host is tried last
+	conn, err = connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if snapshot := hostHealth.Snapshot(); len(snapshot) != 2 || snapshot[1].Host != downHost || snapshot[1].NumFailure != 1 {
+		t.Fatalf("host health %v - expected a single failure of host %s", snapshot, downHost)
+	}
+
+	// no failover in case of database errors
+	s2 := drivertest.NewTestMockServer(t)
+	defer s2.Close()
+	s2.SetCredentials("user", "other")
+
+	connector = driver.NewBasicAuthFailoverConnector([]string{s2.Host(), s.Host()}, "user", "password")
+	if _, err := connector.NativeConn(ctx); err == nil {
+		t.Fatal("expected authentication error")
+	}
+
+	// no reachable host
+	connector = driver.NewBasicAuthFailoverConnector([]string{downHost}, "user", "password")
+	if err := connector.SetFailoverHosts([]string{downHost}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := connector.NativeConn(ctx); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if err := connector.SetFailoverHosts([]string{"invalid"}); err == nil {
+		t.Fatal("expected invalid host error")
+	}
+}
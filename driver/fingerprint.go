@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+var scannerPool = sync.Pool{New: func() interface{} { return &scanner.Scanner{} }}
+
+/*
+NormalizeQuery returns the normalized representation of the sql statement query, where
+  - literals (strings and numbers) are replaced by the question mark placeholder,
+  - unquoted identifiers are converted to lower case,
+  - comments are removed and
+  - whitespaces are collapsed.
+
+Statements differing only in literal values, case or formatting are normalized to the same
+string (e.g. "SELECT * FROM t WHERE id = 42" and "select *  from T where id = ?" are normalized to
+"select * from t where id = ?"). The normalization is the one used for the statement metrics (see StmtMetrics).
+*/
+func NormalizeQuery(query string) string {
+	sc := scannerPool.Get().(*scanner.Scanner)
+	defer scannerPool.Put(sc)
+	return sc.Normalize(query)
+}
+
+// QueryFingerprint returns a stable fingerprint (16 hex digits) of the normalized sql statement query
+// (see NormalizeQuery), e.g. to aggregate metrics of statements differing only in literal values.
+func QueryFingerprint(query string) string { return fingerprint(NormalizeQuery(query)) }
+
+// fingerprint returns the fingerprint of the normalized sql statement stmt.
+func fingerprint(stmt string) string {
+	h := fnv.New64a()
+	h.Write([]byte(stmt))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
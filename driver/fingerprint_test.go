@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"testing"
+)
+
+func TestQueryFingerprint(t *testing.T) {
+	tests := []struct {
+		query, normalized string
+	}{
+		{"SELECT * FROM t WHERE id = 42", "select * from t where id = ?"},
+		{"select *\n  from T  where id = ? -- comment", "select * from t where id = ?"},
+		{`select "Name" from t where name = 'Alice' /* block */`, `select "Name" from t where name = ?`},
+		{"insert into t values (1, 'a', 1.5e3)", "insert into t values (?, ?, ?)"},
+	}
+	for _, test := range tests {
+		if normalized := NormalizeQuery(test.query); normalized != test.normalized {
+			t.Fatalf("normalized query %s - expected %s", normalized, test.normalized)
+		}
+	}
+
+	fp := QueryFingerprint(tests[0].query)
+	if len(fp) != 16 {
+		t.Fatalf("fingerprint length %d - expected %d", len(fp), 16)
+	}
+	if fp2 := QueryFingerprint(tests[1].query); fp2 != fp {
+		t.Fatalf("fingerprint %s - expected %s", fp2, fp)
+	}
+	if fp2 := QueryFingerprint(tests[2].query); fp2 == fp {
+		t.Fatalf("fingerprint %s - expected different fingerprint", fp2)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"math"
+	"math/big"
+)
+
+/*
+Fixed8 is a fixed-point decimal value with a 64 bit unscaled value: the value is Unscaled * 10^-Scale.
+
+Contrary to Decimal, values are bound and scanned without big.Rat allocations. Fixed8 covers the
+values of SMALLDECIMAL (16 digits) and of DECIMAL(p, s) fields with a precision p <= 18.
+Scan keeps the scale of the receiver at minimum, so that a Fixed8 value initialized with the scale s
+of a DECIMAL(p, s) field is scanned with scale s. Values exceeding the range of Fixed8 are reported
+by ErrDecimalOutOfRange.
+*/
+type Fixed8 struct {
+	Unscaled int64
+	Scale    int
+}
+
+// Rat returns the value of f as big.Rat.
+func (f Fixed8) Rat() *big.Rat { return fixedRat(big.NewInt(f.Unscaled), f.Scale) }
+
+// Value implements the database/sql/Valuer interface.
+func (f Fixed8) Value() (driver.Value, error) {
+	if err := checkFixedScale(f.Scale); err != nil {
+		return nil, err
+	}
+	neg := f.Unscaled < 0
+	m := uint64(f.Unscaled)
+	if neg {
+		m = -m // two's complement (covers math.MinInt64)
+	}
+	return encodeDecimal64(m, neg, -f.Scale), nil
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (f *Fixed8) Scan(src interface{}) error {
+	b, err := decimalBytes(src)
+	if err != nil {
+		return err
+	}
+	m, neg, exp, ok := decodeDecimal64(b)
+	if !ok {
+		return ErrDecimalOutOfRange
+	}
+	scale := max(f.Scale, -exp)
+	for i := 0; i < exp+scale; i++ {
+		if m > math.MaxUint64/10 {
+			return ErrDecimalOutOfRange
+		}
+		m *= 10
+	}
+	switch {
+	case neg && m <= 1<<63:
+		f.Unscaled = -int64(m)
+	case !neg && m <= math.MaxInt64:
+		f.Unscaled = int64(m)
+	default:
+		return ErrDecimalOutOfRange
+	}
+	f.Scale = scale
+	return nil
+}
+
+/*
+Fixed12 is a fixed-point decimal value with a 96 bit unscaled value: the value is Unscaled * 10^-Scale
+with the unscaled value Hi * 2^64 + Lo (two's complement).
+
+Fixed12 covers the values of DECIMAL(p, s) fields with a precision p <= 28. Like for Fixed8, Scan keeps
+the scale of the receiver at minimum.
+*/
+type Fixed12 struct {
+	Hi    int32  // high 32 bits of the unscaled value
+	Lo    uint64 // low 64 bits of the unscaled value
+	Scale int
+}
+
+// NewFixed12 returns the Fixed12 value unscaled * 10^-scale.
+func NewFixed12(unscaled int64, scale int) Fixed12 {
+	f := Fixed12{Lo: uint64(unscaled), Scale: scale}
+	if unscaled < 0 {
+		f.Hi = -1
+	}
+	return f
+}
+
+var (
+	fixed12Max  = new(big.Int).Lsh(natOne, 95) // range: -2^95 <= unscaled < 2^95
+	fixed12Mod  = new(big.Int).Lsh(natOne, 96)
+	fixed64Mask = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// Unscaled returns the unscaled value of f.
+func (f Fixed12) Unscaled() *big.Int {
+	m := big.NewInt(int64(f.Hi))
+	m.Lsh(m, 64)
+	return m.Add(m, new(big.Int).SetUint64(f.Lo))
+}
+
+// Rat returns the value of f as big.Rat.
+func (f Fixed12) Rat() *big.Rat { return fixedRat(f.Unscaled(), f.Scale) }
+
+// Value implements the database/sql/Valuer interface.
+func (f Fixed12) Value() (driver.Value, error) {
+	if (f.Hi == 0 && f.Lo <= math.MaxInt64) || (f.Hi == -1 && f.Lo > math.MaxInt64) { // fits into int64
+		return Fixed8{Unscaled: int64(f.Lo), Scale: f.Scale}.Value()
+	}
+	if err := checkFixedScale(f.Scale); err != nil {
+		return nil, err
+	}
+	m := f.Unscaled()
+	neg := m.Sign() < 0
+	return encodeDecimal(m.Abs(m), neg, -f.Scale)
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (f *Fixed12) Scan(src interface{}) error {
+	b, err := decimalBytes(src)
+	if err != nil {
+		return err
+	}
+	m := new(big.Int)
+	neg, exp := decodeDecimal(b, m)
+	scale := max(f.Scale, -exp)
+	if n := exp + scale; n > 0 {
+		m.Mul(m, exp10(n))
+	}
+	if c := m.Cmp(fixed12Max); c > 0 || (c == 0 && !neg) {
+		return ErrDecimalOutOfRange
+	}
+	if neg {
+		m.Sub(fixed12Mod, m) // two's complement
+	}
+	f.Lo = new(big.Int).And(m, fixed64Mask).Uint64()
+	f.Hi = int32(uint32(m.Rsh(m, 64).Uint64()))
+	f.Scale = scale
+	return nil
+}
+
+// checkFixedScale checks if the exponent of scale is in the range of the database decimal format.
+func checkFixedScale(scale int) error {
+	if exp := -scale; exp < dec128MinExp || exp > dec128MaxExp {
+		return ErrDecimalOutOfRange
+	}
+	return nil
+}
+
+// fixedRat returns the value m * 10^-scale as big.Rat.
+func fixedRat(m *big.Int, scale int) *big.Rat {
+	r := new(big.Rat).SetInt(m)
+	switch {
+	case scale > 0:
+		r.Quo(r, new(big.Rat).SetInt(exp10(scale)))
+	case scale < 0:
+		r.Mul(r, new(big.Rat).SetInt(exp10(-scale)))
+	}
+	return r
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver/spatial"
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+/*
+A Geometry is the driver representation of a database spatial value (ST_GEOMETRY, ST_POINT) in well-known binary
+format (WKB). Geometry implements the Scanner and the Valuer interface, so that it can be used as a scan destination
+and as an argument of spatial columns and parameters. A nil Geometry represents the NULL value.
+
+For the conversion from and to the geometry types of package spatial and the WKT and GeoJSON representations
+see NewGeometry, Geometry.Geometry, Geometry.WKT and Geometry.GeoJSON.
+*/
+type Geometry []byte
+
+// NewGeometry returns the Geometry of the spatial geometry g.
+func NewGeometry(g spatial.Geometry) (Geometry, error) {
+	b, err := spatial.EncodeWKB(g)
+	if err != nil {
+		return nil, err
+	}
+	return Geometry(b), nil
+}
+
+// Geometry returns the spatial geometry of g.
+func (g Geometry) Geometry() (spatial.Geometry, error) { return spatial.DecodeWKB(g) }
+
+// WKT returns the well-known text representation of g.
+func (g Geometry) WKT() (string, error) {
+	sg, err := g.Geometry()
+	if err != nil {
+		return "", err
+	}
+	return spatial.EncodeWKT(sg)
+}
+
+// GeoJSON returns the GeoJSON geometry object of g.
+func (g Geometry) GeoJSON() ([]byte, error) {
+	sg, err := g.Geometry()
+	if err != nil {
+		return nil, err
+	}
+	return spatial.EncodeGeoJSON(sg)
+}
+
+// Scan implements the Scanner interface.
+// Besides database spatial values Scan accepts WKB as []byte and WKT as string.
+func (g *Geometry) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*g = nil
+	case p.WriterSetter:
+		b := new(bytes.Buffer)
+		if err := src.SetWriter(b); err != nil {
+			return err
+		}
+		*g = Geometry(b.Bytes())
+	case []byte:
+		*g = append((*g)[:0], src...)
+	case string:
+		sg, err := spatial.DecodeWKT(src)
+		if err != nil {
+			return err
+		}
+		if *g, err = NewGeometry(sg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("geometry: invalid scan type %T", src)
+	}
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (g Geometry) Value() (driver.Value, error) {
+	if g == nil {
+		return nil, nil
+	}
+	return []byte(g), nil
+}
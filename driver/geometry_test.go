@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/spatial"
+)
+
+// testWriterSetter simulates a database lob field.
+type testWriterSetter []byte
+
+func (ws testWriterSetter) SetWriter(w io.Writer) error {
+	_, err := w.Write(ws)
+	return err
+}
+
+func testGeometryScan(t *testing.T) {
+	polygon := spatial.Polygon{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}
+	wkb, err := spatial.EncodeWKB(polygon)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, src := range []interface{}{testWriterSetter(wkb), wkb, "POLYGON ((0 0, 1 0, 1 1, 0 0))"} {
+		var g Geometry
+		if err := g.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(g, wkb) {
+			t.Fatalf("scan %T: geometry %v - expected %v", src, g, wkb)
+		}
+		sg, err := g.Geometry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sg, polygon) {
+			t.Fatalf("scan %T: geometry %v - expected %v", src, sg, polygon)
+		}
+	}
+
+	g := Geometry(wkb)
+	if err := g.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if g != nil {
+		t.Fatalf("geometry %v - expected nil", g)
+	}
+	if err := g.Scan(42); err == nil {
+		t.Fatal("error expected for invalid scan type")
+	}
+}
+
+func testGeometryValue(t *testing.T) {
+	g, err := NewGeometry(spatial.Point{X: 8.64, Y: 49.29})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := g.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := v.([]byte); !ok || !bytes.Equal(b, g) {
+		t.Fatalf("value %v - expected %v", v, []byte(g))
+	}
+	wkt, err := g.WKT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wkt != "POINT (8.64 49.29)" {
+		t.Fatalf("wkt %s - expected POINT (8.64 49.29)", wkt)
+	}
+	geoJSON, err := g.GeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(geoJSON) != `{"type":"Point","coordinates":[8.64,49.29]}` {
+		t.Fatalf("geojson %s", geoJSON)
+	}
+
+	if v, err := Geometry(nil).Value(); v != nil || err != nil {
+		t.Fatalf("value %v error %v - expected nil", v, err)
+	}
+}
+
+func TestGeometry(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"scan", testGeometryScan},
+		{"value", testGeometryValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
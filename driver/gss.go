@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+/*
+GSSProvider is the client side of a GSS-API security context used for the Kerberos authentication
+(see NewKerberosAuthConnector).
+
+InitSecContext returns the next token of the security context established for the service principal name spn.
+It is called with a nil input token for the initial token (Kerberos AP-REQ) and with the token returned by the
+database server afterwards (Kerberos AP-REP in case of mutual authentication). An empty output token ends the
+token exchange.
+
+The driver does not depend on a Kerberos implementation: the client principal and its credentials (keytab or
+credential cache) are managed by the provider. A provider based on github.com/jcmturner/gokrb5 is available
+as separate module github.com/SAP/go-hdb/kerberos.
+*/
+type GSSProvider = p.GSSProvider
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+// mockGSSProvider is a GSS provider returning fixed tokens.
+type mockGSSProvider struct {
+	mu          sync.Mutex
+	spn         string
+	serverToken []byte
+}
+
+var (
+	mockGSSClientToken = []byte(strings.Repeat("AP-REQ", 200)) // exceeds short authentication field size
+	mockGSSServerToken = []byte("AP-REP")
+)
+
+func (p *mockGSSProvider) InitSecContext(spn string, token []byte) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.spn = spn
+	if token == nil {
+		return mockGSSClientToken, nil
+	}
+	p.serverToken = token
+	return nil, nil
+}
+
+func TestMockKerberos(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.SetGSSAcceptor(func(token []byte) (string, []byte, error) {
+		if !reflect.DeepEqual(token, mockGSSClientToken) {
+			return "", nil, errors.New("invalid ticket")
+		}
+		return "KRBUSER", mockGSSServerToken, nil
+	})
+
+	ctx := context.Background()
+
+	provider := &mockGSSProvider{}
+	connector := driver.NewKerberosAuthConnector(s.Host(), provider)
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	host, _, _ := net.SplitHostPort(s.Host())
+	provider.mu.Lock()
+	spn, serverToken := provider.spn, provider.serverToken
+	provider.mu.Unlock()
+	if spn != "hdb/"+host {
+		t.Fatalf("service principal %s - expected %s", spn, "hdb/"+host)
+	}
+	if !reflect.DeepEqual(serverToken, mockGSSServerToken) {
+		t.Fatalf("server token %s - expected %s", serverToken, mockGSSServerToken)
+	}
+
+	if err := connector.SetServicePrincipal("hdb/mock@EXAMPLE.COM"); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	provider.mu.Lock()
+	spn = provider.spn
+	provider.mu.Unlock()
+	if spn != "hdb/mock@EXAMPLE.COM" {
+		t.Fatalf("service principal %s - expected %s", spn, "hdb/mock@EXAMPLE.COM")
+	}
+
+	// rejected ticket
+	s.SetGSSAcceptor(func(token []byte) (string, []byte, error) { return "", nil, errors.New("invalid ticket") })
+	if _, err := connector.NativeConn(ctx); err == nil {
+		t.Fatal("expected authentication error")
+	}
+
+	// basic authentication is not affected
+	conn, err = driver.NewBasicAuthConnector(s.Host(), "user", "password").NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package hdbsql provides the sql statement analysis of the driver as a stable api, so that frameworks
+can e.g. route statements (read replica versus primary) and categorize traces consistently with the driver.
+*/
+package hdbsql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// Kind is the statement kind.
+type Kind int
+
+// Statement kinds.
+const (
+	KindUnknown     Kind = iota
+	KindQuery            // select, with
+	KindDML              // insert, update, upsert, replace, delete, merge
+	KindDDL              // create, drop, alter, rename, comment, truncate, grant, revoke
+	KindCall             // call, do (anonymous block)
+	KindTransaction      // commit, rollback, savepoint, release, lock
+	KindSession          // set, unset, connect
+)
+
+var kindNames = map[Kind]string{
+	KindUnknown:     "unknown",
+	KindQuery:       "query",
+	KindDML:         "dml",
+	KindDDL:         "ddl",
+	KindCall:        "call",
+	KindTransaction: "transaction",
+	KindSession:     "session",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+var keywordKinds = map[string]Kind{
+	"select":    KindQuery,
+	"with":      KindQuery,
+	"insert":    KindDML,
+	"update":    KindDML,
+	"upsert":    KindDML,
+	"replace":   KindDML,
+	"delete":    KindDML,
+	"merge":     KindDML,
+	"create":    KindDDL,
+	"drop":      KindDDL,
+	"alter":     KindDDL,
+	"rename":    KindDDL,
+	"comment":   KindDDL,
+	"truncate":  KindDDL,
+	"grant":     KindDDL,
+	"revoke":    KindDDL,
+	"call":      KindCall,
+	"do":        KindCall,
+	"commit":    KindTransaction,
+	"rollback":  KindTransaction,
+	"savepoint": KindTransaction,
+	"release":   KindTransaction,
+	"lock":      KindTransaction,
+	"set":       KindSession,
+	"unset":     KindSession,
+	"connect":   KindSession,
+}
+
+const bulkKeyword = "bulk"
+
+// ErrNoCommand is returned by Classify for statements not starting with a command keyword.
+var ErrNoCommand = errors.New("hdbsql: no command keyword")
+
+// A Class is the classification of a sql statement.
+type Class struct {
+	Kind      Kind
+	Keyword   string // lower case command keyword (e.g. select)
+	Bulk      bool   // driver bulk statement (bulk prefix)
+	ForUpdate bool   // query locking the selected rows (for update, for share lock)
+}
+
+// ReadOnly returns true if the statement does not modify data and does not lock rows,
+// so that it could be executed on a read-only replica.
+func (c Class) ReadOnly() bool { return c.Kind == KindQuery && !c.ForUpdate }
+
+func (c Class) String() string {
+	return fmt.Sprintf("kind: %s keyword: %s bulk: %t forUpdate: %t", c.Kind, c.Keyword, c.Bulk, c.ForUpdate)
+}
+
+// Classify returns the classification of query. Leading comments and opening parentheses are ignored.
+// Statements starting with an unknown keyword are of kind KindUnknown.
+func Classify(query string) (Class, error) {
+	var sc scanner.Scanner
+	sc.Reset(query)
+
+	c := Class{}
+
+	token, start, end := sc.Next()
+	for token == scanner.Delimiter && query[start:end] == "(" {
+		token, start, end = sc.Next()
+	}
+	if token != scanner.Identifier {
+		return c, ErrNoCommand
+	}
+	keyword := strings.ToLower(query[start:end])
+	if keyword == bulkKeyword {
+		c.Bulk = true
+		if token, start, end = sc.Next(); token != scanner.Identifier {
+			return c, ErrNoCommand
+		}
+		keyword = strings.ToLower(query[start:end])
+	}
+	c.Keyword = keyword
+	c.Kind = keywordKinds[keyword]
+
+	if c.Kind != KindQuery {
+		return c, nil
+	}
+
+	prevFor := false
+	for token, start, end = sc.Next(); token != scanner.EOS; token, start, end = sc.Next() {
+		if token != scanner.Identifier {
+			prevFor = false
+			continue
+		}
+		word := strings.ToLower(query[start:end])
+		if prevFor && (word == "update" || word == "share") {
+			c.ForUpdate = true
+			break
+		}
+		prevFor = word == "for"
+	}
+	return c, nil
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbsql
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		query    string
+		class    Class
+		readOnly bool
+	}{
+		{"select * from dummy", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"  SELECT * FROM dummy", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"-- comment\n/* block */ select 1 from dummy", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"((select 1 from dummy) union (select 2 from dummy))", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"with t as (select 1 a from dummy) select * from t", Class{Kind: KindQuery, Keyword: "with"}, true},
+		{"select * from t where id = ? for update", Class{Kind: KindQuery, Keyword: "select", ForUpdate: true}, false},
+		{"select * from t for share lock", Class{Kind: KindQuery, Keyword: "select", ForUpdate: true}, false},
+		{"select 'for update' from dummy", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"select \"for\", update from t", Class{Kind: KindQuery, Keyword: "select"}, true},
+		{"insert into t values (?)", Class{Kind: KindDML, Keyword: "insert"}, false},
+		{"bulk insert into t values (?)", Class{Kind: KindDML, Keyword: "insert", Bulk: true}, false},
+		{"delete from t", Class{Kind: KindDML, Keyword: "delete"}, false},
+		{"upsert t values (1) with primary key", Class{Kind: KindDML, Keyword: "upsert"}, false},
+		{"create column table t (i integer)", Class{Kind: KindDDL, Keyword: "create"}, false},
+		{"truncate table t", Class{Kind: KindDDL, Keyword: "truncate"}, false},
+		{"call proc(?)", Class{Kind: KindCall, Keyword: "call"}, false},
+		{"do begin select 1 from dummy; end", Class{Kind: KindCall, Keyword: "do"}, false},
+		{"commit", Class{Kind: KindTransaction, Keyword: "commit"}, false},
+		{"set schema s", Class{Kind: KindSession, Keyword: "set"}, false},
+		{"explain plan for select * from dummy", Class{Kind: KindUnknown, Keyword: "explain"}, false},
+	}
+
+	for _, test := range tests {
+		class, err := Classify(test.query)
+		if err != nil {
+			t.Fatalf("query %s: %s", test.query, err)
+		}
+		if class != test.class {
+			t.Fatalf("query %s: class %s - expected %s", test.query, class, test.class)
+		}
+		if class.ReadOnly() != test.readOnly {
+			t.Fatalf("query %s: read only %t - expected %t", test.query, class.ReadOnly(), test.readOnly)
+		}
+	}
+
+	for _, query := range []string{"", "  ", "-- comment", "? = call", "'select'", "bulk"} {
+		if _, err := Classify(query); err != ErrNoCommand {
+			t.Fatalf("query %q: error %v - expected %v", query, err, ErrNoCommand)
+		}
+	}
+}
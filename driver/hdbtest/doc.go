@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package hdbtest provides an in-memory fake sql driver for application tests without a database.
+
+The driver is registered with name hdbtest. The data source name is the name of the in-memory database:
+all connections opened with the same name share the database content.
+
+	db, err := sql.Open(hdbtest.DriverName, "testdb")
+
+The driver supports a subset of HANA sql statements:
+
+	CREATE [COLUMN|ROW] TABLE <name> (<column> <type>[(<length>[, <scale>])] [NOT NULL] [PRIMARY KEY], ...)
+	DROP TABLE <name>
+	TRUNCATE TABLE <name>
+	INSERT INTO <name> [(<column>, ...)] VALUES (<expr>, ...)
+	SELECT * | COUNT(*) | <column>, ... FROM <name> [WHERE <cond> [AND <cond> ...]] [ORDER BY <column> [ASC|DESC]]
+	UPDATE <name> SET <column> = <expr>, ... [WHERE <cond> [AND <cond> ...]]
+	DELETE FROM <name> [WHERE <cond> [AND <cond> ...]]
+
+where <expr> is a parameter (?), a number, a string literal, NULL, TRUE or FALSE and <cond> is a comparison
+(=, <>, !=, <, <=, >, >=) of a column with an expression or a IS [NOT] NULL test.
+
+Values are converted with the type semantics of the go-hdb driver, so that go-hdb specific types like
+driver.Decimal, driver.Lob and driver.NullLob can be used as arguments and scan destinations.
+Bulk statements (bulk prefix, driver.NoFlush and driver.Flush) are buffered until flushed like in the go-hdb driver.
+
+Transactions are supported by a rollback snapshot of the database. Transactions are not isolated from each other and
+ddl statements (create, drop and truncate) commit the current transaction.
+Database errors implement the driver.Error interface using the corresponding HANA error codes.
+*/
+package hdbtest
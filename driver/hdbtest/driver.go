@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	hdb "github.com/SAP/go-hdb/driver"
+	p "github.com/SAP/go-hdb/internal/protocol"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// DriverName is the driver name to use with sql.Open.
+const DriverName = "hdbtest"
+
+var drv = &hdbTestDrv{}
+
+func init() {
+	sql.Register(DriverName, drv)
+}
+
+// check if driver implements all required interfaces
+var (
+	_ driver.Driver                         = (*hdbTestDrv)(nil)
+	_ driver.DriverContext                  = (*hdbTestDrv)(nil)
+	_ driver.Conn                           = (*conn)(nil)
+	_ driver.ConnPrepareContext             = (*conn)(nil)
+	_ driver.ConnBeginTx                    = (*conn)(nil)
+	_ driver.Stmt                           = (*stmt)(nil)
+	_ driver.StmtExecContext                = (*stmt)(nil)
+	_ driver.StmtQueryContext               = (*stmt)(nil)
+	_ driver.NamedValueChecker              = (*stmt)(nil)
+	_ driver.Rows                           = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+)
+
+type hdbTestDrv struct{}
+
+func (d *hdbTestDrv) Open(dsn string) (driver.Conn, error) {
+	return NewConnector(dsn).Connect(context.Background())
+}
+
+func (d *hdbTestDrv) OpenConnector(dsn string) (driver.Connector, error) {
+	return NewConnector(dsn), nil
+}
+
+// Connector implements the database/sql/driver/Connector interface for in-memory databases.
+type Connector struct {
+	name string
+}
+
+// NewConnector returns a new Connector instance for the in-memory database name.
+// All connections to the same database name share the database content.
+func NewConnector(name string) *Connector { return &Connector{name: name} }
+
+// Connect implements the database/sql/driver/Connector interface.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{db: lookupDatabase(c.name)}, nil
+}
+
+// Driver implements the database/sql/driver/Connector interface.
+func (c *Connector) Driver() driver.Driver { return drv }
+
+type conn struct {
+	db       *database
+	snapshot map[string]*table // transaction rollback snapshot
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var sc scanner.Scanner
+	qd, err := p.NewQueryDescr(query, &sc)
+	if err != nil {
+		return nil, newSyntaxError(query, 0)
+	}
+	cmd, err := parse(qd.Query())
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, query: qd.Query(), cmd: cmd, bulk: qd.IsBulk()}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.snapshot != nil {
+		return nil, errors.New("nested transactions are not supported")
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.snapshot = c.db.snapshot()
+	return c, nil
+}
+
+// Commit implements the driver.Tx interface.
+func (c *conn) Commit() error {
+	c.snapshot = nil
+	return nil
+}
+
+// Rollback implements the driver.Tx interface.
+func (c *conn) Rollback() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.snapshot != nil {
+		c.db.tables = c.snapshot
+	}
+	c.snapshot = nil
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+	cmd   *command
+
+	bulk, flush bool
+	bulkArgs    [][]driver.NamedValue
+}
+
+func (s *stmt) Close() error { return nil } // like the database, not flushed bulk records are discarded
+
+func (s *stmt) NumInput() int { return -1 } // bulk control statements do not have arguments
+
+// CheckNamedValue implements the NamedValueChecker interface.
+// Arguments are converted on execution according to the column types.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nv.Name == hdb.NoFlush.Name {
+		switch nv.Value {
+		case hdb.NoFlush.Value:
+			s.bulk = true
+			return driver.ErrRemoveArgument
+		case hdb.Flush.Value:
+			s.flush = true
+			return driver.ErrRemoveArgument
+		}
+	}
+	return nil
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	panic("deprecated")
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	panic("deprecated")
+}
+
+func (s *stmt) checkNumArg(numArg int) error {
+	if numArg != s.cmd.numPrm {
+		return fmt.Errorf("invalid number of arguments %d - %d expected", numArg, s.cmd.numPrm)
+	}
+	return nil
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer func() { s.flush = false }()
+
+	if !s.bulk {
+		if err := s.checkNumArg(len(args)); err != nil {
+			return nil, err
+		}
+		return s.exec([][]driver.NamedValue{args})
+	}
+
+	if len(args) != 0 {
+		if err := s.checkNumArg(len(args)); err != nil {
+			return nil, err
+		}
+		s.bulkArgs = append(s.bulkArgs, args)
+	}
+	if len(s.bulkArgs) != 0 && (s.flush || len(args) == 0 || len(s.bulkArgs) == hdb.DefaultBulkSize) {
+		bulkArgs := s.bulkArgs
+		s.bulkArgs = nil
+		return s.exec(bulkArgs)
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.cmd.kind != ckSelect {
+		return nil, fmt.Errorf("invalid query: %s", s.query)
+	}
+	if err := s.checkNumArg(len(args)); err != nil {
+		return nil, err
+	}
+
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.query(s.cmd, args)
+}
+
+func (s *stmt) exec(bulkArgs [][]driver.NamedValue) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var rowsAffected int64
+	for _, args := range bulkArgs {
+		n, err := db.exec(s.cmd, args)
+		if err != nil {
+			return nil, err
+		}
+		rowsAffected += n
+	}
+	if s.cmd.kind == ckCreateTable || s.cmd.kind == ckDropTable || s.cmd.kind == ckTruncateTable {
+		if s.conn.snapshot != nil { // ddl commits the transaction
+			s.conn.snapshot = db.snapshot()
+		}
+		return driver.ResultNoRows, nil
+	}
+	return driver.RowsAffected(rowsAffected), nil
+}
+
+type rows struct {
+	columns []*column
+	rows    [][]driver.Value
+	idx     int
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, c := range r.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.rows) {
+		return io.EOF
+	}
+	for i, c := range r.columns {
+		dest[i] = c.value(r.rows[r.idx][i])
+	}
+	r.idx++
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName implements the RowsColumnTypeDatabaseTypeName interface.
+func (r *rows) ColumnTypeDatabaseTypeName(idx int) string { return r.columns[idx].typeName }
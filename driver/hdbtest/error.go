@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// HANA error codes returned by the fake driver.
+const (
+	ErrCodeSyntax             = 257 // sql syntax error
+	ErrCodeInvalidTableName   = 259 // invalid table name
+	ErrCodeInvalidColumnName  = 260 // invalid column name
+	ErrCodeValueTooLarge      = 274 // inserted value too large for column
+	ErrCodeNotNull            = 287 // cannot insert NULL or update to NULL
+	ErrCodeDuplicateTableName = 288 // cannot use duplicate table name
+	ErrCodeUniqueConstraint   = 301 // unique constraint violated
+	ErrCodeInvalidValue       = 339 // invalid number / value
+)
+
+// Error is the database error returned by the fake driver. Error implements the driver.Error interface.
+type Error struct {
+	code     int
+	position int
+	text     string
+}
+
+var _ driver.Error = (*Error)(nil)
+
+func newError(code int, format string, args ...interface{}) *Error {
+	return &Error{code: code, text: fmt.Sprintf(format, args...)}
+}
+
+func newSyntaxError(query string, pos int) *Error {
+	text := "incorrect syntax near end of statement"
+	if pos < len(query) {
+		text = fmt.Sprintf("incorrect syntax near %q: line 1 col %d (at pos %d)", query[pos:], pos+1, pos)
+	}
+	return &Error{code: ErrCodeSyntax, position: pos, text: text}
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("SQL Error %d - %s", e.code, e.text) }
+
+// NumError implements the driver.Error interface.
+func (e *Error) NumError() int { return 1 }
+
+// SetIdx implements the driver.Error interface.
+func (e *Error) SetIdx(idx int) {}
+
+// StmtNo implements the driver.Error interface.
+func (e *Error) StmtNo() int { return 0 }
+
+// Code implements the driver.Error interface.
+func (e *Error) Code() int { return e.code }
+
+// Position implements the driver.Error interface.
+func (e *Error) Position() int { return e.position }
+
+// SQLState implements the driver.SQLStateError interface.
+func (e *Error) SQLState() string { return "HY000" }
+
+// Level implements the driver.Error interface.
+func (e *Error) Level() int { return driver.HdbError }
+
+// Text implements the driver.Error interface.
+func (e *Error) Text() string { return e.text }
+
+// IsWarning implements the driver.Error interface.
+func (e *Error) IsWarning() bool { return false }
+
+// IsError implements the driver.Error interface.
+func (e *Error) IsError() bool { return true }
+
+// IsFatal implements the driver.Error interface.
+func (e *Error) IsFatal() bool { return false }
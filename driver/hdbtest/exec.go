@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"database/sql/driver"
+	"math/big"
+
+	hdb "github.com/SAP/go-hdb/driver"
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// exprValue returns the value of expression e.
+func exprValue(c *column, e expr, args []driver.NamedValue) interface{} {
+	switch e.kind {
+	case ekParam:
+		return args[e.idx].Value
+	case ekValue:
+		if c.dataType == p.DtDecimal { // convert literal text exactly
+			if r, ok := new(big.Rat).SetString(e.text); ok {
+				return (*hdb.Decimal)(r)
+			}
+		}
+		return e.value
+	default:
+		return nil
+	}
+}
+
+func (db *database) bindConds(t *table, conds []cond, args []driver.NamedValue) ([]boundCond, error) {
+	bound := make([]boundCond, len(conds))
+	for i, c := range conds {
+		idx, err := t.columnIndex(c.column)
+		if err != nil {
+			return nil, err
+		}
+		bound[i] = boundCond{idx: idx, op: c.op}
+		if v := exprValue(t.columns[idx], c.expr, args); v != nil {
+			if bound[i].value, err = t.columns[idx].convert(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bound, nil
+}
+
+// exec executes a non query command and returns the number of affected rows.
+func (db *database) exec(cmd *command, args []driver.NamedValue) (int64, error) {
+	switch cmd.kind {
+	case ckCreateTable:
+		return 0, db.createTable(cmd)
+	case ckDropTable:
+		if _, err := db.table(cmd.table); err != nil {
+			return 0, err
+		}
+		delete(db.tables, cmd.table)
+		return 0, nil
+	}
+
+	t, err := db.table(cmd.table)
+	if err != nil {
+		return 0, err
+	}
+
+	switch cmd.kind {
+	case ckTruncateTable:
+		t.rows = nil
+		return 0, nil
+	case ckInsert:
+		return 1, db.insert(t, cmd, args)
+	case ckUpdate:
+		return db.update(t, cmd, args)
+	case ckDelete:
+		return db.delete(t, cmd, args)
+	default:
+		return 0, newError(ErrCodeSyntax, "statement does not return a result set")
+	}
+}
+
+func (db *database) createTable(cmd *command) error {
+	if _, ok := db.tables[cmd.table]; ok {
+		return newError(ErrCodeDuplicateTableName, "cannot use duplicate table name: %s", cmd.table)
+	}
+	t := &table{name: cmd.table}
+	for _, def := range cmd.defs {
+		c, err := newColumn(def)
+		if err != nil {
+			return err
+		}
+		t.columns = append(t.columns, c)
+	}
+	db.tables[cmd.table] = t
+	return nil
+}
+
+func (db *database) insert(t *table, cmd *command, args []driver.NamedValue) error {
+	idxs := make([]int, len(t.columns))
+	if cmd.columns == nil {
+		for i := range idxs {
+			idxs[i] = i
+		}
+	} else {
+		idxs = idxs[:len(cmd.columns)]
+		for i, name := range cmd.columns {
+			idx, err := t.columnIndex(name)
+			if err != nil {
+				return err
+			}
+			idxs[i] = idx
+		}
+	}
+	if len(cmd.values) != len(idxs) {
+		return newError(ErrCodeSyntax, "not enough values: %d - %d expected", len(cmd.values), len(idxs))
+	}
+
+	values := make([]interface{}, len(t.columns))
+	for i, e := range cmd.values {
+		values[idxs[i]] = exprValue(t.columns[idxs[i]], e, args)
+	}
+	row := make([]driver.Value, len(t.columns))
+	for i, c := range t.columns {
+		var err error
+		if row[i], err = c.convert(values[i]); err != nil {
+			return err
+		}
+	}
+	if err := t.checkUnique(row, -1); err != nil {
+		return err
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+func (db *database) update(t *table, cmd *command, args []driver.NamedValue) (int64, error) {
+	conds, err := db.bindConds(t, cmd.where, args)
+	if err != nil {
+		return 0, err
+	}
+	type setValue struct {
+		idx   int
+		value driver.Value
+	}
+	set := make([]setValue, len(cmd.set))
+	for i, a := range cmd.set {
+		idx, err := t.columnIndex(a.column)
+		if err != nil {
+			return 0, err
+		}
+		c := t.columns[idx]
+		v, err := c.convert(exprValue(c, a.expr, args))
+		if err != nil {
+			return 0, err
+		}
+		set[i] = setValue{idx: idx, value: v}
+	}
+
+	rows := make([][]driver.Value, len(t.rows))
+	copy(rows, t.rows)
+	var rowsAffected int64
+	for i, row := range t.rows {
+		ok, err := t.match(row, conds)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		newRow := make([]driver.Value, len(row)) // rows might be referenced by a transaction snapshot
+		copy(newRow, row)
+		for _, s := range set {
+			newRow[s.idx] = s.value
+		}
+		rows[i] = newRow
+		rowsAffected++
+	}
+	// check primary key on updated table
+	old := t.rows
+	t.rows = rows
+	for i, row := range rows {
+		if err := t.checkUnique(row, i); err != nil {
+			t.rows = old
+			return 0, err
+		}
+	}
+	return rowsAffected, nil
+}
+
+func (db *database) delete(t *table, cmd *command, args []driver.NamedValue) (int64, error) {
+	conds, err := db.bindConds(t, cmd.where, args)
+	if err != nil {
+		return 0, err
+	}
+	rows := make([][]driver.Value, 0, len(t.rows))
+	for _, row := range t.rows {
+		ok, err := t.match(row, conds)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			rows = append(rows, row)
+		}
+	}
+	rowsAffected := int64(len(t.rows) - len(rows))
+	t.rows = rows
+	return rowsAffected, nil
+}
+
+var countColumn = &column{name: "COUNT(*)", typeName: "BIGINT", dataType: p.DtBigint}
+
+func (db *database) query(cmd *command, args []driver.NamedValue) (driver.Rows, error) {
+	t, err := db.table(cmd.table)
+	if err != nil {
+		return nil, err
+	}
+	conds, err := db.bindConds(t, cmd.where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected [][]driver.Value
+	for _, row := range t.rows {
+		ok, err := t.match(row, conds)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, row)
+		}
+	}
+
+	if cmd.count {
+		return &rows{columns: []*column{countColumn}, rows: [][]driver.Value{{int64(len(selected))}}}, nil
+	}
+
+	if cmd.orderBy != "" {
+		idx, err := t.columnIndex(cmd.orderBy)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.sortRows(selected, idx, cmd.desc); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.columns == nil {
+		return &rows{columns: t.columns, rows: selected}, nil
+	}
+
+	columns := make([]*column, len(cmd.columns))
+	idxs := make([]int, len(cmd.columns))
+	for i, name := range cmd.columns {
+		idx, err := t.columnIndex(name)
+		if err != nil {
+			return nil, err
+		}
+		idxs[i], columns[i] = idx, t.columns[idx]
+	}
+	projected := make([][]driver.Value, len(selected))
+	for i, row := range selected {
+		projected[i] = make([]driver.Value, len(idxs))
+		for j, idx := range idxs {
+			projected[i][j] = row[idx]
+		}
+	}
+	return &rows{columns: columns, rows: projected}, nil
+}
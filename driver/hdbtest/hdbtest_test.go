@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"bytes"
+	"database/sql"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+func testCreateTable(t *testing.T, db *sql.DB) {
+	if _, err := db.Exec("create column table person (id integer primary key, name nvarchar(10) not null, salary decimal(10, 2), photo blob, birthday date)"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.Exec("create table person (id integer)")
+	testCheckErrorCode(t, err, ErrCodeDuplicateTableName)
+}
+
+func testCheckErrorCode(t *testing.T, err error, code int) {
+	dbErr, ok := err.(driver.Error)
+	if !ok {
+		t.Fatalf("error %v - expected driver.Error", err)
+	}
+	if dbErr.Code() != code {
+		t.Fatalf("error code %d - expected %d", dbErr.Code(), code)
+	}
+}
+
+func testInsertSelect(t *testing.T, db *sql.DB) {
+	salary, _ := new(big.Rat).SetString("1000.50")
+	photo := []byte{0x01, 0x02, 0x03}
+	birthday := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if _, err := db.Exec("insert into person values (?, ?, ?, ?, ?)", 1, "Alice", (*driver.Decimal)(salary), driver.NewLob(bytes.NewReader(photo), nil), birthday); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("insert into person (id, name) values (2, 'Bob')"); err != nil {
+		t.Fatal(err)
+	}
+
+	var name string
+	dec := driver.NullDecimal{Decimal: new(driver.Decimal)}
+	var buf bytes.Buffer
+	var day time.Time
+	if err := db.QueryRow("select name, salary, photo, birthday from person where id = ?", 1).Scan(&name, &dec, driver.NewLob(nil, &buf), &day); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Alice" {
+		t.Fatalf("name %s - expected %s", name, "Alice")
+	}
+	if !dec.Valid || (*big.Rat)(dec.Decimal).Cmp(salary) != 0 {
+		t.Fatalf("salary %v - expected %v", dec.Decimal, salary)
+	}
+	if !bytes.Equal(buf.Bytes(), photo) {
+		t.Fatalf("photo %v - expected %v", buf.Bytes(), photo)
+	}
+	if exp := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC); !day.Equal(exp) {
+		t.Fatalf("birthday %v - expected %v", day, exp)
+	}
+
+	if err := db.QueryRow("select salary from person where id = 2").Scan(&dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Valid {
+		t.Fatalf("salary %v - expected null", dec.Decimal)
+	}
+}
+
+func testConstraints(t *testing.T, db *sql.DB) {
+	_, err := db.Exec("insert into person (id, name) values (?, ?)", 1, "Carol")
+	testCheckErrorCode(t, err, ErrCodeUniqueConstraint)
+	_, err = db.Exec("insert into person (id, name) values (?, ?)", 3, strings.Repeat("x", 11))
+	testCheckErrorCode(t, err, ErrCodeValueTooLarge)
+	_, err = db.Exec("insert into person (id) values (?)", 3)
+	testCheckErrorCode(t, err, ErrCodeNotNull)
+	_, err = db.Exec("insert into person (id, name) values (?, ?)", "abc", "Carol")
+	testCheckErrorCode(t, err, ErrCodeInvalidValue)
+	_, err = db.Query("select * from unknown")
+	testCheckErrorCode(t, err, ErrCodeInvalidTableName)
+	_, err = db.Query("select unknown from person")
+	testCheckErrorCode(t, err, ErrCodeInvalidColumnName)
+	_, err = db.Exec("selec * from person")
+	testCheckErrorCode(t, err, ErrCodeSyntax)
+}
+
+func testBulk(t *testing.T, db *sql.DB) {
+	const numRow = 10
+
+	stmt, err := db.Prepare("bulk insert into person (id, name) values (?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < numRow; i++ {
+		if _, err := stmt.Exec(100+i, "bulk"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCount(t, db, "select count(*) from person where name = 'bulk'", 0) // not flushed yet
+	if _, err := stmt.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	testCount(t, db, "select count(*) from person where name = 'bulk'", numRow)
+
+	stmt2, err := db.Prepare("insert into person (id, name) values (?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt2.Close()
+	if _, err := stmt2.Exec(200, "bulk", driver.NoFlush); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt2.Exec(201, "bulk", driver.Flush); err != nil {
+		t.Fatal(err)
+	}
+	testCount(t, db, "select count(*) from person where name = 'bulk'", numRow+2)
+
+	result, err := db.Exec("delete from person where name = ? and id >= ?", "bulk", 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := result.RowsAffected(); n != 2 {
+		t.Fatalf("rows affected %d - expected %d", n, 2)
+	}
+}
+
+func testCount(t *testing.T, db *sql.DB, query string, exp int) {
+	var cnt int
+	if err := db.QueryRow(query).Scan(&cnt); err != nil {
+		t.Fatal(err)
+	}
+	if cnt != exp {
+		t.Fatalf("count %d - expected %d", cnt, exp)
+	}
+}
+
+func testUpdateOrder(t *testing.T, db *sql.DB) {
+	if _, err := db.Exec("update person set salary = ? where name = ?", "42.1", "Bob"); err == nil {
+		t.Fatal("error expected for string decimal argument")
+	}
+	if _, err := db.Exec("update person set name = 'Robert' where id = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("select id, name from person where id < 100 order by name desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "Robert" || names[1] != "Alice" {
+		t.Fatalf("names %v - expected %v", names, []string{"Robert", "Alice"})
+	}
+}
+
+func testTransaction(t *testing.T, db *sql.DB) {
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("delete from person"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	testCount(t, db, "select count(*) from person where id < 100", 2)
+}
+
+func TestDriver(t *testing.T) {
+	db, err := sql.Open(DriverName, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Drop(t.Name())
+	defer db.Close()
+
+	tests := []struct {
+		name string
+		fct  func(t *testing.T, db *sql.DB)
+	}{
+		{"createTable", testCreateTable},
+		{"insertSelect", testInsertSelect},
+		{"constraints", testConstraints},
+		{"bulk", testBulk},
+		{"updateOrder", testUpdateOrder},
+		{"transaction", testTransaction},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t, db)
+		})
+	}
+}
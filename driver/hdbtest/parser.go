@@ -0,0 +1,486 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+type cmdKind int
+
+const (
+	ckCreateTable cmdKind = iota
+	ckDropTable
+	ckTruncateTable
+	ckInsert
+	ckSelect
+	ckUpdate
+	ckDelete
+)
+
+// exprKind is the kind of a statement expression.
+type exprKind int
+
+const (
+	ekNull exprKind = iota
+	ekValue
+	ekParam
+)
+
+type expr struct {
+	kind  exprKind
+	value interface{} // literal value
+	text  string      // literal text
+	idx   int         // parameter index
+}
+
+type columnDef struct {
+	name       string
+	typeName   string
+	length     int
+	notNull    bool
+	primaryKey bool
+}
+
+type cond struct {
+	column string
+	op     string // =, <>, <, <=, >, >=, is null, is not null
+	expr   expr
+}
+
+type assignment struct {
+	column string
+	expr   expr
+}
+
+type command struct {
+	kind    cmdKind
+	table   string
+	defs    []columnDef  // create table
+	columns []string     // insert, select (nil: all columns)
+	values  []expr       // insert
+	set     []assignment // update
+	where   []cond       // select, update, delete (and combined)
+	count   bool         // select count(*)
+	orderBy string       // select
+	desc    bool         // select
+	numPrm  int
+}
+
+type token struct {
+	tok  scanner.Token
+	text string
+	pos  int
+}
+
+// parser is a simple parser for the subset of HANA sql statements supported by the fake driver.
+type parser struct {
+	query  string
+	tokens []token
+	i      int
+	numPrm int
+}
+
+func parse(query string) (*command, error) {
+	var sc scanner.Scanner
+	sc.Reset(query)
+
+	p := &parser{query: query}
+	for {
+		tok, start, end := sc.Next()
+		switch tok {
+		case scanner.EOS:
+			return p.parse()
+		case scanner.Error:
+			if query[start:end] != "*" { // select list wildcard
+				return nil, newSyntaxError(query, start)
+			}
+		}
+		p.tokens = append(p.tokens, token{tok: tok, text: query[start:end], pos: start})
+	}
+}
+
+func (p *parser) peek() token {
+	if p.i >= len(p.tokens) {
+		return token{tok: scanner.EOS, pos: len(p.query)}
+	}
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if p.i < len(p.tokens) {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) error() error { return newSyntaxError(p.query, p.peek().pos) }
+
+// isKeyword reports whether the next token is one of the keywords kws.
+func (p *parser) isKeyword(kws ...string) bool {
+	for j, kw := range kws {
+		if p.i+j >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.i+j]
+		if t.tok != scanner.Identifier || !strings.EqualFold(t.text, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyword consumes the keywords kws, if they are the next tokens.
+func (p *parser) keyword(kws ...string) bool {
+	if !p.isKeyword(kws...) {
+		return false
+	}
+	p.i += len(kws)
+	return true
+}
+
+func (p *parser) expectKeyword(kws ...string) error {
+	if !p.keyword(kws...) {
+		return p.error()
+	}
+	return nil
+}
+
+func (p *parser) delimiter(d string) bool {
+	if t := p.peek(); t.tok == scanner.Delimiter && t.text == d {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectDelimiter(d string) error {
+	if !p.delimiter(d) {
+		return p.error()
+	}
+	return nil
+}
+
+// identifier parses a (quoted) identifier. Unquoted identifiers are converted to upper case.
+func (p *parser) identifier() (string, error) {
+	t := p.peek()
+	switch {
+	case t.tok == scanner.Identifier:
+		p.i++
+		return strings.ToUpper(t.text), nil
+	case t.tok == scanner.QuotedIdentifier:
+		p.i++
+		return strings.Replace(t.text[1:len(t.text)-1], `""`, `"`, -1), nil
+	default:
+		return "", p.error()
+	}
+}
+
+// name parses a schema qualified database object name.
+func (p *parser) name() (string, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return "", err
+	}
+	for p.peek().tok == scanner.IdentifierDelimiter {
+		p.i++
+		s, err := p.identifier()
+		if err != nil {
+			return "", err
+		}
+		name += "." + s
+	}
+	return name, nil
+}
+
+func (p *parser) expr() (expr, error) {
+	t := p.next()
+	switch t.tok {
+	case scanner.Variable:
+		e := expr{kind: ekParam, idx: p.numPrm}
+		p.numPrm++
+		return e, nil
+	case scanner.Number:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return expr{kind: ekValue, value: i, text: t.text}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return expr{}, newSyntaxError(p.query, t.pos)
+		}
+		return expr{kind: ekValue, value: f, text: t.text}, nil
+	case scanner.String:
+		s := strings.Replace(t.text[1:len(t.text)-1], "''", "'", -1)
+		return expr{kind: ekValue, value: s, text: s}, nil
+	case scanner.Identifier:
+		switch strings.ToLower(t.text) {
+		case "null":
+			return expr{kind: ekNull}, nil
+		case "true":
+			return expr{kind: ekValue, value: true, text: t.text}, nil
+		case "false":
+			return expr{kind: ekValue, value: false, text: t.text}, nil
+		}
+	}
+	return expr{}, newSyntaxError(p.query, t.pos)
+}
+
+func (p *parser) parse() (*command, error) {
+	var cmd *command
+	var err error
+
+	switch {
+	case p.keyword("create"):
+		p.keyword("column")
+		p.keyword("row")
+		cmd, err = p.parseCreateTable()
+	case p.keyword("drop", "table"):
+		cmd = &command{kind: ckDropTable}
+		cmd.table, err = p.name()
+	case p.keyword("truncate", "table"):
+		cmd = &command{kind: ckTruncateTable}
+		cmd.table, err = p.name()
+	case p.keyword("insert", "into"):
+		cmd, err = p.parseInsert()
+	case p.keyword("select"):
+		cmd, err = p.parseSelect()
+	case p.keyword("update"):
+		cmd, err = p.parseUpdate()
+	case p.keyword("delete", "from"):
+		cmd = &command{kind: ckDelete}
+		if cmd.table, err = p.name(); err == nil {
+			cmd.where, err = p.parseWhere()
+		}
+	default:
+		return nil, p.error()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().tok != scanner.EOS {
+		return nil, p.error()
+	}
+	cmd.numPrm = p.numPrm
+	return cmd, nil
+}
+
+func (p *parser) parseCreateTable() (*command, error) {
+	if err := p.expectKeyword("table"); err != nil {
+		return nil, err
+	}
+	cmd := &command{kind: ckCreateTable}
+	var err error
+	if cmd.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expectDelimiter("("); err != nil {
+		return nil, err
+	}
+	for {
+		def := columnDef{}
+		if def.name, err = p.identifier(); err != nil {
+			return nil, err
+		}
+		t := p.next()
+		if t.tok != scanner.Identifier {
+			return nil, newSyntaxError(p.query, t.pos)
+		}
+		def.typeName = strings.ToUpper(t.text)
+		if p.delimiter("(") { // length or precision and scale
+			t := p.next()
+			if t.tok != scanner.Number {
+				return nil, newSyntaxError(p.query, t.pos)
+			}
+			def.length, _ = strconv.Atoi(t.text)
+			if p.delimiter(",") {
+				if t := p.next(); t.tok != scanner.Number {
+					return nil, newSyntaxError(p.query, t.pos)
+				}
+			}
+			if err := p.expectDelimiter(")"); err != nil {
+				return nil, err
+			}
+		}
+		for {
+			if p.keyword("not", "null") {
+				def.notNull = true
+			} else if p.keyword("null") {
+				def.notNull = false
+			} else if p.keyword("primary", "key") {
+				def.primaryKey, def.notNull = true, true
+			} else {
+				break
+			}
+		}
+		cmd.defs = append(cmd.defs, def)
+		if !p.delimiter(",") {
+			break
+		}
+	}
+	if err := p.expectDelimiter(")"); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func (p *parser) parseColumnList() ([]string, error) {
+	var columns []string
+	for {
+		column, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+		if !p.delimiter(",") {
+			return columns, nil
+		}
+	}
+}
+
+func (p *parser) parseInsert() (*command, error) {
+	cmd := &command{kind: ckInsert}
+	var err error
+	if cmd.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.delimiter("(") {
+		if cmd.columns, err = p.parseColumnList(); err != nil {
+			return nil, err
+		}
+		if err := p.expectDelimiter(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("values"); err != nil {
+		return nil, err
+	}
+	if err := p.expectDelimiter("("); err != nil {
+		return nil, err
+	}
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		cmd.values = append(cmd.values, e)
+		if !p.delimiter(",") {
+			break
+		}
+	}
+	if err := p.expectDelimiter(")"); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func (p *parser) parseSelect() (*command, error) {
+	cmd := &command{kind: ckSelect}
+	var err error
+	switch {
+	case p.peek().text == "*":
+		p.i++
+	case p.isKeyword("count"):
+		p.i++
+		if p.expectDelimiter("(") != nil || p.next().text != "*" || p.expectDelimiter(")") != nil {
+			return nil, p.error()
+		}
+		cmd.count = true
+	default:
+		if cmd.columns, err = p.parseColumnList(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("from"); err != nil {
+		return nil, err
+	}
+	if cmd.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if cmd.where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	if p.keyword("order", "by") {
+		if cmd.orderBy, err = p.identifier(); err != nil {
+			return nil, err
+		}
+		if p.keyword("desc") {
+			cmd.desc = true
+		} else {
+			p.keyword("asc")
+		}
+	}
+	return cmd, nil
+}
+
+func (p *parser) parseUpdate() (*command, error) {
+	cmd := &command{kind: ckUpdate}
+	var err error
+	if cmd.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("set"); err != nil {
+		return nil, err
+	}
+	for {
+		a := assignment{}
+		if a.column, err = p.identifier(); err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.tok != scanner.Operator || t.text != "=" {
+			return nil, newSyntaxError(p.query, t.pos)
+		}
+		if a.expr, err = p.expr(); err != nil {
+			return nil, err
+		}
+		cmd.set = append(cmd.set, a)
+		if !p.delimiter(",") {
+			break
+		}
+	}
+	if cmd.where, err = p.parseWhere(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+var operators = map[string]string{"=": "=", "<>": "<>", "!=": "<>", "<": "<", "<=": "<=", ">": ">", ">=": ">="}
+
+func (p *parser) parseWhere() ([]cond, error) {
+	if !p.keyword("where") {
+		return nil, nil
+	}
+	var conds []cond
+	for {
+		c := cond{}
+		var err error
+		if c.column, err = p.identifier(); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.keyword("is", "null"):
+			c.op = "is null"
+		case p.keyword("is", "not", "null"):
+			c.op = "is not null"
+		default:
+			t := p.next()
+			op, ok := operators[t.text]
+			if t.tok != scanner.Operator || !ok {
+				return nil, newSyntaxError(p.query, t.pos)
+			}
+			c.op = op
+			if c.expr, err = p.expr(); err != nil {
+				return nil, err
+			}
+		}
+		conds = append(conds, c)
+		if !p.keyword("and") {
+			return conds, nil
+		}
+	}
+}
@@ -0,0 +1,388 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hdbtest
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	hdb "github.com/SAP/go-hdb/driver"
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// column is a table column with HANA type semantics.
+type column struct {
+	name       string
+	typeName   string
+	length     int // maximum length of character and binary types
+	notNull    bool
+	primaryKey bool
+	converter  p.Converter
+	dataType   p.DataType
+}
+
+func newColumn(def columnDef) (*column, error) {
+	converter, dataType, ok := p.TypeConverter(def.typeName)
+	if !ok {
+		return nil, newError(ErrCodeSyntax, "invalid datatype: %s", def.typeName)
+	}
+	return &column{
+		name:       def.name,
+		typeName:   def.typeName,
+		length:     def.length,
+		notNull:    def.notNull,
+		primaryKey: def.primaryKey,
+		converter:  converter,
+		dataType:   dataType,
+	}, nil
+}
+
+// convert converts a statement argument or literal into the column value representation.
+func (c *column) convert(v interface{}) (driver.Value, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	if v == nil {
+		if c.notNull {
+			return nil, newError(ErrCodeNotNull, "cannot insert NULL or update to NULL: %s", c.name)
+		}
+		return nil, nil
+	}
+
+	switch c.dataType {
+	case p.DtDecimal:
+		return c.convertDecimal(v)
+	case p.DtLob:
+		return c.convertLob(v)
+	}
+
+	v, err := c.converter.Convert(v)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidValue, "invalid value for column %s: %s", c.name, err)
+	}
+
+	switch c.dataType {
+	case p.DtString:
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if c.length != 0 && utf8.RuneCountInString(v.(string)) > c.length {
+			return nil, newError(ErrCodeValueTooLarge, "inserted value too large for column: %s", c.name)
+		}
+	case p.DtBytes:
+		if s, ok := v.(string); ok {
+			v = []byte(s)
+		}
+		if c.length != 0 && len(v.([]byte)) > c.length {
+			return nil, newError(ErrCodeValueTooLarge, "inserted value too large for column: %s", c.name)
+		}
+	case p.DtTime:
+		v = c.convertTime(v.(time.Time))
+	}
+	return v, nil
+}
+
+// convertDecimal accepts decimal values (see driver.Decimal) only.
+func (c *column) convertDecimal(v interface{}) (driver.Value, error) {
+	v, err := c.converter.Convert(v)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidValue, "invalid value for column %s: %s", c.name, err)
+	}
+	return v, nil
+}
+
+// convertLob reads the lob content completely (streamed lob write).
+func (c *column) convertLob(v interface{}) (driver.Value, error) {
+	switch x := v.(type) {
+	case string:
+		v = bytes.NewReader([]byte(x))
+	case []byte:
+		v = bytes.NewReader(x)
+	}
+	v, err := c.converter.Convert(v)
+	if err != nil {
+		return nil, newError(ErrCodeInvalidValue, "invalid value for column %s: %s", c.name, err)
+	}
+	return ioutil.ReadAll(v.(io.Reader))
+}
+
+// convertTime strips the location like the database does.
+func (c *column) convertTime(t time.Time) time.Time {
+	t = t.UTC()
+	switch c.typeName {
+	case "DATE":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "TIME":
+		return time.Date(1, 1, 1, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	case "SECONDDATE":
+		return t.Truncate(time.Second)
+	}
+	return t
+}
+
+// value returns the column value as returned by a query.
+func (c *column) value(v driver.Value) driver.Value {
+	if b, ok := v.([]byte); ok && c.dataType == p.DtLob {
+		return &lobValue{b: b}
+	}
+	return v
+}
+
+func compareDecimal(a, b []byte) (int, error) {
+	var x, y hdb.Decimal
+	if err := x.Scan(a); err != nil {
+		return 0, err
+	}
+	if err := y.Scan(b); err != nil {
+		return 0, err
+	}
+	return (*big.Rat)(&x).Cmp((*big.Rat)(&y)), nil
+}
+
+// compare compares two non null column values.
+func (c *column) compare(a, b driver.Value) (int, error) {
+	switch a := a.(type) {
+	case int64:
+		return compareInt64(a, b.(int64)), nil
+	case float64:
+		return compareFloat64(a, b.(float64)), nil
+	case bool:
+		return compareInt64(boolToInt64(a), boolToInt64(b.(bool))), nil
+	case string:
+		return compareString(a, b.(string)), nil
+	case time.Time:
+		return compareInt64(a.UnixNano(), b.(time.Time).UnixNano()), nil
+	case []byte:
+		switch c.dataType {
+		case p.DtDecimal:
+			return compareDecimal(a, b.([]byte))
+		case p.DtBytes:
+			return bytes.Compare(a, b.([]byte)), nil
+		}
+	}
+	return 0, newError(ErrCodeInvalidValue, "column %s of type %s is not comparable", c.name, c.typeName)
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareString(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// lobValue is the query result value of a lob column. It implements the protocol.WriterSetter interface
+// expected by driver.Lob.Scan.
+type lobValue struct {
+	b []byte
+}
+
+func (l *lobValue) SetWriter(wr io.Writer) error {
+	_, err := wr.Write(l.b)
+	return err
+}
+
+type table struct {
+	name    string
+	columns []*column
+	rows    [][]driver.Value
+}
+
+func (t *table) clone() *table {
+	c := *t
+	c.rows = make([][]driver.Value, len(t.rows))
+	copy(c.rows, t.rows) // rows are not modified in place
+	return &c
+}
+
+func (t *table) columnIndex(name string) (int, error) {
+	for i, c := range t.columns {
+		if c.name == name {
+			return i, nil
+		}
+	}
+	return 0, newError(ErrCodeInvalidColumnName, "invalid column name: %s", name)
+}
+
+// checkUnique checks the primary key constraint of row (idx: row index or -1 for new rows).
+func (t *table) checkUnique(row []driver.Value, idx int) error {
+	for i, c := range t.columns {
+		if !c.primaryKey {
+			continue
+		}
+		for j, other := range t.rows {
+			if j == idx {
+				continue
+			}
+			if cmp, err := c.compare(row[i], other[i]); err == nil && cmp == 0 {
+				return newError(ErrCodeUniqueConstraint, "unique constraint violated: Table(%s)", t.name)
+			}
+		}
+	}
+	return nil
+}
+
+// match returns true if row fulfills all conditions.
+func (t *table) match(row []driver.Value, conds []boundCond) (bool, error) {
+	for _, c := range conds {
+		v := row[c.idx]
+		switch c.op {
+		case "is null":
+			if v != nil {
+				return false, nil
+			}
+			continue
+		case "is not null":
+			if v == nil {
+				return false, nil
+			}
+			continue
+		}
+		if v == nil || c.value == nil { // comparison with null is unknown
+			return false, nil
+		}
+		cmp, err := t.columns[c.idx].compare(v, c.value)
+		if err != nil {
+			return false, err
+		}
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case "<>":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// sortRows sorts rows by column idx (null values first).
+func (t *table) sortRows(rows [][]driver.Value, idx int, desc bool) (err error) {
+	c := t.columns[idx]
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][idx], rows[j][idx]
+		if desc {
+			a, b = b, a
+		}
+		switch {
+		case a == nil:
+			return b != nil
+		case b == nil:
+			return false
+		}
+		cmp, cmpErr := c.compare(a, b)
+		if cmpErr != nil {
+			err = cmpErr
+		}
+		return cmp < 0
+	})
+	return err
+}
+
+// boundCond is a where condition with the column index and the condition value.
+type boundCond struct {
+	idx   int
+	op    string
+	value driver.Value
+}
+
+// database is an in-memory database shared by all connections opened with the same data source name.
+type database struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+var databases = struct {
+	mu sync.Mutex
+	m  map[string]*database
+}{m: map[string]*database{}}
+
+func lookupDatabase(name string) *database {
+	databases.mu.Lock()
+	defer databases.mu.Unlock()
+	db, ok := databases.m[name]
+	if !ok {
+		db = &database{tables: map[string]*table{}}
+		databases.m[name] = db
+	}
+	return db
+}
+
+// Drop drops the in-memory database name. Connections opened later on with the same name start with an empty database.
+func Drop(name string) {
+	databases.mu.Lock()
+	defer databases.mu.Unlock()
+	delete(databases.m, name)
+}
+
+func (db *database) table(name string) (*table, error) {
+	t, ok := db.tables[name]
+	if !ok {
+		return nil, newError(ErrCodeInvalidTableName, "invalid table name: Could not find table/view %s", name)
+	}
+	return t, nil
+}
+
+// snapshot returns a copy of the database tables used to roll back transactions.
+func (db *database) snapshot() map[string]*table {
+	tables := make(map[string]*table, len(db.tables))
+	for name, t := range db.tables {
+		tables[name] = t.clone()
+	}
+	return tables
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// ErrInvalidHint is the error wrapped by errors raised for invalid statement hints.
+var ErrInvalidHint = errors.New("invalid hint")
+
+// hint name with optional arguments, e.g. NO_CS_JOIN or ROUTE_TO(1, 2)
+var reHint = regexp.MustCompile(`(?i)^[A-Z_][A-Z0-9_]*(\([^()]*\))?$`)
+
+// statements supporting hints
+var hintKeywords = map[string]bool{"select": true, "with": true, "insert": true, "update": true, "upsert": true, "replace": true, "delete": true, "merge": true}
+
+type hintsCtxKey struct{}
+
+/*
+WithHints returns a context with the statement hints added to the hints of ctx.
+
+The hints are applied to all select, insert, update, upsert, delete and merge statements
+prepared or executed with the context by adding a WITH HINT clause (see AddHints), e.g.
+
+	ctx := driver.WithHints(ctx, "NO_CS_JOIN", "ROUTE_TO(1)")
+	rows, err := db.QueryContext(ctx, "select * from t1 join t2 on t1.id = t2.id")
+
+Other statements are executed unchanged. Hints of a prepared statement are applied when
+the statement is prepared.
+*/
+func WithHints(ctx context.Context, hints ...string) context.Context {
+	if len(hints) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, hintsCtxKey{}, append(HintsFromContext(ctx), hints...))
+}
+
+// HintsFromContext returns the statement hints of ctx.
+func HintsFromContext(ctx context.Context) []string {
+	hints, _ := ctx.Value(hintsCtxKey{}).([]string)
+	return hints[:len(hints):len(hints)] // force copy on append
+}
+
+// AddHints adds the hints to the WITH HINT clause of query. If query does not contain a WITH HINT clause,
+// the clause is appended. Hints already part of the clause are not added twice.
+func AddHints(query string, hints ...string) (string, error) {
+	return addHints(&scanner.Scanner{}, query, hints, true)
+}
+
+// addHints adds the hints to query. If strict is not set, statements not supporting hints are returned unchanged.
+func addHints(sc *scanner.Scanner, query string, hints []string, strict bool) (string, error) {
+	if len(hints) == 0 {
+		return query, nil
+	}
+	for _, hint := range hints {
+		if !reHint.MatchString(strings.TrimSpace(hint)) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidHint, hint)
+		}
+	}
+
+	sc.Reset(query)
+
+	var (
+		first       = true
+		depth       int  // parenthesis depth
+		end         int  // end of last token (excluding statement terminator)
+		hintStart   = -1 // start position of the existing hints (after WITH HINT ()
+		hintEnd     = -1 // position of the closing parenthesis of the existing hints
+		prev2, prev string
+	)
+	for {
+		token, start, stop := sc.Next()
+		if token == scanner.EOS {
+			break
+		}
+		value := query[start:stop]
+		if first {
+			if token != scanner.Identifier || !hintKeywords[strings.ToLower(value)] {
+				if strict {
+					return "", fmt.Errorf("%w: statement does not support hints: %s", ErrInvalidHint, query)
+				}
+				return query, nil
+			}
+			first = false
+		}
+		switch {
+		case token == scanner.Delimiter && value == "(":
+			if depth == 0 && strings.EqualFold(prev2, "with") && strings.EqualFold(prev, "hint") {
+				hintStart = stop
+			}
+			depth++
+		case token == scanner.Delimiter && value == ")":
+			depth--
+			if depth == 0 && hintStart != -1 && hintEnd == -1 {
+				hintEnd = start
+			}
+		}
+		if !(token == scanner.Delimiter && value == ";") {
+			end = stop
+		}
+		prev2, prev = prev, value
+	}
+	if first { // empty statement
+		return query, nil
+	}
+
+	if hintEnd == -1 { // append hint clause
+		return query[:end] + " WITH HINT (" + strings.Join(hints, ", ") + ")" + query[end:], nil
+	}
+
+	existing := map[string]bool{}
+	for _, hint := range splitHints(query[hintStart:hintEnd]) {
+		existing[strings.ToUpper(strings.Join(strings.Fields(hint), ""))] = true
+	}
+	var add []string
+	for _, hint := range hints {
+		if key := strings.ToUpper(strings.Join(strings.Fields(hint), "")); !existing[key] {
+			existing[key] = true
+			add = append(add, hint)
+		}
+	}
+	if len(add) == 0 {
+		return query, nil
+	}
+	sep := ", "
+	if strings.TrimSpace(query[hintStart:hintEnd]) == "" {
+		sep = ""
+	}
+	return query[:hintEnd] + sep + strings.Join(add, ", ") + query[hintEnd:], nil
+}
+
+// splitHints splits a hint list at the commas outside of hint arguments.
+func splitHints(s string) []string {
+	var hints []string
+	depth, start := 0, 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				hints = append(hints, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(hints, s[start:])
+}
+
+// applyHints adds the hints of ctx to query.
+func (c *conn) applyHints(ctx context.Context, query string) (string, error) {
+	hints := HintsFromContext(ctx)
+	if len(hints) == 0 {
+		return query, nil
+	}
+	return addHints(c.scanner, query, hints, false)
+}
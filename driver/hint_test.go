@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testAddHints(t *testing.T) {
+	tests := []struct {
+		query    string
+		hints    []string
+		expected string
+	}{
+		{"select * from t", []string{"NO_CS_JOIN"}, "select * from t WITH HINT (NO_CS_JOIN)"},
+		{"select * from t;", []string{"A", "ROUTE_TO(1, 2)"}, "select * from t WITH HINT (A, ROUTE_TO(1, 2));"},
+		{"select * from t -- comment", []string{"A"}, "select * from t WITH HINT (A) -- comment"},
+		{"select * from t with hint (A)", []string{"a", "B"}, "select * from t with hint (A, B)"},
+		{"select * from t with hint (ROUTE_TO(1, 2))", []string{"route_to(1,2)"}, "select * from t with hint (ROUTE_TO(1, 2))"},
+		{"select * from (select * from t with hint (A)) x", []string{"B"}, "select * from (select * from t with hint (A)) x WITH HINT (B)"},
+		{"delete from t where a = ?", []string{"A"}, "delete from t where a = ? WITH HINT (A)"},
+		{"select * from t", nil, "select * from t"},
+	}
+	for _, test := range tests {
+		query, err := AddHints(test.query, test.hints...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != test.expected {
+			t.Fatalf("query %s - expected %s", query, test.expected)
+		}
+	}
+
+	for _, test := range []struct {
+		query string
+		hints []string
+	}{
+		{"select * from t", []string{"A) ; drop table t --"}},
+		{"select * from t", []string{""}},
+		{"create table t (a integer)", []string{"A"}},
+	} {
+		if _, err := AddHints(test.query, test.hints...); !errors.Is(err, ErrInvalidHint) {
+			t.Fatalf("query %s hints %v: error %v - expected %v", test.query, test.hints, err, ErrInvalidHint)
+		}
+	}
+}
+
+func testContextHints(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select * from dummy WITH HINT (IGNORE_PLAN_CACHE, NO_CS_JOIN)", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "DUMMY", TypeName: "NVARCHAR"}},
+		Rows:    [][]interface{}{{"X"}},
+	})
+	s.Handle("create table t (a integer)", &drivertest.MockStatement{})
+
+	db := sql.OpenDB(NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	ctx := WithHints(WithHints(context.Background(), "IGNORE_PLAN_CACHE"), "NO_CS_JOIN")
+	var dummy string
+	if err := db.QueryRowContext(ctx, "select * from dummy").Scan(&dummy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "create table t (a integer)"); err != nil { // not supporting hints
+		t.Fatal(err)
+	}
+	if hints := HintsFromContext(context.Background()); len(hints) != 0 {
+		t.Fatalf("hints %v - expected none", hints)
+	}
+}
+
+func TestHints(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"addHints", testAddHints},
+		{"contextHints", testContextHints},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
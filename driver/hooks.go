@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+/*
+Hooks is the interface implemented by types intercepting driver operations.
+
+Hooks are registered on a connector (see Connector.SetHooks) and are called for all
+connections of this connector:
+- OnConnect is called after a database connection is established. The connection
+  can be used to execute statements (e.g. to set session state).
+- BeforePrepare is called before a statement is prepared (statements with arguments).
+- BeforeQuery and BeforeExec are called before a query or a statement is executed.
+- AfterQuery and AfterExec are called after a query or a statement is executed.
+- OnError is called if a driver operation returns an error.
+
+BeforePrepare, BeforeQuery and BeforeExec can modify the context and the sql statement
+(query rewriting). A returned error aborts the operation. As a statement is prepared before
+its execution, the sql statement returned by BeforeQuery and BeforeExec is only taken into
+account for direct executions (queries and statements without arguments) - please use
+BeforePrepare to rewrite statements with arguments.
+
+The context passed to the hook methods contains the correlation id of the statement execution
+(see CorrelationIDFromContext).
+
+Hooks are called while the connection is locked. Therefore, with exception of OnConnect,
+the connection must not be used in a hook function.
+*/
+type Hooks interface {
+	OnConnect(ctx context.Context, conn driver.Conn) error
+	BeforePrepare(ctx context.Context, query string) (context.Context, string, error)
+	BeforeQuery(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error)
+	AfterQuery(ctx context.Context, query string, args []driver.NamedValue, err error)
+	BeforeExec(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error)
+	AfterExec(ctx context.Context, query string, args []driver.NamedValue, result driver.Result, err error)
+	OnError(ctx context.Context, query string, err error)
+}
+
+// NopHooks implements Hooks with no-op methods. It can be embedded
+// in custom hooks types to implement only a subset of the hooks methods.
+type NopHooks struct{}
+
+// OnConnect implements the Hooks interface.
+func (NopHooks) OnConnect(ctx context.Context, conn driver.Conn) error { return nil }
+
+// BeforePrepare implements the Hooks interface.
+func (NopHooks) BeforePrepare(ctx context.Context, query string) (context.Context, string, error) {
+	return ctx, query, nil
+}
+
+// BeforeQuery implements the Hooks interface.
+func (NopHooks) BeforeQuery(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	return ctx, query, nil
+}
+
+// AfterQuery implements the Hooks interface.
+func (NopHooks) AfterQuery(ctx context.Context, query string, args []driver.NamedValue, err error) {}
+
+// BeforeExec implements the Hooks interface.
+func (NopHooks) BeforeExec(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	return ctx, query, nil
+}
+
+// AfterExec implements the Hooks interface.
+func (NopHooks) AfterExec(ctx context.Context, query string, args []driver.NamedValue, result driver.Result, err error) {
+}
+
+// OnError implements the Hooks interface.
+func (NopHooks) OnError(ctx context.Context, query string, err error) {}
+
+// check if hooks types implements the Hooks interface.
+var (
+	_ Hooks = (*NopHooks)(nil)
+	_ Hooks = (hooksChain)(nil)
+)
+
+/*
+ChainHooks composes hooks like middleware:
+- OnConnect and the Before methods are called in order of the hooks parameters, where
+  context and sql statement returned by a hook is passed to the next one.
+  Processing stops at the first error.
+- the After methods are called in reverse order.
+- OnError is called for all hooks.
+*/
+func ChainHooks(hooks ...Hooks) Hooks {
+	chain := make(hooksChain, 0, len(hooks))
+	for _, h := range hooks {
+		switch h := h.(type) {
+		case nil:
+		case hooksChain:
+			chain = append(chain, h...)
+		default:
+			chain = append(chain, h)
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	default:
+		return chain
+	}
+}
+
+type hooksChain []Hooks
+
+func (c hooksChain) OnConnect(ctx context.Context, conn driver.Conn) error {
+	for _, h := range c {
+		if err := h.OnConnect(ctx, conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c hooksChain) BeforePrepare(ctx context.Context, query string) (context.Context, string, error) {
+	var err error
+	for _, h := range c {
+		if ctx, query, err = h.BeforePrepare(ctx, query); err != nil {
+			return ctx, query, err
+		}
+	}
+	return ctx, query, nil
+}
+
+func (c hooksChain) BeforeQuery(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	var err error
+	for _, h := range c {
+		if ctx, query, err = h.BeforeQuery(ctx, query, args); err != nil {
+			return ctx, query, err
+		}
+	}
+	return ctx, query, nil
+}
+
+func (c hooksChain) AfterQuery(ctx context.Context, query string, args []driver.NamedValue, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].AfterQuery(ctx, query, args, err)
+	}
+}
+
+func (c hooksChain) BeforeExec(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	var err error
+	for _, h := range c {
+		if ctx, query, err = h.BeforeExec(ctx, query, args); err != nil {
+			return ctx, query, err
+		}
+	}
+	return ctx, query, nil
+}
+
+func (c hooksChain) AfterExec(ctx context.Context, query string, args []driver.NamedValue, result driver.Result, err error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].AfterExec(ctx, query, args, result, err)
+	}
+}
+
+func (c hooksChain) OnError(ctx context.Context, query string, err error) {
+	for _, h := range c {
+		h.OnError(ctx, query, err)
+	}
+}
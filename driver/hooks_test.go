@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testHooks struct {
+	NopHooks
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (h testHooks) BeforeQuery(ctx context.Context, query string, args []driver.NamedValue) (context.Context, string, error) {
+	*h.calls = append(*h.calls, "before "+h.name)
+	return ctx, query + " " + h.name, h.err
+}
+
+func (h testHooks) AfterQuery(ctx context.Context, query string, args []driver.NamedValue, err error) {
+	*h.calls = append(*h.calls, "after "+h.name)
+}
+
+func testChainHooksOrder(t *testing.T) {
+	calls := []string{}
+
+	hooks := ChainHooks(testHooks{name: "a", calls: &calls}, nil, testHooks{name: "b", calls: &calls})
+
+	_, query, err := hooks.BeforeQuery(context.Background(), "q", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "q a b" {
+		t.Fatalf("query %s - expected %s", query, "q a b")
+	}
+	hooks.AfterQuery(context.Background(), query, nil, nil)
+
+	expected := []string{"before a", "before b", "after b", "after a"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("calls %v - expected %v", calls, expected)
+	}
+}
+
+func testChainHooksError(t *testing.T) {
+	calls := []string{}
+	testErr := errors.New("test error")
+
+	hooks := ChainHooks(testHooks{name: "a", calls: &calls, err: testErr}, testHooks{name: "b", calls: &calls})
+
+	if _, _, err := hooks.BeforeQuery(context.Background(), "q", nil); err != testErr {
+		t.Fatalf("error %v - expected %v", err, testErr)
+	}
+	expected := []string{"before a"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("calls %v - expected %v", calls, expected)
+	}
+}
+
+func testChainHooksEmpty(t *testing.T) {
+	if hooks := ChainHooks(); hooks != nil {
+		t.Fatalf("hooks %v - expected nil", hooks)
+	}
+	if hooks := ChainHooks(nil, NopHooks{}); hooks != (NopHooks{}) {
+		t.Fatalf("hooks %v - expected %v", hooks, NopHooks{})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"chainHooksOrder", testChainHooksOrder},
+		{"chainHooksError", testChainHooksError},
+		{"chainHooksEmpty", testChainHooksEmpty},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// health score parameters.
+const (
+	healthWeight   = 0.2                   // weight of the latest probe in the exponentially weighted moving averages
+	healthLatency  = 10 * time.Millisecond // round trip time halving the health score
+	healthInitRate = 1.0                   // initial success rate of a host
+)
+
+// LatencyStats contains the round trip time statistics of connection pings (latency probes).
+type LatencyStats struct {
+	// NumProbe is the number of probes.
+	NumProbe int64
+	// NumFailure is the number of failed probes.
+	NumFailure int64
+	// Last is the round trip time of the last successful probe.
+	Last time.Duration
+	// Min and Max are the minimum and maximum round trip times of successful probes.
+	Min, Max time.Duration
+	// TotalTime is the accumulated round trip time of successful probes.
+	TotalTime time.Duration
+	// P50, P90 and P99 are the approximated 50th, 90th and 99th round trip time percentiles.
+	P50, P90, P99 time.Duration
+	// Score is the health score in the range of 0 (unhealthy) to 1 (healthy) (see HostHealth).
+	Score float64
+}
+
+// AvgTime returns the average round trip time of successful probes.
+func (s *LatencyStats) AvgTime() time.Duration {
+	if n := s.NumProbe - s.NumFailure; n != 0 {
+		return s.TotalTime / time.Duration(n)
+	}
+	return 0
+}
+
+// latencyProbes collects the round trip times of latency probes.
+type latencyProbes struct {
+	numProbe, numFailure int64
+	last, min, max       time.Duration
+	totalTime            time.Duration
+	histogram            latencyHistogram
+	successRate          float64 // exponentially weighted moving average of the probe success
+	avgTime              float64 // exponentially weighted moving average of the round trip time (nanoseconds)
+}
+
+func newLatencyProbes() *latencyProbes { return &latencyProbes{successRate: healthInitRate} }
+
+func (p *latencyProbes) add(d time.Duration, err error) {
+	p.numProbe++
+	if err != nil {
+		p.numFailure++
+		p.successRate *= 1 - healthWeight
+		return
+	}
+	p.successRate = p.successRate*(1-healthWeight) + healthWeight
+	if p.numProbe-p.numFailure == 1 {
+		p.min, p.max, p.avgTime = d, d, float64(d)
+	} else {
+		p.avgTime = p.avgTime*(1-healthWeight) + float64(d)*healthWeight
+	}
+	p.last = d
+	if d < p.min {
+		p.min = d
+	}
+	if d > p.max {
+		p.max = d
+	}
+	p.totalTime += d
+	p.histogram.add(d)
+}
+
+// score returns the health score: the weighted success rate of the probes scaled down by the weighted round trip time,
+// so that a host with a round trip time of healthLatency scores half of a host with a negligible round trip time.
+func (p *latencyProbes) score() float64 {
+	return p.successRate / (1 + p.avgTime/float64(healthLatency))
+}
+
+func (p *latencyProbes) stats() LatencyStats {
+	n := p.numProbe - p.numFailure
+	percentile := func(q float64) time.Duration {
+		// bucket upper bound might exceed maximum
+		if d := p.histogram.percentile(q, n); d < p.max {
+			return d
+		}
+		return p.max
+	}
+	return LatencyStats{
+		NumProbe:   p.numProbe,
+		NumFailure: p.numFailure,
+		Last:       p.last,
+		Min:        p.min,
+		Max:        p.max,
+		TotalTime:  p.totalTime,
+		P50:        percentile(0.5),
+		P90:        percentile(0.9),
+		P99:        percentile(0.99),
+		Score:      p.score(),
+	}
+}
+
+// HostLatency contains the latency statistics of a database host.
+type HostLatency struct {
+	Host string
+	LatencyStats
+}
+
+/*
+HostHealth is a registry collecting the latency statistics and health scores per database host.
+
+The round trip times of connection pings (periodic pings, see Connector.SetPingInterval, and pings executed via
+database/sql) are recorded as latency probes of the host of the connection. The health score of a host combines
+the exponentially weighted success rate and round trip time of the probes, so that hosts can be ranked for host
+selection and unhealthy hosts can be alerted.
+A HostHealth registry is activated by assigning it to a connector (see Connector.SetHostHealth) and
+can be shared between connectors.
+*/
+type HostHealth struct {
+	mu    sync.Mutex
+	hosts map[string]*latencyProbes
+}
+
+// NewHostHealth returns a new host health registry.
+func NewHostHealth() *HostHealth { return &HostHealth{hosts: make(map[string]*latencyProbes)} }
+
+// record records a latency probe of host.
+func (h *HostHealth) record(host string, d time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	p, ok := h.hosts[host]
+	if !ok {
+		p = newLatencyProbes()
+		h.hosts[host] = p
+	}
+	p.add(d, err)
+}
+
+// Score returns the health score of host. The score of hosts without probes is 1 (healthy).
+func (h *HostHealth) Score(host string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p, ok := h.hosts[host]; ok {
+		return p.score()
+	}
+	return healthInitRate
+}
+
+// Reset removes all collected statistics.
+func (h *HostHealth) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hosts = make(map[string]*latencyProbes)
+}
+
+// Snapshot returns the current latency statistics of all hosts ordered by health score (descending).
+func (h *HostHealth) Snapshot() []HostLatency {
+	h.mu.Lock()
+	r := make([]HostLatency, 0, len(h.hosts))
+	for host, p := range h.hosts {
+		r = append(r, HostLatency{Host: host, LatencyStats: p.stats()})
+	}
+	h.mu.Unlock()
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Score != r[j].Score {
+			return r[i].Score > r[j].Score
+		}
+		return r[i].Host < r[j].Host
+	})
+	return r
+}
+
+// Dump writes the current latency statistics of all hosts as table to w.
+func (h *HostHealth) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "probes\tfailures\tlast\tmin\tavg\tp50\tp90\tp99\tmax\tscore\t host")
+	for _, hl := range h.Snapshot() {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.3f\t %s\n",
+			hl.NumProbe, hl.NumFailure, hl.Last, hl.Min, hl.AvgTime(), hl.P50, hl.P90, hl.P99, hl.Max, hl.Score, hl.Host)
+	}
+	return tw.Flush()
+}
+
+// connLatency collects the latency probes of a connection.
+type connLatency struct {
+	mu     sync.Mutex
+	probes *latencyProbes
+}
+
+func (l *connLatency) add(d time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.probes.add(d, err)
+}
+
+func (l *connLatency) stats() LatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.probes.stats()
+}
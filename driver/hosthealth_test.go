@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testHostHealthRecord(t *testing.T) {
+	h := NewHostHealth()
+
+	if score := h.Score("fast:30015"); score != 1 {
+		t.Fatalf("score %f - expected %f", score, 1.0)
+	}
+
+	for i := 0; i < 10; i++ {
+		h.record("fast:30015", time.Millisecond, nil)
+		h.record("slow:30015", 50*time.Millisecond, nil)
+		h.record("failing:30015", time.Millisecond, errors.New("test error"))
+	}
+	h.record("slow:30015", 100*time.Millisecond, nil)
+
+	snapshot := h.Snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("number of hosts %d - expected %d", len(snapshot), 3)
+	}
+	for i, host := range []string{"fast:30015", "slow:30015", "failing:30015"} {
+		if snapshot[i].Host != host {
+			t.Fatalf("host %d %s - expected %s", i, snapshot[i].Host, host)
+		}
+	}
+
+	hl := snapshot[1]
+	if hl.NumProbe != 11 || hl.NumFailure != 0 {
+		t.Fatalf("probes %d failures %d - expected %d %d", hl.NumProbe, hl.NumFailure, 11, 0)
+	}
+	if hl.Last != 100*time.Millisecond || hl.Min != 50*time.Millisecond || hl.Max != 100*time.Millisecond {
+		t.Fatalf("last %s min %s max %s - expected %s %s %s", hl.Last, hl.Min, hl.Max, 100*time.Millisecond, 50*time.Millisecond, 100*time.Millisecond)
+	}
+	if hl.AvgTime() != 600*time.Millisecond/11 {
+		t.Fatalf("average time %s - expected %s", hl.AvgTime(), 600*time.Millisecond/11)
+	}
+	if hl.P50 < 50*time.Millisecond || hl.P99 > 100*time.Millisecond {
+		t.Fatalf("invalid percentiles p50 %s p99 %s", hl.P50, hl.P99)
+	}
+
+	if score := h.Score("fast:30015"); score < 0.9 {
+		t.Fatalf("score %f - expected healthy host", score)
+	}
+	if score := h.Score("failing:30015"); score > 0.2 {
+		t.Fatalf("score %f - expected unhealthy host", score)
+	}
+	// recovery
+	for i := 0; i < 20; i++ {
+		h.record("failing:30015", time.Millisecond, nil)
+	}
+	if score := h.Score("failing:30015"); score < 0.8 {
+		t.Fatalf("score %f - expected recovered host", score)
+	}
+
+	b := new(bytes.Buffer)
+	if err := h.Dump(b); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + b.String())
+
+	h.Reset()
+	if len(h.Snapshot()) != 0 {
+		t.Fatal("host health not reset")
+	}
+}
+
+func testHostHealthPing(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	h := NewHostHealth()
+	connector := NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetHostHealth(h); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	const numPing = 5
+	for i := 0; i < numPing; i++ {
+		if err := conn.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := conn.conn.Stats().Latency
+	if stats.NumProbe != numPing || stats.NumFailure != 0 {
+		t.Fatalf("probes %d failures %d - expected %d %d", stats.NumProbe, stats.NumFailure, numPing, 0)
+	}
+	if stats.Min <= 0 || stats.Max < stats.Min {
+		t.Fatalf("invalid round trip times min %s max %s", stats.Min, stats.Max)
+	}
+
+	snapshot := h.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Host != s.Host() || snapshot[0].NumProbe != numPing {
+		t.Fatalf("host health %v - expected %d probes of host %s", snapshot, numPing, s.Host())
+	}
+}
+
+func TestHostHealth(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"record", testHostHealthRecord},
+		{"ping", testHostHealthPing},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
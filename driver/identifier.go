@@ -9,7 +9,7 @@ import (
 	"fmt"
 	"io"
 	"regexp"
-	"strconv"
+	"strings"
 )
 
 var reSimple = regexp.MustCompile("^[_A-Z][_#$A-Z0-9]*$")
@@ -28,10 +28,102 @@ func RandomIdentifier(prefix string) Identifier {
 }
 
 // String implements Stringer interface.
+// Simple identifiers (upper case letters, digits, _, # and $) are returned as is, all other
+// identifiers are returned as delimited identifiers (double quotes, inner double quotes doubled).
 func (i Identifier) String() string {
 	s := string(i)
 	if reSimple.MatchString(s) {
 		return s
 	}
-	return strconv.Quote(s)
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Qualify returns the identifier qualified by schema (schema.identifier).
+// If schema is empty, the unqualified identifier is returned.
+func (i Identifier) Qualify(schema Identifier) string {
+	if schema == "" {
+		return i.String()
+	}
+	return schema.String() + "." + i.String()
+}
+
+// QualifiedName returns the fully qualified object name of the identifiers
+// (e.g. schema.table or schema.table.column). Empty identifiers are omitted.
+func QualifiedName(ids ...Identifier) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" {
+			parts = append(parts, id.String())
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ParseIdentifier parses an identifier as written in a sql statement: simple identifiers
+// are converted to upper case like the database does, delimited identifiers ("name") are unquoted.
+func ParseIdentifier(s string) (Identifier, error) {
+	ids, err := ParseQualifiedName(s)
+	if err != nil {
+		return "", err
+	}
+	if len(ids) != 1 {
+		return "", fmt.Errorf("invalid identifier %s: qualified name", s)
+	}
+	return ids[0], nil
+}
+
+// ParseQualifiedName parses a qualified object name as written in a sql statement (e.g. schema."table")
+// and returns its identifiers (see ParseIdentifier).
+func ParseQualifiedName(s string) ([]Identifier, error) {
+	var ids []Identifier
+	rest := strings.TrimSpace(s)
+	for {
+		var id string
+		if strings.HasPrefix(rest, `"`) { // delimited identifier
+			var b strings.Builder
+			i := 1
+			for {
+				j := strings.IndexByte(rest[i:], '"')
+				if j == -1 {
+					return nil, fmt.Errorf("invalid identifier %s: missing closing quote", s)
+				}
+				b.WriteString(rest[i : i+j])
+				i += j + 1
+				if i < len(rest) && rest[i] == '"' { // escaped quote
+					b.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			id, rest = b.String(), rest[i:]
+			if id == "" {
+				return nil, fmt.Errorf("invalid identifier %s: empty delimited identifier", s)
+			}
+		} else {
+			j := strings.IndexByte(rest, '.')
+			if j == -1 {
+				j = len(rest)
+			}
+			id, rest = strings.ToUpper(rest[:j]), rest[j:]
+			if !reSimple.MatchString(id) {
+				return nil, fmt.Errorf("invalid identifier %s: %q is not a simple identifier", s, id)
+			}
+		}
+		ids = append(ids, Identifier(id))
+
+		if rest == "" {
+			return ids, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("invalid identifier %s: unexpected %q", s, rest)
+		}
+		rest = rest[1:]
+	}
+}
+
+// StringLiteral returns s as sql string literal (single quotes, inner single quotes doubled).
+// Prefer statement parameters (?) to string literals wherever possible.
+func StringLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
@@ -5,6 +5,7 @@
 package driver
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -24,6 +25,8 @@ var testIdentifierData = []*testIdentifier{
 	{"testTransaction", `"testTransaction"`},
 	{"a.b.c", `"a.b.c"`},
 	{"AAA.BBB.CCC", `"AAA.BBB.CCC"`},
+	{`a"b`, `"a""b"`},
+	{`a\b`, `"a\b"`},
 }
 
 func TestIdentifierStringer(t *testing.T) {
@@ -33,3 +36,58 @@ func TestIdentifierStringer(t *testing.T) {
 		}
 	}
 }
+
+func TestIdentifierQualify(t *testing.T) {
+	tests := []struct {
+		s, expected string
+	}{
+		{Identifier("T").Qualify(""), "T"},
+		{Identifier("table").Qualify("SYS"), `SYS."table"`},
+		{QualifiedName("mySchema", "T", "c"), `"mySchema".T."c"`},
+		{QualifiedName("", "T"), "T"},
+		{StringLiteral("it's"), `'it''s'`},
+		{StringLiteral(""), `''`},
+	}
+	for i, test := range tests {
+		if test.s != test.expected {
+			t.Fatalf("%d %s - expected %s", i, test.s, test.expected)
+		}
+	}
+}
+
+func TestParseQualifiedName(t *testing.T) {
+	tests := []struct {
+		s   string
+		ids []Identifier
+	}{
+		{"table", []Identifier{"TABLE"}},
+		{` "mySchema".t `, []Identifier{"mySchema", "T"}},
+		{`"a.b"."c""d".E`, []Identifier{"a.b", `c"d`, "E"}},
+	}
+	for _, test := range tests {
+		ids, err := ParseQualifiedName(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Fatalf("%s: identifiers %v - expected %v", test.s, ids, test.ids)
+		}
+		// round trip
+		if ids2, err := ParseQualifiedName(QualifiedName(ids...)); err != nil || !reflect.DeepEqual(ids2, ids) {
+			t.Fatalf("%s: round trip identifiers %v (%v) - expected %v", test.s, ids2, err, ids)
+		}
+	}
+
+	for _, s := range []string{"", `"a`, `""`, "a b", "a.", `"a"b`, "1a", "a;drop table t"} {
+		if _, err := ParseQualifiedName(s); err == nil {
+			t.Fatalf("%s: error expected", s)
+		}
+	}
+
+	if _, err := ParseIdentifier("a.b"); err == nil {
+		t.Fatal("error expected for qualified name")
+	}
+	if id, err := ParseIdentifier(`"a"`); err != nil || id != "a" {
+		t.Fatalf("identifier %s (%v) - expected %s", id, err, "a")
+	}
+}
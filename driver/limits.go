@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Connector maximal values.
+const (
+	MaxFetchSize    = math.MaxInt32 // Maximal fetchSize value (fetch size is transferred as 32 bit integer).
+	MaxBulkSize     = math.MaxInt16 // Maximal bulkSize value (maximum number of parameter rows of a request).
+	MaxLobChunkSize = 1 << 14       // Maximal lobChunkSize value.
+	MaxPrefetch     = 16            // Maximal number of result set chunks fetched in advance.
+
+	MinMaxPacketSize = 1 << 16 // Minimal maxPacketSize value (64 KiB).
+)
+
+// ErrLimitExceeded is matched by all LimitError errors (see errors.Is).
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError is the error returned in case a connector setting exceeds its limit.
+type LimitError struct {
+	Name  string // Name of the setting (e.g. "bulk size").
+	Value int    // Configured value.
+	Limit int    // Violated limit.
+	Max   bool   // Limit is a maximum (true) or a minimum (false).
+}
+
+func (e *LimitError) Error() string {
+	if e.Max {
+		return fmt.Sprintf("%s %d exceeds maximum %d", e.Name, e.Value, e.Limit)
+	}
+	return fmt.Sprintf("%s %d is below minimum %d", e.Name, e.Value, e.Limit)
+}
+
+// Is returns true for target ErrLimitExceeded.
+func (e *LimitError) Is(target error) bool { return target == ErrLimitExceeded }
+
+// checkLimit returns a LimitError in case value is not in the range min <= value <= max.
+func checkLimit(name string, value, min, max int) error {
+	switch {
+	case value < min:
+		return &LimitError{Name: name, Value: value, Limit: min}
+	case value > max:
+		return &LimitError{Name: name, Value: value, Limit: max, Max: true}
+	}
+	return nil
+}
+
+// Limits contains the effective size settings of a connector and the respective maxima.
+type Limits struct {
+	FetchSize       int
+	BulkSize        int
+	LobChunkSize    int
+	MaxFetchSize    int
+	MaxBulkSize     int
+	MaxLobChunkSize int
+}
+
+// Limits returns the effective size settings of the connector.
+func (c *Connector) Limits() Limits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Limits{
+		FetchSize:       c.fetchSize,
+		BulkSize:        c.bulkSize,
+		LobChunkSize:    int(c.lobChunkSize),
+		MaxFetchSize:    MaxFetchSize,
+		MaxBulkSize:     MaxBulkSize,
+		MaxLobChunkSize: MaxLobChunkSize,
+	}
+}
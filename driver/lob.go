@@ -8,6 +8,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"io"
+	"sync"
 
 	p "github.com/SAP/go-hdb/internal/protocol"
 )
@@ -17,6 +18,11 @@ import (
 // A Lob object uses an io.Writer object as destination for reading content from a database lob field.
 // A Lob can be created by contructor method NewLob with io.Reader and io.Writer as parameters or
 // created by new, setting io.Reader and io.Writer by SetReader and SetWriter methods.
+//
+// The length of the lob content does not need to be known in advance: the content is read from the io.Reader
+// until io.EOF and written to the database in chunks of the connector lob chunk size (see Connector.SetLobChunkSize).
+// Any io.Reader (e.g. an *os.File or the reader of an io.Pipe) can be bound directly as lob parameter as well.
+// For content produced while the statement is executed please see NewLobStream.
 type Lob struct {
 	rd io.Reader
 	wr io.Writer
@@ -27,6 +33,53 @@ func NewLob(rd io.Reader, wr io.Writer) *Lob {
 	return &Lob{rd: rd, wr: wr}
 }
 
+/*
+NewLobStream creates a new Lob instance writing the lob content by the write function.
+
+write is called in a separate goroutine as soon as the lob content is sent to the database server during
+the statement execution. The content written to w is streamed to the database in chunks of the connector lob
+chunk size (see Connector.SetLobChunkSize) without materializing the value in memory: a write to w blocks
+until the previous chunk is sent, so that the producer cannot outpace the database.
+An error returned by write aborts the statement execution with this error. In case the statement execution
+is aborted otherwise (e.g. by a database error or the cancellation of the statement context), the writes
+to w fail with the respective error, so that write can return.
+
+As the content is produced once, a Lob created by NewLobStream can only be used for a single statement execution.
+*/
+func NewLobStream(write func(w io.Writer) error) *Lob {
+	return &Lob{rd: newLobPipe(write)}
+}
+
+// lobPipe is the reader of a lob stream starting the write function on first read.
+type lobPipe struct {
+	once  sync.Once
+	write func(w io.Writer) error
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+}
+
+func newLobPipe(write func(w io.Writer) error) *lobPipe {
+	pr, pw := io.Pipe()
+	return &lobPipe{write: write, pr: pr, pw: pw}
+}
+
+func (p *lobPipe) start() {
+	p.once.Do(func() {
+		go func() { p.pw.CloseWithError(p.write(p.pw)) }()
+	})
+}
+
+// Read implements the io.Reader interface.
+func (p *lobPipe) Read(b []byte) (int, error) {
+	p.start()
+	return p.pr.Read(b)
+}
+
+// CloseWithError closes the pipe, so that pending and future writes of the write function fail with err.
+func (p *lobPipe) CloseWithError(err error) error {
+	return p.pr.CloseWithError(err)
+}
+
 // Reader returns the io.Reader of the Lob.
 func (l Lob) Reader() io.Reader {
 	return l.rd
@@ -96,3 +149,76 @@ func (l *NullLob) Scan(src interface{}) error {
 	l.Valid = true
 	return nil
 }
+
+/*
+LobReader provides random access to a binary database lob field (BLOB) read by a query.
+
+Contrary to Lob, scanning a LobReader does not read the lob content. The content is read by
+Read, ReadAt and Seek requesting the range of the lob content from the database, so that ranges of
+huge lobs can be read without reading the lob sequentially (e.g. resumable downloads).
+LobReader implements the io.Reader, io.ReaderAt and io.Seeker interfaces.
+
+The lob content can be read as long as the rows of the query are not closed and the transaction
+of the query is not finished. Character lobs (CLOB, NCLOB, TEXT) are not supported.
+*/
+type LobReader struct {
+	rd  p.LobReaderAt
+	ofs int64
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (r *LobReader) Scan(src interface{}) error {
+	rd, ok := src.(p.LobReaderAt)
+	if !ok {
+		return fmt.Errorf("lob reader: invalid scan type %T", src)
+	}
+	if rd.IsCharBased() {
+		return fmt.Errorf("lob reader: character lobs are not supported")
+	}
+	r.rd, r.ofs = rd, 0
+	return nil
+}
+
+// Size returns the size of the lob in bytes.
+func (r *LobReader) Size() int64 {
+	if r.rd == nil {
+		return 0
+	}
+	return r.rd.Size()
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r *LobReader) ReadAt(b []byte, ofs int64) (int, error) {
+	if r.rd == nil {
+		return 0, io.EOF
+	}
+	return r.rd.ReadAt(b, ofs)
+}
+
+// Read implements the io.Reader interface.
+func (r *LobReader) Read(b []byte) (int, error) {
+	n, err := r.ReadAt(b, r.ofs)
+	r.ofs += int64(n)
+	if err == io.EOF && n != 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (r *LobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.ofs
+	case io.SeekEnd:
+		offset += r.Size()
+	default:
+		return 0, fmt.Errorf("lob reader: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("lob reader: negative position %d", offset)
+	}
+	r.ofs = offset
+	return offset, nil
+}
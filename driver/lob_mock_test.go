@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockLobStream(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var blob []byte
+	var nclob string
+	s.Handle("insert into t values (?, ?)", &drivertest.MockStatement{Params: []string{"BLOB", "NCLOB"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		blob, nclob = args[0].([]byte), args[1].(string)
+		return &drivertest.MockResult{}, nil
+	}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetLobChunkSize(128); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	chunk := make([]byte, 1000)
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+	const numChunk = 100
+	text := strings.Repeat("Hello, 世界! ", 100)
+
+	t.Run("stream", func(t *testing.T) {
+		lob := driver.NewLobStream(func(w io.Writer) error {
+			for i := 0; i < numChunk; i++ {
+				if _, err := w.Write(chunk); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		r, err := db.Exec("insert into t values (?, ?)", lob, new(driver.Lob).SetReader(strings.NewReader(text)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := r.RowsAffected(); n != 1 {
+			t.Fatalf("rows affected %d - expected %d", n, 1)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !bytes.Equal(blob, bytes.Repeat(chunk, numChunk)) {
+			t.Fatalf("blob size %d - expected %d", len(blob), numChunk*len(chunk))
+		}
+		if nclob != text {
+			t.Fatalf("nclob %q - expected %q", nclob, text)
+		}
+	})
+
+	t.Run("writeError", func(t *testing.T) {
+		errWrite := errors.New("lob content not available")
+		lob := driver.NewLobStream(func(w io.Writer) error {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			return errWrite
+		})
+		if _, err := db.Exec("insert into t values (?, ?)", lob, new(driver.Lob).SetReader(strings.NewReader(text))); !errors.Is(err, errWrite) {
+			t.Fatalf("error %v - expected %v", err, errWrite)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		release := make(chan struct{})
+		writeErr := make(chan error, 1)
+		lob := driver.NewLobStream(func(w io.Writer) error {
+			_, err := w.Write(chunk)
+			if err == nil {
+				<-release // stalled producer
+				_, err = w.Write(chunk)
+			}
+			writeErr <- err
+			return err
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := db.ExecContext(ctx, "insert into t values (?, ?)", lob, new(driver.Lob).SetReader(strings.NewReader(text))); err == nil {
+			t.Fatal("error expected")
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Fatalf("execution canceled after %s", d)
+		}
+		close(release)
+		if err := <-writeErr; !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("write error %v - expected %v", err, context.DeadlineExceeded)
+		}
+		// the pool replaces the canceled connection
+		if err := db.Ping(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestMockLobReader(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	content := make([]byte, 5000)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	s.Handle("select data from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "DATA", TypeName: "BLOB"}}, Rows: [][]interface{}{{content}}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetLobChunkSize(512); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	rows, err := db.Query("select data from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("row expected: %v", rows.Err())
+	}
+	rd := &driver.LobReader{}
+	if err := rows.Scan(rd); err != nil {
+		t.Fatal(err)
+	}
+	if rd.Size() != int64(len(content)) {
+		t.Fatalf("size %d - expected %d", rd.Size(), len(content))
+	}
+
+	readAtTests := []struct {
+		ofs, size int
+	}{
+		{0, 100},    // data included in result set
+		{1000, 100}, // data included in result set and read lob request
+		{3000, 1500},
+		{4900, 100},
+	}
+	for _, test := range readAtTests {
+		b := make([]byte, test.size)
+		n, err := rd.ReadAt(b, int64(test.ofs))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b[:n], content[test.ofs:test.ofs+test.size]) {
+			t.Fatalf("offset %d size %d: content mismatch", test.ofs, test.size)
+		}
+	}
+	if n, err := rd.ReadAt(make([]byte, 200), 4900); n != 100 || err != io.EOF {
+		t.Fatalf("n %d error %v - expected %d %v", n, err, 100, io.EOF)
+	}
+
+	if _, err := rd.Seek(-1000, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rd); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), content[4000:]) {
+		t.Fatalf("content mismatch: read %d bytes - expected %d", buf.Len(), 1000)
+	}
+}
+
+func TestMockLobReaderShort(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.SetLobLimit(3000) // lob content ends before the lob size
+
+	content := make([]byte, 5000)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	s.Handle("select data from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "DATA", TypeName: "BLOB"}}, Rows: [][]interface{}{{content}}})
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	rows, err := db.Query("select data from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("row expected: %v", rows.Err())
+	}
+	rd := &driver.LobReader{}
+	if err := rows.Scan(rd); err != nil {
+		t.Fatal(err)
+	}
+
+	readAtTests := []struct {
+		ofs, size, n int
+	}{
+		{2500, 1000, 500},
+		{3000, 1000, 0},
+		{4000, 2000, 0},
+	}
+	for _, test := range readAtTests {
+		b := make([]byte, test.size)
+		n, err := rd.ReadAt(b, int64(test.ofs))
+		if n != test.n || err != io.ErrUnexpectedEOF {
+			t.Fatalf("offset %d size %d: n %d error %v - expected %d %v", test.ofs, test.size, n, err, test.n, io.ErrUnexpectedEOF)
+		}
+		if !bytes.Equal(b[:n], content[test.ofs:test.ofs+n]) {
+			t.Fatalf("offset %d size %d: content mismatch", test.ofs, test.size)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rd); err != io.ErrUnexpectedEOF {
+		t.Fatalf("error %v - expected %v", err, io.ErrUnexpectedEOF)
+	}
+	if !bytes.Equal(buf.Bytes(), content[:3000]) {
+		t.Fatalf("content mismatch: read %d bytes - expected %d", buf.Len(), 3000)
+	}
+}
@@ -13,9 +13,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
 )
 
 const (
@@ -24,11 +27,11 @@ const (
 		- set e.g. DSN via env variable and
 		- e.g. execute tests via go test -v ./...
 	*/
-	envDSN = "GOHDBDSN"
+	envDSN = drivertest.EnvDSN
 )
 
 const (
-	TestGoHDBSchemaPrefix = "goHdbTest_"
+	TestGoHDBSchemaPrefix = drivertest.SchemaPrefix
 )
 
 // flags
@@ -45,6 +48,14 @@ var (
 	// TestPingConn sets the connection ping interval in milliseconds.
 	// If zero, the connection ping is deactivated.
 	TestPingConn int64
+	// TestStaleSchemaAge enables dropping test schemas older than the given duration before test execution
+	// (garbage collection of schemas left by aborted or failed test runs).
+	// If zero, stale test schemas are not dropped.
+	TestStaleSchemaAge time.Duration
+	// TestRecord is the file name the protocol conversation of the test run is recorded to.
+	TestRecord string
+	// TestReplay is the file name of a recorded protocol conversation, which is replayed instead of connecting to the database.
+	TestReplay string
 )
 
 func init() {
@@ -53,17 +64,25 @@ func init() {
 	flag.BoolVar(&TestDropSchema, "dropSchema", true, "drop test schema if test ran successfully")
 	flag.BoolVar(&TestDropAllSchemas, "dropAllSchemas", false, "drop all existing test schemas if test ran successfully")
 	flag.Int64Var(&TestPingConn, "pingConn", 0, "sets the connection ping interval (if zero, the connection ping is deactivated)")
+	flag.StringVar(&TestRecord, "record", "", "record the protocol conversation of the test run to file")
+	flag.StringVar(&TestReplay, "replay", "", "replay the protocol conversation recorded in file instead of connecting to the database")
+	flag.DurationVar(&TestStaleSchemaAge, "staleSchemaAge", 0, "drop test schemas older than the given age before test execution (if zero, stale schemas are not dropped)")
 }
 
 // globals
 var (
 	// TestSchema will be used as test schema name and created on the database by TestMain.
-	// The schema name consists of the prefix "test_" and a random Identifier.
+	// The schema name consists of the prefix TestGoHDBSchemaPrefix, the package name and a random part.
 	TestSchema Identifier
 	// TestDB is instantiated by TestMain and should be used by tests.
 	// TestDB uses TestDSN to connect to database.
 	// Each TestDB connection will set TestSchema as default database schema.
 	TestDB *sql.DB
+	// TestMatrix is the compatibility test matrix of targets (database endpoints and data format versions)
+	// defined by the environment variables drivertest.EnvTargets and drivertest.EnvDfvs.
+	// If not set, TestMatrix consists of the single target TestDSN.
+	// The target server versions are detected by TestMain.
+	TestMatrix drivertest.Matrix
 	// // TestRec enables test recording.
 	// TestRec bool
 	// // TestRecFname is the filename used for test recording.
@@ -75,7 +94,7 @@ var (
 )
 
 func init() {
-	TestSchema = RandomIdentifier(TestGoHDBSchemaPrefix)
+	TestSchema = Identifier(drivertest.SchemaName(TestGoHDBSchemaPrefix, "driver")) // unique per package and test run
 }
 
 func testExit(err error) {
@@ -95,12 +114,87 @@ func TestMain(m *testing.M) {
 	if !flag.Parsed() {
 		flag.Parse()
 	}
+	teardown := testSetupDSN()
 	conn := testSetup()
 	exitCode := m.Run()
 	testTeardown(exitCode, conn)
+	TestDB.Close() // close connections to complete recording
+	teardown()
 	os.Exit(exitCode)
 }
 
+// testSetupDSN starts a HANA express container if no dsn is provided and the container environment variable is set
+// (see drivertest.Setup).
+// Protocol conversations are recorded or replayed via drivertest.RecordProxy and drivertest.ReplayServer.
+func testSetupDSN() func() {
+	if TestReplay != "" {
+		return testSetupReplay()
+	}
+	teardown := func() {}
+	if TestDSN == "" {
+		var err error
+		if TestDSN, teardown, err = drivertest.Setup(context.Background()); err != nil {
+			testExit(err)
+		}
+		log.Print("started test database container")
+	}
+	if TestRecord == "" {
+		return teardown
+	}
+	u, err := url.Parse(TestDSN)
+	if err != nil {
+		testExit(err)
+	}
+	rp, err := drivertest.NewRecordProxy(u.Host)
+	if err != nil {
+		testExit(err)
+	}
+	u.Host = rp.Host()
+	TestDSN = u.String()
+	return func() {
+		rp.Close()
+		if err := saveRecording(TestRecord, rp.Recording()); err != nil {
+			log.Printf("save recording error: %s", err)
+		}
+		teardown()
+	}
+}
+
+func saveRecording(fname string, recording *drivertest.Recording) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := recording.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func testSetupReplay() func() {
+	f, err := os.Open(TestReplay)
+	if err != nil {
+		testExit(err)
+	}
+	recording, err := drivertest.LoadRecording(f)
+	f.Close()
+	if err != nil {
+		testExit(err)
+	}
+	rs, err := drivertest.NewReplayServer(recording)
+	if err != nil {
+		testExit(err)
+	}
+	TestDSN = (&url.URL{Scheme: "hdb", User: url.UserPassword("replay", "replay"), Host: rs.Host()}).String()
+	return func() {
+		rs.Close()
+		if err := rs.Err(); err != nil {
+			log.Printf("replay error: %s", err)
+		}
+	}
+}
+
 func testSetup() *sql.Conn {
 	connector, err := NewDSNConnector(TestDSN)
 	if err != nil {
@@ -112,6 +206,21 @@ func testSetup() *sql.Conn {
 
 	ctx := context.Background()
 
+	if TestMatrix, err = drivertest.MatrixFromEnv(TestDSN); err != nil {
+		testExit(err)
+	}
+	if err := TestMatrix.DetectVersions(ctx, DriverName); err != nil {
+		testExit(err)
+	}
+
+	if TestStaleSchemaAge != 0 {
+		dropped, err := drivertest.DropStaleSchemas(ctx, TestDB, TestGoHDBSchemaPrefix, TestStaleSchemaAge)
+		if err != nil {
+			testExit(err)
+		}
+		log.Printf("number of dropped stale schemas: %d", len(dropped))
+	}
+
 	// create schema in own connection (no reuse of conn as DefaultSchema is not set)
 	conn, err := TestDB.Conn(ctx)
 	if err != nil {
@@ -0,0 +1,196 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// Operations of latency histograms and StatsObserver observations.
+const (
+	StatsOpPrepare = opPrepare // prepare of a statement
+	StatsOpExec    = opExec    // execution of a statement
+	StatsOpQuery   = opQuery   // execution of a query (without fetching further rows)
+	StatsOpFetch   = p.OpFetch // fetch of result set rows
+)
+
+var statsOps = [...]string{StatsOpPrepare, StatsOpExec, StatsOpQuery, StatsOpFetch}
+
+// HistogramBucket is a bucket of a Histogram.
+type HistogramBucket struct {
+	UpperBound time.Duration // upper bound (inclusive) of the bucket
+	Count      int64         // cumulative number of observations less or equal the upper bound
+}
+
+/*
+Histogram contains the latency distribution of a database operation. The bucket layout is fixed (upper bounds
+of powers of two microseconds) and the bucket counts are cumulative, so that a histogram can be exported directly
+as Prometheus histogram (e.g. via prometheus.MustNewConstHistogram).
+*/
+type Histogram struct {
+	Count    int64         // number of observations
+	NumError int64         // number of observations of failed operations
+	Sum      time.Duration // sum of the observed durations
+	Buckets  []HistogramBucket
+}
+
+// latencyMetric collects the latencies of a database operation.
+type latencyMetric struct {
+	count, numError int64
+	sum             time.Duration
+	histogram       latencyHistogram
+}
+
+func (m *latencyMetric) add(d time.Duration, err error) {
+	m.count++
+	if err != nil {
+		m.numError++
+	}
+	m.sum += d
+	m.histogram.add(d)
+}
+
+func (m *latencyMetric) snapshot() Histogram {
+	h := Histogram{Count: m.count, NumError: m.numError, Sum: m.sum, Buckets: make([]HistogramBucket, numLatencyBucket)}
+	var n int64
+	for i, c := range m.histogram {
+		n += c
+		h.Buckets[i] = HistogramBucket{UpperBound: time.Duration(uint64(1)<<uint(i)) * time.Microsecond, Count: n}
+	}
+	return h
+}
+
+/*
+DriverStats contains the statistics of database connections, either of the connections of a connector (see Connector.Stats)
+or of all connections of the driver (see function Stats).
+
+The statistics can be published via expvar, e.g.:
+
+	expvar.Publish("hdb", expvar.Func(func() interface{} { return driver.Stats() }))
+*/
+type DriverStats struct {
+	OpenConnections  int64 // number of open connections
+	NumConnect       int64 // number of connections opened
+	NumBadConn       int64 // number of connections closed because of a broken connection (driver.ErrBadConn)
+	OpenStatements   int64 // number of open prepared statements
+	OpenTransactions int64 // number of open transactions
+	BytesRead        uint64
+	BytesWritten     uint64
+	RoundTrips       uint64
+	// Latencies contains the latency histograms of the database operations by operation
+	// (StatsOpPrepare, StatsOpExec, StatsOpQuery and StatsOpFetch).
+	Latencies map[string]Histogram
+}
+
+/*
+StatsObserver is the interface implemented by types observing database operations (see Connector.SetStatsObserver),
+e.g. to feed histograms of a monitoring system with custom buckets or labels.
+
+Observe is called after each prepare, execution and fetch (see StatsOp constants) with the duration of the operation
+and the error returned by the operation. Observe is called while the connection is locked and must not block.
+*/
+type StatsObserver interface {
+	Observe(op string, d time.Duration, err error)
+}
+
+// driverMetrics counters.
+const (
+	cntOpenConn = iota
+	cntConnect
+	cntBadConn
+	cntOpenStmt
+	cntOpenTx
+	numCounter
+)
+
+// driverMetrics collects the statistics of connections aggregating the counters to parent (nil: no parent).
+type driverMetrics struct {
+	counters [numCounter]int64 // first to guarantee atomic alignment on 32-bit platforms
+
+	parent *driverMetrics
+
+	mu        sync.Mutex
+	latencies [len(statsOps)]latencyMetric
+}
+
+// drvMetrics and drvSessionStats are the statistics of all driver connections.
+var (
+	drvMetrics      = newDriverMetrics(nil)
+	drvSessionStats = p.NewSessionStats(nil)
+)
+
+func newDriverMetrics(parent *driverMetrics) *driverMetrics { return &driverMetrics{parent: parent} }
+
+func (m *driverMetrics) add(counter int, delta int64) {
+	for ; m != nil; m = m.parent {
+		atomic.AddInt64(&m.counters[counter], delta)
+	}
+}
+
+func (m *driverMetrics) connOpened() { m.add(cntOpenConn, 1); m.add(cntConnect, 1) }
+
+func (m *driverMetrics) connClosed(bad bool) {
+	m.add(cntOpenConn, -1)
+	if bad {
+		m.add(cntBadConn, 1)
+	}
+}
+
+func (m *driverMetrics) stmtOpened() { m.add(cntOpenStmt, 1) }
+func (m *driverMetrics) stmtClosed() { m.add(cntOpenStmt, -1) }
+func (m *driverMetrics) txOpened()   { m.add(cntOpenTx, 1) }
+func (m *driverMetrics) txClosed()   { m.add(cntOpenTx, -1) }
+
+func (m *driverMetrics) observe(op string, d time.Duration, err error) {
+	idx := -1
+	for i, statsOp := range statsOps {
+		if statsOp == op {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return
+	}
+	for ; m != nil; m = m.parent {
+		m.mu.Lock()
+		m.latencies[idx].add(d, err)
+		m.mu.Unlock()
+	}
+}
+
+func (m *driverMetrics) stats(sessionStats *p.SessionStats) DriverStats {
+	s := DriverStats{
+		OpenConnections:  atomic.LoadInt64(&m.counters[cntOpenConn]),
+		NumConnect:       atomic.LoadInt64(&m.counters[cntConnect]),
+		NumBadConn:       atomic.LoadInt64(&m.counters[cntBadConn]),
+		OpenStatements:   atomic.LoadInt64(&m.counters[cntOpenStmt]),
+		OpenTransactions: atomic.LoadInt64(&m.counters[cntOpenTx]),
+		BytesRead:        sessionStats.BytesRead(),
+		BytesWritten:     sessionStats.BytesWritten(),
+		RoundTrips:       sessionStats.RoundTrips(),
+		Latencies:        make(map[string]Histogram, len(statsOps)),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, op := range statsOps {
+		s.Latencies[op] = m.latencies[i].snapshot()
+	}
+	return s
+}
+
+// Stats returns the statistics of all connections opened by the driver (all connectors).
+func Stats() DriverStats { return drvMetrics.stats(drvSessionStats) }
+
+// observe records the duration d of operation op in the connection statistics and calls the stats observer.
+func (c *conn) observe(op string, d time.Duration, err error) {
+	c.metrics.observe(op, d, err)
+	if c.statsObserver != nil {
+		c.statsObserver.Observe(op, d, err)
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package migrate provides a database driver for schema migrations against HANA
+following the database driver contract of the golang-migrate tool (github.com/golang-migrate/migrate).
+
+The applied migration version and the dirty state are stored in the migrations table (default SCHEMA_MIGRATIONS).
+Concurrent migrations are serialized by an exclusive table lock on the lock table (default SCHEMA_MIGRATIONS_LOCK)
+held by a dedicated connection, so that the lock is released even if the migrating process terminates.
+Migration scripts may contain multiple statements separated by semicolons (see Driver.Run).
+
+As golang-migrate is not a dependency of go-hdb, the driver is registered by a small adapter
+in the application:
+
+	import (
+		"github.com/SAP/go-hdb/driver/migrate"
+		"github.com/golang-migrate/migrate/v4/database"
+	)
+
+	type hdbDriver struct{ *migrate.Driver }
+
+	func (d hdbDriver) Open(url string) (database.Driver, error) {
+		drv, err := d.Driver.Open(url)
+		if err != nil {
+			return nil, err
+		}
+		return hdbDriver{drv}, nil
+	}
+
+	func (d hdbDriver) Lock() error {
+		if err := d.Driver.Lock(); err == migrate.ErrLocked {
+			return database.ErrLocked
+		}
+		return err
+	}
+
+	func init() { database.Register("hdb", hdbDriver{&migrate.Driver{}}) }
+
+The url is a go-hdb data source name with the additional, driver specific parameters
+	x-migrations-table: name of the migrations table
+	x-lock-table:       name of the lock table
+	x-lock-timeout:     lock wait timeout in seconds (default: 0, fail immediately if locked)
+*/
+package migrate
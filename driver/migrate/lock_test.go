@@ -0,0 +1,69 @@
+// +build !unit
+
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+	"github.com/SAP/go-hdb/driver/migrate"
+)
+
+func TestLock(t *testing.T) {
+	dsn := os.Getenv(drivertest.EnvDSN)
+	if dsn == "" {
+		t.Skipf("no database dsn provided (environment variable %s)", drivertest.EnvDSN)
+	}
+	connector, err := driver.NewDSNConnector(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	schema, err := drivertest.CreateSchema(ctx, db, drivertest.SchemaPrefix, "migrate", drivertest.DropAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer schema.Cleanup(ctx, t.Failed())
+
+	config := &migrate.Config{SchemaName: schema.Name()}
+	d1, err := migrate.WithInstance(db, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d1.Close()
+	d2, err := migrate.WithInstance(db, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d2.Close()
+
+	if err := d1.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d2.Lock(); err != migrate.ErrLocked {
+		t.Fatalf("error %v - expected %v", err, migrate.ErrLocked)
+	}
+	if err := d1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d2.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d2.Unlock(); err != migrate.ErrNotLocked {
+		t.Fatalf("error %v - expected %v", err, migrate.ErrNotLocked)
+	}
+}
@@ -0,0 +1,338 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// Defaults.
+const (
+	DefaultMigrationsTable = "SCHEMA_MIGRATIONS"
+	DefaultLockTable       = "SCHEMA_MIGRATIONS_LOCK"
+)
+
+// NilVersion is the version of a database without applied migrations.
+const NilVersion = -1
+
+// Data source name parameters of Driver.Open.
+const (
+	ParamMigrationsTable = "x-migrations-table"
+	ParamLockTable       = "x-lock-table"
+	ParamLockTimeout     = "x-lock-timeout"
+)
+
+// HANA error codes.
+const (
+	errCodeLockWaitTimeout    = 131 // transaction rolled back by lock wait timeout
+	errCodeResourceBusy       = 146 // resource busy and NOWAIT specified
+	errCodeDuplicateTableName = 288 // cannot use duplicate table name
+)
+
+// Driver errors.
+var (
+	ErrLocked    = errors.New("migrate: database is locked")
+	ErrNotLocked = errors.New("migrate: database is not locked")
+	ErrNilConfig = errors.New("migrate: no config")
+)
+
+// Config is the migration driver configuration.
+type Config struct {
+	MigrationsTable string        // default: DefaultMigrationsTable
+	LockTable       string        // default: DefaultLockTable
+	SchemaName      string        // schema of the migration tables (default: current schema)
+	LockTimeout     time.Duration // time to wait for the lock (0: fail immediately if locked)
+}
+
+// Error is a failed statement of a migration script.
+type Error struct {
+	StmtNo int    // statement number in the script (starting with 1)
+	Stmt   string // statement text
+	Err    error  // database error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("migrate: statement %d failed: %s (statement: %s)", e.StmtNo, e.Err, e.Stmt)
+}
+
+// Unwrap returns the database error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// Driver is a golang-migrate compatible database driver (see package documentation).
+type Driver struct {
+	db     *sql.DB
+	ownsDB bool      // db was opened by Open and is closed by Close
+	conn   *sql.Conn // migration connection
+	config Config
+
+	lockConn *sql.Conn // connection holding the lock table lock
+	lockTx   *sql.Tx
+
+	scanner scanner.Scanner
+}
+
+// WithInstance returns a driver executing the migrations on db.
+// The migrations and the lock table are created if they do not exist.
+func WithInstance(db *sql.DB, config *Config) (*Driver, error) {
+	if config == nil {
+		return nil, ErrNilConfig
+	}
+	d := &Driver{db: db, config: *config}
+	if d.config.MigrationsTable == "" {
+		d.config.MigrationsTable = DefaultMigrationsTable
+	}
+	if d.config.LockTable == "" {
+		d.config.LockTable = DefaultLockTable
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.conn = conn
+	if err := d.ensureTables(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Open returns a new driver for the data source name dsn (see package documentation for the additional parameters).
+func (d *Driver) Open(dsn string) (*Driver, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	q := u.Query()
+	config.MigrationsTable = q.Get(ParamMigrationsTable)
+	config.LockTable = q.Get(ParamLockTable)
+	if s := q.Get(ParamLockTimeout); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid %s %s: %w", ParamLockTimeout, s, err)
+		}
+		config.LockTimeout = time.Duration(secs) * time.Second
+	}
+	// remove driver specific parameters not supported by the go-hdb connector
+	for k := range q {
+		if strings.HasPrefix(k, "x-") {
+			q.Del(k)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	connector, err := driver.NewDSNConnector(u.String())
+	if err != nil {
+		return nil, err
+	}
+	config.SchemaName = string(connector.DefaultSchema())
+
+	db := sql.OpenDB(connector)
+	drv, err := WithInstance(db, config)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	drv.ownsDB = true
+	return drv, nil
+}
+
+// Close releases a held lock and closes the driver connections.
+func (d *Driver) Close() error {
+	if d.lockTx != nil {
+		d.Unlock()
+	}
+	err := d.conn.Close()
+	if d.ownsDB {
+		if dbErr := d.db.Close(); err == nil {
+			err = dbErr
+		}
+	}
+	return err
+}
+
+func (d *Driver) qualify(table string) string {
+	return driver.Identifier(table).Qualify(driver.Identifier(d.config.SchemaName))
+}
+
+func isDBErrorCode(err error, codes ...int) bool {
+	var dbErr driver.Error
+	if !errors.As(err, &dbErr) {
+		return false
+	}
+	for _, code := range codes {
+		if dbErr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Driver) ensureTables(ctx context.Context) error {
+	for _, stmt := range []string{
+		fmt.Sprintf("create column table %s (version bigint not null primary key, dirty boolean not null)", d.qualify(d.config.MigrationsTable)),
+		fmt.Sprintf("create column table %s (id integer)", d.qualify(d.config.LockTable)),
+	} {
+		if _, err := d.conn.ExecContext(ctx, stmt); err != nil && !isDBErrorCode(err, errCodeDuplicateTableName) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lock acquires the exclusive migration lock. It fails with ErrLocked if the lock is held by another driver
+// and could not be acquired within the configured lock timeout.
+func (d *Driver) Lock() error {
+	if d.lockTx != nil {
+		return ErrLocked
+	}
+
+	ctx := context.Background()
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	wait := "nowait"
+	if secs := int(d.config.LockTimeout / time.Second); secs > 0 {
+		wait = fmt.Sprintf("wait %d", secs)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("lock table %s in exclusive mode %s", d.qualify(d.config.LockTable), wait)); err != nil {
+		tx.Rollback()
+		conn.Close()
+		if isDBErrorCode(err, errCodeResourceBusy, errCodeLockWaitTimeout) {
+			return ErrLocked
+		}
+		return err
+	}
+	d.lockConn, d.lockTx = conn, tx
+	return nil
+}
+
+// Unlock releases the migration lock.
+func (d *Driver) Unlock() error {
+	if d.lockTx == nil {
+		return ErrNotLocked
+	}
+	err := d.lockTx.Rollback()
+	if connErr := d.lockConn.Close(); err == nil {
+		err = connErr
+	}
+	d.lockConn, d.lockTx = nil, nil
+	return err
+}
+
+// Run executes the migration script read from migration. The script is split into its
+// statements (separated by semicolons) which are executed one by one. As HANA ddl statements
+// commit implicitly, the statements executed before a failing statement are not rolled back.
+func (d *Driver) Run(migration io.Reader) error {
+	b, err := ioutil.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	stmts, err := d.scanner.Split(string(b))
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	for i, stmt := range stmts {
+		if _, err := d.conn.ExecContext(ctx, stmt); err != nil {
+			return &Error{StmtNo: i + 1, Stmt: stmt, Err: err}
+		}
+	}
+	return nil
+}
+
+// SetVersion stores the migration version and the dirty state. The version NilVersion
+// without dirty state removes the version information.
+func (d *Driver) SetVersion(version int, dirty bool) error {
+	ctx := context.Background()
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op after commit
+
+	table := d.qualify(d.config.MigrationsTable)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("delete from %s", table)); err != nil {
+		return err
+	}
+	if version >= 0 || (version == NilVersion && dirty) {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("insert into %s values (?, ?)", table), version, dirty); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Version returns the stored migration version and dirty state or NilVersion if no version is stored.
+func (d *Driver) Version() (version int, dirty bool, err error) {
+	err = d.conn.QueryRowContext(context.Background(), fmt.Sprintf("select version, dirty from %s", d.qualify(d.config.MigrationsTable))).Scan(&version, &dirty)
+	switch {
+	case err == sql.ErrNoRows:
+		return NilVersion, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
+
+// Drop drops all tables of the migration schema except the lock table and recreates the (empty) migrations table.
+func (d *Driver) Drop() error {
+	ctx := context.Background()
+
+	query := "select table_name from sys.tables where schema_name = current_schema and is_user_defined_type = 'FALSE'"
+	var args []interface{}
+	if d.config.SchemaName != "" {
+		query = "select table_name from sys.tables where schema_name = ? and is_user_defined_type = 'FALSE'"
+		args = append(args, d.config.SchemaName)
+	}
+	rows, err := d.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		if table != d.config.LockTable {
+			tables = append(tables, table)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err := d.conn.ExecContext(ctx, fmt.Sprintf("drop table %s cascade", d.qualify(table))); err != nil {
+			return err
+		}
+	}
+	return d.ensureTables(ctx)
+}
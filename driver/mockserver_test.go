@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func testMockQuery(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("select id, name from persons", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}, {Name: "NAME", TypeName: "NVARCHAR"}},
+		Rows:    [][]interface{}{{int32(1), "Alice"}, {int32(2), nil}, {int32(3), "Carol"}},
+	})
+
+	rows, err := db.Query("select id, name from persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type person struct {
+		id   int
+		name sql.NullString
+	}
+	var persons []person
+	for rows.Next() {
+		var p person
+		if err := rows.Scan(&p.id, &p.name); err != nil {
+			t.Fatal(err)
+		}
+		persons = append(persons, p)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	exp := []person{{1, sql.NullString{String: "Alice", Valid: true}}, {2, sql.NullString{}}, {3, sql.NullString{String: "Carol", Valid: true}}}
+	if !reflect.DeepEqual(persons, exp) {
+		t.Fatalf("persons %v - expected %v", persons, exp)
+	}
+}
+
+func testMockFetch(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	const numRow = 100
+
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i)}
+	}
+	s.Handle("select i from numbers", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "I", TypeName: "BIGINT"}}, Rows: rows})
+
+	r, err := db.Query("select i from numbers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	cnt := 0
+	for r.Next() {
+		var i int64
+		if err := r.Scan(&i); err != nil {
+			t.Fatal(err)
+		}
+		if i != int64(cnt) {
+			t.Fatalf("value %d - expected %d", i, cnt)
+		}
+		cnt++
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if cnt != numRow {
+		t.Fatalf("rows %d - expected %d", cnt, numRow)
+	}
+}
+
+func testMockExec(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	var args []interface{}
+	s.Handle("insert into persons values (?, ?)", &drivertest.MockStatement{
+		Params: []string{"INTEGER", "NVARCHAR"},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			args = a
+			return &drivertest.MockResult{RowsAffected: 1}, nil
+		},
+	})
+
+	result, err := db.Exec("insert into persons values (?, ?)", 4, "Dave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rowsAffected != 1 {
+		t.Fatalf("rows affected %d - expected %d", rowsAffected, 1)
+	}
+	exp := []interface{}{int64(4), "Dave"}
+	if !reflect.DeepEqual(args, exp) {
+		t.Fatalf("args %v - expected %v", args, exp)
+	}
+}
+
+func testMockNamed(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	var args []interface{}
+	s.Handle("update persons set name = ? where id = ? or parent = ?", &drivertest.MockStatement{
+		Params: []string{"NVARCHAR", "INTEGER", "INTEGER"},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			args = a
+			return &drivertest.MockResult{RowsAffected: 2}, nil
+		},
+	})
+
+	query := "update persons set name = :name where id = :id or parent = :id"
+	if _, err := db.Exec(query, sql.Named("id", 4), sql.Named("name", "Dave")); err != nil {
+		t.Fatal(err)
+	}
+	exp := []interface{}{"Dave", int64(4), int64(4)}
+	if !reflect.DeepEqual(args, exp) {
+		t.Fatalf("args %v - expected %v", args, exp)
+	}
+
+	for _, args := range [][]interface{}{
+		{sql.Named("id", 4)},                                 // missing argument
+		{sql.Named("id", 4), "Dave"},                         // positional argument
+		{sql.Named("id", 4), sql.Named("firstname", "Dave")}, // unknown parameter
+	} {
+		if _, err := db.Exec(query, args...); err == nil {
+			t.Fatalf("args %v: error expected", args)
+		}
+	}
+	if _, err := db.Exec("update persons set name = :name where id = ?", sql.Named("name", "Dave"), 4); !errors.Is(err, driver.ErrMixedParameters) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrMixedParameters)
+	}
+}
+
+func testMockSliceExpansion(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	var args []interface{}
+	s.Handle("select name from persons where id in (?, ?, ?, ?)", &drivertest.MockStatement{
+		Params:  []string{"INTEGER", "INTEGER", "INTEGER", "INTEGER"},
+		Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			args = a
+			return &drivertest.MockResult{Rows: [][]interface{}{{"Alice"}, {"Carol"}}}, nil
+		},
+	})
+
+	rows, err := db.Query("select name from persons where id in (?)", []int{1, 3, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnt := 0
+	for rows.Next() {
+		cnt++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if cnt != 2 {
+		t.Fatalf("number of rows %d - expected %d", cnt, 2)
+	}
+	exp := []interface{}{int64(1), int64(3), int64(5), int64(5)}
+	if !reflect.DeepEqual(args, exp) {
+		t.Fatalf("args %v - expected %v", args, exp)
+	}
+}
+
+func testMockStatementInfo(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("delete from persons where id = ?", &drivertest.MockStatement{Params: []string{"INTEGER"}})
+	s.Handle("select plan_id from sys.m_prepared_statements where statement_id = ?", &drivertest.MockStatement{
+		Params:  []string{"BIGINT"},
+		Columns: []drivertest.MockColumn{{Name: "PLAN_ID", TypeName: "BIGINT"}},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			return &drivertest.MockResult{Rows: [][]interface{}{{a[0].(int64) + 1000}}}, nil
+		},
+	})
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stmt, err := conn.PrepareContext(ctx, "delete from persons where id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	info := new(driver.StatementInfo)
+	if _, err := stmt.ExecContext(driver.WithStatementInfo(ctx, info), 1); err != nil {
+		t.Fatal(err)
+	}
+	if info.StatementID == 0 {
+		t.Fatal("statement id not set")
+	}
+	planID, err := driver.PlanID(ctx, conn, info.StatementID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planID != int64(info.StatementID)+1000 {
+		t.Fatalf("plan id %d - expected %d", planID, int64(info.StatementID)+1000)
+	}
+
+	// direct execution
+	info = &driver.StatementInfo{StatementID: 42}
+	if _, err := conn.ExecContext(driver.WithStatementInfo(ctx, info), "delete from persons where id = 1"); err == nil {
+		t.Fatal("error expected") // statement is not handled by the mock server
+	}
+	if info.StatementID != 0 {
+		t.Fatalf("statement id %d - expected %d", info.StatementID, 0)
+	}
+}
+
+func testMockError(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("delete from persons", &drivertest.MockStatement{Err: &drivertest.MockError{Code: 259, Text: "invalid table name"}})
+
+	_, err := db.Exec("delete from persons")
+	dbErr, ok := err.(driver.Error)
+	if !ok {
+		t.Fatalf("error %v - expected driver.Error", err)
+	}
+	if dbErr.Code() != 259 {
+		t.Fatalf("error code %d - expected %d", dbErr.Code(), 259)
+	}
+	if errors.Is(err, driver.ErrQueryTimeout) || errors.Is(err, driver.ErrLockTimeout) || errors.Is(err, driver.ErrCanceled) {
+		t.Fatalf("error %v - unexpected aborted execution error", err)
+	}
+	var stateErr driver.SQLStateError
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("error %v - expected driver.SQLStateError", err)
+	}
+	if stateErr.SQLState() != "HY000" || dbErr.Position() != 0 || dbErr.Level() != driver.HdbError || !dbErr.IsError() {
+		t.Fatalf("error %v - unexpected sql state %s position %d level %d", err, stateErr.SQLState(), dbErr.Position(), dbErr.Level())
+	}
+
+	s.Handle("insert into persons values (?)", &drivertest.MockStatement{Err: &drivertest.MockError{Code: driver.HdbErrCodeUniqueConstraint, Position: 12, SQLState: "23000", Text: "unique constraint violated"}})
+
+	_, err = db.Exec("insert into persons values (?)", "Alice")
+	if !errors.As(err, &dbErr) || !errors.As(err, &stateErr) {
+		t.Fatalf("error %v - expected driver.Error and driver.SQLStateError", err)
+	}
+	if dbErr.Code() != driver.HdbErrCodeUniqueConstraint || stateErr.SQLState() != "23000" || dbErr.Position() != 12 || dbErr.Text() != "unique constraint violated" {
+		t.Fatalf("error %v - unexpected code %d sql state %s position %d", err, dbErr.Code(), stateErr.SQLState(), dbErr.Position())
+	}
+
+	s.Handle("update persons set age = 1", &drivertest.MockStatement{Err: &drivertest.MockError{Code: 131, Text: "transaction rolled back by lock wait timeout"}})
+	s.Handle("update persons set age = 2", &drivertest.MockStatement{Err: &drivertest.MockError{Code: 139, Text: "current operation cancelled by request and transaction rolled back"}})
+
+	if _, err := db.Exec("update persons set age = 1"); !errors.Is(err, driver.ErrLockTimeout) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrLockTimeout)
+	}
+	if _, err := db.Exec("update persons set age = 2"); !errors.Is(err, driver.ErrCanceled) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrCanceled)
+	}
+
+	if _, err := db.Exec("drop table unknown"); err == nil {
+		t.Fatal("error expected for unknown statement")
+	}
+}
+
+func testMockDelay(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("call slow", &drivertest.MockStatement{Delay: 500 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, "call slow")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v - expected %v", err, context.DeadlineExceeded)
+	}
+	if !errors.Is(err, driver.ErrQueryTimeout) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrQueryTimeout)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err = db.ExecContext(ctx, "call slow")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v - expected %v", err, context.Canceled)
+	}
+	if !errors.Is(err, driver.ErrCanceled) || errors.Is(err, driver.ErrQueryTimeout) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrCanceled)
+	}
+}
+
+func testMockDisconnect(t *testing.T, s *drivertest.MockServer, db *sql.DB) {
+	s.Handle("call crash", &drivertest.MockStatement{Disconnect: true})
+
+	if _, err := db.Exec("call crash"); err == nil {
+		t.Fatal("error expected for server disconnect")
+	}
+	// pool recovers with a new connection
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMockServer(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	tests := []struct {
+		name string
+		fct  func(t *testing.T, s *drivertest.MockServer, db *sql.DB)
+	}{
+		{"query", testMockQuery},
+		{"fetch", testMockFetch},
+		{"exec", testMockExec},
+		{"named", testMockNamed},
+		{"sliceExpansion", testMockSliceExpansion},
+		{"statementInfo", testMockStatementInfo},
+		{"error", testMockError},
+		{"delay", testMockDelay},
+		{"disconnect", testMockDisconnect},
+	}
+
+	// strict protocol validation: mock server replies need to be valid
+	for _, strict := range []bool{false, true} {
+		connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+		connector.SetStrictProtocol(strict)
+		connector.SetSliceExpansion(true)
+		db := sql.OpenDB(connector)
+
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%s strict %t", test.name, strict), func(t *testing.T) {
+				test.fct(t, s, db)
+			})
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// ErrMixedParameters is the error raised if a query uses positional (?) and named (:name) parameters.
+var ErrMixedParameters = p.ErrMixedParameters
+
+/*
+namedParams are the parameter names of a query using named parameters (:name) in positional order.
+
+Queries using named parameters are rewritten to positional parameters before prepare and the
+arguments (sql.Named) are bound to the positional parameters by name:
+
+	db.Exec("update persons set name = :name where id = :id", sql.Named("id", 42), sql.Named("name", "Jane"))
+*/
+type namedParams []string
+
+// index returns the position of the first occurrence of parameter name or -1 if name is not a parameter.
+func (np namedParams) index(name string) int {
+	for i, param := range np {
+		if param == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// check checks the named argument nv and returns the position of the parameter the argument is bound to.
+func (np namedParams) check(nv *driver.NamedValue) (int, error) {
+	if nv.Name == "" {
+		return -1, fmt.Errorf("positional argument %d in query with named parameters", nv.Ordinal)
+	}
+	idx := np.index(nv.Name)
+	if idx == -1 {
+		return -1, fmt.Errorf("unknown named parameter %s", nv.Name)
+	}
+	return idx, nil
+}
+
+// bind returns the arguments in positional order.
+func (np namedParams) bind(args []driver.NamedValue) ([]driver.NamedValue, error) {
+	if len(np) == 0 || len(args) == 0 { // no named parameters or bulk control
+		return args, nil
+	}
+	values := make(map[string]driver.Value, len(args))
+	for _, arg := range args {
+		if _, err := np.check(&arg); err != nil {
+			return nil, err
+		}
+		values[arg.Name] = arg.Value
+	}
+	bound := make([]driver.NamedValue, len(np))
+	for i, param := range np {
+		v, ok := values[param]
+		if !ok {
+			return nil, fmt.Errorf("missing argument for named parameter %s", param)
+		}
+		bound[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return bound, nil
+}
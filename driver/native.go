@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+/*
+NativeConn is a low-level database connection bypassing database/sql.
+
+It is intended for applications like proxies or ETL tools needing full control over the statement
+lifecycle (prepare, execute, fetch and close) and avoiding the conversions and allocations of database/sql.
+Query results are returned as driver.Rows, so that values are read into a reusable []driver.Value
+slice with the driver native types (e.g. int64, float64, time.Time and []byte for character and binary types).
+Decimal values are converted via Decimal.Scan, lob values are read via Lob.Scan or NullLob.Scan.
+
+Connection pooling, retries on bad connections and the session reset of database/sql are not provided,
+i.e. a NativeConn returning driver.ErrBadConn needs to be closed and reopened by the application.
+A NativeConn must not be used concurrently.
+
+The connections of a database/sql pool provide a NativeConn via Conn.Native (see sql.Conn.Raw).
+*/
+type NativeConn struct {
+	conn *conn
+}
+
+// NativeConn opens a new low-level database connection.
+func (c *Connector) NativeConn(ctx context.Context) (*NativeConn, error) {
+	dc, err := newConn(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeConn{conn: dc.(*conn)}, nil
+}
+
+// Native implements the Conn interface.
+func (c *conn) Native() *NativeConn { return &NativeConn{conn: c} }
+
+// SessionID implements the Conn interface.
+func (c *conn) SessionID() int64 { return c.session.ID() }
+
+// Close closes the connection.
+func (c *NativeConn) Close() error { return c.conn.Close() }
+
+// SessionID returns the database session id of the connection.
+func (c *NativeConn) SessionID() int64 { return c.conn.SessionID() }
+
+// Ping verifies that the connection is still alive.
+func (c *NativeConn) Ping(ctx context.Context) error { return c.conn.Ping(ctx) }
+
+// Begin starts a transaction.
+func (c *NativeConn) Begin(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.conn.BeginTx(ctx, opts)
+}
+
+// Prepare prepares the statement query.
+func (c *NativeConn) Prepare(ctx context.Context, query string) (*NativeStmt, error) {
+	ds, err := c.conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &NativeStmt{stmt: ds.(*stmt)}, nil
+}
+
+// Exec executes the statement query. Statements without arguments are executed directly without prepare.
+func (c *NativeConn) Exec(ctx context.Context, query string, args ...interface{}) (driver.Result, error) {
+	if len(args) == 0 {
+		r, err := c.conn.ExecContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return r, err
+		}
+	}
+	s, err := c.Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.Exec(ctx, args...)
+}
+
+// Query executes the query. Queries without arguments are executed directly without prepare.
+// The returned rows need to be closed before the next statement is executed on the connection.
+func (c *NativeConn) Query(ctx context.Context, query string, args ...interface{}) (driver.Rows, error) {
+	if len(args) == 0 {
+		rows, err := c.conn.QueryContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return rows, err
+		}
+	}
+	s, err := c.Prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.Query(ctx, args...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return newRows(rows, func(numRow int64) { s.Close() }), nil
+}
+
+// NativeStmt is a prepared statement of a NativeConn.
+type NativeStmt struct {
+	stmt *stmt
+}
+
+// Close closes the statement.
+func (s *NativeStmt) Close() error { return s.stmt.Close() }
+
+// NumInput returns the number of statement parameters.
+func (s *NativeStmt) NumInput() int { return s.stmt.pr.NumField() }
+
+// StatementID returns the server statement id of the prepared statement.
+func (s *NativeStmt) StatementID() uint64 { return s.stmt.pr.StmtID() }
+
+// namedValues converts the arguments like database/sql does for driver statements.
+// Arguments might be sql.NamedArg values for statements with named parameters.
+func (s *NativeStmt) namedValues(args []interface{}) ([]driver.NamedValue, error) {
+	if s.stmt.params == nil && len(args) > s.stmt.pr.NumField() {
+		return nil, fmt.Errorf("invalid number of arguments %d - %d expected", len(args), s.stmt.pr.NumField())
+	}
+	nvs := make([]driver.NamedValue, 0, len(args))
+	for i, arg := range args {
+		nv := driver.NamedValue{Ordinal: i + 1, Value: arg}
+		if na, ok := arg.(sql.NamedArg); ok {
+			nv.Name, nv.Value = na.Name, na.Value
+		}
+		switch err := s.stmt.CheckNamedValue(&nv); err {
+		case nil:
+			nvs = append(nvs, nv)
+		case driver.ErrRemoveArgument:
+		default:
+			return nil, err
+		}
+	}
+	return nvs, nil
+}
+
+// Exec executes the statement with the arguments args.
+func (s *NativeStmt) Exec(ctx context.Context, args ...interface{}) (driver.Result, error) {
+	nvs, err := s.namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.stmt.ExecContext(ctx, nvs)
+}
+
+// Query executes the query statement with the arguments args.
+func (s *NativeStmt) Query(ctx context.Context, args ...interface{}) (driver.Rows, error) {
+	nvs, err := s.namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.stmt.QueryContext(ctx, nvs)
+}
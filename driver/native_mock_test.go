@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockDirect(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("select i from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "I", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}, {int32(2)}}})
+	s.Handle("delete from t", &drivertest.MockStatement{RowsAffected: 2})
+
+	hook := &stmtCacheHook{}
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetHooks(hook); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	conn := hook.conn
+
+	if conn.SessionID() == 0 {
+		t.Fatal("session id expected")
+	}
+
+	ctx := context.Background()
+	rows, err := conn.Native().Query(ctx, "select i from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []int64
+	dest := make([]sqldriver.Value, 1)
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+		values = append(values, dest[0].(int64))
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Fatalf("values %v - expected %v", values, []int64{1, 2})
+	}
+
+	r, err := conn.Native().Exec(ctx, "delete from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.RowsAffected(); err != nil || n != 2 {
+		t.Fatalf("rows affected %d error %v - expected %d", n, err, 2)
+	}
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestNativeConn(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	columns := []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}, {Name: "NAME", TypeName: "NVARCHAR"}}
+	rows := [][]interface{}{{int32(1), "Alice"}, {int32(2), "Bob"}}
+	s.Handle("select id, name from persons", &drivertest.MockStatement{Columns: columns, Rows: rows})
+	s.Handle("select id, name from persons where id > ?", &drivertest.MockStatement{Params: []string{"INTEGER"}, Columns: columns, Rows: rows[1:]})
+	var args []interface{}
+	s.Handle("insert into persons values (?, ?)", &drivertest.MockStatement{
+		Params: []string{"INTEGER", "NVARCHAR"},
+		Func: func(a []interface{}) (*drivertest.MockResult, error) {
+			args = a
+			return &drivertest.MockResult{RowsAffected: 1}, nil
+		},
+	})
+
+	ctx := context.Background()
+	conn, err := NewBasicAuthConnector(s.Host(), "user", "password").NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	readRows := func(rows driver.Rows) [][]driver.Value {
+		defer rows.Close()
+		var result [][]driver.Value
+		for {
+			dest := make([]driver.Value, len(rows.Columns()))
+			if err := rows.Next(dest); err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				return result
+			}
+			result = append(result, dest)
+		}
+	}
+
+	// direct query
+	dr, err := conn.Query(ctx, "select id, name from persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := [][]driver.Value{{int64(1), []byte("Alice")}, {int64(2), []byte("Bob")}}
+	if result := readRows(dr); !reflect.DeepEqual(result, exp) {
+		t.Fatalf("rows %v - expected %v", result, exp)
+	}
+
+	// prepared query
+	dr, err = conn.Query(ctx, "select id, name from persons where id > ?", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := readRows(dr); !reflect.DeepEqual(result, exp[1:]) {
+		t.Fatalf("rows %v - expected %v", result, exp[1:])
+	}
+
+	// prepared statement
+	stmt, err := conn.Prepare(ctx, "insert into persons values (?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if stmt.NumInput() != 2 {
+		t.Fatalf("num input %d - expected %d", stmt.NumInput(), 2)
+	}
+	r, err := stmt.Exec(ctx, 3, "Carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := r.RowsAffected(); n != 1 {
+		t.Fatalf("rows affected %d - expected %d", n, 1)
+	}
+	if exp := []interface{}{int64(3), "Carol"}; !reflect.DeepEqual(args, exp) {
+		t.Fatalf("args %v - expected %v", args, exp)
+	}
+	if _, err := stmt.Exec(ctx, 4, "Dave", "too many"); err == nil {
+		t.Fatal("error expected")
+	}
+}
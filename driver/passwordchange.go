@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+/*
+PasswordChangeHandler returns the new password of user username, if the database server requires the password
+to be changed when a connection is opened (e.g. initial password or expired password, see SetPasswordChangeHandler).
+
+The handler is called with the context of the connection request. Returning an error aborts the connection attempt.
+*/
+type PasswordChangeHandler = p.PasswordChangeHandler
+
+/*
+newSession opens a database session with the connector snapshot ctr. A password changed when the session is opened
+is stored in ctr and in the connector ctr was taken from, so that reconnects of the connection as well as
+subsequent connections are authenticated with the new password.
+*/
+func newSession(ctx context.Context, ctr *Connector) (*p.Session, error) {
+	session, err := p.NewSession(ctx, ctr)
+	if err != nil {
+		return nil, err
+	}
+	if password, ok := session.ChangedPassword(); ok {
+		ctr.setPassword(password)
+	}
+	return session, nil
+}
+
+// setPassword sets the password of the connector and of the connector the snapshot c was taken from.
+func (c *Connector) setPassword(password string) {
+	c.mu.Lock()
+	c.password = password
+	c.mu.Unlock()
+	if c.origin != nil {
+		c.origin.setPassword(password)
+	}
+}
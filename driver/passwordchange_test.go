@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockPasswordChange(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.SetCredentials("user", "initial")
+	s.SetPasswordChangeRequired(true)
+
+	// no handler
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "initial")
+	db := sql.OpenDB(connector)
+	err := db.Ping()
+	db.Close()
+	if !errors.Is(err, driver.ErrPasswordChangeRequired) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrPasswordChangeRequired)
+	}
+
+	// failing handler
+	errHandler := errors.New("no password")
+	if err := connector.SetPasswordChangeHandler(func(ctx context.Context, username string) (string, error) { return "", errHandler }); err != nil {
+		t.Fatal(err)
+	}
+	db = sql.OpenDB(connector)
+	err = db.Ping()
+	db.Close()
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("error %v - expected %v", err, errHandler)
+	}
+
+	// password rejected by the server
+	if err := connector.SetPasswordChangeHandler(func(ctx context.Context, username string) (string, error) { return "initial", nil }); err != nil {
+		t.Fatal(err)
+	}
+	db = sql.OpenDB(connector)
+	err = db.Ping()
+	db.Close()
+	var dbErr driver.Error
+	if !errors.As(err, &dbErr) || dbErr.Code() != drivertest.ErrorCodePasswordReuse {
+		t.Fatalf("error %v - expected error code %d", err, drivertest.ErrorCodePasswordReuse)
+	}
+	if connector.Password() != "initial" { // password is stored only after the password change succeeded
+		t.Fatalf("connector password %s - expected %s", connector.Password(), "initial")
+	}
+
+	// password change
+	var calls int
+	if err := connector.SetPasswordChangeHandler(func(ctx context.Context, username string) (string, error) {
+		calls++
+		if username != "user" {
+			return "", fmt.Errorf("username %s - expected user", username)
+		}
+		return `new "secret"`, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	connector.SetRetryIdempotent(true)
+	if err := connector.SetRetryPolicy(driver.RetryPolicy{MaxAttempts: 1}); err != nil {
+		t.Fatal(err)
+	}
+	var numExec int32
+	s.Handle("select id from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		if atomic.AddInt32(&numExec, 1) == 1 {
+			return nil, drivertest.ErrMockDisconnect
+		}
+		return &drivertest.MockResult{Rows: [][]interface{}{{int32(1)}}}, nil
+	}})
+	db = sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxIdleConns(0) // open a new connection for each statement
+	// the reconnect of the connection changing the password is authenticated with the new password
+	var id int
+	if err := db.QueryRow("select id from t").Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("handler calls %d - expected %d", calls, 1)
+	}
+	if s.Password() != `new "secret"` {
+		t.Fatalf("server password %s - expected %s", s.Password(), `new "secret"`)
+	}
+	if connector.Password() != `new "secret"` {
+		t.Fatalf("connector password %s - expected %s", connector.Password(), `new "secret"`)
+	}
+}
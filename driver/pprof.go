@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"hash/fnv"
+	"runtime/pprof"
+	"strconv"
+)
+
+// pprof label keys.
+const (
+	PprofLabelOp   = "hdb.op"   // operation type (ping, prepare, begin, query, exec, dbconnectinfo, batch)
+	PprofLabelStmt = "hdb.stmt" // statement hash (hexadecimal fnv-1a 64 bit hash value of the sql statement)
+)
+
+// pprof operation types.
+const (
+	opPing          = "ping"
+	opPrepare       = "prepare"
+	opBegin         = "begin"
+	opQuery         = "query"
+	opExec          = "exec"
+	opDBConnectInfo = "dbconnectinfo"
+	opBatch         = "batch"
+)
+
+// StmtHash returns the hash value of a sql statement used as pprof statement label value.
+func StmtHash(query string) string {
+	h := fnv.New64a()
+	h.Write([]byte(query))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func pprofLabels(op, query string) pprof.LabelSet {
+	if query == "" {
+		return pprof.Labels(PprofLabelOp, op)
+	}
+	return pprof.Labels(PprofLabelOp, op, PprofLabelStmt, StmtHash(query))
+}
@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prottrace_test
+
+import (
+	"github.com/SAP/go-hdb/driver/prottrace"
+)
+
+func Example() {
+	prottrace.SetOn(true)  // set protocol trace output active
+	prottrace.SetOn(false) // set protocol trace output inactive
+}
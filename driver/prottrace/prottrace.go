@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package prottrace implements the driver protocol trace functions.
+
+The protocol trace logs every message, segment and part exchanged with the database
+including message types, sizes and request-reply roundtrip timings. It is meant for
+analyzing protocol level issues (e.g. 'error while parsing protocol') and can be activated
+- globally (flag hdb.protocol.trace or SetOn) or
+- per connector (see driver.Connector.SetProtocolTrace).
+*/
+package prottrace
+
+import (
+	"github.com/SAP/go-hdb/driver/dlog"
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// On returns if the protocol trace is active for all connections.
+func On() bool { return p.Trace() }
+
+// SetOn sets the protocol trace for all connections active or inactive.
+// The setting takes effect for open connections as well.
+func SetOn(on bool) { p.SetTrace(on) }
+
+// Logger returns the default protocol trace logger.
+func Logger() dlog.Logger { return p.TraceLogger() }
+
+// SetLogger sets the default protocol trace logger used for connections without connector specific logger.
+// Setting nil restores the standard logger writing to os.Stdout.
+func SetLogger(logger dlog.Logger) { p.SetTraceLogger(logger) }
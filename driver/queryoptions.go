@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/hdbsql"
+)
+
+// ErrReadOnlyIntent is returned for statements modifying data executed with read-only intent (see QueryOptions)
+// or on read-only connections (see Connector.SetReadOnly).
+var ErrReadOnlyIntent = errors.New("statement is not read-only")
+
+/*
+QueryOptions are request scoped options of a statement execution. They are passed via the context
+of the database/sql methods (see WithQueryOptions) and apply to the statements executed with this context:
+
+	ctx := driver.WithQueryOptions(ctx, driver.QueryOptions{FetchSize: 1000, Timeout: 10 * time.Second})
+	rows, err := db.QueryContext(ctx, "select * from t")
+
+As for the cancellation of the context, the connection of a timed out request becomes a bad connection.
+The routing hint is added to the statement hints (see WithHints) when the statement is prepared or
+executed directly, i.e. it is not applied to executions of an already prepared statement.
+*/
+type QueryOptions struct {
+	TraceTag      string        // tag added to the sql trace entries of the statement
+	FetchSize     int           // fetch size of the statement result sets (0: connector fetch size)
+	RoutingHint   string        // statement routing hint like ROUTE_TO(2) or ROUTE_BY(t)
+	Timeout       time.Duration // timeout of each database request of the statement execution (0: no timeout)
+	ServerTimeout time.Duration // server side statement timeout (0: connector statement timeout, < 0: no timeout)
+	ReadOnly      bool          // fail with ErrReadOnlyIntent for statements which are not read-only queries
+	Idempotent    bool          // statement can be retried on a broken connection (see Connector.SetRetryIdempotent)
+}
+
+type queryOptionsCtxKey struct{}
+
+// WithQueryOptions returns a context with the query options opts.
+func WithQueryOptions(ctx context.Context, opts QueryOptions) context.Context {
+	return context.WithValue(ctx, queryOptionsCtxKey{}, opts)
+}
+
+// QueryOptionsFromContext returns the query options of ctx.
+func QueryOptionsFromContext(ctx context.Context) (QueryOptions, bool) {
+	opts, ok := ctx.Value(queryOptionsCtxKey{}).(QueryOptions)
+	return opts, ok
+}
+
+// applyQueryOptions checks the read-only intent and adds the routing hint and the context hints (see WithHints) to query.
+// Write statements executed on read-only connections (see Connector.SetReadOnly) fail with ErrReadOnlyIntent as well.
+func (c *conn) applyQueryOptions(ctx context.Context, query string) (string, error) {
+	if c.readOnly && isWriteStatement(query) {
+		return query, ErrReadOnlyIntent
+	}
+	opts, ok := QueryOptionsFromContext(ctx)
+	if !ok {
+		return c.applyHints(ctx, query)
+	}
+	if opts.ReadOnly {
+		class, err := hdbsql.Classify(query)
+		if err != nil {
+			return query, err
+		}
+		if !class.ReadOnly() {
+			return query, ErrReadOnlyIntent
+		}
+	}
+	hints := HintsFromContext(ctx)
+	if opts.RoutingHint != "" {
+		hints = append(hints[:len(hints):len(hints)], opts.RoutingHint)
+	}
+	if len(hints) == 0 {
+		return query, nil
+	}
+	return addHints(c.scanner, query, hints, false)
+}
+
+// traceKeyvals returns the sql trace key value pairs of the current statement execution followed by keyvals.
+func (c *conn) traceKeyvals(ctx context.Context, keyvals ...interface{}) []interface{} {
+	kvs := append(make([]interface{}, 0, 6+len(keyvals)), "connID", c.session.ID(), "corrID", c.session.CorrelationID())
+	if opts, ok := QueryOptionsFromContext(ctx); ok && opts.TraceTag != "" {
+		kvs = append(kvs, "tag", opts.TraceTag)
+	}
+	return append(kvs, keyvals...)
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestQueryOptions(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const numRow = 100
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i)}
+	}
+	s.Handle("select i from numbers", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "I", TypeName: "BIGINT"}}, Rows: rows})
+	s.Handle("select i from numbers with hint (route_to(2))", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "I", TypeName: "BIGINT"}}, Rows: rows[:1]})
+	s.Handle("delete from numbers", &drivertest.MockStatement{})
+	s.Handle("call slow", &drivertest.MockStatement{Delay: 500 * time.Millisecond})
+
+	connector := NewBasicAuthConnector(s.Host(), "user", "password")
+	ctx := context.Background()
+
+	query := func(ctx context.Context, conn *NativeConn, query string) (int, uint64) {
+		start := conn.conn.Stats().RoundTrips
+		rows, err := conn.Query(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		cnt := 0
+		dest := make([]driver.Value, 1)
+		for {
+			if err := rows.Next(dest); err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				return cnt, conn.conn.Stats().RoundTrips - start
+			}
+			cnt++
+		}
+	}
+
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// fetch size
+	_, defaultRoundTrips := query(ctx, conn, "select i from numbers")
+	cnt, roundTrips := query(WithQueryOptions(ctx, QueryOptions{FetchSize: 10}), conn, "select i from numbers")
+	if cnt != numRow {
+		t.Fatalf("rows %d - expected %d", cnt, numRow)
+	}
+	if roundTrips-defaultRoundTrips != 6 { // remaining 68 rows: 7 instead of 1 fetch
+		t.Fatalf("round trips %d - expected %d", roundTrips, defaultRoundTrips+6)
+	}
+
+	// routing hint
+	if cnt, _ := query(WithQueryOptions(ctx, QueryOptions{RoutingHint: "ROUTE_TO(2)"}), conn, "select i from numbers"); cnt != 1 {
+		t.Fatalf("rows %d - expected %d", cnt, 1)
+	}
+
+	// read-only intent
+	roCtx := WithQueryOptions(ctx, QueryOptions{ReadOnly: true})
+	if cnt, _ := query(roCtx, conn, "select i from numbers"); cnt != numRow {
+		t.Fatalf("rows %d - expected %d", cnt, numRow)
+	}
+	if _, err := conn.Exec(roCtx, "delete from numbers"); err != ErrReadOnlyIntent {
+		t.Fatalf("error %v - expected %v", err, ErrReadOnlyIntent)
+	}
+	if _, err := conn.Exec(ctx, "delete from numbers"); err != nil {
+		t.Fatal(err)
+	}
+
+	// timeout
+	if _, err := conn.Exec(WithQueryOptions(ctx, QueryOptions{Timeout: 50 * time.Millisecond}), "call slow"); !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrQueryTimeout) {
+		t.Fatalf("error %v - expected %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestStatementTimeout(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	s.Handle("call slow", &drivertest.MockStatement{Delay: 1100 * time.Millisecond})
+	s.Handle("call fast", &drivertest.MockStatement{})
+
+	connector := NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetStatementTimeout(-time.Second); err == nil {
+		t.Fatal("error expected")
+	}
+	if err := connector.SetStatementTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec(ctx, "call slow")
+	dbErr, ok := err.(Error)
+	if !ok {
+		t.Fatalf("error %v - expected database error", err)
+	}
+	if dbErr.Code() != drivertest.ErrorCodeQueryTimeout {
+		t.Fatalf("error code %d - expected %d", dbErr.Code(), drivertest.ErrorCodeQueryTimeout)
+	}
+	if !errors.Is(err, ErrQueryTimeout) {
+		t.Fatalf("error %v - expected %v", err, ErrQueryTimeout)
+	}
+	// connection is still usable
+	if _, err := conn.Exec(ctx, "call fast"); err != nil {
+		t.Fatal(err)
+	}
+	// no server timeout
+	if _, err := conn.Exec(WithQueryOptions(ctx, QueryOptions{ServerTimeout: -1}), "call slow"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver/dlog"
+	"github.com/SAP/go-hdb/driver/hdbsql"
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// isWriteStatement returns true if query modifies data or locks rows (dml, ddl and queries with for update clause).
+// Statements which cannot be classified are not regarded as write statements.
+func isWriteStatement(query string) bool {
+	class, err := hdbsql.Classify(query)
+	if err != nil {
+		return false
+	}
+	return class.Kind == hdbsql.KindDML || class.Kind == hdbsql.KindDDL || class.ForUpdate
+}
+
+// secondaryHost returns the read-enabled secondary host of the topology information of session (if any).
+func secondaryHost(session *p.Session) (p.TopologyHost, bool) {
+	for _, h := range session.Topology() {
+		if h.Secondary && !h.CurrentSession {
+			return h, true
+		}
+	}
+	return p.TopologyHost{}, false
+}
+
+/*
+routeReadOnly routes the session of a read-only connector snapshot ctr to the read-enabled secondary site of an
+Active/Active (read enabled) system replication: if the topology information returned by the server at connect time
+contains a secondary host, the session is replaced by a session on the secondary host. If the connection to the
+secondary host fails, the session on the primary host is kept. The host of ctr is set to the host of the returned
+session.
+*/
+func routeReadOnly(ctx context.Context, ctr *Connector, session *p.Session) *p.Session {
+	h, ok := secondaryHost(session)
+	if !ok {
+		return session
+	}
+	primaryHost := ctr.host
+	ctr.host = h.Address()
+	secondary, err := newSession(ctx, ctr)
+	if err != nil {
+		ctr.host = primaryHost
+		logger := ctr.Logger()
+		if logger == nil {
+			logger = dlog.Default()
+		}
+		logger.Log(dlog.LevelWarn, "read-only routing to secondary failed", "host", primaryHost, "secondary", h.Address(), "error", err)
+		return session
+	}
+	session.Lock()
+	session.Close()
+	session.Unlock()
+	return secondary
+}
+
+// setReadOnlyMode sets the transaction access mode of the session of a read-only connection.
+// The caller needs to hold the session lock.
+func (c *conn) setReadOnlyMode() error {
+	_, err := c.session.ExecDirect(fmt.Sprintf(accessModeStmt, modeReadOnly))
+	return err
+}
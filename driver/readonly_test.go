@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockReadOnly(t *testing.T) {
+	newServer := func(t *testing.T, site string) *drivertest.MockServer {
+		s := drivertest.NewTestMockServer(t)
+		s.Handle("select site from dummy", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "SITE", TypeName: "NVARCHAR"}}, Rows: [][]interface{}{{site}}})
+		s.Handle("set transaction read only", &drivertest.MockStatement{})
+		s.Handle("set transaction isolation level read committed", &drivertest.MockStatement{})
+		s.Handle("insert into t values (1)", &drivertest.MockStatement{RowsAffected: 1})
+		return s
+	}
+
+	primary := newServer(t, "primary")
+	defer primary.Close()
+	secondary := newServer(t, "secondary")
+	defer secondary.Close()
+
+	down := newServer(t, "down") // closed server: secondary not reachable
+	down.Close()
+
+	site := func(t *testing.T, db *sql.DB) string {
+		var site string
+		if err := db.QueryRow("select site from dummy").Scan(&site); err != nil {
+			t.Fatal(err)
+		}
+		return site
+	}
+
+	openDB := func(t *testing.T, readOnly bool, secondaryAddr string) *sql.DB {
+		if err := primary.SetTopology(
+			drivertest.MockTopologyHost{Address: primary.Host(), Master: true},
+			drivertest.MockTopologyHost{Address: secondaryAddr, Secondary: true},
+		); err != nil {
+			t.Fatal(err)
+		}
+		connector := driver.NewBasicAuthConnector(primary.Host(), "user", "password")
+		if err := connector.SetReadOnly(readOnly); err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+		db.SetMaxOpenConns(1)
+		return db
+	}
+
+	t.Run("secondary", func(t *testing.T) {
+		db := openDB(t, true, secondary.Host())
+		defer db.Close()
+
+		if s := site(t, db); s != "secondary" {
+			t.Fatalf("site %s - expected %s", s, "secondary")
+		}
+		if _, err := db.Exec("insert into t values (1)"); !errors.Is(err, driver.ErrReadOnlyIntent) {
+			t.Fatalf("error %v - expected %v", err, driver.ErrReadOnlyIntent)
+		}
+		tx, err := db.Begin() // read-only transaction
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec("insert into t values (1)"); !errors.Is(err, driver.ErrReadOnlyIntent) {
+			t.Fatalf("error %v - expected %v", err, driver.ErrReadOnlyIntent)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		db := openDB(t, true, down.Host())
+		defer db.Close()
+
+		if s := site(t, db); s != "primary" {
+			t.Fatalf("site %s - expected %s", s, "primary")
+		}
+	})
+
+	t.Run("readWrite", func(t *testing.T) {
+		db := openDB(t, false, secondary.Host())
+		defer db.Close()
+
+		if s := site(t, db); s != "primary" {
+			t.Fatalf("site %s - expected %s", s, "primary")
+		}
+		if _, err := db.Exec("insert into t values (1)"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+type logicalSessionCtxKey struct{}
+
+// WithLogicalSession returns a context with the logical session id. Statements executed with contexts of
+// the same logical session id are guarded by read-your-writes consistency (see ReadYourWrites).
+func WithLogicalSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, logicalSessionCtxKey{}, id)
+}
+
+// LogicalSessionFromContext returns the logical session id of ctx.
+func LogicalSessionFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(logicalSessionCtxKey{}).(string)
+	return id, ok
+}
+
+// ReplicaTimeFunc returns the point in time up to which all commits of the primary database are visible
+// on the replica (e.g. the log replay position time of a read-enabled secondary).
+type ReplicaTimeFunc func(ctx context.Context) (time.Time, error)
+
+// PrimaryTimeFunc returns the current time of the primary database server (see CurrentUTCTimestamp).
+type PrimaryTimeFunc func(ctx context.Context) (time.Time, error)
+
+const currentUTCTimestampQuery = "select current_utctimestamp from dummy"
+
+// CurrentUTCTimestamp returns a PrimaryTimeFunc selecting the current UTC timestamp of the database server of db.
+func CurrentUTCTimestamp(db *sql.DB) PrimaryTimeFunc {
+	return func(ctx context.Context) (time.Time, error) {
+		var t time.Time
+		err := db.QueryRowContext(ctx, currentUTCTimestampQuery).Scan(&t)
+		return t, err
+	}
+}
+
+const replicaPollInterval = 10 * time.Millisecond
+
+/*
+ReadYourWrites guards read-your-writes consistency in case of read/write splitting, where reads are
+executed on a replica which lags behind the primary database.
+
+The commit time of the last write of each logical session (see WithLogicalSession) is tracked (see Wrote),
+so that subsequent reads of the logical session are only executed on the replica, if the replica has caught up
+with the commit time (see UseReplica). Otherwise the reads need to be executed on the primary database.
+
+Whether the replica has caught up is decided by comparing the commit time with the time returned by the
+ReplicaTimeFunc. As the replica time is a database server time, the commit time needs to be a server time as
+well: if a PrimaryTimeFunc is set (see SetPrimaryTime), the time of the primary database server is queried after
+each write and taken as commit time. As this time is not earlier than the actual commit, the comparison is safe.
+Otherwise the commit time is taken from the client clock, which is a heuristic only: in case the client clock is
+ahead of the database server clock, reads wait for the replica longer than needed, in case it is behind,
+reads might be executed on a replica which has not caught up yet.
+
+Independent of the replica time, the replica is assumed to have caught up after the maximum replication lag,
+which is measured by the client clock from the recording of the write. If no ReplicaTimeFunc is provided, this is
+the only criterion.
+*/
+type ReadYourWrites struct {
+	maxLag      time.Duration
+	wait        time.Duration
+	replicaTime ReplicaTimeFunc
+
+	mu          sync.Mutex
+	primaryTime PrimaryTimeFunc
+	lastWrites  map[string]lastWrite
+}
+
+// lastWrite is the last write of a logical session.
+type lastWrite struct {
+	recorded time.Time // client time of recording (maximum lag)
+	commit   time.Time // commit time compared with the replica time
+}
+
+// NewReadYourWrites returns a new read-your-writes guard. maxLag is the maximum replication lag, wait the
+// maximum time UseReplica waits for the replica to catch up, replicaTime is an optional ReplicaTimeFunc.
+func NewReadYourWrites(maxLag, wait time.Duration, replicaTime ReplicaTimeFunc) *ReadYourWrites {
+	return &ReadYourWrites{maxLag: maxLag, wait: wait, replicaTime: replicaTime, lastWrites: make(map[string]lastWrite)}
+}
+
+// SetPrimaryTime sets the function querying the time of the primary database server taken as commit time
+// of writes (nil: client time). The function is called by Wrote.
+func (g *ReadYourWrites) SetPrimaryTime(f PrimaryTimeFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.primaryTime = f
+}
+
+// commitTime returns the server time of the primary database, if available, or the client time now otherwise.
+func (g *ReadYourWrites) commitTime(ctx context.Context, now time.Time) time.Time {
+	g.mu.Lock()
+	f := g.primaryTime
+	g.mu.Unlock()
+	if f == nil {
+		return now
+	}
+	t, err := f(ctx)
+	if err != nil {
+		return now
+	}
+	return t
+}
+
+// Wrote records a committed write of the logical session of ctx. It needs to be called after the write
+// got committed (after the execution in auto commit mode or after the transaction commit).
+func (g *ReadYourWrites) Wrote(ctx context.Context) {
+	id, ok := LogicalSessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	w := lastWrite{recorded: now, commit: g.commitTime(ctx, now)}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for k, lw := range g.lastWrites { // remove writes replicated for sure
+		if now.Sub(lw.recorded) >= g.maxLag {
+			delete(g.lastWrites, k)
+		}
+	}
+	g.lastWrites[id] = w
+}
+
+func (g *ReadYourWrites) lastWrite(id string) (lastWrite, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	w, ok := g.lastWrites[id]
+	return w, ok
+}
+
+func (g *ReadYourWrites) caughtUp(ctx context.Context, w lastWrite) bool {
+	if time.Since(w.recorded) >= g.maxLag {
+		return true
+	}
+	if g.replicaTime == nil {
+		return false
+	}
+	rt, err := g.replicaTime(ctx)
+	return err == nil && !rt.Before(w.commit)
+}
+
+/*
+UseReplica reports whether a read of the logical session of ctx can be executed on the replica.
+In case the replica has not caught up with the last write of the logical session yet, UseReplica
+waits up to the configured wait time for the replica before it returns false (read on primary).
+Reads of contexts without logical session are always executed on the replica.
+*/
+func (g *ReadYourWrites) UseReplica(ctx context.Context) bool {
+	id, ok := LogicalSessionFromContext(ctx)
+	if !ok {
+		return true
+	}
+	w, ok := g.lastWrite(id)
+	if !ok || g.caughtUp(ctx, w) {
+		return true
+	}
+	if g.wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(g.wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(replicaPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return false
+		case <-ticker.C:
+			if g.caughtUp(ctx, w) {
+				return true
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	var mu sync.Mutex
+	var replicaTime time.Time
+	setReplicaTime := func(t time.Time) { mu.Lock(); replicaTime = t; mu.Unlock() }
+	replicaTimeFunc := func(ctx context.Context) (time.Time, error) { mu.Lock(); defer mu.Unlock(); return replicaTime, nil }
+
+	g := NewReadYourWrites(time.Hour, 0, replicaTimeFunc)
+
+	ctx := context.Background()
+	ctx1 := WithLogicalSession(ctx, "s1")
+	ctx2 := WithLogicalSession(ctx, "s2")
+
+	g.Wrote(ctx1)
+
+	tests := []struct {
+		ctx        context.Context
+		useReplica bool
+	}{
+		{ctx, true},  // no logical session
+		{ctx2, true}, // no write in logical session
+		{ctx1, false},
+	}
+	for i, test := range tests {
+		if useReplica := g.UseReplica(test.ctx); useReplica != test.useReplica {
+			t.Fatalf("%d: use replica %t - expected %t", i, useReplica, test.useReplica)
+		}
+	}
+
+	// replica catches up
+	setReplicaTime(time.Now())
+	if !g.UseReplica(ctx1) {
+		t.Fatal("use replica false - expected true")
+	}
+
+	// wait for replica
+	g = NewReadYourWrites(time.Hour, time.Second, replicaTimeFunc)
+	g.Wrote(ctx1)
+	time.AfterFunc(50*time.Millisecond, func() { setReplicaTime(time.Now()) })
+	if !g.UseReplica(ctx1) {
+		t.Fatal("use replica false - expected true")
+	}
+
+	// replication lag without replica time function
+	g = NewReadYourWrites(50*time.Millisecond, 0, nil)
+	g.Wrote(ctx1)
+	if g.UseReplica(ctx1) {
+		t.Fatal("use replica true - expected false")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !g.UseReplica(ctx1) {
+		t.Fatal("use replica false - expected true")
+	}
+	// commit time taken from the primary database server (client clock ahead of server clocks)
+	serverTime := time.Date(2020, time.June, 4, 13, 10, 3, 0, time.UTC)
+	setReplicaTime(serverTime.Add(-time.Second))
+	g = NewReadYourWrites(time.Hour, 0, replicaTimeFunc)
+	g.SetPrimaryTime(func(ctx context.Context) (time.Time, error) { return serverTime, nil })
+	g.Wrote(ctx1)
+	if g.UseReplica(ctx1) {
+		t.Fatal("use replica true - expected false")
+	}
+	setReplicaTime(serverTime)
+	if !g.UseReplica(ctx1) {
+		t.Fatal("use replica false - expected true")
+	}
+}
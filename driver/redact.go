@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+)
+
+// RedactedValue is the placeholder written to the sql trace instead of a redacted argument value.
+const RedactedValue = "<redacted>"
+
+/*
+RedactFunc is the function type deciding which statement argument values are written to the sql trace.
+It returns true if the value of argument arg of statement query needs to be redacted.
+Redaction rules are set per connector (see Connector.SetRedactFunc).
+*/
+type RedactFunc func(query string, arg driver.NamedValue) bool
+
+// RedactAll is a RedactFunc redacting all argument values.
+func RedactAll(query string, arg driver.NamedValue) bool { return true }
+
+// RedactOrdinals returns a RedactFunc redacting the values of the arguments with the given ordinal positions
+// (starting at one, see database/sql/driver NamedValue).
+func RedactOrdinals(ordinals ...int) RedactFunc {
+	m := make(map[int]bool, len(ordinals))
+	for _, ordinal := range ordinals {
+		m[ordinal] = true
+	}
+	return func(query string, arg driver.NamedValue) bool { return m[arg.Ordinal] }
+}
+
+// redactArgs returns a copy of args with the argument values replaced by RedactedValue where requested by redact.
+func redactArgs(redact RedactFunc, query string, args []driver.NamedValue) []driver.NamedValue {
+	if redact == nil {
+		return args
+	}
+	r := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		r[i] = arg
+		if redact(query, arg) {
+			r[i].Value = RedactedValue
+		}
+	}
+	return r
+}
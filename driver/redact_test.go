@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: "name"}, {Ordinal: 2, Value: "secret"}, {Ordinal: 3, Value: 42}}
+
+	testData := []struct {
+		redactFunc RedactFunc
+		values     []driver.Value
+	}{
+		{nil, []driver.Value{"name", "secret", 42}},
+		{RedactAll, []driver.Value{RedactedValue, RedactedValue, RedactedValue}},
+		{RedactOrdinals(2), []driver.Value{"name", RedactedValue, 42}},
+		{func(query string, arg driver.NamedValue) bool { _, ok := arg.Value.(int); return ok }, []driver.Value{"name", "secret", RedactedValue}},
+	}
+
+	for i, d := range testData {
+		r := redactArgs(d.redactFunc, "insert into t values (?, ?, ?)", args)
+		for j, arg := range r {
+			if arg.Value != d.values[j] {
+				t.Fatalf("test %d argument %d value %v - expected %v", i, j, arg.Value, d.values[j])
+			}
+		}
+	}
+	if args[1].Value != "secret" {
+		t.Fatal("original arguments modified")
+	}
+}
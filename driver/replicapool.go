@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replica is a read-only database of a replica pool.
+type replica struct {
+	db      *sql.DB
+	healthy int32 // atomic - 1: healthy, 0: outage
+}
+
+func (r *replica) isHealthy() bool { return atomic.LoadInt32(&r.healthy) == 1 }
+func (r *replica) setHealthy(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&r.healthy, v)
+}
+
+/*
+A ReplicaPool manages the connection pools of read-only replicas like Active/Active (read enabled) secondaries.
+
+Queries are executed on the healthy replicas (round robin). In case of a replica outage (connection error),
+the replica is excluded until the health check reports the replica to be available again and the query is
+executed on the primary database instead (failback). If no replica is healthy, all queries are executed on
+the primary database.
+
+All statements are executed with read-only intent (see QueryOptions), so that statements which are not read-only
+queries fail with ErrReadOnlyIntent.
+*/
+type ReplicaPool struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     uint32 // atomic - round robin index of the replicas
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewReplicaPool returns a replica pool for the primary connector and the replica connectors.
+// The health of the replicas is checked by a ping every checkInterval (checkInterval <= 0: no health check).
+func NewReplicaPool(primary driver.Connector, checkInterval time.Duration, replicas ...driver.Connector) *ReplicaPool {
+	p := &ReplicaPool{primary: sql.OpenDB(primary), replicas: make([]*replica, len(replicas)), closed: make(chan struct{})}
+	for i, c := range replicas {
+		p.replicas[i] = &replica{db: sql.OpenDB(c), healthy: 1}
+	}
+	if checkInterval > 0 && len(p.replicas) != 0 {
+		p.wg.Add(1)
+		go p.checkHealth(checkInterval)
+	}
+	return p
+}
+
+func (p *ReplicaPool) checkHealth(d time.Duration) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-ticker.C:
+			for _, r := range p.replicas {
+				ctx, cancel := context.WithTimeout(context.Background(), d)
+				r.setHealthy(r.db.PingContext(ctx) == nil)
+				cancel()
+			}
+		}
+	}
+}
+
+// Primary returns the primary database.
+func (p *ReplicaPool) Primary() *sql.DB { return p.primary }
+
+// HealthyReplicas returns the number of healthy replicas.
+func (p *ReplicaPool) HealthyReplicas() int {
+	n := 0
+	for _, r := range p.replicas {
+		if r.isHealthy() {
+			n++
+		}
+	}
+	return n
+}
+
+// replica returns the next healthy replica or nil, if no replica is healthy.
+func (p *ReplicaPool) replica() *replica {
+	n := len(p.replicas)
+	start := atomic.AddUint32(&p.next, 1)
+	for i := 0; i < n; i++ {
+		if r := p.replicas[(start+uint32(i))%uint32(n)]; r.isHealthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+// isConnError returns true if err is a connection error.
+func isConnError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
+
+// readOnlyContext returns a context with read-only intent.
+func readOnlyContext(ctx context.Context) context.Context {
+	opts, _ := QueryOptionsFromContext(ctx)
+	opts.ReadOnly = true
+	return WithQueryOptions(ctx, opts)
+}
+
+// QueryContext executes a query on a replica or on the primary database (see sql.DB.QueryContext).
+func (p *ReplicaPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx = readOnlyContext(ctx)
+	for r := p.replica(); r != nil; r = p.replica() {
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err == nil || !isConnError(err) || ctx.Err() != nil {
+			return rows, err
+		}
+		r.setHealthy(false) // replica outage
+	}
+	return p.primary.QueryContext(ctx, query, args...)
+}
+
+// Query executes a query on a replica or on the primary database (see sql.DB.Query).
+func (p *ReplicaPool) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return p.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query returning at most one row on a replica or on the primary database (see sql.DB.QueryRowContext).
+// As the error of the query is deferred until the row is scanned, a replica outage is detected by the health check only.
+func (p *ReplicaPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx = readOnlyContext(ctx)
+	if r := p.replica(); r != nil {
+		return r.db.QueryRowContext(ctx, query, args...)
+	}
+	return p.primary.QueryRowContext(ctx, query, args...)
+}
+
+// QueryRow executes a query returning at most one row on a replica or on the primary database (see sql.DB.QueryRow).
+func (p *ReplicaPool) QueryRow(query string, args ...interface{}) *sql.Row {
+	return p.QueryRowContext(context.Background(), query, args...)
+}
+
+// Close stops the health check and closes the primary and the replica databases.
+func (p *ReplicaPool) Close() error {
+	close(p.closed)
+	p.wg.Wait()
+	err := p.primary.Close()
+	for _, r := range p.replicas {
+		if closeErr := r.db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockReplicaPool(t *testing.T) {
+	newServer := func(name string) *drivertest.MockServer {
+		s := drivertest.NewTestMockServer(t)
+		s.Handle("select name from server", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}}, Rows: [][]interface{}{{name}}})
+		return s
+	}
+
+	primary, replica := newServer("primary"), newServer("replica")
+	defer primary.Close()
+
+	pool := driver.NewReplicaPool(driver.NewBasicAuthConnector(primary.Host(), "user", "password"), 0, driver.NewBasicAuthConnector(replica.Host(), "user", "password"))
+	defer pool.Close()
+
+	queryServer := func() string {
+		rows, err := pool.Query("select name from server")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var name string
+		for rows.Next() {
+			if err := rows.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	if name := queryServer(); name != "replica" {
+		t.Fatalf("server %s - expected %s", name, "replica")
+	}
+	if _, err := pool.Query("update t set id = 1"); err != driver.ErrReadOnlyIntent {
+		t.Fatalf("error %v - expected %v", err, driver.ErrReadOnlyIntent)
+	}
+
+	// replica outage: failback to primary
+	replica.Close()
+	if name := queryServer(); name != "primary" {
+		t.Fatalf("server %s - expected %s", name, "primary")
+	}
+	if n := pool.HealthyReplicas(); n != 0 {
+		t.Fatalf("healthy replicas %d - expected %d", n, 0)
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/dlog"
+	"github.com/SAP/go-hdb/driver/hdbsql"
+)
+
+// ErrBrokenConn is returned instead of driver.ErrBadConn for statements which are not idempotent,
+// in case the connection broke during the statement execution (see Connector.SetRetryIdempotent).
+var ErrBrokenConn = errors.New("connection broke during statement execution")
+
+// isIdempotent returns true if the operation op of query can be executed again: pings, read-only queries and
+// statements marked idempotent via the query options of ctx, unless ctx excludes the retry (see withoutRetry).
+func isIdempotent(ctx context.Context, op, query string) bool {
+	if op == opPing {
+		return true
+	}
+	if op != opQuery && op != opExec {
+		return false
+	}
+	if ctx.Value(noRetryCtxKey{}) != nil {
+		return false
+	}
+	if opts, ok := QueryOptionsFromContext(ctx); ok && opts.Idempotent {
+		return true
+	}
+	class, err := hdbsql.Classify(query)
+	return err == nil && class.ReadOnly()
+}
+
+type noRetryCtxKey struct{}
+
+// withoutRetry returns a context excluding the statement execution from the retry of idempotent statements
+// (e.g. bulk executions, as the rows buffered before cannot be sent again on a new connection).
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryCtxKey{}, true)
+}
+
+// RetryPolicy defines how idempotent statements are retried on broken connections (see Connector.SetRetryPolicy).
+type RetryPolicy struct {
+	MaxAttempts int           // maximum number of retries on a new connection (0: retry by database/sql only)
+	Backoff     time.Duration // backoff before the first retry, doubled for each further retry
+	MaxBackoff  time.Duration // upper limit of the backoff (0: no limit)
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 0 || p.Backoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("invalid retry policy %+v", p)
+	}
+	return nil
+}
+
+// backoff returns the backoff before retry attempt (starting with 0).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff != 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+/*
+checkRetry handles the error err of operation op of query, if the retry of idempotent statements is enabled
+(see Connector.SetRetryIdempotent) and the connection broke outside of a transaction:
+  - idempotent operations are retried on a new connection according to the retry policy of the connection. If the
+    operation is not retried or all attempts fail, driver.ErrBadConn is returned, so that database/sql re-executes
+    the operation on another connection.
+  - ErrBrokenConn is returned instead of driver.ErrBadConn for statements which are not idempotent, so that
+    database/sql does not re-execute the statement on another connection.
+*/
+func (c *conn) checkRetry(ctx context.Context, op, query string, f func() error, err error) error {
+	if !c.retryIdempotent || !errors.Is(err, driver.ErrBadConn) || c.session.InTx() {
+		return err
+	}
+	if !isIdempotent(ctx, op, query) {
+		if op == opQuery || op == opExec {
+			return ErrBrokenConn
+		}
+		return err
+	}
+	return c.retry(ctx, op, query, f, err)
+}
+
+/*
+retry re-dials the database and calls f on the new connection according to the retry policy of the connection,
+as long as f fails because of a broken connection. The error of the last attempt is returned.
+*/
+func (c *conn) retry(ctx context.Context, op, query string, f func() error, err error) error {
+	logger := c.logger
+	if logger == nil {
+		logger = dlog.Default()
+	}
+	for attempt := 0; attempt < c.retryPolicy.MaxAttempts; attempt++ {
+		d := c.retryPolicy.backoff(attempt)
+		logger.Log(dlog.LevelWarn, "connection retry", "op", op, "attempt", attempt+1, "backoff", d, "error", err)
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return newCtxError(ctx.Err())
+		case <-timer.C:
+		}
+		if err = c.reconnect(ctx); err != nil {
+			if ctx.Err() != nil {
+				return newCtxError(ctx.Err())
+			}
+			if !isConnectError(err) {
+				return err
+			}
+			err = driver.ErrBadConn
+			continue
+		}
+		if err = c.watched(ctx, op, query, f); err == nil || !errors.Is(err, driver.ErrBadConn) {
+			return err
+		}
+	}
+	return err
+}
+
+// reconnect replaces the broken connection of the session by a new connection to the database.
+func (c *conn) reconnect(ctx context.Context) error {
+	session, err := newFailoverSession(ctx, c.connector)
+	if err != nil {
+		return err
+	}
+	c.session.Reconnect(session)
+	c.host = c.connector.Host()
+	if c.stmtCache != nil {
+		c.stmtCache.clear()
+	}
+	if schema := c.connector.defaultSchema; schema != "" {
+		if _, err := c.session.ExecDirect(fmt.Sprintf(defaultSchema, schema)); err != nil {
+			return err
+		}
+	}
+	if c.readOnly {
+		return c.setReadOnlyMode()
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockRetryIdempotent(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	// disconnectOnce returns a statement closing the connection on the first execution.
+	disconnectOnce := func(columns []drivertest.MockColumn, rows [][]interface{}, n *int32) *drivertest.MockStatement {
+		return &drivertest.MockStatement{Columns: columns, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			if atomic.AddInt32(n, 1) == 1 {
+				return nil, drivertest.ErrMockDisconnect
+			}
+			return &drivertest.MockResult{Rows: rows, RowsAffected: 1}, nil
+		}}
+	}
+
+	columns := []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}
+	rows := [][]interface{}{{int32(1)}}
+
+	testData := []struct {
+		retry   bool
+		query   string
+		columns []drivertest.MockColumn
+		opts    *driver.QueryOptions
+		numExec int32
+		err     error
+	}{
+		{false, "select id from t1", columns, nil, 2, nil},
+		{false, "update t2 set id = 1", nil, nil, 2, nil}, // executed twice
+		{true, "select id from t3", columns, nil, 2, nil},
+		{true, "select id from t4 for update", columns, nil, 1, driver.ErrBrokenConn},
+		{true, "update t5 set id = 1", nil, nil, 1, driver.ErrBrokenConn},
+		{true, "update t6 set id = 1", nil, &driver.QueryOptions{Idempotent: true}, 2, nil},
+	}
+
+	for i, d := range testData {
+		var n int32
+		s.Handle(d.query, disconnectOnce(d.columns, rows, &n))
+
+		connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+		connector.SetRetryIdempotent(d.retry)
+		db := sql.OpenDB(connector)
+
+		ctx := context.Background()
+		if d.opts != nil {
+			ctx = driver.WithQueryOptions(ctx, *d.opts)
+		}
+		var err error
+		if d.columns != nil {
+			var id int
+			err = db.QueryRowContext(ctx, d.query).Scan(&id)
+		} else {
+			_, err = db.ExecContext(ctx, d.query)
+		}
+		db.Close()
+
+		if err != d.err {
+			t.Fatalf("%d: %s: error %v - expected %v", i, d.query, err, d.err)
+		}
+		if n := atomic.LoadInt32(&n); n != d.numExec {
+			t.Fatalf("%d: %s: number of executions %d - expected %d", i, d.query, n, d.numExec)
+		}
+	}
+}
+
+func TestMockRetryPolicy(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	// disconnect returns a statement closing the connection on the first numDisconnect executions.
+	disconnect := func(columns []drivertest.MockColumn, numDisconnect int32, n *int32) *drivertest.MockStatement {
+		return &drivertest.MockStatement{Columns: columns, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			if atomic.AddInt32(n, 1) <= numDisconnect {
+				return nil, drivertest.ErrMockDisconnect
+			}
+			return &drivertest.MockResult{Rows: [][]interface{}{{int32(1)}}, RowsAffected: 1}, nil
+		}}
+	}
+
+	columns := []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}
+	policy := driver.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	testData := []struct {
+		policy        driver.RetryPolicy
+		query         string
+		columns       []drivertest.MockColumn
+		numDisconnect int32
+		numExec       int32
+		err           error // expected error (nil: any error if not ok)
+		ok            bool
+	}{
+		{driver.RetryPolicy{}, "select id from t1", columns, 1, 1, nil, false},
+		{policy, "select id from t2", columns, 1, 2, nil, true},
+		{policy, "select id from t3", columns, 2, 3, nil, true},
+		{policy, "select id from t4", columns, 3, 3, nil, false},                 // max attempts exceeded
+		{policy, "update t5 set id = 1", nil, 1, 1, driver.ErrBrokenConn, false}, // not idempotent
+	}
+
+	for i, d := range testData {
+		var n int32
+		s.Handle(d.query, disconnect(d.columns, d.numDisconnect, &n))
+
+		connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+		connector.SetRetryIdempotent(true)
+		if err := connector.SetRetryPolicy(d.policy); err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+
+		// use a single connection, so that database/sql does not retry on another connection
+		ctx := context.Background()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.columns != nil {
+			var id int
+			err = conn.QueryRowContext(ctx, d.query).Scan(&id)
+		} else {
+			_, err = conn.ExecContext(ctx, d.query)
+		}
+		if (err == nil) != d.ok || (d.err != nil && err != d.err) {
+			t.Fatalf("%d: %s: error %v - expected ok %t error %v", i, d.query, err, d.ok, d.err)
+		}
+		if n := atomic.LoadInt32(&n); n != d.numExec {
+			t.Fatalf("%d: %s: number of executions %d - expected %d", i, d.query, n, d.numExec)
+		}
+		if d.ok {
+			// connection is usable after reconnect
+			if err := conn.PingContext(ctx); err != nil {
+				t.Fatalf("%d: %s: ping error %v", i, d.query, err)
+			}
+		}
+		conn.Close()
+		db.Close()
+	}
+
+	if err := driver.NewBasicAuthConnector(s.Host(), "user", "password").SetRetryPolicy(driver.RetryPolicy{MaxAttempts: -1}); err == nil {
+		t.Fatal("error expected for invalid retry policy")
+	}
+}
+
+func TestMockRetryBulk(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var numExec int32
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Params: []string{"INTEGER"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		if atomic.AddInt32(&numExec, 1) == 1 {
+			return nil, drivertest.ErrMockDisconnect
+		}
+		return &drivertest.MockResult{RowsAffected: int64(len(args))}, nil
+	}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetRetryIdempotent(true)
+	if err := connector.SetRetryPolicy(driver.RetryPolicy{MaxAttempts: 1}); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := driver.WithQueryOptions(context.Background(), driver.QueryOptions{Idempotent: true})
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stmt, err := conn.PrepareContext(ctx, "insert into t values (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+
+	// buffered rows cannot be sent again: the bulk execution is not retried
+	for i := 0; i < 3; i++ {
+		if _, err := stmt.ExecContext(ctx, i, driver.NoFlush); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != driver.ErrBrokenConn {
+		t.Fatalf("error %v - expected %v", err, driver.ErrBrokenConn)
+	}
+	if n := atomic.LoadInt32(&numExec); n != 1 {
+		t.Fatalf("number of executions %d - expected %d", n, 1)
+	}
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SAP/go-hdb/driver/hdbsql"
+)
+
+/*
+A Router routes statements by statement type (see hdbsql.Classify) to the connection pools of a connector group:
+read-only queries are executed on the read pools (round robin), all other statements like DML, DDL, procedure calls
+and queries with for update clause are executed on the primary pool. Statements which cannot be classified
+are executed on the primary pool as well.
+
+The Router methods mirror the methods of sql.DB, so that a Router can be used in place of a sql.DB:
+
+	r := driver.NewRouter(primaryConnector, replicaConnector1, replicaConnector2)
+	defer r.Close()
+	rows, err := r.QueryContext(ctx, "select * from t") // executed on a replica
+
+Transactions are always executed on the primary pool. Read-your-writes consistency can be
+guarded by a ReadYourWrites guard (see SetReadYourWrites).
+*/
+type Router struct {
+	primary *sql.DB
+	reads   []*sql.DB
+	next    uint32 // atomic - round robin index of the read pools
+
+	mu  sync.RWMutex
+	ryw *ReadYourWrites
+}
+
+// NewRouter returns a router for the primary connector and the read connectors.
+// If no read connector is provided, all statements are executed on the primary pool.
+func NewRouter(primary driver.Connector, reads ...driver.Connector) *Router {
+	r := &Router{primary: sql.OpenDB(primary), reads: make([]*sql.DB, len(reads))}
+	for i, c := range reads {
+		r.reads[i] = sql.OpenDB(c)
+	}
+	return r
+}
+
+// Primary returns the primary pool.
+func (r *Router) Primary() *sql.DB { return r.primary }
+
+// Reads returns the read pools.
+func (r *Router) Reads() []*sql.DB { return r.reads }
+
+/*
+SetReadYourWrites sets the read-your-writes guard of the router (nil: no guard).
+
+Successful writes executed by the router (auto commit mode) are recorded by the guard. Reads of a logical
+session (see WithLogicalSession) are executed on the primary pool, as long as the guard reports that the read
+pools have not caught up with the last write of the logical session. Writes executed in transactions need to be
+recorded by the application after the commit (see ReadYourWrites.Wrote). To record the commit time of writes
+in database server time, the guard can query the time of the primary pool:
+
+	g.SetPrimaryTime(driver.CurrentUTCTimestamp(r.Primary()))
+*/
+func (r *Router) SetReadYourWrites(g *ReadYourWrites) { r.mu.Lock(); defer r.mu.Unlock(); r.ryw = g }
+
+// ReadYourWrites returns the read-your-writes guard of the router.
+func (r *Router) ReadYourWrites() *ReadYourWrites { r.mu.RLock(); defer r.mu.RUnlock(); return r.ryw }
+
+// isReadOnlyQuery returns true if query is a read-only query.
+func isReadOnlyQuery(query string) bool {
+	class, err := hdbsql.Classify(query)
+	return err == nil && class.ReadOnly()
+}
+
+// route returns the pool query is executed on and whether query is a read-only query.
+func (r *Router) route(ctx context.Context, query string) (*sql.DB, bool) {
+	if !isReadOnlyQuery(query) {
+		return r.primary, false
+	}
+	if len(r.reads) == 0 {
+		return r.primary, true
+	}
+	if g := r.ReadYourWrites(); g != nil && !g.UseReplica(ctx) {
+		return r.primary, true
+	}
+	i := atomic.AddUint32(&r.next, 1)
+	return r.reads[i%uint32(len(r.reads))], true
+}
+
+// wrote records a write in the read-your-writes guard.
+func (r *Router) wrote(ctx context.Context) {
+	if g := r.ReadYourWrites(); g != nil {
+		g.Wrote(ctx)
+	}
+}
+
+// QueryContext executes a query (see sql.DB.QueryContext).
+func (r *Router) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db, isRead := r.route(ctx, query)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err == nil && !isRead {
+		r.wrote(ctx)
+	}
+	return rows, err
+}
+
+// Query executes a query (see sql.DB.Query).
+func (r *Router) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query returning at most one row (see sql.DB.QueryRowContext).
+func (r *Router) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	db, isRead := r.route(ctx, query)
+	row := db.QueryRowContext(ctx, query, args...)
+	if !isRead {
+		r.wrote(ctx) // error is not known before scan: record write in any case
+	}
+	return row
+}
+
+// QueryRow executes a query returning at most one row (see sql.DB.QueryRow).
+func (r *Router) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.QueryRowContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a statement without returning rows (see sql.DB.ExecContext).
+func (r *Router) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db, isRead := r.route(ctx, query)
+	result, err := db.ExecContext(ctx, query, args...)
+	if err == nil && !isRead {
+		r.wrote(ctx)
+	}
+	return result, err
+}
+
+// Exec executes a statement without returning rows (see sql.DB.Exec).
+func (r *Router) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return r.ExecContext(context.Background(), query, args...)
+}
+
+// PrepareContext creates a prepared statement on the pool the statement is routed to (see sql.DB.PrepareContext).
+// Writes executed via prepared statements are not recorded by the read-your-writes guard.
+func (r *Router) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	db, _ := r.route(ctx, query)
+	return db.PrepareContext(ctx, query)
+}
+
+// Prepare creates a prepared statement on the pool the statement is routed to (see sql.DB.Prepare).
+func (r *Router) Prepare(query string) (*sql.Stmt, error) {
+	return r.PrepareContext(context.Background(), query)
+}
+
+// BeginTx starts a transaction on the primary pool (see sql.DB.BeginTx).
+func (r *Router) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return r.primary.BeginTx(ctx, opts)
+}
+
+// Begin starts a transaction on the primary pool (see sql.DB.Begin).
+func (r *Router) Begin() (*sql.Tx, error) { return r.BeginTx(context.Background(), nil) }
+
+// PingContext verifies the connections to the primary and to all read databases (see sql.DB.PingContext).
+func (r *Router) PingContext(ctx context.Context) error {
+	if err := r.primary.PingContext(ctx); err != nil {
+		return err
+	}
+	for _, db := range r.reads {
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping verifies the connections to the primary and to all read databases (see sql.DB.Ping).
+func (r *Router) Ping() error { return r.PingContext(context.Background()) }
+
+// Close closes all pools of the router (see sql.DB.Close).
+func (r *Router) Close() error {
+	err := r.primary.Close()
+	for _, db := range r.reads {
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockRouter(t *testing.T) {
+	newServer := func(name string) *drivertest.MockServer {
+		s := drivertest.NewTestMockServer(t)
+		s.Handle("select name from server", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}}, Rows: [][]interface{}{{name}}})
+		return s
+	}
+
+	primary, replica := newServer("primary"), newServer("replica")
+	defer primary.Close()
+	defer replica.Close()
+	var writes int
+	primary.Handle("update t set id = 1", &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		writes++
+		return &drivertest.MockResult{RowsAffected: 1}, nil
+	}})
+
+	r := driver.NewRouter(driver.NewBasicAuthConnector(primary.Host(), "user", "password"), driver.NewBasicAuthConnector(replica.Host(), "user", "password"))
+	defer r.Close()
+	commitTime := time.Date(2020, time.June, 4, 13, 10, 3, 0, time.UTC)
+	primary.Handle("select current_utctimestamp from dummy", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "CURRENT_UTCTIMESTAMP", TypeName: "TIMESTAMP"}}, Rows: [][]interface{}{{commitTime}}})
+	var replicaTime time.Time
+	g := driver.NewReadYourWrites(time.Hour, 0, func(ctx context.Context) (time.Time, error) { return replicaTime, nil })
+	g.SetPrimaryTime(driver.CurrentUTCTimestamp(r.Primary()))
+	r.SetReadYourWrites(g)
+
+	ctx := driver.WithLogicalSession(context.Background(), "session")
+
+	queryServer := func(ctx context.Context) string {
+		var name string
+		if err := r.QueryRowContext(ctx, "select name from server").Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+
+	if name := queryServer(ctx); name != "replica" {
+		t.Fatalf("server %s - expected %s", name, "replica")
+	}
+	if _, err := r.ExecContext(ctx, "update t set id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if writes != 1 {
+		t.Fatalf("writes %d - expected %d", writes, 1)
+	}
+	// read your writes
+	if name := queryServer(ctx); name != "primary" {
+		t.Fatalf("server %s - expected %s", name, "primary")
+	}
+	// other logical session
+	if name := queryServer(context.Background()); name != "replica" {
+		t.Fatalf("server %s - expected %s", name, "replica")
+	}
+	// replica caught up with the commit time of the primary server
+	replicaTime = commitTime
+	if name := queryServer(ctx); name != "replica" {
+		t.Fatalf("server %s - expected %s", name, "replica")
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"io"
+	"reflect"
+)
+
+//  check if rows implements all required interfaces
+var (
+	_ driver.Rows                           = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeLength           = (*rows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*rows)(nil)
+	_ driver.RowsColumnTypePrecisionScale   = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+	_ driver.RowsNextResultSet              = (*rows)(nil)
+)
+
+var scanTypeUnknown = reflect.TypeOf(new(interface{})).Elem()
+
+// rows wraps the protocol result set keeping track of the number of rows read.
+// onClose is called once when the rows are closed.
+type rows struct {
+	driver.Rows
+	numRow  int64
+	closed  bool
+	onClose func(numRow int64)
+}
+
+func newRows(dr driver.Rows, onClose func(numRow int64)) *rows {
+	return &rows{Rows: dr, onClose: onClose}
+}
+
+func (r *rows) Close() error {
+	err := r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		if r.onClose != nil {
+			r.onClose(r.numRow)
+		}
+	}
+	return err
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	r.numRow++
+	return nil
+}
+
+func (r *rows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *rows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(idx int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(idx)
+	}
+	return ""
+}
+
+func (r *rows) ColumnTypeLength(idx int) (int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(idx)
+	}
+	return 0, false
+}
+
+func (r *rows) ColumnTypePrecisionScale(idx int) (int64, int64, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(idx)
+	}
+	return 0, 0, false
+}
+
+func (r *rows) ColumnTypeNullable(idx int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(idx)
+	}
+	return false, false
+}
+
+func (r *rows) ColumnTypeScanType(idx int) reflect.Type {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(idx)
+	}
+	return scanTypeUnknown
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockPrefetch(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const numRow = 232 // first chunk of 32 rows (mock server) followed by 10 fetches of 20 rows
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i)}
+	}
+	s.Handle("select id from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Rows: rows})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetFetchSize(20)
+	if err := connector.SetPrefetch(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := connector.SetPrefetch(driver.MaxPrefetch + 1); !errors.Is(err, driver.ErrLimitExceeded) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrLimitExceeded)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	numFetch := func() int64 { return connector.Stats().Latencies[driver.StatsOpFetch].Count }
+
+	t.Run("scan", func(t *testing.T) {
+		start := numFetch()
+		r, err := db.Query("select id from t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		n := 0
+		for r.Next() {
+			var id int
+			if err := r.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			if id != n {
+				t.Fatalf("id %d - expected %d", id, n)
+			}
+			if n == 0 { // chunks are fetched in advance while the first chunk is scanned
+				for i := 0; numFetch()-start < 2; i++ {
+					if i == 100 {
+						t.Fatalf("number of fetches %d - expected 2 fetches in advance", numFetch()-start)
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+			n++
+		}
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if n != numRow {
+			t.Fatalf("number of rows %d - expected %d", n, numRow)
+		}
+		if fetches := numFetch() - start; fetches != 10 {
+			t.Fatalf("number of fetches %d - expected %d", fetches, 10)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		r, err := db.Query("select id from t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 40 && r.Next(); i++ {
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// connection is usable after the prefetching is stopped
+		var id int
+		if err := db.QueryRow("select id from t").Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("zeroCopy", func(t *testing.T) {
+		names := make([][]interface{}, numRow)
+		for i := range names {
+			names[i] = []interface{}{fmt.Sprintf("name %d", i)}
+		}
+		s.Handle("select name from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}}, Rows: names})
+
+		connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+		connector.SetFetchSize(20)
+		if err := connector.SetPrefetch(2); err != nil {
+			t.Fatal(err)
+		}
+		if err := connector.SetZeroCopyStrings(true); err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		r, err := db.Query("select name from t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		// the strings of a chunk stay valid while the next chunks are fetched in advance
+		var scanned []string
+		for r.Next() {
+			var name string
+			if err := r.Scan(&name); err != nil {
+				t.Fatal(err)
+			}
+			scanned = append(scanned, name)
+			time.Sleep(100 * time.Microsecond) // let the prefetcher run while the chunk is scanned
+		}
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(scanned) != numRow {
+			t.Fatalf("number of rows %d - expected %d", len(scanned), numRow)
+		}
+		for i, name := range scanned {
+			if name != names[i][0] {
+				t.Fatalf("row %d: name %q - expected %q", i, name, names[i][0])
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// ErrTxNotInnermost is returned if a nested transaction is committed or rolled back before
+// the transactions nested in it (see Connector.SetNestedTransactions).
+var ErrTxNotInnermost = errors.New("transaction is not the innermost transaction")
+
+// ErrNestedTxOptions is returned if a nested transaction is started with an isolation level or access mode
+// differing from the outer transaction (see Connector.SetNestedTransactions).
+var ErrNestedTxOptions = errors.New("nested transaction options differ from the outer transaction")
+
+// savepoint statements
+const (
+	savepointStmt         = "savepoint %s"
+	releaseSavepointStmt  = "release savepoint %s"
+	rollbackSavepointStmt = "rollback to savepoint %s"
+)
+
+// savepointName returns the name of the savepoint of the nested transaction at nesting level level.
+func savepointName(level int) string { return fmt.Sprintf("GO_HDB_SAVEPOINT_%d", level) }
+
+// check if savepointTx implements the driver.Tx interface.
+var _ driver.Tx = (*savepointTx)(nil)
+
+// savepointTx is a nested transaction emulated by a savepoint.
+type savepointTx struct {
+	conn    *conn
+	session *p.Session
+	gen     uint64 // generation of the outer transaction
+	level   int    // nesting level (1: first nested transaction)
+	done    bool   // committed or rolled back
+}
+
+// beginSavepoint starts a nested transaction by setting a savepoint.
+func (c *conn) beginSavepoint() (*savepointTx, error) {
+	level := c.txLevel + 1
+	if _, err := c.session.ExecDirect(fmt.Sprintf(savepointStmt, savepointName(level))); err != nil {
+		return nil, err
+	}
+	c.txLevel = level
+	return &savepointTx{conn: c, session: c.session, gen: c.txGen, level: level}, nil
+}
+
+// end releases the savepoint after an optional rollback to the savepoint.
+func (t *savepointTx) end(rollback bool) error {
+	t.session.Lock()
+	defer t.session.Unlock()
+
+	if t.session.IsBad() {
+		return driver.ErrBadConn
+	}
+	if t.done || !t.session.InTx() || t.gen != t.conn.txGen { // outer transaction already ended
+		return sql.ErrTxDone
+	}
+	if t.level != t.conn.txLevel {
+		return ErrTxNotInnermost
+	}
+	name := savepointName(t.level)
+	if rollback {
+		if _, err := t.session.ExecDirect(fmt.Sprintf(rollbackSavepointStmt, name)); err != nil {
+			return err
+		}
+	}
+	if _, err := t.session.ExecDirect(fmt.Sprintf(releaseSavepointStmt, name)); err != nil {
+		return err
+	}
+	t.done = true
+	t.conn.txLevel--
+	return nil
+}
+
+// Commit releases the savepoint of the nested transaction.
+func (t *savepointTx) Commit() error { return t.end(false) }
+
+// Rollback rolls back to the savepoint of the nested transaction and releases the savepoint.
+func (t *savepointTx) Rollback() error { return t.end(true) }
@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockNestedTransactions(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var stmts []string
+	handle := func(query string) {
+		s.Handle(query, &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			stmts = append(stmts, query)
+			return &drivertest.MockResult{}, nil
+		}})
+	}
+	for _, query := range []string{
+		"set transaction isolation level read committed",
+		"set transaction read write",
+		"savepoint GO_HDB_SAVEPOINT_1",
+		"savepoint GO_HDB_SAVEPOINT_2",
+		"release savepoint GO_HDB_SAVEPOINT_1",
+		"release savepoint GO_HDB_SAVEPOINT_2",
+		"rollback to savepoint GO_HDB_SAVEPOINT_2",
+		"insert into t values (1)",
+	} {
+		handle(query)
+	}
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// nested transactions not enabled
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.BeginTx(ctx, nil); err != driver.ErrNestedTransaction {
+		t.Fatalf("error %v - expected %v", err, driver.ErrNestedTransaction)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if err := connector.SetNestedTransactions(true); err != nil {
+		t.Fatal(err)
+	}
+	db2 := sql.OpenDB(connector)
+	defer db2.Close()
+
+	conn, err = db2.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	mu.Lock()
+	stmts = nil
+	mu.Unlock()
+	outer, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner1, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner2, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inner2.Exec("insert into t values (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner2.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := outer.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"set transaction isolation level read committed",
+		"set transaction read write",
+		"savepoint GO_HDB_SAVEPOINT_1",
+		"savepoint GO_HDB_SAVEPOINT_2",
+		"insert into t values (1)",
+		"rollback to savepoint GO_HDB_SAVEPOINT_2",
+		"release savepoint GO_HDB_SAVEPOINT_2",
+		"release savepoint GO_HDB_SAVEPOINT_1",
+	}
+	mu.Lock()
+	got := stmts
+	mu.Unlock()
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("statements %v - expected %v", got, exp)
+	}
+
+	// nested transactions need to be ended in reverse order
+	nc, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	nestedOuter, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested1, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested2, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nested1.Commit(); err != driver.ErrTxNotInnermost {
+		t.Fatalf("error %v - expected %v", err, driver.ErrTxNotInnermost)
+	}
+	if err := nested2.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := nested1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := nestedOuter.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := nested1.Commit(); err != sql.ErrTxDone {
+		t.Fatalf("error %v - expected %v", err, sql.ErrTxDone)
+	}
+
+	// nested transactions of an ended outer transaction do not act on the savepoints of a new outer transaction
+	staleOuter, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := staleOuter.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	newOuter, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := nc.Begin(ctx, sqldriver.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Rollback(); err != sql.ErrTxDone {
+		t.Fatalf("error %v - expected %v", err, sql.ErrTxDone)
+	}
+	if err := nested.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// nested transactions inherit the transaction options of the outer transaction
+	for _, opts := range []sqldriver.TxOptions{
+		{Isolation: sqldriver.IsolationLevel(sql.LevelSerializable)},
+		{ReadOnly: true},
+	} {
+		if _, err := nc.Begin(ctx, opts); err != driver.ErrNestedTxOptions {
+			t.Fatalf("options %v: error %v - expected %v", opts, err, driver.ErrNestedTxOptions)
+		}
+	}
+	nested, err = nc.Begin(ctx, sqldriver.TxOptions{Isolation: sqldriver.IsolationLevel(sql.LevelReadCommitted)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nested.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := newOuter.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
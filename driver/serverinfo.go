@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ServerInfo contains the database server information of a connection, so that applications can enable
+features depending on the database version or the server capabilities.
+
+Besides the most relevant attributes, Options contains all connect options sent by the server during the
+connection handshake by option name (e.g. DatabaseName, SystemID, BuildPlatform, ClientDistributionMode,
+FlagSet1). Option values are of type bool, int64, float64, string or []byte.
+*/
+type ServerInfo struct {
+	Version           string // database server version (e.g. 2.00.048.00.1591276203)
+	BuildID           uint64 // build id of the database server version (e.g. 1591276203)
+	DataFormatVersion int    // data format version negotiated with the server (see Connector.SetDfv)
+	DatabaseName      string // name of the database connected to
+	SystemID          string // system id (SID) of the database system
+	BuildPlatform     int    // build platform of the database server
+	DistributionMode  int    // client distribution mode accepted by the server
+	Compression       bool   // connection compression is enabled (see Connector.SetCompression)
+	Flags             int64  // server option flags (FlagSet1)
+	Options           map[string]interface{}
+}
+
+// server connect option names
+const (
+	soDatabaseName     = "DatabaseName"
+	soSystemID         = "SystemID"
+	soBuildPlatform    = "BuildPlatform"
+	soDistributionMode = "ClientDistributionMode"
+	soFlagSet          = "FlagSet1"
+)
+
+func newServerInfo(version string, dfv int, compression bool, options map[string]interface{}) *ServerInfo {
+	info := &ServerInfo{Version: version, DataFormatVersion: dfv, Compression: compression, Options: options}
+	if i := strings.LastIndexByte(version, '.'); i != -1 && strings.Count(version, ".") == 4 {
+		info.BuildID, _ = strconv.ParseUint(version[i+1:], 10, 64)
+	}
+	info.DatabaseName, _ = options[soDatabaseName].(string)
+	info.SystemID, _ = options[soSystemID].(string)
+	if i, ok := options[soBuildPlatform].(int64); ok {
+		info.BuildPlatform = int(i)
+	}
+	if i, ok := options[soDistributionMode].(int64); ok {
+		info.DistributionMode = int(i)
+	}
+	info.Flags, _ = options[soFlagSet].(int64)
+	return info
+}
+
+// ServerInfo implements the Conn interface.
+func (c *conn) ServerInfo() *ServerInfo {
+	return newServerInfo(c.session.ServerVersion(), c.session.Dfv(), c.session.Compressed(), c.session.ServerOptions())
+}
+
+// ServerInfo returns the database server information of the connection.
+func (c *NativeConn) ServerInfo() *ServerInfo { return c.conn.ServerInfo() }
+
+// DatabaseInfo contains the information of the database connected to (see M_DATABASE).
+type DatabaseInfo struct {
+	SystemID     string
+	DatabaseName string
+	Host         string
+	StartTime    time.Time
+	Version      string
+	Usage        string // usage of the database system (e.g. DEVELOPMENT, TEST, PRODUCTION or CUSTOM)
+}
+
+// databaseInfoQuery selects the database information.
+const databaseInfoQuery = "select system_id, database_name, host, start_time, version, usage from sys.m_database"
+
+// QueryDatabaseInfo returns the information of the database connected to by querying M_DATABASE via q.
+func QueryDatabaseInfo(ctx context.Context, q RowQueryer) (*DatabaseInfo, error) {
+	info := new(DatabaseInfo)
+	if err := q.QueryRowContext(ctx, databaseInfoQuery).Scan(&info.SystemID, &info.DatabaseName, &info.Host, &info.StartTime, &info.Version, &info.Usage); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockHANA1Compat(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.SetVersion("1.00.122.00.1466466057")
+	s.Handle("select 1 from dummy", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "1", TypeName: "INTEGER"}}, Rows: [][]interface{}{{int32(1)}}})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetStrictProtocol(true)
+	connector.SetSessionVariables(driver.SessionVariables{"APPLICATION_COMPONENT": "test"})
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err == nil {
+		t.Fatal("error expected: server version not supported")
+	}
+
+	connector.SetHANA1Compat(true)
+	db = sql.OpenDB(connector)
+	defer db.Close()
+	var i int
+	if err := db.QueryRow("select 1 from dummy").Scan(&i); err != nil {
+		t.Fatal(err)
+	}
+	if i != 1 {
+		t.Fatalf("value %d - expected %d", i, 1)
+	}
+
+	s.SetVersion("1.00.112.00.1446714002") // SPS11
+	db = sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err == nil {
+		t.Fatal("error expected: server version not supported")
+	}
+}
+
+func TestMockDfv(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetStrictProtocol(true)
+
+	serverInfo := func() (*driver.ServerInfo, error) {
+		conn, err := connector.NativeConn(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		return conn.ServerInfo(), nil
+	}
+
+	tests := []struct {
+		maxDfv, dfv int
+		pin         bool
+		negotiated  int
+		fail        bool
+	}{
+		{0, driver.DfvLevel8, false, driver.DfvLevel8, false},
+		{driver.DfvLevel6, driver.DfvLevel8, false, driver.DfvLevel6, false},
+		{driver.DfvLevel6, driver.DfvLevel4, false, driver.DfvLevel4, false},
+		{driver.DfvLevel6, driver.DfvLevel8, true, 0, true},
+		{driver.DfvLevel6, driver.DfvLevel6, true, driver.DfvLevel6, false},
+	}
+
+	for _, test := range tests {
+		s.SetMaxDfv(test.maxDfv)
+		connector.SetDfv(test.dfv)
+		connector.SetPinDfv(test.pin)
+		info, err := serverInfo()
+		switch {
+		case test.fail && err == nil:
+			t.Fatalf("max dfv %d dfv %d pinned: error expected", test.maxDfv, test.dfv)
+		case test.fail:
+			continue
+		case err != nil:
+			t.Fatal(err)
+		}
+		if info.DataFormatVersion != test.negotiated {
+			t.Fatalf("max dfv %d dfv %d: negotiated dfv %d - expected %d", test.maxDfv, test.dfv, info.DataFormatVersion, test.negotiated)
+		}
+		if info.Version == "" {
+			t.Fatal("server version expected")
+		}
+	}
+}
+
+func TestMockServerInfo(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	startTime := time.Date(2020, time.June, 4, 13, 10, 3, 0, time.UTC)
+	s.Handle("select system_id, database_name, host, start_time, version, usage from sys.m_database", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{
+			{Name: "SYSTEM_ID", TypeName: "NVARCHAR"},
+			{Name: "DATABASE_NAME", TypeName: "NVARCHAR"},
+			{Name: "HOST", TypeName: "NVARCHAR"},
+			{Name: "START_TIME", TypeName: "TIMESTAMP"},
+			{Name: "VERSION", TypeName: "NVARCHAR"},
+			{Name: "USAGE", TypeName: "NVARCHAR"},
+		},
+		Rows: [][]interface{}{{"MCK", "MOCK", "localhost", startTime, "2.00.048.00.1591276203", "DEVELOPMENT"}},
+	})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	conn, err := connector.NativeConn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := conn.ServerInfo()
+	conn.Close()
+
+	if info.Version != "2.00.048.00.1591276203" || info.BuildID != 1591276203 {
+		t.Fatalf("version %s build id %d - expected %s %d", info.Version, info.BuildID, "2.00.048.00.1591276203", 1591276203)
+	}
+	if info.DatabaseName != "MOCK" || info.SystemID != "MCK" {
+		t.Fatalf("database name %s system id %s - expected %s %s", info.DatabaseName, info.SystemID, "MOCK", "MCK")
+	}
+	if v := info.Options["FullVersionString"]; v != info.Version {
+		t.Fatalf("option FullVersionString %v - expected %s", v, info.Version)
+	}
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	dbInfo, err := driver.QueryDatabaseInfo(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dbInfo.DatabaseName != "MOCK" || dbInfo.Usage != "DEVELOPMENT" || !dbInfo.StartTime.Equal(startTime) {
+		t.Fatalf("database info %v - expected database name %s usage %s start time %s", dbInfo, "MOCK", "DEVELOPMENT", startTime)
+	}
+}
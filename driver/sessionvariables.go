@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+)
+
+// SessionVariables implements the Conn interface.
+func (c *conn) SessionVariables() SessionVariables {
+	return SessionVariables(c.session.SessionVariables())
+}
+
+// SetSessionVariables implements the Conn interface.
+func (c *conn) SetSessionVariables(sessionVariables SessionVariables) error {
+	for k := range sessionVariables {
+		if k == "" {
+			return errors.New("invalid session variable: empty key")
+		}
+	}
+	c.session.SetSessionVariables(sessionVariables)
+	return nil
+}
+
+// SessionVariables returns the session variables of the connection.
+func (c *NativeConn) SessionVariables() SessionVariables { return c.conn.SessionVariables() }
+
+// SetSessionVariables sets session variables of the connection (see Conn).
+func (c *NativeConn) SetSessionVariables(sessionVariables SessionVariables) error {
+	return c.conn.SetSessionVariables(sessionVariables)
+}
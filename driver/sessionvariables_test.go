@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockSessionVariables(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.Handle("update t set a = 1", &drivertest.MockStatement{RowsAffected: 1})
+
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetSessionVariables(driver.SessionVariables{"APPLICATION": "app", "APPLICATIONUSER": "nobody"})
+
+	ctx := context.Background()
+	conn, err := connector.NativeConn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	exec := func() {
+		if _, err := conn.Exec(ctx, "update t set a = 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkClientInfo := func(k, v string) {
+		if ci := s.ClientInfo(); ci[k] != v {
+			t.Fatalf("client info %s: %s - expected %s", k, ci[k], v)
+		}
+	}
+
+	for _, user := range []string{"alice", "bob"} {
+		if err := conn.SetSessionVariables(driver.SessionVariables{"APPLICATIONUSER": user}); err != nil {
+			t.Fatal(err)
+		}
+		exec()
+		checkClientInfo("APPLICATIONUSER", user)
+	}
+
+	sv := conn.SessionVariables()
+	if sv["APPLICATION"] != "app" || sv["APPLICATIONUSER"] != "bob" {
+		t.Fatalf("session variables %v", sv)
+	}
+	if len(connector.SessionVariables()) != 2 || connector.SessionVariables()["APPLICATIONUSER"] != "nobody" {
+		t.Fatalf("connector session variables modified: %v", connector.SessionVariables())
+	}
+	if err := conn.SetSessionVariables(driver.SessionVariables{"": "x"}); err == nil {
+		t.Fatal("error expected for empty session variable key")
+	}
+}
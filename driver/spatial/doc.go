@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package spatial provides the geometry types of HANA spatial values (ST_GEOMETRY, ST_POINT) and
+the conversion of geometries from and to the well-known binary (WKB), well-known text (WKT) and
+GeoJSON representations.
+
+The database server transfers spatial values in WKB format. Geometries are bound to and scanned from
+spatial columns via driver.Geometry, e.g.
+
+	g, err := driver.NewGeometry(spatial.Point{X: 8.64, Y: 49.29})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := db.Exec("insert into places values (?, ?)", "Walldorf", g); err != nil {
+		log.Fatal(err)
+	}
+
+	var location driver.Geometry
+	if err := db.QueryRow("select location from places where name = ?", "Walldorf").Scan(&location); err != nil {
+		log.Fatal(err)
+	}
+	wkt, err := location.WKT() // POINT (8.64 49.29)
+
+The geometries are two-dimensional: geometries with z or m coordinates are rejected by the decoding functions.
+Decoding WKB accepts the extended format (EWKB) including a spatial reference system identifier (SRID),
+decoding WKT accepts a leading SRID (EWKT, e.g. "SRID=4326;POINT (8.64 49.29)"). The SRID is not part of
+the geometry and is ignored.
+*/
+package spatial
@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spatial
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// geoJSON is the GeoJSON geometry object.
+type geoJSON struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates,omitempty"`
+	Geometries  []json.RawMessage `json:"geometries,omitempty"`
+}
+
+// EncodeGeoJSON returns the GeoJSON geometry object of g.
+func EncodeGeoJSON(g Geometry) ([]byte, error) {
+	if g == nil {
+		return nil, errors.New("nil geometry")
+	}
+	obj := geoJSON{Type: g.Type()}
+	var coords interface{}
+	switch g := g.(type) {
+	case Point:
+		if g.IsEmpty() {
+			coords = []float64{}
+		} else {
+			coords = jsonPoint(g)
+		}
+	case LineString:
+		coords = jsonPoints(g)
+	case Polygon:
+		coords = jsonRings(g)
+	case MultiPoint:
+		coords = jsonPoints(g)
+	case MultiLineString:
+		coords = jsonRings(g)
+	case MultiPolygon:
+		polygons := make([][][][2]float64, len(g))
+		for i, p := range g {
+			polygons[i] = jsonRings(p)
+		}
+		coords = polygons
+	case GeometryCollection:
+		obj.Geometries = make([]json.RawMessage, len(g))
+		for i, e := range g {
+			b, err := EncodeGeoJSON(e)
+			if err != nil {
+				return nil, err
+			}
+			obj.Geometries[i] = b
+		}
+		if len(g) == 0 {
+			return []byte(`{"type":"GeometryCollection","geometries":[]}`), nil
+		}
+		return json.Marshal(obj)
+	default:
+		return nil, fmt.Errorf("%w: geometry type %T", ErrUnsupported, g)
+	}
+	b, err := json.Marshal(coords)
+	if err != nil {
+		return nil, err
+	}
+	obj.Coordinates = b
+	return json.Marshal(obj)
+}
+
+func jsonPoint(p Point) [2]float64 { return [2]float64{p.X, p.Y} }
+
+func jsonPoints(ps []Point) [][2]float64 {
+	coords := make([][2]float64, len(ps))
+	for i, p := range ps {
+		coords[i] = jsonPoint(p)
+	}
+	return coords
+}
+
+func jsonRings(rings []LineString) [][][2]float64 {
+	coords := make([][][2]float64, len(rings))
+	for i, ring := range rings {
+		coords[i] = jsonPoints(ring)
+	}
+	return coords
+}
+
+// DecodeGeoJSON returns the geometry of the GeoJSON geometry object b.
+func DecodeGeoJSON(b []byte) (Geometry, error) {
+	var obj geoJSON
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	if obj.Type == typeNames[wkbGeometryCollection] {
+		gc := make(GeometryCollection, len(obj.Geometries))
+		for i, e := range obj.Geometries {
+			g, err := DecodeGeoJSON(e)
+			if err != nil {
+				return nil, err
+			}
+			gc[i] = g
+		}
+		return gc, nil
+	}
+
+	switch obj.Type {
+	case typeNames[wkbPoint]:
+		var coord []float64
+		if err := json.Unmarshal(obj.Coordinates, &coord); err != nil {
+			return nil, err
+		}
+		if len(coord) == 0 {
+			return EmptyPoint(), nil
+		}
+		return pointOf(coord)
+	case typeNames[wkbLineString]:
+		var coords [][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		ps, err := pointsOf(coords)
+		return LineString(ps), err
+	case typeNames[wkbPolygon]:
+		var coords [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		rings, err := ringsOf(coords)
+		return Polygon(rings), err
+	case typeNames[wkbMultiPoint]:
+		var coords [][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		ps, err := pointsOf(coords)
+		return MultiPoint(ps), err
+	case typeNames[wkbMultiLineString]:
+		var coords [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		rings, err := ringsOf(coords)
+		return MultiLineString(rings), err
+	case typeNames[wkbMultiPolygon]:
+		var coords [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, err
+		}
+		mp := make(MultiPolygon, len(coords))
+		for i, c := range coords {
+			rings, err := ringsOf(c)
+			if err != nil {
+				return nil, err
+			}
+			mp[i] = rings
+		}
+		return mp, nil
+	default:
+		return nil, fmt.Errorf("%w: geojson geometry type %q", ErrUnsupported, obj.Type)
+	}
+}
+
+func pointOf(coord []float64) (Point, error) {
+	switch len(coord) {
+	case 2:
+		return Point{X: coord[0], Y: coord[1]}, nil
+	case 3, 4:
+		return Point{}, fmt.Errorf("%w: geojson position with z or m coordinates", ErrUnsupported)
+	default:
+		return Point{}, fmt.Errorf("invalid geojson position %v", coord)
+	}
+}
+
+func pointsOf(coords [][]float64) ([]Point, error) {
+	ps := make([]Point, len(coords))
+	for i, coord := range coords {
+		p, err := pointOf(coord)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = p
+	}
+	return ps, nil
+}
+
+func ringsOf(coords [][][]float64) ([]LineString, error) {
+	rings := make([]LineString, len(coords))
+	for i, c := range coords {
+		ps, err := pointsOf(c)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = ps
+	}
+	return rings, nil
+}
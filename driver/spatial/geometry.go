@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spatial
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrUnsupported is the error wrapped by errors raised for geometry representations not supported by the package
+// (e.g. geometries with z or m coordinates).
+var ErrUnsupported = errors.New("unsupported geometry")
+
+// geometry types (WKB type codes)
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// geometry type names (WKT and GeoJSON)
+var typeNames = map[uint32]string{
+	wkbPoint:              "Point",
+	wkbLineString:         "LineString",
+	wkbPolygon:            "Polygon",
+	wkbMultiPoint:         "MultiPoint",
+	wkbMultiLineString:    "MultiLineString",
+	wkbMultiPolygon:       "MultiPolygon",
+	wkbGeometryCollection: "GeometryCollection",
+}
+
+// A Geometry is one of the geometry types Point, LineString, Polygon, MultiPoint, MultiLineString,
+// MultiPolygon and GeometryCollection.
+type Geometry interface {
+	// Type returns the geometry type name (e.g. Point).
+	Type() string
+	wkbType() uint32
+}
+
+// A Point is a single location. A point with NaN coordinates is an empty point.
+type Point struct{ X, Y float64 }
+
+// EmptyPoint returns an empty point.
+func EmptyPoint() Point { return Point{X: math.NaN(), Y: math.NaN()} }
+
+// IsEmpty returns true if p is an empty point.
+func (p Point) IsEmpty() bool { return math.IsNaN(p.X) && math.IsNaN(p.Y) }
+
+// A LineString is a sequence of points connected by straight lines.
+type LineString []Point
+
+// A Polygon is a surface given by its exterior ring followed by the interior rings (holes).
+type Polygon []LineString
+
+// A MultiPoint is a collection of points.
+type MultiPoint []Point
+
+// A MultiLineString is a collection of line strings.
+type MultiLineString []LineString
+
+// A MultiPolygon is a collection of polygons.
+type MultiPolygon []Polygon
+
+// A GeometryCollection is a collection of geometries.
+type GeometryCollection []Geometry
+
+// Type implements the Geometry interface.
+func (Point) Type() string { return typeNames[wkbPoint] }
+
+// Type implements the Geometry interface.
+func (LineString) Type() string { return typeNames[wkbLineString] }
+
+// Type implements the Geometry interface.
+func (Polygon) Type() string { return typeNames[wkbPolygon] }
+
+// Type implements the Geometry interface.
+func (MultiPoint) Type() string { return typeNames[wkbMultiPoint] }
+
+// Type implements the Geometry interface.
+func (MultiLineString) Type() string { return typeNames[wkbMultiLineString] }
+
+// Type implements the Geometry interface.
+func (MultiPolygon) Type() string { return typeNames[wkbMultiPolygon] }
+
+// Type implements the Geometry interface.
+func (GeometryCollection) Type() string { return typeNames[wkbGeometryCollection] }
+
+func (Point) wkbType() uint32              { return wkbPoint }
+func (LineString) wkbType() uint32         { return wkbLineString }
+func (Polygon) wkbType() uint32            { return wkbPolygon }
+func (MultiPoint) wkbType() uint32         { return wkbMultiPoint }
+func (MultiLineString) wkbType() uint32    { return wkbMultiLineString }
+func (MultiPolygon) wkbType() uint32       { return wkbMultiPolygon }
+func (GeometryCollection) wkbType() uint32 { return wkbGeometryCollection }
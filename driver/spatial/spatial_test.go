@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spatial_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/spatial"
+)
+
+var testGeometries = []struct {
+	g       spatial.Geometry
+	wkt     string
+	geoJSON string
+}{
+	{
+		spatial.Point{X: 1, Y: 2.5},
+		"POINT (1 2.5)",
+		`{"type":"Point","coordinates":[1,2.5]}`,
+	},
+	{
+		spatial.LineString{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: -1}},
+		"LINESTRING (0 0, 1 1, 2 -1)",
+		`{"type":"LineString","coordinates":[[0,0],[1,1],[2,-1]]}`,
+	},
+	{
+		spatial.Polygon{
+			{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+			{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 1, Y: 1}},
+		},
+		"POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 2 1, 2 2, 1 1))",
+		`{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+	},
+	{
+		spatial.MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		"MULTIPOINT ((1 2), (3 4))",
+		`{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+	},
+	{
+		spatial.MultiLineString{{{X: 0, Y: 0}, {X: 1, Y: 1}}, {{X: 2, Y: 2}, {X: 3, Y: 3}}},
+		"MULTILINESTRING ((0 0, 1 1), (2 2, 3 3))",
+		`{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`,
+	},
+	{
+		spatial.MultiPolygon{
+			{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}},
+			{{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 6, Y: 6}, {X: 5, Y: 5}}},
+		},
+		"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`,
+	},
+	{
+		spatial.GeometryCollection{spatial.Point{X: 1, Y: 2}, spatial.LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		"GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (0 0, 1 1))",
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[0,0],[1,1]]}]}`,
+	},
+}
+
+func testWKB(t *testing.T) {
+	for _, test := range testGeometries {
+		b, err := spatial.EncodeWKB(test.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := spatial.DecodeWKB(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g, test.g) {
+			t.Fatalf("geometry %v - expected %v", g, test.g)
+		}
+	}
+
+	// POINT (1 2) in big endian byte order
+	g, err := spatial.DecodeWKB(mustDecodeHex(t, "00000000013ff00000000000004000000000000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != (spatial.Point{X: 1, Y: 2}) {
+		t.Fatalf("geometry %v - expected POINT (1 2)", g)
+	}
+
+	// SRID=4326;POINT (1 2) (EWKB)
+	g, err = spatial.DecodeWKB(mustDecodeHex(t, "0101000020e6100000000000000000f03f0000000000000040"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != (spatial.Point{X: 1, Y: 2}) {
+		t.Fatalf("geometry %v - expected POINT (1 2)", g)
+	}
+
+	// POINT Z (1 2 3)
+	if _, err := spatial.DecodeWKB(mustDecodeHex(t, "01e9030000000000000000f03f00000000000000400000000000000840")); !errors.Is(err, spatial.ErrUnsupported) {
+		t.Fatalf("error %v - expected %v", err, spatial.ErrUnsupported)
+	}
+	// truncated
+	if _, err := spatial.DecodeWKB(mustDecodeHex(t, "0101000000000000000000f03f")); err == nil {
+		t.Fatal("error expected for truncated wkb")
+	}
+	// huge number of points
+	if _, err := spatial.DecodeWKB(mustDecodeHex(t, "0102000000ffffffff")); err == nil {
+		t.Fatal("error expected for invalid number of points")
+	}
+}
+
+func testWKT(t *testing.T) {
+	for _, test := range testGeometries {
+		s, err := spatial.EncodeWKT(test.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.wkt {
+			t.Fatalf("wkt %s - expected %s", s, test.wkt)
+		}
+		g, err := spatial.DecodeWKT(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g, test.g) {
+			t.Fatalf("geometry %v - expected %v", g, test.g)
+		}
+	}
+
+	decodeTests := []struct {
+		s string
+		g spatial.Geometry
+	}{
+		{"point(1 2)", spatial.Point{X: 1, Y: 2}},
+		{"SRID=4326;POINT (1 2)", spatial.Point{X: 1, Y: 2}},
+		{" MULTIPOINT (1 2, 3 4) ", spatial.MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		{"LINESTRING EMPTY", spatial.LineString(nil)},
+		{"POINT (1e3 -2.5E-1)", spatial.Point{X: 1000, Y: -0.25}},
+	}
+	for _, test := range decodeTests {
+		g, err := spatial.DecodeWKT(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g, test.g) {
+			t.Fatalf("%s: geometry %v - expected %v", test.s, g, test.g)
+		}
+	}
+
+	g, err := spatial.DecodeWKT("POINT EMPTY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.(spatial.Point); !ok || !p.IsEmpty() {
+		t.Fatalf("geometry %v - expected empty point", g)
+	}
+	if s, err := spatial.EncodeWKT(spatial.EmptyPoint()); err != nil || s != "POINT EMPTY" {
+		t.Fatalf("wkt %s error %v - expected POINT EMPTY", s, err)
+	}
+
+	if _, err := spatial.DecodeWKT("POINT Z (1 2 3)"); !errors.Is(err, spatial.ErrUnsupported) {
+		t.Fatalf("error %v - expected %v", err, spatial.ErrUnsupported)
+	}
+	for _, s := range []string{"POINT (1)", "POINT (1 2", "LINESTRING (1 2,)", "POINT (1 2) x", "CIRCLE (1 2)"} {
+		if _, err := spatial.DecodeWKT(s); err == nil {
+			t.Fatalf("%s: error expected", s)
+		}
+	}
+}
+
+func testGeoJSON(t *testing.T) {
+	for _, test := range testGeometries {
+		b, err := spatial.EncodeGeoJSON(test.g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.geoJSON {
+			t.Fatalf("geojson %s - expected %s", b, test.geoJSON)
+		}
+		g, err := spatial.DecodeGeoJSON(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g, test.g) {
+			t.Fatalf("geometry %v - expected %v", g, test.g)
+		}
+	}
+
+	if _, err := spatial.DecodeGeoJSON([]byte(`{"type":"Point","coordinates":[1,2,3]}`)); !errors.Is(err, spatial.ErrUnsupported) {
+		t.Fatalf("error %v - expected %v", err, spatial.ErrUnsupported)
+	}
+	if _, err := spatial.DecodeGeoJSON([]byte(`{"type":"Feature"}`)); !errors.Is(err, spatial.ErrUnsupported) {
+		t.Fatalf("error %v - expected %v", err, spatial.ErrUnsupported)
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSpatial(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"wkb", testWKB},
+		{"wkt", testWKT},
+		{"geoJSON", testGeoJSON},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spatial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// byte order marker
+const (
+	wkbXDR = 0 // big endian
+	wkbNDR = 1 // little endian
+)
+
+// EWKB flags
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// EncodeWKB returns the well-known binary representation (little endian) of g.
+func EncodeWKB(g Geometry) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encodeWKB(buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeWKB(buf *bytes.Buffer, g Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry")
+	}
+	buf.WriteByte(wkbNDR)
+	writeUint32(buf, g.wkbType())
+	switch g := g.(type) {
+	case Point:
+		writePoint(buf, g)
+	case LineString:
+		writePoints(buf, g)
+	case Polygon:
+		writeRings(buf, g)
+	case MultiPoint:
+		writeUint32(buf, uint32(len(g)))
+		for _, p := range g {
+			if err := encodeWKB(buf, p); err != nil {
+				return err
+			}
+		}
+	case MultiLineString:
+		writeUint32(buf, uint32(len(g)))
+		for _, ls := range g {
+			if err := encodeWKB(buf, ls); err != nil {
+				return err
+			}
+		}
+	case MultiPolygon:
+		writeUint32(buf, uint32(len(g)))
+		for _, p := range g {
+			if err := encodeWKB(buf, p); err != nil {
+				return err
+			}
+		}
+	case GeometryCollection:
+		writeUint32(buf, uint32(len(g)))
+		for _, e := range g {
+			if err := encodeWKB(buf, e); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: geometry type %T", ErrUnsupported, g)
+	}
+	return nil
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeFloat64(buf *bytes.Buffer, v float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	buf.Write(b[:])
+}
+
+func writePoint(buf *bytes.Buffer, p Point) {
+	writeFloat64(buf, p.X)
+	writeFloat64(buf, p.Y)
+}
+
+func writePoints(buf *bytes.Buffer, ps []Point) {
+	writeUint32(buf, uint32(len(ps)))
+	for _, p := range ps {
+		writePoint(buf, p)
+	}
+}
+
+func writeRings(buf *bytes.Buffer, rings []LineString) {
+	writeUint32(buf, uint32(len(rings)))
+	for _, ring := range rings {
+		writePoints(buf, ring)
+	}
+}
+
+// DecodeWKB returns the geometry of the well-known binary representation b (WKB or EWKB).
+func DecodeWKB(b []byte) (Geometry, error) {
+	d := &wkbDecoder{b: b}
+	g, err := d.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.b) != 0 {
+		return nil, fmt.Errorf("invalid wkb: %d trailing bytes", len(d.b))
+	}
+	return g, nil
+}
+
+// errWKBSize is returned for truncated WKB.
+var errWKBSize = errors.New("invalid wkb: unexpected end of data")
+
+type wkbDecoder struct {
+	b     []byte
+	order binary.ByteOrder
+}
+
+func (d *wkbDecoder) uint32() (uint32, error) {
+	if len(d.b) < 4 {
+		return 0, errWKBSize
+	}
+	v := d.order.Uint32(d.b)
+	d.b = d.b[4:]
+	return v, nil
+}
+
+// count returns the number of elements of a collection, checking that the remaining data can contain
+// at least count elements of size minSize (guard against allocating huge slices for corrupted data).
+func (d *wkbDecoder) count(minSize int) (int, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(d.b)) {
+		return 0, errWKBSize
+	}
+	return int(n), nil
+}
+
+func (d *wkbDecoder) float64() (float64, error) {
+	if len(d.b) < 8 {
+		return 0, errWKBSize
+	}
+	v := math.Float64frombits(d.order.Uint64(d.b))
+	d.b = d.b[8:]
+	return v, nil
+}
+
+func (d *wkbDecoder) point() (Point, error) {
+	x, err := d.float64()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := d.float64()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+func (d *wkbDecoder) points() ([]Point, error) {
+	n, err := d.count(16)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]Point, n)
+	for i := range ps {
+		if ps[i], err = d.point(); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+func (d *wkbDecoder) rings() ([]LineString, error) {
+	n, err := d.count(4)
+	if err != nil {
+		return nil, err
+	}
+	rings := make([]LineString, n)
+	for i := range rings {
+		if rings[i], err = d.points(); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+// header decodes the byte order and the geometry type.
+func (d *wkbDecoder) header() (uint32, error) {
+	if len(d.b) == 0 {
+		return 0, errWKBSize
+	}
+	switch d.b[0] {
+	case wkbXDR:
+		d.order = binary.BigEndian
+	case wkbNDR:
+		d.order = binary.LittleEndian
+	default:
+		return 0, fmt.Errorf("invalid wkb: byte order %d", d.b[0])
+	}
+	d.b = d.b[1:]
+	typ, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if typ&(ewkbZ|ewkbM) != 0 || (typ&0xffff) > 1000 {
+		return 0, fmt.Errorf("%w: wkb geometry type %d with z or m coordinates", ErrUnsupported, typ)
+	}
+	if typ&ewkbSRID != 0 {
+		if _, err := d.uint32(); err != nil { // srid
+			return 0, err
+		}
+		typ &^= ewkbSRID
+	}
+	return typ, nil
+}
+
+func (d *wkbDecoder) geometry() (Geometry, error) {
+	typ, err := d.header()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case wkbPoint:
+		return d.point()
+	case wkbLineString:
+		ps, err := d.points()
+		return LineString(ps), err
+	case wkbPolygon:
+		rings, err := d.rings()
+		return Polygon(rings), err
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := d.count(5)
+		if err != nil {
+			return nil, err
+		}
+		gs := make([]Geometry, n)
+		for i := range gs {
+			if gs[i], err = d.geometry(); err != nil {
+				return nil, err
+			}
+		}
+		return collection(typ, gs)
+	default:
+		return nil, fmt.Errorf("%w: wkb geometry type %d", ErrUnsupported, typ)
+	}
+}
+
+// collection returns the collection of type typ containing the geometries gs.
+func collection(typ uint32, gs []Geometry) (Geometry, error) {
+	var ok bool
+	switch typ {
+	case wkbMultiPoint:
+		mp := make(MultiPoint, len(gs))
+		for i, g := range gs {
+			if mp[i], ok = g.(Point); !ok {
+				break
+			}
+		}
+		return mp, elementError(ok || len(gs) == 0, typ)
+	case wkbMultiLineString:
+		mls := make(MultiLineString, len(gs))
+		for i, g := range gs {
+			if mls[i], ok = g.(LineString); !ok {
+				break
+			}
+		}
+		return mls, elementError(ok || len(gs) == 0, typ)
+	case wkbMultiPolygon:
+		mp := make(MultiPolygon, len(gs))
+		for i, g := range gs {
+			if mp[i], ok = g.(Polygon); !ok {
+				break
+			}
+		}
+		return mp, elementError(ok || len(gs) == 0, typ)
+	default:
+		return GeometryCollection(gs), nil
+	}
+}
+
+func elementError(ok bool, typ uint32) error {
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("invalid element type of %s", typeNames[typ])
+}
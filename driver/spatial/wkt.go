@@ -0,0 +1,338 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package spatial
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EncodeWKT returns the well-known text representation of g.
+func EncodeWKT(g Geometry) (string, error) {
+	b := new(strings.Builder)
+	if err := encodeWKT(b, g); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func encodeWKT(b *strings.Builder, g Geometry) error {
+	if g == nil {
+		return errors.New("nil geometry")
+	}
+	b.WriteString(strings.ToUpper(g.Type()))
+	b.WriteByte(' ')
+	switch g := g.(type) {
+	case Point:
+		if g.IsEmpty() {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		writeWKTPoint(b, g)
+		b.WriteByte(')')
+	case LineString:
+		writeWKTPoints(b, g)
+	case Polygon:
+		writeWKTRings(b, g)
+	case MultiPoint:
+		if len(g) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i, p := range g {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('(')
+			writeWKTPoint(b, p)
+			b.WriteByte(')')
+		}
+		b.WriteByte(')')
+	case MultiLineString:
+		writeWKTRings(b, g)
+	case MultiPolygon:
+		if len(g) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i, p := range g {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			writeWKTRings(b, p)
+		}
+		b.WriteByte(')')
+	case GeometryCollection:
+		if len(g) == 0 {
+			b.WriteString("EMPTY")
+			return nil
+		}
+		b.WriteByte('(')
+		for i, e := range g {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			if err := encodeWKT(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(')')
+	default:
+		return fmt.Errorf("%w: geometry type %T", ErrUnsupported, g)
+	}
+	return nil
+}
+
+func writeWKTPoint(b *strings.Builder, p Point) {
+	b.WriteString(strconv.FormatFloat(p.X, 'f', -1, 64))
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatFloat(p.Y, 'f', -1, 64))
+}
+
+func writeWKTPoints(b *strings.Builder, ps []Point) {
+	if len(ps) == 0 {
+		b.WriteString("EMPTY")
+		return
+	}
+	b.WriteByte('(')
+	for i, p := range ps {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		writeWKTPoint(b, p)
+	}
+	b.WriteByte(')')
+}
+
+func writeWKTRings(b *strings.Builder, rings []LineString) {
+	if len(rings) == 0 {
+		b.WriteString("EMPTY")
+		return
+	}
+	b.WriteByte('(')
+	for i, ring := range rings {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		writeWKTPoints(b, ring)
+	}
+	b.WriteByte(')')
+}
+
+// DecodeWKT returns the geometry of the well-known text representation s (WKT or EWKT).
+func DecodeWKT(s string) (Geometry, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 5 && strings.EqualFold(s[:5], "SRID=") {
+		i := strings.IndexByte(s, ';')
+		if i == -1 {
+			return nil, fmt.Errorf("invalid wkt: missing ';' after srid")
+		}
+		s = s[i+1:]
+	}
+	d := &wktDecoder{s: s}
+	g, err := d.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if d.next(); d.token != "" {
+		return nil, fmt.Errorf("invalid wkt: unexpected token %q", d.token)
+	}
+	return g, nil
+}
+
+type wktDecoder struct {
+	s     string
+	token string
+}
+
+// next scans the next token: a word, a number or one of the delimiters '(', ')' and ','.
+// An empty token signals the end of the text.
+func (d *wktDecoder) next() {
+	d.s = strings.TrimLeftFunc(d.s, unicode.IsSpace)
+	if d.s == "" {
+		d.token = ""
+		return
+	}
+	switch d.s[0] {
+	case '(', ')', ',':
+		d.token, d.s = d.s[:1], d.s[1:]
+		return
+	}
+	i := strings.IndexFunc(d.s, func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ')' || r == ',' })
+	if i == -1 {
+		i = len(d.s)
+	}
+	d.token, d.s = d.s[:i], d.s[i:]
+}
+
+func (d *wktDecoder) expect(token string) error {
+	if d.next(); d.token != token {
+		return fmt.Errorf("invalid wkt: %q expected - got %q", token, d.token)
+	}
+	return nil
+}
+
+// empty scans either the keyword EMPTY or the opening parenthesis of a coordinate list.
+func (d *wktDecoder) empty() (bool, error) {
+	d.next()
+	switch {
+	case strings.EqualFold(d.token, "EMPTY"):
+		return true, nil
+	case d.token == "(":
+		return false, nil
+	case strings.EqualFold(d.token, "Z"), strings.EqualFold(d.token, "M"), strings.EqualFold(d.token, "ZM"):
+		return false, fmt.Errorf("%w: wkt geometry with z or m coordinates", ErrUnsupported)
+	default:
+		return false, fmt.Errorf("invalid wkt: '(' or EMPTY expected - got %q", d.token)
+	}
+}
+
+// list scans the elements of a parenthesized list by calling f for each element. The opening parenthesis
+// is already consumed.
+func (d *wktDecoder) list(f func() error) error {
+	for {
+		if err := f(); err != nil {
+			return err
+		}
+		d.next()
+		switch d.token {
+		case ",":
+		case ")":
+			return nil
+		default:
+			return fmt.Errorf("invalid wkt: ',' or ')' expected - got %q", d.token)
+		}
+	}
+}
+
+func (d *wktDecoder) number() (float64, error) {
+	d.next()
+	f, err := strconv.ParseFloat(d.token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid wkt: number expected - got %q", d.token)
+	}
+	return f, nil
+}
+
+func (d *wktDecoder) coord() (Point, error) {
+	x, err := d.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := d.number()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+func (d *wktDecoder) points() ([]Point, error) {
+	empty, err := d.empty()
+	if err != nil || empty {
+		return nil, err
+	}
+	var ps []Point
+	err = d.list(func() error {
+		p, err := d.coord()
+		ps = append(ps, p)
+		return err
+	})
+	return ps, err
+}
+
+func (d *wktDecoder) rings() ([]LineString, error) {
+	empty, err := d.empty()
+	if err != nil || empty {
+		return nil, err
+	}
+	var rings []LineString
+	err = d.list(func() error {
+		ps, err := d.points()
+		rings = append(rings, ps)
+		return err
+	})
+	return rings, err
+}
+
+func (d *wktDecoder) geometry() (Geometry, error) {
+	d.next()
+	typ := d.token
+	switch strings.ToUpper(typ) {
+	case "POINT":
+		empty, err := d.empty()
+		if err != nil || empty {
+			return EmptyPoint(), err
+		}
+		p, err := d.coord()
+		if err != nil {
+			return nil, err
+		}
+		return p, d.expect(")")
+	case "LINESTRING":
+		ps, err := d.points()
+		return LineString(ps), err
+	case "POLYGON":
+		rings, err := d.rings()
+		return Polygon(rings), err
+	case "MULTIPOINT":
+		empty, err := d.empty()
+		if err != nil || empty {
+			return MultiPoint(nil), err
+		}
+		var mp MultiPoint
+		err = d.list(func() error {
+			// points may or may not be enclosed in parentheses: MULTIPOINT ((1 2), (3 4)) or MULTIPOINT (1 2, 3 4)
+			s := d.s
+			if d.next(); d.token != "(" {
+				d.s = s
+				p, err := d.coord()
+				mp = append(mp, p)
+				return err
+			}
+			p, err := d.coord()
+			mp = append(mp, p)
+			if err != nil {
+				return err
+			}
+			return d.expect(")")
+		})
+		return mp, err
+	case "MULTILINESTRING":
+		rings, err := d.rings()
+		return MultiLineString(rings), err
+	case "MULTIPOLYGON":
+		empty, err := d.empty()
+		if err != nil || empty {
+			return MultiPolygon(nil), err
+		}
+		var mp MultiPolygon
+		err = d.list(func() error {
+			rings, err := d.rings()
+			mp = append(mp, rings)
+			return err
+		})
+		return mp, err
+	case "GEOMETRYCOLLECTION":
+		empty, err := d.empty()
+		if err != nil || empty {
+			return GeometryCollection(nil), err
+		}
+		var gc GeometryCollection
+		err = d.list(func() error {
+			g, err := d.geometry()
+			gc = append(gc, g)
+			return err
+		})
+		return gc, err
+	default:
+		return nil, fmt.Errorf("%w: wkt geometry type %q", ErrUnsupported, typ)
+	}
+}
@@ -5,10 +5,15 @@
 package sqltrace_test
 
 import (
+	"time"
+
 	"github.com/SAP/go-hdb/driver/sqltrace"
 )
 
 func Example() {
 	sqltrace.SetOn(true)  // set SQL trace output active
 	sqltrace.SetOn(false) // set SQL trace output inactive
+
+	sqltrace.SetSlowThreshold(500 * time.Millisecond) // trace statements exceeding 500 milliseconds only
+	sqltrace.SetSlowThreshold(0)                      // set slow query trace output inactive
 }
@@ -6,20 +6,29 @@ package sqltrace
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"sync"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/dlog"
 )
 
 type sqlTrace struct {
-	mu sync.RWMutex // protects field on
-	on bool
-	*log.Logger
+	mu            sync.RWMutex // protects fields on, json, slowThreshold and logger
+	on            bool
+	json          bool
+	slowThreshold time.Duration
+	logger        dlog.Logger // custom logger (nil: standard logger)
+	stdLogger     dlog.Logger
+	jsonLogger    dlog.Logger
 }
 
 func newSQLTrace() *sqlTrace {
 	return &sqlTrace{
-		Logger: log.New(os.Stdout, "hdb ", log.Ldate|log.Ltime|log.Lshortfile),
+		stdLogger:  dlog.NewStdLogger(log.New(os.Stdout, "hdb ", log.Ldate|log.Ltime)),
+		jsonLogger: dlog.NewJSONLogger(os.Stdout),
 	}
 }
 
@@ -27,6 +36,8 @@ var tracer = newSQLTrace()
 
 func init() {
 	flag.BoolVar(&tracer.on, "hdb.sqlTrace", false, "enabling hdb sql trace")
+	flag.BoolVar(&tracer.json, "hdb.sqlTrace.json", false, "enabling hdb sql trace JSON lines output")
+	flag.DurationVar(&tracer.slowThreshold, "hdb.sqlTrace.slowThreshold", 0, "hdb sql trace slow query threshold (0 = disabled)")
 }
 
 // On returns if tracing methods output is active.
@@ -44,23 +55,131 @@ func SetOn(on bool) {
 	tracer.mu.Unlock()
 }
 
+// JSON returns if the standard trace logger writes JSON lines.
+func JSON() bool {
+	tracer.mu.RLock()
+	json := tracer.json
+	tracer.mu.RUnlock()
+	return json
+}
+
+/*
+SetJSON sets the output format of the standard trace logger.
+
+If set, one JSON object per trace event is written (JSON lines), e.g.
+	{"time":"2020-10-01T12:00:00.000000001+02:00","level":"INFO","msg":"query","connID":205023,"stmt":"select * from dummy","duration":823044,"rows":1}
+so that traces can be ingested by log processing tools without parsing.
+*/
+func SetJSON(json bool) {
+	tracer.mu.Lock()
+	tracer.json = json
+	tracer.mu.Unlock()
+}
+
+// Logger returns the default trace logger.
+func Logger() dlog.Logger {
+	tracer.mu.RLock()
+	defer tracer.mu.RUnlock()
+	switch {
+	case tracer.logger != nil:
+		return tracer.logger
+	case tracer.json:
+		return tracer.jsonLogger
+	default:
+		return tracer.stdLogger
+	}
+}
+
+// SetLogger sets the default trace logger. Setting nil restores the standard logger writing to os.Stdout.
+func SetLogger(logger dlog.Logger) {
+	tracer.mu.Lock()
+	tracer.logger = logger
+	tracer.mu.Unlock()
+}
+
 // Trace calls trace logger Print method to print to the trace logger.
 func Trace(v ...interface{}) {
 	if On() {
-		tracer.Print(v...)
+		Logger().Log(dlog.LevelInfo, fmt.Sprint(v...))
 	}
 }
 
 // Tracef calls trace logger Printf method to print to the trace logger.
 func Tracef(format string, v ...interface{}) {
 	if On() {
-		tracer.Printf(format, v...)
+		Logger().Log(dlog.LevelInfo, fmt.Sprintf(format, v...))
 	}
 }
 
 // Traceln calls trace logger Println method to print to the trace logger.
 func Traceln(v ...interface{}) {
 	if On() {
-		tracer.Println(v...)
+		msg := fmt.Sprintln(v...)
+		Logger().Log(dlog.LevelInfo, msg[:len(msg)-1])
+	}
+}
+
+// Log writes a trace entry to logger if tracing methods output is active.
+// If logger is nil, the default trace logger is used.
+func Log(logger dlog.Logger, msg string, keyvals ...interface{}) {
+	if On() {
+		if logger == nil {
+			logger = Logger()
+		}
+		logger.Log(dlog.LevelInfo, msg, keyvals...)
+	}
+}
+
+// TraceStmt writes a statement execution event to logger if tracing methods output is active.
+// If logger is nil, the default trace logger is used.
+// The parameter op describes the operation (e.g. query, exec), connID identifies the database connection
+// and corrID the statement execution.
+func TraceStmt(logger dlog.Logger, op string, connID int64, corrID string, query string, d time.Duration, numRow int64, err error) {
+	if !On() {
+		return
+	}
+	if logger == nil {
+		logger = Logger()
+	}
+	if err != nil {
+		logger.Log(dlog.LevelError, op, "connID", connID, "corrID", corrID, "stmt", query, "duration", d, "rows", numRow, "error", err)
+		return
+	}
+	logger.Log(dlog.LevelInfo, op, "connID", connID, "corrID", corrID, "stmt", query, "duration", d, "rows", numRow)
+}
+
+// SlowThreshold returns the slow query threshold.
+func SlowThreshold() time.Duration {
+	tracer.mu.RLock()
+	d := tracer.slowThreshold
+	tracer.mu.RUnlock()
+	return d
+}
+
+/*
+SetSlowThreshold sets the slow query threshold.
+
+If the threshold is greater than zero, statements with an execution duration
+exceeding the threshold are traced - independent of the tracing methods output being active (see SetOn).
+A threshold of zero disables slow query tracing.
+*/
+func SetSlowThreshold(d time.Duration) {
+	tracer.mu.Lock()
+	tracer.slowThreshold = d
+	tracer.mu.Unlock()
+}
+
+// SlowOn returns if slow query tracing is active.
+func SlowOn() bool { return SlowThreshold() > 0 }
+
+// TraceSlow writes the statement, the execution duration, the number of rows (fetched or affected)
+// and the server processing time to logger if the duration exceeds the slow query threshold.
+// If logger is nil, the default trace logger is used.
+func TraceSlow(logger dlog.Logger, connID int64, corrID string, query string, d time.Duration, numRow int64, serverTime time.Duration) {
+	if threshold := SlowThreshold(); threshold > 0 && d > threshold {
+		if logger == nil {
+			logger = Logger()
+		}
+		logger.Log(dlog.LevelWarn, "slow query", "connID", connID, "corrID", corrID, "stmt", query, "duration", d, "rows", numRow, "serverTime", serverTime)
 	}
 }
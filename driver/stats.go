@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// ConnStats contains the network statistics of database connections.
+type ConnStats struct {
+	BytesRead    uint64 // Number of protocol bytes read from the database server.
+	BytesWritten uint64 // Number of protocol bytes written to the database server.
+	RoundTrips   uint64 // Number of request / reply round trips to the database server.
+	// StmtCacheHits and StmtCacheMisses count the prepared statements taken from and not found in
+	// the statement cache of the connection (see Connector.SetStmtCacheSize).
+	StmtCacheHits, StmtCacheMisses uint64
+	// Latency contains the round trip time statistics of the connection pings (statistics of a single connection only).
+	Latency LatencyStats
+}
+
+func newConnStats(s *p.SessionStats) ConnStats {
+	return ConnStats{
+		BytesRead:    s.BytesRead(),
+		BytesWritten: s.BytesWritten(),
+		RoundTrips:   s.RoundTrips(),
+	}
+}
+
+/*
+Conn is the interface implemented by go-hdb driver connections extending the database/sql/driver connection.
+
+A driver connection is passed to Hooks.OnConnect and can be accessed via sql.Conn.Raw (starting from go 1.16).
+*/
+type Conn interface {
+	driver.Conn
+	// Stats returns the network statistics of the connection. Stats is safe for concurrent use.
+	Stats() ConnStats
+	// ServerInfo returns the database server information of the connection.
+	ServerInfo() *ServerInfo
+	// DBConnectInfo requests the connect information of the database (tenant) databaseName from the server.
+	DBConnectInfo(ctx context.Context, databaseName string) (*DBConnectInfo, error)
+	// SessionVariables returns the session variables of the connection, i.e. the session variables of the connector
+	// merged with the variables set by SetSessionVariables.
+	SessionVariables() SessionVariables
+	// SetSessionVariables sets session variables (e.g. APPLICATIONUSER) of the connection at runtime. The variables are
+	// sent to the server with the next statement execution, variables not contained in sessionVariables keep their values.
+	// Session variables set on the connection take precedence over the session variables of the connector.
+	SetSessionVariables(sessionVariables SessionVariables) error
+	// SessionID returns the database session id of the connection.
+	SessionID() int64
+	// Native returns the low-level API of the connection bypassing database/sql (see NativeConn). The NativeConn
+	// must only be used within sql.Conn.Raw and must not be closed, as the connection is owned by database/sql.
+	Native() *NativeConn
+	// SendBatch executes the independent statements items sending the requests of all statements back-to-back
+	// before the replies are read. The results are returned per statement.
+	SendBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error)
+}
+
+// check if conn implements the Conn interface.
+var _ Conn = (*conn)(nil)
+
+// Stats implements the Conn interface.
+func (c *conn) Stats() ConnStats {
+	stats := newConnStats(c.session.Stats())
+	stats.Latency = c.latency.stats()
+	if c.stmtCache != nil {
+		stats.StmtCacheHits, stats.StmtCacheMisses = c.stmtCache.stats()
+	}
+	return stats
+}
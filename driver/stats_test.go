@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+type testStatsObserver struct {
+	mu  sync.Mutex
+	ops map[string]int
+}
+
+func (o *testStatsObserver) Observe(op string, d time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ops[op]++
+}
+
+func TestMockStats(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const numRow = 70 // first chunk of 32 rows (mock server) followed by 2 fetches of 20 rows
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i)}
+	}
+	s.Handle("select id from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Rows: rows})
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Params: []string{"INTEGER"}})
+	for _, query := range []string{"set transaction isolation level read committed", "set transaction read write"} {
+		s.Handle(query, &drivertest.MockStatement{})
+	}
+
+	observer := &testStatsObserver{ops: map[string]int{}}
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetFetchSize(20)
+	connector.SetStatsObserver(observer)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := tx.Prepare("insert into t values (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.Exec(1); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := connector.Stats()
+	if stats.OpenConnections != 1 || stats.NumConnect != 1 || stats.OpenStatements != 1 || stats.OpenTransactions != 1 {
+		t.Fatalf("stats %+v - expected one open connection, statement and transaction", stats)
+	}
+	stmt.Close()
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := db.Query("select id from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for r.Next() {
+		n++
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if n != numRow {
+		t.Fatalf("number of rows %d - expected %d", n, numRow)
+	}
+
+	stats = connector.Stats()
+	if stats.OpenStatements != 0 || stats.OpenTransactions != 0 {
+		t.Fatalf("stats %+v - expected no open statement and transaction", stats)
+	}
+	if stats.BytesRead == 0 || stats.BytesWritten == 0 || stats.RoundTrips == 0 {
+		t.Fatalf("stats %+v - expected network statistics", stats)
+	}
+	expCount := map[string]int64{driver.StatsOpPrepare: 1, driver.StatsOpExec: 1, driver.StatsOpQuery: 1, driver.StatsOpFetch: 2}
+	for op, count := range expCount {
+		h := stats.Latencies[op]
+		if h.Count != count || h.Buckets[len(h.Buckets)-1].Count != count {
+			t.Fatalf("%s: histogram %+v - expected count %d", op, h, count)
+		}
+		observer.mu.Lock()
+		numObserved := observer.ops[op]
+		observer.mu.Unlock()
+		if int64(numObserved) != count {
+			t.Fatalf("%s: number of observations %d - expected %d", op, numObserved, count)
+		}
+	}
+
+	if drvStats := driver.Stats(); drvStats.NumConnect < stats.NumConnect || drvStats.BytesRead < stats.BytesRead {
+		t.Fatalf("driver stats %+v - expected at least connector stats %+v", drvStats, stats)
+	}
+
+	db.Close()
+	if stats := connector.Stats(); stats.OpenConnections != 0 || stats.NumBadConn != 0 {
+		t.Fatalf("stats %+v - expected no open and bad connections", stats)
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"container/list"
+	"sync/atomic"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+type stmtCacheEntry struct {
+	query string
+	pr    *p.PrepareResult
+}
+
+/*
+stmtCache is a least recently used cache of the server side prepared statements of a connection
+(see Connector.SetStmtCacheSize).
+
+A prepared statement is taken from the cache by get for the lifetime of a driver statement and is put back
+on statement close, so that a prepared statement is never used by more than one driver statement and
+is never dropped while in use. The cache is protected by the session lock, only the hit and miss counters
+are safe for concurrent use.
+*/
+type stmtCache struct {
+	// 64-bit counters first to guarantee atomic alignment on 32-bit platforms.
+	hits, misses uint64
+
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{size: size, ll: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get takes the prepared statement of query out of the cache.
+func (c *stmtCache) get(query string) (*p.PrepareResult, bool) {
+	e, ok := c.entries[query]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	c.ll.Remove(e)
+	delete(c.entries, query)
+	return e.Value.(*stmtCacheEntry).pr, true
+}
+
+// put puts the prepared statement of query into the cache and returns the prepared statement to be dropped,
+// either the least recently used one in case the cache is full or pr itself in case query is already cached.
+func (c *stmtCache) put(query string, pr *p.PrepareResult) *p.PrepareResult {
+	if _, ok := c.entries[query]; ok {
+		return pr
+	}
+	c.entries[query] = c.ll.PushFront(&stmtCacheEntry{query: query, pr: pr})
+	if c.ll.Len() <= c.size {
+		return nil
+	}
+	entry := c.ll.Remove(c.ll.Back()).(*stmtCacheEntry)
+	delete(c.entries, entry.query)
+	return entry.pr
+}
+
+// clear removes all prepared statements from the cache, e.g. after the prepared statements got invalid.
+func (c *stmtCache) clear() {
+	c.ll.Init()
+	c.entries = map[string]*list.Element{}
+}
+
+func (c *stmtCache) stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&c.hits), atomic.LoadUint64(&c.misses)
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+type stmtCacheHook struct {
+	driver.NopHooks
+	conn driver.Conn
+}
+
+func (h *stmtCacheHook) OnConnect(ctx context.Context, conn sqldriver.Conn) error {
+	h.conn = conn.(driver.Conn)
+	return nil
+}
+
+func TestMockStmtCache(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	queries := []string{"insert into t1 values (?)", "insert into t2 values (?)", "insert into t3 values (?)"}
+	for _, query := range queries {
+		s.Handle(query, &drivertest.MockStatement{RowsAffected: 1})
+	}
+
+	hook := &stmtCacheHook{}
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	if err := connector.SetStmtCacheSize(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := connector.SetHooks(hook); err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	exec := func(query string) {
+		if _, err := db.Exec(query, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkPrepared := func(exp int) {
+		if n := s.Prepared(); n != exp {
+			t.Fatalf("prepared statements %d - expected %d", n, exp)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		exec(queries[0])
+	}
+	checkPrepared(1)
+
+	exec(queries[1])
+	exec(queries[0])
+	checkPrepared(2)
+
+	// evicts queries[1] (least recently used)
+	exec(queries[2])
+	exec(queries[0])
+	checkPrepared(3)
+	exec(queries[1])
+	checkPrepared(4)
+
+	stats := hook.conn.Stats()
+	if stats.StmtCacheHits != 6 || stats.StmtCacheMisses != 4 {
+		t.Fatalf("statement cache hits %d misses %d - expected %d %d", stats.StmtCacheHits, stats.StmtCacheMisses, 6, 4)
+	}
+
+	// invalidated cached statements are re-prepared
+	s.InvalidateStatements()
+	exec(queries[1])
+	checkPrepared(5)
+	exec(queries[1])
+	checkPrepared(5)
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+/*
+StatementInfo provides the server side identification of a statement execution, so that application
+metrics can be joined with the monitoring views of the database (M_PREPARED_STATEMENTS, M_SQL_PLAN_CACHE,
+M_EXPENSIVE_STATEMENTS).
+
+As database/sql does not give access to the driver statements and results, the information is
+returned via the context of the statement execution:
+
+	info := new(driver.StatementInfo)
+	rows, err := db.QueryContext(driver.WithStatementInfo(ctx, info), "select * from t where id = ?", 1)
+	...
+	planID, err := driver.PlanID(ctx, db, info.StatementID)
+
+The plan id is not part of the statement context sent by the server and needs to be queried from
+M_PREPARED_STATEMENTS while the statement is still prepared (e.g. in case of a prepared sql.Stmt or
+before closing the query rows).
+*/
+type StatementInfo struct {
+	// StatementID is the server statement id of the prepared statement
+	// (0 in case of direct execution without parameters).
+	StatementID uint64
+	// ServerExecutionTime is the server processing time of the statement execution.
+	ServerExecutionTime time.Duration
+}
+
+type statementInfoCtxKey struct{}
+
+// WithStatementInfo returns a context which lets the driver fill info after executing a statement with the context.
+func WithStatementInfo(ctx context.Context, info *StatementInfo) context.Context {
+	return context.WithValue(ctx, statementInfoCtxKey{}, info)
+}
+
+// setStatementInfo sets the statement information requested via WithStatementInfo (if any).
+func (c *conn) setStatementInfo(ctx context.Context, stmtID uint64) {
+	info, ok := ctx.Value(statementInfoCtxKey{}).(*StatementInfo)
+	if !ok || info == nil {
+		return
+	}
+	info.StatementID = stmtID
+	info.ServerExecutionTime = c.session.ServerExecutionTime()
+}
+
+// planIDQuery selects the plan id of a prepared statement.
+const planIDQuery = "select plan_id from sys.m_prepared_statements where statement_id = ?"
+
+// A RowQueryer is a database object executing single row queries like sql.DB, sql.Conn or sql.Tx.
+type RowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// PlanID returns the plan cache id (M_SQL_PLAN_CACHE.PLAN_ID) of the prepared statement with statement id stmtID.
+// sql.ErrNoRows is returned if the statement is not prepared (anymore).
+func PlanID(ctx context.Context, q RowQueryer, stmtID uint64) (int64, error) {
+	var planID sql.NullInt64
+	if err := q.QueryRowContext(ctx, planIDQuery, int64(stmtID)).Scan(&planID); err != nil {
+		return 0, err
+	}
+	return planID.Int64, nil
+}
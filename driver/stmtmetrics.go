@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// DefaultMaxStmtMetrics is the default maximum number of distinct statements tracked by StmtMetrics.
+const DefaultMaxStmtMetrics = 1000
+
+// latency histogram: bucket i counts the durations d with 2^(i-1) <= d (microseconds) < 2^i.
+const numLatencyBucket = 32
+
+type latencyHistogram [numLatencyBucket]int64
+
+func (h *latencyHistogram) add(d time.Duration) {
+	us := d / time.Microsecond
+	if us < 0 {
+		us = 0
+	}
+	i := bits.Len64(uint64(us))
+	if i >= numLatencyBucket {
+		i = numLatencyBucket - 1
+	}
+	h[i]++
+}
+
+// percentile returns the upper bound of the bucket containing the p-th percentile (0 < p <= 1).
+func (h *latencyHistogram) percentile(p float64, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	rank := int64(p*float64(count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var n int64
+	for i, c := range h {
+		n += c
+		if n >= rank {
+			return time.Duration(uint64(1)<<uint(i)) * time.Microsecond
+		}
+	}
+	return time.Duration(uint64(1)<<uint(numLatencyBucket-1)) * time.Microsecond
+}
+
+type stmtMetrics struct {
+	numExec, numError int64
+	numRow            int64
+	totalTime         time.Duration
+	maxTime           time.Duration
+	histogram         latencyHistogram
+}
+
+// StmtMetric contains the execution metrics of a normalized sql statement.
+type StmtMetric struct {
+	// Statement is the normalized sql statement (literals replaced by placeholders).
+	Statement string
+	// NumExec is the number of executions.
+	NumExec int64
+	// NumError is the number of executions returning an error.
+	NumError int64
+	// NumRow is the number of rows affected (exec) or read (query).
+	NumRow int64
+	// TotalTime is the accumulated execution time.
+	TotalTime time.Duration
+	// MaxTime is the maximum execution time.
+	MaxTime time.Duration
+	// P50, P90 and P99 are the approximated 50th, 90th and 99th latency percentiles.
+	P50, P90, P99 time.Duration
+}
+
+// AvgTime returns the average execution time.
+func (m *StmtMetric) AvgTime() time.Duration {
+	if m.NumExec == 0 {
+		return 0
+	}
+	return m.TotalTime / time.Duration(m.NumExec)
+}
+
+/*
+StmtMetrics is a registry collecting execution metrics per normalized sql statement.
+
+Statements are normalized by replacing literals with placeholders, so that
+statements differing only in literal values are aggregated.
+A StmtMetrics registry is activated by assigning it to a connector (see Connector.SetStmtMetrics)
+and can be shared between connectors.
+*/
+type StmtMetrics struct {
+	mu         sync.Mutex
+	maxStmt    int
+	numDropped int64
+	stmts      map[string]*stmtMetrics
+	scanner    scanner.Scanner
+}
+
+// NewStmtMetrics returns a new statement metrics registry tracking up to maxStmt distinct statements.
+// Executions of further statements are not tracked but counted (see NumDropped).
+// If maxStmt is less or equal zero, DefaultMaxStmtMetrics is used.
+func NewStmtMetrics(maxStmt int) *StmtMetrics {
+	if maxStmt <= 0 {
+		maxStmt = DefaultMaxStmtMetrics
+	}
+	return &StmtMetrics{maxStmt: maxStmt, stmts: make(map[string]*stmtMetrics)}
+}
+
+func (m *StmtMetrics) lookup(query string) *stmtMetrics {
+	stmt := m.scanner.Normalize(query)
+	sm, ok := m.stmts[stmt]
+	if !ok {
+		if len(m.stmts) >= m.maxStmt {
+			m.numDropped++
+			return nil
+		}
+		sm = &stmtMetrics{}
+		m.stmts[stmt] = sm
+	}
+	return sm
+}
+
+// record records a statement execution.
+func (m *StmtMetrics) record(query string, d time.Duration, numRow int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sm := m.lookup(query)
+	if sm == nil {
+		return
+	}
+	sm.numExec++
+	if err != nil {
+		sm.numError++
+	}
+	sm.numRow += numRow
+	sm.totalTime += d
+	if d > sm.maxTime {
+		sm.maxTime = d
+	}
+	sm.histogram.add(d)
+}
+
+// addRows adds the number of rows read by a query to the statement metrics.
+func (m *StmtMetrics) addRows(query string, numRow int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sm, ok := m.stmts[m.scanner.Normalize(query)]; ok {
+		sm.numRow += numRow
+	}
+}
+
+// NumDropped returns the number of executions not tracked because the maximum number of statements was reached.
+func (m *StmtMetrics) NumDropped() int64 { m.mu.Lock(); defer m.mu.Unlock(); return m.numDropped }
+
+// Reset removes all collected metrics.
+func (m *StmtMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stmts = make(map[string]*stmtMetrics)
+	m.numDropped = 0
+}
+
+// Snapshot returns the current metrics of all tracked statements ordered by total execution time (descending).
+func (m *StmtMetrics) Snapshot() []StmtMetric {
+	m.mu.Lock()
+	r := make([]StmtMetric, 0, len(m.stmts))
+	for stmt, sm := range m.stmts {
+		percentile := func(p float64) time.Duration {
+			// bucket upper bound might exceed maximum
+			if d := sm.histogram.percentile(p, sm.numExec); d < sm.maxTime {
+				return d
+			}
+			return sm.maxTime
+		}
+		r = append(r, StmtMetric{
+			Statement: stmt,
+			NumExec:   sm.numExec,
+			NumError:  sm.numError,
+			NumRow:    sm.numRow,
+			TotalTime: sm.totalTime,
+			MaxTime:   sm.maxTime,
+			P50:       percentile(0.5),
+			P90:       percentile(0.9),
+			P99:       percentile(0.99),
+		})
+	}
+	m.mu.Unlock()
+
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].TotalTime != r[j].TotalTime {
+			return r[i].TotalTime > r[j].TotalTime
+		}
+		return r[i].Statement < r[j].Statement
+	})
+	return r
+}
+
+// Dump writes the current metrics of all tracked statements as table to w.
+func (m *StmtMetrics) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "exec\terror\trows\ttotal\tavg\tp50\tp90\tp99\tmax\t statement")
+	for _, sm := range m.Snapshot() {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t %s\n",
+			sm.NumExec, sm.NumError, sm.NumRow, sm.TotalTime, sm.AvgTime(), sm.P50, sm.P90, sm.P99, sm.MaxTime, sm.Statement)
+	}
+	if n := m.NumDropped(); n != 0 {
+		fmt.Fprintf(tw, "%d executions of untracked statements\n", n)
+	}
+	return tw.Flush()
+}
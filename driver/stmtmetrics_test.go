@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testStmtMetricsRecord(t *testing.T) {
+	m := NewStmtMetrics(2)
+
+	m.record("select * from t where a = 1", 10*time.Millisecond, 0, nil)
+	m.record("SELECT * FROM t WHERE a = 2", 30*time.Millisecond, 0, errors.New("test error"))
+	m.addRows("select * from t where a = 3", 5)
+	m.record("insert into t values (?)", time.Millisecond, 1, nil)
+	m.record("delete from t", time.Millisecond, 2, nil) // exceeds max statements
+
+	snapshot := m.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("number of statements %d - expected %d", len(snapshot), 2)
+	}
+
+	sm := snapshot[0]
+	if sm.Statement != "select * from t where a = ?" {
+		t.Fatalf("statement %s - expected %s", sm.Statement, "select * from t where a = ?")
+	}
+	if sm.NumExec != 2 {
+		t.Fatalf("number of executions %d - expected %d", sm.NumExec, 2)
+	}
+	if sm.NumError != 1 {
+		t.Fatalf("number of errors %d - expected %d", sm.NumError, 1)
+	}
+	if sm.NumRow != 5 {
+		t.Fatalf("number of rows %d - expected %d", sm.NumRow, 5)
+	}
+	if sm.TotalTime != 40*time.Millisecond {
+		t.Fatalf("total time %s - expected %s", sm.TotalTime, 40*time.Millisecond)
+	}
+	if sm.AvgTime() != 20*time.Millisecond {
+		t.Fatalf("average time %s - expected %s", sm.AvgTime(), 20*time.Millisecond)
+	}
+	if sm.MaxTime != 30*time.Millisecond {
+		t.Fatalf("max time %s - expected %s", sm.MaxTime, 30*time.Millisecond)
+	}
+	if sm.P50 < 10*time.Millisecond || sm.P99 < 30*time.Millisecond {
+		t.Fatalf("invalid percentiles p50 %s p99 %s", sm.P50, sm.P99)
+	}
+
+	if m.NumDropped() != 1 {
+		t.Fatalf("number of dropped executions %d - expected %d", m.NumDropped(), 1)
+	}
+
+	b := new(bytes.Buffer)
+	if err := m.Dump(b); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + b.String())
+
+	m.Reset()
+	if len(m.Snapshot()) != 0 {
+		t.Fatal("statement metrics not reset")
+	}
+}
+
+func testLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+
+	for i := 1; i <= 100; i++ {
+		h.add(time.Duration(i) * time.Millisecond)
+	}
+
+	testData := []struct {
+		p        float64
+		min, max time.Duration
+	}{
+		{0.5, 50 * time.Millisecond, 100 * time.Millisecond},
+		{0.9, 90 * time.Millisecond, 180 * time.Millisecond},
+		{0.99, 99 * time.Millisecond, 198 * time.Millisecond},
+	}
+
+	for _, d := range testData {
+		v := h.percentile(d.p, 100)
+		if v < d.min || v > d.max {
+			t.Fatalf("percentile %f value %s - expected range %s - %s", d.p, v, d.min, d.max)
+		}
+	}
+}
+
+func TestStmtMetrics(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"record", testStmtMetricsRecord},
+		{"latencyHistogram", testLatencyHistogram},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/SAP/go-hdb/driver/dlog"
+)
+
+// nodeConn returns the connection to the node with address addr, opening the connection on demand.
+// The caller needs to hold the session lock.
+func (c *conn) nodeConn(ctx context.Context, addr string) (*conn, error) {
+	if node, ok := c.nodes[addr]; ok {
+		return node, nil
+	}
+	ctr := c.connector.snapshot()
+	ctr.host, ctr.failoverHosts, ctr.statementRouting = addr, nil, false
+	dc, err := newConn(ctx, ctr)
+	if err != nil {
+		return nil, err
+	}
+	if c.nodes == nil {
+		c.nodes = map[string]*conn{}
+	}
+	node := dc.(*conn)
+	c.nodes[addr] = node
+	return node, nil
+}
+
+// closeNodes closes the node connections. The caller needs to hold the session lock.
+func (c *conn) closeNodes() {
+	for addr, node := range c.nodes {
+		node.Close()
+		delete(c.nodes, addr)
+	}
+}
+
+/*
+routeStmt prepares query on the node connection the executions of statement s are routed to (see
+Connector.SetStatementRouting). Failures are logged and leave the statement unrouted.
+The caller needs to hold the session lock.
+*/
+func (c *conn) routeStmt(ctx context.Context, query string, s *stmt) {
+	if s.bulk || s.pr.IsProcedureCall() {
+		return
+	}
+	addr, ok := c.session.RouteAddress(s.pr)
+	if !ok {
+		return
+	}
+	err := func() error {
+		node, err := c.nodeConn(ctx, addr)
+		if err != nil {
+			return err
+		}
+		node.session.Lock()
+		defer node.session.Unlock()
+		if node.session.IsBad() {
+			return driver.ErrBadConn
+		}
+		s.node, err = node.prepareStmt(ctx, query)
+		return err
+	}()
+	if err != nil {
+		c.logger.Log(dlog.LevelWarn, "statement routing failed", "query", query, "node", addr, "error", err)
+	}
+}
+
+// routed returns the statement of the node connection the execution of s is routed to or nil, if s is not routed
+// or a transaction is active on the connection.
+func (s *stmt) routed() *stmt {
+	if s.node == nil {
+		return nil
+	}
+	s.session.Lock()
+	inTx := s.session.InTx()
+	s.session.Unlock()
+	if inTx {
+		return nil
+	}
+	return s.node
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockStatementRouting(t *testing.T) {
+	const query = "select site from t where id = ?"
+
+	newServer := func(t *testing.T, site string) *drivertest.MockServer {
+		s := drivertest.NewTestMockServer(t)
+		s.Handle(query, &drivertest.MockStatement{VolumeID: 2, Columns: []drivertest.MockColumn{{Name: "SITE", TypeName: "NVARCHAR"}}, Rows: [][]interface{}{{site}}})
+		s.Handle("set transaction isolation level read committed", &drivertest.MockStatement{})
+		s.Handle("set transaction read write", &drivertest.MockStatement{})
+		return s
+	}
+
+	nodeA := newServer(t, "A")
+	defer nodeA.Close()
+	nodeB := newServer(t, "B")
+	defer nodeB.Close()
+
+	for _, s := range []*drivertest.MockServer{nodeA, nodeB} {
+		if err := s.SetTopology(
+			drivertest.MockTopologyHost{Address: nodeA.Host(), VolumeID: 1, Master: true},
+			drivertest.MockTopologyHost{Address: nodeB.Host(), VolumeID: 2},
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	openDB := func(t *testing.T, statementRouting bool) *sql.DB {
+		connector := driver.NewBasicAuthConnector(nodeA.Host(), "user", "password")
+		if err := connector.SetStatementRouting(statementRouting); err != nil {
+			t.Fatal(err)
+		}
+		db := sql.OpenDB(connector)
+		db.SetMaxOpenConns(1)
+		return db
+	}
+
+	site := func(t *testing.T, q interface {
+		QueryRow(query string, args ...interface{}) *sql.Row
+	}) string {
+		var site string
+		if err := q.QueryRow(query, "1").Scan(&site); err != nil {
+			t.Fatal(err)
+		}
+		return site
+	}
+
+	t.Run("routed", func(t *testing.T) {
+		db := openDB(t, true)
+		defer db.Close()
+
+		if s := site(t, db); s != "B" {
+			t.Fatalf("site %s - expected %s", s, "B")
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		db := openDB(t, true)
+		defer db.Close()
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		// statements inside a transaction are executed on the anchor connection
+		if s := site(t, tx); s != "A" {
+			t.Fatalf("site %s - expected %s", s, "A")
+		}
+	})
+
+	t.Run("notRouted", func(t *testing.T) {
+		db := openDB(t, false)
+		defer db.Close()
+
+		if s := site(t, db); s != "A" {
+			t.Fatalf("site %s - expected %s", s, "A")
+		}
+	})
+}
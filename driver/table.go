@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// ErrTableParameter is the error wrapped by errors raised if a table argument cannot be bound (see TableRows).
+var ErrTableParameter = errors.New("table parameter binding failed")
+
+// tablePrefix is the name prefix of the local temporary tables of table arguments.
+const tablePrefix = "#GO_HDB_TABLE_"
+
+/*
+TableRows is the argument of a table typed input parameter of a database procedure.
+
+The database server accepts the arguments of table typed parameters as table names only. Therefore
+the rows of a TableRows argument are inserted into a local temporary table created like the table type
+(or table) typeName and the placeholder of the argument is replaced by the name of the temporary table
+before the procedure call is executed. The temporary table is dropped after the call.
+
+Example:
+
+	// create type tt_order as table (id integer, amount decimal(10,2))
+	// create procedure add_orders(in orders tt_order, in customer integer) ...
+	orders := driver.NewTableRows("tt_order", [][]interface{}{{1, "10.50"}, {2, "4.25"}})
+	if _, err := db.Exec("call add_orders(?, ?)", orders, 42); err != nil {
+		log.Fatal(err)
+	}
+
+typeName is used in the create statement as is, so that schema qualified or delimited names need to be given
+in SQL syntax (see Identifier). The row values are converted like the arguments of an insert statement.
+TableRows arguments are supported by procedure calls executed via DB, Conn, Tx and Stmt, either by Exec
+or, for procedures with output parameters, by Query.
+*/
+type TableRows struct {
+	typeName string
+	rows     [][]interface{}
+}
+
+// NewTableRows creates a new TableRows instance of table type typeName with the rows given as parameter.
+func NewTableRows(typeName string, rows [][]interface{}) *TableRows {
+	return &TableRows{typeName: typeName, rows: rows}
+}
+
+// TypeName returns the table type of the rows.
+func (t *TableRows) TypeName() string { return t.typeName }
+
+// Rows returns the rows.
+func (t *TableRows) Rows() [][]interface{} { return t.rows }
+
+// AddRow adds a row and returns *TableRows, to enable simple call chaining.
+func (t *TableRows) AddRow(values ...interface{}) *TableRows {
+	t.rows = append(t.rows, values)
+	return t
+}
+
+func (t *TableRows) numColumn() (int, error) {
+	if len(t.rows) == 0 {
+		return 0, nil
+	}
+	n := len(t.rows[0])
+	for i, row := range t.rows {
+		if len(row) != n {
+			return 0, fmt.Errorf("%w: invalid number of values %d in row %d - %d expected", ErrTableParameter, len(row), i, n)
+		}
+	}
+	return n, nil
+}
+
+func hasTableArg(args []driver.NamedValue) bool {
+	for _, arg := range args {
+		if _, ok := arg.Value.(*TableRows); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tableArg is a table argument bound to a local temporary table.
+type tableArg struct {
+	name string
+	rows *TableRows
+}
+
+/*
+bindTables replaces the placeholders of table arguments in the procedure call query by the names of local temporary
+tables and returns the rewritten query, the remaining arguments and the table arguments.
+Queries using named parameters are rewritten to positional parameters first.
+*/
+func (c *conn) bindTables(sc *scanner.Scanner, query string, args []driver.NamedValue) (string, []driver.NamedValue, []tableArg, error) {
+	qd, err := p.NewQueryDescr(query, sc)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if qd.Kind() != p.QkCall {
+		return "", nil, nil, fmt.Errorf("%w: table arguments are supported for procedure calls only", ErrTableParameter)
+	}
+	query = qd.Query()
+	if args, err = namedParams(qd.NamedParams()).bind(args); err != nil {
+		return "", nil, nil, err
+	}
+
+	var (
+		b      strings.Builder
+		last   int
+		argIdx int
+		tables []tableArg
+		bound  = make([]driver.NamedValue, 0, len(args))
+	)
+
+	sc.Reset(query)
+	for {
+		token, start, end := sc.Next()
+		if token == scanner.EOS {
+			break
+		}
+		if token != scanner.Variable {
+			continue
+		}
+		if argIdx >= len(args) {
+			return "", nil, nil, fmt.Errorf("invalid number of arguments %d - more placeholders expected", len(args))
+		}
+		arg := args[argIdx]
+		argIdx++
+
+		rows, ok := arg.Value.(*TableRows)
+		if !ok {
+			bound = append(bound, driver.NamedValue{Ordinal: len(bound) + 1, Value: arg.Value})
+			continue
+		}
+		c.tableNo++
+		table := tableArg{name: fmt.Sprintf("%s%d", tablePrefix, c.tableNo), rows: rows}
+		tables = append(tables, table)
+
+		b.WriteString(query[last:start])
+		b.WriteString(table.name)
+		last = end
+	}
+	if argIdx != len(args) {
+		return "", nil, nil, fmt.Errorf("invalid number of arguments %d - %d expected", len(args), argIdx)
+	}
+	b.WriteString(query[last:])
+	return b.String(), bound, tables, nil
+}
+
+// createTable creates the local temporary table of table argument t and inserts the rows.
+func (c *conn) createTable(ctx context.Context, t tableArg) error {
+	numColumn, err := t.rows.numColumn()
+	if err != nil {
+		return err
+	}
+	if _, err := c.ExecContext(ctx, fmt.Sprintf("create local temporary table %s like %s", t.name, t.rows.typeName), nil); err != nil {
+		return err
+	}
+	if numColumn == 0 {
+		return nil
+	}
+
+	ds, err := c.PrepareContext(ctx, fmt.Sprintf("bulk insert into %s values (%s?)", t.name, strings.Repeat("?, ", numColumn-1)))
+	if err != nil {
+		return err
+	}
+	s := ds.(*stmt)
+	defer s.Close()
+
+	for i, row := range t.rows.rows {
+		args := make([]driver.NamedValue, len(row))
+		for j, v := range row {
+			args[j] = driver.NamedValue{Ordinal: j + 1, Value: v}
+			if err := s.CheckNamedValue(&args[j]); err != nil {
+				return fmt.Errorf("%w: row %d: %s", ErrTableParameter, i, err)
+			}
+		}
+		if _, err := s.ExecContext(ctx, args); err != nil {
+			return err
+		}
+	}
+	_, err = s.ExecContext(ctx, nil) // flush
+	return err
+}
+
+// dropTables drops the local temporary tables of the table arguments.
+func (c *conn) dropTables(ctx context.Context, tables []tableArg) error {
+	var lastErr error
+	for _, t := range tables {
+		if _, err := c.ExecContext(ctx, "drop table "+t.name, nil); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// prepareTables creates the temporary tables of the table arguments, prepares the rewritten procedure call and
+// converts the remaining arguments. In case of an error the temporary tables are dropped.
+func (c *conn) prepareTables(ctx context.Context, query string, args []driver.NamedValue) (*stmt, []driver.NamedValue, []tableArg, error) {
+	c.session.Lock()
+	query, args, tables, err := c.bindTables(c.scanner, query, args)
+	c.session.Unlock()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i, t := range tables {
+		if err := c.createTable(ctx, t); err != nil {
+			c.dropTables(ctx, tables[:i+1])
+			return nil, nil, nil, err
+		}
+	}
+
+	ds, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		c.dropTables(ctx, tables)
+		return nil, nil, nil, err
+	}
+	s := ds.(*stmt)
+	for i := range args {
+		if err := s.CheckNamedValue(&args[i]); err != nil {
+			s.Close()
+			c.dropTables(ctx, tables)
+			return nil, nil, nil, err
+		}
+	}
+	return s, args, tables, nil
+}
+
+// queryTables executes a procedure call with table arguments. The temporary tables are dropped when the rows are closed.
+func (c *conn) queryTables(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, args, tables, err := c.prepareTables(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.QueryContext(ctx, args)
+	if err != nil {
+		s.Close()
+		c.dropTables(ctx, tables)
+		return nil, err
+	}
+	return newRows(rows, func(int64) { s.Close(); c.dropTables(ctx, tables) }), nil
+}
+
+// execTables executes a procedure call with table arguments.
+func (c *conn) execTables(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s, args, tables, err := c.prepareTables(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.ExecContext(ctx, args)
+	s.Close()
+	if dropErr := c.dropTables(ctx, tables); err == nil {
+		err = dropErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// queryTables executes the procedure call of the statement with table arguments.
+func (s *stmt) queryTables(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	args, err := s.params.bind(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.queryTables(ctx, s.query, args)
+}
+
+// execTables executes the procedure call of the statement with table arguments.
+func (s *stmt) execTables(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	args, err := s.params.bind(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.conn.execTables(ctx, s.query, args)
+}
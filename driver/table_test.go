@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockTableRows(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var stmts []string
+	var rows [][]interface{}
+	handle := func(query string, stmt *drivertest.MockStatement) {
+		f := stmt.Func
+		stmt.Func = func(args []interface{}) (*drivertest.MockResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			stmts = append(stmts, query)
+			if f != nil {
+				return f(args)
+			}
+			return &drivertest.MockResult{}, nil
+		}
+		s.Handle(query, stmt)
+	}
+	for i := 1; i <= 2; i++ {
+		table := fmt.Sprintf("#GO_HDB_TABLE_%d", i)
+		handle("create local temporary table "+table+" like tt_order", &drivertest.MockStatement{})
+		handle("insert into "+table+" values (?, ?)", &drivertest.MockStatement{Params: []string{"INTEGER", "NVARCHAR"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			for i := 0; i < len(args); i += 2 {
+				rows = append(rows, args[i:i+2])
+			}
+			return &drivertest.MockResult{RowsAffected: int64(len(args) / 2)}, nil
+		}})
+		handle("call add_orders("+table+", ?)", &drivertest.MockStatement{Params: []string{"INTEGER"}})
+		handle("drop table "+table, &drivertest.MockStatement{})
+	}
+	s.Handle("call add_orders(?, ?)", &drivertest.MockStatement{Params: []string{"NVARCHAR", "INTEGER"}})
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	orders := driver.NewTableRows("tt_order", [][]interface{}{{1, "10.50"}}).AddRow(2, "4.25")
+	expRows := [][]interface{}{{int64(1), "10.50"}, {int64(2), "4.25"}}
+	check := func(table string) {
+		mu.Lock()
+		defer mu.Unlock()
+		expStmts := []string{
+			"create local temporary table " + table + " like tt_order",
+			"insert into " + table + " values (?, ?)",
+			"call add_orders(" + table + ", ?)",
+			"drop table " + table,
+		}
+		if !reflect.DeepEqual(stmts, expStmts) {
+			t.Fatalf("statements %v - expected %v", stmts, expStmts)
+		}
+		if !reflect.DeepEqual(rows, expRows) {
+			t.Fatalf("rows %v - expected %v", rows, expRows)
+		}
+		stmts, rows = nil, nil
+	}
+
+	if _, err := db.Exec("call add_orders(?, ?)", orders, 42); err != nil {
+		t.Fatal(err)
+	}
+	check("#GO_HDB_TABLE_1")
+
+	stmt, err := db.Prepare("call add_orders(?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if _, err := stmt.Exec(orders, 42); err != nil {
+		t.Fatal(err)
+	}
+	check("#GO_HDB_TABLE_2")
+
+	if _, err := db.Exec("insert into t values (?)", orders); !errors.Is(err, driver.ErrTableParameter) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrTableParameter)
+	}
+	invalid := driver.NewTableRows("tt_order", [][]interface{}{{1, "10.50"}, {2}})
+	if _, err := db.Exec("call add_orders(?, ?)", invalid, 42); !errors.Is(err, driver.ErrTableParameter) {
+		t.Fatalf("error %v - expected %v", err, driver.ErrTableParameter)
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockTimeLocation(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var stored time.Time // wall clock stored by the database
+
+	s.Handle("insert into t values (?)", &drivertest.MockStatement{Params: []string{"TIMESTAMP"}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = args[0].(time.Time)
+		return &drivertest.MockResult{RowsAffected: 1}, nil
+	}})
+	s.Handle("select ts from t", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "TS", TypeName: "TIMESTAMP"}}, Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return &drivertest.MockResult{Rows: [][]interface{}{{stored}}}, nil
+	}})
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 123456789, zone)
+
+	tests := []struct {
+		name   string
+		loc    *time.Location
+		stored time.Time
+	}{
+		{"utc", nil, time.Date(2020, 1, 2, 1, 4, 5, 123456700, time.UTC)},
+		{"zone", zone, time.Date(2020, 1, 2, 3, 4, 5, 123456700, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+			if err := connector.SetTimeLocation(test.loc); err != nil {
+				t.Fatal(err)
+			}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			if _, err := db.Exec("insert into t values (?)", ts); err != nil {
+				t.Fatal(err)
+			}
+			mu.Lock()
+			wallClock := stored
+			mu.Unlock()
+			if !wallClock.Equal(test.stored) {
+				t.Fatalf("stored %s - expected %s", wallClock, test.stored)
+			}
+
+			var got time.Time
+			if err := db.QueryRow("select ts from t").Scan(&got); err != nil {
+				t.Fatal(err)
+			}
+			if want := ts.Truncate(100 * time.Nanosecond); !got.Equal(want) {
+				t.Fatalf("time %s - expected %s", got, want)
+			}
+			if got.Location() != connector.TimeLocation() {
+				t.Fatalf("location %s - expected %s", got.Location(), connector.TimeLocation())
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+// ErrInvalidAsOf is the error wrapped by errors raised for statements not supporting a time travel clause.
+var ErrInvalidAsOf = errors.New("invalid time travel")
+
+// asOfTimeFormat is the format of utc timestamp literals.
+const asOfTimeFormat = "2006-01-02 15:04:05.0000000"
+
+// AsOf is the point in time of a time travel query on history tables: a commit id or an utc time.
+type AsOf struct {
+	CommitID int64     // commit id (0: Time is used)
+	Time     time.Time // point in time (converted to UTC)
+}
+
+// AsOfCommitID returns the point in time of the commit with commit id id.
+func AsOfCommitID(id int64) AsOf { return AsOf{CommitID: id} }
+
+// AsOfTime returns the point in time t.
+func AsOfTime(t time.Time) AsOf { return AsOf{Time: t} }
+
+// String returns the time travel clause (AS OF COMMIT ID or AS OF UTCTIMESTAMP).
+func (a AsOf) String() string {
+	if a.CommitID != 0 {
+		return fmt.Sprintf("AS OF COMMIT ID %d", a.CommitID)
+	}
+	return "AS OF UTCTIMESTAMP " + StringLiteral(a.Time.UTC().Format(asOfTimeFormat))
+}
+
+// AddAsOf adds the time travel clause of asOf to the select statement query. The clause is added at the end
+// of the statement or in front of the WITH HINT clause. Statements already containing a time travel or
+// a for update clause are rejected.
+func AddAsOf(query string, asOf AsOf) (string, error) {
+	sc := &scanner.Scanner{}
+	sc.Reset(query)
+
+	var (
+		first           = true
+		depth           int // parenthesis depth
+		end             int // end of last token (excluding statement terminator)
+		hint            = -1
+		prev, prevStart = "", 0
+	)
+	for {
+		token, start, stop := sc.Next()
+		if token == scanner.EOS {
+			break
+		}
+		value := query[start:stop]
+		if first {
+			if token != scanner.Identifier || !(strings.EqualFold(value, "select") || strings.EqualFold(value, "with")) {
+				return "", fmt.Errorf("%w: statement is not a query: %s", ErrInvalidAsOf, query)
+			}
+			first = false
+		}
+		switch {
+		case token == scanner.Delimiter && value == "(":
+			depth++
+		case token == scanner.Delimiter && value == ")":
+			depth--
+		case depth == 0 && token == scanner.Identifier:
+			switch {
+			case strings.EqualFold(prev, "as") && strings.EqualFold(value, "of"):
+				return "", fmt.Errorf("%w: statement contains time travel clause: %s", ErrInvalidAsOf, query)
+			case strings.EqualFold(prev, "for") && strings.EqualFold(value, "update"):
+				return "", fmt.Errorf("%w: statement contains for update clause: %s", ErrInvalidAsOf, query)
+			case strings.EqualFold(prev, "with") && strings.EqualFold(value, "hint") && hint == -1:
+				hint = prevStart
+			}
+		}
+		if !(token == scanner.Delimiter && value == ";") {
+			end = stop
+		}
+		prev, prevStart = value, start
+	}
+	if first { // empty statement
+		return "", fmt.Errorf("%w: empty statement", ErrInvalidAsOf)
+	}
+	if hint != -1 {
+		return query[:hint] + asOf.String() + " " + query[hint:], nil
+	}
+	return query[:end] + " " + asOf.String() + query[end:], nil
+}
+
+// QueryAsOf executes the query on the history tables as of the point in time asOf (see AddAsOf).
+func QueryAsOf(ctx context.Context, db *sql.DB, asOf AsOf, query string, args ...interface{}) (*sql.Rows, error) {
+	query, err := AddAsOf(query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+const (
+	lastCommitIDQuery = "select last_commit_id from m_history_index_last_commit_id where session_id = current_connection"
+	commitTimeQuery   = "select commit_time from transaction_history where commit_id = ?"
+)
+
+// LastCommitID returns the commit id of the last transaction committed in the session of conn,
+// which can be used as point in time of later time travel queries (see AsOfCommitID).
+func LastCommitID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	if err := conn.QueryRowContext(ctx, lastCommitIDQuery).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// CommitTime returns the commit timestamp of the transaction with commit id commitID.
+func CommitTime(ctx context.Context, db *sql.DB, commitID int64) (time.Time, error) {
+	var t time.Time
+	if err := db.QueryRowContext(ctx, commitTimeQuery, commitID).Scan(&t); err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockTimeTravel(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	commitTime := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	s.Handle("select last_commit_id from m_history_index_last_commit_id where session_id = current_connection", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "LAST_COMMIT_ID", TypeName: "BIGINT"}},
+		Rows:    [][]interface{}{{int64(42)}},
+	})
+	s.Handle("select commit_time from transaction_history where commit_id = ?", &drivertest.MockStatement{
+		Params:  []string{"BIGINT"},
+		Columns: []drivertest.MockColumn{{Name: "COMMIT_TIME", TypeName: "TIMESTAMP"}},
+		Rows:    [][]interface{}{{commitTime}},
+	})
+	s.Handle("select name from persons AS OF COMMIT ID 42", &drivertest.MockStatement{
+		Columns: []drivertest.MockColumn{{Name: "NAME", TypeName: "NVARCHAR"}},
+		Rows:    [][]interface{}{{"Alice"}},
+	})
+
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	id, err := driver.LastCommitID(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Fatalf("commit id %d - expected %d", id, 42)
+	}
+
+	ts, err := driver.CommitTime(ctx, db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ts.Equal(commitTime) {
+		t.Fatalf("commit time %s - expected %s", ts, commitTime)
+	}
+
+	rows, err := driver.QueryAsOf(ctx, db, driver.AsOfCommitID(id), "select name from persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"Alice"}) {
+		t.Fatalf("names %v - expected %v", names, []string{"Alice"})
+	}
+}
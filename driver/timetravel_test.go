@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddAsOf(t *testing.T) {
+	ts := time.Date(2020, 3, 4, 5, 6, 7, 800000000, time.FixedZone("CET", 3600))
+
+	testData := []struct {
+		query string
+		asOf  AsOf
+		exp   string
+	}{
+		{"select * from t", AsOfCommitID(42), "select * from t AS OF COMMIT ID 42"},
+		{"select * from t;", AsOfCommitID(42), "select * from t AS OF COMMIT ID 42;"},
+		{"select * from t", AsOfTime(ts), "select * from t AS OF UTCTIMESTAMP '2020-03-04 04:06:07.8000000'"},
+		{"select * from t where a = ? with hint (no_cs_join)", AsOfCommitID(1), "select * from t where a = ? AS OF COMMIT ID 1 with hint (no_cs_join)"},
+		{"select * from (select a from t with hint(x)) as s", AsOfCommitID(1), "select * from (select a from t with hint(x)) as s AS OF COMMIT ID 1"},
+		{"with s as (select a from t) select * from s", AsOfCommitID(1), "with s as (select a from t) select * from s AS OF COMMIT ID 1"},
+	}
+
+	for i, d := range testData {
+		query, err := AddAsOf(d.query, d.asOf)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if query != d.exp {
+			t.Fatalf("%d: query %s - expected %s", i, query, d.exp)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"delete from t",
+		"select * from t as of commit id 1",
+		"select * from t for update",
+	}
+	for _, query := range invalid {
+		if _, err := AddAsOf(query, AsOfCommitID(1)); !errors.Is(err, ErrInvalidAsOf) {
+			t.Fatalf("query %s: error %v - expected %v", query, err, ErrInvalidAsOf)
+		}
+	}
+}
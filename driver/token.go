@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+/*
+TokenProvider returns the token of the token authentication (see NewTokenAuthConnector): a JSON Web Token (JWT)
+or a SAML bearer assertion. The authentication method is derived from the token: XML tokens are sent as SAML
+assertion, all other tokens as JWT.
+
+The provider is called for each connection opened by the connector (with the context of the connection request),
+so that expiring tokens (e.g. OAuth access tokens) can be refreshed or taken from a token cache by the provider.
+*/
+type TokenProvider = p.TokenProvider
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+type mockTokenCtxKey struct{}
+
+func TestMockToken(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	type auth struct{ method, token string }
+	var mu sync.Mutex
+	var auths []auth
+	s.SetTokenAcceptor(func(method, token string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		auths = append(auths, auth{method, token})
+		if strings.HasSuffix(token, "expired") {
+			return "", errors.New("token expired")
+		}
+		return "TOKENUSER", nil
+	})
+
+	jwt := "eyJhbGciOiJSUzI1NiJ9." + strings.Repeat("x", 300) // exceeds short authentication field size
+	saml := "<saml2:Assertion>" + strings.Repeat("x", 300) + "</saml2:Assertion>"
+
+	var tokenNo int32
+	provider := func(ctx context.Context) (string, error) {
+		if v, _ := ctx.Value(mockTokenCtxKey{}).(string); v != "token" {
+			return "", errors.New("invalid context")
+		}
+		switch n := atomic.AddInt32(&tokenNo, 1); n {
+		case 1, 2:
+			return jwt + strconv.Itoa(int(n)), nil // refreshed token per connection
+		case 3:
+			return saml, nil
+		default:
+			return jwt + "expired", nil
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), mockTokenCtxKey{}, "token")
+	connector := driver.NewTokenAuthConnector(s.Host(), provider)
+	for i := 0; i < 3; i++ {
+		conn, err := connector.NativeConn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Ping(ctx); err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+	if _, err := connector.NativeConn(ctx); err == nil {
+		t.Fatal("expected authentication error")
+	}
+
+	mu.Lock()
+	got := auths
+	mu.Unlock()
+	exp := []auth{{"JWT", jwt + "1"}, {"JWT", jwt + "2"}, {"SAML", saml}, {"JWT", jwt + "expired"}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("authentications %v - expected %v", got, exp)
+	}
+
+	// token provider error
+	errProvider := errors.New("token provider error")
+	connector = driver.NewTokenAuthConnector(s.Host(), func(ctx context.Context) (string, error) { return "", errProvider })
+	if _, err := connector.NativeConn(ctx); !errors.Is(err, errProvider) {
+		t.Fatalf("error %v - expected %v", err, errProvider)
+	}
+}
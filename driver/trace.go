@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"time"
+
+	p "github.com/SAP/go-hdb/internal/protocol"
+)
+
+// Operations of trace spans.
+const (
+	TraceOpPrepare  = opPrepare    // prepare of a statement
+	TraceOpExec     = opExec       // execution of a statement
+	TraceOpQuery    = opQuery      // execution of a query (without fetching further rows)
+	TraceOpFetch    = p.OpFetch    // fetch of result set rows
+	TraceOpLobRead  = p.OpLobRead  // read of lob content
+	TraceOpLobWrite = p.OpLobWrite // write of lob content
+	TraceOpCommit   = "commit"     // commit of a transaction
+	TraceOpRollback = "rollback"   // rollback of a transaction
+)
+
+// A TraceSpan describes a traced database operation.
+type TraceSpan struct {
+	Op        string        // operation (see TraceOp constants)
+	SessionID int64         // database session id
+	StmtID    uint64        // statement id of prepared statements (0: none or not known yet)
+	Query     string        // sql statement text (prepare, execution and query)
+	Start     time.Time     // start time of the operation
+	Duration  time.Duration // duration of the operation (set when the operation is finished)
+	Err       error         // error of the operation (set when the operation is finished)
+}
+
+/*
+TraceHook is the interface implemented by types tracing database operations (see Connector.SetTraceHook),
+e.g. to emit OpenTelemetry spans without wrapping the driver:
+
+	func (h *otelHook) Trace(ctx context.Context, s *driver.TraceSpan) func() {
+		_, span := h.tracer.Start(ctx, s.Op, trace.WithTimestamp(s.Start))
+		return func() {
+			span.SetAttributes(attribute.Int64("db.hana.session_id", s.SessionID), attribute.String("db.statement", s.Query))
+			if s.Err != nil {
+				span.RecordError(s.Err)
+			}
+			span.End(trace.WithTimestamp(s.Start.Add(s.Duration)))
+		}
+	}
+
+Trace is called before the operation and returns the function called after the operation (nil: none). Before
+the returned function is called, the duration, the error and (prepare) the statement id of span are set.
+Operations executed without context (fetch, lob read / write outside of an execution, commit and rollback)
+are traced with context.Background(). Trace and the returned function are called while the connection is
+locked and must not block.
+*/
+type TraceHook interface {
+	Trace(ctx context.Context, span *TraceSpan) func()
+}
+
+// trace calls the trace hook of the connection for operation op and returns the function to be called
+// with the error of the operation after the operation is finished.
+func (c *conn) trace(ctx context.Context, op, query string) func(err error) {
+	if c.traceHook == nil {
+		return func(error) {}
+	}
+	span := &TraceSpan{Op: op, SessionID: c.session.ID(), Query: query, Start: time.Now()}
+	prevSpan := c.span
+	c.span = span
+	end := c.traceHook.Trace(ctx, span)
+	return func(err error) {
+		c.span = prevSpan
+		span.Duration, span.Err = time.Since(span.Start), err
+		if end != nil {
+			end()
+		}
+	}
+}
+
+// traceStmtID sets the statement id of the current trace span (if any).
+func (c *conn) traceStmtID(stmtID uint64) {
+	if c.span != nil {
+		c.span.StmtID = stmtID
+	}
+}
+
+// observeSessionOp observes the session operation op (fetch, lob read / write) for statistics and tracing.
+func (c *conn) observeSessionOp(op string) func(err error) {
+	start := time.Now()
+	end := c.trace(context.Background(), op, "")
+	return func(err error) {
+		if op == StatsOpFetch {
+			c.observe(op, time.Since(start), err)
+		}
+		end(err)
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+type testTraceHook struct {
+	mu    sync.Mutex
+	spans []driver.TraceSpan
+}
+
+func (h *testTraceHook) Trace(ctx context.Context, span *driver.TraceSpan) func() {
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.spans = append(h.spans, *span)
+	}
+}
+
+func TestMockTraceHook(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	const numRow = 70 // first chunk of 32 rows (mock server) followed by 2 fetches of 20 rows
+	rows := make([][]interface{}, numRow)
+	for i := range rows {
+		rows[i] = []interface{}{int32(i)}
+	}
+	const (
+		insertQuery = "insert into t values (?)"
+		selectQuery = "select id from t"
+	)
+	s.Handle(selectQuery, &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "INTEGER"}}, Rows: rows})
+	s.Handle(insertQuery, &drivertest.MockStatement{Params: []string{"BLOB"}})
+	for _, query := range []string{"set transaction isolation level read committed", "set transaction read write"} {
+		s.Handle(query, &drivertest.MockStatement{})
+	}
+
+	hook := &testTraceHook{}
+	connector := driver.NewBasicAuthConnector(s.Host(), "user", "password")
+	connector.SetFetchSize(20)
+	connector.SetTraceHook(hook)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := tx.Prepare(insertQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stmt.Exec(new(driver.Lob).SetReader(strings.NewReader("lob content"))); err != nil {
+		t.Fatal(err)
+	}
+	stmt.Close()
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if tx, err = db.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := db.Query(selectQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r.Next() {
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	expOps := []string{driver.TraceOpPrepare, driver.TraceOpLobWrite, driver.TraceOpExec, driver.TraceOpCommit, driver.TraceOpRollback, driver.TraceOpQuery, driver.TraceOpFetch, driver.TraceOpFetch}
+	ops := make([]string, len(hook.spans))
+	for i, span := range hook.spans {
+		ops[i] = span.Op
+	}
+	if !reflect.DeepEqual(ops, expOps) {
+		t.Fatalf("traced operations %v - expected %v", ops, expOps)
+	}
+	for _, span := range hook.spans {
+		if span.SessionID == 0 || span.Start.IsZero() || span.Duration < 0 || span.Err != nil {
+			t.Fatalf("invalid span %+v", span)
+		}
+		switch span.Op {
+		case driver.TraceOpPrepare, driver.TraceOpExec:
+			if span.StmtID == 0 || span.Query != insertQuery {
+				t.Fatalf("span %+v - expected statement id and query %q", span, insertQuery)
+			}
+		case driver.TraceOpQuery:
+			if span.Query != selectQuery {
+				t.Fatalf("span %+v - expected query %q", span, selectQuery)
+			}
+		}
+	}
+}
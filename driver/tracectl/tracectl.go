@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package tracectl implements runtime control of the driver traces.
+
+The sql trace, the protocol trace and the slow query threshold can be changed at runtime
+via an HTTP handler or by sending a signal to the process, so that traces can be activated
+for debugging without restarting an application.
+
+Example (HTTP):
+
+	http.Handle("/debug/hdb/trace", tracectl.Handler())
+
+	curl http://localhost:8080/debug/hdb/trace
+	curl -X POST -d "sqlTrace=true&slowThreshold=500ms" http://localhost:8080/debug/hdb/trace
+
+Example (signal):
+
+	stop := tracectl.ToggleOnSignal(syscall.SIGUSR1)
+	defer stop()
+*/
+package tracectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/prottrace"
+	"github.com/SAP/go-hdb/driver/sqltrace"
+)
+
+// Parameter names of the trace settings.
+const (
+	ParamSQLTrace      = "sqlTrace"      // sql trace on (bool)
+	ParamSQLTraceJSON  = "sqlTraceJSON"  // sql trace JSON lines output (bool)
+	ParamProtocolTrace = "protocolTrace" // protocol trace on (bool)
+	ParamSlowThreshold = "slowThreshold" // slow query threshold (duration, e.g. 500ms, 0 = disabled)
+)
+
+// Settings represents the runtime trace settings.
+type Settings struct {
+	SQLTrace      bool   `json:"sqlTrace"`
+	SQLTraceJSON  bool   `json:"sqlTraceJSON"`
+	ProtocolTrace bool   `json:"protocolTrace"`
+	SlowThreshold string `json:"slowThreshold"`
+}
+
+// Current returns the current trace settings.
+func Current() Settings {
+	return Settings{
+		SQLTrace:      sqltrace.On(),
+		SQLTraceJSON:  sqltrace.JSON(),
+		ProtocolTrace: prottrace.On(),
+		SlowThreshold: sqltrace.SlowThreshold().String(),
+	}
+}
+
+// set applies the trace setting parameter name to value (parameter needs to be validated before).
+func set(name, value string) {
+	switch name {
+	case ParamSQLTrace:
+		b, _ := strconv.ParseBool(value)
+		sqltrace.SetOn(b)
+	case ParamSQLTraceJSON:
+		b, _ := strconv.ParseBool(value)
+		sqltrace.SetJSON(b)
+	case ParamProtocolTrace:
+		b, _ := strconv.ParseBool(value)
+		prottrace.SetOn(b)
+	case ParamSlowThreshold:
+		d, _ := time.ParseDuration(value)
+		sqltrace.SetSlowThreshold(d)
+	}
+}
+
+/*
+Handler returns an HTTP handler controlling the driver traces.
+
+- GET returns the current trace settings as JSON object.
+- POST or PUT updates the trace settings given as form values (see Param constants) and
+  returns the updated settings.
+*/
+func Handler() http.Handler { return http.HandlerFunc(handle) }
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// validate all parameters before applying
+		for name, values := range r.Form {
+			if len(values) == 0 {
+				continue
+			}
+			if err := validate(name, values[len(values)-1]); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		for name, values := range r.Form {
+			if len(values) == 0 {
+				continue
+			}
+			set(name, values[len(values)-1])
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Current())
+}
+
+func validate(name, value string) error {
+	switch name {
+	case ParamSQLTrace, ParamSQLTraceJSON, ParamProtocolTrace:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value %s of parameter %s: %w", value, name, err)
+		}
+	case ParamSlowThreshold:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid value %s of parameter %s: %w", value, name, err)
+		}
+	default:
+		return fmt.Errorf("invalid parameter %s", name)
+	}
+	return nil
+}
+
+/*
+ToggleOnSignal toggles the sql trace each time one of the signals sig is received
+(e.g. syscall.SIGUSR1 on unix systems).
+The returned function stops the signal handling.
+*/
+func ToggleOnSignal(sig ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				sqltrace.SetOn(!sqltrace.On())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
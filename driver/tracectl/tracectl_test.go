@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracectl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/sqltrace"
+)
+
+func testHandlerUpdate(t *testing.T) {
+	defer sqltrace.SetOn(sqltrace.On())
+	defer sqltrace.SetSlowThreshold(sqltrace.SlowThreshold())
+
+	form := url.Values{ParamSQLTrace: {"true"}, ParamSlowThreshold: {"250ms"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code %d - expected %d", w.Code, http.StatusOK)
+	}
+	settings := Settings{}
+	if err := json.NewDecoder(w.Body).Decode(&settings); err != nil {
+		t.Fatal(err)
+	}
+	if !settings.SQLTrace || !sqltrace.On() {
+		t.Fatal("sql trace not activated")
+	}
+	if sqltrace.SlowThreshold() != 250*time.Millisecond {
+		t.Fatalf("slow threshold %s - expected %s", sqltrace.SlowThreshold(), 250*time.Millisecond)
+	}
+}
+
+func testHandlerInvalid(t *testing.T) {
+	for _, query := range []string{"sqlTrace=maybe", "slowThreshold=fast", "unknown=true"} {
+		r := httptest.NewRequest(http.MethodPost, "/?"+query, nil)
+		w := httptest.NewRecorder()
+		Handler().ServeHTTP(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status code %d - expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"update", testHandlerUpdate},
+		{"invalid", testHandlerInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
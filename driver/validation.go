@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is the error returned by Connector.Validate. It contains an error for each invalid connector setting.
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	s := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		s[i] = err.Error()
+	}
+	return fmt.Sprintf("invalid connector: %s", strings.Join(s, "; "))
+}
+
+// Is reports whether any of the contained errors matches target.
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateHost checks if host is a valid "host:port" address.
+func validateHost(host string) error {
+	if host == "" {
+		return errors.New("host is empty")
+	}
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return fmt.Errorf("invalid host %s: %s", host, err)
+	}
+	if hostname == "" {
+		return fmt.Errorf("invalid host %s: missing host name", host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid host %s: port %s out of range [1, 65535]", host, port)
+	}
+	return nil
+}
+
+/*
+Validate checks all connector settings and returns a ValidationError listing all invalid settings,
+so that misconfigurations are reported before a connection is opened (Validate is called by Connect as well).
+
+Checked are the host addresses ("host:port" with a port in the range 1 to 65535), the credentials,
+the size and timeout settings and the data format version. TLS root certificate files are checked
+while parsing the data source name (see DSNTLSRootCAFile).
+*/
+func (c *Connector) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var errs []error
+	add := func(err error) { errs = append(errs, err) }
+
+	if err := validateHost(c.host); err != nil {
+		add(err)
+	}
+	for _, host := range c.failoverHosts {
+		if err := validateHost(host); err != nil {
+			add(fmt.Errorf("failover %w", err))
+		}
+	}
+	if c.username == "" && c.gssProvider == nil && c.clientCert == nil && c.tokenProvider == nil {
+		add(errors.New("username is empty"))
+	}
+	if err := checkLimit("fetch size", c.fetchSize, minFetchSize, MaxFetchSize); err != nil {
+		add(err)
+	}
+	if err := checkLimit("bulk size", c.bulkSize, minBulkSize, MaxBulkSize); err != nil {
+		add(err)
+	}
+	if err := checkLimit("lob chunk size", int(c.lobChunkSize), minLobChunkSize, MaxLobChunkSize); err != nil {
+		add(err)
+	}
+	if c.timeout < minTimeout {
+		add(fmt.Errorf("timeout %d - minimum is %d", c.timeout, minTimeout))
+	}
+	if _, ok := supportedDfvs[c.dfv]; !ok {
+		add(fmt.Errorf("data format version %d is not supported", c.dfv))
+	}
+	if c.statementTimeout < 0 {
+		add(fmt.Errorf("invalid statement timeout %s", c.statementTimeout))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errs: errs}
+}
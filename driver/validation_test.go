@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package driver_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+)
+
+func TestMockConnectorValidate(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	if err := driver.NewBasicAuthConnector(s.Host(), "user", "password").Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var testData = []struct {
+		host     string
+		username string
+		numErr   int
+	}{
+		{"", "user", 1},
+		{"host", "user", 1},
+		{":30015", "user", 1},
+		{"host:70000", "", 2},
+		{"host:port", "user", 1},
+	}
+
+	for i, d := range testData {
+		err := driver.NewBasicAuthConnector(d.host, d.username, "password").Validate()
+		var verr *driver.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("%d: error %v - expected validation error", i, err)
+		}
+		if len(verr.Errs) != d.numErr {
+			t.Fatalf("%d: number of errors %d - expected %d (%s)", i, len(verr.Errs), d.numErr, verr)
+		}
+	}
+
+	// connect fails before dialing
+	db := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "", "password"))
+	defer db.Close()
+	var verr *driver.ValidationError
+	if err := db.Ping(); !errors.As(err, &verr) {
+		t.Fatalf("ping error %v - expected validation error", err)
+	}
+}
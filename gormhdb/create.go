@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gormhdb
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/SAP/go-hdb/driver/dialect"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnsupportedOnConflict is returned by creating records with a conflict clause which is neither
+// updating the record nor doing nothing (e.g. conflicts on constraints).
+var ErrUnsupportedOnConflict = errors.New("unsupported on conflict clause")
+
+// conflictMode is the handling of records conflicting with existing records.
+type conflictMode int
+
+const (
+	conflictNone    conflictMode = iota // insert
+	conflictUpdate                      // upsert with primary key
+	conflictNothing                     // insert if no record with the same primary key exists
+)
+
+func onConflict(stmt *gorm.Statement) (conflictMode, error) {
+	c, ok := stmt.Clauses["ON CONFLICT"]
+	if !ok {
+		return conflictNone, nil
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	switch {
+	case !ok || onConflict.OnConstraint != "":
+		return conflictNone, ErrUnsupportedOnConflict
+	case onConflict.DoNothing:
+		return conflictNothing, nil
+	case onConflict.UpdateAll || len(onConflict.DoUpdates) != 0:
+		return conflictUpdate, nil
+	}
+	return conflictNone, ErrUnsupportedOnConflict
+}
+
+// create replaces the GORM create callback.
+func create(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	stmt := db.Statement
+
+	if stmt.SQL.Len() != 0 { // statement built already
+		if !db.DryRun {
+			result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+			if db.AddError(err) == nil {
+				db.RowsAffected, _ = result.RowsAffected()
+			}
+		}
+		return
+	}
+
+	if stmt.Schema != nil && !stmt.Unscoped {
+		for _, c := range stmt.Schema.CreateClauses {
+			stmt.AddClause(c)
+		}
+	}
+	mode, err := onConflict(stmt)
+	if db.AddError(err) != nil {
+		return
+	}
+	values := callbacks.ConvertToCreateValues(stmt)
+	if db.Error != nil {
+		return
+	}
+
+	var pkField *schema.Field // identity column
+	if stmt.Schema != nil {
+		if f := stmt.Schema.PrioritizedPrimaryField; f != nil && f.AutoIncrement && f.Readable {
+			pkField = f
+		}
+	}
+
+	pool := stmt.ConnPool
+	if sqlDB, ok := pool.(*sql.DB); ok && pkField != nil && !db.DryRun {
+		// identity values need to be queried in the session of the insert statement
+		conn, err := sqlDB.Conn(stmt.Context)
+		if db.AddError(err) != nil {
+			return
+		}
+		defer conn.Close()
+		pool = conn
+	}
+
+	var result sql.Result
+	for i, row := range values.Values {
+		if db.DryRun { // statements of all records
+			if i > 0 {
+				stmt.WriteString("; ")
+			}
+		} else {
+			stmt.SQL.Reset()
+			stmt.Vars = nil
+		}
+
+		var columns []clause.Column
+		var vars []interface{}
+		for j, v := range row {
+			if !isDefaultValue(v) {
+				columns = append(columns, values.Columns[j])
+				vars = append(vars, v)
+			}
+		}
+		buildInsert(stmt, mode, columns, vars)
+		if db.DryRun {
+			continue
+		}
+
+		if result, err = pool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...); db.AddError(err) != nil {
+			return
+		}
+		rowsAffected, _ := result.RowsAffected()
+		db.RowsAffected += rowsAffected
+
+		if pkField == nil || rowsAffected == 0 {
+			continue
+		}
+		if err := setIdentity(stmt, pool, pkField, i); db.AddError(err) != nil {
+			return
+		}
+	}
+
+	if stmt.Result != nil {
+		stmt.Result.Result = result
+		stmt.Result.RowsAffected = db.RowsAffected
+	}
+}
+
+// buildInsert builds the statement inserting the record with values vars of columns.
+func buildInsert(stmt *gorm.Statement, mode conflictMode, columns []clause.Column, vars []interface{}) {
+	var pks []clause.Column // primary key columns of conflicting records
+	var pkVars []interface{}
+	if mode != conflictNone && stmt.Schema != nil {
+		for i, c := range columns {
+			if f := stmt.Schema.LookUpField(c.Name); f != nil && f.PrimaryKey {
+				pks = append(pks, c)
+				pkVars = append(pkVars, vars[i])
+			}
+		}
+	}
+	if len(pks) == 0 { // records without primary key value (e.g. identity) do not conflict
+		mode = conflictNone
+	}
+
+	if mode == conflictUpdate {
+		stmt.WriteString("UPSERT ")
+	} else {
+		stmt.WriteString("INSERT INTO ")
+	}
+	stmt.WriteQuoted(clause.Table{Name: clause.CurrentTable})
+	stmt.WriteString(" (")
+	for i, c := range columns {
+		if i > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.WriteQuoted(c)
+	}
+	stmt.WriteByte(')')
+
+	if mode != conflictNothing {
+		stmt.WriteString(" VALUES (")
+		stmt.AddVar(stmt, vars...)
+		stmt.WriteByte(')')
+		if mode == conflictUpdate {
+			stmt.WriteString(" WITH PRIMARY KEY")
+		}
+		return
+	}
+
+	stmt.WriteString(" SELECT ")
+	stmt.AddVar(stmt, vars...)
+	stmt.WriteString(" FROM DUMMY WHERE NOT EXISTS (SELECT 1 FROM ")
+	stmt.WriteQuoted(clause.Table{Name: clause.CurrentTable})
+	stmt.WriteString(" WHERE ")
+	for i, c := range pks {
+		if i > 0 {
+			stmt.WriteString(" AND ")
+		}
+		stmt.WriteQuoted(c)
+		stmt.WriteString(" = ")
+		stmt.AddVar(stmt, pkVars[i])
+	}
+	stmt.WriteByte(')')
+}
+
+// setIdentity sets the identity value of the record idx if the record does not provide the value.
+func setIdentity(stmt *gorm.Statement, q dialect.Queryer, pkField *schema.Field, idx int) error {
+	switch values := stmt.Dest.(type) {
+	case map[string]interface{}:
+		return setMapIdentity(stmt, q, pkField, values)
+	case *map[string]interface{}:
+		return setMapIdentity(stmt, q, pkField, *values)
+	case []map[string]interface{}:
+		return setMapIdentity(stmt, q, pkField, values[idx])
+	case *[]map[string]interface{}:
+		return setMapIdentity(stmt, q, pkField, (*values)[idx])
+	}
+	rv := reflect.Indirect(stmt.ReflectValue)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		rv = reflect.Indirect(rv.Index(idx))
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, isZero := pkField.ValueOf(stmt.Context, rv); !isZero {
+		return nil
+	}
+	id, err := dialect.LastInsertID(stmt.Context, q)
+	if err != nil {
+		return err
+	}
+	return pkField.Set(stmt.Context, rv, id)
+}
+
+func setMapIdentity(stmt *gorm.Statement, q dialect.Queryer, pkField *schema.Field, values map[string]interface{}) error {
+	if _, ok := values[pkField.Name]; ok {
+		return nil
+	}
+	if _, ok := values[pkField.DBName]; ok {
+		return nil
+	}
+	id, err := dialect.LastInsertID(stmt.Context, q)
+	if err != nil {
+		return err
+	}
+	values[pkField.DBName] = id
+	return nil
+}
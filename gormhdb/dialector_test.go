@@ -0,0 +1,255 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+// +build unit
+
+package gormhdb_test
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+	"github.com/SAP/go-hdb/gormhdb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type user struct {
+	ID        uint
+	Name      string `gorm:"size:40;index"`
+	Age       int32
+	Balance   driver.Decimal `gorm:"precision:10;scale:2"`
+	Active    bool           `gorm:"default:true"`
+	Avatar    []byte
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+func openTestDB(t *testing.T, s *drivertest.MockServer, config *gorm.Config) *gorm.DB {
+	sqlDB := sql.OpenDB(driver.NewBasicAuthConnector(s.Host(), "user", "password"))
+	config.Logger = logger.Discard
+	db, err := gorm.Open(gormhdb.New(gormhdb.Config{Conn: sqlDB}), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestDataTypeOf(t *testing.T) {
+	sch, err := schema.Parse(&user{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := gormhdb.Open("")
+	tests := map[string]string{
+		"id":         "BIGINT",
+		"name":       "NVARCHAR(40)",
+		"age":        "INTEGER",
+		"balance":    "DECIMAL(10,2)",
+		"active":     "BOOLEAN",
+		"avatar":     "VARBINARY(256)",
+		"created_at": "TIMESTAMP",
+		"deleted_at": "TIMESTAMP",
+	}
+	for name, typ := range tests {
+		if got := d.DataTypeOf(sch.LookUpField(name)); got != typ {
+			t.Fatalf("field %s: type %s - expected %s", name, got, typ)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	db := openTestDB(t, s, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+
+	u := user{ID: 1, Name: "Alice", CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		tx  *gorm.DB
+		sql string
+	}{
+		{db.Omit("balance", "avatar", "deleted_at").Create(&u), `INSERT INTO "users" ("name","age","active","created_at","id") VALUES ('Alice',0,true,'2020-01-01 00:00:00',1)`},
+		{db.Omit("balance", "avatar", "deleted_at").Clauses(clause.OnConflict{UpdateAll: true}).Create(&u), `UPSERT "users" ("name","age","active","created_at","id") VALUES ('Alice',0,true,'2020-01-01 00:00:00',1) WITH PRIMARY KEY`},
+		{db.Omit("balance", "avatar", "deleted_at").Clauses(clause.OnConflict{DoNothing: true}).Create(&u),
+			`INSERT INTO "users" ("name","age","active","created_at","id") SELECT 'Alice',0,true,'2020-01-01 00:00:00',1 FROM DUMMY WHERE NOT EXISTS (SELECT 1 FROM "users" WHERE "id" = 1)`},
+		{db.Unscoped().Offset(10).Find(&[]user{}), `SELECT * FROM "users" LIMIT 2147483647 OFFSET 10`},
+		{db.Unscoped().Limit(5).Offset(10).Find(&[]user{}), `SELECT * FROM "users" LIMIT 5 OFFSET 10`},
+	}
+	for _, test := range tests {
+		if test.tx.Error != nil {
+			t.Fatal(test.tx.Error)
+		}
+		if got := db.Dialector.Explain(test.tx.Statement.SQL.String(), test.tx.Statement.Vars...); got != test.sql {
+			t.Fatalf("sql %s - expected %s", got, test.sql)
+		}
+	}
+
+	if err := db.Clauses(clause.OnConflict{OnConstraint: "c"}).Create(&u).Error; !errors.Is(err, gormhdb.ErrUnsupportedOnConflict) {
+		t.Fatalf("error %v - expected %v", err, gormhdb.ErrUnsupportedOnConflict)
+	}
+}
+
+type item struct {
+	ID   int64
+	Name string
+}
+
+func TestMockCreate(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var names []string
+	var identity int64
+	for _, query := range []string{"set transaction isolation level read committed", "set transaction read write"} {
+		s.Handle(query, &drivertest.MockStatement{})
+	}
+	s.Handle(`INSERT INTO "items" ("name") VALUES (?)`, &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, args[0].(string))
+		identity++
+		return &drivertest.MockResult{RowsAffected: 1}, nil
+	}})
+	s.Handle(`INSERT INTO "items" ("name","id") VALUES (?,?)`, &drivertest.MockStatement{Params: []string{"NVARCHAR", "BIGINT"}, RowsAffected: 1})
+	s.Handle(`INSERT INTO "items" ("name","id") SELECT ?,? FROM DUMMY WHERE NOT EXISTS (SELECT 1 FROM "items" WHERE "id" = ?)`,
+		&drivertest.MockStatement{Params: []string{"NVARCHAR", "BIGINT", "BIGINT"}, RowsAffected: 0})
+	s.Handle("SELECT CURRENT_IDENTITY_VALUE() FROM DUMMY", &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "ID", TypeName: "BIGINT"}},
+		Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return &drivertest.MockResult{Rows: [][]interface{}{{identity}}}, nil
+		}})
+	s.Handle(`INSERT INTO "items" ("name") VALUES ('dup')`, &drivertest.MockStatement{Err: &drivertest.MockError{Code: driver.HdbErrCodeUniqueConstraint, Text: "unique constraint violated"}})
+
+	for _, skipTx := range []bool{false, true} {
+		db := openTestDB(t, s, &gorm.Config{SkipDefaultTransaction: skipTx, TranslateError: true})
+
+		mu.Lock()
+		names, identity = nil, 0
+		mu.Unlock()
+
+		items := []item{{Name: "a"}, {Name: "b", ID: 42}, {Name: "c"}}
+		tx := db.Create(&items)
+		if tx.Error != nil {
+			t.Fatal(tx.Error)
+		}
+		if tx.RowsAffected != 3 {
+			t.Fatalf("rows affected %d - expected %d", tx.RowsAffected, 3)
+		}
+		if exp := []item{{Name: "a", ID: 1}, {Name: "b", ID: 42}, {Name: "c", ID: 2}}; !reflect.DeepEqual(items, exp) {
+			t.Fatalf("items %v - expected %v", items, exp)
+		}
+
+		m := map[string]interface{}{"Name": "d"}
+		if err := db.Model(&item{}).Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+		if m["id"] != int64(3) {
+			t.Fatalf("map identity %v - expected %d", m["id"], 3)
+		}
+		mu.Lock()
+		if exp := []string{"a", "c", "d"}; !reflect.DeepEqual(names, exp) {
+			t.Fatalf("names %v - expected %v", names, exp)
+		}
+		mu.Unlock()
+
+		tx = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&item{Name: "e", ID: 42})
+		if tx.Error != nil {
+			t.Fatal(tx.Error)
+		}
+		if tx.RowsAffected != 0 {
+			t.Fatalf("rows affected %d - expected %d", tx.RowsAffected, 0)
+		}
+
+		if err := db.Exec(`INSERT INTO "items" ("name") VALUES ('dup')`).Error; !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Fatalf("error %v - expected %v", err, gorm.ErrDuplicatedKey)
+		}
+	}
+}
+
+func TestMockMigrator(t *testing.T) {
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var stmts []string
+	handle := func(query string) {
+		s.Handle(query, &drivertest.MockStatement{Func: func(args []interface{}) (*drivertest.MockResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			stmts = append(stmts, query)
+			return &drivertest.MockResult{}, nil
+		}})
+	}
+	count := func(query string, n int64) {
+		s.Handle(query, &drivertest.MockStatement{Columns: []drivertest.MockColumn{{Name: "COUNT", TypeName: "BIGINT"}}, Rows: [][]interface{}{{n}}})
+	}
+
+	count("SELECT COUNT(*) FROM SYS.TABLES WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ?", 0)
+	count("SELECT COUNT(*) FROM SYS.TABLE_COLUMNS WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ? AND COLUMN_NAME = ?", 1)
+	count("SELECT COUNT(*) FROM SYS.INDEXES WHERE SCHEMA_NAME = CURRENT_SCHEMA AND TABLE_NAME = ? AND INDEX_NAME = ?", 0)
+	createTable := `CREATE TABLE "users" ("id" BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL,"name" NVARCHAR(40),"age" INTEGER,` +
+		`"balance" DECIMAL(10,2),"active" BOOLEAN DEFAULT true,"avatar" VARBINARY(256),"created_at" TIMESTAMP,"deleted_at" TIMESTAMP,PRIMARY KEY ("id"))`
+	for _, query := range []string{
+		createTable,
+		`CREATE INDEX "idx_users_name" ON "users"("name")`,
+		`ALTER TABLE "users" ADD ("age" INTEGER)`,
+		`ALTER TABLE "users" ALTER ("id" BIGINT NOT NULL)`,
+		`ALTER TABLE "users" DROP ("age")`,
+		`RENAME COLUMN "users"."name" TO "full_name"`,
+		`RENAME TABLE "users" TO "persons"`,
+		`RENAME INDEX "idx_users_name" TO "idx_users_full_name"`,
+		`DROP INDEX "idx_users_name"`,
+	} {
+		handle(query)
+	}
+
+	db := openTestDB(t, s, &gorm.Config{SkipDefaultTransaction: true})
+	m := db.Migrator()
+	if m.HasTable(&user{}) {
+		t.Fatal("table not expected")
+	}
+	if err := m.CreateTable(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	if !m.HasColumn(&user{}, "Name") {
+		t.Fatal("column expected")
+	}
+	if m.HasIndex(&user{}, "Name") {
+		t.Fatal("index not expected")
+	}
+	for _, f := range []func() error{
+		func() error { return m.AddColumn(&user{}, "Age") },
+		func() error { return m.AlterColumn(&user{}, "ID") },
+		func() error { return m.DropColumn(&user{}, "Age") },
+		func() error { return m.RenameColumn(&user{}, "name", "full_name") },
+		func() error { return m.RenameTable(&user{}, "persons") },
+		func() error { return m.RenameIndex(&user{}, "idx_users_name", "idx_users_full_name") },
+		func() error { return m.DropIndex(&user{}, "Name") },
+	} {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.DropTable(&user{}); err != nil { // table does not exist
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(stmts) != 9 || stmts[0] != createTable {
+		t.Fatalf("statements %v", stmts)
+	}
+}
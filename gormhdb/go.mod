@@ -0,0 +1,17 @@
+module github.com/SAP/go-hdb/gormhdb
+
+go 1.18
+
+require (
+	github.com/SAP/go-hdb v0.0.0
+	gorm.io/gorm v1.31.2
+)
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
+	golang.org/x/text v0.20.0 // indirect
+)
+
+replace github.com/SAP/go-hdb => ../
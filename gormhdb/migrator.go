@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gormhdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver/dialect"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// Migrator is the GORM migrator for HANA.
+// It replaces the ddl statements and catalog queries of the GORM default migrator not supported by HANA.
+type Migrator struct {
+	migrator.Migrator
+}
+
+// check if Migrator implements the gorm.Migrator interface.
+var _ gorm.Migrator = Migrator{}
+
+// CurrentDatabase returns the current schema.
+func (m Migrator) CurrentDatabase() (name string) {
+	m.DB.Raw(dialect.CurrentSchemaQuery).Row().Scan(&name)
+	return name
+}
+
+// GetTables returns the tables of the current schema.
+func (m Migrator) GetTables() (tables []string, err error) {
+	err = m.DB.Raw(dialect.TablesQuery).Scan(&tables).Error
+	return tables, err
+}
+
+// FullDataTypeOf returns the column definition of field in HANA clause order.
+func (m Migrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	return m.columnDefinition(field, true)
+}
+
+// columnDefinition returns the column definition of field, optionally including the identity clause.
+func (m Migrator) columnDefinition(field *schema.Field, identity bool) clause.Expr {
+	expr := clause.Expr{SQL: m.DataTypeOf(field)}
+	if field.HasDefaultValue && (field.DefaultValueInterface != nil || field.DefaultValue != "") {
+		if field.DefaultValueInterface != nil {
+			defaultStmt := &gorm.Statement{Vars: []interface{}{field.DefaultValueInterface}}
+			m.Dialector.BindVarTo(defaultStmt, defaultStmt, field.DefaultValueInterface)
+			expr.SQL += " DEFAULT " + m.Dialector.Explain(defaultStmt.SQL.String(), field.DefaultValueInterface)
+		} else if field.DefaultValue != "(-)" {
+			expr.SQL += " DEFAULT " + field.DefaultValue
+		}
+	}
+	if field.AutoIncrement && identity {
+		expr.SQL += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if field.NotNull || field.PrimaryKey || field.AutoIncrement {
+		expr.SQL += " NOT NULL"
+	}
+	return expr
+}
+
+// field returns the field name of the schema of stmt.
+func field(stmt *gorm.Statement, name string) (*schema.Field, error) {
+	if stmt.Schema == nil {
+		return nil, errors.New("failed to get schema")
+	}
+	f := stmt.Schema.LookUpField(name)
+	if f == nil {
+		return nil, fmt.Errorf("failed to look up field with name: %s", name)
+	}
+	return f, nil
+}
+
+// columnName returns the column name of the field name of stmt (name: no field found).
+func columnName(stmt *gorm.Statement, name string) string {
+	if stmt.Schema != nil {
+		if f := stmt.Schema.LookUpField(name); f != nil {
+			return f.DBName
+		}
+	}
+	return name
+}
+
+// DropTable drops the existing tables of values.
+func (m Migrator) DropTable(values ...interface{}) error {
+	values = m.ReorderModels(values, false)
+	for i := len(values) - 1; i >= 0; i-- {
+		if !m.HasTable(values[i]) {
+			continue
+		}
+		if err := m.RunWithValue(values[i], func(stmt *gorm.Statement) error {
+			return m.DB.Exec("DROP TABLE ? CASCADE", m.CurrentTable(stmt)).Error
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasTable returns true if the table of value exists.
+func (m Migrator) HasTable(value interface{}) bool {
+	var count int64
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Raw(dialect.HasTableQuery, stmt.Table).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+// table returns the table of value (table name or model).
+func (m Migrator) table(value interface{}) (interface{}, error) {
+	if name, ok := value.(string); ok {
+		return clause.Table{Name: name}, nil
+	}
+	stmt := &gorm.Statement{DB: m.DB}
+	if err := stmt.Parse(value); err != nil {
+		return nil, err
+	}
+	return m.CurrentTable(stmt), nil
+}
+
+// RenameTable renames the table oldName to newName.
+func (m Migrator) RenameTable(oldName, newName interface{}) error {
+	oldTable, err := m.table(oldName)
+	if err != nil {
+		return err
+	}
+	newTable, err := m.table(newName)
+	if err != nil {
+		return err
+	}
+	return m.DB.Exec("RENAME TABLE ? TO ?", oldTable, newTable).Error
+}
+
+// AddColumn adds the column of field name to the table of value.
+func (m Migrator) AddColumn(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		f, err := field(stmt, name)
+		if err != nil || f.IgnoreMigration {
+			return err
+		}
+		return m.DB.Exec("ALTER TABLE ? ADD (? ?)", m.CurrentTable(stmt), clause.Column{Name: f.DBName}, m.FullDataTypeOf(f)).Error
+	})
+}
+
+// DropColumn drops the column of field name from the table of value.
+func (m Migrator) DropColumn(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Exec("ALTER TABLE ? DROP (?)", m.CurrentTable(stmt), clause.Column{Name: columnName(stmt, name)}).Error
+	})
+}
+
+// AlterColumn changes the column of field name to the field definition.
+// The identity property of columns cannot be changed.
+func (m Migrator) AlterColumn(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		f, err := field(stmt, name)
+		if err != nil {
+			return err
+		}
+		return m.DB.Exec("ALTER TABLE ? ALTER (? ?)", m.CurrentTable(stmt), clause.Column{Name: f.DBName}, m.columnDefinition(f, false)).Error
+	})
+}
+
+// HasColumn returns true if the column of field name exists in the table of value.
+func (m Migrator) HasColumn(value interface{}, name string) bool {
+	var count int64
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Raw(dialect.HasColumnQuery, stmt.Table, columnName(stmt, name)).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+// RenameColumn renames the column of field oldName to the column of field newName.
+func (m Migrator) RenameColumn(value interface{}, oldName, newName string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Exec("RENAME COLUMN ?.? TO ?", m.CurrentTable(stmt),
+			clause.Column{Name: columnName(stmt, oldName)}, clause.Column{Name: columnName(stmt, newName)}).Error
+	})
+}
+
+// HasConstraint returns true if the constraint name of the table of value exists.
+func (m Migrator) HasConstraint(value interface{}, name string) bool {
+	var count int64
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		constraint, table := m.GuessConstraintInterfaceAndTable(stmt, name)
+		if constraint != nil {
+			name = constraint.GetName()
+		}
+		return m.DB.Raw(dialect.HasConstraintQuery, table, name).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+// indexName returns the name of the index name of stmt (name: no index found).
+func indexName(stmt *gorm.Statement, name string) string {
+	if stmt.Schema != nil {
+		if idx := stmt.Schema.LookIndex(name); idx != nil {
+			return idx.Name
+		}
+	}
+	return name
+}
+
+// DropIndex drops the index name of the table of value.
+func (m Migrator) DropIndex(value interface{}, name string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Exec("DROP INDEX ?", clause.Column{Name: indexName(stmt, name)}).Error
+	})
+}
+
+// HasIndex returns true if the index name of the table of value exists.
+func (m Migrator) HasIndex(value interface{}, name string) bool {
+	var count int64
+	m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Raw(dialect.HasIndexQuery, stmt.Table, indexName(stmt, name)).Row().Scan(&count)
+	})
+	return count > 0
+}
+
+// RenameIndex renames the index oldName of the table of value to newName.
+func (m Migrator) RenameIndex(value interface{}, oldName, newName string) error {
+	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		return m.DB.Exec("RENAME INDEX ? TO ?", clause.Column{Name: oldName}, clause.Column{Name: newName}).Error
+	})
+}
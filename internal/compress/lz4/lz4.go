@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lz4 implements the LZ4 block format used for hdb protocol message compression.
+// Only the block format is supported (no frame format).
+package lz4
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	minMatch     = 4
+	hashLog      = 12
+	mfLimit      = 12 // the last match must start at least mfLimit bytes before the end of the block
+	lastLiterals = 5  // the last lastLiterals bytes of the block are always literals
+	maxOffset    = 1<<16 - 1
+)
+
+// ErrCorrupt is returned by Uncompress in case of corrupt input data.
+var ErrCorrupt = errors.New("lz4: corrupt input")
+
+// CompressBound returns the maximum size of the compressed data of n input bytes.
+func CompressBound(n int) int { return n + n/255 + 16 }
+
+func hash(v uint32) uint32 { return (v * 2654435761) >> (32 - hashLog) }
+
+func appendLength(dst []byte, l int) []byte {
+	for ; l >= 0xff; l -= 0xff {
+		dst = append(dst, 0xff)
+	}
+	return append(dst, byte(l))
+}
+
+func appendSequence(dst, literals []byte, offset, matchLen int) []byte {
+	litLen := len(literals)
+	matchLen -= minMatch
+
+	var token byte
+	if litLen < 0xf {
+		token = byte(litLen) << 4
+	} else {
+		token = 0xf0
+	}
+	if matchLen < 0xf {
+		token |= byte(matchLen)
+	} else {
+		token |= 0x0f
+	}
+	dst = append(dst, token)
+	if litLen >= 0xf {
+		dst = appendLength(dst, litLen-0xf)
+	}
+	dst = append(dst, literals...)
+	if offset == 0 { // last sequence
+		return dst
+	}
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if matchLen >= 0xf {
+		dst = appendLength(dst, matchLen-0xf)
+	}
+	return dst
+}
+
+// Compress appends the LZ4 block compressed data of src to dst[:0] and returns the resulting byte slice.
+func Compress(dst, src []byte) []byte {
+	dst = dst[:0]
+
+	var table [1 << hashLog]int32 // source positions + 1 (0: no entry)
+
+	n := len(src)
+	anchor := 0
+	for i := 0; i < n-mfLimit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := hash(seq)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if ref < 0 || i-ref > maxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+			continue
+		}
+		matchLen := minMatch
+		for i+matchLen < n-lastLiterals && src[ref+matchLen] == src[i+matchLen] {
+			matchLen++
+		}
+		dst = appendSequence(dst, src[anchor:i], i-ref, matchLen)
+		i += matchLen
+		anchor = i
+	}
+	return appendSequence(dst, src[anchor:], 0, minMatch)
+}
+
+func readLength(src []byte, si, l int) (int, int, error) {
+	for {
+		if si >= len(src) {
+			return si, l, ErrCorrupt
+		}
+		b := src[si]
+		si++
+		l += int(b)
+		if b != 0xff {
+			return si, l, nil
+		}
+	}
+}
+
+// Uncompress uncompresses the LZ4 block compressed data of src into dst and returns the number of bytes written.
+// The size of dst needs to be at least the size of the uncompressed data.
+func Uncompress(dst, src []byte) (int, error) {
+	var err error
+
+	si, di := 0, 0
+	for si < len(src) {
+		token := src[si]
+		si++
+
+		litLen := int(token >> 4)
+		if litLen == 0xf {
+			if si, litLen, err = readLength(src, si, litLen); err != nil {
+				return di, err
+			}
+		}
+		if si+litLen > len(src) || di+litLen > len(dst) {
+			return di, ErrCorrupt
+		}
+		di += copy(dst[di:], src[si:si+litLen])
+		si += litLen
+		if si == len(src) { // last sequence
+			return di, nil
+		}
+
+		if si+2 > len(src) {
+			return di, ErrCorrupt
+		}
+		offset := int(src[si]) | int(src[si+1])<<8
+		si += 2
+		if offset == 0 || offset > di {
+			return di, ErrCorrupt
+		}
+
+		matchLen := int(token & 0xf)
+		if matchLen == 0xf {
+			if si, matchLen, err = readLength(src, si, matchLen); err != nil {
+				return di, err
+			}
+		}
+		matchLen += minMatch
+		if di+matchLen > len(dst) {
+			return di, ErrCorrupt
+		}
+		if offset >= matchLen {
+			di += copy(dst[di:di+matchLen], dst[di-offset:])
+		} else { // overlapping match
+			for end := di + matchLen; di < end; di++ {
+				dst[di] = dst[di-offset]
+			}
+		}
+	}
+	return di, nil
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package lz4
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func testRoundtrip(t *testing.T, src []byte) {
+	c := Compress(nil, src)
+	if len(c) > CompressBound(len(src)) {
+		t.Fatalf("compressed size %d exceeds bound %d", len(c), CompressBound(len(src)))
+	}
+	dst := make([]byte, len(src))
+	n, err := Uncompress(dst, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(src) {
+		t.Fatalf("uncompressed size %d - expected %d", n, len(src))
+	}
+	if !bytes.Equal(dst, src) {
+		t.Fatal("uncompressed data differs from source data")
+	}
+}
+
+func testCompress(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	random := make([]byte, 100000)
+	rnd.Read(random)
+
+	tests := [][]byte{
+		nil,
+		[]byte("a"),
+		[]byte("abcdefghijklm"),
+		bytes.Repeat([]byte("a"), 100000),
+		bytes.Repeat([]byte("go-hdb "), 10000),
+		random,
+	}
+	for _, src := range tests {
+		testRoundtrip(t, src)
+	}
+
+	c := Compress(nil, bytes.Repeat([]byte("go-hdb "), 10000))
+	if len(c) > 1000 {
+		t.Fatalf("compressed size %d - expected size <= %d", len(c), 1000)
+	}
+}
+
+func testUncompressCorrupt(t *testing.T) {
+	c := Compress(nil, bytes.Repeat([]byte("go-hdb "), 100))
+	dst := make([]byte, 700)
+
+	tests := [][]byte{
+		c[:len(c)-10],                    // truncated
+		{0x1f, 'a', 0x02, 0x00},          // offset out of range
+		{0x00, 0x00, 0x00},               // zero offset
+		{0xf0},                           // missing literal length
+		append([]byte{0xf0, 0xff}, c...), // literals exceed source
+	}
+	for i, src := range tests {
+		if _, err := Uncompress(dst, src); err != ErrCorrupt {
+			t.Fatalf("test %d: error %v - expected %v", i, err, ErrCorrupt)
+		}
+	}
+	if _, err := Uncompress(make([]byte, 10), c); err != ErrCorrupt { // destination too small
+		t.Fatalf("error %v - expected %v", err, ErrCorrupt)
+	}
+}
+
+func TestLZ4(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"compress", testCompress},
+		{"uncompressCorrupt", testUncompressCorrupt},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -53,6 +53,18 @@ func (vm *VarMap) StoreMap(m map[string]string) {
 	atomic.StoreInt32(&vm.updated, 1)
 }
 
+// UpdateMap stores the key value pairs of m in VarMap keeping all other entries.
+func (vm *VarMap) UpdateMap(m map[string]string) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	for k, v := range m {
+		vm.m[k] = v
+		vm.c[k] = false
+	}
+	atomic.StoreInt32(&vm.updated, 1)
+}
+
 // LoadMap returns the content of a VarMap as string key value map.
 func (vm *VarMap) LoadMap() map[string]string {
 	vm.mu.Lock()
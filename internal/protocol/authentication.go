@@ -79,6 +79,65 @@ func (_authShortBytes) encode(enc *encoding.Encoder, b []byte) error {
 	return nil
 }
 
+// authentication field length indicators.
+const (
+	authMaxShortFieldSize = 245 // maximal size of a field with a one byte length indicator
+	authFieldSize2        = 246 // indicator of a field with a two byte (big endian) length
+)
+
+// _authBytes encodes fields which might exceed the short field size (e.g. security tokens) by an extended length indicator.
+type _authBytes struct{}
+
+var authBytes = _authBytes{}
+
+func (_authBytes) lenSize(size int) int {
+	if size <= authMaxShortFieldSize {
+		return 1
+	}
+	return 3
+}
+
+func (a _authBytes) size(b []byte) int { return a.lenSize(len(b)) + len(b) }
+
+func (_authBytes) decodeLen(dec *encoding.Decoder) int {
+	size := dec.Byte()
+	if size != authFieldSize2 {
+		return int(size)
+	}
+	var b [2]byte
+	dec.Bytes(b[:])
+	return int(binary.BigEndian.Uint16(b[:]))
+}
+
+func (_authBytes) encodeLen(enc *encoding.Encoder, size int) error {
+	switch {
+	case size <= authMaxShortFieldSize:
+		enc.Byte(byte(size))
+	case size <= math.MaxUint16:
+		enc.Byte(authFieldSize2)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(size))
+		enc.Bytes(b[:])
+	default:
+		return fmt.Errorf("invalid auth parameter length %d", size)
+	}
+	return nil
+}
+
+func (a _authBytes) decode(dec *encoding.Decoder) []byte {
+	b := make([]byte, a.decodeLen(dec))
+	dec.Bytes(b)
+	return b
+}
+
+func (a _authBytes) encode(enc *encoding.Encoder, b []byte) error {
+	if err := a.encodeLen(enc, len(b)); err != nil {
+		return err
+	}
+	enc.Bytes(b)
+	return nil
+}
+
 type authMethod struct {
 	method          string
 	clientChallenge []byte
@@ -89,15 +148,15 @@ func (m *authMethod) String() string {
 }
 
 func (m *authMethod) size() int {
-	size := 2 // number of parameters
+	size := 1 // len byte method
 	size += len(m.method)
-	size += len(m.clientChallenge)
+	size += authBytes.size(m.clientChallenge)
 	return size
 }
 
 func (m *authMethod) decode(dec *encoding.Decoder, ph *partHeader) error {
 	m.method = string(authShortBytes.decode(dec))
-	m.clientChallenge = authShortBytes.decode(dec)
+	m.clientChallenge = authBytes.decode(dec)
 	return nil
 }
 
@@ -105,7 +164,7 @@ func (m *authMethod) encode(enc *encoding.Encoder) error {
 	if err := authShortBytes.encode(enc, []byte(m.method)); err != nil {
 		return err
 	}
-	if err := authShortBytes.encode(enc, m.clientChallenge); err != nil {
+	if err := authBytes.encode(enc, m.clientChallenge); err != nil {
 		return err
 	}
 	return nil
@@ -151,7 +210,9 @@ func (r *authInitReq) encode(enc *encoding.Encoder) error {
 		return err
 	}
 	for _, m := range r.methods {
-		m.encode(enc)
+		if err := m.encode(enc); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -216,7 +277,7 @@ func (r *authInitRep) decode(dec *encoding.Decoder, ph *partHeader) error {
 	}
 	r.method = string(authShortBytes.decode(dec))
 
-	dec.Byte() // sub parameter length
+	authBytes.decodeLen(dec) // sub parameter length
 
 	switch r.method {
 	case mnSCRAMSHA256:
@@ -225,6 +286,15 @@ func (r *authInitRep) decode(dec *encoding.Decoder, ph *partHeader) error {
 	case mnSCRAMPBKDF2SHA256:
 		r.prms = &authInitSCRAMPBKDF2SHA256Rep{}
 		return r.prms.decode(dec, ph)
+	case mnGSS:
+		r.prms = &authGSSPrms{}
+		return r.prms.decode(dec, ph)
+	case mnX509:
+		r.prms = &authX509InitRep{}
+		return r.prms.decode(dec, ph)
+	case mnJWT, mnSAML:
+		r.prms = &authLogonNameRep{}
+		return r.prms.decode(dec, ph)
 	default:
 		return fmt.Errorf("invalid or not supported authentication method %s", r.method)
 	}
@@ -272,7 +342,7 @@ func (r *authFinalReq) size() int {
 	size := int16Size // no of parameters
 	size += cesu8.StringSize(r.username) + 1
 	size += len(r.method) + 1
-	size++ // len sub parameters
+	size += authBytes.lenSize(r.prms.size()) // len sub parameters
 	size += r.prms.size()
 	return size
 }
@@ -284,8 +354,17 @@ func (r *authFinalReq) decode(dec *encoding.Decoder, ph *partHeader) error {
 	}
 	r.username = authShortCESU8String.decode(dec)
 	r.method = string(authShortBytes.decode(dec))
-	dec.Byte() // sub parameters
-	r.prms = &authClientProofReq{}
+	authBytes.decodeLen(dec) // sub parameters
+	switch r.method {
+	case mnGSS:
+		r.prms = &authGSSPrms{}
+	case mnX509:
+		r.prms = &authX509FinalReq{}
+	case mnJWT, mnSAML:
+		r.prms = authNoPrms{}
+	default:
+		r.prms = &authClientProofReq{}
+	}
 	return r.prms.decode(dec, ph)
 }
 
@@ -297,7 +376,9 @@ func (r *authFinalReq) encode(enc *encoding.Encoder) error {
 	if err := authShortBytes.encode(enc, []byte(r.method)); err != nil {
 		return err
 	}
-	enc.Byte(byte(r.prms.size()))
+	if err := authBytes.encodeLen(enc, r.prms.size()); err != nil {
+		return err
+	}
 	return r.prms.encode(enc)
 }
 
@@ -341,14 +422,45 @@ func (r *authFinalRep) decode(dec *encoding.Decoder, ph *partHeader) error {
 		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 2)
 	}
 	r.method = string(authShortBytes.decode(dec))
-	if size := dec.Byte(); size == 0 { // sub parameter length
+	if size := authBytes.decodeLen(dec); size == 0 { // sub parameter length
 		// mnSCRAMSHA256: server does not return server proof parameter
 		return nil
 	}
-	r.prms = &authServerProofRep{}
+	switch r.method {
+	case mnGSS:
+		r.prms = &authGSSPrms{}
+	case mnX509:
+		r.prms = &authLogonNameRep{}
+	default:
+		r.prms = &authServerProofRep{}
+	}
 	return r.prms.decode(dec, ph)
 }
 
+// authLogonNameRep is the reply of the authentication methods returning the database user the client is mapped to
+// (X509: final reply, JWT and SAML: init reply).
+type authLogonNameRep struct {
+	logonName string
+}
+
+func (r *authLogonNameRep) String() string { return fmt.Sprintf("logonName %s", r.logonName) }
+
+func (r *authLogonNameRep) size() int { return int16Size + authBytes.size([]byte(r.logonName)) }
+
+func (r *authLogonNameRep) decode(dec *encoding.Decoder, ph *partHeader) error {
+	numPrm := int(dec.Int16())
+	if numPrm != 1 {
+		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 1)
+	}
+	r.logonName = string(authBytes.decode(dec))
+	return nil
+}
+
+func (r *authLogonNameRep) encode(enc *encoding.Encoder) error {
+	enc.Int16(1)
+	return authBytes.encode(enc, []byte(r.logonName))
+}
+
 type auth struct {
 	step               int
 	username, password string
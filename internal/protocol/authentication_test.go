@@ -5,7 +5,11 @@
 package protocol
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
 
 func TestAuthentication(t *testing.T) {
@@ -58,3 +62,53 @@ func TestAuthentication(t *testing.T) {
 
 	}
 }
+
+func TestAuthBytes(t *testing.T) {
+	for _, size := range []int{0, 1, authMaxShortFieldSize, authMaxShortFieldSize + 1, 4096} {
+		b := bytes.Repeat([]byte{0x42}, size)
+
+		buf := new(bytes.Buffer)
+		enc := encoding.NewEncoder(buf)
+		if err := authBytes.encode(enc, b); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != authBytes.size(b) {
+			t.Fatalf("size %d: encoded size %d - expected %d", size, buf.Len(), authBytes.size(b))
+		}
+		dec := encoding.NewDecoder(buf)
+		if r := authBytes.decode(dec); !bytes.Equal(r, b) {
+			t.Fatalf("size %d: decoded size %d - expected %d", size, len(r), size)
+		}
+	}
+	if err := authBytes.encodeLen(encoding.NewEncoder(new(bytes.Buffer)), 1<<16); err == nil {
+		t.Fatal("expected invalid length error")
+	}
+}
+
+func TestAuthGSSPrms(t *testing.T) {
+	prms := &authGSSPrms{oid: gssKerberosOID, token: bytes.Repeat([]byte{0x01}, 1024)}
+	b, err := prms.encodeBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != prms.size() {
+		t.Fatalf("encoded size %d - expected %d", len(b), prms.size())
+	}
+	r := &authGSSPrms{}
+	if err := r.decode(encoding.NewDecoder(bytes.NewReader(b)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, prms) {
+		t.Fatalf("parameters %v - expected %v", r, prms)
+	}
+
+	for _, test := range []struct{ spn, host, exp string }{
+		{"", "myhost.example.com:30015", "hdb/myhost.example.com"},
+		{"", "myhost", "hdb/myhost"},
+		{"hdb/other@EXAMPLE.COM", "myhost:30015", "hdb/other@EXAMPLE.COM"},
+	} {
+		if spn := gssServicePrincipal(test.spn, test.host); spn != test.exp {
+			t.Fatalf("service principal %s - expected %s", spn, test.exp)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+// GSS authentication (Kerberos).
+
+const mnGSS = "GSS"
+
+// gssKerberosOID is the DER encoded object identifier of the Kerberos V5 GSS-API mechanism (1.2.840.113554.1.2.2).
+var gssKerberosOID = []byte{0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x12, 0x01, 0x02, 0x02}
+
+// gssServicePrefix is the service name of the default service principal name (e.g. hdb/myhost.example.com).
+const gssServicePrefix = "hdb/"
+
+/*
+GSSProvider is the client side of a GSS-API security context (e.g. Kerberos based on a keytab or a credential cache).
+
+InitSecContext returns the next token of the security context established for the service principal name spn.
+It is called with a nil input token for the initial token and with the token returned by the server afterwards.
+An empty output token ends the token exchange.
+*/
+type GSSProvider interface {
+	InitSecContext(spn string, inputToken []byte) ([]byte, error)
+}
+
+// authGSSPrms are the GSS parameters of the authentication requests and replies: mechanism and token.
+type authGSSPrms struct {
+	oid, token []byte
+}
+
+func (p *authGSSPrms) String() string {
+	return fmt.Sprintf("oid %v token size %d", p.oid, len(p.token))
+}
+
+func (p *authGSSPrms) size() int {
+	return int16Size + authBytes.size(p.oid) + authBytes.size(p.token)
+}
+
+func (p *authGSSPrms) decode(dec *encoding.Decoder, ph *partHeader) error {
+	numPrm := int(dec.Int16())
+	if numPrm != 2 {
+		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 2)
+	}
+	p.oid = authBytes.decode(dec)
+	p.token = authBytes.decode(dec)
+	return nil
+}
+
+func (p *authGSSPrms) encode(enc *encoding.Encoder) error {
+	enc.Int16(2)
+	if err := authBytes.encode(enc, p.oid); err != nil {
+		return err
+	}
+	return authBytes.encode(enc, p.token)
+}
+
+// encodeBytes returns the parameters in the encoding of an authentication method field.
+func (p *authGSSPrms) encodeBytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := p.encode(encoding.NewEncoder(buf)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gssServicePrincipal returns the service principal name spn or, if empty, the default service principal name of host.
+func gssServicePrincipal(spn, host string) string {
+	if spn != "" {
+		return spn
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return gssServicePrefix + hostname
+	}
+	return gssServicePrefix + host
+}
+
+// gssAuth is the authentication stepper of the GSS method.
+type gssAuth struct {
+	step     int
+	username string
+	spn      string
+	provider GSSProvider
+	initRep  *authInitRep
+}
+
+func newGSSAuth(username, spn string, provider GSSProvider) *gssAuth {
+	return &gssAuth{username: username, spn: spn, provider: provider, initRep: &authInitRep{}}
+}
+
+func (a *gssAuth) next() (partReadWriter, error) {
+	defer func() { a.step++ }()
+
+	switch a.step {
+	case 0:
+		token, err := a.provider.InitSecContext(a.spn, nil)
+		if err != nil {
+			return nil, fmt.Errorf("gss initial token: %w", err)
+		}
+		b, err := (&authGSSPrms{oid: gssKerberosOID, token: token}).encodeBytes()
+		if err != nil {
+			return nil, err
+		}
+		return &authInitReq{username: a.username, methods: []*authMethod{{method: mnGSS, clientChallenge: b}}}, nil
+	case 1:
+		return a.initRep, nil
+	case 2:
+		if a.initRep.method != mnGSS {
+			return nil, fmt.Errorf("invalid authentication method %s - expected %s", a.initRep.method, mnGSS)
+		}
+		prms := a.initRep.prms.(*authGSSPrms)
+		var token []byte
+		if len(prms.token) != 0 {
+			var err error
+			if token, err = a.provider.InitSecContext(a.spn, prms.token); err != nil {
+				return nil, fmt.Errorf("gss server token: %w", err)
+			}
+		}
+		return &authFinalReq{username: a.username, method: mnGSS, prms: &authGSSPrms{oid: gssKerberosOID, token: token}}, nil
+	case 3:
+		return &authFinalRep{}, nil
+	}
+	panic("should never happen")
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+// Token (JWT, SAML bearer assertion) authentication.
+
+const (
+	mnJWT  = "JWT"
+	mnSAML = "SAML"
+)
+
+// TokenProvider returns the authentication token (JWT or SAML bearer assertion) of a new connection.
+type TokenProvider func(ctx context.Context) (string, error)
+
+// tokenMethod returns the authentication method of token: SAML for xml assertions, JWT otherwise.
+func tokenMethod(token string) string {
+	if strings.HasPrefix(strings.TrimSpace(token), "<") {
+		return mnSAML
+	}
+	return mnJWT
+}
+
+// authNoPrms are the empty parameters of the token methods final request.
+type authNoPrms struct{}
+
+func (authNoPrms) String() string { return "" }
+func (authNoPrms) size() int      { return int16Size }
+
+func (authNoPrms) decode(dec *encoding.Decoder, ph *partHeader) error {
+	if numPrm := int(dec.Int16()); numPrm != 0 {
+		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 0)
+	}
+	return nil
+}
+
+func (authNoPrms) encode(enc *encoding.Encoder) error {
+	enc.Int16(0)
+	return nil
+}
+
+// tokenAuth is the authentication stepper of the JWT and SAML methods.
+type tokenAuth struct {
+	step     int
+	username string
+	method   string
+	token    string
+	initRep  *authInitRep
+}
+
+// newTokenAuth returns the authentication stepper of the token returned by provider.
+func newTokenAuth(ctx context.Context, username string, provider TokenProvider) (*tokenAuth, error) {
+	token, err := provider(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("token provider: %w", err)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("token provider: empty token")
+	}
+	return &tokenAuth{username: username, method: tokenMethod(token), token: token, initRep: &authInitRep{}}, nil
+}
+
+func (a *tokenAuth) next() (partReadWriter, error) {
+	defer func() { a.step++ }()
+
+	switch a.step {
+	case 0:
+		return &authInitReq{username: a.username, methods: []*authMethod{{method: a.method, clientChallenge: []byte(a.token)}}}, nil
+	case 1:
+		return a.initRep, nil
+	case 2:
+		if a.initRep.method != a.method {
+			return nil, fmt.Errorf("invalid authentication method %s - expected %s", a.initRep.method, a.method)
+		}
+		logonName := a.initRep.prms.(*authLogonNameRep).logonName
+		return &authFinalReq{username: logonName, method: a.method, prms: authNoPrms{}}, nil
+	case 3:
+		return &authFinalRep{}, nil
+	}
+	panic("should never happen")
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+// X.509 client certificate authentication.
+
+const mnX509 = "X509"
+
+const x509ServerNonceSize = 64
+
+// authX509InitRep is the init reply of the X509 method: the server nonce to be signed by the client.
+type authX509InitRep struct {
+	serverNonce []byte
+}
+
+func (r *authX509InitRep) String() string { return fmt.Sprintf("serverNonce %v", r.serverNonce) }
+
+func (r *authX509InitRep) size() int { return int16Size + authBytes.size(r.serverNonce) }
+
+func (r *authX509InitRep) decode(dec *encoding.Decoder, ph *partHeader) error {
+	numPrm := int(dec.Int16())
+	if numPrm != 1 {
+		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 1)
+	}
+	r.serverNonce = authBytes.decode(dec)
+	return nil
+}
+
+func (r *authX509InitRep) encode(enc *encoding.Encoder) error {
+	enc.Int16(1)
+	return authBytes.encode(enc, r.serverNonce)
+}
+
+// authX509FinalReq contains the client certificate, the certificate chain and the signature
+// of the certificates and the server nonce.
+type authX509FinalReq struct {
+	certs     [][]byte // DER encoded certificates (client certificate first)
+	signature []byte
+}
+
+func (r *authX509FinalReq) String() string {
+	return fmt.Sprintf("certificates %d signature size %d", len(r.certs), len(r.signature))
+}
+
+// chainSize returns the encoded size of the certificate chain (certificates without the client certificate).
+func (r *authX509FinalReq) chainSize() int {
+	if len(r.certs) < 2 {
+		return 0
+	}
+	size := int16Size
+	for _, cert := range r.certs[1:] {
+		size += authBytes.size(cert)
+	}
+	return size
+}
+
+func (r *authX509FinalReq) size() int {
+	size := int16Size // no of parameters
+	size += authBytes.size(r.certs[0])
+	chainSize := r.chainSize()
+	size += authBytes.lenSize(chainSize) + chainSize
+	size += authBytes.size(r.signature)
+	return size
+}
+
+func (r *authX509FinalReq) decode(dec *encoding.Decoder, ph *partHeader) error {
+	numPrm := int(dec.Int16())
+	if numPrm != 3 {
+		return fmt.Errorf("invalid number of parameters %d - expected %d", numPrm, 3)
+	}
+	r.certs = [][]byte{authBytes.decode(dec)}
+	if authBytes.decodeLen(dec) != 0 { // certificate chain
+		numCert := int(dec.Int16())
+		for i := 0; i < numCert; i++ {
+			r.certs = append(r.certs, authBytes.decode(dec))
+		}
+	}
+	r.signature = authBytes.decode(dec)
+	return nil
+}
+
+func (r *authX509FinalReq) encode(enc *encoding.Encoder) error {
+	enc.Int16(3)
+	if err := authBytes.encode(enc, r.certs[0]); err != nil {
+		return err
+	}
+	if err := authBytes.encodeLen(enc, r.chainSize()); err != nil {
+		return err
+	}
+	if len(r.certs) > 1 {
+		enc.Int16(int16(len(r.certs) - 1))
+		for _, cert := range r.certs[1:] {
+			if err := authBytes.encode(enc, cert); err != nil {
+				return err
+			}
+		}
+	}
+	return authBytes.encode(enc, r.signature)
+}
+
+// x509Message returns the message signed by the client: the certificates followed by the server nonce.
+func x509Message(certs [][]byte, serverNonce []byte) []byte {
+	buf := new(bytes.Buffer)
+	for _, cert := range certs {
+		buf.Write(cert)
+	}
+	buf.Write(serverNonce)
+	return buf.Bytes()
+}
+
+// x509Sign signs message with key (RSA PKCS #1 v1.5 and ECDSA with SHA-256, Ed25519).
+func x509Sign(key crypto.PrivateKey, message []byte) ([]byte, error) {
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key.Sign(rand.Reader, message, crypto.Hash(0))
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		digest := sha256.Sum256(message)
+		return key.(crypto.Signer).Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// x509Verify verifies the signature of message by the public key of cert (see x509Sign).
+func x509Verify(cert *x509.Certificate, message, signature []byte) error {
+	var algo x509.SignatureAlgorithm
+	switch cert.PublicKeyAlgorithm {
+	case x509.RSA:
+		algo = x509.SHA256WithRSA
+	case x509.ECDSA:
+		algo = x509.ECDSAWithSHA256
+	case x509.Ed25519:
+		algo = x509.PureEd25519
+	default:
+		return fmt.Errorf("unsupported public key algorithm %s", cert.PublicKeyAlgorithm)
+	}
+	return cert.CheckSignature(algo, message, signature)
+}
+
+// x509Auth is the authentication stepper of the X509 method.
+type x509Auth struct {
+	step    int
+	cert    *tls.Certificate
+	initRep *authInitRep
+}
+
+func newX509Auth(cert *tls.Certificate) *x509Auth {
+	return &x509Auth{cert: cert, initRep: &authInitRep{}}
+}
+
+func (a *x509Auth) next() (partReadWriter, error) {
+	defer func() { a.step++ }()
+
+	switch a.step {
+	case 0:
+		if len(a.cert.Certificate) == 0 {
+			return nil, errors.New("client certificate is missing")
+		}
+		return &authInitReq{methods: []*authMethod{{method: mnX509, clientChallenge: []byte{}}}}, nil
+	case 1:
+		return a.initRep, nil
+	case 2:
+		if a.initRep.method != mnX509 {
+			return nil, fmt.Errorf("invalid authentication method %s - expected %s", a.initRep.method, mnX509)
+		}
+		serverNonce := a.initRep.prms.(*authX509InitRep).serverNonce
+		if len(serverNonce) != x509ServerNonceSize {
+			return nil, fmt.Errorf("invalid server nonce size %d - expected %d", len(serverNonce), x509ServerNonceSize)
+		}
+		signature, err := x509Sign(a.cert.PrivateKey, x509Message(a.cert.Certificate, serverNonce))
+		if err != nil {
+			return nil, err
+		}
+		return &authFinalReq{method: mnX509, prms: &authX509FinalReq{certs: a.cert.Certificate, signature: signature}}, nil
+	case 3:
+		return &authFinalRep{}, nil
+	}
+	panic("should never happen")
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// pipelineDepth is the maximal number of requests written before the replies are read (see pipeline).
+// Limiting the number of pending replies avoids blocking the database server writing replies not read yet.
+const pipelineDepth = 32
+
+/*
+pipeline writes the requests of n statements back-to-back before reading the replies in request order, so that
+the statements are processed with a single round trip per pipelineDepth statements.
+write writes the request of statement i, read reads the reply of statement i. Database errors of a statement
+are returned in errs and do not abort the pipeline, any other error (e.g. of a broken connection) does.
+*/
+func (s *Session) pipeline(n int, write, read func(i int) error) (errs []error, err error) {
+	errs = make([]error, n)
+	for start := 0; start < n; start += pipelineDepth {
+		end := start + pipelineDepth
+		if end > n {
+			end = n
+		}
+		for i := start; i < end; i++ {
+			if err := write(i); err != nil {
+				return nil, err
+			}
+		}
+		for i := start; i < end; i++ {
+			if err := read(i); err != nil {
+				var hdbErrs *hdbErrors
+				if !errors.As(err, &hdbErrs) {
+					return nil, err
+				}
+				stmtErrs := &hdbErrors{correlationID: hdbErrs.correlationID}
+				stmtErrs.append(hdbErrs) // copy as the errors part is reused by the next reply
+				errs[i] = stmtErrs
+			}
+		}
+	}
+	return errs, nil
+}
+
+// PrepareBatch prepares the sql statements queries in one round trip (see pipeline).
+// The prepare results and the database errors are returned per statement.
+func (s *Session) PrepareBatch(queries []string) ([]*PrepareResult, []error, error) {
+	s.checkLock()
+
+	prs := make([]*PrepareResult, len(queries))
+	errs, err := s.pipeline(len(queries), func(i int) error {
+		return s.pw.write(s.sessionID, mtPrepare, false, s.cmdParts(command(queries[i]))...)
+	}, func(i int) (err error) {
+		prs[i], err = s.readPrepareReply()
+		return err
+	})
+	return prs, errs, err
+}
+
+// BatchExec is a statement execution of a batch (see ExecBatch): statements with prepare result PR are executed
+// with the arguments Args, statements without prepare result are executed directly.
+type BatchExec struct {
+	Query string
+	PR    *PrepareResult
+	Args  []driver.NamedValue
+}
+
+// ExecBatch executes the statements execs in one round trip (see pipeline).
+// The results and the database errors are returned per statement. In auto commit mode each statement is committed.
+func (s *Session) ExecBatch(execs []BatchExec) ([]driver.Result, []error, error) {
+	s.checkLock()
+
+	for i, e := range execs {
+		if e.PR == nil {
+			continue
+		}
+		for _, f := range e.PR.prmFields {
+			if f.tc.isLob() || f.Out() {
+				return nil, nil, fmt.Errorf("statement %d: lob and output parameters are not supported by batch executions", i)
+			}
+		}
+	}
+
+	commit := !s.inTx
+	results := make([]driver.Result, len(execs))
+	errs, err := s.pipeline(len(execs), func(i int) error {
+		e := execs[i]
+		if e.PR == nil {
+			return s.pw.write(s.sessionID, mtExecuteDirect, commit, s.execParts(command(e.Query))...)
+		}
+		return s.pw.write(s.sessionID, mtExecute, commit, s.execParts(statementID(e.PR.stmtID), newInputParameters(e.PR.prmFields, e.Args))...)
+	}, func(i int) (err error) {
+		results[i], err = s.readExecReply()
+		return err
+	})
+	return results, errs, err
+}
+
+// DropStatementIDs releases the hdb statement handles ids in one round trip (see pipeline).
+func (s *Session) DropStatementIDs(ids []uint64) error {
+	s.checkLock()
+	s.SetInQuery(false)
+
+	errs, err := s.pipeline(len(ids), func(i int) error {
+		return s.pw.write(s.sessionID, mtDropStatementID, false, statementID(ids[i]))
+	}, func(i int) error {
+		return s.pr.readSkip()
+	})
+	if err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bufio"
+	"io"
+	"net"
+)
+
+const (
+	defaultBufferSize     = 4096    // bufio default buffer size
+	maxAdaptiveBufferSize = 1 << 20 // maximum size of adaptive buffers
+	adaptWindow           = 16      // number of messages observed before an adaptive buffer gets shrunk
+)
+
+/*
+bufferAdapter calculates the size of an adaptive buffer based on the observed message sizes:
+  - the buffer grows immediately to the smallest power of 2 multiple of the initial buffer size
+    holding the largest message (up to maxAdaptiveBufferSize)
+  - the buffer shrinks if the messages of the last adaptWindow messages would fit
+    into a quarter of the current buffer size
+*/
+type bufferAdapter struct {
+	minSize int // initial buffer size
+	size    int // current buffer size
+	cnt     int // number of messages observed in window
+	maxMsg  int // maximum message size observed in window
+}
+
+func newBufferAdapter(size int) bufferAdapter { return bufferAdapter{minSize: size, size: size} }
+
+// next returns the buffer size after observing a message of size msgSize.
+func (a *bufferAdapter) next(msgSize int) int {
+	if msgSize > a.maxMsg {
+		a.maxMsg = msgSize
+	}
+	a.cnt++
+
+	size := a.minSize
+	for size < a.maxMsg && size < maxAdaptiveBufferSize {
+		size <<= 1
+	}
+
+	switch {
+	case size > a.size: // grow
+		a.size = size
+	case a.cnt >= adaptWindow && size <= a.size/4: // shrink
+		a.size = size
+	}
+
+	if a.cnt >= adaptWindow { // new window
+		a.cnt, a.maxMsg = 0, 0
+	}
+	return a.size
+}
+
+func bufferSize(size int) int {
+	if size <= 0 {
+		return defaultBufferSize
+	}
+	return size
+}
+
+// bufferedReader is a bufio.Reader, which buffer size can optionally be adapted to the message sizes read.
+type bufferedReader struct {
+	*bufio.Reader
+	rd       io.Reader
+	adaptive bool
+	adapter  bufferAdapter
+}
+
+func newBufferedReader(rd io.Reader, size int, adaptive bool) *bufferedReader {
+	size = bufferSize(size)
+	return &bufferedReader{Reader: bufio.NewReaderSize(rd, size), rd: rd, adaptive: adaptive, adapter: newBufferAdapter(size)}
+}
+
+// messageRead needs to be called after a message of size msgSize was read completely.
+func (r *bufferedReader) messageRead(msgSize int) {
+	if !r.adaptive {
+		return
+	}
+	if size := r.adapter.next(msgSize); size != r.Size() && r.Buffered() == 0 {
+		r.Reader = bufio.NewReaderSize(r.rd, size)
+	}
+}
+
+/*
+minVectorSize is the minimal size of byte slices written by reference (see bufferedWriter).
+It needs to be greater than the size of scratch buffers used for encoding (see encoding.Encoder).
+*/
+const minVectorSize = 8192
+
+// buffersWriter is implemented by connections supporting vectored writes.
+type buffersWriter interface {
+	writeBuffers(v *net.Buffers) (int64, error)
+}
+
+/*
+bufferedWriter is a buffered writer using vectored writes (net.Buffers).
+
+Small writes are copied into the buffer, whereas byte slices with a size of at least minVectorSize
+(e.g. large binary or LOB data) are not copied but referenced until Flush is called.
+Therefore such slices must not be modified before the data is flushed.
+On Flush the buffered and referenced byte slices are written with a single vectored write (writev)
+if supported by the connection.
+
+Optionally, the buffer size is adapted to the message sizes written.
+*/
+type bufferedWriter struct {
+	wr    io.Writer
+	buf   []byte      // write buffer
+	start int         // start of the buffer section not yet added to vec
+	vec   net.Buffers // pending buffer sections and referenced byte slices
+	n     int         // number of pending bytes
+	err   error
+
+	adaptive bool
+	adapter  bufferAdapter
+}
+
+func newBufferedWriter(wr io.Writer, size int, adaptive bool) *bufferedWriter {
+	size = bufferSize(size)
+	return &bufferedWriter{wr: wr, buf: make([]byte, 0, size), adaptive: adaptive, adapter: newBufferAdapter(size)}
+}
+
+// Size returns the size of the underlying buffer in bytes.
+func (w *bufferedWriter) Size() int { return cap(w.buf) }
+
+// Buffered returns the number of bytes written but not flushed yet.
+func (w *bufferedWriter) Buffered() int { return w.n }
+
+// available returns the number of bytes unused in the buffer.
+func (w *bufferedWriter) available() int { return cap(w.buf) - len(w.buf) }
+
+// seal adds the current buffer section to vec.
+func (w *bufferedWriter) seal() {
+	if len(w.buf) > w.start {
+		w.vec = append(w.vec, w.buf[w.start:])
+		w.start = len(w.buf)
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if len(p) >= minVectorSize {
+		w.seal()
+		w.vec = append(w.vec, p)
+		w.n += len(p)
+		return len(p), nil
+	}
+	nn := 0
+	for len(p) > 0 {
+		if w.available() == 0 {
+			if err := w.Flush(); err != nil {
+				return nn, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		w.n += n
+		nn += n
+		p = p[n:]
+	}
+	return nn, nil
+}
+
+// WriteString implements the io.StringWriter interface.
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	nn := 0
+	for len(s) > 0 {
+		if w.available() == 0 {
+			if err := w.Flush(); err != nil {
+				return nn, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):cap(w.buf)], s)
+		w.buf = w.buf[:len(w.buf)+n]
+		w.n += n
+		nn += n
+		s = s[n:]
+	}
+	return nn, nil
+}
+
+// Flush writes the pending data to the underlying writer.
+func (w *bufferedWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.seal()
+	if len(w.vec) != 0 {
+		v := w.vec // WriteTo consumes v
+		if bw, ok := w.wr.(buffersWriter); ok {
+			_, w.err = bw.writeBuffers(&v)
+		} else {
+			_, w.err = v.WriteTo(w.wr)
+		}
+		for i := range w.vec { // release referenced byte slices
+			w.vec[i] = nil
+		}
+		w.vec = w.vec[:0]
+	}
+	w.buf = w.buf[:0]
+	w.start = 0
+	w.n = 0
+	return w.err
+}
+
+// messageWritten needs to be called after a message of size msgSize was written and flushed.
+func (w *bufferedWriter) messageWritten(msgSize int) {
+	if !w.adaptive {
+		return
+	}
+	if size := w.adapter.next(msgSize); size != w.Size() && w.Buffered() == 0 {
+		w.buf = make([]byte, 0, size)
+	}
+}
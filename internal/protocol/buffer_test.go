@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func testBufferAdapter(t *testing.T) {
+	a := newBufferAdapter(defaultBufferSize)
+
+	steps := []struct {
+		msgSize int
+		size    int
+	}{
+		{100, defaultBufferSize},
+		{defaultBufferSize + 1, 2 * defaultBufferSize},
+		{5 * defaultBufferSize, 8 * defaultBufferSize},
+		{10 * maxAdaptiveBufferSize, maxAdaptiveBufferSize},
+	}
+	for i, step := range steps {
+		if size := a.next(step.msgSize); size != step.size {
+			t.Fatalf("step %d: size %d - expected %d", i, size, step.size)
+		}
+	}
+
+	// shrink after window of small messages
+	size := 0
+	for i := 0; i < 2*adaptWindow; i++ {
+		size = a.next(100)
+	}
+	if size != defaultBufferSize {
+		t.Fatalf("size %d - expected %d", size, defaultBufferSize)
+	}
+}
+
+func testBufferedReader(t *testing.T) {
+	rd := newBufferedReader(bytes.NewReader(make([]byte, 100)), 0, true)
+	if rd.Size() != defaultBufferSize {
+		t.Fatalf("size %d - expected %d", rd.Size(), defaultBufferSize)
+	}
+	rd.messageRead(3 * defaultBufferSize)
+	if rd.Size() != 4*defaultBufferSize {
+		t.Fatalf("size %d - expected %d", rd.Size(), 4*defaultBufferSize)
+	}
+
+	rd = newBufferedReader(bytes.NewReader(make([]byte, 100)), 0, false)
+	rd.messageRead(3 * defaultBufferSize)
+	if rd.Size() != defaultBufferSize {
+		t.Fatalf("size %d - expected %d", rd.Size(), defaultBufferSize)
+	}
+}
+
+func testBufferedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	wr := newBufferedWriter(&buf, 1024, true)
+	wr.Write([]byte{1, 2, 3})
+	wr.messageWritten(3 * 1024) // buffer not flushed - no resize
+	if wr.Size() != 1024 {
+		t.Fatalf("size %d - expected %d", wr.Size(), 1024)
+	}
+	wr.Flush()
+	wr.messageWritten(3 * 1024)
+	if wr.Size() != 4*1024 {
+		t.Fatalf("size %d - expected %d", wr.Size(), 4*1024)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 2, 3}) {
+		t.Fatalf("written %v - expected %v", buf.Bytes(), []byte{1, 2, 3})
+	}
+}
+
+type testVectorWriter struct {
+	bytes.Buffer
+	vecs int
+}
+
+func (w *testVectorWriter) writeBuffers(v *net.Buffers) (int64, error) {
+	w.vecs++
+	return v.WriteTo(&w.Buffer)
+}
+
+func testBufferedWriterVector(t *testing.T) {
+	var exp []byte
+	large := bytes.Repeat([]byte{0xff}, minVectorSize)
+
+	var buf testVectorWriter
+	wr := newBufferedWriter(&buf, 1024, false)
+	for i := 0; i < 3; i++ {
+		small := bytes.Repeat([]byte{byte(i)}, 100)
+		wr.Write(small)
+		wr.Write(large)
+		exp = append(exp, small...)
+		exp = append(exp, large...)
+	}
+	wr.WriteString("abc")
+	exp = append(exp, "abc"...)
+
+	if wr.Buffered() != len(exp) {
+		t.Fatalf("buffered %d - expected %d", wr.Buffered(), len(exp))
+	}
+	if err := wr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.vecs != 1 {
+		t.Fatalf("vectored writes %d - expected %d", buf.vecs, 1)
+	}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Fatal("written bytes differ from expected bytes")
+	}
+	if wr.Buffered() != 0 {
+		t.Fatalf("buffered %d - expected %d", wr.Buffered(), 0)
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"adapter", testBufferAdapter},
+		{"reader", testBufferedReader},
+		{"writer", testBufferedWriter},
+		{"writerVector", testBufferedWriterVector},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+// Wire capture record direction.
+const (
+	CaptureUpStream   byte = '>' // client to server
+	CaptureDownStream byte = '<' // server to client
+)
+
+// CaptureHeaderSize is the size of a wire capture record header.
+const CaptureHeaderSize = 1 + 4 + 8 + 4
+
+// captureMaskByte is the byte value replacing masked (authentication) bytes.
+const captureMaskByte = 0
+
+/*
+CaptureWriter serializes wire capture records of multiple connections to a writer.
+
+Capture record format (big endian):
+- direction:         1 byte (CaptureUpStream or CaptureDownStream)
+- connection number: 4 bytes (client side session connection number)
+- timestamp:         8 bytes (unix time in nanoseconds)
+- data length:       4 bytes
+- data:              data length bytes (raw protocol bytes)
+*/
+type CaptureWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewCaptureWriter returns a new CaptureWriter writing to w.
+func NewCaptureWriter(w io.Writer) *CaptureWriter { return &CaptureWriter{w: w} }
+
+func (w *CaptureWriter) record(direction byte, connNo uint32, b []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	size := CaptureHeaderSize + len(b)
+	if cap(w.buf) < size {
+		w.buf = make([]byte, size)
+	}
+	buf := w.buf[:size]
+	buf[0] = direction
+	binary.BigEndian.PutUint32(buf[1:], connNo)
+	binary.BigEndian.PutUint64(buf[5:], uint64(time.Now().UnixNano()))
+	binary.BigEndian.PutUint32(buf[13:], uint32(len(b)))
+	copy(buf[CaptureHeaderSize:], b)
+	w.w.Write(buf) // capture errors are ignored
+}
+
+type captureMask struct {
+	start, end int64
+}
+
+// captureConn records the raw bytes of a session connection.
+type captureConn struct {
+	sessionConn
+	w       *CaptureWriter
+	connNo  uint32
+	upPos   int64         // number of upstream bytes written
+	masks   []captureMask // upstream byte ranges to be masked
+	stopped bool
+}
+
+func newCaptureConn(conn sessionConn, w *CaptureWriter, connNo uint64) *captureConn {
+	return &captureConn{sessionConn: conn, w: w, connNo: uint32(connNo)}
+}
+
+// mask masks the upstream bytes [start, start+size) in the capture.
+func (c *captureConn) mask(start int64, size int) {
+	c.masks = append(c.masks, captureMask{start: start, end: start + int64(size)})
+}
+
+// stop stops capturing.
+func (c *captureConn) stop() { c.stopped = true }
+
+func (c *captureConn) Read(b []byte) (int, error) {
+	n, err := c.sessionConn.Read(b)
+	if n > 0 && !c.stopped {
+		c.w.record(CaptureDownStream, c.connNo, b[:n])
+	}
+	return n, err
+}
+
+func (c *captureConn) Write(b []byte) (int, error) {
+	if !c.stopped {
+		c.w.record(CaptureUpStream, c.connNo, c.masked(b))
+	}
+	n, err := c.sessionConn.Write(b)
+	c.upPos += int64(n)
+	return n, err
+}
+
+// masked returns b with the masked byte ranges replaced.
+func (c *captureConn) masked(b []byte) []byte {
+	start, end := c.upPos, c.upPos+int64(len(b))
+	var r []byte
+	j := 0
+	for _, m := range c.masks {
+		if m.end > end { // keep masks not completely covered by b
+			c.masks[j] = m
+			j++
+		}
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		if r == nil {
+			r = make([]byte, len(b))
+			copy(r, b)
+		}
+		from, to := m.start-start, m.end-start
+		if from < 0 {
+			from = 0
+		}
+		if to > int64(len(b)) {
+			to = int64(len(b))
+		}
+		for i := from; i < to; i++ {
+			r[i] = captureMaskByte
+		}
+	}
+	c.masks = c.masks[:j]
+	if r == nil {
+		return b
+	}
+	return r
+}
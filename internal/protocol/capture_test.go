@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+type testCaptureSessionConn struct {
+	bytes.Buffer
+}
+
+func (c *testCaptureSessionConn) Close() error { return nil }
+func (c *testCaptureSessionConn) isBad() bool  { return false }
+func (c *testCaptureSessionConn) cancel()      {}
+
+func testCaptureMask(t *testing.T) {
+	var buf bytes.Buffer
+	c := newCaptureConn(new(testCaptureSessionConn), NewCaptureWriter(&buf), 1)
+
+	c.mask(2, 4) // mask bytes spanning two writes
+	c.Write([]byte{1, 2, 3, 4})
+	c.Write([]byte{5, 6, 7, 8})
+	c.stop()
+	c.Write([]byte{9})
+
+	var data []byte
+	b := buf.Bytes()
+	for len(b) > 0 {
+		if b[0] != CaptureUpStream {
+			t.Fatalf("direction %c - expected %c", b[0], CaptureUpStream)
+		}
+		if connNo := binary.BigEndian.Uint32(b[1:]); connNo != c.connNo {
+			t.Fatalf("connection number %d - expected %d", connNo, c.connNo)
+		}
+		size := int(binary.BigEndian.Uint32(b[13:]))
+		data = append(data, b[CaptureHeaderSize:CaptureHeaderSize+size]...)
+		b = b[CaptureHeaderSize+size:]
+	}
+	if expected := []byte{1, 2, 0, 0, 0, 0, 7, 8}; !bytes.Equal(data, expected) {
+		t.Fatalf("data %v - expected %v", data, expected)
+	}
+	if len(c.masks) != 0 {
+		t.Fatalf("number of masks %d - expected %d", len(c.masks), 0)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"mask", testCaptureMask},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
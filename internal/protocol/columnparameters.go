@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+	"github.com/SAP/go-hdb/internal/unicode/cesu8"
+)
+
+// A ColumnConverter converts the argument value v of parameter idx of a column-wise bulk execution
+// (see Session.ExecColumns).
+type ColumnConverter func(idx int, v interface{}) (interface{}, error)
+
+// columnEncoder encodes the argument values of a column of a column-wise bulk execution.
+type columnEncoder interface {
+	len() int
+	size(row int) int // encoded size including the type code
+	encode(e *encoding.Encoder, row int) error
+}
+
+/*
+newColumnEncoder returns the encoder of the argument column of parameter field f.
+
+Slices of the go types matching the parameter type ([]int64, []int32, []int for integer types, []float64 for
+floating point types, []string and [][]byte for character and binary types, []bool for boolean types) are
+encoded directly without conversion of the single values. All other slices are converted value by value
+by convert in advance.
+*/
+func newColumnEncoder(idx int, f *parameterField, column interface{}, convert ColumnConverter) (columnEncoder, error) {
+	tc := f.tc
+	if tc.isLob() {
+		return nil, fmt.Errorf("parameter %d: lob parameters are not supported by column-wise bulk executions", idx)
+	}
+
+	switch col := column.(type) {
+	case []int64:
+		if tc.isIntegerType() {
+			return newIntColumn(idx, tc, len(col), func(row int) int64 { return col[row] })
+		}
+	case []int32:
+		if tc.isIntegerType() {
+			return newIntColumn(idx, tc, len(col), func(row int) int64 { return int64(col[row]) })
+		}
+	case []int:
+		if tc.isIntegerType() {
+			return newIntColumn(idx, tc, len(col), func(row int) int64 { return int64(col[row]) })
+		}
+	case []float64:
+		if tc == tcReal || tc == tcDouble {
+			return newFloatColumn(idx, tc, col)
+		}
+	case []string:
+		if isCESU8, ok := isCharColumn(tc); ok {
+			return &stringColumn{tc: tc, cesu8: isCESU8, values: col}, nil
+		}
+	case [][]byte:
+		if isCESU8, ok := isCharColumn(tc); ok {
+			return &bytesColumn{tc: tc, cesu8: isCESU8, values: col}, nil
+		}
+	case []bool:
+		if tc == tcBoolean {
+			return boolColumn(col), nil
+		}
+	}
+
+	rv := reflect.ValueOf(column)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("parameter %d: invalid column type %T - slice expected", idx, column)
+	}
+	values := make([]interface{}, rv.Len())
+	for row := range values {
+		v, err := convert(idx, rv.Index(row).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d row %d: %w", idx, row, err)
+		}
+		values[row] = v
+	}
+	return &valueColumn{tc: tc, values: values}, nil
+}
+
+// isCharColumn returns true if string and byte slice values of type tc are encoded directly (character or binary types)
+// and if they are encoded in CESU-8.
+func isCharColumn(tc typeCode) (bool, bool) {
+	switch tc.fieldType().(type) {
+	case _varType, _alphaType:
+		return false, true
+	case _cesu8Type:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+type intColumn struct {
+	tc        typeCode
+	n         int
+	value     func(row int) int64
+	min, max  int64
+	fieldSize int
+}
+
+// newIntColumn returns an integer column checking that all values are in the range of the integer type tc.
+func newIntColumn(idx int, tc typeCode, n int, value func(row int) int64) (*intColumn, error) {
+	c := &intColumn{tc: tc, n: n, value: value}
+	switch tc {
+	case tcTinyint:
+		c.min, c.max, c.fieldSize = minTinyint, maxTinyint, tinyintFieldSize
+	case tcSmallint:
+		c.min, c.max, c.fieldSize = minSmallint, maxSmallint, smallintFieldSize
+	case tcInteger:
+		c.min, c.max, c.fieldSize = minInteger, maxInteger, integerFieldSize
+	default:
+		c.min, c.max, c.fieldSize = minBigint, maxBigint, bigintFieldSize
+	}
+	for row := 0; row < n; row++ {
+		if i := value(row); i < c.min || i > c.max {
+			return nil, fmt.Errorf("parameter %d row %d: %w", idx, row, newConvertError(tc.fieldType(), i, ErrIntegerOutOfRange))
+		}
+	}
+	return c, nil
+}
+
+func (c *intColumn) len() int         { return c.n }
+func (c *intColumn) size(row int) int { return 1 + c.fieldSize }
+func (c *intColumn) encode(e *encoding.Encoder, row int) error {
+	i := c.value(row)
+	e.Byte(byte(c.tc.encTc()))
+	switch c.tc {
+	case tcTinyint:
+		e.Byte(byte(i))
+	case tcSmallint:
+		e.Int16(int16(i))
+	case tcInteger:
+		e.Int32(int32(i))
+	default:
+		e.Int64(i)
+	}
+	return nil
+}
+
+type floatColumn struct {
+	tc     typeCode
+	values []float64
+}
+
+// newFloatColumn returns a floating point column checking that all values are in the range of the type tc.
+func newFloatColumn(idx int, tc typeCode, values []float64) (*floatColumn, error) {
+	if tc == tcReal {
+		for row, f := range values {
+			if math.Abs(f) > maxReal {
+				return nil, fmt.Errorf("parameter %d row %d: %w", idx, row, newConvertError(tc.fieldType(), f, ErrFloatOutOfRange))
+			}
+		}
+	}
+	return &floatColumn{tc: tc, values: values}, nil
+}
+
+func (c *floatColumn) len() int { return len(c.values) }
+func (c *floatColumn) size(row int) int {
+	if c.tc == tcReal {
+		return 1 + realFieldSize
+	}
+	return 1 + doubleFieldSize
+}
+func (c *floatColumn) encode(e *encoding.Encoder, row int) error {
+	f := c.values[row]
+	e.Byte(byte(c.tc.encTc()))
+	if c.tc == tcDouble {
+		e.Float64(f)
+	} else {
+		e.Float32(float32(f))
+	}
+	return nil
+}
+
+type stringColumn struct {
+	tc     typeCode
+	cesu8  bool
+	values []string
+}
+
+func (c *stringColumn) len() int { return len(c.values) }
+func (c *stringColumn) size(row int) int {
+	if c.cesu8 {
+		return 1 + varBytesSize(cesu8Type, cesu8.StringSize(c.values[row]))
+	}
+	return 1 + varBytesSize(varType, len(c.values[row]))
+}
+func (c *stringColumn) encode(e *encoding.Encoder, row int) error {
+	e.Byte(byte(c.tc.encTc()))
+	if c.cesu8 {
+		return encodeCESU8String(e, c.values[row])
+	}
+	return encodeVarString(e, c.values[row])
+}
+
+// bytesColumn encodes nil values as null values.
+type bytesColumn struct {
+	tc     typeCode
+	cesu8  bool
+	values [][]byte
+}
+
+func (c *bytesColumn) len() int { return len(c.values) }
+func (c *bytesColumn) size(row int) int {
+	switch {
+	case c.values[row] == nil:
+		return 1
+	case c.cesu8:
+		return 1 + varBytesSize(cesu8Type, cesu8.Size(c.values[row]))
+	default:
+		return 1 + varBytesSize(varType, len(c.values[row]))
+	}
+}
+func (c *bytesColumn) encode(e *encoding.Encoder, row int) error {
+	b := c.values[row]
+	if b == nil {
+		e.Byte(byte(c.tc.encTc()) | 0x80) // type code null value: set high bit
+		return nil
+	}
+	e.Byte(byte(c.tc.encTc()))
+	if c.cesu8 {
+		return encodeCESU8Bytes(e, b)
+	}
+	return encodeVarBytes(e, b)
+}
+
+type boolColumn []bool
+
+func (c boolColumn) len() int         { return len(c) }
+func (c boolColumn) size(row int) int { return 1 + booleanFieldSize }
+func (c boolColumn) encode(e *encoding.Encoder, row int) error {
+	e.Byte(byte(tcBoolean.encTc()))
+	if c[row] {
+		e.Byte(booleanTrueValue)
+	} else {
+		e.Byte(booleanFalseValue)
+	}
+	return nil
+}
+
+// valueColumn encodes converted argument values.
+type valueColumn struct {
+	tc     typeCode
+	values []interface{}
+}
+
+func (c *valueColumn) len() int { return len(c.values) }
+func (c *valueColumn) size(row int) int {
+	return 1 + prmSize(c.tc, driver.NamedValue{Value: c.values[row]})
+}
+func (c *valueColumn) encode(e *encoding.Encoder, row int) error {
+	return encodePrm(e, c.tc, driver.NamedValue{Value: c.values[row]})
+}
+
+// columnParameters are the input parameters of the rows [start, end) of a column-wise bulk execution.
+type columnParameters struct {
+	columns    []columnEncoder
+	start, end int
+}
+
+func (p *columnParameters) String() string {
+	return fmt.Sprintf("columns %d rows [%d, %d)", len(p.columns), p.start, p.end)
+}
+
+func (p *columnParameters) kind() partKind { return pkParameters }
+func (p *columnParameters) numArg() int    { return p.end - p.start }
+
+func (p *columnParameters) rowSize(row int) int {
+	size := 0
+	for _, c := range p.columns {
+		size += c.size(row)
+	}
+	return size
+}
+
+func (p *columnParameters) size() int {
+	size := 0
+	for row := p.start; row < p.end; row++ {
+		size += p.rowSize(row)
+	}
+	return size
+}
+
+func (p *columnParameters) encode(enc *encoding.Encoder) error {
+	for row := p.start; row < p.end; row++ {
+		for _, c := range p.columns {
+			if err := c.encode(enc, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
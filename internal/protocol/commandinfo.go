@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+// commandInfo contains the application source location (source module and line number) of a statement,
+// which is shown by the database server e.g. in expensive statement traces.
+type commandInfo plainOptions
+
+func newCommandInfo(sourceModule string, lineNumber int) commandInfo {
+	return commandInfo{
+		int8(cmiLineNumber):   optIntType(lineNumber),
+		int8(cmiSourceModule): optStringType(sourceModule),
+	}
+}
+
+func (ci commandInfo) String() string {
+	m := make(map[commandInfoType]interface{})
+	for k, v := range ci {
+		m[commandInfoType(k)] = v
+	}
+	return fmt.Sprintf("command info %s", m)
+}
+
+func (ci commandInfo) size() int   { return plainOptions(ci).size() }
+func (ci commandInfo) numArg() int { return len(ci) }
+
+func (ci commandInfo) sourceModule() string {
+	s, _ := ci[int8(cmiSourceModule)].(optStringType)
+	return string(s)
+}
+
+func (ci commandInfo) lineNumber() int {
+	i, _ := ci[int8(cmiLineNumber)].(optIntType)
+	return int(i)
+}
+
+func (ci *commandInfo) decode(dec *encoding.Decoder, ph *partHeader) error {
+	*ci = commandInfo{} // no reuse of maps - create new one
+	plainOptions(*ci).decode(dec, ph.numArg())
+	return dec.Error()
+}
+
+func (ci commandInfo) encode(enc *encoding.Encoder) error {
+	plainOptions(ci).encode(enc)
+	return nil
+}
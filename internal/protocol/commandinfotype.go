@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+//go:generate stringer -type=commandInfoType
+
+type commandInfoType int8
+
+const (
+	cmiLineNumber   commandInfoType = 1 // int4
+	cmiSourceModule commandInfoType = 2 // string
+)
@@ -6,6 +6,7 @@ package protocol
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
@@ -24,6 +25,12 @@ const (
 	dpvClientHandlesStatementSequence = 1
 )
 
+// defaultCompressionLevel is the compression level requested by the client if compression is enabled.
+const defaultCompressionLevel optIntType = 1
+
+// activeActiveProtocolVersion is the Active/Active (read enabled) protocol version supported by the client.
+const activeActiveProtocolVersion optIntType = 1
+
 type connectOptions plainOptions
 
 func (o connectOptions) String() string {
@@ -48,6 +55,45 @@ func (o connectOptions) fullVersionString() (version string) {
 	return
 }
 
+// compressionLevel returns the compression level accepted by the server (0: no compression).
+func (o connectOptions) compressionLevel() int {
+	v, ok := o[int8(coCompressionLevelAndFlags)]
+	if !ok {
+		return 0
+	}
+	if i, ok := v.(optIntType); ok {
+		return int(i) & 0xff // lower byte: level, upper bytes: flags
+	}
+	return 0
+}
+
+// values returns the option values by option name (e.g. DatabaseName).
+func (o connectOptions) values() map[string]interface{} {
+	m := make(map[string]interface{}, len(o))
+	for k, v := range o {
+		name := strings.TrimPrefix(connectOption(k).String(), "co")
+		switch v := v.(type) {
+		case optBooleanType:
+			m[name] = bool(v)
+		case optTinyintType:
+			m[name] = int64(v)
+		case optIntType:
+			m[name] = int64(v)
+		case optBigintType:
+			m[name] = int64(v)
+		case optDoubleType:
+			m[name] = float64(v)
+		case optStringType:
+			m[name] = string(v)
+		case optBinaryStringType:
+			m[name] = []byte(v)
+		default:
+			m[name] = v
+		}
+	}
+	return m
+}
+
 func (o *connectOptions) decode(dec *encoding.Decoder, ph *partHeader) error {
 	*o = connectOptions{} // no reuse of maps - create new one
 	plainOptions(*o).decode(dec, ph.numArg())
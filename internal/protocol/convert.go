@@ -31,6 +31,25 @@ const (
 	secondtimeNullValue int32 = 86402
 )
 
+/*
+Database date and time values are stored without time zone: the wall clock of the values is transferred
+as UTC time, so that values bound and scanned in a time location other than UTC are converted
+by utcWallClock and inLocation.
+*/
+
+// utcWallClock returns the UTC time with the wall clock of t.
+func utcWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// inLocation returns the time with the wall clock of the UTC time t in location loc.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == time.UTC {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
 // Longdate
 func convertLongdateToTime(longdate int64) time.Time {
 	const dayfactor = 10000000 * 24 * 60 * 60
@@ -40,7 +59,7 @@ func convertLongdateToTime(longdate int64) time.Time {
 	return t.Add(time.Duration(d))
 }
 
-// nanosecond: HDB - 7 digits precision (not 9 digits)
+// nanosecond: HDB - 7 digits precision (not 9 digits), i.e. nanoseconds are truncated to multiples of 100
 func convertTimeToLongdate(t time.Time) int64 {
 	return (((((((convertTimeToDayDate(t)-1)*24)+int64(t.Hour()))*60)+int64(t.Minute()))*60)+int64(t.Second()))*10000000 + int64(t.Nanosecond()/100) + 1
 }
@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"errors"
 	"math"
+	"math/big"
+	"strings"
 	"testing"
 	"time"
 )
@@ -182,6 +184,114 @@ func testConvertBytes(t *testing.T) {
 
 }
 
+func decimalRat(b []byte) *big.Rat {
+	m, neg, exp := decodeDecimal128(b)
+	r := new(big.Rat).SetInt(m)
+	if neg {
+		r.Neg(r)
+	}
+	if exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(int64(-exp)), nil)))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(int64(exp)), nil)))
+}
+
+func testConvertFixed(t *testing.T) {
+	tests := []struct {
+		value string // decimal value
+		tc    typeCode
+		scale int
+		fixed int64  // expected fixed value
+		round string // expected value read from the database
+		err   error
+	}{
+		{"123.45", tcFixed8, 2, 12345, "123.45", nil},
+		{"123.45", tcFixed12, 4, 1234500, "123.45", nil},
+		{"-123.45", tcFixed16, 2, -12345, "-123.45", nil},
+		{"0", tcFixed8, 3, 0, "0", nil},
+		{"1.25", tcFixed8, 1, 13, "1.3", nil},
+		{"-1.5", tcFixed8, 0, -2, "-2", nil},
+		{"1.44", tcFixed8, 1, 14, "1.4", nil},
+		{"9223372036854775807", tcFixed8, 0, math.MaxInt64, "9223372036854775807", nil},
+		{"-9223372036854775808", tcFixed8, 0, math.MinInt64, "-9223372036854775808", nil},
+		{"9223372036854775808", tcFixed8, 0, 0, "", ErrDecimalOutOfRange},
+		{"922337203685477580.8", tcFixed8, 1, 0, "", ErrDecimalOutOfRange},
+	}
+
+	for _, test := range tests {
+		neg := strings.HasPrefix(test.value, "-")
+		digits, exp := strings.TrimPrefix(test.value, "-"), 0
+		if i := strings.IndexByte(digits, '.'); i != -1 {
+			digits, exp = digits[:i]+digits[i+1:], i+1-len(digits)
+		}
+		m, ok := new(big.Int).SetString(digits, 10)
+		if !ok {
+			t.Fatalf("invalid test value %s", test.value)
+		}
+
+		ft := test.tc.scaledFieldType(test.scale)
+		cv, err := ft.Convert(encodeDecimal128(m, neg, exp))
+		switch {
+		case test.err != nil:
+			if !errors.Is(err, test.err) {
+				t.Fatalf("value %s %s scale %d: error %v - expected %v", test.value, test.tc, test.scale, err, test.err)
+			}
+			continue
+		case err != nil:
+			t.Fatal(err)
+		}
+		fixed := decodeFixed(cv.([]byte))
+		if len(cv.([]byte)) != ft.prmSize(cv) || !fixed.IsInt64() || fixed.Int64() != test.fixed {
+			t.Fatalf("value %s %s scale %d: fixed value %v - expected %d", test.value, test.tc, test.scale, cv, test.fixed)
+		}
+		round, _ := new(big.Rat).SetString(test.round)
+		if v := decimalRat(fixedToDecimal(cv.([]byte), test.scale)); v.Cmp(round) != 0 {
+			t.Fatalf("value %s %s scale %d: decimal value %s - expected %s", test.value, test.tc, test.scale, v.RatString(), test.round)
+		}
+	}
+
+	// fixed16 values exceeding the decimal128 precision
+	m := new(big.Int).Sub(new(big.Int).Exp(bigTen, big.NewInt(38), nil), bigOne) // 38 digits
+	b, err := encodeFixed(m, fixed16FieldSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, round := decimalRat(fixedToDecimal(b, 2)), new(big.Rat).SetFrac(new(big.Int).Exp(bigTen, big.NewInt(36), nil), bigOne); v.Cmp(round) != 0 {
+		t.Fatalf("decimal value %s - expected %s", v.RatString(), round.RatString())
+	}
+}
+
+func testConvertSpatial(t *testing.T) {
+	wkb := []byte{0x01, 0x01, 0x00, 0x00, 0x00} // truncated, content is not checked by the converter
+
+	for _, tc := range []typeCode{tcStGeometry, tcStPoint} {
+		cv, err := tc.fieldType().Convert(wkb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, ok := cv.(*bytes.Reader)
+		if !ok {
+			t.Fatalf("%s: converted value type %T - expected %T", tc, cv, r)
+		}
+		b := make([]byte, len(wkb)+1)
+		if n, _ := r.Read(b); !bytes.Equal(b[:n], wkb) {
+			t.Fatalf("%s: converted value %v - expected %v", tc, b[:n], wkb)
+		}
+		if !tc.isLob() || tc.dataType() != DtLob {
+			t.Fatalf("%s: spatial type is expected to be a lob", tc)
+		}
+	}
+
+	for typeName, tc := range map[string]typeCode{"ST_GEOMETRY": tcStGeometry, "ST_POINT": tcStPoint} {
+		if tc.typeName() != typeName {
+			t.Fatalf("type name %s - expected %s", tc.typeName(), typeName)
+		}
+		if _, dt, ok := TypeConverter(typeName); !ok || dt != DtLob {
+			t.Fatalf("type converter %s: data type %s ok %t - expected %s", typeName, dt, ok, DtLob)
+		}
+	}
+}
+
 func TestConverter(t *testing.T) {
 	tests := []struct {
 		name string
@@ -192,6 +302,8 @@ func TestConverter(t *testing.T) {
 		{"convertTime", testConvertTime},
 		{"convertString", testConvertString},
 		{"convertBytes", testConvertBytes},
+		{"convertFixed", testConvertFixed},
+		{"convertSpatial", testConvertSpatial},
 	}
 
 	for _, test := range tests {
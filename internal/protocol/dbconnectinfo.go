@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+type dbConnectInfo plainOptions
+
+func (ci dbConnectInfo) String() string {
+	m := make(map[dbConnectInfoType]interface{})
+	for k, v := range ci {
+		m[dbConnectInfoType(k)] = v
+	}
+	return fmt.Sprintf("db connect info %s", m)
+}
+
+func (ci dbConnectInfo) size() int   { return plainOptions(ci).size() }
+func (ci dbConnectInfo) numArg() int { return len(ci) }
+
+func (ci dbConnectInfo) databaseName() string {
+	s, _ := ci[int8(ciDatabaseName)].(optStringType)
+	return string(s)
+}
+
+func (ci dbConnectInfo) host() string {
+	s, _ := ci[int8(ciHost)].(optStringType)
+	return string(s)
+}
+
+func (ci dbConnectInfo) port() int {
+	i, _ := ci[int8(ciPort)].(optIntType)
+	return int(i)
+}
+
+func (ci dbConnectInfo) isConnected() bool {
+	b, _ := ci[int8(ciIsConnected)].(optBooleanType)
+	return bool(b)
+}
+
+func (ci *dbConnectInfo) decode(dec *encoding.Decoder, ph *partHeader) error {
+	*ci = dbConnectInfo{} // no reuse of maps - create new one
+	plainOptions(*ci).decode(dec, ph.numArg())
+	return dec.Error()
+}
+
+func (ci dbConnectInfo) encode(enc *encoding.Encoder) error {
+	plainOptions(ci).encode(enc)
+	return nil
+}
+
+/*
+DBConnectInfo contains the connect information of a database as returned by the database server:
+- IsConnected is true, if the session is connected to the database
+- otherwise Host and Port are the address of the database to connect to
+*/
+type DBConnectInfo struct {
+	DatabaseName string
+	Host         string
+	Port         int
+	IsConnected  bool
+}
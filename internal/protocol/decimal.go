@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrDecimalOutOfRange means that a decimal exceeds the precision of the hdb fixed decimal field.
+var ErrDecimalOutOfRange = errors.New("decimal out of range error")
+
+/*
+decimal128 values are transferred in the little endian IEEE 754 decimal128 binary integer decimal format
+used by the driver Decimal type:
+- bits 0-112:   mantissa
+- bits 113-126: biased exponent
+- bit 127:      sign
+Starting with data format version 8 (fixed types), the database transfers decimals with precision and scale
+as little endian two's complement integers scaled by 10^scale of 8, 12 or 16 bytes size.
+*/
+const (
+	dec128Bias         = 6176
+	dec128MantissaBits = 113
+)
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// decodeDecimal128 returns the absolute mantissa, the sign and the exponent of the decimal128 value b.
+func decodeDecimal128(b []byte) (*big.Int, bool, int) {
+	neg := (b[15] & 0x80) != 0
+	exp := int((((uint16(b[15])<<8)|uint16(b[14]))<<1)>>2) - dec128Bias
+
+	be := make([]byte, 15) // little endian -> big endian mantissa
+	for i := 0; i < 15; i++ {
+		be[14-i] = b[i]
+	}
+	be[0] &= 0x01 // keep the mantissa bit (rest: exponent)
+	return new(big.Int).SetBytes(be), neg, exp
+}
+
+// encodeDecimal128 returns the decimal128 value of the absolute mantissa m, the sign neg and the exponent exp.
+// m needs to fit into the decimal128 mantissa.
+func encodeDecimal128(m *big.Int, neg bool, exp int) []byte {
+	b := make([]byte, decimalFieldSize)
+	be := m.Bytes()
+	for i, j := 0, len(be)-1; j >= 0; i, j = i+1, j-1 {
+		b[i] = be[j]
+	}
+	exp += dec128Bias
+	b[14] |= byte(exp) << 1
+	b[15] = byte(uint16(exp) >> 7)
+	if neg {
+		b[15] |= 0x80
+	}
+	return b
+}
+
+// quoRound sets m to m / 10^n rounded half away from zero (m >= 0).
+func quoRound(m *big.Int, n int) {
+	d := new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+	var r big.Int
+	m.QuoRem(m, d, &r)
+	if r.Lsh(&r, 1).Cmp(d) >= 0 {
+		m.Add(m, bigOne)
+	}
+}
+
+// decimalToFixed converts the decimal128 value b into a fixed value of size bytes with scale scale.
+// Fractional digits exceeding the scale are rounded half away from zero.
+func decimalToFixed(b []byte, size, scale int) ([]byte, error) {
+	m, neg, exp := decodeDecimal128(b)
+	if m.Sign() != 0 {
+		if n := exp + scale; n >= 0 {
+			m.Mul(m, new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil))
+		} else {
+			quoRound(m, -n)
+		}
+	}
+	if neg {
+		m.Neg(m)
+	}
+	return encodeFixed(m, size)
+}
+
+// fixedToDecimal converts the fixed value b with scale scale into a decimal128 value.
+// Values exceeding the decimal128 precision of 34 digits are rounded half away from zero.
+func fixedToDecimal(b []byte, scale int) []byte {
+	m := decodeFixed(b)
+	neg := m.Sign() < 0
+	m.Abs(m)
+	exp := -scale
+	for m.BitLen() > dec128MantissaBits {
+		quoRound(m, 1)
+		exp++
+	}
+	return encodeDecimal128(m, neg, exp)
+}
+
+// decodeFixed returns the value of the little endian two's complement integer b.
+func decodeFixed(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i, j := 0, len(b)-1; j >= 0; i, j = i+1, j-1 {
+		be[i] = b[j]
+	}
+	m := new(big.Int).SetBytes(be)
+	if len(b) != 0 && b[len(b)-1]&0x80 != 0 { // negative: subtract 2^(8*size)
+		m.Sub(m, new(big.Int).Lsh(bigOne, uint(8*len(b))))
+	}
+	return m
+}
+
+// encodeFixed returns the little endian two's complement integer of size bytes of m.
+func encodeFixed(m *big.Int, size int) ([]byte, error) {
+	bits := uint(8 * size)
+	max := new(big.Int).Lsh(bigOne, bits-1) // range: -2^(bits-1) <= m < 2^(bits-1)
+	if m.Cmp(max) >= 0 || m.Cmp(new(big.Int).Neg(max)) < 0 {
+		return nil, ErrDecimalOutOfRange
+	}
+	u := new(big.Int).Set(m)
+	if u.Sign() < 0 { // two's complement: add 2^bits
+		u.Add(u, new(big.Int).Lsh(bigOne, bits))
+	}
+	b := make([]byte, size)
+	be := u.Bytes()
+	for i, j := 0, len(be)-1; j >= 0; i, j = i+1, j-1 {
+		b[i] = be[j]
+	}
+	return b, nil
+}
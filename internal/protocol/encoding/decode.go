@@ -8,6 +8,8 @@ import (
 	"encoding/binary"
 	"io"
 	"math"
+	"time"
+	"unsafe"
 
 	"github.com/SAP/go-hdb/internal/unicode"
 	"golang.org/x/text/transform"
@@ -23,6 +25,11 @@ type Decoder struct {
 	tr  transform.Transformer
 	cnt int
 	dfv int
+
+	zeroCopy bool   // zero-copy string decoding
+	arena    []byte // zero-copy string buffer
+
+	loc *time.Location // time location of date and time values (nil: UTC)
 }
 
 // NewDecoder creates a new Decoder instance based on an io.Reader.
@@ -33,6 +40,16 @@ func NewDecoder(rd io.Reader) *Decoder {
 	}
 }
 
+// Reader returns the underlying reader.
+func (d *Decoder) Reader() io.Reader {
+	return d.rd
+}
+
+// SetReader sets the underlying reader (e.g. to decode a decompressed message).
+func (d *Decoder) SetReader(rd io.Reader) {
+	d.rd = rd
+}
+
 // Dfv returns the data format version.
 func (d *Decoder) Dfv() int {
 	return d.dfv
@@ -43,6 +60,41 @@ func (d *Decoder) SetDfv(dfv int) {
 	d.dfv = dfv
 }
 
+// Location returns the time location of date and time values (default: UTC).
+func (d *Decoder) Location() *time.Location {
+	if d.loc == nil {
+		return time.UTC
+	}
+	return d.loc
+}
+
+// SetLocation sets the time location of date and time values.
+func (d *Decoder) SetLocation(loc *time.Location) {
+	d.loc = loc
+}
+
+// ZeroCopy returns if zero-copy string decoding is active.
+func (d *Decoder) ZeroCopy() bool {
+	return d.zeroCopy
+}
+
+// SetZeroCopy sets zero-copy string decoding active or inactive (see CESU8String).
+func (d *Decoder) SetZeroCopy(zeroCopy bool) {
+	d.zeroCopy = zeroCopy
+}
+
+// ResetArena resets the zero-copy string buffer. The buffer content gets overwritten by
+// the following string decodings, so that all strings decoded so far become invalid.
+func (d *Decoder) ResetArena() {
+	d.arena = d.arena[:0]
+}
+
+// DetachArena releases the zero-copy string buffer. The following string decodings use a new buffer,
+// so that all strings decoded so far stay valid.
+func (d *Decoder) DetachArena() {
+	d.arena = nil
+}
+
 // ResetCnt resets the byte read counter.
 func (d *Decoder) ResetCnt() {
 	d.cnt = 0
@@ -267,6 +319,55 @@ func (d *Decoder) CESU8Bytes(size int) []byte {
 	return p[:n]
 }
 
+const minArenaSize = 4096
+
+// arenaBytes returns the next size bytes of the zero-copy string buffer.
+func (d *Decoder) arenaBytes(size int) []byte {
+	l := len(d.arena)
+	if cap(d.arena)-l < size { // new buffer - strings referencing the current buffer stay valid
+		c := 2 * cap(d.arena)
+		if c < size {
+			c = size
+		}
+		if c < minArenaSize {
+			c = minArenaSize
+		}
+		d.arena = make([]byte, 0, c)
+		l = 0
+	}
+	return d.arena[l : l+size]
+}
+
+/*
+CESU8String reads a size CESU-8 encoded byte sequence and returns an UTF-8 string.
+
+If zero-copy string decoding is active, the string is not allocated but references
+the zero-copy string buffer of the decoder and is therefore only valid until the next
+call of ResetArena.
+*/
+func (d *Decoder) CESU8String(size int) string {
+	if !d.zeroCopy {
+		return string(d.CESU8Bytes(size))
+	}
+	if d.err != nil {
+		return ""
+	}
+	p := d.arenaBytes(size)
+	var n int
+	n, d.err = io.ReadFull(d.rd, p)
+	d.cnt += n
+	if d.err != nil {
+		return ""
+	}
+	d.tr.Reset()
+	if n, _, d.err = d.tr.Transform(p, p, true); d.err != nil { // inplace transformation
+		return ""
+	}
+	d.arena = d.arena[:len(d.arena)+n]
+	p = p[:n]
+	return *(*string)(unsafe.Pointer(&p)) // zero-copy conversion
+}
+
 // // ShortCESU8Bytes reads a CESU-8 encoded byte sequence and returns an UTF-8 byte slice.
 // // Size is encoded in one byte.
 // func (d *Decoder) ShortCESU8Bytes() ([]byte, int) {
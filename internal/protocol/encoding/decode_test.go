@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testDecoderCESU8String(t *testing.T, zeroCopy bool) {
+	data := []byte("helloworld")
+
+	rd := bytes.NewReader(data)
+	dec := NewDecoder(rd)
+	dec.SetZeroCopy(zeroCopy)
+
+	s1 := dec.CESU8String(5)
+	s2 := dec.CESU8String(5)
+	if s1 != "hello" || s2 != "world" {
+		t.Fatalf("strings %s %s - expected %s %s", s1, s2, "hello", "world")
+	}
+
+	dec.ResetArena()
+	rd.Reset(data)
+	dec.CESU8String(5)
+	dec.CESU8String(5)
+	if s1 != "hello" || s2 != "world" { // same content
+		t.Fatalf("strings %s %s - expected %s %s", s1, s2, "hello", "world")
+	}
+
+	dec.ResetArena()
+	rd.Reset([]byte("HELLO"))
+	dec.CESU8String(5)
+
+	expected := "hello"
+	if zeroCopy { // arena got overwritten
+		expected = "HELLO"
+	}
+	if s1 != expected {
+		t.Fatalf("string %s - expected %s", s1, expected)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"cesu8String", func(t *testing.T) { testDecoderCESU8String(t, false) }},
+		{"cesu8StringZeroCopy", func(t *testing.T) { testDecoderCESU8String(t, true) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
+
+func benchmarkDecoderCESU8String(b *testing.B, zeroCopy bool) {
+	const numString = 1000
+	data := bytes.Repeat([]byte("abcdefghijklmnopqrstuvwxyz"), numString)
+
+	rd := bytes.NewReader(data)
+	dec := NewDecoder(rd)
+	dec.SetZeroCopy(zeroCopy)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rd.Reset(data)
+		dec.ResetArena()
+		for j := 0; j < numString; j++ {
+			dec.CESU8String(26)
+		}
+	}
+}
+
+func BenchmarkDecoderCESU8String(b *testing.B) {
+	b.Run("alloc", func(b *testing.B) { benchmarkDecoderCESU8String(b, false) })
+	b.Run("zeroCopy", func(b *testing.B) { benchmarkDecoderCESU8String(b, true) })
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/binary"
 	"io"
 	"math"
+	"time"
 
 	"github.com/SAP/go-hdb/internal/unicode"
 	"golang.org/x/text/transform"
@@ -21,6 +22,8 @@ type Encoder struct {
 	err error
 	b   []byte // scratch buffer (min 8 Bytes)
 	tr  transform.Transformer
+
+	loc *time.Location // time location of date and time values (nil: UTC)
 }
 
 // NewEncoder creates a new Encoder instance.
@@ -32,6 +35,19 @@ func NewEncoder(wr io.Writer) *Encoder {
 	}
 }
 
+// Location returns the time location of date and time values (default: UTC).
+func (e *Encoder) Location() *time.Location {
+	if e.loc == nil {
+		return time.UTC
+	}
+	return e.loc
+}
+
+// SetLocation sets the time location of date and time values.
+func (e *Encoder) SetLocation(loc *time.Location) {
+	e.loc = loc
+}
+
 // Zeroes writes cnt zero byte values.
 func (e *Encoder) Zeroes(cnt int) {
 	if e.err != nil {
@@ -176,7 +192,7 @@ func (e *Encoder) String(s string) {
 	if e.err != nil {
 		return
 	}
-	e.Bytes([]byte(s))
+	io.WriteString(e.wr, s) // avoid string to byte slice conversion (bufio.Writer implements io.StringWriter)
 }
 
 // CESU8Bytes writes an UTF-8 byte slice as CESU-8 and returns the CESU-8 bytes written.
@@ -206,5 +222,9 @@ func (e *Encoder) CESU8Bytes(p []byte) int {
 
 // CESU8String is like WriteCesu8 with an UTF-8 string as parameter.
 func (e *Encoder) CESU8String(s string) int {
-	return e.CESU8Bytes([]byte(s))
+	b := getBuffer(len(s))
+	copy(*b, s)
+	n := e.CESU8Bytes(*b)
+	putBuffer(b)
+	return n
 }
@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"bufio"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func BenchmarkEncoderString(b *testing.B) {
+	s := strings.Repeat("ä€𝄞x", 64)
+	enc := NewEncoder(bufio.NewWriter(ioutil.Discard))
+
+	b.Run("String", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			enc.String(s)
+		}
+	})
+	b.Run("CESU8String", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			enc.CESU8String(s)
+		}
+	})
+}
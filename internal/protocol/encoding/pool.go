@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"sync"
+)
+
+// maxPoolBufferSize is the maximum capacity of buffers kept in the pool
+// to avoid holding large buffers (e.g. of huge string parameters).
+const maxPoolBufferSize = 1 << 16
+
+var bufferPool = sync.Pool{New: func() interface{} { return new([]byte) }}
+
+// getBuffer returns a buffer with a byte slice of length size from the pool.
+func getBuffer(size int) *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	if cap(*b) < size {
+		*b = make([]byte, size)
+	}
+	*b = (*b)[:size]
+	return b
+}
+
+// putBuffer returns a buffer to the pool.
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPoolBufferSize {
+		return
+	}
+	bufferPool.Put(b)
+}
@@ -5,6 +5,7 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
@@ -20,6 +21,30 @@ const (
 
 type sqlState [sqlStateSize]byte
 
+// HANA error codes of aborted statement executions.
+const (
+	errCodeLockTimeout  = 131 // transaction rolled back by lock wait timeout
+	errCodeCanceled     = 139 // current operation cancelled by request and transaction rolled back
+	errCodeQueryTimeout = 613 // execution aborted by timeout
+)
+
+// ErrCodeInvalidStatementID is the HANA error code of executions of prepared statements which are not known
+// to the database server (anymore).
+const ErrCodeInvalidStatementID = 5
+
+// errCodePasswordChangeRequired is the HANA error code of connects of users forced to change the password.
+const errCodePasswordChangeRequired = 414
+
+// ErrPasswordChangeRequired is matched by connect errors of users forced to change the password (see errors.Is).
+var ErrPasswordChangeRequired = errors.New("password change required")
+
+// Errors matched by database errors of aborted statement executions (see errors.Is).
+var (
+	ErrQueryTimeout = errors.New("query timeout")
+	ErrLockTimeout  = errors.New("lock wait timeout")
+	ErrCanceled     = errors.New("statement canceled")
+)
+
 type hdbError struct {
 	errorCode       int32
 	errorPosition   int32
@@ -55,6 +80,8 @@ type hdbErrors struct {
 	errors []*hdbError
 	//numArg int
 	idx int
+
+	correlationID string
 }
 
 // String implements the Stringer interface.
@@ -103,6 +130,11 @@ func (e *hdbErrors) Position() int {
 	return int(e.errors[e.idx].errorPosition)
 }
 
+// SQLState implements the driver.SQLStateError interface.
+func (e *hdbErrors) SQLState() string {
+	return string(e.errors[e.idx].sqlState[:])
+}
+
 // Level implements the driver.Error interface.
 func (e *hdbErrors) Level() int {
 	return int(e.errors[e.idx].errorLevel)
@@ -128,12 +160,75 @@ func (e *hdbErrors) IsFatal() bool {
 	return e.errors[e.idx].errorLevel == errorLevelFatalError
 }
 
+// Is reports whether the database error is an aborted statement execution error
+// (ErrQueryTimeout, ErrLockTimeout or ErrCanceled) or a required password change
+// (ErrPasswordChangeRequired) matching target.
+func (e *hdbErrors) Is(target error) bool {
+	if e.NumError() == 0 {
+		return false
+	}
+	switch e.Code() {
+	case errCodeQueryTimeout:
+		return target == ErrQueryTimeout
+	case errCodeLockTimeout:
+		return target == ErrLockTimeout
+	case errCodeCanceled:
+		return target == ErrCanceled
+	case errCodePasswordChangeRequired:
+		return target == ErrPasswordChangeRequired
+	}
+	return false
+}
+
+// CorrelationID returns the correlation id of the statement execution causing the error.
+func (e *hdbErrors) CorrelationID() string {
+	return e.correlationID
+}
+
 func (e *hdbErrors) setStmtNo(idx, no int) {
 	if idx >= 0 && idx < e.NumError() {
 		e.errors[idx].stmtNo = no
 	}
 }
 
+// offsetStmtNo adds offset to the statement numbers of the errors (e.g. for bulk executions split into multiple requests).
+func (e *hdbErrors) offsetStmtNo(offset int) {
+	for _, err := range e.errors {
+		if err.stmtNo != -1 {
+			err.stmtNo += offset
+		}
+	}
+}
+
+// append appends copies of the errors of errs.
+func (e *hdbErrors) append(errs *hdbErrors) {
+	for _, err := range errs.errors {
+		cp := *err
+		e.errors = append(e.errors, &cp)
+	}
+}
+
+/*
+StmtErrors returns the database errors of err linked to a statement number (e.g. the rows of a bulk execution rejected
+by the database server) as single database errors. Warnings are not returned. StmtErrors returns nil if err is not a
+database error or does not contain errors linked to a statement number.
+*/
+func StmtErrors(err error) []error {
+	var e *hdbErrors
+	if !errors.As(err, &e) {
+		return nil
+	}
+	var errs []error
+	for _, hdbErr := range e.errors {
+		if hdbErr.stmtNo == -1 || hdbErr.errorLevel == errorLevelWarning {
+			continue
+		}
+		cp := *hdbErr
+		errs = append(errs, &hdbErrors{errors: []*hdbError{&cp}, correlationID: e.correlationID})
+	}
+	return errs
+}
+
 func (e *hdbErrors) isWarnings() bool {
 	for _, _error := range e.errors {
 		if _error.errorLevel != errorLevelWarning {
@@ -19,3 +19,13 @@ func (s *fetchsize) decode(dec *encoding.Decoder, ph *partHeader) error {
 	return dec.Error()
 }
 func (s fetchsize) encode(enc *encoding.Encoder) error { enc.Int32(int32(s)); return nil }
+
+// fetch position of the fetch absolute and fetch relative requests of scrollable result sets
+type fetchPosition int32
+
+func (p fetchPosition) String() string { return fmt.Sprintf("fetch position %d", p) }
+func (p *fetchPosition) decode(dec *encoding.Decoder, ph *partHeader) error {
+	*p = fetchPosition(dec.Int32())
+	return dec.Error()
+}
+func (p fetchPosition) encode(enc *encoding.Encoder) error { enc.Int32(int32(p)); return nil }
@@ -7,6 +7,7 @@ package protocol
 import (
 	"database/sql/driver"
 	"sort"
+	"sync"
 
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
@@ -83,7 +84,26 @@ var (
 	_ Field = (*parameterField)(nil)
 )
 
-// TODO cache
+// fieldValuesPool caches field value slices of fetched result set chunks.
+var fieldValuesPool sync.Pool
+
+// newFieldValues returns a field value slice of length size (from the pool if available).
 func newFieldValues(size int) []driver.Value {
+	if fv, ok := fieldValuesPool.Get().([]driver.Value); ok && cap(fv) >= size {
+		return fv[:size]
+	}
 	return make([]driver.Value, size)
 }
+
+// freeFieldValues returns a field value slice to the pool.
+// fv must not be used after calling freeFieldValues.
+func freeFieldValues(fv []driver.Value) {
+	if cap(fv) == 0 {
+		return
+	}
+	fv = fv[:cap(fv)]
+	for i := range fv { // release field values
+		fv[i] = nil
+	}
+	fieldValuesPool.Put(fv[:0]) // slice header allocation per result set chunk only
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+func benchmarkResultsetDecode(b *testing.B, free bool) {
+	const (
+		numRow = 1000
+		numCol = 8
+	)
+
+	fields := make([]*resultField, numCol)
+	for i := range fields {
+		fields[i] = &resultField{tc: tcTinyint}
+	}
+
+	buf := new(bytes.Buffer)
+	enc := encoding.NewEncoder(buf)
+	for i := 0; i < numRow*numCol; i++ {
+		enc.Bool(true) // not null
+		enc.Byte(byte(i))
+	}
+	data := buf.Bytes()
+
+	ph := &partHeader{argumentCount: numRow}
+	rd := bytes.NewReader(data)
+	dec := encoding.NewDecoder(rd)
+	resSet := &resultset{resultFields: fields}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rd.Reset(data)
+		if err := resSet.decode(dec, ph); err != nil {
+			b.Fatal(err)
+		}
+		if free {
+			freeFieldValues(resSet.fieldValues)
+		}
+	}
+}
+
+func BenchmarkResultsetDecode(b *testing.B) {
+	b.Run("alloc", func(b *testing.B) { benchmarkResultsetDecode(b, false) })
+	b.Run("pool", func(b *testing.B) { benchmarkResultsetDecode(b, true) })
+}
+
+func TestFieldMetadata(t *testing.T) {
+	tests := []struct {
+		tc               typeCode
+		length, fraction int16
+
+		varLength bool
+		expLength int64
+
+		decimal          bool
+		precision, scale int64
+	}{
+		{tcInteger, 0, 0, false, 0, false, 0, 0},
+		{tcNvarchar, 20, 0, true, 20, false, 0, 0},
+		{tcNstring, 20, 0, true, 20, false, 0, 0},
+		{tcShorttext, 15, 0, true, 15, false, 0, 0},
+		{tcAlphanum, 15, 0, true, 15, false, 0, 0},
+		{tcVarbinary, 10, 0, true, 10, false, 0, 0},
+		{tcNclob, 0, 0, true, math.MaxInt64, false, 0, 0},
+		{tcBlob, 0, 0, true, math.MaxInt64, false, 0, 0},
+		{tcStGeometry, 0, 0, true, math.MaxInt64, false, 0, 0},
+		{tcDecimal, 34, 32767, false, 0, true, 34, 32767},
+		{tcFixed8, 10, 2, false, 0, true, 10, 2},
+		{tcFixed16, 38, 5, false, 0, true, 38, 5},
+	}
+
+	for _, test := range tests {
+		for _, f := range []Field{
+			&resultField{columnOptions: coMandatory, tc: test.tc, length: test.length, fraction: test.fraction},
+			&parameterField{parameterOptions: poMandatory, tc: test.tc, length: test.length, fraction: test.fraction},
+		} {
+			length, ok := f.TypeLength()
+			if ok != test.varLength || length != test.expLength {
+				t.Fatalf("%T %s: length %d %t - expected %d %t", f, test.tc, length, ok, test.expLength, test.varLength)
+			}
+			precision, scale, ok := f.TypePrecisionScale()
+			if ok != test.decimal || precision != test.precision || scale != test.scale {
+				t.Fatalf("%T %s: precision %d scale %d %t - expected %d %d %t", f, test.tc, precision, scale, ok, test.precision, test.scale, test.decimal)
+			}
+		}
+	}
+
+	nullableTests := []struct {
+		f        Field
+		nullable bool
+	}{
+		{&resultField{columnOptions: coMandatory}, false},
+		{&resultField{columnOptions: coOptional}, true},
+		{&resultField{columnOptions: coOptional | 0x10}, true}, // further column options (e.g. ALPHANUM)
+		{&parameterField{parameterOptions: poMandatory}, false},
+		{&parameterField{parameterOptions: poOptional}, true},
+		{&parameterField{parameterOptions: poOptional | poDefault}, true},
+	}
+	for _, test := range nullableTests {
+		if nullable := test.f.Nullable(); nullable != test.nullable {
+			t.Fatalf("%T %v: nullable %t - expected %t", test.f, test.f, nullable, test.nullable)
+		}
+	}
+}
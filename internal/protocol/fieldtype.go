@@ -5,6 +5,7 @@
 package protocol
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"errors"
 	"fmt"
@@ -64,6 +65,9 @@ const (
 	daydateFieldSize    = 4
 	secondtimeFieldSize = 4
 	decimalFieldSize    = 16
+	fixed8FieldSize     = 8
+	fixed12FieldSize    = 12
+	fixed16FieldSize    = 16
 
 	lobInputParametersSize = 9
 )
@@ -95,6 +99,11 @@ type resDecoder interface {
 	decodeRes(*encoding.Decoder) (interface{}, error)
 }
 
+// result decoder of field types depending on the field scale (fixed types)
+type scaledResDecoder interface {
+	decodeScaledRes(*encoding.Decoder, int) (interface{}, error)
+}
+
 // parameter size
 func prmSize(tc typeCode, arg driver.NamedValue) int {
 	v := arg.Value
@@ -143,13 +152,16 @@ func decodePrm(d *encoding.Decoder) (typeCode, interface{}, error) {
 
 /*
 decode result
+- scale: field scale (fraction) of decimal fields
 */
-func decodeRes(d *encoding.Decoder, tc typeCode) (interface{}, error) {
+func decodeRes(d *encoding.Decoder, tc typeCode, scale int) (interface{}, error) {
 	ft := tc.fieldType()
 
 	switch ft := ft.(type) {
 	default:
 		panic("field type missing decoder")
+	case scaledResDecoder:
+		return ft.decodeScaledRes(d, scale)
 	case resDecoder:
 		return ft.decodeRes(d)
 	case commonDecoder:
@@ -173,6 +185,9 @@ var (
 	daydateType    = _daydateType{}
 	secondtimeType = _secondtimeType{}
 	decimalType    = _decimalType{}
+	fixed8Type     = _fixedType{size: fixed8FieldSize}
+	fixed12Type    = _fixedType{size: fixed12FieldSize}
+	fixed16Type    = _fixedType{size: fixed16FieldSize}
 	varType        = _varType{}
 	alphaType      = _alphaType{}
 	cesu8Type      = _cesu8Type{}
@@ -195,6 +210,7 @@ type _seconddateType struct{}
 type _daydateType struct{}
 type _secondtimeType struct{}
 type _decimalType struct{}
+type _fixedType struct{ size, scale int }
 type _varType struct{}
 type _alphaType struct{}
 type _cesu8Type struct{}
@@ -217,6 +233,7 @@ var (
 	_ fieldType = (*_daydateType)(nil)
 	_ fieldType = (*_secondtimeType)(nil)
 	_ fieldType = (*_decimalType)(nil)
+	_ fieldType = (*_fixedType)(nil)
 	_ fieldType = (*_varType)(nil)
 	_ fieldType = (*_alphaType)(nil)
 	_ fieldType = (*_cesu8Type)(nil)
@@ -256,6 +273,7 @@ func (_seconddateType) String() string { return "seconddateType" }
 func (_daydateType) String() string    { return "daydateType" }
 func (_secondtimeType) String() string { return "secondtimeType" }
 func (_decimalType) String() string    { return "decimalType" }
+func (ft _fixedType) String() string   { return fmt.Sprintf("fixed%dType", ft.size) }
 func (_varType) String() string        { return "varType" }
 func (_alphaType) String() string      { return "alphaType" }
 func (_cesu8Type) String() string      { return "cesu8Type" }
@@ -475,6 +493,25 @@ func convertDecimal(ft fieldType, v interface{}) (driver.Value, error) {
 	return nil, newConvertError(ft, v, nil)
 }
 
+// withScale returns the field type of fields with scale (fraction) scale.
+func (ft _fixedType) withScale(scale int) _fixedType { ft.scale = scale; return ft }
+
+// Convert converts decimal values (see convertDecimal) into fixed values with the scale of the field type.
+func (ft _fixedType) Convert(v interface{}) (interface{}, error) {
+	dv, err := convertDecimal(ft, v)
+	if dv == nil || err != nil {
+		return dv, err
+	}
+	p := dv.([]byte)
+	if len(p) != decimalFieldSize {
+		return nil, newConvertError(ft, v, nil)
+	}
+	if p, err = decimalToFixed(p, ft.size, ft.scale); err != nil {
+		return nil, newConvertError(ft, v, err)
+	}
+	return p, nil
+}
+
 func (ft _varType) Convert(v interface{}) (interface{}, error)   { return convertBytes(ft, v) }
 func (ft _alphaType) Convert(v interface{}) (interface{}, error) { return convertBytes(ft, v) }
 func (ft _cesu8Type) Convert(v interface{}) (interface{}, error) { return convertBytes(ft, v) }
@@ -535,6 +572,8 @@ func convertLob(isCharBased bool, ft fieldType, v interface{}) (driver.Value, er
 	switch v := v.(type) {
 	case io.Reader:
 		return v, nil
+	case []byte: // e.g. spatial values in well-known binary format
+		return bytes.NewReader(v), nil
 	case ReadProvider:
 		return v.Reader(), nil
 	default:
@@ -557,6 +596,7 @@ func (_seconddateType) prmSize(interface{}) int { return seconddateFieldSize }
 func (_daydateType) prmSize(interface{}) int    { return daydateFieldSize }
 func (_secondtimeType) prmSize(interface{}) int { return secondtimeFieldSize }
 func (_decimalType) prmSize(interface{}) int    { return decimalFieldSize }
+func (ft _fixedType) prmSize(interface{}) int   { return ft.size }
 func (_lobVarType) prmSize(v interface{}) int   { return lobInputParametersSize }
 func (_lobCESU8Type) prmSize(v interface{}) int { return lobInputParametersSize }
 
@@ -682,7 +722,7 @@ func (ft _doubleType) encodePrm(e *encoding.Encoder, v interface{}) error {
 }
 
 func (ft _dateType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -690,7 +730,7 @@ func (ft _dateType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 func (ft _timeType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -698,7 +738,7 @@ func (ft _timeType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 func (ft _timestampType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -724,7 +764,7 @@ func encodeTime(e *encoding.Encoder, t time.Time) {
 }
 
 func (ft _longdateType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -732,7 +772,7 @@ func (ft _longdateType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 func (ft _seconddateType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -740,7 +780,7 @@ func (ft _seconddateType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 func (ft _daydateType) encodePrm(e *encoding.Encoder, v interface{}) error {
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -752,7 +792,7 @@ func (ft _secondtimeType) encodePrm(e *encoding.Encoder, v interface{}) error {
 		e.Int32(secondtimeNullValue)
 		return nil
 	}
-	t, err := asTime(ft, v)
+	t, err := asTime(ft, v, e.Location())
 	if err != nil {
 		return err
 	}
@@ -760,13 +800,17 @@ func (ft _secondtimeType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 
-func asTime(ft fieldType, v interface{}) (time.Time, error) {
+// asTime returns the wall clock of time value v in location loc as UTC time.
+func asTime(ft fieldType, v interface{}, loc *time.Location) (time.Time, error) {
 	t, ok := v.(time.Time)
 	if !ok {
 		return zeroTime, newConvertError(ft, v, nil)
 	}
 	//store in utc
-	return t.UTC(), nil
+	if loc == time.UTC {
+		return t.UTC(), nil
+	}
+	return utcWallClock(t.In(loc)), nil
 }
 
 func (ft _decimalType) encodePrm(e *encoding.Encoder, v interface{}) error {
@@ -781,6 +825,18 @@ func (ft _decimalType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	return nil
 }
 
+func (ft _fixedType) encodePrm(e *encoding.Encoder, v interface{}) error {
+	p, ok := v.([]byte)
+	if !ok {
+		return newConvertError(ft, v, nil)
+	}
+	if len(p) != ft.size {
+		return fmt.Errorf("invalid argument length %d - expected %d", len(p), ft.size)
+	}
+	e.Bytes(p)
+	return nil
+}
+
 func (ft _varType) encodePrm(e *encoding.Encoder, v interface{}) error {
 	switch v := v.(type) {
 	case []byte:
@@ -950,7 +1006,7 @@ func (_dateType) decode(d *encoding.Decoder) (interface{}, error) {
 	if null {
 		return nil, nil
 	}
-	return time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC), nil
+	return time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, d.Location()), nil
 }
 func (_timeType) decode(d *encoding.Decoder) (interface{}, error) {
 	// time read gives only seconds (cut), no milliseconds
@@ -958,7 +1014,7 @@ func (_timeType) decode(d *encoding.Decoder) (interface{}, error) {
 	if null {
 		return nil, nil
 	}
-	return time.Date(1, 1, 1, hour, min, sec, nsec, time.UTC), nil
+	return time.Date(1, 1, 1, hour, min, sec, nsec, d.Location()), nil
 }
 func (_timestampType) decode(d *encoding.Decoder) (interface{}, error) {
 	year, month, day, dateNull := decodeDate(d)
@@ -966,7 +1022,7 @@ func (_timestampType) decode(d *encoding.Decoder) (interface{}, error) {
 	if dateNull || timeNull {
 		return nil, nil
 	}
-	return time.Date(year, month, day, hour, min, sec, nsec, time.UTC), nil
+	return time.Date(year, month, day, hour, min, sec, nsec, d.Location()), nil
 }
 
 // null values: most sig bit unset
@@ -1003,28 +1059,28 @@ func (_longdateType) decode(d *encoding.Decoder) (interface{}, error) {
 	if longdate == longdateNullValue {
 		return nil, nil
 	}
-	return convertLongdateToTime(longdate), nil
+	return inLocation(convertLongdateToTime(longdate), d.Location()), nil
 }
 func (_seconddateType) decode(d *encoding.Decoder) (interface{}, error) {
 	seconddate := d.Int64()
 	if seconddate == seconddateNullValue {
 		return nil, nil
 	}
-	return convertSeconddateToTime(seconddate), nil
+	return inLocation(convertSeconddateToTime(seconddate), d.Location()), nil
 }
 func (_daydateType) decode(d *encoding.Decoder) (interface{}, error) {
 	daydate := d.Int32()
 	if daydate == daydateNullValue {
 		return nil, nil
 	}
-	return convertDaydateToTime(int64(daydate)), nil
+	return inLocation(convertDaydateToTime(int64(daydate)), d.Location()), nil
 }
 func (_secondtimeType) decode(d *encoding.Decoder) (interface{}, error) {
 	secondtime := d.Int32()
 	if secondtime == secondtimeNullValue {
 		return nil, nil
 	}
-	return convertSecondtimeToTime(int(secondtime)), nil
+	return inLocation(convertSecondtimeToTime(int(secondtime)), d.Location()), nil
 }
 
 func (_decimalType) decode(d *encoding.Decoder) (interface{}, error) {
@@ -1036,6 +1092,22 @@ func (_decimalType) decode(d *encoding.Decoder) (interface{}, error) {
 	return b, nil
 }
 
+// sniffer: scale is unknown, return fixed value
+func (ft _fixedType) decodePrm(d *encoding.Decoder) (interface{}, error) {
+	b := make([]byte, ft.size)
+	d.Bytes(b)
+	return b, nil
+}
+
+func (ft _fixedType) decodeScaledRes(d *encoding.Decoder, scale int) (interface{}, error) {
+	if !d.Bool() { //null value
+		return nil, nil
+	}
+	b := make([]byte, ft.size)
+	d.Bytes(b)
+	return fixedToDecimal(b, scale), nil
+}
+
 func (_varType) decode(d *encoding.Decoder) (interface{}, error) {
 	size, null := decodeVarBytesSize(d)
 	if null {
@@ -1073,6 +1145,9 @@ func (_cesu8Type) decode(d *encoding.Decoder) (interface{}, error) {
 	if null {
 		return nil, nil
 	}
+	if d.ZeroCopy() {
+		return d.CESU8String(size), nil
+	}
 	return d.CESU8Bytes(size), nil
 }
 
@@ -104,6 +104,15 @@ func (r *initReply) String() string {
 	return fmt.Sprintf("productVersion %s protocolVersion %s", r.product, r.protocol)
 }
 
+func (r *initReply) encode(enc *encoding.Encoder) error {
+	enc.Int8(r.product.major)
+	enc.Int16(r.product.minor)
+	enc.Int8(r.protocol.major)
+	enc.Int16(r.protocol.minor)
+	enc.Zeroes(2) //commitInitReplySize
+	return nil
+}
+
 func (r *initReply) decode(dec *encoding.Decoder) error {
 	r.product.major = dec.Int8()
 	r.product.minor = dec.Int16()
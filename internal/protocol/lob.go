@@ -73,11 +73,31 @@ const (
 // WriterSetter is the interface wrapping the SetWriter method (Lob handling).
 type WriterSetter interface{ SetWriter(w io.Writer) error }
 
+/*
+lobInterrupter is implemented by lob parameter readers which can be closed while a read is pending (e.g. *io.PipeReader),
+so that the writer feeding the reader gets unblocked in case the statement execution is aborted.
+*/
+type lobInterrupter interface{ CloseWithError(err error) error }
+
 // sessionSetter is the interface wrapping the setSession method (lob handling).
 type sessionSetter interface{ setSession(s *Session) }
 
+/*
+LobReaderAt is implemented by result lobs providing random access reads: ReadAt reads the lob content
+at a byte offset by read lob requests, so that ranges of a lob can be read without reading the lob sequentially.
+Random access is supported for binary lobs only, as character lob offsets are counted in characters.
+*/
+type LobReaderAt interface {
+	io.ReaderAt
+	// Size returns the size of the lob in bytes.
+	Size() int64
+	// IsCharBased returns true for character lobs (CLOB, NCLOB, TEXT).
+	IsCharBased() bool
+}
+
 var _ WriterSetter = (*lobOutDescr)(nil)
 var _ sessionSetter = (*lobOutDescr)(nil)
+var _ LobReaderAt = (*lobOutDescr)(nil)
 
 /*
 TODO description
@@ -127,6 +147,44 @@ func (d *lobOutDescr) setSession(s *Session) { d.s = s }
 // SetWriter implements the WriterSetter interface.
 func (d *lobOutDescr) SetWriter(wr io.Writer) error { return d.s.decodeLobs(d, wr) }
 
+// Size implements the LobReaderAt interface.
+func (d *lobOutDescr) Size() int64 { return d.numByte }
+
+// IsCharBased implements the LobReaderAt interface.
+func (d *lobOutDescr) IsCharBased() bool { return d.isCharBased }
+
+// ReadAt implements the LobReaderAt interface.
+func (d *lobOutDescr) ReadAt(b []byte, ofs int64) (int, error) {
+	if d.isCharBased {
+		return 0, fmt.Errorf("lob: random access is not supported for character lobs")
+	}
+	if ofs < 0 {
+		return 0, fmt.Errorf("lob: negative offset %d", ofs)
+	}
+	if ofs >= d.numByte {
+		return 0, io.EOF
+	}
+	var err error
+	if rest := d.numByte - ofs; int64(len(b)) > rest {
+		b, err = b[:rest], io.EOF
+	}
+	n := 0
+	if ofs < int64(len(d.b)) { // data included in result set
+		n = copy(b, d.b[ofs:])
+	}
+	if n < len(b) {
+		m, rerr := d.s.readLobAt(d.id, ofs+int64(n), b[n:])
+		n += m
+		if rerr != nil {
+			return n, rerr
+		}
+		if n < len(b) { // lob content ended before the lob size
+			return n, io.ErrUnexpectedEOF
+		}
+	}
+	return n, err
+}
+
 /*
 write lobs:
 - write lob field to database in chunks
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadLobChunk(t *testing.T) {
+	const chunkSize = 100
+
+	content := make([]byte, 250)
+	for i := range content {
+		content[i] = byte(i)
+	}
+
+	readers := map[string]io.Reader{
+		"reader":    bytes.NewReader(content),
+		"oneByte":   iotest.OneByteReader(bytes.NewReader(content)),
+		"half":      iotest.HalfReader(bytes.NewReader(content)),
+		"dataError": iotest.DataErrReader(bytes.NewReader(content)),
+	}
+
+	for name, rd := range readers {
+		t.Run(name, func(t *testing.T) {
+			var sizes []int
+			b := make([]byte, chunkSize)
+			buf := new(bytes.Buffer)
+			for {
+				n, eof, err := readLobChunk(rd, b)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sizes = append(sizes, n)
+				buf.Write(b[:n])
+				if eof {
+					break
+				}
+			}
+			if !bytes.Equal(buf.Bytes(), content) {
+				t.Fatal("lob content mismatch")
+			}
+			if len(sizes) != 3 || sizes[0] != chunkSize || sizes[1] != chunkSize || sizes[2] != 50 {
+				t.Fatalf("chunk sizes %v - expected %v", sizes, []int{chunkSize, chunkSize, 50})
+			}
+		})
+	}
+
+	if _, _, err := readLobChunk(iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader(content))), make([]byte, chunkSize)); !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("error %v - expected %v", err, iotest.ErrTimeout)
+	}
+}
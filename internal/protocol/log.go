@@ -9,6 +9,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/dlog"
 )
 
 const (
@@ -17,7 +21,9 @@ const (
 
 var (
 	debug bool
-	trace bool
+
+	traceMu sync.RWMutex // protects trace
+	trace   bool
 )
 
 func init() {
@@ -25,36 +31,59 @@ func init() {
 	flag.BoolVar(&trace, fmt.Sprintf("%s.trace", pPrefix), false, "enabling hdb protocol trace")
 }
 
-type pLogger struct {
-	log *log.Logger
-}
+// Trace returns if the protocol trace is active.
+func Trace() bool { traceMu.RLock(); defer traceMu.RUnlock(); return trace }
 
-func newPLogger() *pLogger {
-	return &pLogger{
-		log: log.New(os.Stderr, fmt.Sprintf("%s ", pPrefix), log.Ldate|log.Ltime|log.Lshortfile),
-	}
-}
+// SetTrace sets the protocol trace active or inactive. The setting takes effect for open sessions as well.
+func SetTrace(on bool) { traceMu.Lock(); trace = on; traceMu.Unlock() }
 
-func (l *pLogger) Printf(format string, v ...interface{}) {
-	l.log.Output(2, fmt.Sprintf(format, v...))
+// pLogger writes protocol log entries to the default driver logger
+// if no session specific logger is available.
+type pLogger struct{}
+
+func (l pLogger) Printf(format string, v ...interface{}) {
+	dlog.Default().Log(dlog.LevelWarn, fmt.Sprintf(format, v...))
 }
 
-func (l *pLogger) Fatalf(format string, v ...interface{}) {
+func (l pLogger) Fatalf(format string, v ...interface{}) {
 	s := fmt.Sprintf(format, v...)
-	l.log.Output(2, fmt.Sprintf(format, v...))
+	dlog.Default().Log(dlog.LevelError, s)
 	if debug {
 		panic(s)
 	}
 	os.Exit(1)
 }
 
-var plog = newPLogger()
+var plog pLogger
 
 // store os.Stdout
 // executing test examples will override os.Stdout
 // and fail consequently if trace output is added
 var stdout = os.Stdout
 
+var (
+	traceLoggerMu     sync.RWMutex // protects customTraceLogger
+	customTraceLogger dlog.Logger
+	stdTraceLogger    = dlog.NewStdLogger(log.New(stdout, fmt.Sprintf("%s ", pPrefix), log.Ldate|log.Ltime))
+)
+
+// TraceLogger returns the default protocol trace logger.
+func TraceLogger() dlog.Logger {
+	traceLoggerMu.RLock()
+	defer traceLoggerMu.RUnlock()
+	if customTraceLogger != nil {
+		return customTraceLogger
+	}
+	return stdTraceLogger
+}
+
+// SetTraceLogger sets the default protocol trace logger. Setting nil restores the standard logger writing to os.Stdout.
+func SetTraceLogger(logger dlog.Logger) {
+	traceLoggerMu.Lock()
+	customTraceLogger = logger
+	traceLoggerMu.Unlock()
+}
+
 const (
 	upStreamPrefix   = "→"
 	downStreamPrefix = "←"
@@ -71,41 +100,67 @@ type traceLogger interface {
 	Log(v interface{})
 }
 
+/*
+traceState is the protocol trace state shared by the upstream (request)
+and downstream (reply) trace loggers of a session.
+*/
+type traceState struct {
+	on            bool        // session specific trace flag
+	logger        dlog.Logger // session specific logger (nil: default protocol trace logger)
+	reqTime       time.Time   // time of last request message
+	connNo        uint64      // session connection number (0: no session)
+	correlationID string      // correlation id of the current statement execution
+}
+
+func newTraceState(on bool, logger dlog.Logger) *traceState {
+	return &traceState{on: on, logger: logger}
+}
+
+func (s *traceState) traceLogger(upStream bool) traceLogger {
+	return &traceLog{prefix: streamPrefix(upStream), upStream: upStream, traceState: s}
+}
+
 type traceLog struct {
-	prefix string
-	log    *log.Logger
+	prefix   string
+	upStream bool
+	*traceState
 }
 
 func (l *traceLog) Log(v interface{}) {
-	var msg string
+	if !l.on && !Trace() {
+		return
+	}
+
+	logger := l.logger
+	if logger == nil {
+		logger = TraceLogger()
+	}
+
+	var keyvals []interface{}
+	if l.connNo != 0 {
+		keyvals = append(keyvals, "connNo", l.connNo)
+	}
+	if l.correlationID != "" {
+		keyvals = append(keyvals, "corrID", l.correlationID)
+	}
 
 	switch v.(type) {
 	case *initRequest, *initReply:
-		msg = fmt.Sprintf("%sINI %s", l.prefix, v)
+		logger.Log(dlog.LevelDebug, fmt.Sprintf("%sINI %s", l.prefix, v), keyvals...)
 	case *messageHeader:
-		msg = fmt.Sprintf("%sMSG %s", l.prefix, v)
+		// timings: request - reply roundtrip
+		now := time.Now()
+		if l.upStream {
+			l.reqTime = now
+			logger.Log(dlog.LevelDebug, fmt.Sprintf("%sMSG %s", l.prefix, v), keyvals...)
+		} else {
+			logger.Log(dlog.LevelDebug, fmt.Sprintf("%sMSG %s", l.prefix, v), append(keyvals, "roundtrip", now.Sub(l.reqTime))...)
+		}
 	case *segmentHeader:
-		msg = fmt.Sprintf(" SEG %s", v)
+		logger.Log(dlog.LevelDebug, fmt.Sprintf(" SEG %s", v), keyvals...)
 	case *partHeader:
-		msg = fmt.Sprintf(" PAR %s", v)
+		logger.Log(dlog.LevelDebug, fmt.Sprintf(" PAR %s", v), keyvals...)
 	default:
-		msg = fmt.Sprintf("     %s", v)
-	}
-	l.log.Output(2, msg)
-}
-
-type noTraceLog struct{}
-
-func (l *noTraceLog) Log(v interface{}) {}
-
-var noTrace = new(noTraceLog)
-
-func newTraceLogger(upStream bool) traceLogger {
-	if !trace {
-		return noTrace
-	}
-	return &traceLog{
-		prefix: streamPrefix(upStream),
-		log:    log.New(stdout, fmt.Sprintf("%s ", pPrefix), log.Ldate|log.Ltime),
+		logger.Log(dlog.LevelDebug, fmt.Sprintf("     %s", v), keyvals...)
 	}
 }
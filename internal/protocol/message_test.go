@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/container/varmap"
+)
+
+func testMessageRoundtrip(t *testing.T, threshold int, expCompressed bool) {
+	var buf bytes.Buffer
+
+	ts := newTraceState(false, nil)
+	pw := newProtocolWriter(newBufferedWriter(&buf, 0, false), varmap.NewVarMap(), ts.traceLogger(true))
+	pw.setCompression(threshold)
+
+	query := command("select * from dummy where x = '" + strings.Repeat("go-hdb ", 1000) + "'")
+	for i := 0; i < 2; i++ { // write twice to check buffer reuse
+		if err := pw.write(1, mtExecuteDirect, false, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pr := newProtocolReader(true, &buf, ts.traceLogger(false))
+	for i := 0; i < 2; i++ {
+		var cmd command
+		if err := pr.iterateParts(func(ph *partHeader) {
+			if ph.partKind == pkCommand {
+				pr.read(&cmd)
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if pr.mh.compressed() != expCompressed {
+			t.Fatalf("compressed %t - expected %t", pr.mh.compressed(), expCompressed)
+		}
+		if !bytes.Equal(cmd, query) {
+			t.Fatalf("command %s - expected %s", cmd, query)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unread bytes %d - expected %d", buf.Len(), 0)
+	}
+}
+
+func testMessageCompressed(t *testing.T) {
+	testMessageRoundtrip(t, 1024, true)
+}
+
+func testMessageBelowThreshold(t *testing.T) {
+	testMessageRoundtrip(t, 1<<20, false)
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"compressed", testMessageCompressed},
+		{"belowThreshold", testMessageBelowThreshold},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
@@ -34,6 +34,8 @@ const (
 	mtExecuteITab     messageType = 78
 	mtFetchNextITab   messageType = 79
 	mtInsertNextITab  messageType = 80
+	mtBatchPrepare    messageType = 81
+	mtDBConnectInfo   messageType = 82
 )
 
 func (mt messageType) clientInfoSupported() bool {
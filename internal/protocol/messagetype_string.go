@@ -33,6 +33,8 @@ func _() {
 	_ = x[mtExecuteITab-78]
 	_ = x[mtFetchNextITab-79]
 	_ = x[mtInsertNextITab-80]
+	_ = x[mtBatchPrepare-81]
+	_ = x[mtDBConnectInfo-82]
 }
 
 const (
@@ -41,14 +43,14 @@ const (
 	_messageType_name_2 = "mtExecute"
 	_messageType_name_3 = "mtWriteLobmtReadLobmtFindLob"
 	_messageType_name_4 = "mtAuthenticatemtConnectmtCommitmtRollbackmtCloseResultsetmtDropStatementIDmtFetchNextmtFetchAbsolutemtFetchRelativemtFetchFirstmtFetchLast"
-	_messageType_name_5 = "mtDisconnectmtExecuteITabmtFetchNextITabmtInsertNextITab"
+	_messageType_name_5 = "mtDisconnectmtExecuteITabmtFetchNextITabmtInsertNextITabmtBatchPreparemtDBConnectInfo"
 )
 
 var (
 	_messageType_index_1 = [...]uint8{0, 15, 24, 36, 45, 53}
 	_messageType_index_3 = [...]uint8{0, 10, 19, 28}
 	_messageType_index_4 = [...]uint8{0, 14, 23, 31, 41, 57, 74, 85, 100, 115, 127, 138}
-	_messageType_index_5 = [...]uint8{0, 12, 25, 40, 56}
+	_messageType_index_5 = [...]uint8{0, 12, 25, 40, 56, 70, 85}
 )
 
 func (i messageType) String() string {
@@ -66,7 +68,7 @@ func (i messageType) String() string {
 	case 65 <= i && i <= 75:
 		i -= 65
 		return _messageType_name_4[_messageType_index_4[i]:_messageType_index_4[i+1]]
-	case 77 <= i && i <= 80:
+	case 77 <= i && i <= 82:
 		i -= 77
 		return _messageType_name_5[_messageType_index_5[i]:_messageType_index_5[i+1]]
 	default:
@@ -87,7 +87,7 @@ func (f *parameterField) String() string {
 	)
 }
 
-func (f *parameterField) Converter() Converter { return f.tc.fieldType() }
+func (f *parameterField) Converter() Converter { return f.tc.scaledFieldType(int(f.fraction)) }
 
 // TypeName returns the type name of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeDatabaseTypeName
@@ -99,26 +99,19 @@ func (f *parameterField) ScanType() DataType { return f.tc.dataType() }
 
 // typeLength returns the type length of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeLength
-func (f *parameterField) TypeLength() (int64, bool) {
-	if f.tc.isVariableLength() {
-		return int64(f.length), true
-	}
-	return 0, false
-}
+func (f *parameterField) TypeLength() (int64, bool) { return f.tc.typeLength(f.length) }
 
 // typePrecisionScale returns the type precision and scale (decimal types) of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypePrecisionScale
 func (f *parameterField) TypePrecisionScale() (int64, int64, bool) {
-	if f.tc.isDecimalType() {
-		return int64(f.length), int64(f.fraction), true
-	}
-	return 0, 0, false
+	return f.tc.typePrecisionScale(f.length, f.fraction)
 }
 
 // nullable returns true if the field may be null, false otherwise.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeNullable
+// The parameter options are a bit set (e.g. optional parameters with default value).
 func (f *parameterField) Nullable() bool {
-	return f.parameterOptions == poOptional
+	return f.parameterOptions&poOptional != 0
 }
 
 // in returns true if the parameter field is an input field.
@@ -211,9 +204,21 @@ func (p *inputParameters) numArg() int {
 }
 
 func (p *inputParameters) decode(dec *encoding.Decoder, ph *partHeader) error {
-	// TODO Sniffer
-	//return fmt.Errorf("not implemented")
-	return nil
+	// TODO Sniffer: input fields are only known by the server (see ServerSession)
+	numArg := ph.numArg()
+	cnt := len(p.inputFields)
+	p.args = make([]driver.NamedValue, numArg*cnt)
+
+	for i := 0; i < numArg; i++ {
+		for j := 0; j < cnt; j++ {
+			_, v, err := decodePrm(dec)
+			if err != nil {
+				return err
+			}
+			p.args[i*cnt+j] = driver.NamedValue{Ordinal: j + 1, Value: v}
+		}
+	}
+	return dec.Error()
 }
 
 func (p *inputParameters) encode(enc *encoding.Encoder) error {
@@ -247,7 +252,7 @@ func (p *outputParameters) decode(dec *encoding.Decoder, ph *partHeader) error {
 	for i := 0; i < numArg; i++ {
 		for j, field := range p.outputFields {
 			var err error
-			if p.fieldValues[i*cols+j], err = decodeRes(dec, field.tc); err != nil {
+			if p.fieldValues[i*cols+j], err = decodeRes(dec, field.tc, int(field.fraction)); err != nil {
 				return err
 			}
 		}
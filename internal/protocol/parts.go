@@ -43,10 +43,13 @@ func (*authInitRep) kind() partKind         { return pkAuthentication }
 func (*authFinalReq) kind() partKind        { return pkAuthentication }
 func (*authFinalRep) kind() partKind        { return pkAuthentication }
 func (clientContext) kind() partKind        { return pkClientContext }
+func (commandInfo) kind() partKind          { return pkCommandInfo }
+func (dbConnectInfo) kind() partKind        { return pkDBConnectInfo }
 func (clientID) kind() partKind             { return pkClientID }
 func (clientInfo) kind() partKind           { return pkClientInfo }
 func (connectOptions) kind() partKind       { return pkConnectOptions }
 func (*topologyInformation) kind() partKind { return pkTopologyInformation }
+func (*tableLocation) kind() partKind       { return pkTableLocation }
 func (command) kind() partKind              { return pkCommand }
 func (*rowsAffected) kind() partKind        { return pkRowsAffected }
 func (transactionFlags) kind() partKind     { return pkTransactionFlags }
@@ -59,6 +62,7 @@ func (*resultMetadata) kind() partKind      { return pkResultMetadata }
 func (resultsetID) kind() partKind          { return pkResultsetID }
 func (*resultset) kind() partKind           { return pkResultset }
 func (fetchsize) kind() partKind            { return pkFetchSize }
+func (fetchPosition) kind() partKind        { return pkFetchOptions }
 func (*readLobRequest) kind() partKind      { return pkReadLobRequest }
 func (*readLobReply) kind() partKind        { return pkReadLobReply }
 func (*writeLobRequest) kind() partKind     { return pkWriteLobRequest }
@@ -74,10 +78,13 @@ var (
 	_ part = (*authFinalReq)(nil)
 	_ part = (*authFinalRep)(nil)
 	_ part = (*clientContext)(nil)
+	_ part = (*commandInfo)(nil)
+	_ part = (*dbConnectInfo)(nil)
 	_ part = (*clientID)(nil)
 	_ part = (*clientInfo)(nil)
 	_ part = (*connectOptions)(nil)
 	_ part = (*topologyInformation)(nil)
+	_ part = (*tableLocation)(nil)
 	_ part = (*command)(nil)
 	_ part = (*rowsAffected)(nil)
 	_ part = (*transactionFlags)(nil)
@@ -90,6 +97,7 @@ var (
 	_ part = (*resultsetID)(nil)
 	_ part = (*resultset)(nil)
 	_ part = (*fetchsize)(nil)
+	_ part = (*fetchPosition)(nil)
 	_ part = (*readLobRequest)(nil)
 	_ part = (*readLobReply)(nil)
 	_ part = (*writeLobRequest)(nil)
@@ -114,6 +122,7 @@ func (command) numArg() int         { return 1 }
 func (statementID) numArg() int     { return 1 }
 func (resultsetID) numArg() int     { return 1 }
 func (fetchsize) numArg() int       { return 1 }
+func (fetchPosition) numArg() int   { return 1 }
 func (*readLobRequest) numArg() int { return 1 }
 
 // func (lobFlags) numArg() int                   { return 1 }
@@ -123,12 +132,14 @@ const (
 	statementIDSize    = 8
 	resultsetIDSize    = 8
 	fetchsizeSize      = 4
+	fetchPositionSize  = 4
 	readLobRequestSize = 24
 )
 
 func (statementID) size() int    { return statementIDSize }
 func (resultsetID) size() int    { return resultsetIDSize }
 func (fetchsize) size() int      { return fetchsizeSize }
+func (fetchPosition) size() int  { return fetchPositionSize }
 func (readLobRequest) size() int { return readLobRequestSize }
 
 // func (lobFlags) size() int       { return tinyintFieldSize }
@@ -138,6 +149,8 @@ var (
 	_ partWriter = (*authInitReq)(nil)
 	_ partWriter = (*authFinalReq)(nil)
 	_ partWriter = (*clientContext)(nil)
+	_ partWriter = (*commandInfo)(nil)
+	_ partWriter = (*dbConnectInfo)(nil)
 	_ partWriter = (*clientID)(nil)
 	_ partWriter = (*clientInfo)(nil)
 	_ partWriter = (*connectOptions)(nil)
@@ -146,6 +159,8 @@ var (
 	_ partWriter = (*inputParameters)(nil)
 	_ partWriter = (*resultsetID)(nil)
 	_ partWriter = (*fetchsize)(nil)
+	_ partWriter = (*fetchPosition)(nil)
+	_ partWriter = (*statementContext)(nil)
 	_ partReader = (*readLobRequest)(nil)
 	_ partReader = (*writeLobRequest)(nil)
 
@@ -165,10 +180,13 @@ var (
 	_ partReader = (*authFinalReq)(nil)
 	_ partReader = (*authFinalRep)(nil)
 	_ partReader = (*clientContext)(nil)
+	_ partReader = (*commandInfo)(nil)
+	_ partReader = (*dbConnectInfo)(nil)
 	_ partReader = (*clientID)(nil)
 	_ partReader = (*clientInfo)(nil)
 	_ partReader = (*connectOptions)(nil)
 	_ partReader = (*topologyInformation)(nil)
+	_ partReader = (*tableLocation)(nil)
 	_ partReader = (*command)(nil)
 	_ partReader = (*rowsAffected)(nil)
 	_ partReader = (*transactionFlags)(nil)
@@ -181,6 +199,7 @@ var (
 	_ partReader = (*resultsetID)(nil)
 	_ partReader = (*resultset)(nil)
 	_ partReader = (*fetchsize)(nil)
+	_ partReader = (*fetchPosition)(nil)
 	_ partReader = (*readLobRequest)(nil)
 	_ partReader = (*writeLobRequest)(nil)
 	_ partReader = (*readLobReply)(nil)
@@ -207,10 +226,13 @@ var (
 var partTypeMap = map[partKind]reflect.Type{
 	pkError:               reflect.TypeOf((*hdbErrors)(nil)).Elem(),
 	pkClientContext:       reflect.TypeOf((*clientContext)(nil)).Elem(),
+	pkCommandInfo:         reflect.TypeOf((*commandInfo)(nil)).Elem(),
+	pkDBConnectInfo:       reflect.TypeOf((*dbConnectInfo)(nil)).Elem(),
 	pkClientID:            reflect.TypeOf((*clientID)(nil)).Elem(),
 	pkClientInfo:          reflect.TypeOf((*clientInfo)(nil)).Elem(),
 	pkConnectOptions:      reflect.TypeOf((*connectOptions)(nil)).Elem(),
 	pkTopologyInformation: reflect.TypeOf((*topologyInformation)(nil)).Elem(),
+	pkTableLocation:       reflect.TypeOf((*tableLocation)(nil)).Elem(),
 	pkCommand:             reflect.TypeOf((*command)(nil)).Elem(),
 	pkRowsAffected:        reflect.TypeOf((*rowsAffected)(nil)).Elem(),
 	pkTransactionFlags:    reflect.TypeOf((*transactionFlags)(nil)).Elem(),
@@ -223,6 +245,7 @@ var partTypeMap = map[partKind]reflect.Type{
 	pkResultsetID:         reflect.TypeOf((*resultsetID)(nil)).Elem(),
 	pkResultset:           reflect.TypeOf((*resultset)(nil)).Elem(),
 	pkFetchSize:           reflect.TypeOf((*fetchsize)(nil)).Elem(),
+	pkFetchOptions:        reflect.TypeOf((*fetchPosition)(nil)).Elem(),
 	pkReadLobRequest:      reflect.TypeOf((*readLobRequest)(nil)).Elem(),
 	pkReadLobReply:        reflect.TypeOf((*readLobReply)(nil)).Elem(),
 	pkWriteLobReply:       reflect.TypeOf((*writeLobReply)(nil)).Elem(),
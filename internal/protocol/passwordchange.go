@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Password change of users forced to change the password (e.g. initial or expired passwords).
+
+// PasswordChangeHandler returns the new password of user username, if the database server requires
+// the password to be changed when connecting.
+type PasswordChangeHandler func(ctx context.Context, username string) (string, error)
+
+// alterUserPassword is the statement changing the password of a user.
+const alterUserPassword = "alter user %s password %s"
+
+// quoteIdentifier returns s as quoted sql identifier.
+func quoteIdentifier(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+
+// changePassword changes the password of the session user to the password returned by handler. Sessions of
+// users forced to change the password are restricted to the password change until the password is changed.
+func (s *Session) changePassword(ctx context.Context, handler PasswordChangeHandler) error {
+	username := s.cfg.Username()
+	password, err := handler(ctx, username)
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return errors.New("password change: empty password")
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.ExecDirect(fmt.Sprintf(alterUserPassword, quoteIdentifier(username), quoteIdentifier(password))); err != nil {
+		return fmt.Errorf("password change: %w", err)
+	}
+	s.changedPassword = password
+	return nil
+}
+
+// ChangedPassword returns the new password and true, if the password was changed when the session was opened.
+func (s *Session) ChangedPassword() (string, bool) { return s.changedPassword, s.changedPassword != "" }
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"database/sql/driver"
+)
+
+// prefetchChunk is a result set chunk fetched in advance.
+type prefetchChunk struct {
+	fieldValues []driver.Value
+	attributes  partAttributes
+	err         error
+}
+
+/*
+prefetcher fetches the next chunks of a query result in a separate goroutine while the rows of the current chunk
+are scanned (double buffering), so that the network round trips of the fetches overlap with the processing of the
+rows by the application. At most n chunks are fetched in advance.
+
+The session is locked for each fetch only. Zero-copy strings of each chunk are decoded into an own buffer, as
+the application might still scan the strings of the previous chunks. The goroutine terminates after the last chunk
+is fetched, after a fetch error or after the prefetcher is stopped. As the goroutine never discards a fetched chunk,
+all chunks need to be received until the chunk channel is closed (see stop).
+*/
+type prefetcher struct {
+	chunks chan prefetchChunk
+	done   chan struct{}
+}
+
+func newPrefetcher(s *Session, qr *queryResult, fetchSize, n int) *prefetcher {
+	pf := &prefetcher{chunks: make(chan prefetchChunk, n), done: make(chan struct{})}
+	go pf.run(s, qr, fetchSize)
+	return pf
+}
+
+func (pf *prefetcher) run(s *Session, qr *queryResult, fetchSize int) {
+	defer close(pf.chunks)
+	for {
+		select {
+		case <-pf.done:
+			return
+		default:
+		}
+
+		var chunk prefetchChunk
+		s.Lock()
+		if s.IsBad() {
+			chunk.err = driver.ErrBadConn
+		} else {
+			s.pr.detachArena()
+			chunk.err = s.observed(OpFetch, func() (err error) {
+				chunk.fieldValues, chunk.attributes, err = s.fetchChunk(qr, fetchSize)
+				return err
+			})
+		}
+		s.Unlock()
+
+		pf.chunks <- chunk
+		if chunk.err != nil || chunk.attributes.LastPacket() {
+			return
+		}
+	}
+}
+
+// stop stops the prefetching and calls f for all chunks fetched in advance.
+func (pf *prefetcher) stop(f func(chunk prefetchChunk)) {
+	close(pf.done)
+	for chunk := range pf.chunks {
+		f(chunk)
+	}
+}
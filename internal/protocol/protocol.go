@@ -5,14 +5,16 @@
 package protocol
 
 import (
-	"bufio"
+	"bytes"
 	"database/sql/driver"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"time"
 
 	"github.com/SAP/go-hdb/driver/sqltrace"
+	"github.com/SAP/go-hdb/internal/compress/lz4"
 	"github.com/SAP/go-hdb/internal/container/varmap"
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
@@ -36,10 +38,11 @@ var (
 
 // A PrepareResult represents the result of a prepare statement.
 type PrepareResult struct {
-	fc           functionCode
-	stmtID       uint64
-	prmFields    []*parameterField
-	resultFields []*resultField
+	fc            functionCode
+	stmtID        uint64
+	prmFields     []*parameterField
+	resultFields  []*resultField
+	tableLocation tableLocation // volume ids of the accessed tables (scale-out statement routing)
 }
 
 // Check checks consistency of the prepare result.
@@ -89,6 +92,11 @@ func (pr *PrepareResult) NumInputField() int {
 	return numField
 }
 
+// RowSize returns the encoded size of the parameter row args.
+func (pr *PrepareResult) RowSize(args []driver.NamedValue) int {
+	return newInputParameters(pr.prmFields, args).size()
+}
+
 // PrmField returns the parameter field at index idx.
 func (pr *PrepareResult) PrmField(idx int) Field {
 	return pr.prmFields[idx]
@@ -127,6 +135,13 @@ func (qr *queryResult) copyRow(idx int, dest []driver.Value) {
 	copy(dest, qr.fieldValues[idx*cols:(idx+1)*cols])
 }
 
+// setChunk replaces the rows of the current chunk by the rows of the next chunk.
+func (qr *queryResult) setChunk(fieldValues []driver.Value, attributes partAttributes) {
+	freeFieldValues(qr.fieldValues)
+	qr.fieldValues = fieldValues
+	qr.attributes = attributes
+}
+
 // Closed implements the RowsResult interface.
 func (qr *queryResult) closed() bool {
 	return qr.attributes.ResultsetClosed()
@@ -240,26 +255,49 @@ type protocolReader struct {
 	ph *partHeader
 
 	msgSize  int64
+	cntSegm  int
 	numPart  int
 	cntPart  int
 	partRead bool
 
 	partReaderCache map[partKind]partReader
 
+	bufRd *bufferedReader // adaptive buffer (nil: reader is not adaptive)
+
 	lastErrors       *hdbErrors
 	lastRowsAffected *rowsAffected
 
+	serverExecutionTime time.Duration // server processing time of last reply
+
+	correlationID string // correlation id of the current statement execution
+
+	zeroCopy bool // zero-copy string decoding of result sets
+
+	stats *SessionStats // network statistics (nil: no statistics)
+
+	strict        bool  // strict protocol validation (see validate.go)
+	segSize       int64 // remaining segment size (strict protocol validation)
+	validationErr error // protocol violation (strict protocol validation)
+
+	readAheadOn bool       // overlap network reads of large messages with decoding
+	readAhead   *readAhead // read ahead buffers
+
+	// message decompression
+	compBuf []byte        // compressed message buffer
+	msgBuf  []byte        // uncompressed message buffer
+	msgRd   *bytes.Reader // uncompressed message reader
+
 	// partReader read errors could be
 	// - read buffer errors -> buffer Error() and ResetError()
 	// - plus other errors (which cannot be ignored, e.g. Lob reader)
 	err error
 }
 
-func newProtocolReader(upStream bool, rd io.Reader) *protocolReader {
+func newProtocolReader(upStream bool, rd io.Reader, tracer traceLogger) *protocolReader {
 	return &protocolReader{
 		upStream:        upStream,
 		dec:             encoding.NewDecoder(rd),
-		tracer:          newTraceLogger(upStream),
+		tracer:          tracer,
 		partReaderCache: map[partKind]partReader{},
 		mh:              &messageHeader{},
 		sh:              &segmentHeader{},
@@ -271,6 +309,11 @@ func (r *protocolReader) setDfv(dfv int) {
 	r.dec.SetDfv(dfv)
 }
 
+// setLocation sets the time location of date and time values read (nil: UTC).
+func (r *protocolReader) setLocation(loc *time.Location) {
+	r.dec.SetLocation(loc)
+}
+
 func (r *protocolReader) readSkip() error            { return r.iterateParts(nil) }
 func (r *protocolReader) sessionID() int64           { return r.mh.sessionID }
 func (r *protocolReader) functionCode() functionCode { return r.sh.functionCode }
@@ -330,6 +373,8 @@ func (r *protocolReader) checkError() error {
 		}
 	}
 
+	r.lastErrors.correlationID = r.correlationID
+
 	if r.lastErrors.isWarnings() {
 		for _, e := range r.lastErrors.errors {
 			sqltrace.Traceln(e)
@@ -341,19 +386,30 @@ func (r *protocolReader) checkError() error {
 }
 
 func (r *protocolReader) canSkip(pk partKind) bool {
-	// errors and rowsAffected needs always to be read
-	if pk == pkError || pk == pkRowsAffected {
+	// errors, rowsAffected and statementContext needs always to be read
+	if pk == pkError || pk == pkRowsAffected || pk == pkStatementContext {
 		return false
 	}
-	if debug {
+	if debug || r.strict {
 		return false
 	}
 	return true
 }
 
+// detachArena lets the next result set chunk decode zero-copy strings into a new buffer, so that the strings
+// of the chunks read so far stay valid (see prefetcher).
+func (r *protocolReader) detachArena() { r.dec.DetachArena() }
+
 func (r *protocolReader) read(part partReader) error {
 	r.partRead = true
 
+	if _, ok := part.(*resultset); ok && r.zeroCopy {
+		// strings of the previous result set chunk get invalid
+		r.dec.ResetArena()
+		r.dec.SetZeroCopy(true)
+		defer r.dec.SetZeroCopy(false)
+	}
+
 	err := r.readPart(part)
 	if err != nil {
 		r.err = err
@@ -364,6 +420,8 @@ func (r *protocolReader) read(part partReader) error {
 		r.lastErrors = part
 	case *rowsAffected:
 		r.lastRowsAffected = part
+	case *statementContext:
+		r.serverExecutionTime = part.serverExecutionTime()
 	}
 	return err
 }
@@ -412,6 +470,10 @@ func (r *protocolReader) skip() error {
 		part, err = r.defaultPart(pk)
 	}
 	if err != nil {
+		if r.strict {
+			r.err = r.violation("unknown part kind %s", pk)
+			return r.err
+		}
 		return r.skipPart()
 	}
 	return r.read(part)
@@ -444,6 +506,12 @@ func (r *protocolReader) readPart(part partReader) error {
 	cnt := r.dec.Cnt()
 	r.tracer.Log(part)
 
+	if r.strict {
+		if err := r.validatePart(part, cnt); err != nil {
+			return err
+		}
+	}
+
 	bufferLen := int(r.ph.bufferLength)
 	switch {
 	case cnt < bufferLen: // protocol buffer length > read bytes -> skip the unread bytes
@@ -480,7 +548,26 @@ func (r *protocolReader) iterateParts(partCb func(ph *partHeader)) error {
 	}
 	r.tracer.Log(r.mh)
 
+	r.cntSegm, r.cntPart = 0, 0
+	if r.strict {
+		if err := r.validateMessageHeader(); err != nil {
+			return err
+		}
+	}
+
 	r.msgSize = int64(r.mh.varPartLength)
+	if r.mh.compressed() {
+		restore, err := r.uncompress()
+		if err != nil {
+			return err
+		}
+		defer restore()
+		r.msgSize = int64(r.mh.compressionVarPartLength)
+	} else if r.readAheadOn && r.mh.varPartLength >= readAheadThreshold {
+		stop := r.startReadAhead(int(r.mh.varPartLength))
+		defer stop()
+	}
+	r.serverExecutionTime = 0
 
 	for i := 0; i < int(r.mh.noOfSegm); i++ {
 
@@ -489,6 +576,13 @@ func (r *protocolReader) iterateParts(partCb func(ph *partHeader)) error {
 		}
 		r.tracer.Log(r.sh)
 
+		r.cntSegm++
+		if r.strict {
+			if err := r.validateSegmentHeader(r.msgSize); err != nil {
+				return err
+			}
+		}
+
 		r.msgSize -= int64(r.sh.segmentLength)
 		r.numPart = int(r.sh.noOfParts)
 		r.cntPart = 0
@@ -501,6 +595,11 @@ func (r *protocolReader) iterateParts(partCb func(ph *partHeader)) error {
 			r.tracer.Log(r.ph)
 
 			r.cntPart++
+			if r.strict {
+				if err := r.validatePartHeader(); err != nil {
+					return err
+				}
+			}
 
 			r.partRead = false
 			if partCb != nil {
@@ -514,35 +613,131 @@ func (r *protocolReader) iterateParts(partCb func(ph *partHeader)) error {
 			}
 		}
 	}
+	if r.readAhead != nil {
+		r.readAhead.stop() // stop reading ahead before the read buffer might be resized
+	}
+	msgSize := messageHeaderSize + int(r.mh.varPartLength)
+	if r.bufRd != nil {
+		r.bufRd.messageRead(msgSize)
+	}
+	r.stats.addRead(msgSize)
 	return r.checkError()
 }
 
+// uncompress reads and uncompresses the variable part of a compressed message and lets the decoder
+// read from the uncompressed data. The returned function restores the original decoder reader.
+func (r *protocolReader) uncompress() (func(), error) {
+	size := int(r.mh.varPartLength)
+	if cap(r.compBuf) < size {
+		r.compBuf = make([]byte, size)
+	}
+	r.compBuf = r.compBuf[:size]
+	r.dec.Bytes(r.compBuf)
+	if err := r.dec.Error(); err != nil {
+		return nil, err
+	}
+
+	size = int(r.mh.compressionVarPartLength)
+	if cap(r.msgBuf) < size {
+		r.msgBuf = make([]byte, size)
+	}
+	r.msgBuf = r.msgBuf[:size]
+	n, err := lz4.Uncompress(r.msgBuf, r.compBuf)
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, fmt.Errorf("uncompressed message size %d - expected %d", n, size)
+	}
+
+	if r.msgRd == nil {
+		r.msgRd = bytes.NewReader(r.msgBuf)
+	} else {
+		r.msgRd.Reset(r.msgBuf)
+	}
+	rd := r.dec.Reader()
+	r.dec.SetReader(r.msgRd)
+	return func() { r.dec.SetReader(rd) }, nil
+}
+
+// startReadAhead lets the decoder read the message variable part of size bytes via read ahead.
+// The returned function stops reading ahead and restores the original decoder reader.
+func (r *protocolReader) startReadAhead(size int) func() {
+	if r.readAhead == nil {
+		r.readAhead = newReadAhead()
+	}
+	rd := r.dec.Reader()
+	r.readAhead.start(rd, size)
+	r.dec.SetReader(r.readAhead)
+	return func() {
+		r.readAhead.stop()
+		r.dec.SetReader(rd)
+	}
+}
+
 // protocol writer
 type protocolWriter struct {
-	wr  *bufio.Writer
+	wr  *bufferedWriter
 	sv  *varmap.VarMap // session variables
+	csv *varmap.VarMap // session variables of the connection (set at runtime, see Session.SetSessionVariables)
 	enc *encoding.Encoder
 
 	tracer traceLogger
 
+	capture *captureConn // wire capture (nil: capture not active)
+
+	noConnectClientInfo bool // send client info with the first statement instead of CONNECT (HANA 1.0 compatibility)
+
+	stats *SessionStats // network statistics (nil: no statistics)
+
+	partSize []int // reuse part size buffer
+
+	commandOptions commandOptions // segment command options of the next request (see writeOptions)
+
+	// message compression
+	compress          bool              // compression active
+	compressThreshold int               // minimal message size to be compressed
+	msgBuf            bytes.Buffer      // uncompressed message buffer
+	msgEnc            *encoding.Encoder // uncompressed message encoder
+	compBuf           []byte            // compressed message buffer
+
 	// reuse header
 	mh *messageHeader
 	sh *segmentHeader
 	ph *partHeader
 }
 
-func newProtocolWriter(wr *bufio.Writer, sv *varmap.VarMap) *protocolWriter {
+func newProtocolWriter(wr *bufferedWriter, sv *varmap.VarMap, tracer traceLogger) *protocolWriter {
 	return &protocolWriter{
 		wr:     wr,
 		sv:     sv,
+		csv:    varmap.NewVarMap(),
 		enc:    encoding.NewEncoder(wr),
-		tracer: newTraceLogger(true),
+		tracer: tracer,
 		mh:     new(messageHeader),
 		sh:     new(segmentHeader),
 		ph:     new(partHeader),
 	}
 }
 
+// setCompression activates the compression of messages with a size of at least threshold bytes.
+func (w *protocolWriter) setCompression(threshold int) {
+	w.compress = true
+	w.compressThreshold = threshold
+	if w.msgEnc == nil {
+		w.msgEnc = encoding.NewEncoder(&w.msgBuf)
+		w.msgEnc.SetLocation(w.enc.Location())
+	}
+}
+
+// setLocation sets the time location of date and time values written (nil: UTC).
+func (w *protocolWriter) setLocation(loc *time.Location) {
+	w.enc.SetLocation(loc)
+	if w.msgEnc != nil {
+		w.msgEnc.SetLocation(loc)
+	}
+}
+
 const (
 	productVersionMajor  = 4
 	productVersionMinor  = 20
@@ -565,20 +760,51 @@ func (w *protocolWriter) writeProlog() error {
 	return w.wr.Flush()
 }
 
+// clientInfo returns the changes of the connector and the connection session variables to be sent as client info.
+// Session variables of the connection take precedence over the connector session variables.
+func (w *protocolWriter) clientInfo() clientInfo {
+	ci := clientInfo{}
+
+	upd, del := w.sv.Delta()
+	connUpd, _ := w.csv.Delta() // session variables of the connection are not deleted
+	connVars := w.csv.LoadMap()
+
+	// TODO: how to delete session variables via clientInfo
+	// ...for the time being we set the value to <space>...
+	for k := range del {
+		if _, ok := connVars[k]; !ok {
+			ci[k] = ""
+		}
+	}
+	for k, v := range upd {
+		if _, ok := connVars[k]; !ok {
+			ci[k] = v
+		}
+	}
+	for k, v := range connUpd {
+		ci[k] = v
+	}
+	return ci
+}
+
+// writeOptions writes a request with the segment command options.
+func (w *protocolWriter) writeOptions(sessionID int64, messageType messageType, commit bool, options commandOptions, writers ...partWriter) error {
+	w.commandOptions = options
+	defer func() { w.commandOptions = coNil }()
+	return w.write(sessionID, messageType, commit, writers...)
+}
+
 func (w *protocolWriter) write(sessionID int64, messageType messageType, commit bool, writers ...partWriter) error {
 	// check on session variables to be send as ClientInfo
-	if messageType.clientInfoSupported() && w.sv.HasUpdates() {
-		upd, del := w.sv.Delta()
-		// TODO: how to delete session variables via clientInfo
-		// ...for the time being we set the value to <space>...
-		for k := range del {
-			upd[k] = ""
-		}
-		writers = append([]partWriter{clientInfo(upd)}, writers...)
+	if messageType.clientInfoSupported() && !(w.noConnectClientInfo && messageType == mtConnect) && (w.sv.HasUpdates() || w.csv.HasUpdates()) {
+		writers = append([]partWriter{w.clientInfo()}, writers...)
 	}
 
 	numWriters := len(writers)
-	partSize := make([]int, numWriters)
+	if cap(w.partSize) < numWriters {
+		w.partSize = make([]int, numWriters)
+	}
+	partSize := w.partSize[:numWriters]
 	size := int64(segmentHeaderSize + numWriters*partHeaderSize) //int64 to hold MaxUInt32 in 32bit OS
 
 	for i, part := range writers {
@@ -591,36 +817,89 @@ func (w *protocolWriter) write(sessionID int64, messageType messageType, commit
 		return fmt.Errorf("message size %d exceeds maximum message header value %d", size, int64(math.MaxUint32)) //int64: without cast overflow error in 32bit OS
 	}
 
-	bufferSize := size
-
 	w.mh.sessionID = sessionID
 	w.mh.varPartLength = uint32(size)
-	w.mh.varPartSize = uint32(bufferSize)
+	w.mh.varPartSize = uint32(size)
 	w.mh.noOfSegm = 1
+	w.mh.packetOptions = 0
+	w.mh.compressionVarPartLength = 0
+
+	if size > math.MaxInt32 {
+		return fmt.Errorf("message size %d exceeds maximum part header value %d", size, math.MaxInt32)
+	}
+
+	if w.compress && size >= int64(w.compressThreshold) {
+		if err := w.writeCompressed(size, messageType, commit, writers, partSize); err != nil {
+			return err
+		}
+	} else {
+		if err := w.mh.encode(w.enc); err != nil {
+			return err
+		}
+		w.tracer.Log(w.mh)
+
+		if err := w.writeSegment(w.enc, size, messageType, commit, writers, partSize); err != nil {
+			return err
+		}
+	}
+
+	if err := w.wr.Flush(); err != nil {
+		return err
+	}
+	msgSize := messageHeaderSize + int(w.mh.varPartLength)
+	w.wr.messageWritten(msgSize)
+	w.stats.addWritten(msgSize)
+	return nil
+}
+
+// writeCompressed encodes the message segment into the message buffer and writes the message compressed
+// if the compressed size is smaller than the uncompressed one.
+func (w *protocolWriter) writeCompressed(size int64, messageType messageType, commit bool, writers []partWriter, partSize []int) error {
+	w.msgBuf.Reset()
+	if err := w.writeSegment(w.msgEnc, size, messageType, commit, writers, partSize); err != nil {
+		return err
+	}
+
+	msg := w.msgBuf.Bytes()
+	w.compBuf = lz4.Compress(w.compBuf, msg)
+	if len(w.compBuf) < len(msg) {
+		w.mh.packetOptions = poCompressed
+		w.mh.varPartLength = uint32(len(w.compBuf))
+		w.mh.varPartSize = uint32(len(w.compBuf))
+		w.mh.compressionVarPartLength = uint32(len(msg))
+		msg = w.compBuf
+	}
 
 	if err := w.mh.encode(w.enc); err != nil {
 		return err
 	}
 	w.tracer.Log(w.mh)
+	w.enc.Bytes(msg)
 
-	if size > math.MaxInt32 {
-		return fmt.Errorf("message size %d exceeds maximum part header value %d", size, math.MaxInt32)
+	// do not hold buffers of huge messages (e.g. bulk inserts)
+	if w.msgBuf.Cap() > maxAdaptiveBufferSize {
+		w.msgBuf = bytes.Buffer{}
+		w.compBuf = nil
 	}
+	return nil
+}
 
+func (w *protocolWriter) writeSegment(enc *encoding.Encoder, size int64, messageType messageType, commit bool, writers []partWriter, partSize []int) error {
 	w.sh.messageType = messageType
 	w.sh.commit = commit
+	w.sh.commandOptions = w.commandOptions
 	w.sh.segmentKind = skRequest
 	w.sh.segmentLength = int32(size)
 	w.sh.segmentOfs = 0
-	w.sh.noOfParts = int16(numWriters)
+	w.sh.noOfParts = int16(len(writers))
 	w.sh.segmentNo = 1
 
-	if err := w.sh.encode(w.enc); err != nil {
+	if err := w.sh.encode(enc); err != nil {
 		return err
 	}
 	w.tracer.Log(w.sh)
 
-	bufferSize -= segmentHeaderSize
+	bufferSize := size - segmentHeaderSize
 
 	for i, part := range writers {
 
@@ -634,19 +913,23 @@ func (w *protocolWriter) write(sessionID int64, messageType messageType, commit
 		w.ph.bufferLength = int32(size)
 		w.ph.bufferSize = int32(bufferSize)
 
-		if err := w.ph.encode(w.enc); err != nil {
+		if err := w.ph.encode(enc); err != nil {
 			return err
 		}
 		w.tracer.Log(w.ph)
 
-		if err := part.encode(w.enc); err != nil {
+		if w.capture != nil && w.ph.partKind == pkAuthentication && enc == w.enc {
+			w.capture.mask(w.capture.upPos+int64(w.wr.Buffered()), size)
+		}
+
+		if err := part.encode(enc); err != nil {
 			return err
 		}
 		w.tracer.Log(part)
 
-		w.enc.Zeroes(pad)
+		enc.Zeroes(pad)
 
 		bufferSize -= int64(partHeaderSize + size + pad)
 	}
-	return w.wr.Flush()
+	return nil
 }
@@ -67,6 +67,9 @@ func encodeID(id uint64) string {
 
 var errInvalidCmdToken = errors.New("invalid command token")
 
+// ErrMixedParameters is returned for queries using positional (?) and named (:name) parameters.
+var ErrMixedParameters = errors.New("mixed positional and named parameters")
+
 const (
 	bulkQuery = "bulk"
 )
@@ -77,6 +80,7 @@ type QueryDescr struct {
 	kind   QueryKind
 	isBulk bool
 	id     uint64
+	params []string // named parameters in positional order
 }
 
 func (d *QueryDescr) String() string {
@@ -95,6 +99,10 @@ func (d *QueryDescr) ID() uint64 { return d.id }
 // IsBulk returns true if the query is a bulk statement..
 func (d *QueryDescr) IsBulk() bool { return d.isBulk }
 
+// NamedParams returns the parameter names of a query using named parameters (:name) in positional order.
+// A parameter name used more than once in the query is returned for each occurrence.
+func (d *QueryDescr) NamedParams() []string { return d.params }
+
 // NewQueryDescr returns a new QueryDescr instance.
 func NewQueryDescr(query string, sc *scanner.Scanner) (*QueryDescr, error) {
 	d := &QueryDescr{query: query}
@@ -115,6 +123,7 @@ func NewQueryDescr(query string, sc *scanner.Scanner) (*QueryDescr, error) {
 
 	// kind
 	keyword := strings.ToLower(query[start:end])
+	cmdStart := start
 
 	d.kind = QkUnknown
 	kind, ok := queryKeywordKind[keyword]
@@ -138,7 +147,46 @@ func NewQueryDescr(query string, sc *scanner.Scanner) (*QueryDescr, error) {
 		}
 	}
 
-	// TODO release v1.0.0 - scan variables (named parameters)
+	// named parameters are not replaced in statements containing SQLScript (variable references)
+	if d.kind == QkCreate || keyword == "do" || keyword == "alter" {
+		return d, nil
+	}
+	return d, d.scanNamedParams(sc, query, cmdStart)
+}
 
-	return d, nil
+// scanNamedParams replaces named parameters (:name) by positional parameters (?)
+// in query starting at the command start position.
+func (d *QueryDescr) scanNamedParams(sc *scanner.Scanner, query string, cmdStart int) error {
+	var b strings.Builder
+	last, numVars := cmdStart, 0
+	for {
+		token, start, end := sc.Next()
+		if token == scanner.EOS {
+			break
+		}
+		switch token {
+		case scanner.Variable:
+			numVars++
+		case scanner.NamedVariable:
+			if end-start == 1 { // colon without name
+				continue
+			}
+			if d.params == nil {
+				b.Grow(len(query) - cmdStart)
+			}
+			d.params = append(d.params, query[start+1:end])
+			b.WriteString(query[last:start])
+			b.WriteByte('?')
+			last = end
+		}
+	}
+	if d.params == nil {
+		return nil
+	}
+	if numVars != 0 {
+		return ErrMixedParameters
+	}
+	b.WriteString(query[last:])
+	d.query = b.String()
+	return nil
 }
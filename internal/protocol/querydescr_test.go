@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+)
+
+func TestQueryDescrNamedParams(t *testing.T) {
+	tests := []struct {
+		query  string
+		result string
+		params []string
+	}{
+		{"select * from t where a = ?", "select * from t where a = ?", nil},
+		{"select * from t where a = :a and b = :b2", "select * from t where a = ? and b = ?", []string{"a", "b2"}},
+		{"bulk insert into t values (:id, :name, :id)", "insert into t values (?, ?, ?)", []string{"id", "name", "id"}},
+		{"select ':a' from t where a = :a -- :b", "select ':a' from t where a = ? -- :b", []string{"a"}},
+		{"select * from t where a = :1", "select * from t where a = :1", nil},
+		{"create procedure p (in a integer) as begin select :a from dummy; end", "create procedure p (in a integer) as begin select :a from dummy; end", nil},
+		{"do begin declare a integer = 1; select :a from dummy; end", "do begin declare a integer = 1; select :a from dummy; end", nil},
+	}
+
+	sc := &scanner.Scanner{}
+	for _, test := range tests {
+		qd, err := NewQueryDescr(test.query, sc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if qd.Query() != test.result {
+			t.Fatalf("query %s - expected %s", qd.Query(), test.result)
+		}
+		if !reflect.DeepEqual(qd.NamedParams(), test.params) {
+			t.Fatalf("named params %v - expected %v", qd.NamedParams(), test.params)
+		}
+	}
+
+	if _, err := NewQueryDescr("select * from t where a = :a and b = ?", sc); err != ErrMixedParameters {
+		t.Fatalf("error %v - expected %v", err, ErrMixedParameters)
+	}
+}
+
+func TestQueryDescrBulk(t *testing.T) {
+	tests := []struct {
+		query  string
+		isBulk bool
+		kind   QueryKind
+	}{
+		{"bulk insert into t values (?)", true, QkInsert},
+		{"-- comment ?\nbulk insert into t values (?)", true, QkInsert},
+		{"/* bulk /* nested */ */ insert into t values (?)", false, QkInsert},
+		{"insert into bulk values ('bulk ?')", false, QkInsert},
+	}
+
+	sc := &scanner.Scanner{}
+	for _, test := range tests {
+		qd, err := NewQueryDescr(test.query, sc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if qd.IsBulk() != test.isBulk {
+			t.Fatalf("query %s: bulk %t - expected %t", test.query, qd.IsBulk(), test.isBulk)
+		}
+		if qd.Kind() != test.kind {
+			t.Fatalf("query %s: kind %s - expected %s", test.query, qd.Kind(), test.kind)
+		}
+	}
+}
@@ -45,22 +45,44 @@ var (
 	_ driver.RowsColumnTypePrecisionScale   = (*queryResultSet)(nil) // go 1.8
 	_ driver.RowsColumnTypeScanType         = (*queryResultSet)(nil) // go 1.8
 	_ driver.RowsNextResultSet              = (*queryResultSet)(nil) // go 1.8
+	_ ScrollableRows                        = (*queryResultSet)(nil)
 )
 
 type queryResultSet struct {
-	session *Session
-	rrs     []rowsResult
-	rr      rowsResult
-	idx     int // current result set
-	pos     int
-	lastErr error
+	session   *Session
+	rrs       []rowsResult
+	rr        rowsResult
+	idx       int // current result set
+	pos       int
+	fetchSize int // statement fetch size (0: configured fetch size)
+	lastErr   error
+
+	prefetch int         // number of chunks fetched in advance (0: no prefetching)
+	pf       *prefetcher // active prefetcher (nil: none)
+
+	scrollable bool // scrollable result set (see Scroll)
+	isClosed   bool
 }
 
 func newQueryResultSet(session *Session, rrs ...rowsResult) *queryResultSet {
 	if len(rrs) == 0 {
 		panic("query result set is empty")
 	}
-	return &queryResultSet{session: session, rrs: rrs, rr: rrs[0]}
+	qrs := &queryResultSet{session: session, rrs: rrs, rr: rrs[0], fetchSize: session.stmtFetchSize}
+	if len(rrs) == 1 { // no prefetching of multiple result sets (procedure calls)
+		qrs.prefetch = session.cfg.Prefetch()
+	}
+	return qrs
+}
+
+// newScrollableQueryResultSet returns the result set of a query requesting a scrollable result set (scrollable == true)
+// or a forward only one. Scrollable result sets are not prefetched.
+func newScrollableQueryResultSet(session *Session, qr *queryResult, scrollable bool) *queryResultSet {
+	qrs := newQueryResultSet(session, qr)
+	if scrollable {
+		qrs.scrollable, qrs.prefetch = true, 0
+	}
+	return qrs
 }
 
 func (r *queryResultSet) Columns() []string {
@@ -68,10 +90,16 @@ func (r *queryResultSet) Columns() []string {
 }
 
 func (r *queryResultSet) Close() error {
+	if r.pf != nil { // before locking the session, as the prefetcher locks the session for each fetch
+		r.stopPrefetch()
+	}
+
 	r.session.Lock()
 	defer r.session.Unlock()
 	defer r.session.SetInQuery(false)
 
+	r.isClosed = true
+
 	// if lastError is set, attrs are nil
 	if r.lastErr != nil {
 		return r.lastErr
@@ -84,6 +112,10 @@ func (r *queryResultSet) Close() error {
 }
 
 func (r *queryResultSet) Next(dest []driver.Value) error {
+	if r.prefetch > 0 {
+		return r.nextPrefetched(dest)
+	}
+
 	r.session.Lock()
 	defer r.session.Unlock()
 
@@ -95,7 +127,7 @@ func (r *queryResultSet) Next(dest []driver.Value) error {
 		if r.rr.lastPacket() {
 			return io.EOF
 		}
-		if err := r.session.fetchNext(r.rr); err != nil {
+		if err := r.session.fetchNext(r.rr, r.fetchSize); err != nil {
 			r.lastErr = err //fieldValues and attrs are nil
 			return err
 		}
@@ -105,6 +137,11 @@ func (r *queryResultSet) Next(dest []driver.Value) error {
 		r.pos = 0
 	}
 
+	r.copyRow(dest)
+	return nil
+}
+
+func (r *queryResultSet) copyRow(dest []driver.Value) {
 	r.rr.copyRow(r.pos, dest)
 	r.pos++
 
@@ -114,9 +151,62 @@ func (r *queryResultSet) Next(dest []driver.Value) error {
 			v.setSession(r.session)
 		}
 	}
+}
+
+/*
+nextPrefetched implements Next with prefetching: the next chunks are fetched by a prefetcher while the rows of the
+current chunk are copied. As the rows of the current chunk are owned by the result set, they are copied without
+locking the session, so that the copying does not wait for a running fetch.
+*/
+func (r *queryResultSet) nextPrefetched(dest []driver.Value) error {
+	if r.lastErr != nil {
+		return r.lastErr
+	}
+	qr, err := r.rr.queryResult()
+	if err != nil {
+		return err
+	}
+
+	if r.pos >= qr.numRow() {
+		if qr.lastPacket() {
+			return io.EOF
+		}
+		if r.pf == nil {
+			r.pf = newPrefetcher(r.session, qr, r.fetchSize, r.prefetch)
+		}
+		chunk := <-r.pf.chunks
+		if chunk.err != nil {
+			r.lastErr = chunk.err
+			return chunk.err
+		}
+		qr.setChunk(chunk.fieldValues, chunk.attributes)
+		if qr.numRow() == 0 {
+			return io.EOF
+		}
+		r.pos = 0
+	}
+	if r.pf == nil && !qr.lastPacket() { // start prefetching while the rows of the first chunk are copied
+		r.pf = newPrefetcher(r.session, qr, r.fetchSize, r.prefetch)
+	}
+
+	r.copyRow(dest)
 	return nil
 }
 
+// stopPrefetch stops the prefetcher and applies the chunks fetched in advance, so that the result set state
+// (e.g. result set closed by the server) reflects all fetches.
+func (r *queryResultSet) stopPrefetch() {
+	qr, _ := r.rr.queryResult()
+	r.pf.stop(func(chunk prefetchChunk) {
+		if chunk.err != nil {
+			r.lastErr = chunk.err
+			return
+		}
+		qr.setChunk(chunk.fieldValues, chunk.attributes)
+	})
+	r.pf = nil
+}
+
 func (r *queryResultSet) HasNextResultSet() bool {
 	return (r.idx + 1) < len(r.rrs)
 }
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// raw prolog sizes
+const (
+	initRequestSize = 14
+	initReplySize   = 8
+)
+
+// offset of the varPartLength field in the message header
+const varPartLengthOffset = 12
+
+// ReadRawProlog reads the protocol prolog without decoding it: the init request,
+// if upStream is true (client to database), the init reply otherwise.
+func ReadRawProlog(rd io.Reader, upStream bool) ([]byte, error) {
+	size := initReplySize
+	if upStream {
+		size = initRequestSize
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(rd, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ReadRawMessage reads a complete protocol message (message header and variable part) without decoding it.
+func ReadRawMessage(rd io.Reader) ([]byte, error) {
+	header := make([]byte, messageHeaderSize)
+	if _, err := io.ReadFull(rd, header); err != nil {
+		return nil, err
+	}
+	varPartLength := binary.LittleEndian.Uint32(header[varPartLengthOffset:])
+	b := make([]byte, messageHeaderSize+int(varPartLength))
+	copy(b, header)
+	if _, err := io.ReadFull(rd, b[messageHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
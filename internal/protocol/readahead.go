@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"io"
+)
+
+const (
+	readAheadChunkSize = 1 << 16                // size of read ahead buffers
+	readAheadThreshold = 2 * readAheadChunkSize // minimal message size to be read ahead
+)
+
+type readAheadChunk struct {
+	b   []byte
+	err error
+}
+
+/*
+readAhead reads the bytes of a message from an underlying reader in a separate goroutine
+using two buffers (double buffering), so that network reads overlap with the decoding of
+the message data already read.
+
+The goroutine reads exactly the number of message bytes and terminates afterwards. Therefore,
+the underlying reader is never read beyond the message end and no goroutine is left running
+between request / reply round trips.
+*/
+type readAhead struct {
+	bufs [2][]byte
+	full chan readAheadChunk
+	free chan []byte
+	cur  []byte // unread bytes of current chunk
+	buf  []byte // current chunk buffer
+	err  error
+
+	active bool
+}
+
+func newReadAhead() *readAhead {
+	return &readAhead{bufs: [2][]byte{make([]byte, readAheadChunkSize), make([]byte, readAheadChunkSize)}}
+}
+
+// start starts reading size bytes from rd.
+func (r *readAhead) start(rd io.Reader, size int) {
+	r.full = make(chan readAheadChunk, len(r.bufs))
+	r.free = make(chan []byte, len(r.bufs))
+	for _, b := range r.bufs {
+		r.free <- b
+	}
+	r.cur, r.buf, r.err = nil, nil, nil
+	r.active = true
+	go r.read(rd, size, r.full, r.free)
+}
+
+func (r *readAhead) read(rd io.Reader, size int, full chan<- readAheadChunk, free <-chan []byte) {
+	defer close(full)
+	for size > 0 {
+		b := <-free
+		if size < len(b) {
+			b = b[:size]
+		}
+		n, err := io.ReadFull(rd, b)
+		size -= n
+		full <- readAheadChunk{b: b[:n], err: err}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read implements the io.Reader interface.
+func (r *readAhead) Read(p []byte) (int, error) {
+	for len(r.cur) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.buf != nil {
+			r.free <- r.buf
+			r.buf = nil
+		}
+		c, ok := <-r.full
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		r.buf, r.cur, r.err = c.b, c.b, c.err
+	}
+	n := copy(p, r.cur)
+	r.cur = r.cur[n:]
+	return n, nil
+}
+
+// stop discards the unread message bytes and waits until the read goroutine is terminated.
+func (r *readAhead) stop() {
+	if !r.active {
+		return
+	}
+	r.active = false
+	if r.buf != nil {
+		r.free <- r.buf
+		r.buf = nil
+	}
+	for c := range r.full {
+		r.free <- c.b
+	}
+	r.cur = nil
+}
@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/container/varmap"
+)
+
+func testReadAheadRead(t *testing.T) {
+	const size = 3*readAheadChunkSize + 100
+
+	data := make([]byte, size+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	rd := bytes.NewReader(data)
+
+	r := newReadAhead()
+	r.start(rd, size)
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.stop()
+	if !bytes.Equal(b, data[:size]) {
+		t.Fatal("read bytes differ from expected bytes")
+	}
+	if rd.Len() != 10 { // no read beyond size
+		t.Fatalf("unread bytes %d - expected %d", rd.Len(), 10)
+	}
+}
+
+func testReadAheadStop(t *testing.T) {
+	const size = 3 * readAheadChunkSize
+
+	rd := bytes.NewReader(make([]byte, size))
+
+	r := newReadAhead()
+	r.start(rd, size)
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	r.stop() // discard remaining bytes
+	if rd.Len() != 0 {
+		t.Fatalf("unread bytes %d - expected %d", rd.Len(), 0)
+	}
+
+	// reuse
+	rd.Reset(make([]byte, 100))
+	r.start(rd, 100)
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.stop()
+	if len(b) != 100 {
+		t.Fatalf("read bytes %d - expected %d", len(b), 100)
+	}
+}
+
+type testErrorReader struct{ err error }
+
+func (r testErrorReader) Read(p []byte) (int, error) { return 0, r.err }
+
+func testReadAheadError(t *testing.T) {
+	testErr := errors.New("test error")
+
+	r := newReadAhead()
+	r.start(io.MultiReader(bytes.NewReader(make([]byte, 10)), testErrorReader{err: testErr}), readAheadChunkSize)
+	b, err := ioutil.ReadAll(r)
+	r.stop()
+	if err != testErr {
+		t.Fatalf("error %v - expected %v", err, testErr)
+	}
+	if len(b) != 10 {
+		t.Fatalf("read bytes %d - expected %d", len(b), 10)
+	}
+}
+
+func testReadAheadMessage(t *testing.T) {
+	var buf bytes.Buffer
+
+	ts := newTraceState(false, nil)
+	pw := newProtocolWriter(newBufferedWriter(&buf, 0, false), varmap.NewVarMap(), ts.traceLogger(true))
+
+	query := command("select * from dummy where x = '" + strings.Repeat("go-hdb ", readAheadThreshold/4) + "'")
+	for i := 0; i < 2; i++ {
+		if err := pw.write(1, mtExecuteDirect, false, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pr := newProtocolReader(true, &buf, ts.traceLogger(false))
+	pr.readAheadOn = true
+	for i := 0; i < 2; i++ {
+		var cmd command
+		if err := pr.iterateParts(func(ph *partHeader) {
+			if ph.partKind == pkCommand {
+				pr.read(&cmd)
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(cmd, query) {
+			t.Fatal("command differs from expected command")
+		}
+	}
+	if pr.readAhead == nil {
+		t.Fatal("message was not read ahead")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unread bytes %d - expected %d", buf.Len(), 0)
+	}
+}
+
+func TestReadAhead(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"read", testReadAheadRead},
+		{"stop", testReadAheadStop},
+		{"error", testReadAheadError},
+		{"message", testReadAheadMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
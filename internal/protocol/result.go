@@ -79,7 +79,7 @@ func (f *resultField) String() string {
 	)
 }
 
-func (f *resultField) Converter() Converter { return f.tc.fieldType() }
+func (f *resultField) Converter() Converter { return f.tc.scaledFieldType(int(f.fraction)) }
 
 // TypeName returns the type name of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeDatabaseTypeName
@@ -91,25 +91,18 @@ func (f *resultField) ScanType() DataType { return f.tc.dataType() }
 
 // TypeLength returns the type length of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeLength
-func (f *resultField) TypeLength() (int64, bool) {
-	if f.tc.isVariableLength() {
-		return int64(f.length), true
-	}
-	return 0, false
-}
+func (f *resultField) TypeLength() (int64, bool) { return f.tc.typeLength(f.length) }
 
 // TypePrecisionScale returns the type precision and scale (decimal types) of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypePrecisionScale
 func (f *resultField) TypePrecisionScale() (int64, int64, bool) {
-	if f.tc.isDecimalType() {
-		return int64(f.length), int64(f.fraction), true
-	}
-	return 0, 0, false
+	return f.tc.typePrecisionScale(f.length, f.fraction)
 }
 
 // Nullable returns true if the field may be null, false otherwise.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeNullable
-func (f *resultField) Nullable() bool { return f.columnOptions == coOptional }
+// The column options are a bit set, so that further options (e.g. of ALPHANUM and SHORTTEXT columns) need to be masked.
+func (f *resultField) Nullable() bool { return f.columnOptions&coOptional != 0 }
 
 // Name returns the result field name.
 func (f *resultField) Name() string { return f.columnDisplayName }
@@ -183,7 +176,7 @@ func (r *resultset) decode(dec *encoding.Decoder, ph *partHeader) error {
 	for i := 0; i < numArg; i++ {
 		for j, field := range r.resultFields {
 			var err error
-			if r.fieldValues[i*cols+j], err = decodeRes(dec, field.tc); err != nil {
+			if r.fieldValues[i*cols+j], err = decodeRes(dec, field.tc, int(field.fraction)); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,64 @@
+// +build go1.10
+
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"strings"
+)
+
+// Normalize returns a normalized representation of the SQL statement s, where
+// - literals (strings and numbers) are replaced by the question mark placeholder,
+// - unquoted identifiers are converted to lower case and
+// - whitespaces are collapsed.
+// Statements differing only in literal values, case or formatting are normalized to the same string.
+func (sc *Scanner) Normalize(s string) string {
+	sc.Reset(s)
+
+	b := new(strings.Builder)
+	b.Grow(len(s))
+
+	noSpace := true // no space before next token
+	for {
+		token, start, end := sc.Next()
+		if token == EOS {
+			break
+		}
+
+		value := s[start:end]
+
+		switch token {
+		case Identifier:
+			value = strings.ToLower(value)
+		case String, Number:
+			value = "?"
+		}
+
+		switch token {
+		case IdentifierDelimiter:
+			noSpace = true
+		case Delimiter:
+			if value != "(" {
+				noSpace = true
+			}
+		}
+
+		if !noSpace {
+			b.WriteByte(' ')
+		}
+		b.WriteString(value)
+
+		switch token {
+		case IdentifierDelimiter:
+			noSpace = true
+		case Delimiter:
+			noSpace = value == "("
+		default:
+			noSpace = false
+		}
+	}
+	return b.String()
+}
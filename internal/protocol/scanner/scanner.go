@@ -119,19 +119,63 @@ func (sc *Scanner) unreadRune() {
 	sc.prev = -1
 }
 
+// scanWhitespace skips whitespaces and comments.
 func (sc *Scanner) scanWhitespace() {
 	for {
 		ch, ok := sc.readRune()
 		if !ok {
 			return
 		}
-		if !unicode.IsSpace(ch) {
-			sc.unreadRune()
+		if unicode.IsSpace(ch) {
+			continue
+		}
+		sc.unreadRune()
+		if !sc.scanComment() {
 			return
 		}
 	}
 }
 
+// scanComment skips a line comment (-- comment) or a block comment (/* comment */)
+// and returns false if the scanner is not positioned at a comment.
+// Block comments might be nested, an unterminated comment extends to the end of the statement.
+func (sc *Scanner) scanComment() bool {
+	rest := sc.s[sc.i:]
+	switch {
+	case strings.HasPrefix(rest, "--"):
+		if i := strings.IndexByte(rest, '\n'); i != -1 {
+			sc.i += i + 1
+		} else {
+			sc.i = len(sc.s)
+		}
+	case strings.HasPrefix(rest, "/*"):
+		sc.i += 2 + blockCommentLen(rest[2:])
+	default:
+		return false
+	}
+	return true
+}
+
+// blockCommentLen returns the length of the block comment body s including the closing
+// comment delimiter, taking nested block comments into account.
+func blockCommentLen(s string) int {
+	depth := 1
+	for i := 0; i < len(s)-1; i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*':
+			depth++
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
 func (sc *Scanner) scanOperator(ch rune) {
 	ch2, ok := sc.readRune()
 	if !ok {
@@ -169,7 +213,9 @@ func (sc *Scanner) scanAlpha() {
 	}
 }
 
-func (sc *Scanner) scanQuotedIdentifier(quote rune) Token {
+// scanQuoted scans a string literal or a quoted identifier. A quote character
+// inside the literal or identifier is escaped by doubling it.
+func (sc *Scanner) scanQuoted(quote rune, token Token) Token {
 	for {
 		ch, ok := sc.readRune()
 		if !ok {
@@ -178,11 +224,11 @@ func (sc *Scanner) scanQuotedIdentifier(quote rune) Token {
 		if ch == quote {
 			ch, ok := sc.readRune()
 			if !ok {
-				return QuotedIdentifier
+				return token
 			}
 			if ch != quote {
 				sc.unreadRune()
-				return QuotedIdentifier
+				return token
 			}
 		}
 	}
@@ -209,18 +255,20 @@ func (sc *Scanner) scanNumber() Token {
 	}
 	if isDecimalSeparator(ch) {
 		sc.scanNumeric()
+		ch, ok = sc.readRune()
+		if !ok {
+			return Number
+		}
 	}
-	ch, ok = sc.readRune()
-	if !ok {
+	if !isExp(ch) {
+		sc.unreadRune()
 		return Number
 	}
-	if isExp(ch) {
-		if isNumber(ch) {
-			sc.scanNumeric()
-			return Number
-		}
+	ch, ok = sc.readRune()
+	if !ok || !isNumber(ch) {
 		return Error
 	}
+	sc.scanNumeric()
 	return Number
 }
 
@@ -254,8 +302,11 @@ func (sc *Scanner) Next() (Token, int, int) {
 		sc.scanAlpha()
 		return Identifier, start, sc.i
 
-	case isSingleQuote(ch) || isDoubleQuote(ch):
-		token := sc.scanQuotedIdentifier(ch)
+	case isSingleQuote(ch):
+		token := sc.scanQuoted(ch, String)
+		return token, start, sc.i
+	case isDoubleQuote(ch):
+		token := sc.scanQuoted(ch, QuotedIdentifier)
 		return token, start, sc.i
 
 	case isColon(ch):
@@ -263,7 +314,7 @@ func (sc *Scanner) Next() (Token, int, int) {
 		return token, start, sc.i
 
 	case isNumber(ch):
-		sc.scanNumber()
-		return Number, start, sc.i
+		token := sc.scanNumber()
+		return token, start, sc.i
 	}
 }
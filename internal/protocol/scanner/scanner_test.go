@@ -113,6 +113,40 @@ var testData = []struct {
 			{Error, `" >= :start;`},
 		},
 	},
+	{
+		`select 'it''s ?', "a?" from /* ? /* nested ? */ ? */ t -- ?
+		where c = ? and d = ':e'`,
+		[]tokenValue{
+			{Identifier, "select"},
+			{String, `'it''s ?'`},
+			{Delimiter, ","},
+			{QuotedIdentifier, `"a?"`},
+			{Identifier, "from"},
+			{Identifier, "t"},
+			{Identifier, "where"},
+			{Identifier, "c"},
+			{Operator, "="},
+			{Variable, "?"},
+			{Identifier, "and"},
+			{Identifier, "d"},
+			{Operator, "="},
+			{String, `':e'`},
+		},
+	},
+	{
+		`select 'abc from dummy`,
+		[]tokenValue{
+			{Identifier, "select"},
+			{Error, `'abc from dummy`},
+		},
+	},
+	{
+		`select 1 /* unterminated /* nested */ comment`,
+		[]tokenValue{
+			{Identifier, "select"},
+			{Number, "1"},
+		},
+	},
 	{
 		// call table result query
 		`rsid 1234567890`,
@@ -157,12 +191,41 @@ func testScannerX(t *testing.T) {
 	}
 }
 
+func testNormalize(t *testing.T) {
+	testData := []struct {
+		s string
+		r string
+	}{
+		{``, ``},
+		{`select * from dummy`, `select * from dummy`},
+		{
+			`SELECT a,b FROM "Schema".T  WHERE  c = 'abc' and d in (1, 2.5, -3e2)`,
+			`select a, b from "Schema".t where c = ? and d in (?, ?, ?)`,
+		},
+		{
+			`select a, b from "Schema".t where c = 'x''y' and d in (7, 8, 9)`,
+			`select a, b from "Schema".t where c = ? and d in (?, ?, ?)`,
+		},
+		{`insert into t values (?, :1, :name)`, `insert into t values (?, :1, :name)`},
+		{`select /* a /* nested */ comment */ 'x?' from t where c = ?`, `select ? from t where c = ?`},
+	}
+
+	scanner := Scanner{}
+
+	for i, d := range testData {
+		if r := scanner.Normalize(d.s); r != d.r {
+			t.Fatalf("test %d: normalized %q - expected %q", i, r, d.r)
+		}
+	}
+}
+
 func TestScanner(t *testing.T) {
 	tests := []struct {
 		name string
 		fct  func(t *testing.T)
 	}{
 		{"scannerX", testScannerX},
+		{"normalize", testNormalize},
 	}
 
 	for _, test := range tests {
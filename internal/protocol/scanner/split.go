@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split splits the sql script s into its statements separated by semicolons.
+// Semicolons inside of string literals, quoted identifiers, comments and BEGIN ... END blocks
+// (procedures, functions and anonymous blocks) do not separate statements.
+// Empty statements are omitted and comments before and after a statement are not part of the statement.
+func (sc *Scanner) Split(s string) ([]string, error) {
+	sc.Reset(s)
+
+	var stmts []string
+	depth := 0          // BEGIN / CASE ... END nesting depth
+	pendingEnd := false // END token read
+	start, end := -1, -1
+
+	add := func() {
+		if start != -1 {
+			stmts = append(stmts, s[start:end])
+		}
+		start, end = -1, -1
+	}
+
+	for {
+		token, tStart, tEnd := sc.Next()
+		if token == EOS {
+			break
+		}
+		if token == Error && (isSingleQuote(rune(s[tStart])) || isDoubleQuote(rune(s[tStart]))) { // unterminated literal
+			return nil, fmt.Errorf("%w at position %d: %s", ErrToken, tStart, s[tStart:tEnd])
+		}
+
+		if pendingEnd {
+			pendingEnd = false
+			if token == Identifier {
+				switch strings.ToUpper(s[tStart:tEnd]) {
+				case "IF", "FOR", "WHILE", "LOOP": // end of control statement without BEGIN
+					end = tEnd
+					continue
+				case "CASE": // end of case statement
+					depth--
+					end = tEnd
+					continue
+				}
+			}
+			depth--
+		}
+
+		if token == Delimiter && s[tStart:tEnd] == ";" && depth == 0 {
+			add()
+			continue
+		}
+
+		if token == Identifier {
+			switch strings.ToUpper(s[tStart:tEnd]) {
+			case "BEGIN", "CASE":
+				depth++
+			case "END":
+				pendingEnd = depth > 0
+			}
+		}
+
+		if start == -1 {
+			start = tStart
+		}
+		end = tEnd
+	}
+	add()
+	return stmts, nil
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		s     string
+		stmts []string
+	}{
+		{"", nil},
+		{" ; ;", nil},
+		{"select * from dummy", []string{"select * from dummy"}},
+		{
+			"create table t (a integer);\ninsert into t values (1);\n",
+			[]string{"create table t (a integer)", "insert into t values (1)"},
+		},
+		{
+			"insert into t values ('a;b'); insert into \"t;1\" values (2)",
+			[]string{"insert into t values ('a;b')", "insert into \"t;1\" values (2)"},
+		},
+		{
+			"-- comment; with semicolon\ncreate table t (a integer); /* block; comment */ drop table t -- trailing",
+			[]string{"create table t (a integer)", "drop table t"},
+		},
+		{
+			"/* nested /* block; */ comment; */ select 'it''s; ok' from dummy; select 1 from dummy",
+			[]string{"select 'it''s; ok' from dummy", "select 1 from dummy"},
+		},
+		{
+			"create procedure p as begin\n  declare i integer;\n  if 1 = 1 then\n    select 1 from dummy;\n  end if;\n  select case when 1 = 1 then 1 else 0 end from dummy;\nend;\ncall p",
+			[]string{
+				"create procedure p as begin\n  declare i integer;\n  if 1 = 1 then\n    select 1 from dummy;\n  end if;\n  select case when 1 = 1 then 1 else 0 end from dummy;\nend",
+				"call p",
+			},
+		},
+		{
+			"do begin\n  for i in 1..2 do\n    begin\n      select 1 from dummy;\n    end;\n  end for;\nend; select 2 from dummy",
+			[]string{
+				"do begin\n  for i in 1..2 do\n    begin\n      select 1 from dummy;\n    end;\n  end for;\nend",
+				"select 2 from dummy",
+			},
+		},
+	}
+
+	sc := &Scanner{}
+	for i, test := range tests {
+		stmts, err := sc.Split(test.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(stmts, test.stmts) {
+			t.Fatalf("test %d: statements %q - expected %q", i, stmts, test.stmts)
+		}
+	}
+
+	if _, err := sc.Split("select 'a from dummy"); !errors.Is(err, ErrToken) {
+		t.Fatalf("error %v - expected %v", err, ErrToken)
+	}
+}
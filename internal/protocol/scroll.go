@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ScrollMode is the mode of positioning a scrollable result set (see ScrollableRows).
+type ScrollMode int
+
+// Scroll modes.
+const (
+	ScrollAbsolute ScrollMode = iota // row n (1: first row, -1: last row)
+	ScrollRelative                   // n rows relative to the row returned next (0: row returned next)
+	ScrollFirst                      // first row
+	ScrollLast                       // last row
+)
+
+// Scroll errors.
+var (
+	ErrNotScrollable   = errors.New("result set is not scrollable")
+	ErrScrollClosed    = errors.New("scroll on closed result set")
+	ErrInvalidPosition = errors.New("invalid scroll position")
+)
+
+// ScrollableRows is implemented by the query result sets supporting positioning (scrollable result sets).
+type ScrollableRows interface {
+	driver.Rows
+	// Scrollable returns true if the result set was requested as scrollable result set.
+	Scrollable() bool
+	// Scroll positions the result set, so that the next call of Next returns the row given by mode and n.
+	Scroll(mode ScrollMode, n int) error
+}
+
+// Scrollable implements the ScrollableRows interface.
+func (r *queryResultSet) Scrollable() bool { return r.scrollable }
+
+/*
+Scroll implements the ScrollableRows interface.
+
+The current chunk is replaced by the chunk starting at the new position. A position beyond the last row
+positions the result set after the last row (Next returns io.EOF), a position before the first row (relative
+scroll) positions the result set at the first row.
+*/
+func (r *queryResultSet) Scroll(mode ScrollMode, n int) error {
+	r.session.Lock()
+	defer r.session.Unlock()
+
+	if !r.scrollable {
+		return ErrNotScrollable
+	}
+	if r.isClosed {
+		return ErrScrollClosed
+	}
+	if r.session.IsBad() {
+		return driver.ErrBadConn
+	}
+	if r.lastErr != nil {
+		return r.lastErr
+	}
+	qr, err := r.rr.queryResult()
+	if err != nil {
+		return err
+	}
+
+	var mt messageType
+	var pos int
+	switch mode {
+	case ScrollAbsolute:
+		if n == 0 {
+			return fmt.Errorf("%w: absolute position 0", ErrInvalidPosition)
+		}
+		mt, pos = mtFetchAbsolute, n
+	case ScrollRelative:
+		// the server positions relative to the last row fetched:
+		// skip the rows of the current chunk not returned yet
+		mt, pos = mtFetchRelative, n-(qr.numRow()-r.pos)+1
+	case ScrollFirst:
+		mt = mtFetchFirst
+	case ScrollLast:
+		mt = mtFetchLast
+	default:
+		return fmt.Errorf("invalid scroll mode %d", mode)
+	}
+	if pos < math.MinInt32 || pos > math.MaxInt32 {
+		return fmt.Errorf("%w: %d", ErrInvalidPosition, n)
+	}
+
+	if err := r.session.fetchScrolled(qr, mt, pos, r.fetchSize); err != nil {
+		r.lastErr = err //fieldValues and attrs are nil
+		return err
+	}
+	r.pos = 0
+	return nil
+}
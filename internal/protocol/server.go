@@ -0,0 +1,1279 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// +build unit
+
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/SAP/go-hdb/internal/compress/lz4"
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+	"github.com/SAP/go-hdb/internal/protocol/scanner"
+	"github.com/SAP/go-hdb/internal/unicode/cesu8"
+)
+
+// serverVersion is the database version reported by a ServerSession.
+const serverVersion = "2.00.048.00.1591276203"
+
+// serverDatabaseName and serverSystemID are the database name and the system id reported by a ServerSession.
+const (
+	serverDatabaseName = "MOCK"
+	serverSystemID     = "MCK"
+)
+
+// serverFetchSize is the number of rows returned by a ServerSession with the first result set chunk.
+const serverFetchSize = 32
+
+// serverLobDataSize is the number of bytes of a result lob returned by a ServerSession with the lob descriptor.
+const serverLobDataSize = 1024
+
+// serverCompressionThreshold is the minimal size of replies compressed by a ServerSession if the client requested compression.
+const serverCompressionThreshold = 1024
+
+// ServerErrorCodeQueryTimeout is the error code returned by a ServerSession for statement executions exceeding
+// the query timeout set by the client.
+const ServerErrorCodeQueryTimeout = errCodeQueryTimeout
+
+// ServerErrorCodeCanceled is the error code of statement executions canceled by a cancel request.
+const ServerErrorCodeCanceled = errCodeCanceled
+
+// ServerErrorCodeInvalidStatementID is the error code returned by a ServerSession for executions of
+// statement ids which are not known to the session (e.g. after InvalidateStatements).
+const ServerErrorCodeInvalidStatementID = ErrCodeInvalidStatementID
+
+// ServerErrorCodePasswordChangeRequired is the error code returned by a ServerSession for connects and
+// statements of users forced to change the password (see ServerPasswordChangeHandler).
+const ServerErrorCodePasswordChangeRequired = errCodePasswordChangeRequired
+
+// ErrServerDisconnect can be returned by a ServerHandler to close the client connection.
+var ErrServerDisconnect = errors.New("server disconnect")
+
+// ServerError is a database error returned by a ServerHandler.
+type ServerError struct {
+	Code     int
+	Position int    // start position of the erroneous statement part
+	SQLState string // defaults to HY000
+	Text     string
+}
+
+func (e *ServerError) Error() string { return fmt.Sprintf("SQL Error %d - %s", e.Code, e.Text) }
+
+// ServerColumn is a result set column of a ServerStmt.
+type ServerColumn struct {
+	Name     string
+	TypeName string // database type name (e.g. INTEGER, NVARCHAR)
+}
+
+// ServerStmt describes a statement prepared by a ServerHandler.
+type ServerStmt struct {
+	Params   []string       // parameter database type names
+	Columns  []ServerColumn // result set columns (queries only)
+	VolumeID int            // volume id of the table location returned to the client (0: none)
+}
+
+// ServerResult is the result of a statement execution by a ServerHandler.
+type ServerResult struct {
+	Rows         [][]interface{} // result set rows (queries only)
+	RowsAffected int64           // number of affected rows (non queries only)
+	// RowErrors are the errors of rows rejected by an array execution (row index: error). The not rejected rows
+	// are reported to affect one row each.
+	RowErrors map[int]*ServerError
+}
+
+/*
+ServerHandler is the interface implemented by types handling the requests of a ServerSession.
+
+Errors returned by Prepare and Execute are sent to the client as database errors (see ServerError),
+with exception of ErrServerDisconnect, which closes the client connection.
+*/
+type ServerHandler interface {
+	// Password returns the password of user username or false if the user is unknown.
+	// An empty password disables the password verification.
+	Password(username string) (string, bool)
+	Prepare(query string) (*ServerStmt, error)
+	Execute(query string, args []interface{}) (*ServerResult, error)
+}
+
+// ServerCommandInfoHandler is an optional interface of a ServerHandler receiving the command info
+// (application source location) sent by the client with a statement.
+type ServerCommandInfoHandler interface {
+	CommandInfo(sourceModule string, lineNumber int)
+}
+
+// ServerClientInfoHandler is an optional interface of a ServerHandler receiving the client info
+// (session variables) sent by the client with a statement.
+type ServerClientInfoHandler interface {
+	ClientInfo(m map[string]string)
+}
+
+// ServerGSSHandler is an optional interface of a ServerHandler supporting the GSS (Kerberos) authentication.
+// AcceptSecContext returns the database user and the reply token of the client token or an error,
+// if the client cannot be authenticated.
+type ServerGSSHandler interface {
+	AcceptSecContext(token []byte) (username string, replyToken []byte, err error)
+}
+
+// ServerX509Handler is an optional interface of a ServerHandler supporting the X509 client certificate authentication.
+// AuthenticateX509 returns the database user of the verified client certificate chain (client certificate first)
+// or an error, if the certificate is not accepted.
+type ServerX509Handler interface {
+	AuthenticateX509(certs []*x509.Certificate) (username string, err error)
+}
+
+// ServerTokenHandler is an optional interface of a ServerHandler supporting the token authentication.
+// AuthenticateToken returns the database user of the token of authentication method JWT or SAML
+// or an error, if the token is not accepted.
+type ServerTokenHandler interface {
+	AuthenticateToken(method, token string) (username string, err error)
+}
+
+// ServerPasswordChangeHandler is an optional interface of a ServerHandler forcing users to change the password.
+// Sessions of users with PasswordChangeRequired true are connected restricted to the password change
+// (ALTER USER <user> PASSWORD <password>) and ChangePassword is called with the new password.
+type ServerPasswordChangeHandler interface {
+	PasswordChangeRequired(username string) bool
+	ChangePassword(username, password string) error
+}
+
+// serverTypeCodes maps the database type names supported by a ServerSession to type codes.
+var serverTypeCodes = map[string]typeCode{
+	"BOOLEAN":   tcBoolean,
+	"TINYINT":   tcTinyint,
+	"SMALLINT":  tcSmallint,
+	"INTEGER":   tcInteger,
+	"BIGINT":    tcBigint,
+	"DOUBLE":    tcDouble,
+	"NVARCHAR":  tcNvarchar,
+	"VARBINARY": tcVarbinary,
+	"TIMESTAMP": tcLongdate,
+	"BLOB":      tcBlob,  // input parameters and result columns
+	"NCLOB":     tcNclob, // input parameters only
+}
+
+func serverTypeCode(typeName string) (typeCode, error) {
+	tc, ok := serverTypeCodes[strings.ToUpper(typeName)]
+	if !ok {
+		return tcNullL, fmt.Errorf("type %s is not supported", typeName)
+	}
+	return tc, nil
+}
+
+type serverStmt struct {
+	query     string
+	call      bool // procedure call
+	prmFields []*parameterField
+	resFields []*resultField
+	volumeID  int
+}
+
+// hasLobs returns true if the statement has lob parameters.
+func (stmt *serverStmt) hasLobs() bool {
+	for _, f := range stmt.prmFields {
+		if f.tc.isLob() {
+			return true
+		}
+	}
+	return false
+}
+
+// serverLobWrite is a statement execution waiting for the content of its lob parameters.
+type serverLobWrite struct {
+	stmt    *serverStmt
+	args    []interface{}
+	ids     []locatorID       // ids of the lob parameters not written completely
+	idx     map[locatorID]int // argument index of lob parameter
+	bufs    map[locatorID]*bytes.Buffer
+	timeout time.Duration
+}
+
+// serverResultset is a result set of the server session. The rows of scrollable result sets
+// are kept until the result set is closed by the client.
+type serverResultset struct {
+	fields     []*resultField
+	rows       [][]interface{}
+	next       int // index of the row after the last row fetched
+	scrollable bool
+}
+
+// serverPart is an encoded reply part.
+type serverPart struct {
+	pk    partKind
+	attrs partAttributes
+	n     int
+	b     []byte
+}
+
+/*
+ServerSession implements the server side of a hdb protocol connection supporting a subset of
+the protocol (handshake, SCRAMSHA256, GSS, X509, JWT and SAML authentication, direct execution, prepare, execute and fetch).
+It is intended to be used for testing (see driver/drivertest).
+*/
+type ServerSession struct {
+	conn      net.Conn
+	sessionID int64
+	version   string // database version reported to the client
+	maxDfv    int    // maximal data format version supported (0: any version requested by the client)
+	topology  []TopologyHost
+
+	wr  *bufio.Writer
+	enc *encoding.Encoder
+	pr  *protocolReader
+
+	buf  bytes.Buffer // part encoding buffer
+	benc *encoding.Encoder
+
+	packetCount int32
+
+	passwordChangeUser string // user of a session restricted to the password change ("": not restricted)
+
+	compress bool         // replies exceeding serverCompressionThreshold are compressed
+	msgBuf   bytes.Buffer // uncompressed reply buffer
+	msgEnc   *encoding.Encoder
+	compBuf  []byte // compressed reply buffer
+
+	stmtID      uint64
+	stmts       map[uint64]*serverStmt
+	invalidated int32 // atomic - prepared statements are invalidated with the next request
+	rsID        uint64
+	results     map[uint64]*serverResultset
+	lobID       uint64
+	lobWrite    *serverLobWrite      // execution waiting for lob content (nil: none)
+	lobs        map[locatorID][]byte // content of result lobs by locator id
+	lobLimit    int64                // read lob replies end the lob content after lobLimit bytes (0: no limit)
+}
+
+// NewServerSession returns a new server session for connection conn.
+func NewServerSession(conn net.Conn, sessionID int64) *ServerSession {
+	s := &ServerSession{
+		conn:      conn,
+		sessionID: sessionID,
+		version:   serverVersion,
+		wr:        bufio.NewWriter(conn),
+		stmts:     map[uint64]*serverStmt{},
+		results:   map[uint64]*serverResultset{},
+		lobs:      map[locatorID][]byte{},
+	}
+	s.enc = encoding.NewEncoder(s.wr)
+	s.benc = encoding.NewEncoder(&s.buf)
+	s.msgEnc = encoding.NewEncoder(&s.msgBuf)
+	s.pr = newProtocolReader(true, bufio.NewReader(conn), newTraceState(false, nil).traceLogger(true))
+	return s
+}
+
+// SetVersion sets the database version reported to the client (e.g. 2.00.048.00.1591276203).
+func (s *ServerSession) SetVersion(version string) { s.version = version }
+
+// SetMaxDfv sets the maximal data format version negotiated with the client (0: any version requested by the client).
+func (s *ServerSession) SetMaxDfv(dfv int) { s.maxDfv = dfv }
+
+// SetTopology sets the database hosts of the topology information returned to the client at connect time
+// (nil: no topology information).
+func (s *ServerSession) SetTopology(hosts []TopologyHost) { s.topology = hosts }
+
+// SetLobLimit ends the lob content returned by read lob requests after limit bytes, like a lob shrinking
+// after the query reported its size (0: no limit).
+func (s *ServerSession) SetLobLimit(limit int64) { s.lobLimit = limit }
+
+// InvalidateStatements drops all prepared statements of the session with the next client request, like
+// a database server does e.g. after a failover. It is safe to be called concurrently to Serve.
+func (s *ServerSession) InvalidateStatements() { atomic.StoreInt32(&s.invalidated, 1) }
+
+// Serve handles the client requests until the client closes the connection.
+func (s *ServerSession) Serve(h ServerHandler) error {
+	defer s.conn.Close()
+
+	if err := s.handshake(h); err != nil {
+		return err
+	}
+	for {
+		err := s.serve(h)
+		switch {
+		case err == io.EOF || err == ErrServerDisconnect:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+func (s *ServerSession) handshake(h ServerHandler) error {
+	if err := s.pr.readProlog(); err != nil {
+		return err
+	}
+	rep := &initReply{}
+	rep.product.major = productVersionMajor
+	rep.product.minor = productVersionMinor
+	rep.protocol.major = protocolVersionMajor
+	rep.protocol.minor = protocolVersionMinor
+	if err := rep.encode(s.enc); err != nil {
+		return err
+	}
+	if err := s.wr.Flush(); err != nil {
+		return err
+	}
+
+	// authenticate
+	initReq := &authInitReq{}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		if ph.partKind == pkAuthentication {
+			s.pr.read(initReq)
+		}
+	}); err != nil {
+		return err
+	}
+	var clientChallenge, gssInit []byte
+	x509Init := false
+	var tokenMethod, token string
+	for _, m := range initReq.methods {
+		switch m.method {
+		case mnSCRAMSHA256:
+			clientChallenge = m.clientChallenge
+		case mnGSS:
+			gssInit = m.clientChallenge
+		case mnX509:
+			x509Init = true
+		case mnJWT, mnSAML:
+			tokenMethod, token = m.method, string(m.clientChallenge)
+		}
+	}
+	if gh, ok := h.(ServerGSSHandler); ok && gssInit != nil {
+		return s.authenticateGSS(gh, gssInit)
+	}
+	if xh, ok := h.(ServerX509Handler); ok && x509Init {
+		return s.authenticateX509(xh)
+	}
+	if th, ok := h.(ServerTokenHandler); ok && tokenMethod != "" {
+		return s.authenticateToken(th, tokenMethod, token)
+	}
+	if clientChallenge == nil {
+		return s.writeError(&ServerError{Code: 10, Text: "authentication failed: method not supported"})
+	}
+	salt, serverChallenge := make([]byte, saltSize), make([]byte, serverChallengeSize)
+	if err := s.writeReply(skReply, fcNil, s.part(pkAuthentication, 0, 1, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnSCRAMSHA256))
+		enc.Byte(byte(int16Size + 2 + len(salt) + len(serverChallenge))) // sub parameter length
+		enc.Int16(2)
+		authShortBytes.encode(enc, salt)
+		authShortBytes.encode(enc, serverChallenge)
+	})); err != nil {
+		return err
+	}
+
+	// connect
+	finalReq := &authFinalReq{}
+	co := connectOptions{}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		switch ph.partKind {
+		case pkAuthentication:
+			s.pr.read(finalReq)
+		case pkConnectOptions:
+			s.pr.read(&co)
+		}
+	}); err != nil {
+		return err
+	}
+	password, ok := h.Password(finalReq.username)
+	if ok && password != "" {
+		key := scramsha256Key([]byte(password), salt)
+		proof := clientProof(key, []byte(password), salt, serverChallenge, clientChallenge)
+		ok = hmac.Equal(proof, finalReq.prms.(*authClientProofReq).clientProof)
+	}
+	if !ok {
+		return s.writeError(&ServerError{Code: 10, Text: "authentication failed"})
+	}
+	if ph, ok := h.(ServerPasswordChangeHandler); ok && ph.PasswordChangeRequired(finalReq.username) {
+		s.passwordChangeUser = finalReq.username
+	}
+	return s.connect(co, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnSCRAMSHA256))
+		enc.Byte(0) // no server proof
+	})
+}
+
+// authenticateGSS authenticates the client by the GSS token exchange with the GSS handler gh.
+func (s *ServerSession) authenticateGSS(gh ServerGSSHandler, initData []byte) error {
+	initPrms := &authGSSPrms{}
+	dec := encoding.NewDecoder(bytes.NewReader(initData))
+	if err := initPrms.decode(dec, nil); err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	if err := dec.Error(); err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	username, replyToken, err := gh.AcceptSecContext(initPrms.token)
+	if err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	replyPrms := &authGSSPrms{oid: initPrms.oid, token: replyToken}
+	if err := s.writeReply(skReply, fcNil, s.part(pkAuthentication, 0, 1, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnGSS))
+		authBytes.encodeLen(enc, replyPrms.size()) // sub parameter length
+		replyPrms.encode(enc)
+	})); err != nil {
+		return err
+	}
+
+	// connect
+	finalReq := &authFinalReq{}
+	co := connectOptions{}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		switch ph.partKind {
+		case pkAuthentication:
+			s.pr.read(finalReq)
+		case pkConnectOptions:
+			s.pr.read(&co)
+		}
+	}); err != nil {
+		return err
+	}
+	if finalReq.method != mnGSS || (finalReq.username != "" && finalReq.username != username) {
+		return s.writeError(&ServerError{Code: 10, Text: "authentication failed"})
+	}
+	return s.connect(co, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnGSS))
+		enc.Byte(0) // no final token
+	})
+}
+
+// authenticateX509 authenticates the client by the signature of the server nonce and the mapping of the client
+// certificate to a database user by the X509 handler xh.
+func (s *ServerSession) authenticateX509(xh ServerX509Handler) error {
+	initRep := &authX509InitRep{serverNonce: make([]byte, x509ServerNonceSize)}
+	if _, err := rand.Read(initRep.serverNonce); err != nil {
+		return err
+	}
+	if err := s.writeReply(skReply, fcNil, s.part(pkAuthentication, 0, 1, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnX509))
+		authBytes.encodeLen(enc, initRep.size()) // sub parameter length
+		initRep.encode(enc)
+	})); err != nil {
+		return err
+	}
+
+	// connect
+	finalReq := &authFinalReq{}
+	co := connectOptions{}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		switch ph.partKind {
+		case pkAuthentication:
+			s.pr.read(finalReq)
+		case pkConnectOptions:
+			s.pr.read(&co)
+		}
+	}); err != nil {
+		return err
+	}
+	prms, ok := finalReq.prms.(*authX509FinalReq)
+	if !ok {
+		return s.writeError(&ServerError{Code: 10, Text: "authentication failed"})
+	}
+	certs := make([]*x509.Certificate, len(prms.certs))
+	for i, b := range prms.certs {
+		cert, err := x509.ParseCertificate(b)
+		if err != nil {
+			return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+		}
+		certs[i] = cert
+	}
+	if err := x509Verify(certs[0], x509Message(prms.certs, initRep.serverNonce), prms.signature); err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	username, err := xh.AuthenticateX509(certs)
+	if err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	finalRep := &authLogonNameRep{logonName: username}
+	return s.connect(co, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(mnX509))
+		authBytes.encodeLen(enc, finalRep.size()) // sub parameter length
+		finalRep.encode(enc)
+	})
+}
+
+// authenticateToken authenticates the client by the JWT or SAML token verified by the token handler th.
+func (s *ServerSession) authenticateToken(th ServerTokenHandler, method, token string) error {
+	username, err := th.AuthenticateToken(method, token)
+	if err != nil {
+		return s.writeError(&ServerError{Code: 10, Text: fmt.Sprintf("authentication failed: %s", err)})
+	}
+	initRep := &authLogonNameRep{logonName: username}
+	if err := s.writeReply(skReply, fcNil, s.part(pkAuthentication, 0, 1, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(method))
+		authBytes.encodeLen(enc, initRep.size()) // sub parameter length
+		initRep.encode(enc)
+	})); err != nil {
+		return err
+	}
+
+	// connect
+	finalReq := &authFinalReq{}
+	co := connectOptions{}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		switch ph.partKind {
+		case pkAuthentication:
+			s.pr.read(finalReq)
+		case pkConnectOptions:
+			s.pr.read(&co)
+		}
+	}); err != nil {
+		return err
+	}
+	if finalReq.method != method || finalReq.username != username {
+		return s.writeError(&ServerError{Code: 10, Text: "authentication failed"})
+	}
+	return s.connect(co, func(enc *encoding.Encoder) {
+		enc.Int16(2)
+		authShortBytes.encode(enc, []byte(method))
+		enc.Byte(0) // no session cookie
+	})
+}
+
+// connect writes the connect reply of an authenticated session with the authentication part encoded by auth.
+func (s *ServerSession) connect(co connectOptions, auth func(enc *encoding.Encoder)) error {
+	co[int8(coFullVersionString)] = optStringType(s.version)
+	co[int8(coDatabaseName)] = optStringType(serverDatabaseName)
+	co[int8(coSystemID)] = optStringType(serverSystemID)
+	if _, ok := co[int8(coDataFormatVersion2)]; !ok {
+		co[int8(coDataFormatVersion2)] = optIntType(dfvLevel1)
+	}
+	if dfv := co[int8(coDataFormatVersion2)].(optIntType); s.maxDfv != 0 && int(dfv) > s.maxDfv {
+		co[int8(coDataFormatVersion2)] = optIntType(s.maxDfv)
+	}
+	s.pr.setDfv(int(co[int8(coDataFormatVersion2)].(optIntType)))
+	s.compress = co.compressionLevel() > 0 // accept the compression level requested by the client
+
+	parts := []*serverPart{
+		s.part(pkAuthentication, 0, 1, auth),
+		s.part(pkConnectOptions, 0, len(co), func(enc *encoding.Encoder) { co.encode(enc) }),
+	}
+	if len(s.topology) != 0 {
+		ti := newTopologyInformation(s.topology, s.conn.LocalAddr().String())
+		parts = append(parts, s.part(pkTopologyInformation, 0, len(ti), func(enc *encoding.Encoder) { multiLineOptions(ti).encode(enc) }))
+	}
+	if s.passwordChangeUser != "" {
+		parts = append(parts, s.errorPart(errPasswordChangeRequired))
+	}
+	return s.writeReply(skReply, fcConnect, parts...)
+}
+
+func (s *ServerSession) serve(h ServerHandler) error {
+	var cmd command
+	var stmtID statementID
+	var rsID resultsetID
+	var size fetchsize
+	var pos fetchPosition
+	var stmtCtx statementContext
+	var ci dbConnectInfo
+	var cmdInfo commandInfo
+	var cliInfo clientInfo
+	prms := &inputParameters{}
+	lobReq := &writeLobRequest{}
+	var readLobReq readLobRequest
+
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		if atomic.CompareAndSwapInt32(&s.invalidated, 1, 0) { // request received
+			s.stmts = map[uint64]*serverStmt{}
+		}
+		switch ph.partKind {
+		case pkCommand:
+			s.pr.read(&cmd)
+		case pkStatementID:
+			s.pr.read(&stmtID)
+		case pkResultsetID:
+			s.pr.read(&rsID)
+		case pkFetchSize:
+			s.pr.read(&size)
+		case pkFetchOptions:
+			s.pr.read(&pos)
+		case pkStatementContext:
+			s.pr.read(&stmtCtx)
+		case pkDBConnectInfo:
+			s.pr.read(&ci)
+		case pkCommandInfo:
+			s.pr.read(&cmdInfo)
+		case pkClientInfo:
+			s.pr.read(&cliInfo)
+		case pkWriteLobRequest:
+			s.pr.read(lobReq)
+		case pkReadLobRequest:
+			s.pr.read(&readLobReq)
+		case pkParameters:
+			if stmt, ok := s.stmts[uint64(stmtID)]; ok {
+				prms.inputFields = stmt.prmFields
+				s.pr.read(prms)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	if cih, ok := h.(ServerCommandInfoHandler); ok && cmdInfo != nil {
+		cih.CommandInfo(cmdInfo.sourceModule(), cmdInfo.lineNumber())
+	}
+	if cih, ok := h.(ServerClientInfoHandler); ok && cliInfo != nil {
+		cih.ClientInfo(cliInfo)
+	}
+
+	if s.passwordChangeUser != "" && s.pr.sh.messageType != mtDisconnect {
+		return s.changePassword(h.(ServerPasswordChangeHandler), string(cmd))
+	}
+
+	switch mt := s.pr.sh.messageType; mt {
+	case mtExecuteDirect:
+		stmt, err := s.prepare(h, string(cmd))
+		if err != nil {
+			return s.writeError(err)
+		}
+		return s.execute(h, stmt, nil, true, stmtCtx.queryTimeout())
+	case mtPrepare:
+		stmt, err := s.prepare(h, string(cmd))
+		if err != nil {
+			return s.writeError(err)
+		}
+		s.stmtID++
+		s.stmts[s.stmtID] = stmt
+		parts := []*serverPart{s.part(pkStatementID, 0, 1, func(enc *encoding.Encoder) { statementID(s.stmtID).encode(enc) })}
+		if len(stmt.resFields) != 0 {
+			parts = append(parts, s.resultMetadataPart(stmt.resFields))
+		}
+		if len(stmt.prmFields) != 0 {
+			parts = append(parts, s.parameterMetadataPart(stmt.prmFields))
+		}
+		if stmt.volumeID != 0 {
+			parts = append(parts, s.part(pkTableLocation, 0, 1, func(enc *encoding.Encoder) { tableLocation{int32(stmt.volumeID)}.encode(enc) }))
+		}
+		return s.writeReply(skReply, stmt.functionCode(), parts...)
+	case mtExecute:
+		stmt, ok := s.stmts[uint64(stmtID)]
+		if !ok {
+			return s.writeError(&ServerError{Code: ServerErrorCodeInvalidStatementID, Text: fmt.Sprintf("invalid statement id %d", stmtID)})
+		}
+		args := make([]interface{}, len(prms.args))
+		for i, arg := range prms.args {
+			if b, ok := arg.Value.([]byte); ok && stmt.prmFields[i%len(stmt.prmFields)].tc.isCharBased() {
+				args[i] = string(b)
+			} else {
+				args[i] = arg.Value
+			}
+		}
+		if stmt.hasLobs() {
+			return s.startLobWrite(stmt, args, stmtCtx.queryTimeout())
+		}
+		return s.execute(h, stmt, args, false, stmtCtx.queryTimeout())
+	case mtReadLob: // write lob request
+		return s.writeLob(h, lobReq)
+	case mtWriteLob: // read lob request
+		return s.readLob(&readLobReq)
+	case mtFetchNext, mtFetchAbsolute, mtFetchRelative, mtFetchFirst, mtFetchLast:
+		rs, ok := s.results[uint64(rsID)]
+		if !ok {
+			return s.writeError(&ServerError{Code: 1, Text: fmt.Sprintf("invalid resultset id %d", rsID)})
+		}
+		start := rs.next
+		if mt != mtFetchNext {
+			if !rs.scrollable {
+				return s.writeError(&ServerError{Code: 1, Text: fmt.Sprintf("resultset id %d is not scrollable", rsID)})
+			}
+			start = rs.scrollStart(mt, int(pos))
+		}
+		return s.writeReply(skReply, fcFetch, s.resultsetPart(uint64(rsID), rs, start, int(size)))
+	case mtCloseResultset:
+		delete(s.results, uint64(rsID))
+		return s.writeReply(skReply, fcNil)
+	case mtDropStatementID:
+		delete(s.stmts, uint64(stmtID))
+		return s.writeReply(skReply, fcNil)
+	case mtDBConnectInfo:
+		rep := s.dbConnectInfo(ci.databaseName())
+		return s.writeReply(skReply, fcNil, s.part(pkDBConnectInfo, 0, len(rep), func(enc *encoding.Encoder) { rep.encode(enc) }))
+	case mtCommit:
+		return s.writeReply(skReply, fcCommit)
+	case mtRollback:
+		return s.writeReply(skReply, fcRollback)
+	case mtDisconnect:
+		if err := s.writeReply(skReply, fcDisconnect); err != nil {
+			return err
+		}
+		return ErrServerDisconnect
+	default:
+		return s.writeError(&ServerError{Code: 7, Text: fmt.Sprintf("feature not supported: message type %s", mt)})
+	}
+}
+
+// errPasswordChangeRequired is the error of connects and statements of sessions restricted to the password change.
+var errPasswordChangeRequired = &ServerError{Code: ServerErrorCodePasswordChangeRequired, Text: "user is forced to change password"}
+
+// changePassword executes the request of a session restricted to the password change. Requests other than
+// the password change of the session user are rejected.
+func (s *ServerSession) changePassword(ph ServerPasswordChangeHandler, query string) error {
+	username, password, ok := parseAlterUserPassword(query)
+	if s.pr.sh.messageType != mtExecuteDirect || !ok || username != s.passwordChangeUser {
+		return s.writeError(errPasswordChangeRequired)
+	}
+	if err := ph.ChangePassword(username, password); err != nil {
+		return s.writeError(err)
+	}
+	s.passwordChangeUser = ""
+	return s.writeReply(skReply, fcDDL, s.part(pkRowsAffected, 0, 1, func(enc *encoding.Encoder) { enc.Int32(0) }))
+}
+
+// parseAlterUserPassword returns user and password of statement ALTER USER <user> PASSWORD <password>.
+func parseAlterUserPassword(query string) (username, password string, ok bool) {
+	var sc scanner.Scanner
+	sc.Reset(query)
+	var words []string
+	for token, start, end := sc.Next(); token != scanner.EOS; token, start, end = sc.Next() {
+		switch token {
+		case scanner.Identifier:
+			words = append(words, query[start:end])
+		case scanner.QuotedIdentifier:
+			words = append(words, strings.ReplaceAll(query[start+1:end-1], `""`, `"`))
+		default:
+			return "", "", false
+		}
+	}
+	if len(words) != 5 || !strings.EqualFold(words[0], "alter") || !strings.EqualFold(words[1], "user") || !strings.EqualFold(words[3], "password") {
+		return "", "", false
+	}
+	return words[2], words[4], true
+}
+
+// dbConnectInfo returns the connect information of database databaseName. All databases other than the
+// database of the session are reported to be located at the server address.
+func (s *ServerSession) dbConnectInfo(databaseName string) dbConnectInfo {
+	if strings.EqualFold(databaseName, serverDatabaseName) {
+		return dbConnectInfo{int8(ciIsConnected): optBooleanType(true)}
+	}
+	ci := dbConnectInfo{int8(ciIsConnected): optBooleanType(false)}
+	if addr, ok := s.conn.LocalAddr().(*net.TCPAddr); ok {
+		ci[int8(ciHost)] = optStringType(addr.IP.String())
+		ci[int8(ciPort)] = optIntType(addr.Port)
+	}
+	return ci
+}
+
+// startLobWrite replies to the execution of a statement with lob parameters with the lob locator ids,
+// so that the client sends the lob content. The statement is executed after the lob content is complete
+// (see writeLob). As the rows are affected by the execution only, the number of rows is reported as rows affected.
+func (s *ServerSession) startLobWrite(stmt *serverStmt, args []interface{}, timeout time.Duration) error {
+	lw := &serverLobWrite{stmt: stmt, args: args, idx: map[locatorID]int{}, bufs: map[locatorID]*bytes.Buffer{}, timeout: timeout}
+	for i := range args {
+		if stmt.prmFields[i%len(stmt.prmFields)].tc.isLob() {
+			s.lobID++
+			id := locatorID(s.lobID)
+			lw.ids = append(lw.ids, id)
+			lw.idx[id] = i
+			lw.bufs[id] = new(bytes.Buffer)
+		}
+	}
+	s.lobWrite = lw
+	numRow := len(args) / len(stmt.prmFields)
+	return s.writeReply(skReply, stmt.functionCode(),
+		s.part(pkRowsAffected, 0, 1, func(enc *encoding.Encoder) { enc.Int32(int32(numRow)) }),
+		s.writeLobReplyPart(lw.ids),
+	)
+}
+
+// writeLob adds the lob content of the write lob request req to the waiting statement execution and executes
+// the statement via handler h as soon as the content of all lob parameters is complete.
+func (s *ServerSession) writeLob(h ServerHandler, req *writeLobRequest) error {
+	lw := s.lobWrite
+	if lw == nil {
+		return s.writeError(&ServerError{Code: 1, Text: "no lob write pending"})
+	}
+	for _, descr := range req.descrs {
+		buf, ok := lw.bufs[descr.id]
+		if !ok {
+			s.lobWrite = nil
+			return s.writeError(&ServerError{Code: 1, Text: fmt.Sprintf("invalid lob locator id %d", descr.id)})
+		}
+		buf.Write(descr.b)
+		if descr.opt.isLastData() {
+			for i, id := range lw.ids {
+				if id == descr.id {
+					lw.ids = append(lw.ids[:i], lw.ids[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	if len(lw.ids) != 0 {
+		return s.writeReply(skReply, fcWriteLob, s.writeLobReplyPart(lw.ids))
+	}
+	s.lobWrite = nil
+	for id, i := range lw.idx {
+		if lw.stmt.prmFields[i%len(lw.stmt.prmFields)].tc.isCharBased() {
+			lw.args[i] = lw.bufs[id].String()
+		} else {
+			lw.args[i] = lw.bufs[id].Bytes()
+		}
+	}
+	start := time.Now()
+	if _, err := h.Execute(lw.stmt.query, lw.args); err != nil {
+		return s.writeError(err)
+	}
+	if lw.timeout > 0 && time.Since(start) > lw.timeout {
+		return s.writeError(&ServerError{Code: ServerErrorCodeQueryTimeout, Text: fmt.Sprintf("statement timeout of %s exceeded", lw.timeout)})
+	}
+	return s.writeReply(skReply, fcWriteLob, s.writeLobReplyPart(nil))
+}
+
+// encodeLobRes encodes the descriptor of a result lob including the first serverLobDataSize bytes of the content.
+// The remaining content is read by the client via read lob requests (see readLob).
+func (s *ServerSession) encodeLobRes(enc *encoding.Encoder, v interface{}) error {
+	var b []byte
+	switch v := v.(type) {
+	case nil:
+		enc.Int8(int8(ltcUndefined))
+		enc.Int8(int8(loNullindicator))
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("invalid lob value type %T", v)
+	}
+	s.lobID++
+	id := locatorID(s.lobID)
+	s.lobs[id] = b
+
+	opt := loDataincluded
+	data := b
+	if len(data) > serverLobDataSize {
+		data = data[:serverLobDataSize]
+	} else {
+		opt |= loLastdata
+	}
+	enc.Int8(int8(ltcUndefined))
+	enc.Int8(int8(opt))
+	enc.Zeroes(2)
+	enc.Int64(int64(len(b))) // number of characters
+	enc.Int64(int64(len(b))) // number of bytes
+	enc.Uint64(uint64(id))
+	enc.Int32(int32(len(data)))
+	enc.Bytes(data)
+	return nil
+}
+
+// readLob replies to the read lob request req with the requested range of the result lob content.
+func (s *ServerSession) readLob(req *readLobRequest) error {
+	b, ok := s.lobs[req.id]
+	if !ok {
+		return s.writeError(&ServerError{Code: 1, Text: fmt.Sprintf("invalid lob locator id %d", req.id)})
+	}
+	ofs := req.ofs - 1 // 1-based
+	if ofs < 0 || ofs > int64(len(b)) {
+		return s.writeError(&ServerError{Code: 1, Text: fmt.Sprintf("invalid lob offset %d", req.ofs)})
+	}
+	if s.lobLimit > 0 && int64(len(b)) > s.lobLimit {
+		b = b[:s.lobLimit]
+	}
+	if ofs > int64(len(b)) {
+		ofs = int64(len(b))
+	}
+	b = b[ofs:]
+	opt := loDataincluded
+	if int64(len(b)) > int64(req.chunkSize) {
+		b = b[:req.chunkSize]
+	} else {
+		opt |= loLastdata
+	}
+	return s.writeReply(skReply, fcReadLob, s.part(pkReadLobReply, 0, 1, func(enc *encoding.Encoder) {
+		enc.Uint64(uint64(req.id))
+		enc.Int8(int8(opt))
+		enc.Int32(int32(len(b)))
+		enc.Zeroes(3)
+		enc.Bytes(b)
+	}))
+}
+
+func (s *ServerSession) writeLobReplyPart(ids []locatorID) *serverPart {
+	return s.part(pkWriteLobReply, 0, len(ids), func(enc *encoding.Encoder) {
+		for _, id := range ids {
+			enc.Uint64(uint64(id))
+		}
+	})
+}
+
+func (s *ServerSession) prepare(h ServerHandler, query string) (*serverStmt, error) {
+	prepared, err := h.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &serverStmt{query: query, call: isServerCall(query), volumeID: prepared.VolumeID}
+	for _, typeName := range prepared.Params {
+		tc, err := serverTypeCode(typeName)
+		if err != nil {
+			return nil, err
+		}
+		stmt.prmFields = append(stmt.prmFields, &parameterField{parameterOptions: poOptional, tc: tc, mode: pmIn, length: serverFieldLength(tc), offset: noFieldName})
+	}
+	for _, c := range prepared.Columns {
+		tc, err := serverTypeCode(c.TypeName)
+		if err != nil {
+			return nil, err
+		}
+		if tc.isLob() && tc.isCharBased() {
+			return nil, fmt.Errorf("column type %s is not supported", c.TypeName)
+		}
+		stmt.resFields = append(stmt.resFields, &resultField{columnOptions: coOptional, tc: tc, length: serverFieldLength(tc), columnName: c.Name, columnDisplayName: c.Name})
+	}
+	return stmt, nil
+}
+
+// isServerCall returns true if query is a procedure call.
+func isServerCall(query string) bool {
+	fields := strings.Fields(query)
+	return len(fields) != 0 && strings.EqualFold(fields[0], "call")
+}
+
+func (stmt *serverStmt) functionCode() functionCode {
+	switch {
+	case len(stmt.resFields) != 0:
+		return fcSelect
+	case stmt.call:
+		return fcDBProcedureCall
+	default:
+		return fcUpdate
+	}
+}
+
+func serverFieldLength(tc typeCode) int16 {
+	if tc.isVariableLength() {
+		return 5000
+	}
+	return 0
+}
+
+// execute executes stmt via handler h. If the execution exceeds the query timeout of the client (if set),
+// a timeout error is returned instead of the result.
+func (s *ServerSession) execute(h ServerHandler, stmt *serverStmt, args []interface{}, direct bool, timeout time.Duration) error {
+	start := time.Now()
+	result, err := h.Execute(stmt.query, args)
+	if err != nil {
+		return s.writeError(err)
+	}
+	if timeout > 0 && time.Since(start) > timeout {
+		return s.writeError(&ServerError{Code: ServerErrorCodeQueryTimeout, Text: fmt.Sprintf("statement timeout of %s exceeded", timeout)})
+	}
+	if len(result.RowErrors) != 0 {
+		return s.writeRowErrors(stmt, len(args), result.RowErrors)
+	}
+	if len(stmt.resFields) == 0 {
+		return s.writeReply(skReply, stmt.functionCode(), s.part(pkRowsAffected, 0, 1, func(enc *encoding.Encoder) { enc.Int32(int32(result.RowsAffected)) }))
+	}
+
+	s.rsID++
+	rs := &serverResultset{fields: stmt.resFields, rows: result.Rows, scrollable: s.pr.sh.commandOptions&coScrollableCursorOn != 0}
+	s.results[s.rsID] = rs
+
+	var parts []*serverPart
+	if direct {
+		parts = append(parts, s.resultMetadataPart(stmt.resFields))
+	}
+	parts = append(parts,
+		s.part(pkResultsetID, 0, 1, func(enc *encoding.Encoder) { resultsetID(s.rsID).encode(enc) }),
+		s.resultsetPart(s.rsID, rs, 0, serverFetchSize),
+	)
+	return s.writeReply(skReply, stmt.functionCode(), parts...)
+}
+
+// part returns a reply part encoded by fn.
+func (s *ServerSession) part(pk partKind, attrs partAttributes, numArg int, fn func(enc *encoding.Encoder)) *serverPart {
+	s.buf.Reset()
+	fn(s.benc)
+	b := make([]byte, s.buf.Len())
+	copy(b, s.buf.Bytes())
+	return &serverPart{pk: pk, attrs: attrs, n: numArg, b: b}
+}
+
+func encodeFieldNames(enc *encoding.Encoder, names []string) {
+	for _, name := range names {
+		enc.Byte(byte(cesu8.StringSize(name)))
+		enc.CESU8String(name)
+	}
+}
+
+func (s *ServerSession) resultMetadataPart(fields []*resultField) *serverPart {
+	return s.part(pkResultMetadata, 0, len(fields), func(enc *encoding.Encoder) {
+		names := make([]string, len(fields))
+		offset := uint32(0)
+		for i, f := range fields {
+			enc.Int8(int8(f.columnOptions))
+			enc.Int8(int8(f.tc))
+			enc.Int16(f.fraction)
+			enc.Int16(f.length)
+			enc.Zeroes(2)           //filler
+			enc.Uint32(noFieldName) // table name
+			enc.Uint32(noFieldName) // schema name
+			enc.Uint32(offset)      // column name
+			enc.Uint32(offset)      // column display name
+			names[i] = f.columnName
+			offset += uint32(1 + cesu8.StringSize(f.columnName))
+		}
+		encodeFieldNames(enc, names)
+	})
+}
+
+func (s *ServerSession) parameterMetadataPart(fields []*parameterField) *serverPart {
+	return s.part(pkParameterMetadata, 0, len(fields), func(enc *encoding.Encoder) {
+		for _, f := range fields {
+			enc.Int8(int8(f.parameterOptions))
+			enc.Int8(int8(f.tc))
+			enc.Int8(int8(f.mode))
+			enc.Zeroes(1) //filler
+			enc.Uint32(f.offset)
+			enc.Int16(f.length)
+			enc.Int16(f.fraction)
+			enc.Zeroes(4) //filler
+		}
+	})
+}
+
+// scrollStart returns the index of the first row of a fetch absolute, relative, first or last request.
+func (rs *serverResultset) scrollStart(mt messageType, pos int) int {
+	var start int
+	switch mt {
+	case mtFetchAbsolute:
+		if pos > 0 {
+			start = pos - 1
+		} else {
+			start = len(rs.rows) + pos
+		}
+	case mtFetchRelative:
+		start = rs.next - 1 + pos // relative to the last row fetched
+	case mtFetchLast:
+		start = len(rs.rows) - 1
+	}
+	switch {
+	case start < 0:
+		return 0
+	case start > len(rs.rows):
+		return len(rs.rows)
+	default:
+		return start
+	}
+}
+
+// resultsetPart returns the chunk of at most size rows of result set rs starting at row index start.
+// Forward only result sets are closed after the last row is fetched.
+func (s *ServerSession) resultsetPart(id uint64, rs *serverResultset, start, size int) *serverPart {
+	if size <= 0 || start+size > len(rs.rows) {
+		size = len(rs.rows) - start
+	}
+	rows := rs.rows[start : start+size]
+	rs.next = start + size
+
+	var attrs partAttributes
+	if rs.next == len(rs.rows) {
+		attrs = paLastPacket
+		if !rs.scrollable {
+			attrs |= paResultsetClosed
+			delete(s.results, id)
+		}
+	}
+
+	var err error
+	part := s.part(pkResultset, attrs, len(rows), func(enc *encoding.Encoder) {
+		for _, row := range rows {
+			for i, f := range rs.fields {
+				var v interface{}
+				if i < len(row) {
+					v = row[i]
+				}
+				switch {
+				case err != nil:
+				case f.tc.isLob():
+					err = s.encodeLobRes(enc, v)
+				default:
+					err = encodeServerRes(enc, f.tc, v)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return s.errorPart(&ServerError{Code: 1, Text: err.Error()})
+	}
+	return part
+}
+
+// encodeServerRes encodes a result set value.
+func encodeServerRes(enc *encoding.Encoder, tc typeCode, v interface{}) error {
+	ft := tc.fieldType()
+	v, err := ft.Convert(v)
+	if err != nil {
+		return err
+	}
+	if tc.isIntegerType() {
+		enc.Bool(v != nil) // null indicator
+		if v == nil {
+			return nil
+		}
+	}
+	if v == nil {
+		switch tc {
+		case tcBoolean:
+			enc.Byte(booleanNullValue)
+		case tcDouble:
+			enc.Uint64(doubleNullValue)
+		case tcLongdate:
+			enc.Int64(longdateNullValue)
+		default: // variable length types
+			enc.Byte(bytesLenIndNullValue)
+		}
+		return nil
+	}
+	return ft.encodePrm(enc, v)
+}
+
+func (s *ServerSession) errorPart(err error) *serverPart {
+	e, ok := err.(*ServerError)
+	if !ok {
+		e = &ServerError{Code: 1, Text: err.Error()}
+	}
+	return s.part(pkError, 0, 1, func(enc *encoding.Encoder) {
+		e.encode(enc)
+		enc.Zeroes(1) // see hdbErrors decode
+	})
+}
+
+func (e *ServerError) encode(enc *encoding.Encoder) {
+	state := e.SQLState
+	if state == "" {
+		state = "HY000"
+	}
+	var b sqlState
+	copy(b[:], fmt.Sprintf("%-5s", state))
+	enc.Int32(int32(e.Code))
+	enc.Int32(int32(e.Position))
+	enc.Int32(int32(len(e.Text)))
+	enc.Int8(int8(errorLevelError))
+	enc.Bytes(b[:])
+	enc.Bytes([]byte(e.Text))
+}
+
+// writeRowErrors replies to an array execution of stmt with numArg arguments rejecting the rows of rowErrs.
+func (s *ServerSession) writeRowErrors(stmt *serverStmt, numArg int, rowErrs map[int]*ServerError) error {
+	numRow := 1
+	if len(stmt.prmFields) != 0 {
+		numRow = numArg / len(stmt.prmFields)
+	}
+	rows := make([]int32, numRow)
+	errs := make([]*ServerError, 0, len(rowErrs))
+	for i := range rows {
+		if e, ok := rowErrs[i]; ok {
+			rows[i] = raExecutionFailed
+			errs = append(errs, e)
+		} else {
+			rows[i] = 1
+		}
+	}
+	errPart := s.part(pkError, 0, len(errs), func(enc *encoding.Encoder) {
+		for _, e := range errs {
+			e.encode(enc)
+			if len(errs) == 1 {
+				enc.Zeroes(1) // see hdbErrors decode
+			} else {
+				enc.Zeroes(padBytes(fixLength + len(e.Text)))
+			}
+		}
+	})
+	rowsPart := s.part(pkRowsAffected, 0, numRow, func(enc *encoding.Encoder) {
+		for _, n := range rows {
+			enc.Int32(n)
+		}
+	})
+	return s.writeReply(skError, stmt.functionCode(), errPart, rowsPart)
+}
+
+func (s *ServerSession) writeError(err error) error {
+	if err == ErrServerDisconnect {
+		return err
+	}
+	return s.writeReply(skError, fcNil, s.errorPart(err))
+}
+
+func (s *ServerSession) writeReply(sk segmentKind, fc functionCode, parts ...*serverPart) error {
+	size := segmentHeaderSize
+	for _, part := range parts {
+		size += partHeaderSize + len(part.b) + padBytes(len(part.b))
+	}
+
+	mh := &messageHeader{sessionID: s.sessionID, packetCount: s.packetCount, varPartLength: uint32(size), varPartSize: uint32(size), noOfSegm: 1}
+	s.packetCount++
+
+	if s.compress && size >= serverCompressionThreshold {
+		s.msgBuf.Reset()
+		if err := s.writeSegment(s.msgEnc, size, sk, fc, parts); err != nil {
+			return err
+		}
+		msg := s.msgBuf.Bytes()
+		s.compBuf = lz4.Compress(s.compBuf, msg)
+		if len(s.compBuf) < len(msg) {
+			mh.packetOptions = poCompressed
+			mh.varPartLength = uint32(len(s.compBuf))
+			mh.varPartSize = uint32(len(s.compBuf))
+			mh.compressionVarPartLength = uint32(len(msg))
+			msg = s.compBuf
+		}
+		if err := mh.encode(s.enc); err != nil {
+			return err
+		}
+		s.enc.Bytes(msg)
+		return s.wr.Flush()
+	}
+
+	if err := mh.encode(s.enc); err != nil {
+		return err
+	}
+	if err := s.writeSegment(s.enc, size, sk, fc, parts); err != nil {
+		return err
+	}
+	return s.wr.Flush()
+}
+
+// writeSegment encodes the reply segment of size bytes (segment header included) containing parts.
+func (s *ServerSession) writeSegment(enc *encoding.Encoder, size int, sk segmentKind, fc functionCode, parts []*serverPart) error {
+	sh := &segmentHeader{segmentLength: int32(size), noOfParts: int16(len(parts)), segmentNo: 1, segmentKind: sk, functionCode: fc}
+	if err := sh.encode(enc); err != nil {
+		return err
+	}
+
+	bufferSize := size - segmentHeaderSize
+	for _, part := range parts {
+		ph := &partHeader{partKind: part.pk, partAttributes: part.attrs, bufferLength: int32(len(part.b)), bufferSize: int32(bufferSize)}
+		if err := ph.setNumArg(part.n); err != nil {
+			return err
+		}
+		if err := ph.encode(enc); err != nil {
+			return err
+		}
+		enc.Bytes(part.b)
+		pad := padBytes(len(part.b))
+		enc.Zeroes(pad)
+		bufferSize -= partHeaderSize + len(part.b) + pad
+	}
+	return nil
+}
@@ -5,19 +5,21 @@
 package protocol
 
 import (
-	"bufio"
 	"context"
 	"crypto/tls"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/text/transform"
 
 	"github.com/SAP/go-hdb/driver/dial"
+	"github.com/SAP/go-hdb/driver/dlog"
 	"github.com/SAP/go-hdb/internal/container/varmap"
 	"github.com/SAP/go-hdb/internal/unicode"
 	"github.com/SAP/go-hdb/internal/unicode/cesu8"
@@ -43,36 +45,43 @@ type sessionStatus interface {
 	isBad() bool
 }
 
+type sessionCanceler interface {
+	cancel()
+}
+
 type sessionConn interface {
 	io.ReadWriteCloser
 	sessionStatus
+	sessionCanceler
 }
 
-func newSessionConn(ctx context.Context, address string, dialer dial.Dialer, timeout, tcpKeepAlive time.Duration, tlsConfig *tls.Config) (sessionConn, error) {
+func newSessionConn(ctx context.Context, address string, dialer dial.Dialer, timeout, tcpKeepAlive time.Duration, tlsConfig *tls.Config, logger dlog.Logger) (sessionConn, error) {
 	// session recording
 	if wr, ok := ctx.Value(sesRecording).(io.Writer); ok {
-		conn, err := newDbConn(ctx, address, dialer, timeout, tcpKeepAlive, tlsConfig)
+		conn, err := newDbConn(ctx, address, dialer, timeout, tcpKeepAlive, tlsConfig, logger)
 		if err != nil {
 			return nil, err
 		}
 		return proxyConn{
-			Reader:        io.TeeReader(conn, wr), // teereader: write database replies to writer
-			Writer:        conn,
-			Closer:        conn,
-			sessionStatus: conn,
+			Reader:          io.TeeReader(conn, wr), // teereader: write database replies to writer
+			Writer:          conn,
+			Closer:          conn,
+			sessionStatus:   conn,
+			sessionCanceler: conn,
 		}, nil
 	}
 	// session replay
 	if rd, ok := ctx.Value(sesReplay).(io.Reader); ok {
 		nwc := nullWriterCloser{}
 		return proxyConn{
-			Reader:        rd,
-			Writer:        nwc,
-			Closer:        nwc,
-			sessionStatus: nwc,
+			Reader:          rd,
+			Writer:          nwc,
+			Closer:          nwc,
+			sessionStatus:   nwc,
+			sessionCanceler: nwc,
 		}, nil
 	}
-	return newDbConn(ctx, address, dialer, timeout, tcpKeepAlive, tlsConfig)
+	return newDbConn(ctx, address, dialer, timeout, tcpKeepAlive, tlsConfig, logger)
 }
 
 type nullWriterCloser struct{}
@@ -80,6 +89,7 @@ type nullWriterCloser struct{}
 func (n nullWriterCloser) Write(p []byte) (int, error) { return len(p), nil }
 func (n nullWriterCloser) Close() error                { return nil }
 func (n nullWriterCloser) isBad() bool                 { return false }
+func (n nullWriterCloser) cancel()                     {}
 
 // proxy connection
 type proxyConn struct {
@@ -87,17 +97,23 @@ type proxyConn struct {
 	io.Writer
 	io.Closer
 	sessionStatus
+	sessionCanceler
 }
 
+// errCanceled is the bad connection error of a canceled database connection.
+var errCanceled = errors.New("connection canceled")
+
 // dbConn wraps the database tcp connection. It sets timeouts and handles driver ErrBadConn behavior.
 type dbConn struct {
 	address   string
 	timeout   time.Duration
 	conn      net.Conn
+	logger    dlog.Logger
 	lastError error // error bad connection
+	canceled  int32 // atomic - set if an I/O operation was canceled
 }
 
-func newDbConn(ctx context.Context, address string, dialer dial.Dialer, timeout, tcpKeepAlive time.Duration, tlsConfig *tls.Config) (*dbConn, error) {
+func newDbConn(ctx context.Context, address string, dialer dial.Dialer, timeout, tcpKeepAlive time.Duration, tlsConfig *tls.Config, logger dlog.Logger) (*dbConn, error) {
 	conn, err := dialer.DialContext(ctx, address, dial.DialerOptions{Timeout: timeout, TCPKeepAlive: tcpKeepAlive})
 	if err != nil {
 		return nil, err
@@ -108,7 +124,7 @@ func newDbConn(ctx context.Context, address string, dialer dial.Dialer, timeout,
 		conn = tls.Client(conn, tlsConfig)
 	}
 
-	return &dbConn{address: address, timeout: timeout, conn: conn}, nil
+	return &dbConn{address: address, timeout: timeout, conn: conn, logger: logger}, nil
 }
 
 func (c *dbConn) isBad() bool { return c.lastError != nil }
@@ -124,18 +140,56 @@ func (c *dbConn) Close() error {
 	return c.conn.Close()
 }
 
+// cancel interrupts pending and future I/O operations. It is safe to be called concurrently to Read and Write.
+// As the protocol state of the connection is undefined afterwards, the connection becomes a bad connection.
+func (c *dbConn) cancel() {
+	atomic.StoreInt32(&c.canceled, 1)
+	c.conn.SetDeadline(time.Unix(1, 0)) // deadline in the past
+}
+
+// checkCanceled needs to be called after setting a deadline, so that the deadline
+// of a concurrent call to cancel does not get overwritten unnoticed.
+func (c *dbConn) checkCanceled() error {
+	if atomic.LoadInt32(&c.canceled) != 0 {
+		return errCanceled
+	}
+	return nil
+}
+
 // Read implements the io.Reader interface.
 func (c *dbConn) Read(b []byte) (n int, err error) {
 	//set timeout
 	if err = c.conn.SetReadDeadline(c.deadline()); err != nil {
 		goto retError
 	}
+	if err = c.checkCanceled(); err != nil {
+		goto retError
+	}
 	if n, err = c.conn.Read(b); err != nil {
 		goto retError
 	}
 	return
 retError:
-	plog.Printf("Connection read error local address %s remote address %s: %s", c.conn.LocalAddr(), c.conn.RemoteAddr(), err)
+	c.logger.Log(dlog.LevelError, "connection read error", "localAddr", c.conn.LocalAddr(), "remoteAddr", c.conn.RemoteAddr(), "error", err)
+	c.lastError = err
+	return n, driver.ErrBadConn
+}
+
+// writeBuffers writes v with a single vectored write if supported by the underlying connection.
+func (c *dbConn) writeBuffers(v *net.Buffers) (n int64, err error) {
+	//set timeout
+	if err = c.conn.SetWriteDeadline(c.deadline()); err != nil {
+		goto retError
+	}
+	if err = c.checkCanceled(); err != nil {
+		goto retError
+	}
+	if n, err = v.WriteTo(c.conn); err != nil {
+		goto retError
+	}
+	return
+retError:
+	c.logger.Log(dlog.LevelError, "connection write error", "localAddr", c.conn.LocalAddr(), "remoteAddr", c.conn.RemoteAddr(), "error", err)
 	c.lastError = err
 	return n, driver.ErrBadConn
 }
@@ -146,12 +200,15 @@ func (c *dbConn) Write(b []byte) (n int, err error) {
 	if err = c.conn.SetWriteDeadline(c.deadline()); err != nil {
 		goto retError
 	}
+	if err = c.checkCanceled(); err != nil {
+		goto retError
+	}
 	if n, err = c.conn.Write(b); err != nil {
 		goto retError
 	}
 	return
 retError:
-	plog.Printf("Connection write error local address %s remote address %s: %s", c.conn.LocalAddr(), c.conn.RemoteAddr(), err)
+	c.logger.Log(dlog.LevelError, "connection write error", "localAddr", c.conn.LocalAddr(), "remoteAddr", c.conn.RemoteAddr(), "error", err)
 	c.lastError = err
 	return n, driver.ErrBadConn
 }
@@ -165,43 +222,107 @@ type SessionConfig interface {
 	DriverVersion() string
 	DriverName() string
 	ApplicationName() string
-	BufferSize() int
+	ReadBufferSize() int
+	WriteBufferSize() int
+	AdaptiveBufferSize() bool
 	FetchSize() int
+	Prefetch() int
 	BulkSize() int
 	LobChunkSize() int32
 	Dialer() dial.Dialer
+	Proxy() dial.ProxyFunc
 	TimeoutDuration() time.Duration
 	TCPKeepAlive() time.Duration
 	Dfv() int
 	SessionVariablesVarMap() *varmap.VarMap
 	TLSConfig() *tls.Config
 	Legacy() bool
+	Logger() dlog.Logger
+	ProtocolTrace() bool
+	WireCapture() (*CaptureWriter, bool)
+	ZeroCopyStrings() bool
+	Compression() bool
+	CompressionThreshold() int
+	SessionStats() *SessionStats
+	ReadAhead() bool
+	StrictProtocol() bool
+	HANA1Compat() bool
+	PinDfv() bool
+	MaxPacketSize() int
+	GSSProvider() GSSProvider
+	ServicePrincipal() string
+	ClientCertificate() *tls.Certificate
+	TokenProvider() TokenProvider
+	StatementCancel() bool
+	BulkContinueOnError() bool
+	ReadOnly() bool
+	StatementRouting() bool
+	TimeLocation() *time.Location
+	PasswordChangeHandler() PasswordChangeHandler
 }
 
 const dfvLevel1 = 1
 
+// hana1MaxDfv is the maximal data format version requested in HANA 1.0 compatibility mode.
+const hana1MaxDfv = 4
+
+// Minimal server versions.
+var (
+	minServerVersion      = parseHDBVersion("2.00.042")
+	minHANA1ServerVersion = parseHDBVersion("1.00.122") // HANA 1.0 SPS12
+)
+
 const defaultSessionID = -1
 
+// sessionConnNo is the process wide counter of session connection numbers.
+var sessionConnNo uint64
+
 // Session represents a HDB session.
 type Session struct {
-	cfg SessionConfig
+	cfg    SessionConfig
+	logger dlog.Logger
+	ts     *traceState
+
+	connNo        uint64        // client side connection number (unique per process)
+	stats         *SessionStats // network statistics
+	correlationID string        // correlation id of the current statement execution
+	stmtFetchSize int           // fetch size of the current statement execution (0: configured fetch size)
+	stmtTimeout   time.Duration // server query timeout of the current statement execution (0: no timeout)
+	stmtScroll    bool          // request scrollable result sets in the current statement execution
+	cmdInfo       commandInfo   // source location of the current statement execution (nil: none)
+	stmtCancel    bool          // cancel statements by cancel requests instead of canceling the connection
+
+	opObserver OpObserver // observer of fetches and lob reads / writes (nil: none)
 
 	sessionID     int64
 	serverOptions connectOptions
+	topology      topologyInformation // topology information returned by the server at connect time
 	serverVersion hdbVersion
+	dfv           int // data format version negotiated with the server
+
+	changedPassword string // password changed when the session was opened ("": no password change)
 
 	conn sessionConn
-	rd   *bufio.Reader
-	wr   *bufio.Writer
+	rd   *bufferedReader
+	wr   *bufferedWriter
 
 	pr *protocolReader
 	pw *protocolWriter
 
 	//serialize write request - read reply
-	//supports calling session methods in go routines (driver methods with context cancellation)
 	mu       sync.Mutex
 	isLocked bool
 
+	// context cancellation watcher
+	watcher  chan context.Context
+	finished chan struct{}
+	closed   chan struct{}
+	watching bool
+
+	// lob parameter readers of the current statement execution (interrupted on context cancellation)
+	lobMu      sync.Mutex
+	lobReaders []io.Reader
+
 	inTx bool // in transaction
 	/*
 		As long as a session is in query mode no other sql statement must be executed.
@@ -219,35 +340,77 @@ type Session struct {
 func NewSession(ctx context.Context, cfg SessionConfig) (*Session, error) {
 	var conn sessionConn
 
-	conn, err := newSessionConn(ctx, cfg.Host(), cfg.Dialer(), cfg.TimeoutDuration(), cfg.TCPKeepAlive(), cfg.TLSConfig())
+	logger := cfg.Logger()
+	if logger == nil {
+		logger = dlog.Default()
+	}
+
+	// authentication method (the token of the token authentication is requested before the connection is opened)
+	var stepper authStepper
+	if provider := cfg.GSSProvider(); provider != nil {
+		stepper = newGSSAuth(cfg.Username(), gssServicePrincipal(cfg.ServicePrincipal(), cfg.Host()), provider)
+	} else if cert := cfg.ClientCertificate(); cert != nil {
+		stepper = newX509Auth(cert)
+	} else if provider := cfg.TokenProvider(); provider != nil {
+		var err error
+		if stepper, err = newTokenAuth(ctx, cfg.Username(), provider); err != nil {
+			return nil, err
+		}
+	} else {
+		stepper = newAuth(cfg.Username(), cfg.Password())
+	}
+
+	connNo := atomic.AddUint64(&sessionConnNo, 1)
+
+	dialer := cfg.Dialer()
+	if proxy := cfg.Proxy(); proxy != nil {
+		dialer = dial.NewProxyDialer(proxy, dialer)
+	}
+	conn, err := newSessionConn(ctx, cfg.Host(), dialer, cfg.TimeoutDuration(), cfg.TCPKeepAlive(), cfg.TLSConfig(), logger)
 	if err != nil {
 		return nil, err
 	}
 
-	var bufRd *bufio.Reader
-	var bufWr *bufio.Writer
-
-	bufferSize := cfg.BufferSize()
-	if bufferSize > 0 {
-		bufRd = bufio.NewReaderSize(conn, bufferSize)
-		bufWr = bufio.NewWriterSize(conn, bufferSize)
-	} else {
-		bufRd = bufio.NewReader(conn)
-		bufWr = bufio.NewWriter(conn)
+	var capture *captureConn
+	captureWriter, captureHandshakeOnly := cfg.WireCapture()
+	if captureWriter != nil {
+		capture = newCaptureConn(conn, captureWriter, connNo)
+		conn = capture
 	}
 
-	pw := newProtocolWriter(bufWr, cfg.SessionVariablesVarMap()) // write upstream
+	bufRd := newBufferedReader(conn, cfg.ReadBufferSize(), cfg.AdaptiveBufferSize())
+	bufWr := newBufferedWriter(conn, cfg.WriteBufferSize(), cfg.AdaptiveBufferSize())
+
+	ts := newTraceState(cfg.ProtocolTrace(), cfg.Logger())
+	ts.connNo = connNo
+
+	pw := newProtocolWriter(bufWr, cfg.SessionVariablesVarMap(), ts.traceLogger(true)) // write upstream
+	pw.capture = capture
+	pw.noConnectClientInfo = cfg.HANA1Compat()
+	stats := NewSessionStats(cfg.SessionStats())
+	pw.stats = stats
+	pw.setLocation(cfg.TimeLocation())
 	if err := pw.writeProlog(); err != nil {
 		return nil, err
 	}
 
-	pr := newProtocolReader(false, bufRd) // read downstream
+	pr := newProtocolReader(false, bufRd, ts.traceLogger(false)) // read downstream
+	pr.bufRd = bufRd
+	pr.zeroCopy = cfg.ZeroCopyStrings()
+	pr.stats = stats
+	pr.readAheadOn = cfg.ReadAhead()
+	pr.strict = cfg.StrictProtocol()
+	pr.setLocation(cfg.TimeLocation())
 	if err := pr.readProlog(); err != nil {
 		return nil, err
 	}
 
 	s := &Session{
 		cfg:       cfg,
+		logger:    logger,
+		ts:        ts,
+		connNo:    connNo,
+		stats:     stats,
 		sessionID: defaultSessionID,
 		conn:      conn,
 		rd:        bufRd,
@@ -255,24 +418,46 @@ func NewSession(ctx context.Context, cfg SessionConfig) (*Session, error) {
 		pr:        pr,
 		pw:        pw,
 	}
+	s.stmtCancel = cfg.StatementCancel()
 
-	authStepper := newAuth(cfg.Username(), cfg.Password())
-	if s.sessionID, s.serverOptions, err = s.authenticate(authStepper); err != nil {
-		return nil, err
+	if s.sessionID, s.serverOptions, err = s.authenticate(stepper); err != nil {
+		handler := cfg.PasswordChangeHandler()
+		if handler == nil || !errors.Is(err, ErrPasswordChangeRequired) {
+			return nil, err
+		}
+		if err := s.changePassword(ctx, handler); err != nil {
+			return nil, err
+		}
 	}
 
 	if s.sessionID <= 0 {
 		return nil, fmt.Errorf("invalid session id %d", s.sessionID)
 	}
 
+	if capture != nil && captureHandshakeOnly {
+		capture.stop()
+	}
+
+	if cfg.Compression() && s.serverOptions.compressionLevel() > 0 {
+		s.pw.setCompression(cfg.CompressionThreshold())
+	}
+
 	s.serverVersion = parseHDBVersion(s.serverOptions.fullVersionString())
 	/*
 		hdb version < 2.00.042
 		- no support of providing ClientInfo (server variables) in CONNECT message (see messageType.clientInfoSupported())
+		- supported in HANA 1.0 compatibility mode only (client info is sent with the first statement)
 	*/
-	if s.serverVersion.compare(parseHDBVersion("2.00.042")) == -1 {
+	minVersion := minServerVersion
+	if cfg.HANA1Compat() {
+		minVersion = minHANA1ServerVersion
+	}
+	if s.serverVersion.compare(minVersion) == -1 {
 		return nil, fmt.Errorf("server version %s is not supported", s.serverVersion)
 	}
+	if cfg.PinDfv() && s.dfv != s.requestedDfv() {
+		return nil, fmt.Errorf("data format version %d is not supported by server version %s (negotiated version %d)", s.requestedDfv(), s.serverVersion, s.dfv)
+	}
 	return s, nil
 }
 
@@ -289,18 +474,172 @@ func (s *Session) checkLock() {
 	}
 }
 
-// Kill session.
-func (s *Session) Kill() {
+/*
+Watch starts watching the cancellation of ctx for the following session calls until Unwatch is called.
+
+In case ctx gets canceled, pending and future I/O operations of the session are interrupted and
+the session becomes a bad session (see IsBad). If statement cancellation is configured, the running statement
+is canceled by a cancel request sent on a secondary session instead, so that the session stays valid. Only if
+the cancel request fails or the statement does not return in time, the I/O operations get interrupted. The cancel
+request runs asynchronously, so that Unwatch does not wait for it. The context is watched by a single goroutine per session,
+which is started on first use and stopped when the session gets closed.
+Watch returns the context error if ctx is already done.
+*/
+func (s *Session) Watch(ctx context.Context) error {
 	s.checkLock()
-	plog.Printf("Kill session %d", s.sessionID)
-	s.conn.Close()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil { // context cannot be canceled
+		return nil
+	}
+	if s.watcher == nil {
+		s.startWatcher()
+	}
+	s.watcher <- ctx
+	s.watching = true
+	return nil
+}
+
+// Unwatch stops watching the context cancellation started by Watch.
+func (s *Session) Unwatch() {
+	s.checkLock()
+	if !s.watching {
+		return
+	}
+	s.finished <- struct{}{}
+	s.watching = false
+}
+
+func (s *Session) startWatcher() {
+	s.watcher = make(chan context.Context)
+	s.finished = make(chan struct{})
+	s.closed = make(chan struct{})
+
+	go func(watcher <-chan context.Context, finished, closed <-chan struct{}) {
+		for {
+			var ctx context.Context
+			select {
+			case ctx = <-watcher:
+			case <-closed:
+				return
+			}
+			select {
+			case <-ctx.Done():
+				select {
+				case <-finished: // call finished meanwhile - no need to cancel
+					continue
+				default:
+				}
+				s.interruptLobs(ctx.Err()) // unblock lob parameter readers waiting for content
+				if s.stmtCancel {
+					canceled := make(chan error, 1)
+					go func(sessionID int64) { canceled <- s.cancelStatement(sessionID) }(s.sessionID)
+					timer := time.NewTimer(stmtCancelTimeout)
+					if s.awaitCancel(finished, canceled, timer.C) {
+						timer.Stop()
+						continue
+					}
+					timer.Stop()
+				}
+				s.logger.Log(dlog.LevelWarn, "cancel session", "sessionID", s.sessionID, "error", ctx.Err())
+				s.conn.cancel()
+				<-finished
+			case <-finished:
+			}
+		}
+	}(s.watcher, s.finished, s.closed)
+}
+
+/*
+awaitCancel waits for the return of the statement canceled by the cancel request running asynchronously and
+reporting its result on canceled. The session call is not blocked by the cancel request: in case the statement
+returns before the cancel request is done, the call finishes immediately and only the watching of the next call
+waits for the cancel request, as a late cancel request would cancel the next statement of the session otherwise.
+awaitCancel returns false, if the cancel request failed or the statement did not return until timeout.
+*/
+func (s *Session) awaitCancel(finished <-chan struct{}, canceled <-chan error, timeout <-chan time.Time) bool {
+	for {
+		select {
+		case <-finished: // statement returned - session stays valid
+			if canceled != nil {
+				<-canceled
+			}
+			return true
+		case err := <-canceled:
+			if err != nil {
+				s.logger.Log(dlog.LevelWarn, "cancel statement", "sessionID", s.sessionID, "error", err)
+				return false
+			}
+			canceled = nil // wait for the statement to return
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+// stmtCancelTimeout is the time a canceled statement is given to return, before the connection gets canceled.
+const stmtCancelTimeout = 5 * time.Second
+
+// cancelWorkStmt cancels the statement of a session. The user needs the SESSION ADMIN system privilege.
+const cancelWorkStmt = "alter system cancel work in session '%d'"
+
+// cancelStatement cancels the statement executed by session sessionID via a cancel request sent on a secondary session.
+func (s *Session) cancelStatement(sessionID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stmtCancelTimeout)
+	defer cancel()
+	cs, err := NewSession(ctx, s.cfg)
+	if err != nil {
+		return err
+	}
+	cs.Lock()
+	defer cs.Unlock()
+	defer cs.Close()
+	_, err = cs.ExecDirect(fmt.Sprintf(cancelWorkStmt, sessionID))
+	return err
+}
+
+func (s *Session) stopWatcher() {
+	if s.closed != nil {
+		close(s.closed)
+		s.watcher = nil
+		s.closed = nil
+	}
 }
 
 // Reset resets the session.
 func (s *Session) Reset() { s.checkLock(); s.SetInQuery(false); QrsCache.cleanup(s) }
 
 // Close closes the session.
-func (s *Session) Close() error { s.checkLock(); QrsCache.cleanup(s); return s.conn.Close() }
+func (s *Session) Close() error {
+	s.checkLock()
+	s.stopWatcher()
+	QrsCache.cleanup(s)
+	return s.conn.Close()
+}
+
+/*
+Reconnect replaces the (broken) connection of the session by the connection of the new session ns, so that
+the session can be used further on. The session state bound to the connection (transaction, query mode,
+result sets) is reset, whereas the session variables set by SetSessionVariables are sent again with the next
+request. ns must not be used after calling Reconnect.
+*/
+func (s *Session) Reconnect(ns *Session) {
+	s.checkLock()
+	s.stopWatcher()
+	QrsCache.cleanup(s)
+	s.conn.Close()
+
+	if m := s.pw.csv.LoadMap(); len(m) != 0 {
+		ns.pw.csv.StoreMap(m)
+	}
+	ns.pw.stats, ns.pr.stats = s.stats, s.stats
+
+	s.ts, s.connNo = ns.ts, ns.connNo
+	s.sessionID, s.serverOptions, s.serverVersion, s.dfv = ns.sessionID, ns.serverOptions, ns.serverVersion, ns.dfv
+	s.conn, s.rd, s.wr, s.pr, s.pw = ns.conn, ns.rd, ns.wr, ns.pr, ns.pw
+	s.watching, s.inTx, s.inQuery = false, false, false
+}
 
 // InTx indicates, that the session is in transaction mode.
 func (s *Session) InTx() bool { s.checkLock(); return s.inTx }
@@ -315,7 +654,154 @@ func (s *Session) InQuery() bool { s.checkLock(); return s.inQuery }
 func (s *Session) SetInQuery(v bool) { s.checkLock(); s.inQuery = v }
 
 // IsBad indicates, that the session is in bad state.
-func (s *Session) IsBad() bool { s.checkLock(); return s.conn.isBad() }
+func (s *Session) IsBad() bool { s.checkLock(); return s.conn.isBad() || s.pr.invalid() }
+
+// ID returns the session id.
+func (s *Session) ID() int64 { return s.sessionID }
+
+// ServerVersion returns the version of the database server.
+func (s *Session) ServerVersion() string { return s.serverVersion.String() }
+
+// Dfv returns the data format version negotiated with the database server.
+func (s *Session) Dfv() int { return s.dfv }
+
+/*
+ServerOptions returns the connect options sent by the database server by option name (e.g. DatabaseName,
+SystemID or BuildPlatform). Option values are of type bool, int64, float64, string or []byte.
+*/
+func (s *Session) ServerOptions() map[string]interface{} { return s.serverOptions.values() }
+
+// Topology returns the database hosts of the topology information returned by the server at connect time.
+func (s *Session) Topology() []TopologyHost { return s.topology.hosts() }
+
+/*
+RouteAddress returns the address ("host:port") of the host a prepared statement execution is routed to (scale-out
+statement routing). A statement is routed, if all tables accessed by the statement (table location of the prepare
+result) are located on a single host other than the host of the session.
+*/
+func (s *Session) RouteAddress(pr *PrepareResult) (string, bool) {
+	if len(pr.tableLocation) == 0 {
+		return "", false
+	}
+	hosts := s.topology.hosts()
+	var route *TopologyHost
+	for _, volumeID := range pr.tableLocation {
+		h := findVolumeHost(hosts, int(volumeID))
+		if h == nil || (route != nil && h != route) {
+			return "", false // unknown volume or tables on multiple hosts
+		}
+		route = h
+	}
+	if route.CurrentSession {
+		return "", false
+	}
+	return route.Address(), true
+}
+
+func findVolumeHost(hosts []TopologyHost, volumeID int) *TopologyHost {
+	for i, h := range hosts {
+		if h.VolumeID == volumeID {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
+
+// Compressed returns true if the session compresses messages exceeding the compression threshold.
+func (s *Session) Compressed() bool { return s.pw.compress }
+
+// Stats returns the network statistics of the session.
+func (s *Session) Stats() *SessionStats { return s.stats }
+
+// SessionVariables returns the session variables of the connector merged with the session variables set by
+// SetSessionVariables.
+func (s *Session) SessionVariables() map[string]string {
+	m := s.pw.sv.LoadMap()
+	for k, v := range s.pw.csv.LoadMap() {
+		m[k] = v
+	}
+	return m
+}
+
+// SetSessionVariables sets session variables of the session, which are sent to the server with the next request
+// supporting client info (prepare and execute). Variables not contained in m keep their values.
+// SetSessionVariables is safe for concurrent use.
+func (s *Session) SetSessionVariables(m map[string]string) { s.pw.csv.UpdateMap(m) }
+
+// ConnNo returns the client side connection number of the session, which is unique per process.
+func (s *Session) ConnNo() uint64 { return s.connNo }
+
+// CorrelationID returns the correlation id of the current statement execution.
+func (s *Session) CorrelationID() string { s.checkLock(); return s.correlationID }
+
+// SetStmtFetchSize sets the fetch size of the result sets returned by the current statement execution.
+// A fetch size of 0 resets to the configured fetch size.
+func (s *Session) SetStmtFetchSize(fetchSize int) {
+	s.checkLock()
+	s.stmtFetchSize = fetchSize
+}
+
+// SetStmtTimeout sets the server query timeout of the current statement execution. The timeout is sent to the
+// server in full seconds (rounded up) and enforced by the server. A timeout of 0 disables the server timeout.
+func (s *Session) SetStmtTimeout(timeout time.Duration) {
+	s.checkLock()
+	s.stmtTimeout = timeout
+}
+
+// SetStmtScrollable requests scrollable result sets (see ScrollableRows) in the current statement execution.
+func (s *Session) SetStmtScrollable(scrollable bool) {
+	s.checkLock()
+	s.stmtScroll = scrollable
+}
+
+// queryOptions returns the segment command options of query executions.
+func (s *Session) queryOptions() commandOptions {
+	if s.stmtScroll {
+		return coScrollableCursorOn
+	}
+	return coNil
+}
+
+// SetCommandInfo sets the application source location (source module and line number) of the current
+// statement execution. An empty source module resets the command info.
+func (s *Session) SetCommandInfo(sourceModule string, lineNumber int) {
+	s.checkLock()
+	if sourceModule == "" {
+		s.cmdInfo = nil
+		return
+	}
+	s.cmdInfo = newCommandInfo(sourceModule, lineNumber)
+}
+
+// cmdParts returns the request parts of a statement including the command info if set.
+func (s *Session) cmdParts(parts ...partWriter) []partWriter {
+	if s.cmdInfo == nil {
+		return parts
+	}
+	return append(parts, s.cmdInfo)
+}
+
+// execParts returns the request parts of a statement execution including the command info if set
+// and the statement context if a server query timeout is set.
+func (s *Session) execParts(parts ...partWriter) []partWriter {
+	parts = s.cmdParts(parts...)
+	if s.stmtTimeout <= 0 {
+		return parts
+	}
+	return append(parts, newQueryTimeoutContext(s.stmtTimeout))
+}
+
+// SetCorrelationID sets the correlation id of the current statement execution.
+// The id is added to protocol trace entries and database errors.
+func (s *Session) SetCorrelationID(id string) {
+	s.checkLock()
+	s.correlationID = id
+	s.ts.correlationID = id
+	s.pr.correlationID = id
+}
+
+// ServerExecutionTime returns the server processing time of the last database request.
+func (s *Session) ServerExecutionTime() time.Duration { s.checkLock(); return s.pr.serverExecutionTime }
 
 // MaxBulkNum returns the maximal number of bulk calls before auto flush.
 func (s *Session) MaxBulkNum() int {
@@ -326,19 +812,42 @@ func (s *Session) MaxBulkNum() int {
 	return maxBulkNum
 }
 
+// requestedDfv returns the data format version requested by the client.
+func (s *Session) requestedDfv() int {
+	dfv := s.cfg.Dfv()
+	if s.cfg.HANA1Compat() && dfv > hana1MaxDfv {
+		return hana1MaxDfv
+	}
+	return dfv
+}
+
+// clientDistributionMode returns the client distribution mode requested by the client.
+func (s *Session) clientDistributionMode() optIntType {
+	if s.cfg.StatementRouting() {
+		return cdmStatement
+	}
+	return cdmOff
+}
+
 func (s *Session) defaultClientOptions() connectOptions {
 	co := connectOptions{
 		int8(coDistributionProtocolVersion): optBooleanType(false),
 		int8(coSelectForUpdateSupported):    optBooleanType(false),
 		int8(coSplitBatchCommands):          optBooleanType(true),
-		int8(coDataFormatVersion2):          optIntType(s.cfg.Dfv()),
+		int8(coDataFormatVersion2):          optIntType(s.requestedDfv()),
 		int8(coCompleteArrayExecution):      optBooleanType(true),
-		int8(coClientDistributionMode):      cdmOff,
+		int8(coClientDistributionMode):      s.clientDistributionMode(),
 		// int8(coImplicitLobStreaming):        optBooleanType(true),
 	}
 	if s.cfg.Locale() != "" {
 		co[int8(coClientLocale)] = optStringType(s.cfg.Locale())
 	}
+	if s.cfg.Compression() {
+		co[int8(coCompressionLevelAndFlags)] = optIntType(defaultCompressionLevel)
+	}
+	if s.cfg.ReadOnly() { // request the topology of Active/Active (read enabled) systems
+		co[int8(coActiveActiveProtocolVersion)] = activeActiveProtocolVersion
+	}
 	return co
 }
 
@@ -387,13 +896,22 @@ func (s *Session) authenticate(stepper authStepper) (int64, connectOptions, erro
 		switch ph.partKind {
 		case pkAuthentication:
 			s.pr.read(auth)
+		case pkTopologyInformation:
+			s.pr.read(&s.topology)
 		case pkConnectOptions:
 			s.pr.read(&co)
-			// set data format version
+			// set data format version negotiated by the server
 			// TODO generalize for sniffer
-			s.pr.setDfv(int(co[int8(coDataFormatVersion2)].(optIntType)))
+			s.dfv = dfvLevel1
+			if dfv, ok := co[int8(coDataFormatVersion2)].(optIntType); ok {
+				s.dfv = int(dfv)
+			}
+			s.pr.setDfv(s.dfv)
 		}
 	}); err != nil {
+		if errors.Is(err, ErrPasswordChangeRequired) { // session is connected, but restricted to the password change
+			return s.pr.sessionID(), co, err
+		}
 		return 0, nil, err
 	}
 
@@ -406,7 +924,7 @@ func (s *Session) QueryDirect(query string) (driver.Rows, error) {
 	s.SetInQuery(true)
 
 	// allow e.g inserts as query -> handle commit like in ExecDirect
-	if err := s.pw.write(s.sessionID, mtExecuteDirect, !s.inTx, command(query)); err != nil {
+	if err := s.pw.writeOptions(s.sessionID, mtExecuteDirect, !s.inTx, s.queryOptions(), s.execParts(command(query))...); err != nil {
 		return nil, err
 	}
 
@@ -433,17 +951,21 @@ func (s *Session) QueryDirect(query string) (driver.Rows, error) {
 	if qr._rsID == 0 { // non select query
 		return noResult, nil
 	}
-	return newQueryResultSet(s, qr), nil
+	return newScrollableQueryResultSet(s, qr, s.stmtScroll), nil
 }
 
 // ExecDirect executes a sql statement without statement parameters.
 func (s *Session) ExecDirect(query string) (driver.Result, error) {
 	s.checkLock()
 
-	if err := s.pw.write(s.sessionID, mtExecuteDirect, !s.inTx, command(query)); err != nil {
+	if err := s.pw.write(s.sessionID, mtExecuteDirect, !s.inTx, s.execParts(command(query))...); err != nil {
 		return nil, err
 	}
+	return s.readExecReply()
+}
 
+// readExecReply reads the reply of a statement execution without lob parameters.
+func (s *Session) readExecReply() (driver.Result, error) {
 	rows := &rowsAffected{}
 	var numRow int64
 	if err := s.pr.iterateParts(func(ph *partHeader) {
@@ -464,10 +986,14 @@ func (s *Session) ExecDirect(query string) (driver.Result, error) {
 func (s *Session) Prepare(query string) (*PrepareResult, error) {
 	s.checkLock()
 
-	if err := s.pw.write(s.sessionID, mtPrepare, false, command(query)); err != nil {
+	if err := s.pw.write(s.sessionID, mtPrepare, false, s.cmdParts(command(query))...); err != nil {
 		return nil, err
 	}
+	return s.readPrepareReply()
+}
 
+// readPrepareReply reads the reply of a prepare request.
+func (s *Session) readPrepareReply() (*PrepareResult, error) {
 	pr := &PrepareResult{}
 	resMeta := &resultMetadata{}
 	prmMeta := &parameterMetadata{}
@@ -482,6 +1008,8 @@ func (s *Session) Prepare(query string) (*PrepareResult, error) {
 		case pkParameterMetadata:
 			s.pr.read(prmMeta)
 			pr.prmFields = prmMeta.parameterFields
+		case pkTableLocation:
+			s.pr.read(&pr.tableLocation)
 		}
 	}); err != nil {
 		return nil, err
@@ -490,11 +1018,170 @@ func (s *Session) Prepare(query string) (*PrepareResult, error) {
 	return pr, nil
 }
 
-// Exec executes a sql statement.
+// execPacketReserve is the part of the maximal packet size reserved for the message, segment and part headers,
+// the statement id, the statement context and the client info of an execute request.
+const execPacketReserve = 1024
+
+// MaxRowSize returns the maximal encoded size of a parameter row fitting into an execute request
+// of maximal packet size (see SessionConfig) or 0, if there is no maximal packet size.
+func (s *Session) MaxRowSize() int {
+	maxSize := s.cfg.MaxPacketSize()
+	if maxSize <= 0 {
+		return 0
+	}
+	return maxSize - execPacketReserve
+}
+
+// splitArgs splits the bulk arguments args into chunks, so that the execute request of each chunk does not
+// exceed the maximal packet size (see SessionConfig). Statements with lob parameters are not split as the lob
+// data is written in pieces after the execution. nil is returned if no splitting is needed.
+func (s *Session) splitArgs(fields []*parameterField, args []driver.NamedValue) [][]driver.NamedValue {
+	cnt := len(fields)
+	if s.cfg.MaxPacketSize() <= 0 || cnt == 0 || len(args) <= cnt {
+		return nil
+	}
+	for _, f := range fields {
+		if f.tc.isLob() {
+			return nil
+		}
+	}
+
+	limit := s.MaxRowSize()
+	var chunks [][]driver.NamedValue
+	start, size := 0, 0
+	for i := 0; i < len(args); i += cnt {
+		rowSize := newInputParameters(fields, args[i:i+cnt]).size()
+		if i > start && size+rowSize > limit {
+			chunks = append(chunks, args[start:i])
+			start, size = i, 0
+		}
+		size += rowSize
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+	return append(chunks, args[start:])
+}
+
+/*
+Exec executes a sql statement.
+Bulk executions exceeding the maximal packet size are split into multiple execute requests which are committed
+together (auto commit mode) or rolled back together in case of an error. The statement numbers of bulk errors
+refer to the rows of all requests.
+If the bulk continue on error flag is set (see SessionConfig), all requests are executed despite of rejected rows,
+the errors of all requests are returned together with the number of rows not rejected and the rows not rejected
+are committed (auto commit mode).
+*/
 func (s *Session) Exec(pr *PrepareResult, args []driver.NamedValue) (driver.Result, error) {
 	s.checkLock()
 
-	if err := s.pw.write(s.sessionID, mtExecute, !s.inTx, statementID(pr.stmtID), newInputParameters(pr.prmFields, args)); err != nil {
+	chunks := s.splitArgs(pr.prmFields, args)
+	if chunks == nil {
+		return s.exec(pr, newInputParameters(pr.prmFields, args), args, !s.inTx)
+	}
+	prms := make([]partWriter, len(chunks))
+	for i, chunk := range chunks {
+		prms[i] = newInputParameters(pr.prmFields, chunk)
+	}
+	return s.execChunks(pr, prms)
+}
+
+// execChunks executes the input parameter chunks prms of a bulk execution split into multiple execute requests (see Exec).
+func (s *Session) execChunks(pr *PrepareResult, prms []partWriter) (driver.Result, error) {
+	autoCommit := !s.inTx
+	continueOnError := s.cfg.BulkContinueOnError()
+	var numRow int64
+	var execErrs *hdbErrors // rejected rows of all chunks (continue on error)
+	offset := 0
+	for i, prm := range prms {
+		r, err := s.exec(pr, prm, nil, autoCommit && i == len(prms)-1)
+		if err != nil {
+			var hdbErrs *hdbErrors
+			isHdbErr := errors.As(err, &hdbErrs)
+			if isHdbErr {
+				hdbErrs.offsetStmtNo(offset)
+			}
+			if !isHdbErr || !continueOnError {
+				if autoCommit && !s.IsBad() {
+					s.Rollback() // rollback executions of previous chunks
+				}
+				return nil, err
+			}
+			if execErrs == nil {
+				execErrs = &hdbErrors{correlationID: hdbErrs.correlationID}
+			}
+			execErrs.append(hdbErrs) // copy as the errors part is reused by the next chunk
+		}
+		if r != nil {
+			n, _ := r.RowsAffected()
+			numRow += n
+		}
+		offset += prm.numArg()
+	}
+	if execErrs != nil {
+		if autoCommit && !s.IsBad() {
+			s.Commit() // commit rows of the last chunk not rejected
+		}
+		return driver.RowsAffected(numRow), execErrs
+	}
+	return driver.RowsAffected(numRow), nil
+}
+
+/*
+ExecColumns executes a sql statement column-wise for all rows of the argument columns (one column per parameter).
+The argument values are encoded directly from the columns into the execute requests, which are split like bulk
+executions exceeding the maximal packet size (see Exec). Columns not supporting direct encoding are converted by convert.
+*/
+func (s *Session) ExecColumns(pr *PrepareResult, columns []interface{}, convert ColumnConverter) (driver.Result, error) {
+	s.checkLock()
+
+	if len(columns) != len(pr.prmFields) {
+		return nil, fmt.Errorf("invalid number of columns %d - %d expected", len(columns), len(pr.prmFields))
+	}
+	encs := make([]columnEncoder, len(columns))
+	numRow := -1
+	for i, column := range columns {
+		f := pr.prmFields[i]
+		if f.Out() {
+			return nil, fmt.Errorf("parameter %d: output parameters are not supported by column-wise bulk executions", i)
+		}
+		enc, err := newColumnEncoder(i, f, column, convert)
+		if err != nil {
+			return nil, err
+		}
+		if numRow != -1 && enc.len() != numRow {
+			return nil, fmt.Errorf("parameter %d: invalid number of column values %d - %d expected", i, enc.len(), numRow)
+		}
+		numRow = enc.len()
+		encs[i] = enc
+	}
+	if numRow <= 0 {
+		return driver.RowsAffected(0), nil
+	}
+
+	var prms []partWriter
+	limit := s.MaxRowSize()
+	prm := &columnParameters{columns: encs}
+	size := 0
+	for row := 0; row < numRow; row++ {
+		rowSize := prm.rowSize(row)
+		if limit > 0 && row > prm.start && size+rowSize > limit {
+			prm.end = row
+			prms = append(prms, prm)
+			prm, size = &columnParameters{columns: encs, start: row}, 0
+		}
+		size += rowSize
+	}
+	prm.end = numRow
+	if len(prms) == 0 {
+		return s.exec(pr, prm, nil, !s.inTx)
+	}
+	return s.execChunks(pr, append(prms, prm))
+}
+
+// exec executes the input parameters prm, where args are the arguments of prm providing the lob content.
+func (s *Session) exec(pr *PrepareResult, prm partWriter, args []driver.NamedValue, commit bool) (driver.Result, error) {
+	if err := s.pw.write(s.sessionID, mtExecute, commit, s.execParts(statementID(pr.stmtID), prm)...); err != nil {
 		return nil, err
 	}
 
@@ -513,7 +1200,7 @@ func (s *Session) Exec(pr *PrepareResult, args []driver.NamedValue) (driver.Resu
 			ids = lobReply.ids
 		}
 	}); err != nil {
-		return nil, err
+		return driver.RowsAffected(numRow), err // rows not rejected in case of array executions
 	}
 	fc := s.pr.functionCode()
 
@@ -553,7 +1240,7 @@ func (s *Session) QueryCall(pr *PrepareResult, args []driver.NamedValue) (driver
 		}
 	}
 
-	if err := s.pw.write(s.sessionID, mtExecute, false, statementID(pr.stmtID), newInputParameters(inPrmFields, args)); err != nil {
+	if err := s.pw.write(s.sessionID, mtExecute, false, s.execParts(statementID(pr.stmtID), newInputParameters(inPrmFields, args))...); err != nil {
 		return nil, err
 	}
 
@@ -614,7 +1301,12 @@ func (s *Session) ExecCall(pr *PrepareResult, args []driver.NamedValue) (driver.
 		}
 	}
 
-	if err := s.pw.write(s.sessionID, mtExecute, false, statementID(pr.stmtID), newInputParameters(inPrmFields, inArgs)); err != nil {
+	if len(outPrmFields) != 0 {
+		// TODO release v1.0.0 - assign output parameters (call procedures with output parameters by query)
+		return nil, fmt.Errorf("not implemented yet")
+	}
+
+	if err := s.pw.write(s.sessionID, mtExecute, false, s.execParts(statementID(pr.stmtID), newInputParameters(inPrmFields, inArgs))...); err != nil {
 		return nil, err
 	}
 
@@ -641,9 +1333,7 @@ func (s *Session) ExecCall(pr *PrepareResult, args []driver.NamedValue) (driver.
 		}
 	}
 
-	// TODO release v1.0.0 - assign output parameters
-	return nil, fmt.Errorf("not implemented yet")
-	//return driver.ResultNoRows, nil
+	return driver.ResultNoRows, nil
 }
 
 func (s *Session) readCall(outputFields []*parameterField) (*callResult, []locatorID, error) {
@@ -708,7 +1398,7 @@ func (s *Session) Query(pr *PrepareResult, args []driver.NamedValue) (driver.Row
 	s.SetInQuery(true)
 
 	// allow e.g inserts as query -> handle commit like in exec
-	if err := s.pw.write(s.sessionID, mtExecute, !s.inTx, statementID(pr.stmtID), newInputParameters(pr.prmFields, args)); err != nil {
+	if err := s.pw.writeOptions(s.sessionID, mtExecute, !s.inTx, s.queryOptions(), s.execParts(statementID(pr.stmtID), newInputParameters(pr.prmFields, args))...); err != nil {
 		return nil, err
 	}
 
@@ -731,31 +1421,109 @@ func (s *Session) Query(pr *PrepareResult, args []driver.NamedValue) (driver.Row
 	if qr._rsID == 0 { // non select query
 		return noResult, nil
 	}
-	return newQueryResultSet(s, qr), nil
+	return newScrollableQueryResultSet(s, qr, s.stmtScroll), nil
+}
+
+// Session operations reported to the OpObserver.
+const (
+	OpFetch    = "fetch"    // fetch of result set rows
+	OpLobRead  = "lobRead"  // read of lob content
+	OpLobWrite = "lobWrite" // write of lob content
+)
+
+// An OpObserver is called before a session operation (see Op constants) and returns the function called
+// with the error of the operation after the operation is finished.
+type OpObserver func(op string) func(err error)
+
+// SetOpObserver sets the observer of the result set row fetches and lob reads / writes of the session.
+func (s *Session) SetOpObserver(observer OpObserver) { s.opObserver = observer }
+
+// observed calls f observed by the op observer (if set).
+func (s *Session) observed(op string, f func() error) error {
+	if s.opObserver == nil {
+		return f()
+	}
+	done := s.opObserver(op)
+	err := f()
+	done(err)
+	return err
 }
 
 // FetchNext fetches next chunk in query result set.
-func (s *Session) fetchNext(rr rowsResult) error {
+func (s *Session) fetchNext(rr rowsResult, fetchSize int) error {
 	s.checkLock()
+	return s.observed(OpFetch, func() error { return s.fetch(rr, fetchSize) })
+}
 
+func (s *Session) fetch(rr rowsResult, fetchSize int) error {
 	qr, err := rr.queryResult()
 	if err != nil {
 		return err
 	}
-	if err := s.pw.write(s.sessionID, mtFetchNext, false, resultsetID(qr._rsID), fetchsize(s.cfg.FetchSize())); err != nil {
+	fieldValues, attributes, err := s.fetchChunk(qr, fetchSize)
+	if err != nil {
 		return err
 	}
+	// all rows of the previous chunk were copied - reuse field value slice
+	qr.setChunk(fieldValues, attributes)
+	return nil
+}
 
-	resSet := &resultset{}
+// fetchChunk fetches the next chunk of the query result qr without changing qr.
+func (s *Session) fetchChunk(qr *queryResult, fetchSize int) ([]driver.Value, partAttributes, error) {
+	if fetchSize == 0 {
+		fetchSize = s.cfg.FetchSize()
+	}
+	return s.readChunk(qr, mtFetchNext, resultsetID(qr._rsID), fetchsize(fetchSize))
+}
+
+/*
+fetchScrolled replaces the current chunk of the scrollable query result qr by the chunk starting at the row given
+by message type mt (mtFetchAbsolute, mtFetchRelative, mtFetchFirst or mtFetchLast) and position pos:
+- fetch absolute: row pos (1: first row, -1: last row)
+- fetch relative: row pos relative to the last row fetched (1: row after the last row fetched)
+The position is ignored for fetching the first and the last row.
+*/
+func (s *Session) fetchScrolled(qr *queryResult, mt messageType, pos, fetchSize int) error {
+	s.checkLock()
+	if fetchSize == 0 {
+		fetchSize = s.cfg.FetchSize()
+	}
+	parts := []partWriter{resultsetID(qr._rsID)}
+	if mt == mtFetchAbsolute || mt == mtFetchRelative {
+		parts = append(parts, fetchPosition(pos))
+	}
+	parts = append(parts, fetchsize(fetchSize))
 
-	return s.pr.iterateParts(func(ph *partHeader) {
+	return s.observed(OpFetch, func() error {
+		fieldValues, attributes, err := s.readChunk(qr, mt, parts...)
+		if err != nil {
+			return err
+		}
+		qr.setChunk(fieldValues, attributes)
+		return nil
+	})
+}
+
+// readChunk sends the fetch request mt of the query result qr and reads the fetched chunk.
+func (s *Session) readChunk(qr *queryResult, mt messageType, parts ...partWriter) ([]driver.Value, partAttributes, error) {
+	if err := s.pw.write(s.sessionID, mt, false, parts...); err != nil {
+		return nil, 0, err
+	}
+
+	resSet := &resultset{resultFields: qr.fields}
+	var attributes partAttributes
+
+	if err := s.pr.iterateParts(func(ph *partHeader) {
 		if ph.partKind == pkResultset {
-			resSet.resultFields = qr.fields
 			s.pr.read(resSet)
-			qr.fieldValues = resSet.fieldValues
-			qr.attributes = ph.partAttributes
+			attributes = ph.partAttributes
 		}
-	})
+	}); err != nil {
+		freeFieldValues(resSet.fieldValues)
+		return nil, 0, err
+	}
+	return resSet.fieldValues, attributes, nil
 }
 
 // DropStatementID releases the hdb statement handle.
@@ -806,6 +1574,23 @@ func (s *Session) Rollback() error {
 	return nil
 }
 
+// DBConnectInfo returns the connect information of the database (tenant) databaseName.
+func (s *Session) DBConnectInfo(databaseName string) (*DBConnectInfo, error) {
+	s.checkLock()
+	ci := dbConnectInfo{int8(ciDatabaseName): optStringType(databaseName)}
+	if err := s.pw.write(s.sessionID, mtDBConnectInfo, false, ci); err != nil {
+		return nil, err
+	}
+	if err := s.pr.iterateParts(func(ph *partHeader) {
+		if ph.partKind == pkDBConnectInfo {
+			s.pr.read(&ci)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return &DBConnectInfo{DatabaseName: databaseName, Host: ci.host(), Port: ci.port(), IsConnected: ci.isConnected()}, nil
+}
+
 // decodeLobs decodes (reads from db) output lob or result lob parameters.
 
 // read lob reply
@@ -814,7 +1599,10 @@ func (s *Session) Rollback() error {
 func (s *Session) decodeLobs(descr *lobOutDescr, wr io.Writer) error {
 	s.Lock()
 	defer s.Unlock()
+	return s.observed(OpLobRead, func() error { return s.readLobs(descr, wr) })
+}
 
+func (s *Session) readLobs(descr *lobOutDescr, wr io.Writer) error {
 	var err error
 
 	if descr.isCharBased {
@@ -849,6 +1637,51 @@ func (s *Session) decodeLobs(descr *lobOutDescr, wr io.Writer) error {
 	return err
 }
 
+// readLobAt reads the content of the binary lob id starting at byte offset ofs into b
+// by read lob requests of at most lob chunk size bytes.
+func (s *Session) readLobAt(id locatorID, ofs int64, b []byte) (n int, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	err = s.observed(OpLobRead, func() error {
+		lobChunkSize := int(s.cfg.LobChunkSize())
+
+		lobRequest := &readLobRequest{id: id}
+		lobReply := &readLobReply{}
+
+		for n < len(b) {
+			lobRequest.ofs = ofs + int64(n)
+			lobRequest.chunkSize = int32(len(b) - n)
+			if len(b)-n > lobChunkSize {
+				lobRequest.chunkSize = int32(lobChunkSize)
+			}
+
+			if err := s.pw.write(s.sessionID, mtWriteLob, false, lobRequest); err != nil {
+				return err
+			}
+
+			if err := s.pr.iterateParts(func(ph *partHeader) {
+				if ph.partKind == pkReadLobReply {
+					s.pr.read(lobReply)
+				}
+			}); err != nil {
+				return err
+			}
+
+			if lobReply.id != lobRequest.id {
+				return fmt.Errorf("internal error: invalid lob locator %d - expected %d", lobReply.id, lobRequest.id)
+			}
+
+			n += copy(b[n:], lobReply.b)
+			if lobReply.opt.isLastData() || len(lobReply.b) == 0 {
+				return nil
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
 func (s *Session) _decodeLobs(descr *lobOutDescr, wr io.Writer, countChars func(b []byte) (int64, error)) error {
 	lobChunkSize := int64(s.cfg.LobChunkSize())
 
@@ -910,12 +1743,66 @@ func (s *Session) _decodeLobs(descr *lobOutDescr, wr io.Writer, countChars func(
 	return nil
 }
 
-// encodeLobs encodes (write to db) input lob parameters.
+/*
+readLobChunk reads the next chunk of a lob parameter from rd into b and reports whether rd is exhausted.
+Contrary to a single Read call, readLobChunk reads until b is full or rd returns io.EOF, so that readers
+returning partial reads (e.g. pipes or decompressing readers) do not cause a write lob request per Read call.
+*/
+func readLobChunk(rd io.Reader, b []byte) (int, bool, error) {
+	n, err := io.ReadFull(rd, b)
+	switch err {
+	case nil:
+		return n, false, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, true, nil
+	default:
+		return n, false, err
+	}
+}
+
+// setLobReaders sets the lob parameter readers of the current statement execution.
+func (s *Session) setLobReaders(readers []io.Reader) {
+	s.lobMu.Lock()
+	defer s.lobMu.Unlock()
+	s.lobReaders = readers
+}
+
+// interruptLobs closes the lob parameter readers of the current statement execution supporting
+// interruption (see lobInterrupter) with err. It is safe to be called concurrently to encodeLobs.
+func (s *Session) interruptLobs(err error) {
+	s.lobMu.Lock()
+	defer s.lobMu.Unlock()
+	for _, rd := range s.lobReaders {
+		if li, ok := rd.(lobInterrupter); ok {
+			li.CloseWithError(err)
+		}
+	}
+}
+
+// encodeLobs encodes input lob parameters observed by the op observer (see writeLobs).
 func (s *Session) encodeLobs(cr *callResult, ids []locatorID, inPrmFields []*parameterField, args []driver.NamedValue) error {
+	return s.observed(OpLobWrite, func() error { return s.writeLobs(cr, ids, inPrmFields, args) })
+}
+
+/*
+writeLobs encodes (write to db) input lob parameters.
+
+The lob content is read from the parameter readers and streamed to the database server in chunks of
+the configured lob chunk size until the readers are exhausted, so that the content length does not
+need to be known in advance. The memory needed is limited to one chunk per lob parameter and, as the next
+chunk is read only after the previous one is written, slow database writes apply backpressure to the readers.
+
+Readers supporting interruption (see lobInterrupter) are closed in case the execution is aborted (e.g. by a
+context cancellation or a database error), so that writers feeding the readers do not block forever.
+As the database server expects the rest of the lob content in case a reader fails, the connection gets canceled.
+*/
+func (s *Session) writeLobs(cr *callResult, ids []locatorID, inPrmFields []*parameterField, args []driver.NamedValue) (err error) {
 	chunkSize := int(s.cfg.LobChunkSize())
 
+	lobReaders := make([]io.Reader, 0, len(ids)) // parameter readers (before transformation)
 	readers := make([]io.Reader, 0, len(ids))
 	descrs := make([]*writeLobDescr, 0, len(ids))
+	bufs := make(map[*writeLobDescr][]byte, len(ids)) // chunk buffers
 
 	numInPrmField := len(inPrmFields)
 
@@ -927,6 +1814,7 @@ func (s *Session) encodeLobs(cr *callResult, ids []locatorID, inPrmFields []*par
 			if !ok {
 				return fmt.Errorf("protocol error: invalid lob parameter %[1]T %[1]v - io.Reader expected", arg.Value)
 			}
+			lobReaders = append(lobReaders, rd)
 			if f.tc.isCharBased() {
 				rd = transform.NewReader(rd, unicode.Utf8ToCesu8Transformer) // CESU8 transformer
 			}
@@ -939,6 +1827,14 @@ func (s *Session) encodeLobs(cr *callResult, ids []locatorID, inPrmFields []*par
 		}
 	}
 
+	s.setLobReaders(lobReaders)
+	defer func() {
+		if err != nil {
+			s.interruptLobs(err)
+		}
+		s.setLobReaders(nil)
+	}()
+
 	writeLobRequest := &writeLobRequest{}
 
 	for len(descrs) != 0 {
@@ -954,15 +1850,20 @@ func (s *Session) encodeLobs(cr *callResult, ids []locatorID, inPrmFields []*par
 
 		// TODO check total size limit
 		for i, descr := range descrs {
-			descr.b = make([]byte, chunkSize)
-			size, err := readers[i].Read(descr.b)
-			descr.b = descr.b[:size]
-			if err != nil && err != io.EOF {
+			b, ok := bufs[descr]
+			if !ok {
+				b = make([]byte, chunkSize)
+				bufs[descr] = b
+			}
+			size, eof, err := readLobChunk(readers[i], b)
+			descr.b = b[:size]
+			if err != nil {
+				s.conn.cancel() // the database server is waiting for the rest of the lob content
 				return err
 			}
 			descr.ofs = -1 //offset (-1 := append)
 			descr.opt = loDataincluded
-			if err == io.EOF {
+			if eof {
 				descr.opt |= loLastdata
 			}
 		}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/dlog"
+)
+
+var testNopLogger = dlog.Func(func(level dlog.Level, msg string, keyvals ...interface{}) {})
+
+func testSessionWatchCancel(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+
+	s := &Session{logger: testNopLogger, conn: &dbConn{conn: c1, logger: testNopLogger}}
+	s.Lock()
+	defer s.Unlock()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := s.conn.Read(make([]byte, 1)); err != driver.ErrBadConn { // blocks until canceled
+		t.Fatalf("error %v - expected %v", err, driver.ErrBadConn)
+	}
+	s.Unwatch()
+	if !s.IsBad() {
+		t.Fatal("canceled session is not bad")
+	}
+	if err := s.Watch(ctx); err != context.Canceled {
+		t.Fatalf("error %v - expected %v", err, context.Canceled)
+	}
+}
+
+func testSessionWatchFinished(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c2.Close()
+
+	s := &Session{logger: testNopLogger, conn: &dbConn{conn: c1, logger: testNopLogger}}
+	s.Lock()
+	defer s.Unlock()
+	defer s.Close()
+
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := s.Watch(ctx); err != nil {
+			t.Fatal(err)
+		}
+		s.Unwatch()
+		cancel()
+	}
+	go c2.Read(make([]byte, 1))
+	if _, err := s.conn.Write([]byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsBad() {
+		t.Fatal("session is bad")
+	}
+}
+
+func TestSession(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"watchCancel", testSessionWatchCancel},
+		{"watchFinished", testSessionWatchFinished},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
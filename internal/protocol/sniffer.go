@@ -44,7 +44,6 @@ type Sniffer struct {
 func NewSniffer(conn net.Conn, dbConn net.Conn) *Sniffer {
 
 	//TODO - review setting values here
-	trace = true
 	debug = true
 
 	s := &Sniffer{
@@ -61,8 +60,10 @@ func NewSniffer(conn net.Conn, dbConn net.Conn) *Sniffer {
 	//read from db and write to client connection buffer
 	s.dbRd = bufio.NewReader(io.TeeReader(dbConn, s.clWr))
 
-	s.upRd = newSniffUpReader(s.clRd)
-	s.downRd = newSniffDownReader(s.dbRd, s.upRd)
+	ts := newTraceState(true, nil)
+
+	s.upRd = newSniffUpReader(s.clRd, ts.traceLogger(true))
+	s.downRd = newSniffDownReader(s.dbRd, s.upRd, ts.traceLogger(false))
 
 	return s
 }
@@ -109,14 +110,14 @@ type sniffReader struct {
 	pr *protocolReader
 }
 
-func newSniffReader(upStream bool, rd *bufio.Reader) *sniffReader {
-	return &sniffReader{pr: newProtocolReader(upStream, rd)}
+func newSniffReader(upStream bool, rd *bufio.Reader, tracer traceLogger) *sniffReader {
+	return &sniffReader{pr: newProtocolReader(upStream, rd, tracer)}
 }
 
 type sniffUpReader struct{ *sniffReader }
 
-func newSniffUpReader(rd *bufio.Reader) *sniffUpReader {
-	return &sniffUpReader{sniffReader: newSniffReader(true, rd)}
+func newSniffUpReader(rd *bufio.Reader, tracer traceLogger) *sniffUpReader {
+	return &sniffUpReader{sniffReader: newSniffReader(true, rd, tracer)}
 }
 
 type resMetaCache struct {
@@ -181,9 +182,9 @@ type sniffDownReader struct {
 	prmMeta *parameterMetadata
 }
 
-func newSniffDownReader(rd *bufio.Reader, upRd *sniffUpReader) *sniffDownReader {
+func newSniffDownReader(rd *bufio.Reader, upRd *sniffUpReader, tracer traceLogger) *sniffDownReader {
 	return &sniffDownReader{
-		sniffReader: newSniffReader(false, rd),
+		sniffReader: newSniffReader(false, rd, tracer),
 		resMeta:     &resultMetadata{},
 		prmMeta:     &parameterMetadata{},
 	}
@@ -6,6 +6,7 @@ package protocol
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
@@ -20,8 +21,38 @@ func (c statementContext) String() string {
 	return fmt.Sprintf("options %s", typedSc)
 }
 
+func (c statementContext) size() int   { return plainOptions(c).size() }
+func (c statementContext) numArg() int { return len(c) }
+
 func (c *statementContext) decode(dec *encoding.Decoder, ph *partHeader) error {
 	*c = statementContext{} // no reuse of maps - create new one
 	plainOptions(*c).decode(dec, ph.numArg())
 	return dec.Error()
 }
+
+// serverExecutionTime returns the server processing time of the statement.
+func (c statementContext) serverExecutionTime() time.Duration {
+	if v, ok := c[int8(scServerExecutionTime)].(optBigintType); ok {
+		return time.Duration(v) * time.Microsecond // server execution time is provided in microseconds
+	}
+	return 0
+}
+
+func (c statementContext) encode(enc *encoding.Encoder) error {
+	plainOptions(c).encode(enc)
+	return nil
+}
+
+// queryTimeout returns the server query timeout of the statement.
+func (c statementContext) queryTimeout() time.Duration {
+	if v, ok := c[int8(scQueryTimeout)].(optBigintType); ok {
+		return time.Duration(v) * time.Second // query timeout is provided in seconds
+	}
+	return 0
+}
+
+// newQueryTimeoutContext returns a statement context setting the server query timeout
+// (rounded up to full seconds).
+func newQueryTimeoutContext(timeout time.Duration) statementContext {
+	return statementContext{int8(scQueryTimeout): optBigintType((timeout + time.Second - 1) / time.Second)}
+}
@@ -9,6 +9,12 @@ package protocol
 type statementContextType int8
 
 const (
-	scStatementSequenceInfo statementContextType = 1
-	scServerExecutionTime   statementContextType = 2
+	scStatementSequenceInfo         statementContextType = 1
+	scServerExecutionTime           statementContextType = 2
+	scSchemaName                    statementContextType = 3
+	scFlagSet                       statementContextType = 4
+	scQueryTimeout                  statementContextType = 5
+	scClientReconnectionWaitTimeout statementContextType = 6
+	scServerCPUTime                 statementContextType = 7
+	scServerMemoryUsage             statementContextType = 8
 )
@@ -10,11 +10,17 @@ func _() {
 	var x [1]struct{}
 	_ = x[scStatementSequenceInfo-1]
 	_ = x[scServerExecutionTime-2]
+	_ = x[scSchemaName-3]
+	_ = x[scFlagSet-4]
+	_ = x[scQueryTimeout-5]
+	_ = x[scClientReconnectionWaitTimeout-6]
+	_ = x[scServerCPUTime-7]
+	_ = x[scServerMemoryUsage-8]
 }
 
-const _statementContextType_name = "scStatementSequenceInfoscServerExecutionTime"
+const _statementContextType_name = "scStatementSequenceInfoscServerExecutionTimescSchemaNamescFlagSetscQueryTimeoutscClientReconnectionWaitTimeoutscServerCPUTimescServerMemoryUsage"
 
-var _statementContextType_index = [...]uint8{0, 23, 44}
+var _statementContextType_index = [...]uint8{0, 23, 44, 56, 65, 79, 110, 125, 144}
 
 func (i statementContextType) String() string {
 	i -= 1
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"sync/atomic"
+)
+
+/*
+SessionStats collects network statistics of sessions.
+
+The counters are updated atomically and are additionally added to the counters of
+the parent statistics (if any), so that statistics can be aggregated e.g. per connector.
+*/
+type SessionStats struct {
+	// 64-bit counters first to guarantee atomic alignment on 32-bit platforms.
+	bytesRead    uint64
+	bytesWritten uint64
+	roundTrips   uint64
+
+	parent *SessionStats
+}
+
+// NewSessionStats returns a new SessionStats instance aggregating its counters to parent (nil: no parent).
+func NewSessionStats(parent *SessionStats) *SessionStats { return &SessionStats{parent: parent} }
+
+// BytesRead returns the number of protocol bytes read from the database server.
+func (s *SessionStats) BytesRead() uint64 { return atomic.LoadUint64(&s.bytesRead) }
+
+// BytesWritten returns the number of protocol bytes written to the database server.
+func (s *SessionStats) BytesWritten() uint64 { return atomic.LoadUint64(&s.bytesWritten) }
+
+// RoundTrips returns the number of request / reply round trips to the database server.
+func (s *SessionStats) RoundTrips() uint64 { return atomic.LoadUint64(&s.roundTrips) }
+
+func (s *SessionStats) addRead(n int) {
+	for ; s != nil; s = s.parent {
+		atomic.AddUint64(&s.bytesRead, uint64(n))
+	}
+}
+
+// addWritten adds the bytes of a request message - as each request is answered by a reply
+// the round trips are counted as well.
+func (s *SessionStats) addWritten(n int) {
+	for ; s != nil; s = s.parent {
+		atomic.AddUint64(&s.bytesWritten, uint64(n))
+		atomic.AddUint64(&s.roundTrips, 1)
+	}
+}
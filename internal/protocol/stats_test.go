@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/container/varmap"
+)
+
+func TestSessionStats(t *testing.T) {
+	var buf bytes.Buffer
+
+	parent := NewSessionStats(nil)
+	stats := NewSessionStats(parent)
+
+	ts := newTraceState(false, nil)
+	pw := newProtocolWriter(newBufferedWriter(&buf, 0, false), varmap.NewVarMap(), ts.traceLogger(true))
+	pw.stats = stats
+	pr := newProtocolReader(true, &buf, ts.traceLogger(false))
+	pr.stats = stats
+
+	const numMsg = 3
+	query := command("select * from dummy")
+	for i := 0; i < numMsg; i++ {
+		if err := pw.write(1, mtExecuteDirect, false, query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	size := uint64(buf.Len())
+	for i := 0; i < numMsg; i++ {
+		if err := pr.readSkip(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, s := range []*SessionStats{stats, parent} {
+		if s.BytesWritten() != size {
+			t.Fatalf("bytes written %d - expected %d", s.BytesWritten(), size)
+		}
+		if s.BytesRead() != size {
+			t.Fatalf("bytes read %d - expected %d", s.BytesRead(), size)
+		}
+		if s.RoundTrips() != numMsg {
+			t.Fatalf("round trips %d - expected %d", s.RoundTrips(), numMsg)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/SAP/go-hdb/internal/protocol/encoding"
+)
+
+// table location: volume ids of the tables accessed by a prepared statement (scale-out statement routing)
+type tableLocation []int32
+
+func (l tableLocation) String() string { return fmt.Sprintf("volume ids %v", []int32(l)) }
+func (l *tableLocation) decode(dec *encoding.Decoder, ph *partHeader) error {
+	numArg := ph.numArg()
+	*l = make(tableLocation, numArg)
+	for i := 0; i < numArg; i++ {
+		(*l)[i] = dec.Int32()
+	}
+	return dec.Error()
+}
+func (l tableLocation) encode(enc *encoding.Encoder) error {
+	for _, id := range l {
+		enc.Int32(id)
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ package protocol
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 
 	"github.com/SAP/go-hdb/internal/protocol/encoding"
 )
@@ -28,3 +30,73 @@ func (o *topologyInformation) decode(dec *encoding.Decoder, ph *partHeader) erro
 	(*multiLineOptions)(o).decode(dec, ph.numArg())
 	return dec.Error()
 }
+
+// site types of the topology information (system replication)
+const (
+	siteTypePrimary   = 1
+	siteTypeSecondary = 2
+)
+
+// A TopologyHost is a database host of the topology information returned by the server at connect time.
+type TopologyHost struct {
+	Host           string
+	Port           int
+	VolumeID       int  // volume id of the index server (0: none)
+	Master         bool // master index server
+	CurrentSession bool // host of the current session
+	Standby        bool // standby host
+	Secondary      bool // read-enabled secondary site of an Active/Active (read enabled) system replication
+}
+
+// Address returns the address ("host:port") of the host.
+func (h TopologyHost) Address() string { return net.JoinHostPort(h.Host, strconv.Itoa(h.Port)) }
+
+func (o topologyInformation) hosts() []TopologyHost {
+	hosts := make([]TopologyHost, 0, len(o))
+	for _, po := range o {
+		var h TopologyHost
+		if v, ok := po[int8(toHostName)].(optStringType); ok {
+			h.Host = string(v)
+		}
+		if v, ok := po[int8(toHostPortnumber)].(optIntType); ok {
+			h.Port = int(v)
+		}
+		if v, ok := po[int8(toVolumeID)].(optIntType); ok {
+			h.VolumeID = int(v)
+		}
+		h.Master = topologyBool(po, toIsMaster)
+		h.CurrentSession = topologyBool(po, toIsCurrentSession)
+		h.Standby = topologyBool(po, toIsStandby)
+		if v, ok := po[int8(toSiteType)].(optIntType); ok {
+			h.Secondary = v == siteTypeSecondary
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+func topologyBool(po plainOptions, k topologyOption) bool {
+	v, ok := po[int8(k)].(optBooleanType)
+	return ok && bool(v)
+}
+
+// newTopologyInformation returns the topology information of hosts (server).
+func newTopologyInformation(hosts []TopologyHost, currentAddress string) topologyInformation {
+	o := make(topologyInformation, len(hosts))
+	for i, h := range hosts {
+		siteType := siteTypePrimary
+		if h.Secondary {
+			siteType = siteTypeSecondary
+		}
+		o[i] = plainOptions{
+			int8(toHostName):         optStringType(h.Host),
+			int8(toHostPortnumber):   optIntType(h.Port),
+			int8(toVolumeID):         optIntType(h.VolumeID),
+			int8(toIsMaster):         optBooleanType(h.Master),
+			int8(toIsCurrentSession): optBooleanType(h.Address() == currentAddress),
+			int8(toIsStandby):        optBooleanType(h.Standby),
+			int8(toSiteType):         optIntType(siteType),
+		}
+	}
+	return o
+}
@@ -21,4 +21,5 @@ const (
 	toIsStandby        topologyOption = 10
 	toAllIPAddresses   topologyOption = 11
 	toAllHostNames     topologyOption = 12
+	toSiteType         topologyOption = 13
 )
@@ -20,11 +20,12 @@ func _() {
 	_ = x[toIsStandby-10]
 	_ = x[toAllIPAddresses-11]
 	_ = x[toAllHostNames-12]
+	_ = x[toSiteType-13]
 }
 
-const _topologyOption_name = "toHostNametoHostPortnumbertoTenantNametoLoadfactortoVolumeIDtoIsMastertoIsCurrentSessiontoServiceTypetoNetworkDomaintoIsStandbytoAllIPAddressestoAllHostNames"
+const _topologyOption_name = "toHostNametoHostPortnumbertoTenantNametoLoadfactortoVolumeIDtoIsMastertoIsCurrentSessiontoServiceTypetoNetworkDomaintoIsStandbytoAllIPAddressestoAllHostNamestoSiteType"
 
-var _topologyOption_index = [...]uint8{0, 10, 26, 38, 50, 60, 70, 88, 101, 116, 127, 143, 157}
+var _topologyOption_index = [...]uint8{0, 10, 26, 38, 50, 60, 70, 88, 101, 116, 127, 143, 157, 167}
 
 func (i topologyOption) String() string {
 	i -= 1
@@ -6,6 +6,7 @@ package protocol
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -86,7 +87,12 @@ const (
 )
 
 func (tc typeCode) isLob() bool {
-	return tc == tcClob || tc == tcNclob || tc == tcBlob || tc == tcText || tc == tcBintext || tc == tcLocator || tc == tcNlocator
+	return tc == tcClob || tc == tcNclob || tc == tcBlob || tc == tcText || tc == tcBintext || tc == tcLocator || tc == tcNlocator || tc.isSpatial()
+}
+
+// isSpatial returns true for the spatial types, which are transferred as lobs in well-known binary format.
+func (tc typeCode) isSpatial() bool {
+	return tc == tcStGeometry || tc == tcStPoint
 }
 
 func (tc typeCode) isCharBased() bool {
@@ -94,7 +100,7 @@ func (tc typeCode) isCharBased() bool {
 }
 
 func (tc typeCode) isVariableLength() bool {
-	return tc == tcChar || tc == tcNchar || tc == tcVarchar || tc == tcNvarchar || tc == tcBinary || tc == tcVarbinary || tc == tcShorttext || tc == tcAlphanum
+	return tc == tcChar || tc == tcNchar || tc == tcVarchar || tc == tcNvarchar || tc == tcString || tc == tcNstring || tc == tcBinary || tc == tcVarbinary || tc == tcShorttext || tc == tcAlphanum
 }
 
 func (tc typeCode) isIntegerType() bool {
@@ -102,7 +108,12 @@ func (tc typeCode) isIntegerType() bool {
 }
 
 func (tc typeCode) isDecimalType() bool {
-	return tc == tcSmalldecimal || tc == tcDecimal
+	return tc == tcSmalldecimal || tc == tcDecimal || tc.isFixedType()
+}
+
+// isFixedType returns true for the decimal types with precision and scale of data format version 8.
+func (tc typeCode) isFixedType() bool {
+	return tc == tcFixed8 || tc == tcFixed12 || tc == tcFixed16
 }
 
 //
@@ -155,6 +166,9 @@ var dataTypeMap = map[typeCode]DataType{
 	tcDaydate:    DtTime,
 	tcSecondtime: DtTime,
 	tcDecimal:    DtDecimal,
+	tcFixed8:     DtDecimal,
+	tcFixed12:    DtDecimal,
+	tcFixed16:    DtDecimal,
 	tcChar:       DtString,
 	tcVarchar:    DtString,
 	tcString:     DtString,
@@ -170,6 +184,8 @@ var dataTypeMap = map[typeCode]DataType{
 	tcNclob:      DtLob,
 	tcText:       DtLob,
 	tcBintext:    DtLob,
+	tcStGeometry: DtLob,
+	tcStPoint:    DtLob,
 	tcTableRef:   DtString,
 	tcTableRows:  DtRows,
 }
@@ -186,9 +202,43 @@ func (tc typeCode) dataType() DataType {
 // typeName returns the database type name.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeDatabaseTypeName
 func (tc typeCode) typeName() string {
+	if tc.isFixedType() { // wire format of decimals with precision and scale
+		return tcDecimal.typeName()
+	}
+	switch tc {
+	case tcStGeometry:
+		return "ST_GEOMETRY"
+	case tcStPoint:
+		return "ST_POINT"
+	}
 	return strings.ToUpper(tc.String()[2:])
 }
 
+// typeLength returns the type length of fields of type code tc with field length length, which is the length
+// defined in the database for variable length types and math.MaxInt64 (no length limit) for lob types.
+// see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeLength
+func (tc typeCode) typeLength(length int16) (int64, bool) {
+	switch {
+	case tc.isVariableLength():
+		return int64(length), true
+	case tc.isLob():
+		return math.MaxInt64, true
+	default:
+		return 0, false
+	}
+}
+
+// typePrecisionScale returns the type precision and scale of decimal fields of type code tc, which are transferred
+// as field length and fraction. Floating point decimals (DECIMAL and SMALLDECIMAL without precision and scale) are
+// reported with their maximum precision and scale 32767.
+// see https://golang.org/pkg/database/sql/driver/#RowsColumnTypePrecisionScale
+func (tc typeCode) typePrecisionScale(length, fraction int16) (int64, int64, bool) {
+	if tc.isDecimalType() {
+		return int64(length), int64(fraction), true
+	}
+	return 0, 0, false
+}
+
 var tcFieldTypeMap = map[typeCode]fieldType{
 	tcBoolean:    booleanType,
 	tcTinyint:    tinyintType,
@@ -205,6 +255,9 @@ var tcFieldTypeMap = map[typeCode]fieldType{
 	tcDaydate:    daydateType,
 	tcSecondtime: secondtimeType,
 	tcDecimal:    decimalType,
+	tcFixed8:     fixed8Type,
+	tcFixed12:    fixed12Type,
+	tcFixed16:    fixed16Type,
 	tcChar:       varType,
 	tcVarchar:    varType,
 	tcString:     varType,
@@ -224,6 +277,9 @@ var tcFieldTypeMap = map[typeCode]fieldType{
 	tcLocator: lobCESU8Type,
 	//tcNlocator: lobCESU8Type,
 	tcNlocator: lobVarType,
+
+	tcStGeometry: lobVarType,
+	tcStPoint:    lobVarType,
 }
 
 func (tc typeCode) fieldType() fieldType {
@@ -233,3 +289,24 @@ func (tc typeCode) fieldType() fieldType {
 	}
 	return f
 }
+
+// scaledFieldType returns the field type of fields with scale (fraction) scale.
+func (tc typeCode) scaledFieldType(scale int) fieldType {
+	ft := tc.fieldType()
+	if ft, ok := ft.(_fixedType); ok {
+		return ft.withScale(scale)
+	}
+	return ft
+}
+
+// TypeConverter returns the converter and the driver data type of the database type typeName (e.g. NVARCHAR, DECIMAL).
+// The boolean return value is false, if the database type is not supported.
+func TypeConverter(typeName string) (Converter, DataType, bool) {
+	typeName = strings.ToUpper(typeName)
+	for tc, ft := range tcFieldTypeMap {
+		if !tc.isFixedType() && tc.typeName() == typeName {
+			return ft, tc.dataType(), true
+		}
+	}
+	return nil, DtUnknown, false
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrProtocolValidation is the error wrapped by protocol violations detected in strict protocol validation mode.
+var ErrProtocolValidation = errors.New("protocol validation failed")
+
+// validationError is a protocol violation including the protocol position it was detected at.
+type validationError struct {
+	text string
+	pos  string
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", ErrProtocolValidation, e.text, e.pos)
+}
+
+// Unwrap returns ErrProtocolValidation.
+func (e *validationError) Unwrap() error { return ErrProtocolValidation }
+
+/*
+In strict validation mode the protocol reader
+- decodes all parts instead of skipping the ones not needed,
+- fails on unknown part kinds and type codes,
+- checks message, segment and part sizes and argument counts and
+- fails if a part decoder does not consume exactly the part buffer length
+instead of best-effort parsing. As the protocol position is undefined after a violation, the session becomes bad.
+*/
+
+// violation returns a validation error at the actual protocol position and marks the reader as invalid.
+func (r *protocolReader) violation(format string, args ...interface{}) error {
+	err := &validationError{text: fmt.Sprintf(format, args...), pos: r.position()}
+	r.validationErr = err
+	return err
+}
+
+// invalid returns true if a protocol violation was detected.
+func (r *protocolReader) invalid() bool { return r != nil && r.validationErr != nil }
+
+// position returns a description of the actual protocol position for diagnostics.
+func (r *protocolReader) position() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "message sessionID %d varPartLength %d noOfSegm %d", r.mh.sessionID, r.mh.varPartLength, r.mh.noOfSegm)
+	if r.cntSegm != 0 {
+		fmt.Fprintf(b, " - segment %d/%d kind %s functionCode %s segmentLength %d noOfParts %d",
+			r.cntSegm, r.mh.noOfSegm, r.sh.segmentKind, r.sh.functionCode, r.sh.segmentLength, r.sh.noOfParts)
+	}
+	if r.cntPart != 0 {
+		fmt.Fprintf(b, " - part %d/%d %s", r.cntPart, r.numPart, r.ph)
+	}
+	return b.String()
+}
+
+func (r *protocolReader) validateMessageHeader() error {
+	switch {
+	case r.mh.noOfSegm <= 0:
+		return r.violation("invalid number of segments %d", r.mh.noOfSegm)
+	case r.mh.compressed() && r.mh.compressionVarPartLength == 0:
+		return r.violation("compressed message without uncompressed length")
+	}
+	return nil
+}
+
+// validateSegmentHeader validates the segment header against the remaining message size msgSize.
+func (r *protocolReader) validateSegmentHeader(msgSize int64) error {
+	switch {
+	case r.sh.segmentLength < segmentHeaderSize:
+		return r.violation("segment length %d smaller than segment header size %d", r.sh.segmentLength, segmentHeaderSize)
+	case int64(r.sh.segmentLength) > msgSize:
+		return r.violation("segment length %d exceeds remaining message size %d", r.sh.segmentLength, msgSize)
+	case r.sh.noOfParts < 0:
+		return r.violation("invalid number of parts %d", r.sh.noOfParts)
+	case r.upStream && r.sh.segmentKind != skRequest:
+		return r.violation("invalid request segment kind %s", r.sh.segmentKind)
+	case !r.upStream && r.sh.segmentKind != skReply && r.sh.segmentKind != skError:
+		return r.violation("invalid reply segment kind %s", r.sh.segmentKind)
+	}
+	r.segSize = int64(r.sh.segmentLength) - segmentHeaderSize
+	return nil
+}
+
+// validatePartHeader validates the part header against the remaining segment size.
+func (r *protocolReader) validatePartHeader() error {
+	switch {
+	case r.ph.bigArgumentCount != 0:
+		return r.violation("big argument count %d not supported", r.ph.bigArgumentCount)
+	case r.ph.argumentCount < 0:
+		return r.violation("invalid argument count %d", r.ph.argumentCount)
+	case r.ph.bufferLength < 0:
+		return r.violation("invalid buffer length %d", r.ph.bufferLength)
+	case int64(partHeaderSize+r.ph.bufferLength) > r.segSize:
+		return r.violation("part size %d exceeds remaining segment size %d", partHeaderSize+r.ph.bufferLength, r.segSize)
+	}
+	r.segSize -= int64(partHeaderSize + int(r.ph.bufferLength) + padBytes(int(r.ph.bufferLength)))
+	return nil
+}
+
+// validatePart validates the decoded part.
+func (r *protocolReader) validatePart(part partReader, cnt int) error {
+	if cnt != int(r.ph.bufferLength) {
+		return r.violation("part decoder read %d bytes - expected buffer length %d", cnt, r.ph.bufferLength)
+	}
+	switch part := part.(type) {
+	case *resultMetadata:
+		for i, f := range part.resultFields {
+			if _, ok := tcFieldTypeMap[f.tc]; !ok {
+				return r.violation("unknown type code %s of result field %d %s", f.tc, i, f.columnDisplayName)
+			}
+		}
+	case *parameterMetadata:
+		for i, f := range part.parameterFields {
+			if _, ok := tcFieldTypeMap[f.tc]; !ok {
+				return r.violation("unknown type code %s of parameter field %d %s", f.tc, i, f.name)
+			}
+		}
+	}
+	return nil
+}
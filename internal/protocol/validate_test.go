@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/SAP/go-hdb/internal/container/varmap"
+)
+
+// request message offsets
+const (
+	testSegmentLengthOffset = messageHeaderSize
+	testSegmentKindOffset   = messageHeaderSize + 12
+	testPartKindOffset      = messageHeaderSize + segmentHeaderSize
+	testArgumentCountOffset = testPartKindOffset + 2
+)
+
+func testValidateMessage(t *testing.T) []byte {
+	var buf bytes.Buffer
+	ts := newTraceState(false, nil)
+	pw := newProtocolWriter(newBufferedWriter(&buf, 0, false), varmap.NewVarMap(), ts.traceLogger(true))
+	if err := pw.write(1, mtExecuteDirect, false, command("select * from dummy")); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testValidateRead(b []byte, strict bool) (*protocolReader, error) {
+	ts := newTraceState(false, nil)
+	pr := newProtocolReader(true, bytes.NewReader(b), ts.traceLogger(false))
+	pr.strict = strict
+	return pr, pr.iterateParts(func(ph *partHeader) {
+		if ph.partKind == pkCommand {
+			var cmd command
+			pr.read(&cmd)
+		}
+	})
+}
+
+func testValidateValid(t *testing.T) {
+	pr, err := testValidateRead(testValidateMessage(t), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.invalid() {
+		t.Fatal("reader is invalid - expected valid")
+	}
+}
+
+func testValidateViolation(t *testing.T, modify func(b []byte), bestEffort bool) {
+	b := testValidateMessage(t)
+	modify(b)
+
+	pr, err := testValidateRead(b, true)
+	if !errors.Is(err, ErrProtocolValidation) {
+		t.Fatalf("error %v - expected %v", err, ErrProtocolValidation)
+	}
+	if !pr.invalid() {
+		t.Fatal("reader is valid - expected invalid")
+	}
+	t.Log(err) // diagnostics
+
+	if bestEffort { // best-effort parsing does not detect the violation
+		if _, err := testValidateRead(b, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"valid", testValidateValid},
+		{"segmentKind", func(t *testing.T) {
+			testValidateViolation(t, func(b []byte) { b[testSegmentKindOffset] = byte(skReply) }, false)
+		}},
+		{"segmentLength", func(t *testing.T) {
+			testValidateViolation(t, func(b []byte) {
+				binary.LittleEndian.PutUint32(b[testSegmentLengthOffset:], uint32(len(b)))
+			}, true)
+		}},
+		{"argumentCount", func(t *testing.T) {
+			testValidateViolation(t, func(b []byte) {
+				binary.LittleEndian.PutUint16(b[testArgumentCountOffset:], 0xffff) // -1
+			}, true)
+		}},
+		{"partKind", func(t *testing.T) {
+			testValidateViolation(t, func(b []byte) { b[testPartKindOffset] = 0x7f }, true)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
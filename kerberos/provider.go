@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package kerberos provides a Kerberos GSS provider for the go-hdb driver built on gokrb5 (github.com/jcmturner/gokrb5).
+
+	provider, err := kerberos.NewProvider(&kerberos.Config{Principal: "myuser@EXAMPLE.COM", Keytab: "/etc/myuser.keytab"})
+	...
+	defer provider.Close()
+	db := sql.OpenDB(driver.NewKerberosAuthConnector("host:port", provider))
+
+The provider is a separate module, so that the go-hdb driver module does not depend on a Kerberos implementation.
+The client principal is authenticated either by a keytab or by the tickets of a credential cache (e.g. created by kinit).
+*/
+package kerberos
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// Default locations of the Kerberos configuration and the credential cache.
+const (
+	defaultKrb5Conf = "/etc/krb5.conf"
+	ccachePrefix    = "/tmp/krb5cc_"
+)
+
+// ErrPrincipalMissing is returned by NewProvider if a keytab is configured without a client principal.
+var ErrPrincipalMissing = errors.New("kerberos: client principal missing")
+
+// Config is the configuration of a Kerberos provider.
+type Config struct {
+	Principal string // client principal (user or user@REALM, default realm: default_realm of Krb5Conf); used with Keytab
+	Keytab    string // keytab file of the client principal (empty: tickets of CCache are used)
+	CCache    string // credential cache file (default: KRB5CCNAME or /tmp/krb5cc_<uid>)
+	Krb5Conf  string // Kerberos configuration file (default: KRB5_CONFIG or /etc/krb5.conf)
+}
+
+// Provider is a Kerberos GSS provider (see driver.GSSProvider).
+type Provider struct {
+	cl *client.Client
+	// serviceTicket returns the ticket and session key of a service principal (replaced by tests).
+	serviceTicket func(spn string) (messages.Ticket, types.EncryptionKey, error)
+}
+
+// check if Provider implements the driver.GSSProvider interface.
+var _ driver.GSSProvider = (*Provider)(nil)
+
+// NewProvider returns a Kerberos provider for the client principal of config.
+// With a keytab the client principal is logged in at the KDC immediately, otherwise the ticket
+// granting ticket is read from the credential cache.
+func NewProvider(cfg *Config) (*Provider, error) {
+	krb5Conf, err := config.Load(krb5ConfPath(cfg.Krb5Conf))
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: load configuration: %w", err)
+	}
+
+	var cl *client.Client
+	if cfg.Keytab != "" {
+		if cfg.Principal == "" {
+			return nil, ErrPrincipalMissing
+		}
+		kt, err := keytab.Load(cfg.Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("kerberos: load keytab: %w", err)
+		}
+		username, realm := splitPrincipal(cfg.Principal)
+		if realm == "" {
+			realm = krb5Conf.LibDefaults.DefaultRealm
+		}
+		cl = client.NewWithKeytab(username, realm, kt, krb5Conf, client.DisablePAFXFAST(true))
+		if err := cl.Login(); err != nil {
+			return nil, fmt.Errorf("kerberos: login: %w", err)
+		}
+	} else {
+		cc, err := credentials.LoadCCache(ccachePath(cfg.CCache))
+		if err != nil {
+			return nil, fmt.Errorf("kerberos: load credential cache: %w", err)
+		}
+		if cl, err = client.NewFromCCache(cc, krb5Conf, client.DisablePAFXFAST(true)); err != nil {
+			return nil, fmt.Errorf("kerberos: credential cache: %w", err)
+		}
+	}
+	return newProvider(cl), nil
+}
+
+func newProvider(cl *client.Client) *Provider {
+	return &Provider{cl: cl, serviceTicket: cl.GetServiceTicket}
+}
+
+// Close destroys the Kerberos session of the provider.
+func (p *Provider) Close() { p.cl.Destroy() }
+
+// InitSecContext implements the driver.GSSProvider interface.
+//
+// The initial token is the Kerberos AP-REQ for the service principal spn. A realm of spn
+// (hdb/host@REALM) is ignored, the service realm is determined by the domain_realm mapping of the
+// Kerberos configuration. The reply token of the server is only checked for Kerberos errors.
+func (p *Provider) InitSecContext(spn string, inputToken []byte) ([]byte, error) {
+	if inputToken != nil {
+		if len(inputToken) == 0 {
+			return nil, nil
+		}
+		var token spnego.KRB5Token
+		if token.Unmarshal(inputToken) == nil && token.IsKRBError() {
+			return nil, fmt.Errorf("kerberos: %w", token.KRBError)
+		}
+		return nil, nil
+	}
+
+	serviceName, _ := splitPrincipal(spn)
+	tkt, key, err := p.serviceTicket(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: service ticket %s: %w", spn, err)
+	}
+	token, err := spnego.NewKRB5TokenAPREQ(p.cl, tkt, key, []int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf}, []int{})
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: AP-REQ %s: %w", spn, err)
+	}
+	return token.Marshal()
+}
+
+// splitPrincipal splits a principal name into name and realm.
+func splitPrincipal(principal string) (name, realm string) {
+	if i := strings.LastIndexByte(principal, '@'); i != -1 {
+		return principal[:i], principal[i+1:]
+	}
+	return principal, ""
+}
+
+func krb5ConfPath(path string) string {
+	if path != "" {
+		return path
+	}
+	if path, ok := os.LookupEnv("KRB5_CONFIG"); ok && path != "" {
+		return path
+	}
+	return defaultKrb5Conf
+}
+
+func ccachePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if name, ok := os.LookupEnv("KRB5CCNAME"); ok && name != "" {
+		return strings.TrimPrefix(name, "FILE:")
+	}
+	return fmt.Sprintf("%s%d", ccachePrefix, os.Getuid())
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2014-2020 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+// +build unit
+
+package kerberos
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/drivertest"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/test/testdata"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	testRealm   = "TEST.GOKRB5"
+	testService = "HTTP/host.test.gokrb5"
+)
+
+func loadTestKeytab(t *testing.T, s string) *keytab.Keytab {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kt := keytab.New()
+	if err := kt.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	return kt
+}
+
+// newTestProvider returns a provider of testuser1 issuing service tickets itself (no KDC needed).
+func newTestProvider(t *testing.T, serviceKeytab *keytab.Keytab) (*Provider, *[]string) {
+	krb5Conf, err := config.NewFromString(testdata.KRB5_CONF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := client.NewWithKeytab("testuser1", testRealm, loadTestKeytab(t, testdata.KEYTAB_TESTUSER1_TEST_GOKRB5), krb5Conf)
+
+	var mu sync.Mutex
+	var spns []string
+	p := newProvider(cl)
+	p.serviceTicket = func(spn string) (messages.Ticket, types.EncryptionKey, error) {
+		mu.Lock()
+		spns = append(spns, spn)
+		mu.Unlock()
+		now := time.Now().UTC()
+		return messages.NewTicket(cl.Credentials.CName(), cl.Credentials.Domain(),
+			types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, spn), testRealm, types.NewKrbFlags(),
+			serviceKeytab, etypeID.AES256_CTS_HMAC_SHA1_96, 1, now, now, now.Add(time.Hour), now.Add(time.Hour))
+	}
+	return p, &spns
+}
+
+func TestMockProvider(t *testing.T) {
+	serviceKeytab := loadTestKeytab(t, testdata.HTTP_KEYTAB)
+
+	s := drivertest.NewTestMockServer(t)
+	defer s.Close()
+	s.SetGSSAcceptor(func(b []byte) (string, []byte, error) {
+		var token spnego.KRB5Token
+		if err := token.Unmarshal(b); err != nil {
+			return "", nil, err
+		}
+		if !token.IsAPReq() {
+			return "", nil, errors.New("AP-REQ expected")
+		}
+		if ok, err := token.APReq.Verify(serviceKeytab, time.Minute, types.HostAddress{}, nil); !ok {
+			return "", nil, err
+		}
+		if username := token.APReq.Ticket.DecryptedEncPart.CName.PrincipalNameString(); username != "testuser1" {
+			return "", nil, errors.New("unexpected client principal " + username)
+		}
+		return "TESTUSER1", nil, nil
+	})
+
+	p, spns := newTestProvider(t, serviceKeytab)
+	defer p.Close()
+
+	connector := driver.NewKerberosAuthConnector(s.Host(), p)
+	if err := connector.SetServicePrincipal(testService + "@" + testRealm); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := connector.NativeConn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if len(*spns) != 1 || (*spns)[0] != testService {
+		t.Fatalf("service principals %v - expected %s", *spns, testService)
+	}
+
+	// service key not available
+	p, _ = newTestProvider(t, loadTestKeytab(t, testdata.KEYTAB_TESTUSER1_TEST_GOKRB5))
+	defer p.Close()
+	if _, err := driver.NewKerberosAuthConnector(s.Host(), p).NativeConn(context.Background()); err == nil {
+		t.Fatal("expected authentication error")
+	}
+}
+
+func TestSplitPrincipal(t *testing.T) {
+	tests := []struct{ principal, name, realm string }{
+		{"user", "user", ""},
+		{"user@EXAMPLE.COM", "user", "EXAMPLE.COM"},
+		{"hdb/host.example.com@EXAMPLE.COM", "hdb/host.example.com", "EXAMPLE.COM"},
+	}
+	for _, test := range tests {
+		if name, realm := splitPrincipal(test.principal); name != test.name || realm != test.realm {
+			t.Fatalf("principal %s: %s %s - expected %s %s", test.principal, name, realm, test.name, test.realm)
+		}
+	}
+}